@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/asset/txlog"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/calc"
 	"decred.org/dcrdex/dex/config"
@@ -64,9 +65,10 @@ const (
 	splitTxBaggageSegwit = dexbtc.MinimumTxOverhead + 2*dexbtc.P2WPKHOutputSize +
 		dexbtc.RedeemP2WPKHInputSize + ((dexbtc.RedeemP2WPKHInputWitnessWeight + dexbtc.SegwitMarkerAndFlagWeight + 3) / 4)
 
-	walletTypeLegacy = ""
-	walletTypeRPC    = "bitcoindRPC"
-	walletTypeSPV    = "SPV"
+	walletTypeLegacy         = ""
+	walletTypeRPC            = "bitcoindRPC"
+	walletTypeSPV            = "SPV"
+	walletTypeExternalSigner = "externalSigner"
 
 	swapFeeBumpKey   = "swapfeebump"
 	splitKey         = "swapsplit"
@@ -185,6 +187,41 @@ var (
 		DefaultConfigPath: dexbtc.SystemConfigPath("bitcoin"),
 		ConfigOpts:        append(rpcOpts, commonOpts...),
 	}
+	externalSignerOpts = []*asset.ConfigOption{
+		{
+			Key:         "descriptor",
+			DisplayName: "Output descriptor",
+			Description: "The ranged output descriptor of the wallet's receiving " +
+				"addresses, e.g. wpkh([d34db33f/84'/0'/0']xpub.../0/*). The " +
+				"descriptor is imported into the watch-only wallet if it is not " +
+				"already there.",
+		},
+		{
+			Key:         "changedescriptor",
+			DisplayName: "Change descriptor",
+			Description: "The ranged output descriptor of the wallet's change " +
+				"addresses, e.g. wpkh([d34db33f/84'/0'/0']xpub.../1/*)",
+		},
+		{
+			Key:         "signersocket",
+			DisplayName: "Signer socket",
+			Description: "Address of the external signer. Use unix:<path> for a " +
+				"unix socket or <host>:<port> for TCP.",
+		},
+		{
+			Key:         "signerdir",
+			DisplayName: "Signer directory",
+			Description: "Directory for exchanging signing request and response " +
+				"files with the external signer. Used instead of a signer socket.",
+		},
+	}
+	externalSignerWalletDefinition = &asset.WalletDefinition{
+		Type:              walletTypeExternalSigner,
+		Tab:               "External Signer",
+		Description:       "Connect to a watch-only bitcoind wallet and sign with an external signer",
+		DefaultConfigPath: dexbtc.SystemConfigPath("bitcoin"),
+		ConfigOpts:        append(append(rpcOpts, externalSignerOpts...), commonOpts...),
+	}
 	spvWalletDefinition = &asset.WalletDefinition{
 		Type:        walletTypeSPV,
 		Tab:         "Native",
@@ -201,6 +238,7 @@ var (
 		AvailableWallets: []*asset.WalletDefinition{
 			spvWalletDefinition,
 			rpcWalletDefinition,
+			externalSignerWalletDefinition,
 		},
 		LegacyWalletIndex: 1,
 	}
@@ -441,6 +479,16 @@ type RPCWalletConfig struct {
 	WalletConfig `ini:",extends"`
 }
 
+// ExternalSignerConfig is the configuration of the watch-only wallet's
+// descriptors and the external signer. Exactly one of SignerSocket or
+// SignerDir must be set.
+type ExternalSignerConfig struct {
+	Descriptor       string `ini:"descriptor"`
+	ChangeDescriptor string `ini:"changedescriptor"`
+	SignerSocket     string `ini:"signersocket"`
+	SignerDir        string `ini:"signerdir"`
+}
+
 // WalletConfig are wallet-level configuration settings.
 type WalletConfig struct {
 	UseSplitTx       bool    `ini:"txsplit"`
@@ -692,6 +740,16 @@ type baseWallet struct {
 
 	findRedemptionMtx   sync.RWMutex
 	findRedemptionQueue map[outPoint]*findRedemptionReq
+
+	txLogPath    string
+	txLogMtx     sync.RWMutex
+	txLog        *txlog.DB
+	txHistoryMtx sync.Mutex // serializes updateTxHistory
+
+	// spendTxs are the redemption and refund transactions that might need
+	// to be replaced with transactions paying higher fees.
+	spendTxsMtx sync.RWMutex
+	spendTxs    map[chainhash.Hash]*spendTx
 }
 
 func (w *baseWallet) fallbackFeeRate() uint64 {
@@ -732,10 +790,17 @@ var _ asset.Wallet = (*baseWallet)(nil)
 var _ asset.Accelerator = (*ExchangeWalletAccelerator)(nil)
 var _ asset.Accelerator = (*ExchangeWalletSPV)(nil)
 var _ asset.Withdrawer = (*baseWallet)(nil)
+var _ asset.Bonder = (*baseWallet)(nil)
+var _ asset.FeeReportingRefunder = (*baseWallet)(nil)
 var _ asset.Rescanner = (*ExchangeWalletSPV)(nil)
 var _ asset.FeeRater = (*ExchangeWalletFullNode)(nil)
 var _ asset.LogFiler = (*ExchangeWalletSPV)(nil)
 var _ asset.Recoverer = (*ExchangeWalletSPV)(nil)
+var _ asset.MultiOrderFunder = (*baseWallet)(nil)
+var _ asset.WalletHistorian = (*baseWallet)(nil)
+var _ asset.FeeBumper = (*ExchangeWalletAccelerator)(nil)
+var _ asset.FeeBumper = (*ExchangeWalletSPV)(nil)
+var _ asset.CoinController = (*baseWallet)(nil)
 
 // RecoveryCfg is the information that is transferred from the old wallet
 // to the new one when the wallet is recovered.
@@ -886,6 +951,8 @@ func NewWallet(cfg *asset.WalletConfig, logger dex.Logger, net dex.Network) (ass
 			return nil, err
 		}
 		return &ExchangeWalletAccelerator{rpcWallet}, nil
+	case walletTypeExternalSigner:
+		return newExternalSignerWallet(cloneCFG)
 	default:
 		return nil, fmt.Errorf("unknown wallet type %q", cfg.Type)
 	}
@@ -1005,6 +1072,7 @@ func newUnconnectedWallet(cfg *BTCCloneCFG, walletCfg *WalletConfig) (*baseWalle
 		peersChange:         cfg.WalletCFG.PeersChange,
 		fundingCoins:        make(map[outPoint]*utxo),
 		findRedemptionQueue: make(map[outPoint]*findRedemptionReq),
+		spendTxs:            make(map[chainhash.Hash]*spendTx),
 		minNetworkVersion:   cfg.MinNetworkVersion,
 		dustLimit:           cfg.ConstantDustLimit,
 		useLegacyBalance:    cfg.LegacyBalance,
@@ -1020,6 +1088,7 @@ func newUnconnectedWallet(cfg *BTCCloneCFG, walletCfg *WalletConfig) (*baseWalle
 		hashTx:              txHasher,
 		calcTxSize:          txSizeCalculator,
 		txVersion:           txVersion,
+		txLogPath:           txLogPath(cfg.WalletCFG.DataDir, cfg.Network),
 	}
 	w.cfgV.Store(baseCfg)
 
@@ -1081,6 +1150,9 @@ func (btc *baseWallet) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 	btc.currentTip = bestBlock
 	btc.tipMtx.Unlock()
 	atomic.StoreInt64(&btc.tipAtConnect, btc.currentTip.height)
+	if err := btc.openTxLog(); err != nil {
+		return nil, err
+	}
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -1088,6 +1160,11 @@ func (btc *baseWallet) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 		btc.shutdown()
 	}()
 	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		btc.updateTxHistory(bestBlock.height)
+	}()
+	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		btc.monitorPeers(ctx)
@@ -1105,6 +1182,8 @@ func (btc *baseWallet) shutdown() {
 		delete(btc.findRedemptionQueue, contractOutpoint)
 	}
 	btc.findRedemptionMtx.Unlock()
+
+	btc.closeTxLog()
 }
 
 // Reconfigure attempts to reconfigure the wallet.
@@ -1692,19 +1771,8 @@ func (btc *baseWallet) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes, er
 	if ord.MaxSwapCount == 0 {
 		return nil, nil, fmt.Errorf("cannot fund a zero-lot order")
 	}
-	if ord.FeeSuggestion > ord.DEXConfig.MaxFeeRate {
-		return nil, nil, fmt.Errorf("fee suggestion %d > max fee rate %d", ord.FeeSuggestion, ord.DEXConfig.MaxFeeRate)
-	}
-	if ord.FeeSuggestion > btc.feeRateLimit() {
-		return nil, nil, fmt.Errorf("suggested fee > configured limit. %d > %d", ord.FeeSuggestion, btc.feeRateLimit())
-	}
-	// Check wallets fee rate limit against server's max fee rate
-	if btc.feeRateLimit() < ord.DEXConfig.MaxFeeRate {
-		return nil, nil, fmt.Errorf(
-			"%v: server's max fee rate %v higher than configued fee rate limit %v",
-			ord.DEXConfig.Symbol,
-			ord.DEXConfig.MaxFeeRate,
-			btc.feeRateLimit())
+	if err := btc.checkFundingFeeRates(ord.DEXConfig, ord.FeeSuggestion); err != nil {
+		return nil, nil, err
 	}
 
 	customCfg := new(swapOptions)
@@ -1716,7 +1784,7 @@ func (btc *baseWallet) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes, er
 	btc.fundingMtx.Lock()         // before getting spendable utxos from wallet
 	defer btc.fundingMtx.Unlock() // after we update the map and lock in the wallet
 
-	utxos, _, avail, err := btc.spendableUTXOs(0)
+	utxos, utxoMap, avail, err := btc.spendableUTXOs(0)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error parsing unspent outputs: %w", err)
 	}
@@ -1735,36 +1803,32 @@ func (btc *baseWallet) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes, er
 		return inputsVal >= reqFunds
 	}
 
-	sum, size, coins, fundingCoins, redeemScripts, spents, err := fund(utxos, enough)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error funding swap value of %s: %w", amount(ord.Value), err)
-	}
-
 	useSplit := btc.useSplitTx()
 	if customCfg.Split != nil {
 		useSplit = *customCfg.Split
 	}
 
+	fundFunc := fund
+	if len(ord.Coins) > 0 {
+		// The user chose the coins, so use all of them, and don't split.
+		utxos, err = btc.selectedUTXOs(ord.Coins, utxoMap)
+		if err != nil {
+			return nil, nil, err
+		}
+		fundFunc = fundWithUTXOs
+		useSplit = false
+	}
+
+	sum, size, coins, fundingCoins, redeemScripts, spents, err := fundFunc(utxos, enough)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error funding swap value of %s: %w", amount(ord.Value), err)
+	}
+
 	if useSplit && !ord.Immediate {
 		// We apply the bumped fee rate to the split transaction when the
 		// PreSwap is created, so we use that bumped rate here too.
 		// But first, check that it's within bounds.
-		splitFeeRate := ord.FeeSuggestion
-		if splitFeeRate == 0 {
-			// TODO
-			// 1.0: Error when no suggestion.
-			// return nil, nil, fmt.Errorf("cannot do a split transaction without a fee rate suggestion from the server")
-			splitFeeRate = btc.targetFeeRateWithFallback(btc.redeemConfTarget(), 0)
-			// We PreOrder checked this as <= MaxFeeRate, so use that as an
-			// upper limit.
-			if splitFeeRate > ord.DEXConfig.MaxFeeRate {
-				splitFeeRate = ord.DEXConfig.MaxFeeRate
-			}
-		}
-		splitFeeRate, err = calcBumpedRate(splitFeeRate, customCfg.FeeBump)
-		if err != nil {
-			btc.log.Errorf("calcBumpRate error: %v", err)
-		}
+		splitFeeRate := btc.splitFeeRate(ord.FeeSuggestion, ord.DEXConfig.MaxFeeRate, customCfg.FeeBump)
 
 		splitCoins, split, err := btc.split(ord.Value, ord.MaxSwapCount, spents,
 			uint64(size), fundingCoins, splitFeeRate, bumpedMaxRate, ord.DEXConfig)
@@ -1791,6 +1855,241 @@ func (btc *baseWallet) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes, er
 	return coins, redeemScripts, nil
 }
 
+// FundMultiOrder funds several standing orders at once. If split transactions
+// are enabled, a single split transaction is sent with an output sized for
+// each order. Otherwise, each order is funded with its own set of UTXOs. Part
+// of the asset.MultiOrderFunder interface.
+func (btc *baseWallet) FundMultiOrder(mo *asset.MultiOrder) ([]asset.Coins, [][]dex.Bytes, error) {
+	if len(mo.Values) == 0 {
+		return nil, nil, fmt.Errorf("no orders to fund")
+	}
+	var totalValue uint64
+	for i, v := range mo.Values {
+		if v.Value == 0 {
+			return nil, nil, fmt.Errorf("cannot fund value = 0 for order %d", i)
+		}
+		if v.MaxSwapCount == 0 {
+			return nil, nil, fmt.Errorf("cannot fund a zero-lot order (order %d)", i)
+		}
+		totalValue += v.Value
+	}
+	btc.log.Debugf("Attempting to fund %d orders for %s %s, maxFeeRate = %d",
+		len(mo.Values), amount(totalValue), btc.symbol, mo.DEXConfig.MaxFeeRate)
+
+	if err := btc.checkFundingFeeRates(mo.DEXConfig, mo.FeeSuggestion); err != nil {
+		return nil, nil, err
+	}
+
+	customCfg := new(swapOptions)
+	err := config.Unmapify(mo.Options, customCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing swap options: %w", err)
+	}
+
+	btc.fundingMtx.Lock()
+	defer btc.fundingMtx.Unlock()
+
+	utxos, _, avail, err := btc.spendableUTXOs(0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing unspent outputs: %w", err)
+	}
+	if avail < totalValue {
+		return nil, nil, fmt.Errorf("insufficient funds. %s requested, %s available",
+			amount(totalValue), amount(avail))
+	}
+
+	bumpedMaxRate, err := calcBumpedRate(mo.DEXConfig.MaxFeeRate, customCfg.FeeBump)
+	if err != nil {
+		btc.log.Errorf("calcBumpRate error: %v", err)
+	}
+
+	useSplit := btc.useSplitTx()
+	if customCfg.Split != nil {
+		useSplit = *customCfg.Split
+	}
+	if useSplit {
+		splitFeeRate := btc.splitFeeRate(mo.FeeSuggestion, mo.DEXConfig.MaxFeeRate, customCfg.FeeBump)
+		return btc.multiSplit(mo, utxos, splitFeeRate, bumpedMaxRate)
+	}
+
+	coins := make([]asset.Coins, 0, len(mo.Values))
+	redeemScripts := make([][]dex.Bytes, 0, len(mo.Values))
+	fundingCoins := make(map[outPoint]*utxo)
+	var spents []*output
+	for i, v := range mo.Values {
+		enough := func(inputsSize, inputsVal uint64) bool {
+			reqFunds := calc.RequiredOrderFundsAlt(v.Value, inputsSize, v.MaxSwapCount,
+				mo.DEXConfig.SwapSizeBase, mo.DEXConfig.SwapSize, bumpedMaxRate)
+			return inputsVal >= reqFunds
+		}
+		_, _, ordCoins, ordFundingCoins, ordRedeemScripts, ordSpents, err := fund(utxos, enough)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error funding order %d with swap value of %s: %w", i, amount(v.Value), err)
+		}
+		// Don't use these UTXOs for the remaining orders.
+		remaining := make([]*compositeUTXO, 0, len(utxos))
+		for _, cu := range utxos {
+			if _, used := ordFundingCoins[newOutPoint(cu.txHash, cu.vout)]; !used {
+				remaining = append(remaining, cu)
+			}
+		}
+		utxos = remaining
+		for pt, u := range ordFundingCoins {
+			fundingCoins[pt] = u
+		}
+		coins = append(coins, ordCoins)
+		redeemScripts = append(redeemScripts, ordRedeemScripts)
+		spents = append(spents, ordSpents...)
+	}
+
+	btc.log.Infof("Funding %d %s orders worth %s with coins %v", len(mo.Values), btc.symbol,
+		amount(totalValue), coins)
+
+	err = btc.node.lockUnspent(false, spents)
+	if err != nil {
+		return nil, nil, fmt.Errorf("LockUnspent error: %w", err)
+	}
+
+	for pt, utxo := range fundingCoins {
+		btc.fundingCoins[pt] = utxo
+	}
+
+	return coins, redeemScripts, nil
+}
+
+// multiSplit funds the orders of a MultiOrder with a single split transaction
+// that has an output sized for each order. The fundingMtx must be held.
+func (btc *baseWallet) multiSplit(mo *asset.MultiOrder, utxos []*compositeUTXO,
+	splitFeeRate, bumpedMaxRate uint64) ([]asset.Coins, [][]dex.Bytes, error) {
+
+	swapInputSize, _ := btc.splitBaggageFees(bumpedMaxRate)
+	outputSize := uint64(dexbtc.P2PKHOutputSize)
+	if btc.segwit {
+		outputSize = dexbtc.P2WPKHOutputSize
+	}
+
+	reqFunds := make([]uint64, 0, len(mo.Values))
+	var totalReq uint64
+	for _, v := range mo.Values {
+		req := calc.RequiredOrderFundsAlt(v.Value, swapInputSize, v.MaxSwapCount,
+			mo.DEXConfig.SwapSizeBase, mo.DEXConfig.SwapSize, bumpedMaxRate)
+		reqFunds = append(reqFunds, req)
+		totalReq += req
+	}
+
+	// The split tx has an output for each order, and one for change.
+	baseSize := dexbtc.MinimumTxOverhead + outputSize*uint64(len(mo.Values)+1)
+	enough := func(inputsSize, inputsVal uint64) bool {
+		return inputsVal >= totalReq+(baseSize+inputsSize)*splitFeeRate
+	}
+	coinSum, _, inputCoins, _, _, _, err := fund(utxos, enough)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error funding split transaction for %d orders: %w", len(mo.Values), err)
+	}
+
+	baseTx, _, _, err := btc.fundedTx(inputCoins)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating split transaction: %w", err)
+	}
+	addrStrs := make([]string, 0, len(mo.Values))
+	for _, req := range reqFunds {
+		addr, err := btc.node.changeAddress()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating split transaction address: %w", err)
+		}
+		addrStr, err := btc.stringAddr(addr, btc.chainParams)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to stringify the change address: %w", err)
+		}
+		splitScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating split tx script: %w", err)
+		}
+		baseTx.AddTxOut(wire.NewTxOut(int64(req), splitScript))
+		addrStrs = append(addrStrs, addrStr)
+	}
+
+	changeAddr, err := btc.node.changeAddress()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating change address: %w", err)
+	}
+
+	msgTx, err := btc.sendWithReturn(baseTx, changeAddr, coinSum, totalReq, splitFeeRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	txHash := btc.hashTx(msgTx)
+
+	coins := make([]asset.Coins, 0, len(mo.Values))
+	redeemScripts := make([][]dex.Bytes, 0, len(mo.Values))
+	ops := make([]*output, 0, len(mo.Values))
+	for i, req := range reqFunds {
+		op := newOutput(txHash, uint32(i), req)
+		btc.fundingCoins[op.pt] = &utxo{
+			txHash:  op.txHash(),
+			vout:    op.vout(),
+			address: addrStrs[i],
+			amount:  req,
+		}
+		coins = append(coins, asset.Coins{op})
+		redeemScripts = append(redeemScripts, []dex.Bytes{nil}) // no redeem script required for split tx output
+		ops = append(ops, op)
+	}
+
+	btc.log.Infof("Sent split transaction %s to fund %d %s orders from coins %v",
+		txHash, len(mo.Values), btc.symbol, inputCoins)
+
+	if err = btc.node.lockUnspent(false, ops); err != nil {
+		btc.log.Errorf("error locking unspent outputs: %v", err)
+	}
+
+	return coins, redeemScripts, nil
+}
+
+// checkFundingFeeRates checks the server's max fee rate and fee suggestion
+// against each other and the wallet's configured fee rate limit.
+func (btc *baseWallet) checkFundingFeeRates(dexCfg *dex.Asset, feeSuggestion uint64) error {
+	if feeSuggestion > dexCfg.MaxFeeRate {
+		return fmt.Errorf("fee suggestion %d > max fee rate %d", feeSuggestion, dexCfg.MaxFeeRate)
+	}
+	if feeSuggestion > btc.feeRateLimit() {
+		return fmt.Errorf("suggested fee > configured limit. %d > %d", feeSuggestion, btc.feeRateLimit())
+	}
+	// Check wallets fee rate limit against server's max fee rate
+	if btc.feeRateLimit() < dexCfg.MaxFeeRate {
+		return fmt.Errorf(
+			"%v: server's max fee rate %v higher than configued fee rate limit %v",
+			dexCfg.Symbol,
+			dexCfg.MaxFeeRate,
+			btc.feeRateLimit())
+	}
+	return nil
+}
+
+// splitFeeRate is the fee rate for a split transaction, bumped by the swap fee
+// bump option.
+func (btc *baseWallet) splitFeeRate(feeSuggestion, maxFeeRate uint64, feeBump *float64) uint64 {
+	// We apply the bumped fee rate to the split transaction when the PreSwap
+	// is created, so we use that bumped rate here too.
+	splitFeeRate := feeSuggestion
+	if splitFeeRate == 0 {
+		// TODO
+		// 1.0: Error when no suggestion.
+		// return nil, nil, fmt.Errorf("cannot do a split transaction without a fee rate suggestion from the server")
+		splitFeeRate = btc.targetFeeRateWithFallback(btc.redeemConfTarget(), 0)
+		// We PreOrder checked this as <= MaxFeeRate, so use that as an
+		// upper limit.
+		if splitFeeRate > maxFeeRate {
+			splitFeeRate = maxFeeRate
+		}
+	}
+	splitFeeRate, err := calcBumpedRate(splitFeeRate, feeBump)
+	if err != nil {
+		btc.log.Errorf("calcBumpRate error: %v", err)
+	}
+	return splitFeeRate
+}
+
 func fund(utxos []*compositeUTXO, enough func(uint64, uint64) bool) (
 	sum uint64, size uint32, coins asset.Coins, fundingCoins map[outPoint]*utxo, redeemScripts []dex.Bytes, spents []*output, err error) {
 
@@ -2860,6 +3159,19 @@ func (btc *baseWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, ui
 		return nil, nil, 0, err
 	}
 
+	secretHashes := make([]dex.Bytes, 0, swapCount)
+	for _, contract := range swaps.Contracts {
+		secretHashes = append(secretHashes, contract.SecretHash)
+	}
+	btc.logTx(&asset.WalletTransaction{
+		Type:         asset.Swap,
+		ID:           txHash.String(),
+		Amount:       totalOut,
+		Fees:         fees,
+		Timestamp:    uint64(time.Now().Unix()),
+		SecretHashes: secretHashes,
+	})
+
 	// If change is nil, return a nil asset.Coin.
 	var changeCoin asset.Coin
 	if change != nil {
@@ -2907,6 +3219,8 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 	msgTx := wire.NewMsgTx(btc.txVersion())
 	var totalIn uint64
 	contracts := make([][]byte, 0, len(form.Redemptions))
+	secrets := make([][]byte, 0, len(form.Redemptions))
+	secretHashes := make([]dex.Bytes, 0, len(form.Redemptions))
 	prevScripts := make([][]byte, 0, len(form.Redemptions))
 	addresses := make([]btcutil.Address, 0, len(form.Redemptions))
 	values := make([]int64, 0, len(form.Redemptions))
@@ -2938,7 +3252,10 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 		prevScripts = append(prevScripts, pkScript)
 		addresses = append(addresses, receiver)
 		contracts = append(contracts, contract)
+		secrets = append(secrets, r.Secret)
+		secretHashes = append(secretHashes, secretHash)
 		txIn := wire.NewTxIn(cinfo.output.wireOutPoint(), nil, nil)
+		txIn.Sequence = rbfSequence
 		msgTx.AddTxIn(txIn)
 		values = append(values, int64(cinfo.output.value))
 		totalIn += cinfo.output.value
@@ -3029,6 +3346,23 @@ func (btc *baseWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin,
 		return nil, nil, 0, fmt.Errorf("redemption sent, but received unexpected transaction ID back from RPC server. "+
 			"expected %s, got %s", *txHash, checkHash)
 	}
+	btc.storeSpendTx(txHash, &spendTx{
+		msgTx:     msgTx,
+		contracts: contracts,
+		values:    values,
+		secrets:   secrets,
+		size:      size,
+		fees:      fee,
+		stamp:     time.Now(),
+	})
+	btc.logTx(&asset.WalletTransaction{
+		Type:         asset.Redeem,
+		ID:           txHash.String(),
+		Amount:       totalIn,
+		Fees:         fee,
+		Timestamp:    uint64(time.Now().Unix()),
+		SecretHashes: secretHashes,
+	})
 	// Log the change output.
 	coinIDs := make([]dex.Bytes, 0, len(form.Redemptions))
 	for i := range form.Redemptions {
@@ -3077,6 +3411,13 @@ func (btc *baseWallet) SignMessage(coin asset.Coin, msg dex.Bytes) (pubkeys, sig
 	if utxo == nil {
 		return nil, nil, fmt.Errorf("no utxo found for %s", op)
 	}
+	if es, is := btc.node.(externalSigner); is {
+		pubkey, sig, err := es.signMessage(utxo.address, msg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []dex.Bytes{pubkey}, []dex.Bytes{sig}, nil
+	}
 	privKey, err := btc.node.privKeyForAddress(utxo.address)
 	if err != nil {
 		return nil, nil, err
@@ -3461,14 +3802,21 @@ func (btc *baseWallet) tryRedemptionRequests(ctx context.Context, startBlock *ch
 // was created. The client should store this information for persistence across
 // sessions.
 func (btc *baseWallet) Refund(coinID, contract dex.Bytes, feeSuggestion uint64) (dex.Bytes, error) {
+	refundCoin, _, err := btc.RefundWithFees(coinID, contract, feeSuggestion)
+	return refundCoin, err
+}
+
+// RefundWithFees is like Refund, but also returns the fees paid by the refund
+// transaction. Part of the asset.FeeReportingRefunder interface.
+func (btc *baseWallet) RefundWithFees(coinID, contract dex.Bytes, feeSuggestion uint64) (dex.Bytes, uint64, error) {
 	txHash, vout, err := decodeCoinID(coinID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	pkScript, err := btc.scriptHashScript(contract)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing pubkey script: %w", err)
+		return nil, 0, fmt.Errorf("error parsing pubkey script: %w", err)
 	}
 
 	// TODO: I'd recommend not passing a pkScript without a limited startTime
@@ -3481,26 +3829,49 @@ func (btc *baseWallet) Refund(coinID, contract dex.Bytes, feeSuggestion uint64)
 	// a limited startTime.
 	utxo, _, err := btc.node.getTxOut(txHash, vout, pkScript, time.Time{})
 	if err != nil {
-		return nil, fmt.Errorf("error finding unspent contract: %w", err)
+		return nil, 0, fmt.Errorf("error finding unspent contract: %w", err)
 	}
 	if utxo == nil {
-		return nil, asset.CoinNotFoundError // spent
+		return nil, 0, asset.CoinNotFoundError // spent
 	}
 	msgTx, err := btc.refundTx(txHash, vout, contract, uint64(utxo.Value), nil, feeSuggestion)
 	if err != nil {
-		return nil, fmt.Errorf("error creating refund tx: %w", err)
+		return nil, 0, fmt.Errorf("error creating refund tx: %w", err)
 	}
 
 	checkHash := btc.hashTx(msgTx)
 	refundHash, err := btc.node.sendRawTransaction(msgTx)
 	if err != nil {
-		return nil, fmt.Errorf("sendRawTransaction: %w", err)
+		return nil, 0, fmt.Errorf("sendRawTransaction: %w", err)
 	}
 	if *refundHash != *checkHash {
-		return nil, fmt.Errorf("refund sent, but received unexpected transaction ID back from RPC server. "+
+		return nil, 0, fmt.Errorf("refund sent, but received unexpected transaction ID back from RPC server. "+
 			"expected %s, got %s", *refundHash, checkHash)
 	}
-	return toCoinID(refundHash, 0), nil
+	var fees uint64
+	if len(msgTx.TxOut) == 1 { // it should be
+		fees = uint64(utxo.Value - msgTx.TxOut[0].Value)
+		btc.storeSpendTx(refundHash, &spendTx{
+			msgTx:     msgTx,
+			contracts: [][]byte{contract},
+			values:    []int64{utxo.Value},
+			size:      btc.calcTxSize(msgTx),
+			fees:      fees,
+			stamp:     time.Now(),
+		})
+	}
+	tx := &asset.WalletTransaction{
+		Type:      asset.Refund,
+		ID:        refundHash.String(),
+		Amount:    uint64(utxo.Value),
+		Fees:      fees,
+		Timestamp: uint64(time.Now().Unix()),
+	}
+	if _, _, _, secretHash, err := dexbtc.ExtractSwapDetails(contract, btc.segwit, btc.chainParams); err == nil {
+		tx.SecretHashes = []dex.Bytes{secretHash}
+	}
+	btc.logTx(tx)
+	return toCoinID(refundHash, 0), fees, nil
 }
 
 // refundTx creates and signs a contract`s refund transaction. If refundAddr is
@@ -3517,10 +3888,9 @@ func (btc *baseWallet) refundTx(txHash *chainhash.Hash, vout uint32, contract de
 	msgTx.LockTime = uint32(lockTime)
 	prevOut := wire.NewOutPoint(txHash, vout)
 	txIn := wire.NewTxIn(prevOut, []byte{}, nil)
-	// Enable the OP_CHECKLOCKTIMEVERIFY opcode to be used.
-	//
-	// https://github.com/bitcoin/bips/blob/master/bip-0125.mediawiki#Spending_wallet_policy
-	txIn.Sequence = wire.MaxTxInSequenceNum - 1
+	// Enable the OP_CHECKLOCKTIMEVERIFY opcode to be used, and signal that the
+	// refund can be replaced with one paying a higher fee.
+	txIn.Sequence = rbfSequence
 	msgTx.AddTxIn(txIn)
 	// Calculate fees and add the change output.
 
@@ -3581,6 +3951,269 @@ func (btc *baseWallet) refundTx(txHash *chainhash.Hash, vout uint32, contract de
 	return msgTx, nil
 }
 
+// MakeBondTx creates a time-locked fidelity bond transaction. The V0
+// transaction has two required outputs:
+//
+// Output 0 is the time-locked bond output of type P2SH or P2WSH, depending
+// on whether the wallet is configured for segwit, with the bond script
+// created by dexbtc.MakeBondScript.
+//
+// Output 1 is a DEX Account commitment. This is an OP_RETURN output that
+// references the provided account ID.
+//
+//	OP_RETURN <2-byte version> <32-byte account ID> <4-byte locktime> <20-byte pubkey hash>
+//
+// Having the account ID in the raw allows the txn alone to identify the account
+// without the bond output's redeem script. Having the redeem script's pubkey
+// hash and lock time in the commitment allows the server to verify the bond
+// output without the client providing the script.
+//
+// There may also be a change output.
+//
+// The bond key is used only for the refund path of the bond script. A refund
+// transaction spending the bond output to an address controlled by this
+// wallet is also created and signed with the bond key, and returned as the
+// Bond's RedeemTx. The bond transaction is NOT broadcast.
+func (btc *baseWallet) MakeBondTx(ver uint16, amt, feeRate uint64, lockTime time.Time, bondKey *btcec.PrivateKey, acctID []byte) (*asset.Bond, error) {
+	if ver != 0 {
+		return nil, errors.New("only version 0 bonds supported")
+	}
+	if until := time.Until(lockTime); until >= 365*24*time.Hour {
+		return nil, fmt.Errorf("that lock time is nuts: %v", lockTime)
+	} else if until < 0 {
+		return nil, fmt.Errorf("that lock time is already passed: %v", lockTime)
+	}
+
+	pk := bondKey.PubKey().SerializeCompressed()
+	pkh := btcutil.Hash160(pk)
+
+	feeRate = btc.feeRateWithFallback(feeRate)
+	baseTx := wire.NewMsgTx(btc.txVersion())
+
+	// Add the bond output.
+	lockTimeSec := uint32(lockTime.Unix())
+	bondScript, err := dexbtc.MakeBondScript(ver, lockTimeSec, pkh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build bond output redeem script: %w", err)
+	}
+	pkScript, err := btc.scriptHashScript(bondScript)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing p2sh script: %v", err)
+	}
+	txOut := wire.NewTxOut(int64(amt), pkScript)
+	if btc.IsDust(txOut, feeRate) {
+		return nil, fmt.Errorf("bond output value of %d is dust", amt)
+	}
+	baseTx.AddTxOut(txOut)
+
+	// Add the account ID commitment output.
+	commitPkScript, err := dexbtc.MakeBondCommitScript(ver, acctID, lockTimeSec, pkh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build bond commitment script: %w", err)
+	}
+	baseTx.AddTxOut(wire.NewTxOut(0, commitPkScript))
+
+	// Fund the bond transaction, including a change output.
+	baseSize := btc.calcTxSize(baseTx)
+	if btc.segwit {
+		baseSize += dexbtc.P2WPKHOutputSize
+	} else {
+		baseSize += dexbtc.P2PKHOutputSize
+	}
+
+	btc.fundingMtx.Lock()
+	defer btc.fundingMtx.Unlock()
+
+	utxos, _, avail, err := btc.spendableUTXOs(0)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing unspent outputs: %w", err)
+	}
+	if avail < amt {
+		return nil, fmt.Errorf("insufficient funds. %s requested, %s available",
+			amount(amt), amount(avail))
+	}
+	enough := func(inputsSize, inputsVal uint64) bool {
+		return inputsVal >= amt+(baseSize+inputsSize)*feeRate
+	}
+	totalIn, _, _, _, _, spents, err := fund(utxos, enough)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fund bond of %s: %w", amount(amt), err)
+	}
+	for _, op := range spents {
+		baseTx.AddTxIn(wire.NewTxIn(op.wireOutPoint(), []byte{}, nil))
+	}
+
+	unsignedTx := baseTx.Copy() // signTxAndAddChange may add a change output to baseTx
+	changeAddr, err := btc.node.changeAddress()
+	if err != nil {
+		return nil, fmt.Errorf("error creating change address: %w", err)
+	}
+	signedTx, _, fee, err := btc.signTxAndAddChange(baseTx, changeAddr, totalIn, amt, feeRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign bond tx: %w", err)
+	}
+	txid := btc.hashTx(signedTx)
+
+	// Lock the funding coins so they are not spent before the bond is
+	// broadcast.
+	if err = btc.node.lockUnspent(false, spents); err != nil {
+		btc.log.Errorf("error locking bond funding coins: %v", err)
+	}
+
+	signedTxBytes, err := btc.serializeTx(signedTx)
+	if err != nil {
+		return nil, err
+	}
+	unsignedTxBytes, err := btc.serializeTx(unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prep the redeem / refund tx.
+	redeemAddr, err := btc.externalAddress()
+	if err != nil {
+		return nil, fmt.Errorf("error creating refund address: %w", err)
+	}
+	redeemMsgTx, err := btc.makeBondRefundTxV0(txid, 0, amt, bondScript, bondKey, redeemAddr, feeRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bond refund tx: %w", err)
+	}
+	redeemTx, err := btc.serializeTx(redeemMsgTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize bond refund tx: %w", err)
+	}
+
+	btc.log.Infof("Created %s bond tx %v paying %s with fees of %s, lock time %v",
+		btc.symbol, txid, amount(amt), amount(fee), lockTime)
+
+	assetID, _ := dex.BipSymbolID(btc.symbol)
+	return &asset.Bond{
+		Version:    ver,
+		AssetID:    assetID,
+		Amount:     amt,
+		CoinID:     toCoinID(txid, 0),
+		Data:       bondScript,
+		SignedTx:   signedTxBytes,
+		UnsignedTx: unsignedTxBytes,
+		RedeemTx:   redeemTx,
+	}, nil
+}
+
+// makeBondRefundTxV0 creates and signs a transaction spending the version 0
+// bond output to the provided address. The transaction's lock time is set to
+// the bond's lock time, so it cannot be mined before the bond expires.
+func (btc *baseWallet) makeBondRefundTxV0(txid *chainhash.Hash, vout uint32, amt uint64,
+	script []byte, priv *btcec.PrivateKey, refundAddr btcutil.Address, feeRate uint64) (*wire.MsgTx, error) {
+	lockTime, pkhPush, err := dexbtc.ExtractBondDetailsV0(script)
+	if err != nil {
+		return nil, err
+	}
+
+	pk := priv.PubKey().SerializeCompressed()
+	pkh := btcutil.Hash160(pk)
+	if !bytes.Equal(pkh, pkhPush) {
+		return nil, asset.ErrIncorrectBondKey
+	}
+
+	msgTx := wire.NewMsgTx(btc.txVersion())
+	msgTx.LockTime = lockTime
+	bondPrevOut := wire.NewOutPoint(txid, vout)
+	txIn := wire.NewTxIn(bondPrevOut, []byte{}, nil)
+	txIn.Sequence = wire.MaxTxInSequenceNum - 1 // not finalized, do not disable cltv
+	msgTx.AddTxIn(txIn)
+
+	// Calculate fees and add the refund output.
+	size := btc.calcTxSize(msgTx)
+	if btc.segwit {
+		witnessVBytes := (dexbtc.RefundBondWitnessWeight + 2 + 3) / 4
+		size += uint64(witnessVBytes) + dexbtc.P2WPKHOutputSize
+	} else {
+		size += dexbtc.RefundBondSigScriptSize + dexbtc.P2PKHOutputSize
+	}
+	fee := feeRate * size
+	if fee > amt {
+		return nil, fmt.Errorf("irredeemable bond at fee rate %d atoms/byte", feeRate)
+	}
+
+	redeemPkScript, err := txscript.PayToAddrScript(refundAddr)
+	if err != nil {
+		return nil, fmt.Errorf("error creating pubkey script: %w", err)
+	}
+	redeemTxOut := wire.NewTxOut(int64(amt-fee), redeemPkScript)
+	if btc.IsDust(redeemTxOut, feeRate) {
+		return nil, fmt.Errorf("bond redeem output (amt = %d, feeRate = %d, outputSize = %d) is dust", amt, feeRate, redeemTxOut.SerializeSize())
+	}
+	msgTx.AddTxOut(redeemTxOut)
+
+	if btc.segwit {
+		sigHashes := txscript.NewTxSigHashes(msgTx, new(txscript.CannedPrevOutputFetcher))
+		sig, err := txscript.RawTxInWitnessSignature(msgTx, sigHashes, 0, int64(amt),
+			script, txscript.SigHashAll, priv)
+		if err != nil {
+			return nil, err
+		}
+		txIn.Witness = dexbtc.RefundBondScriptSegwit(script, sig, pk)
+	} else {
+		prevPkScript, err := btc.scriptHashScript(script)
+		if err != nil {
+			return nil, fmt.Errorf("error constructing p2sh script: %w", err)
+		}
+		sig, err := btc.signNonSegwit(msgTx, 0, script, txscript.SigHashAll, priv,
+			[]int64{int64(amt)}, [][]byte{prevPkScript})
+		if err != nil {
+			return nil, err
+		}
+		txIn.SignatureScript, err = dexbtc.RefundBondScript(script, sig, pk)
+		if err != nil {
+			return nil, fmt.Errorf("RefundBondScript: %w", err)
+		}
+	}
+
+	return msgTx, nil
+}
+
+// RefundBond refunds a bond output to a new wallet address given the redeem
+// script and private key. After broadcasting, the output amount and txid are
+// returned.
+func (btc *baseWallet) RefundBond(ctx context.Context, ver uint16, coinID, script []byte, amt uint64, privKey *btcec.PrivateKey) ([]byte, error) {
+	if ver != 0 {
+		return nil, errors.New("only version 0 bonds supported")
+	}
+	txHash, vout, err := decodeCoinID(coinID)
+	if err != nil {
+		return nil, err
+	}
+	feeRate := btc.targetFeeRateWithFallback(2, 0)
+
+	redeemAddr, err := btc.externalAddress()
+	if err != nil {
+		return nil, fmt.Errorf("error creating refund address: %w", err)
+	}
+	msgTx, err := btc.makeBondRefundTxV0(txHash, vout, amt, script, privKey, redeemAddr, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = btc.broadcastTx(msgTx); err != nil {
+		return nil, err
+	}
+
+	return toCoinID(btc.hashTx(msgTx), 0), nil
+}
+
+// SendTransaction broadcasts a valid fully-signed transaction, returning the
+// coin ID of its first output.
+func (btc *baseWallet) SendTransaction(rawTx []byte) ([]byte, error) {
+	msgTx, err := btc.deserializeTx(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	if err = btc.broadcastTx(msgTx); err != nil {
+		return nil, err
+	}
+	return toCoinID(btc.hashTx(msgTx), 0), nil
+}
+
 // DepositAddress returns an address for depositing funds into the
 // exchange wallet.
 func (btc *baseWallet) DepositAddress() (string, error) {
@@ -3592,7 +4225,8 @@ func (btc *baseWallet) DepositAddress() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if btc.Locked() {
+	// Private keys for an external signer wallet are never available.
+	if _, is := btc.node.(externalSigner); is || btc.Locked() {
 		return addrStr, nil
 	}
 	// If the wallet is unlocked, be extra cautious and ensure the wallet gave
@@ -3680,6 +4314,14 @@ func (btc *baseWallet) send(address string, val uint64, feeRate uint64, subtract
 	}
 	for vout, txOut := range tx.TxOut {
 		if bytes.Equal(txOut.PkScript, pay2script) {
+			btc.logTx(&asset.WalletTransaction{
+				Type:      asset.Send,
+				ID:        txHash.String(),
+				Amount:    uint64(txOut.Value),
+				Fees:      toSatoshi(-txRes.Fee), // fee is negative for sends
+				Timestamp: uint64(time.Now().Unix()),
+				Recipient: address,
+			})
 			return txHash, uint32(vout), uint64(txOut.Value), nil
 		}
 	}
@@ -3878,6 +4520,7 @@ func (btc *baseWallet) reportNewTip(ctx context.Context, newTip *block) {
 	btc.currentTip = newTip
 	btc.log.Debugf("tip change: %d (%s) => %d (%s)", prevTip.height, prevTip.hash, newTip.height, newTip.hash)
 	go btc.tipChange(nil)
+	go btc.updateTxHistory(newTip.height)
 
 	reqs := btc.prepareRedemptionRequestsForBlockCheck()
 	// Redemption search would be compromised if the starting point cannot
@@ -4233,6 +4876,9 @@ func (btc *baseWallet) createWitnessSig(tx *wire.MsgTx, idx int, pkScript []byte
 	if err != nil {
 		return nil, nil, err
 	}
+	if es, is := btc.node.(externalSigner); is {
+		return es.signWitnessInput(tx, idx, pkScript, addrStr, val)
+	}
 	privKey, err := btc.node.privKeyForAddress(addrStr)
 	if err != nil {
 		return nil, nil, err
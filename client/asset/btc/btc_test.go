@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -176,6 +177,7 @@ type testData struct {
 	signTxErr            error
 	listUnspent          []*ListUnspentResult
 	listUnspentErr       error
+	listSinceBlock       []btcjson.ListTransactionsResult
 	tipChanged           chan struct{}
 
 	// spv
@@ -426,6 +428,8 @@ func (c *tRawRequester) RawRequest(_ context.Context, method string, params []js
 			return nil, c.privKeyForAddrErr
 		}
 		return json.Marshal(c.privKeyForAddr.String())
+	case methodListSinceBlock:
+		return json.Marshal(&btcjson.ListSinceBlockResult{Transactions: c.listSinceBlock})
 	case methodGetTransaction:
 		if c.getTransactionErr != nil {
 			return nil, c.getTransactionErr
@@ -1168,6 +1172,136 @@ func TestReturnCoins(t *testing.T) {
 	}
 }
 
+func TestCoinControl(t *testing.T) {
+	runRubric(t, testCoinControl)
+}
+
+func testCoinControl(t *testing.T, segwit bool, walletType string) {
+	wallet, node, shutdown := tNewWallet(segwit, walletType)
+	defer shutdown()
+
+	var lots uint64 = 10
+	ordValue := tLotSize * lots
+	reqFunds := calc.RequiredOrderFunds(ordValue, 2*dexbtc.RedeemP2PKHInputSize, lots, tBTC)
+	makeUnspent := func(vout uint32, value uint64) *ListUnspentResult {
+		return &ListUnspentResult{
+			TxID:          tTxID,
+			Vout:          vout,
+			Address:       tP2PKHAddr,
+			Amount:        float64(value) / 1e8,
+			Confirmations: 1,
+			ScriptPubKey:  tP2PKH,
+			Spendable:     true,
+			Solvable:      true,
+			SafePtr:       boolPtr(true),
+		}
+	}
+	// The big output alone would be enough to fund the order.
+	smallUTXO := makeUnspent(0, reqFunds/2)
+	bigUTXO := makeUnspent(1, reqFunds)
+	node.listUnspent = []*ListUnspentResult{smallUTXO, bigUTXO}
+	node.listLockUnspent = []*RPCOutpoint{}
+	node.changeAddr = tP2WPKHAddr
+	node.signFunc = func(tx *wire.MsgTx) {
+		signFunc(tx, 0, wallet.segwit)
+	}
+	txHash, _ := chainhash.NewHashFromStr(tTxID)
+	smallID, bigID := toCoinID(txHash, 0), toCoinID(txHash, 1)
+
+	unspents, err := wallet.ListUnspent()
+	if err != nil {
+		t.Fatalf("ListUnspent error: %v", err)
+	}
+	if len(unspents) != 2 {
+		t.Fatalf("expected 2 unspents, got %d", len(unspents))
+	}
+	if !bytes.Equal(unspents[0].ID, smallID) || unspents[0].Value != reqFunds/2 ||
+		unspents[0].Address != tP2PKHAddr || unspents[0].Confirmations != 1 {
+		t.Fatalf("wrong unspent %+v", unspents[0])
+	}
+
+	ord := &asset.Order{
+		Value:         ordValue,
+		MaxSwapCount:  lots,
+		DEXConfig:     tBTC,
+		FeeSuggestion: feeSuggestion,
+		Options:       map[string]string{splitKey: "true"},
+	}
+
+	// The small output alone is not enough.
+	ord.Coins = []dex.Bytes{smallID}
+	if _, _, err = wallet.FundOrder(ord); err == nil {
+		t.Fatalf("no error funding with not enough selected coins")
+	}
+
+	// Duplicate and unknown coins are rejected.
+	ord.Coins = []dex.Bytes{bigID, bigID}
+	if _, _, err = wallet.FundOrder(ord); err == nil {
+		t.Fatalf("no error funding with a duplicate coin")
+	}
+	ord.Coins = []dex.Bytes{toCoinID(txHash, 2)}
+	if _, _, err = wallet.FundOrder(ord); err == nil {
+		t.Fatalf("no error funding with an unknown coin")
+	}
+
+	// All selected coins are used, even though one would be enough, and no
+	// split is made.
+	ord.Coins = []dex.Bytes{smallID, bigID}
+	coins, redeemScripts, err := wallet.FundOrder(ord)
+	if err != nil {
+		t.Fatalf("error funding with selected coins: %v", err)
+	}
+	if len(coins) != 2 || len(redeemScripts) != 2 {
+		t.Fatalf("expected 2 coins, got %d", len(coins))
+	}
+	if node.sentRawTx != nil {
+		t.Fatalf("split tx sent for selected coins")
+	}
+	if len(wallet.fundingCoins) != 2 {
+		t.Fatalf("expected 2 funding coins, got %d", len(wallet.fundingCoins))
+	}
+
+	// The coins are funding an order, so they are not listed, and can't be
+	// selected again.
+	unspents, err = wallet.ListUnspent()
+	if err != nil {
+		t.Fatalf("ListUnspent error: %v", err)
+	}
+	if len(unspents) != 0 {
+		t.Fatalf("expected 0 unspents, got %d", len(unspents))
+	}
+	ord.Coins = []dex.Bytes{bigID}
+	if _, _, err = wallet.FundOrder(ord); err == nil {
+		t.Fatalf("no error funding with a coin that is already funding an order")
+	}
+	if _, err = wallet.SendWithCoins(tP2PKHAddr, 1e5, feeSuggestion, []dex.Bytes{bigID}); err == nil {
+		t.Fatalf("no error sending with a coin that is already funding an order")
+	}
+	_ = wallet.ReturnCoins(coins)
+
+	// Send with the selected coins.
+	const sendVal = 1e5
+	coin, err := wallet.SendWithCoins(tP2PKHAddr, sendVal, feeSuggestion, []dex.Bytes{smallID})
+	if err != nil {
+		t.Fatalf("SendWithCoins error: %v", err)
+	}
+	if coin.Value() != sendVal {
+		t.Fatalf("wrong sent value %d", coin.Value())
+	}
+	sentTx := node.sentRawTx
+	if len(sentTx.TxIn) != 1 || sentTx.TxIn[0].PreviousOutPoint.Index != 0 {
+		t.Fatalf("send didn't spend the selected coin")
+	}
+	if len(sentTx.TxOut) != 2 || sentTx.TxOut[0].Value != sendVal {
+		t.Fatalf("wrong send outputs")
+	}
+
+	// Not enough to cover the value.
+	if _, err = wallet.SendWithCoins(tP2PKHAddr, reqFunds, feeSuggestion, []dex.Bytes{smallID}); err == nil {
+		t.Fatalf("no error sending more than the selected coins")
+	}
+}
+
 func TestFundingCoins(t *testing.T) {
 	// runRubric(t, testFundingCoins)
 	testFundingCoins(t, false, walletTypeRPC)
@@ -1621,6 +1755,92 @@ func TestFundEdgesSegwit(t *testing.T) {
 	node.walletCfg.useSplitTx = false
 }
 
+func TestFundMultiOrder(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+	defer shutdown()
+
+	swapVal := uint64(1e7)
+	lots := swapVal / tLotSize
+	newUnspent := func(vout uint32) *ListUnspentResult {
+		return &ListUnspentResult{
+			TxID:          tTxID,
+			Vout:          vout,
+			Address:       tP2WPKHAddr,
+			Amount:        0.5,
+			Confirmations: 5,
+			ScriptPubKey:  tP2WPKH,
+			Spendable:     true,
+			Solvable:      true,
+			SafePtr:       boolPtr(true),
+		}
+	}
+	unspents := []*ListUnspentResult{newUnspent(0), newUnspent(1)}
+	node.listUnspent = unspents
+	mo := &asset.MultiOrder{
+		Values: []*asset.MultiOrderValue{
+			{Value: swapVal, MaxSwapCount: lots},
+			{Value: swapVal, MaxSwapCount: lots},
+		},
+		DEXConfig:     tBTC,
+		FeeSuggestion: feeSuggestion,
+	}
+
+	// Without a split, each order is funded with its own utxo.
+	coins, redeemScripts, err := wallet.FundMultiOrder(mo)
+	if err != nil {
+		t.Fatalf("FundMultiOrder error: %v", err)
+	}
+	if len(coins) != 2 || len(redeemScripts) != 2 {
+		t.Fatalf("expected 2 sets of coins, got %d", len(coins))
+	}
+	if len(coins[0]) != 1 || len(coins[1]) != 1 || bytes.Equal(coins[0][0].ID(), coins[1][0].ID()) {
+		t.Fatalf("orders not funded with separate coins")
+	}
+	if len(wallet.fundingCoins) != 2 {
+		t.Fatalf("expected 2 funding coins, got %d", len(wallet.fundingCoins))
+	}
+	wallet.fundingCoins = make(map[outPoint]*utxo)
+
+	// Not enough separate utxos.
+	node.listUnspent = []*ListUnspentResult{newUnspent(0)}
+	if _, _, err = wallet.FundMultiOrder(mo); err == nil {
+		t.Fatalf("no error funding two orders with one utxo")
+	}
+
+	// With a split, a single utxo is split into an output for each order.
+	node.walletCfg.useSplitTx = true
+	node.changeAddr = tP2WPKHAddr
+	node.signFunc = func(tx *wire.MsgTx) {
+		signFunc(tx, 0, wallet.segwit)
+	}
+	coins, _, err = wallet.FundMultiOrder(mo)
+	if err != nil {
+		t.Fatalf("FundMultiOrder split error: %v", err)
+	}
+	if len(coins) != 2 || len(coins[0]) != 1 || len(coins[1]) != 1 {
+		t.Fatalf("wrong coins for split")
+	}
+	if node.sentRawTx == nil || len(node.sentRawTx.TxOut) != 3 {
+		t.Fatalf("split tx not sent with an output per order and change")
+	}
+	for i, c := range coins {
+		op := c[0].(*output)
+		if op.vout() != uint32(i) || *op.txHash() != node.sentRawTx.TxHash() {
+			t.Fatalf("wrong split output for order %d", i)
+		}
+		if op.value <= swapVal {
+			t.Fatalf("split output for order %d does not cover fees", i)
+		}
+	}
+	node.walletCfg.useSplitTx = false
+
+	// Zero value.
+	mo.Values[1].Value = 0
+	if _, _, err = wallet.FundMultiOrder(mo); err == nil {
+		t.Fatalf("no error for zero value order")
+	}
+}
+
 func TestSwap(t *testing.T) {
 	runRubric(t, testSwap)
 }
@@ -2207,10 +2427,13 @@ func testRefund(t *testing.T, segwit bool, walletType string) {
 	node.getTransactionErr = WalletTransactionNotFound
 
 	contractOutput := newOutput(&txHash, 0, 1e8)
-	_, err = wallet.Refund(contractOutput.ID(), contract, feeSuggestion)
+	_, fees, err := wallet.RefundWithFees(contractOutput.ID(), contract, feeSuggestion)
 	if err != nil {
 		t.Fatalf("refund error: %v", err)
 	}
+	if fees == 0 || fees >= 1e8 {
+		t.Fatalf("wrong refund fees %d", fees)
+	}
 
 	// Invalid coin
 	badReceipt := &tReceipt{
@@ -2282,6 +2505,268 @@ func testRefund(t *testing.T, segwit bool, walletType string) {
 	// TODO test spv spent
 }
 
+func TestBumpFee(t *testing.T) {
+	runRubric(t, testBumpFee)
+}
+
+func testBumpFee(t *testing.T, segwit bool, walletType string) {
+	wallet, node, shutdown := tNewWallet(segwit, walletType)
+	defer shutdown()
+
+	wallet.txLogPath = filepath.Join(t.TempDir(), "txhistory.db")
+	if err := wallet.openTxLog(); err != nil {
+		t.Fatalf("openTxLog error: %v", err)
+	}
+	defer wallet.closeTxLog()
+
+	privBytes, _ := hex.DecodeString("b07209eec1a8fb6cfe5cb6ace36567406971a75c330db7101fb21bc679bc5330")
+	privKey, _ := btcec.PrivKeyFromBytes(privBytes)
+	wif, err := btcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatalf("error encoding wif: %v", err)
+	}
+	node.privKeyForAddr = wif
+	node.changeAddr = tP2PKHAddr
+	if segwit {
+		node.changeAddr = tP2WPKHAddr
+	}
+
+	// The sent transaction is unconfirmed in the wallet.
+	walletTx := new(GetTransactionResult)
+	node.getTransactionMap = map[string]*GetTransactionResult{"any": walletTx}
+	sent := func() *wire.MsgTx {
+		t.Helper()
+		tx := node.sentRawTx
+		if tx == nil {
+			t.Fatalf("no tx sent")
+		}
+		walletTx.Hex, _ = serializeMsgTx(tx)
+		return tx
+	}
+
+	checkBump := func(tag string, coinID dex.Bytes, spent *wire.OutPoint) {
+		t.Helper()
+		origTx := sent()
+		for _, txIn := range origTx.TxIn {
+			if txIn.Sequence != rbfSequence {
+				t.Fatalf("%s: sequence %d does not signal replaceability", tag, txIn.Sequence)
+			}
+		}
+		// The transaction can still be bumped after a restart.
+		wallet.spendTxsMtx.Lock()
+		wallet.spendTxs = make(map[chainhash.Hash]*spendTx)
+		wallet.spendTxsMtx.Unlock()
+		feeRate, err := bumpableFeeRate(wallet.baseWallet, coinID)
+		if err != nil {
+			t.Fatalf("%s: bumpableFeeRate error: %v", tag, err)
+		}
+		if feeRate == 0 {
+			t.Fatalf("%s: zero fee rate", tag)
+		}
+		if _, _, err := bumpFee(wallet.baseWallet, coinID, feeRate); err == nil {
+			t.Fatalf("%s: no error for unchanged fee rate", tag)
+		}
+
+		// Signing error.
+		node.privKeyForAddrErr = tErr
+		if _, _, err := bumpFee(wallet.baseWallet, coinID, feeRate*2); err == nil {
+			t.Fatalf("%s: no error for signing error", tag)
+		}
+		node.privKeyForAddrErr = nil
+
+		replacements, addedFees, err := bumpFee(wallet.baseWallet, coinID, feeRate*2)
+		if err != nil {
+			t.Fatalf("%s: bumpFee error: %v", tag, err)
+		}
+		newTx := sent()
+		newHash := newTx.TxHash()
+		if newTx.TxIn[0].PreviousOutPoint != *spent {
+			t.Fatalf("%s: replacement does not spend the contract", tag)
+		}
+		if addedFees == 0 || origTx.TxOut[0].Value-newTx.TxOut[0].Value != int64(addedFees) {
+			t.Fatalf("%s: wrong added fees %d", tag, addedFees)
+		}
+		newCoinID := replacements[coinID.String()]
+		if !bytes.Equal(newCoinID, toCoinID(&newHash, 0)) {
+			t.Fatalf("%s: wrong replacement coin ID %s", tag, newCoinID)
+		}
+		if _, err := bumpableFeeRate(wallet.baseWallet, coinID); err == nil {
+			t.Fatalf("%s: replaced tx still bumpable", tag)
+		}
+		newFeeRate, err := bumpableFeeRate(wallet.baseWallet, newCoinID)
+		if err != nil {
+			t.Fatalf("%s: bumpableFeeRate error for replacement: %v", tag, err)
+		}
+		if newFeeRate < feeRate*2 {
+			t.Fatalf("%s: replacement fee rate %d < %d", tag, newFeeRate, feeRate*2)
+		}
+		if st, _ := wallet.loadSpendTx(&newHash); st == nil || st.feeRate() != newFeeRate {
+			t.Fatalf("%s: replacement not stored in the tx log", tag)
+		}
+
+		// A mined tx can't be bumped.
+		blockHash, _ := node.addRawTx(1, newTx)
+		walletTx.Confirmations = 1
+		walletTx.BlockHash = blockHash.String()
+		if _, _, err := bumpFee(wallet.baseWallet, newCoinID, newFeeRate*2); !errors.Is(err, asset.ErrTxConfirmed) {
+			t.Fatalf("%s: wanted ErrTxConfirmed, got %v", tag, err)
+		}
+		if st, _ := wallet.loadSpendTx(&newHash); st != nil {
+			t.Fatalf("%s: mined tx not removed from the tx log", tag)
+		}
+		walletTx.Confirmations = 0
+		walletTx.BlockHash = ""
+	}
+
+	// Redeem.
+	swapVal := toSatoshi(5)
+	secret, _, _, contract, addr, _, lockTime := makeSwapContract(segwit, time.Hour*12)
+	swapCoin := newOutput(tTxHash, 0, swapVal)
+	coinIDs, _, _, err := wallet.Redeem(&asset.RedeemForm{
+		Redemptions: []*asset.Redemption{{
+			Spends: &asset.AuditInfo{
+				Coin:       swapCoin,
+				Contract:   contract,
+				Recipient:  addr.String(),
+				Expiration: lockTime,
+			},
+			Secret: secret,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("redeem error: %v", err)
+	}
+	checkBump("redeem", coinIDs[0], swapCoin.wireOutPoint())
+
+	// Refund.
+	_, _, pkScript, contract, _, _, _ := makeSwapContract(segwit, time.Hour*12)
+	node.txOutRes = newTxOutResult(nil, 1e8, 2)
+	tx := makeRawTx([]dex.Bytes{pkScript}, []*wire.TxIn{dummyInput()})
+	tx.TxOut[0].Value = 1e8
+	txHash := tx.TxHash()
+	blockHash, _ := node.addRawTx(1, tx)
+	node.getCFilterScripts[*blockHash] = [][]byte{pkScript}
+	contractOutput := newOutput(&txHash, 0, 1e8)
+	node.getTransactionMap = nil // for spv refund
+	refundCoin, _, err := wallet.RefundWithFees(contractOutput.ID(), contract, 100)
+	if err != nil {
+		t.Fatalf("refund error: %v", err)
+	}
+	node.getTransactionMap = map[string]*GetTransactionResult{"any": walletTx}
+	checkBump("refund", refundCoin, contractOutput.wireOutPoint())
+}
+
+func TestMakeBondTx(t *testing.T) {
+	runRubric(t, testMakeBondTx)
+}
+
+func testMakeBondTx(t *testing.T, segwit bool, walletType string) {
+	wallet, node, shutdown := tNewWallet(segwit, walletType)
+	defer shutdown()
+
+	addrStr, pkScript := tP2PKHAddr, tP2PKH
+	if segwit {
+		addrStr, pkScript = tP2WPKHAddr, tP2WPKH
+	}
+	node.newAddress = addrStr
+	node.changeAddr = addrStr
+	node.signFunc = func(tx *wire.MsgTx) {
+		signFunc(tx, 0, wallet.segwit)
+	}
+
+	unspentVal := toSatoshi(10)
+	node.listUnspent = []*ListUnspentResult{{
+		TxID:          tTxID,
+		Address:       addrStr,
+		Amount:        float64(unspentVal) / 1e8,
+		Confirmations: 5,
+		ScriptPubKey:  pkScript,
+		Spendable:     true,
+		Solvable:      true,
+		SafePtr:       boolPtr(true),
+	}}
+
+	bondKey, _ := btcec.NewPrivateKey()
+	acctID := encode.RandomBytes(32)
+	lockTime := time.Now().Add(time.Hour)
+	bondAmt := toSatoshi(2)
+	const feeRate = 10
+
+	// Unsupported version.
+	if _, err := wallet.MakeBondTx(1, bondAmt, feeRate, lockTime, bondKey, acctID); err == nil {
+		t.Fatalf("no error for unsupported bond version")
+	}
+	// Expired lock time.
+	if _, err := wallet.MakeBondTx(0, bondAmt, feeRate, time.Now().Add(-time.Minute), bondKey, acctID); err == nil {
+		t.Fatalf("no error for expired lock time")
+	}
+	// Insufficient funds.
+	if _, err := wallet.MakeBondTx(0, unspentVal, feeRate, lockTime, bondKey, acctID); err == nil {
+		t.Fatalf("no error for insufficient funds")
+	}
+
+	bond, err := wallet.MakeBondTx(0, bondAmt, feeRate, lockTime, bondKey, acctID)
+	if err != nil {
+		t.Fatalf("MakeBondTx error: %v", err)
+	}
+
+	bondTx, err := msgTxFromBytes(bond.SignedTx)
+	if err != nil {
+		t.Fatalf("error decoding bond tx: %v", err)
+	}
+	if len(bondTx.TxOut) < 2 {
+		t.Fatalf("expected at least 2 outputs, got %d", len(bondTx.TxOut))
+	}
+	bondOut := bondTx.TxOut[0]
+	if bondOut.Value != int64(bondAmt) {
+		t.Fatalf("wrong bond amount %d", bondOut.Value)
+	}
+	_, commitAcct, commitLockTime, _, err := dexbtc.ExtractBondCommitDataV0(bondTx.TxOut[1].PkScript)
+	if err != nil {
+		t.Fatalf("error extracting bond commitment: %v", err)
+	}
+	if !bytes.Equal(commitAcct[:], acctID) {
+		t.Fatalf("wrong account ID in commitment")
+	}
+	if commitLockTime != uint32(lockTime.Unix()) {
+		t.Fatalf("wrong lock time in commitment")
+	}
+
+	// The pre-signed refund tx must validly spend the bond output.
+	refundTx, err := msgTxFromBytes(bond.RedeemTx)
+	if err != nil {
+		t.Fatalf("error decoding refund tx: %v", err)
+	}
+	txid := bondTx.TxHash()
+	if refundTx.TxIn[0].PreviousOutPoint.Hash != txid {
+		t.Fatalf("refund tx does not spend the bond tx")
+	}
+	prevOuts := txscript.NewCannedPrevOutputFetcher(bondOut.PkScript, bondOut.Value)
+	sigHashes := txscript.NewTxSigHashes(refundTx, prevOuts)
+	vm, err := txscript.NewEngine(bondOut.PkScript, refundTx, 0, txscript.StandardVerifyFlags,
+		nil, sigHashes, bondOut.Value, prevOuts)
+	if err != nil {
+		t.Fatalf("error creating script engine: %v", err)
+	}
+	if err = vm.Execute(); err != nil {
+		t.Fatalf("refund tx script execution error: %v", err)
+	}
+
+	// Refund with the wrong key.
+	wrongKey, _ := btcec.NewPrivateKey()
+	_, err = wallet.RefundBond(context.Background(), 0, bond.CoinID, bond.Data, bond.Amount, wrongKey)
+	if !errors.Is(err, asset.ErrIncorrectBondKey) {
+		t.Fatalf("expected ErrIncorrectBondKey, got %v", err)
+	}
+
+	if _, err = wallet.RefundBond(context.Background(), 0, bond.CoinID, bond.Data, bond.Amount, bondKey); err != nil {
+		t.Fatalf("RefundBond error: %v", err)
+	}
+	if node.sentRawTx == nil || node.sentRawTx.TxIn[0].PreviousOutPoint.Hash != txid {
+		t.Fatalf("refund tx not broadcast")
+	}
+}
+
 func TestLockUnlock(t *testing.T) {
 	runRubric(t, testLockUnlock)
 }
@@ -2445,6 +2930,93 @@ func TestSend(t *testing.T) {
 	})
 }
 
+func TestTxHistory(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+	defer shutdown()
+
+	if _, err := wallet.TxHistory(0, nil); err == nil {
+		t.Fatalf("no error for TxHistory without a tx log")
+	}
+
+	wallet.txLogPath = filepath.Join(t.TempDir(), "txhistory.db")
+	if err := wallet.openTxLog(); err != nil {
+		t.Fatalf("openTxLog error: %v", err)
+	}
+	defer wallet.closeTxLog()
+
+	randHash := func() string {
+		var h chainhash.Hash
+		copy(h[:], randBytes(32))
+		return h.String()
+	}
+
+	// An unmined swap sent by the wallet.
+	swapID := randHash()
+	wallet.logTx(&asset.WalletTransaction{
+		Type:         asset.Swap,
+		ID:           swapID,
+		Amount:       1e8,
+		Fees:         1e4,
+		SecretHashes: []dex.Bytes{randBytes(32)},
+	})
+
+	// A deposit paid to two wallet addresses, a wallet-funded transaction, and
+	// the wallet's own swap.
+	depositID, splitID := randHash(), randHash()
+	node.listSinceBlock = []btcjson.ListTransactionsResult{
+		{Category: "receive", TxID: depositID, Amount: 0.5, Confirmations: 2, Time: 1000},
+		{Category: "receive", TxID: depositID, Amount: 0.25, Confirmations: 2, Time: 1000},
+		{Category: "send", TxID: splitID, Amount: -1},
+		{Category: "receive", TxID: splitID, Amount: 1},
+		{Category: "send", TxID: swapID, Amount: -1},
+	}
+	// The swap is now mined.
+	node.getTransactionMap = map[string]*GetTransactionResult{
+		swapID: {Confirmations: 3},
+	}
+
+	const tipHeight = 100
+	wallet.updateTxHistory(tipHeight)
+
+	txs, err := wallet.TxHistory(0, nil)
+	if err != nil {
+		t.Fatalf("TxHistory error: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("expected 2 txs, got %d", len(txs))
+	}
+	deposit, swap := txs[0], txs[1]
+	if deposit.ID != depositID || deposit.Type != asset.Receive {
+		t.Fatalf("deposit not logged")
+	}
+	if deposit.Amount != 0.75e8 {
+		t.Fatalf("wrong deposit amount %d", deposit.Amount)
+	}
+	if deposit.BlockNumber != tipHeight-1 {
+		t.Fatalf("wrong deposit block number %d", deposit.BlockNumber)
+	}
+	if swap.ID != swapID || swap.Type != asset.Swap {
+		t.Fatalf("swap not logged")
+	}
+	if swap.BlockNumber != tipHeight-2 {
+		t.Fatalf("wrong swap block number %d", swap.BlockNumber)
+	}
+
+	// Paging.
+	txs, err = wallet.TxHistory(1, &depositID)
+	if err != nil {
+		t.Fatalf("TxHistory error with ref ID: %v", err)
+	}
+	if len(txs) != 1 || txs[0].ID != swapID {
+		t.Fatalf("wrong paged txs")
+	}
+
+	scanHeight, _ := wallet.txLog.ScanHeight()
+	if scanHeight != tipHeight-txHistoryScanMargin {
+		t.Fatalf("wrong scan height %d", scanHeight)
+	}
+}
+
 func TestConfirmations(t *testing.T) {
 	runRubric(t, testConfirmations)
 }
@@ -0,0 +1,164 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package btc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ListUnspent lists the wallet's spendable outputs. Outputs that are funding
+// orders are not included. Part of the asset.CoinController interface.
+func (btc *baseWallet) ListUnspent() ([]*asset.UnspentCoin, error) {
+	btc.fundingMtx.RLock()
+	defer btc.fundingMtx.RUnlock()
+	utxos, _, _, err := btc.spendableUTXOs(0)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing unspent outputs: %w", err)
+	}
+	unspents := make([]*asset.UnspentCoin, 0, len(utxos))
+	for _, utxo := range utxos {
+		if btc.fundingCoins[newOutPoint(utxo.txHash, utxo.vout)] != nil {
+			continue
+		}
+		unspents = append(unspents, &asset.UnspentCoin{
+			ID:            toCoinID(utxo.txHash, utxo.vout),
+			Value:         utxo.amount,
+			Address:       utxo.address,
+			Confirmations: utxo.confs,
+		})
+	}
+	return unspents, nil
+}
+
+// selectedUTXOs finds the user-selected coins in the wallet's spendable
+// outputs. Coins that are already funding an order are rejected.
+// selectedUTXOs should only be called with the fundingMtx locked.
+func (btc *baseWallet) selectedUTXOs(coinIDs []dex.Bytes, utxoMap map[outPoint]*compositeUTXO) ([]*compositeUTXO, error) {
+	if len(coinIDs) == 0 {
+		return nil, errors.New("no coins selected")
+	}
+	utxos := make([]*compositeUTXO, 0, len(coinIDs))
+	seen := make(map[outPoint]bool, len(coinIDs))
+	for _, coinID := range coinIDs {
+		txHash, vout, err := decodeCoinID(coinID)
+		if err != nil {
+			return nil, err
+		}
+		pt := newOutPoint(txHash, vout)
+		if seen[pt] {
+			return nil, fmt.Errorf("coin %s selected more than once", pt)
+		}
+		seen[pt] = true
+		if btc.fundingCoins[pt] != nil {
+			return nil, fmt.Errorf("coin %s is already funding an order", pt)
+		}
+		utxo := utxoMap[pt]
+		if utxo == nil {
+			return nil, fmt.Errorf("coin %s is not a spendable wallet output", pt)
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, nil
+}
+
+// fundWithUTXOs is like fund, but all of the utxos are used. An error is
+// returned if they are not enough.
+func fundWithUTXOs(utxos []*compositeUTXO, enough func(uint64, uint64) bool) (
+	sum uint64, size uint32, coins asset.Coins, fundingCoins map[outPoint]*utxo, redeemScripts []dex.Bytes, spents []*output, err error) {
+
+	fundingCoins = make(map[outPoint]*utxo, len(utxos))
+	for _, unspent := range utxos {
+		op := newOutput(unspent.txHash, unspent.vout, unspent.amount)
+		coins = append(coins, op)
+		redeemScripts = append(redeemScripts, unspent.redeemScript)
+		spents = append(spents, op)
+		size += unspent.input.VBytes()
+		fundingCoins[op.pt] = unspent.utxo
+		sum += unspent.amount
+	}
+	if !enough(uint64(size), sum) {
+		return 0, 0, nil, nil, nil, nil, fmt.Errorf("selected coins worth %s are not enough to cover requested funds",
+			amount(sum))
+	}
+	return
+}
+
+// SendWithCoins sends the exact value to the specified address, spending all
+// of the selected coins. Change is returned to the wallet. feeRate is in units
+// of sats/byte. Part of the asset.CoinController interface.
+func (btc *baseWallet) SendWithCoins(address string, value, feeRate uint64, coinIDs []dex.Bytes) (asset.Coin, error) {
+	addr, err := btc.decodeAddr(address, btc.chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %s", address)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("PayToAddrScript error: %w", err)
+	}
+	feeRate = btc.feeRateWithFallback(feeRate)
+	txOut := wire.NewTxOut(int64(value), pkScript)
+	if btc.IsDust(txOut, feeRate) {
+		return nil, fmt.Errorf("output value %s is dust", amount(value))
+	}
+
+	// Hold the funding lock until the tx is sent so the coins can't be
+	// selected to fund an order in the meantime.
+	btc.fundingMtx.Lock()
+	defer btc.fundingMtx.Unlock()
+
+	_, utxoMap, _, err := btc.spendableUTXOs(0)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing unspent outputs: %w", err)
+	}
+	utxos, err := btc.selectedUTXOs(coinIDs, utxoMap)
+	if err != nil {
+		return nil, err
+	}
+
+	baseTx := wire.NewMsgTx(btc.txVersion())
+	var totalIn uint64
+	for _, utxo := range utxos {
+		baseTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(utxo.txHash, utxo.vout), []byte{}, nil))
+		totalIn += utxo.amount
+	}
+	if totalIn < value {
+		return nil, fmt.Errorf("selected coins worth %s are not enough to send %s",
+			amount(totalIn), amount(value))
+	}
+	baseTx.AddTxOut(txOut)
+
+	changeAddr, err := btc.node.changeAddress()
+	if err != nil {
+		return nil, fmt.Errorf("error creating change address: %w", err)
+	}
+	msgTx, _, fees, err := btc.signTxAndAddChange(baseTx, changeAddr, totalIn, value, feeRate)
+	if err != nil {
+		return nil, err
+	}
+	if err := btc.broadcastTx(msgTx); err != nil {
+		return nil, err
+	}
+
+	txHash := btc.hashTx(msgTx)
+	btc.logTx(&asset.WalletTransaction{
+		Type:      asset.Send,
+		ID:        txHash.String(),
+		Amount:    value,
+		Fees:      fees,
+		Timestamp: uint64(time.Now().Unix()),
+		Recipient: address,
+	})
+
+	btc.log.Infof("Sent %s %s to %s with %d selected coins in transaction %s",
+		amount(value), btc.symbol, address, len(utxos), txHash)
+
+	return newOutput(txHash, 0, value), nil
+}
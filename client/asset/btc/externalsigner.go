@@ -0,0 +1,475 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex/config"
+	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// externalSignerTimeout is how long to wait for the external signer to respond
+// to a signing request. External signers may require manual approval.
+const externalSignerTimeout = 5 * time.Minute
+
+// errNoPrivateKeys is returned when a private key is requested from a wallet
+// whose keys are held by an external signer.
+var errNoPrivateKeys = errors.New("private keys are held by the external signer")
+
+// externalSigner is satisfied by a Wallet that holds no private keys. Contract
+// inputs and messages are signed by the external signer instead of with keys
+// from privKeyForAddress.
+type externalSigner interface {
+	// signWitnessInput signs the P2WSH input spending the script with the key
+	// for the address, returning the signature and the compressed pubkey.
+	signWitnessInput(tx *wire.MsgTx, idx int, script []byte, addr string, val int64) (sig, pubkey []byte, err error)
+	// signMessage signs the message with the key for the address, returning
+	// the compressed pubkey and the signature.
+	signMessage(addr string, msg []byte) (pubkey, sig []byte, err error)
+}
+
+// externalSignerClient is an rpcClient for a watch-only bitcoind descriptor
+// wallet. Transactions are exported as PSBTs and signed by an external signer.
+type externalSignerClient struct {
+	*rpcClient
+	signer           PSBTSigner
+	descriptor       string
+	changeDescriptor string
+}
+
+var _ Wallet = (*externalSignerClient)(nil)
+var _ externalSigner = (*externalSignerClient)(nil)
+
+// readExternalSignerConfig parses and validates the ExternalSignerConfig.
+func readExternalSignerConfig(settings map[string]string) (*ExternalSignerConfig, error) {
+	cfg := new(ExternalSignerConfig)
+	if err := config.Unmapify(settings, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing external signer config: %w", err)
+	}
+	if err := checkWatchOnlyDescriptor(cfg.Descriptor); err != nil {
+		return nil, fmt.Errorf("invalid descriptor: %w", err)
+	}
+	if err := checkWatchOnlyDescriptor(cfg.ChangeDescriptor); err != nil {
+		return nil, fmt.Errorf("invalid change descriptor: %w", err)
+	}
+	if (cfg.SignerSocket == "") == (cfg.SignerDir == "") {
+		return nil, errors.New("exactly one of a signer socket or a signer directory must be specified")
+	}
+	return cfg, nil
+}
+
+// checkWatchOnlyDescriptor checks that the descriptor is a ranged P2WPKH
+// descriptor with an extended public key.
+func checkWatchOnlyDescriptor(descStr string) error {
+	if descStr == "" {
+		return errors.New("no descriptor")
+	}
+	desc, err := dexbtc.ParseDescriptor(descStr)
+	if err != nil {
+		return err
+	}
+	if desc.Function != "wpkh" {
+		return fmt.Errorf("unsupported descriptor function %q. only wpkh is supported", desc.Function)
+	}
+	if desc.KeyFmt != dexbtc.KeyExtended {
+		return errors.New("descriptor key is not an extended key")
+	}
+	xKey, _, _, isRange, err := dexbtc.ParseKeyExtended(desc.Key)
+	if err != nil {
+		return err
+	}
+	defer xKey.Zero()
+	if xKey.IsPrivate() {
+		return errors.New("descriptor has a private key")
+	}
+	if !isRange {
+		return errors.New("descriptor is not ranged")
+	}
+	return nil
+}
+
+// sameDescriptor checks whether the descriptors are for the same addresses.
+// Key origins and checksums are not compared, and bitcoind may use a different
+// notation for hardened derivation steps.
+func sameDescriptor(a, b *dexbtc.Descriptor) bool {
+	if a.Function != b.Function || a.KeyFmt != dexbtc.KeyExtended || b.KeyFmt != dexbtc.KeyExtended {
+		return false
+	}
+	aKey, _, aPathStr, aRange, err := dexbtc.ParseKeyExtended(a.Key)
+	if err != nil {
+		return false
+	}
+	bKey, _, bPathStr, bRange, err := dexbtc.ParseKeyExtended(b.Key)
+	if err != nil {
+		return false
+	}
+	if aKey.String() != bKey.String() || aRange != bRange {
+		return false
+	}
+	aPath, _, err := dexbtc.ParsePath(aPathStr)
+	if err != nil {
+		return false
+	}
+	bPath, _, err := dexbtc.ParsePath(bPathStr)
+	if err != nil || len(aPath) != len(bPath) {
+		return false
+	}
+	for i := range aPath {
+		if aPath[i] != bPath[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newExternalSignerWallet creates a wallet backed by a watch-only bitcoind
+// wallet that signs with an external signer.
+func newExternalSignerWallet(cfg *BTCCloneCFG) (*ExchangeWalletAccelerator, error) {
+	signerCfg, err := readExternalSignerConfig(cfg.WalletCFG.Settings)
+	if err != nil {
+		return nil, err
+	}
+	rpcWallet, err := BTCCloneWallet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	signer := newFileSigner(signerCfg.SignerDir)
+	if signerCfg.SignerSocket != "" {
+		signer = newSocketSigner(signerCfg.SignerSocket)
+	}
+	rpcWallet.node = &externalSignerClient{
+		rpcClient:        rpcWallet.node.(*rpcClient),
+		signer:           signer,
+		descriptor:       signerCfg.Descriptor,
+		changeDescriptor: signerCfg.ChangeDescriptor,
+	}
+	return &ExchangeWalletAccelerator{rpcWallet}, nil
+}
+
+// connect connects to the wallet, checks that it is a watch-only descriptor
+// wallet, and imports the configured descriptors if necessary.
+func (wc *externalSignerClient) connect(ctx context.Context, wg *sync.WaitGroup) error {
+	if err := wc.rpcClient.connect(ctx, wg); err != nil {
+		return err
+	}
+	wi, err := wc.GetWalletInfo()
+	if err != nil {
+		return fmt.Errorf("getwalletinfo failure: %w", err)
+	}
+	if wi.PriveyKeysEnabled {
+		return fmt.Errorf("wallet %q has private keys enabled. a watch-only wallet is required", wi.WalletName)
+	}
+	if !wc.descriptors {
+		return fmt.Errorf("wallet %q is not a descriptor wallet", wi.WalletName)
+	}
+	return wc.importDescriptors()
+}
+
+// importDescriptors imports the receiving and change descriptors if the wallet
+// does not already have them.
+func (wc *externalSignerClient) importDescriptors() error {
+	walletDescs, err := wc.listDescriptors(false)
+	if err != nil {
+		return fmt.Errorf("listdescriptors RPC failure: %w", err)
+	}
+	var imports []*importDescriptorRequest
+	for _, d := range []struct {
+		desc     string
+		internal bool
+	}{{wc.descriptor, false}, {wc.changeDescriptor, true}} {
+		desc, err := dexbtc.ParseDescriptor(d.desc)
+		if err != nil {
+			return err // validated by readExternalSignerConfig
+		}
+		var found bool
+		for _, wd := range walletDescs.Descriptors {
+			walletDesc, err := dexbtc.ParseDescriptor(wd.Descriptor)
+			if err != nil {
+				wc.log.Debugf("Failed to parse wallet descriptor %q: %v", wd.Descriptor, err)
+				continue
+			}
+			if sameDescriptor(desc, walletDesc) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		// importdescriptors requires the checksum.
+		info := new(getDescriptorInfoResult)
+		if err := wc.call(methodGetDescriptorInfo, anylist{d.desc}, info); err != nil {
+			return fmt.Errorf("getdescriptorinfo RPC failure: %w", err)
+		}
+		imports = append(imports, &importDescriptorRequest{
+			Descriptor: info.Descriptor,
+			Active:     true,
+			Internal:   d.internal,
+			Timestamp:  "now",
+		})
+	}
+	if len(imports) == 0 {
+		return nil
+	}
+	var results []*importDescriptorResult
+	if err := wc.call(methodImportDescriptors, anylist{imports}, &results); err != nil {
+		return fmt.Errorf("importdescriptors RPC failure: %w", err)
+	}
+	for i, res := range results {
+		if !res.Success {
+			var msg string
+			if res.Error != nil {
+				msg = res.Error.Message
+			}
+			return fmt.Errorf("failed to import descriptor %s: %s", imports[i].Descriptor, msg)
+		}
+		wc.log.Infof("Imported descriptor %s", imports[i].Descriptor)
+	}
+	return nil
+}
+
+// signPSBT has the external signer sign the packet. The signer may only add
+// signatures, not change the transaction.
+func (wc *externalSignerClient) signPSBT(packet *psbt.Packet) (*psbt.Packet, error) {
+	txHash := packet.UnsignedTx.TxHash()
+	ctx, cancel := context.WithTimeout(wc.ctx, externalSignerTimeout)
+	defer cancel()
+	signed, err := wc.signer.SignPSBT(ctx, packet)
+	if err != nil {
+		return nil, err
+	}
+	if signed.UnsignedTx.TxHash() != txHash || len(signed.Inputs) != len(signed.UnsignedTx.TxIn) {
+		return nil, errors.New("external signer modified the transaction")
+	}
+	return signed, nil
+}
+
+// signAndExtract has the external signer sign the packet, then finalizes it
+// and extracts the signed transaction.
+func (wc *externalSignerClient) signAndExtract(packet *psbt.Packet) (*wire.MsgTx, error) {
+	signed, err := wc.signPSBT(packet)
+	if err != nil {
+		return nil, err
+	}
+	if err := psbt.MaybeFinalizeAll(signed); err != nil {
+		return nil, fmt.Errorf("error finalizing signed PSBT: %w", err)
+	}
+	return psbt.Extract(signed)
+}
+
+// signTx exports the transaction as a PSBT, has the watch-only wallet add the
+// input information, and has the external signer sign it.
+func (wc *externalSignerClient) signTx(inTx *wire.MsgTx) (*wire.MsgTx, error) {
+	packet, err := psbt.NewFromUnsignedTx(inTx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating PSBT: %w", err)
+	}
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding PSBT: %w", err)
+	}
+	res := new(walletPSBTResult)
+	// Arguments are the PSBT, sign, sighash type, and bip32derivs.
+	if err := wc.call(methodWalletProcessPSBT, anylist{b64, false, "ALL", true}, res); err != nil {
+		return nil, fmt.Errorf("walletprocesspsbt RPC failure: %w", err)
+	}
+	if packet, err = decodePSBT(res.PSBT); err != nil {
+		return nil, err
+	}
+	return wc.signAndExtract(packet)
+}
+
+// sendToAddress has the watch-only wallet fund a PSBT paying the address, and
+// has the external signer sign it. feeRate is in units of sats/byte.
+func (wc *externalSignerClient) sendToAddress(address string, value, feeRate uint64, subtract bool) (*chainhash.Hash, error) {
+	outputs := []map[string]float64{{address: btcutil.Amount(value).ToBTC()}}
+	opts := map[string]interface{}{
+		"feeRate":         float64(feeRate) / 1e5, // BTC/kvB
+		"includeWatching": true,
+	}
+	if subtract {
+		opts["subtractFeeFromOutputs"] = []int{0}
+	}
+	res := new(walletPSBTResult)
+	// Arguments are inputs, outputs, locktime, options, and bip32derivs.
+	err := wc.call(methodWalletCreateFundedPSBT, anylist{[]struct{}{}, outputs, 0, opts, true}, res)
+	if err != nil {
+		return nil, fmt.Errorf("walletcreatefundedpsbt RPC failure: %w", err)
+	}
+	packet, err := decodePSBT(res.PSBT)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := wc.signAndExtract(packet)
+	if err != nil {
+		return nil, err
+	}
+	return wc.sendRawTransaction(tx)
+}
+
+// addressDerivation gets the BIP32 derivation of the address' key from its
+// descriptor.
+func (wc *externalSignerClient) addressDerivation(addr string) (*psbt.Bip32Derivation, error) {
+	ai := new(GetAddressInfoResult)
+	if err := wc.call(methodGetAddressInfo, anylist{addr}, ai); err != nil {
+		return nil, fmt.Errorf("getaddressinfo RPC failure: %w", err)
+	}
+	desc, err := dexbtc.ParseDescriptor(ai.Descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor %q: %w", ai.Descriptor, err)
+	}
+	if desc.KeyOrigin == nil {
+		return nil, errors.New("address descriptor has no key origin")
+	}
+	if desc.KeyFmt != dexbtc.KeyHexPub {
+		return nil, fmt.Errorf("not a hexadecimal pubkey: %v", desc.Key)
+	}
+	pubKeyB, err := hex.DecodeString(desc.Key)
+	if err != nil {
+		return nil, fmt.Errorf("address pubkey not hexadecimal: %w", err)
+	}
+	pubKey, err := btcec.ParsePubKey(pubKeyB)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pubkey for address: %w", err)
+	}
+	fp, err := hex.DecodeString(desc.KeyOrigin.Fingerprint)
+	if err != nil || len(fp) != 4 {
+		return nil, fmt.Errorf("invalid key origin fingerprint %q", desc.KeyOrigin.Fingerprint)
+	}
+	return &psbt.Bip32Derivation{
+		PubKey:               pubKey.SerializeCompressed(),
+		MasterKeyFingerprint: binary.LittleEndian.Uint32(fp),
+		Bip32Path:            desc.KeyOrigin.Steps,
+	}, nil
+}
+
+// signWitnessInput exports the transaction as a PSBT with the P2WSH input's
+// script and the key derivation for the address, and has the external signer
+// sign the input. Part of the externalSigner interface.
+func (wc *externalSignerClient) signWitnessInput(tx *wire.MsgTx, idx int, script []byte, addr string, val int64) (sig, pubkey []byte, err error) {
+	deriv, err := wc.addressDerivation(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Other inputs may already be signed, but a PSBT's transaction must be
+	// unsigned. Witness data is not part of the signature hash.
+	unsignedTx := tx.Copy()
+	for _, txIn := range unsignedTx.TxIn {
+		txIn.SignatureScript = nil
+		txIn.Witness = nil
+	}
+	packet, err := psbt.NewFromUnsignedTx(unsignedTx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating PSBT: %w", err)
+	}
+	scriptAddr, err := scriptHashAddress(true, script, wc.chainParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkScript, err := txscript.PayToAddrScript(scriptAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	packet.Inputs[idx] = psbt.PInput{
+		WitnessUtxo:     wire.NewTxOut(val, pkScript),
+		WitnessScript:   script,
+		SighashType:     txscript.SigHashAll,
+		Bip32Derivation: []*psbt.Bip32Derivation{deriv},
+	}
+	signed, err := wc.signPSBT(packet)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, partialSig := range signed.Inputs[idx].PartialSigs {
+		if !bytes.Equal(partialSig.PubKey, deriv.PubKey) {
+			continue
+		}
+		sig = partialSig.Signature
+		if len(sig) == 0 || txscript.SigHashType(sig[len(sig)-1]) != txscript.SigHashAll {
+			return nil, nil, errors.New("external signer returned a signature with the wrong sighash type")
+		}
+		sigHashes := txscript.NewTxSigHashes(unsignedTx, new(txscript.CannedPrevOutputFetcher))
+		sigHash, err := txscript.CalcWitnessSigHash(script, sigHashes, txscript.SigHashAll, unsignedTx, idx, val)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkSignature(sigHash, sig[:len(sig)-1], deriv.PubKey); err != nil {
+			return nil, nil, err
+		}
+		return sig, deriv.PubKey, nil
+	}
+	return nil, nil, fmt.Errorf("external signer did not sign for address %s", addr)
+}
+
+// signMessage has the external signer sign the message with the key for the
+// address. Part of the externalSigner interface.
+func (wc *externalSignerClient) signMessage(addr string, msg []byte) (pubkey, sig []byte, err error) {
+	deriv, err := wc.addressDerivation(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(wc.ctx, externalSignerTimeout)
+	defer cancel()
+	sig, err = wc.signer.SignMessage(ctx, deriv, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkSignature(chainhash.HashB(msg), sig, deriv.PubKey); err != nil {
+		return nil, nil, err
+	}
+	return deriv.PubKey, sig, nil
+}
+
+// checkSignature checks the DER-encoded signature of the hash by the pubkey.
+func checkSignature(hash, sigB, pubKeyB []byte) error {
+	sig, err := ecdsa.ParseDERSignature(sigB)
+	if err != nil {
+		return fmt.Errorf("external signer returned an invalid signature: %w", err)
+	}
+	pubKey, err := btcec.ParsePubKey(pubKeyB)
+	if err != nil {
+		return err
+	}
+	if !sig.Verify(hash, pubKey) {
+		return errors.New("external signer returned an incorrect signature")
+	}
+	return nil
+}
+
+// privKeyForAddress always fails. The private keys are held by the external
+// signer.
+func (wc *externalSignerClient) privKeyForAddress(addr string) (*btcec.PrivateKey, error) {
+	return nil, errNoPrivateKeys
+}
+
+// walletUnlock is a no-op. The watch-only wallet has no keys to unlock.
+func (wc *externalSignerClient) walletUnlock(pw []byte) error {
+	return nil
+}
+
+// walletLock is a no-op. The watch-only wallet has no keys to lock.
+func (wc *externalSignerClient) walletLock() error {
+	return nil
+}
+
+// locked is always false. The external signer is responsible for protecting
+// the keys.
+func (wc *externalSignerClient) locked() bool {
+	return false
+}
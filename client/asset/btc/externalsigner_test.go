@@ -0,0 +1,396 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package btc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var tAcctPath = []uint32{84 + hdkeychain.HardenedKeyStart, hdkeychain.HardenedKeyStart, hdkeychain.HardenedKeyStart}
+
+// tExternalKey is a key of the test signer's master key.
+type tExternalKey struct {
+	deriv *psbt.Bip32Derivation
+	addr  btcutil.Address
+	desc  string
+}
+
+func tDeriveExternalKey(t *testing.T, master *hdkeychain.ExtendedKey, branch, idx uint32) *tExternalKey {
+	t.Helper()
+	path := append(append([]uint32{}, tAcctPath...), branch, idx)
+	child, err := dexbtc.DeepChild(master, path)
+	if err != nil {
+		t.Fatalf("DeepChild error: %v", err)
+	}
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		t.Fatalf("ECPubKey error: %v", err)
+	}
+	pkBytes := pubKey.SerializeCompressed()
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pkBytes), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessPubKeyHash error: %v", err)
+	}
+	masterPub, _ := master.ECPubKey()
+	fp := btcutil.Hash160(masterPub.SerializeCompressed())[:4]
+	return &tExternalKey{
+		deriv: &psbt.Bip32Derivation{
+			PubKey:               pkBytes,
+			MasterKeyFingerprint: keyFingerprint(masterPub),
+			Bip32Path:            path,
+		},
+		addr: addr,
+		desc: fmt.Sprintf("wpkh([%x/84'/0'/0'/%d/%d]%x)", fp, branch, idx, pkBytes),
+	}
+}
+
+// tExternalSignerRequester handles the RPCs that are specific to the watch-only
+// wallet, and passes the rest to the tRawRequester.
+type tExternalSignerRequester struct {
+	*tRawRequester
+	keys     map[string]*tExternalKey // by address
+	prevOuts map[wire.OutPoint]*wire.TxOut
+}
+
+func (r *tExternalSignerRequester) RawRequest(ctx context.Context, method string, params []json.RawMessage) (json.RawMessage, error) {
+	switch method {
+	case methodGetAddressInfo:
+		var addr string
+		if err := json.Unmarshal(params[0], &addr); err != nil {
+			return nil, err
+		}
+		k := r.keys[addr]
+		if k == nil {
+			return json.Marshal(&GetAddressInfoResult{})
+		}
+		return json.Marshal(&GetAddressInfoResult{IsMine: true, Descriptor: k.desc})
+	case methodWalletProcessPSBT:
+		var b64 string
+		if err := json.Unmarshal(params[0], &b64); err != nil {
+			return nil, err
+		}
+		packet, err := decodePSBT(b64)
+		if err != nil {
+			return nil, err
+		}
+		for i, txIn := range packet.UnsignedTx.TxIn {
+			txOut := r.prevOuts[txIn.PreviousOutPoint]
+			if txOut == nil {
+				continue
+			}
+			packet.Inputs[i].WitnessUtxo = txOut
+			_, addrs, _, _ := txscript.ExtractPkScriptAddrs(txOut.PkScript, &chaincfg.MainNetParams)
+			if k := r.keys[addrs[0].String()]; k != nil {
+				packet.Inputs[i].Bip32Derivation = []*psbt.Bip32Derivation{k.deriv}
+			}
+		}
+		b64, err = packet.B64Encode()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(&walletPSBTResult{PSBT: b64})
+	}
+	return r.tRawRequester.RawRequest(ctx, method, params)
+}
+
+func tNewExternalSignerWallet(t *testing.T, signer PSBTSigner, keys ...*tExternalKey) (*ExchangeWalletFullNode, *testData, *tExternalSignerRequester, func()) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+	req := &tExternalSignerRequester{
+		tRawRequester: &tRawRequester{node},
+		keys:          make(map[string]*tExternalKey),
+		prevOuts:      make(map[wire.OutPoint]*wire.TxOut),
+	}
+	for _, k := range keys {
+		req.keys[k.addr.String()] = k
+	}
+	cl := wallet.node.(*rpcClient)
+	cl.requesterV = atomic.Value{} // different requester type
+	cl.requesterV.Store(req)
+	cl.ctx = tCtx
+	wallet.node = &externalSignerClient{rpcClient: cl, signer: signer}
+	return wallet, node, req, shutdown
+}
+
+func tNewLocalSigner(t *testing.T) (*LocalSigner, *hdkeychain.ExtendedKey) {
+	t.Helper()
+	seed := sha256.Sum256([]byte("external signer test seed"))
+	master, err := hdkeychain.NewMaster(seed[:], &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewMaster error: %v", err)
+	}
+	signer, err := NewLocalSigner(master)
+	if err != nil {
+		t.Fatalf("NewLocalSigner error: %v", err)
+	}
+	return signer, master
+}
+
+// tVerifyInput checks the input's signature data with the script engine.
+func tVerifyInput(t *testing.T, tx *wire.MsgTx, idx int, prevOut *wire.TxOut) {
+	t.Helper()
+	fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	vm, err := txscript.NewEngine(prevOut.PkScript, tx, idx, txscript.StandardVerifyFlags,
+		nil, sigHashes, prevOut.Value, fetcher)
+	if err != nil {
+		t.Fatalf("NewEngine error: %v", err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatalf("input %d failed verification: %v", idx, err)
+	}
+}
+
+func TestExternalSignerRedeemRefund(t *testing.T) {
+	signer, master := tNewLocalSigner(t)
+	recipient := tDeriveExternalKey(t, master, 0, 0)
+	sender := tDeriveExternalKey(t, master, 0, 1)
+	wallet, node, _, shutdown := tNewExternalSignerWallet(t, signer, recipient, sender)
+	defer shutdown()
+	node.changeAddr = tP2WPKHAddr
+
+	secret := randBytes(32)
+	secretHash := sha256.Sum256(secret)
+	lockTime := time.Now().Add(time.Hour)
+	contract, err := dexbtc.MakeContract(recipient.addr, sender.addr, secretHash[:], lockTime.Unix(), true, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("MakeContract error: %v", err)
+	}
+	contractAddr, _ := scriptHashAddress(true, contract, &chaincfg.MainNetParams)
+	pkScript, _ := txscript.PayToAddrScript(contractAddr)
+	const swapVal = 1e8
+	prevOut := wire.NewTxOut(swapVal, pkScript)
+
+	// Redeem
+	redemptions := &asset.RedeemForm{
+		Redemptions: []*asset.Redemption{{
+			Spends: &asset.AuditInfo{
+				Coin:       newOutput(tTxHash, 0, swapVal),
+				Contract:   contract,
+				Recipient:  recipient.addr.String(),
+				Expiration: lockTime,
+			},
+			Secret: secret,
+		}},
+	}
+	if _, _, _, err := wallet.Redeem(redemptions); err != nil {
+		t.Fatalf("redeem error: %v", err)
+	}
+	tVerifyInput(t, node.sentRawTx, 0, prevOut)
+
+	// Refund
+	tx := makeRawTx([]dex.Bytes{pkScript}, []*wire.TxIn{dummyInput()})
+	tx.TxOut[0].Value = swapVal
+	txHash := tx.TxHash()
+	node.addRawTx(1, tx)
+	node.txOutRes = newTxOutResult(pkScript, swapVal, 1)
+	if _, err := wallet.Refund(toCoinID(&txHash, 0), contract, feeSuggestion); err != nil {
+		t.Fatalf("refund error: %v", err)
+	}
+	tVerifyInput(t, node.sentRawTx, 0, prevOut)
+
+	// The signer doesn't have the key for an unknown address.
+	otherSigner, _ := NewLocalSigner(func() *hdkeychain.ExtendedKey {
+		k, _ := hdkeychain.NewMaster(randBytes(32), &chaincfg.MainNetParams)
+		return k
+	}())
+	wallet.node.(*externalSignerClient).signer = otherSigner
+	if _, _, _, err := wallet.Redeem(redemptions); err == nil {
+		t.Fatalf("no error for redeem without the signer's key")
+	}
+}
+
+func TestExternalSignerSignTx(t *testing.T) {
+	signer, master := tNewLocalSigner(t)
+	key := tDeriveExternalKey(t, master, 0, 2)
+	wallet, _, req, shutdown := tNewExternalSignerWallet(t, signer, key)
+	defer shutdown()
+
+	pkScript, _ := txscript.PayToAddrScript(key.addr)
+	prevOut := wire.NewTxOut(5e7, pkScript)
+	prevPt := wire.OutPoint{Hash: *tTxHash, Index: 1}
+	req.prevOuts[prevPt] = prevOut
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&prevPt, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(4e7, pkScript))
+	signedTx, err := wallet.node.signTx(tx)
+	if err != nil {
+		t.Fatalf("signTx error: %v", err)
+	}
+	tVerifyInput(t, signedTx, 0, prevOut)
+
+	// An input the wallet doesn't know about can't be finalized.
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(tTxHash, 2), nil, nil))
+	if _, err = wallet.node.signTx(tx); err == nil {
+		t.Fatalf("no error for unknown input")
+	}
+
+	// Private keys are never available.
+	if _, err := wallet.node.privKeyForAddress(key.addr.String()); err == nil {
+		t.Fatalf("no error for privKeyForAddress")
+	}
+
+	// Messages are signed by the signer.
+	op := newOutput(tTxHash, 1, 5e7)
+	wallet.fundingCoins[op.pt] = &utxo{
+		txHash:  op.txHash(),
+		vout:    op.vout(),
+		address: key.addr.String(),
+		amount:  op.value,
+	}
+	msg := randBytes(36)
+	pubkeys, sigs, err := wallet.SignMessage(op, msg)
+	if err != nil {
+		t.Fatalf("SignMessage error: %v", err)
+	}
+	if len(pubkeys) != 1 || len(sigs) != 1 {
+		t.Fatalf("expected 1 pubkey and signature, got %d, %d", len(pubkeys), len(sigs))
+	}
+	if err := checkSignature(chainhash.HashB(msg), sigs[0], pubkeys[0]); err != nil {
+		t.Fatalf("bad message signature: %v", err)
+	}
+}
+
+func TestSignerTransports(t *testing.T) {
+	signer, master := tNewLocalSigner(t)
+	key := tDeriveExternalKey(t, master, 1, 0)
+	defer func(d time.Duration) { fileSignerPollInterval = d }(fileSignerPollInterval)
+	fileSignerPollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(tCtx, 10*time.Second)
+	defer cancel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen error: %v", err)
+	}
+	go ServeSignerSocket(ctx, ln, signer)
+	dir := t.TempDir()
+	go ServeSignerDir(ctx, dir, signer)
+
+	pkScript, _ := txscript.PayToAddrScript(key.addr)
+	prevOut := wire.NewTxOut(5e7, pkScript)
+
+	for name, remote := range map[string]PSBTSigner{
+		"socket": newSocketSigner(ln.Addr().String()),
+		"file":   newFileSigner(dir),
+	} {
+		msg := randBytes(20)
+		sig, err := remote.SignMessage(ctx, key.deriv, msg)
+		if err != nil {
+			t.Fatalf("%s: SignMessage error: %v", name, err)
+		}
+		if err := checkSignature(chainhash.HashB(msg), sig, key.deriv.PubKey); err != nil {
+			t.Fatalf("%s: bad message signature: %v", name, err)
+		}
+
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(tTxHash, 0), nil, nil))
+		tx.AddTxOut(wire.NewTxOut(4e7, pkScript))
+		packet, _ := psbt.NewFromUnsignedTx(tx)
+		packet.Inputs[0].WitnessUtxo = prevOut
+		packet.Inputs[0].Bip32Derivation = []*psbt.Bip32Derivation{key.deriv}
+		signed, err := remote.SignPSBT(ctx, packet)
+		if err != nil {
+			t.Fatalf("%s: SignPSBT error: %v", name, err)
+		}
+		if err := psbt.MaybeFinalizeAll(signed); err != nil {
+			t.Fatalf("%s: MaybeFinalizeAll error: %v", name, err)
+		}
+		signedTx, err := psbt.Extract(signed)
+		if err != nil {
+			t.Fatalf("%s: Extract error: %v", name, err)
+		}
+		tVerifyInput(t, signedTx, 0, prevOut)
+
+		// Signer errors are returned.
+		badDeriv := *key.deriv
+		badDeriv.PubKey = tDeriveExternalKey(t, master, 1, 1).deriv.PubKey
+		if _, err := remote.SignMessage(ctx, &badDeriv, msg); err == nil || !strings.Contains(err.Error(), "unknown key") {
+			t.Fatalf("%s: wrong error for wrong pubkey: %v", name, err)
+		}
+	}
+
+	// No response from the file signer.
+	shortCtx, shortCancel := context.WithTimeout(tCtx, 50*time.Millisecond)
+	defer shortCancel()
+	if _, err := newFileSigner(filepath.Join(dir, "nobody")).SignMessage(shortCtx, key.deriv, nil); err == nil {
+		t.Fatalf("no error for unresponsive signer")
+	}
+}
+
+func TestReadExternalSignerConfig(t *testing.T) {
+	_, master := tNewLocalSigner(t)
+	acct, _ := dexbtc.DeepChild(master, tAcctPath)
+	xpub, _ := acct.Neuter()
+	fp := hex.EncodeToString(btcutil.Hash160(func() []byte {
+		pk, _ := master.ECPubKey()
+		return pk.SerializeCompressed()
+	}())[:4])
+	desc := fmt.Sprintf("wpkh([%s/84'/0'/0']%s/0/*)", fp, xpub)
+	changeDesc := fmt.Sprintf("wpkh([%s/84h/0h/0h]%s/1/*)", fp, xpub)
+
+	settings := func(desc, changeDesc, socket, dir string) map[string]string {
+		return map[string]string{
+			"descriptor":       desc,
+			"changedescriptor": changeDesc,
+			"signersocket":     socket,
+			"signerdir":        dir,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		settings map[string]string
+		wantErr  bool
+	}{
+		{"ok socket", settings(desc, changeDesc, "unix:/tmp/signer.sock", ""), false},
+		{"ok dir", settings(desc, changeDesc, "", "/tmp/signer"), false},
+		{"no signer", settings(desc, changeDesc, "", ""), true},
+		{"two signers", settings(desc, changeDesc, "127.0.0.1:9999", "/tmp/signer"), true},
+		{"no change descriptor", settings(desc, "", "", "/tmp/signer"), true},
+		{"private key", settings(fmt.Sprintf("wpkh(%s/0/*)", acct), changeDesc, "", "/tmp/signer"), true},
+		{"not ranged", settings(fmt.Sprintf("wpkh(%s/0/0)", xpub), changeDesc, "", "/tmp/signer"), true},
+		{"not wpkh", settings(fmt.Sprintf("pkh(%s/0/*)", xpub), changeDesc, "", "/tmp/signer"), true},
+	}
+	for _, tt := range tests {
+		_, err := readExternalSignerConfig(tt.settings)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: wantErr = %t, err = %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	// Hardened step notation and key origins don't matter when comparing
+	// descriptors.
+	a, _ := dexbtc.ParseDescriptor(fmt.Sprintf("wpkh(%s/1/*)", xpub))
+	b, _ := dexbtc.ParseDescriptor(changeDesc)
+	if !sameDescriptor(a, b) {
+		t.Fatalf("descriptors not the same")
+	}
+	c, _ := dexbtc.ParseDescriptor(desc)
+	if sameDescriptor(a, c) {
+		t.Fatalf("different branches are the same")
+	}
+}
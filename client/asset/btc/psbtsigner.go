@@ -0,0 +1,441 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	signerRequestExt  = ".request.json"
+	signerResponseExt = ".response.json"
+)
+
+// fileSignerPollInterval is how often the file exchange directory is checked
+// for new requests or responses.
+var fileSignerPollInterval = 500 * time.Millisecond
+
+// PSBTSigner signs with keys that are held outside of the wallet. The signer
+// identifies the keys it controls by the BIP32 derivations of the PSBT inputs,
+// adding a partial signature for each input it can sign.
+type PSBTSigner interface {
+	// SignPSBT returns the packet with partial signatures added.
+	SignPSBT(ctx context.Context, packet *psbt.Packet) (*psbt.Packet, error)
+	// SignMessage returns a DER-encoded signature of the sha256 hash of the
+	// message by the key with the specified derivation.
+	SignMessage(ctx context.Context, deriv *psbt.Bip32Derivation, msg []byte) ([]byte, error)
+}
+
+// signerDerivation is the JSON encoding of a psbt.Bip32Derivation.
+type signerDerivation struct {
+	PubKey      dex.Bytes `json:"pubkey"`
+	Fingerprint uint32    `json:"fingerprint"`
+	Path        []uint32  `json:"path"`
+}
+
+// signerRequest is a request to an external signer. A request has either a
+// base64-encoded PSBT to sign, or a message and the derivation of the key to
+// sign it with.
+type signerRequest struct {
+	ID         string            `json:"id"`
+	PSBT       string            `json:"psbt,omitempty"`
+	Message    dex.Bytes         `json:"message,omitempty"`
+	Derivation *signerDerivation `json:"derivation,omitempty"`
+}
+
+// signerResponse is the external signer's response to a signerRequest.
+type signerResponse struct {
+	ID        string    `json:"id"`
+	PSBT      string    `json:"psbt,omitempty"`
+	Signature dex.Bytes `json:"signature,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// remoteSigner is a PSBTSigner that forwards requests to a signer in another
+// process.
+type remoteSigner struct {
+	roundTrip func(ctx context.Context, req *signerRequest) (*signerResponse, error)
+}
+
+var _ PSBTSigner = (*remoteSigner)(nil)
+
+// newSocketSigner creates a signer that connects to the external signer at
+// the specified address. An address prefixed with "unix:" is a unix socket
+// path. Otherwise, the address is a TCP host:port.
+func newSocketSigner(addr string) *remoteSigner {
+	network := "tcp"
+	if strings.HasPrefix(addr, "unix:") {
+		network, addr = "unix", strings.TrimPrefix(addr, "unix:")
+	}
+	return &remoteSigner{
+		roundTrip: func(ctx context.Context, req *signerRequest) (*signerResponse, error) {
+			return socketRoundTrip(ctx, network, addr, req)
+		},
+	}
+}
+
+// newFileSigner creates a signer that exchanges request and response files
+// with the external signer in the specified directory.
+func newFileSigner(dir string) *remoteSigner {
+	return &remoteSigner{
+		roundTrip: func(ctx context.Context, req *signerRequest) (*signerResponse, error) {
+			return fileRoundTrip(ctx, dir, req)
+		},
+	}
+}
+
+func (s *remoteSigner) request(ctx context.Context, req *signerRequest) (*signerResponse, error) {
+	req.ID = hex.EncodeToString(encode.RandomBytes(8))
+	resp, err := s.roundTrip(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ID != req.ID {
+		return nil, fmt.Errorf("external signer response ID %q does not match request ID %q", resp.ID, req.ID)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("external signer error: %s", resp.Error)
+	}
+	return resp, nil
+}
+
+// SignPSBT sends the packet to the external signer. Part of the PSBTSigner
+// interface.
+func (s *remoteSigner) SignPSBT(ctx context.Context, packet *psbt.Packet) (*psbt.Packet, error) {
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding PSBT: %w", err)
+	}
+	resp, err := s.request(ctx, &signerRequest{PSBT: b64})
+	if err != nil {
+		return nil, err
+	}
+	return decodePSBT(resp.PSBT)
+}
+
+// SignMessage sends the message to the external signer. Part of the
+// PSBTSigner interface.
+func (s *remoteSigner) SignMessage(ctx context.Context, deriv *psbt.Bip32Derivation, msg []byte) ([]byte, error) {
+	resp, err := s.request(ctx, &signerRequest{
+		Message: msg,
+		Derivation: &signerDerivation{
+			PubKey:      deriv.PubKey,
+			Fingerprint: deriv.MasterKeyFingerprint,
+			Path:        deriv.Bip32Path,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// socketRoundTrip sends a single request over a new connection and reads the
+// response. Requests and responses are newline-terminated JSON.
+func socketRoundTrip(ctx context.Context, network, addr string, req *signerRequest) (*signerResponse, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to external signer: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("error sending request to external signer: %w", err)
+	}
+	resp := new(signerResponse)
+	if err := json.NewDecoder(conn).Decode(resp); err != nil {
+		return nil, fmt.Errorf("error reading external signer response: %w", err)
+	}
+	return resp, nil
+}
+
+// fileRoundTrip writes the request to {id}.request.json in dir, and waits for
+// the signer to write {id}.response.json.
+func fileRoundTrip(ctx context.Context, dir string, req *signerRequest) (*signerResponse, error) {
+	reqPath := filepath.Join(dir, req.ID+signerRequestExt)
+	respPath := filepath.Join(dir, req.ID+signerResponseExt)
+	if err := writeJSONFile(reqPath, req); err != nil {
+		return nil, fmt.Errorf("error writing external signer request: %w", err)
+	}
+	// The signer should remove the request when it picks it up, but don't
+	// leave it behind if the signer never does.
+	defer os.Remove(reqPath)
+
+	ticker := time.NewTicker(fileSignerPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b, err := os.ReadFile(respPath)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error reading external signer response: %w", err)
+			}
+			os.Remove(respPath)
+			resp := new(signerResponse)
+			if err := json.Unmarshal(b, resp); err != nil {
+				return nil, fmt.Errorf("error decoding external signer response: %w", err)
+			}
+			return resp, nil
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no response from external signer: %w", ctx.Err())
+		}
+	}
+}
+
+// writeJSONFile writes the thing to a temporary file first and renames it so
+// that the other side never reads a partially written file.
+func writeJSONFile(path string, thing interface{}) error {
+	b, err := json.Marshal(thing)
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func decodePSBT(b64 string) (*psbt.Packet, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(b64), true)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding PSBT: %w", err)
+	}
+	return packet, nil
+}
+
+// handleSignerRequest performs the signing requested by a remote wallet.
+func handleSignerRequest(ctx context.Context, signer PSBTSigner, req *signerRequest) *signerResponse {
+	resp := &signerResponse{ID: req.ID}
+	switch {
+	case req.PSBT != "":
+		packet, err := decodePSBT(req.PSBT)
+		if err != nil {
+			resp.Error = err.Error()
+			return resp
+		}
+		if packet, err = signer.SignPSBT(ctx, packet); err != nil {
+			resp.Error = err.Error()
+			return resp
+		}
+		if resp.PSBT, err = packet.B64Encode(); err != nil {
+			resp.Error = err.Error()
+		}
+	case req.Derivation != nil:
+		sig, err := signer.SignMessage(ctx, &psbt.Bip32Derivation{
+			PubKey:               req.Derivation.PubKey,
+			MasterKeyFingerprint: req.Derivation.Fingerprint,
+			Bip32Path:            req.Derivation.Path,
+		}, req.Message)
+		if err != nil {
+			resp.Error = err.Error()
+			return resp
+		}
+		resp.Signature = sig
+	default:
+		resp.Error = "nothing to sign"
+	}
+	return resp
+}
+
+// ServeSignerSocket accepts connections from external signer wallets on the
+// listener and signs their requests with the signer. ServeSignerSocket blocks
+// until the context is canceled or the listener fails.
+func ServeSignerSocket(ctx context.Context, ln net.Listener, signer PSBTSigner) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			req := new(signerRequest)
+			if err := json.NewDecoder(conn).Decode(req); err != nil {
+				return
+			}
+			json.NewEncoder(conn).Encode(handleSignerRequest(ctx, signer, req))
+		}()
+	}
+}
+
+// ServeSignerDir watches the directory for request files from an external
+// signer wallet, and writes a response file for each. ServeSignerDir blocks
+// until the context is canceled.
+func ServeSignerDir(ctx context.Context, dir string, signer PSBTSigner) error {
+	ticker := time.NewTicker(fileSignerPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+		reqPaths, err := filepath.Glob(filepath.Join(dir, "*"+signerRequestExt))
+		if err != nil {
+			return err
+		}
+		for _, reqPath := range reqPaths {
+			b, err := os.ReadFile(reqPath)
+			if err != nil {
+				continue // removed by the wallet
+			}
+			os.Remove(reqPath)
+			req := new(signerRequest)
+			if err := json.Unmarshal(b, req); err != nil || req.ID == "" {
+				continue
+			}
+			resp := handleSignerRequest(ctx, signer, req)
+			if err := writeJSONFile(filepath.Join(dir, req.ID+signerResponseExt), resp); err != nil {
+				return fmt.Errorf("error writing response: %w", err)
+			}
+		}
+	}
+}
+
+// LocalSigner is a PSBTSigner that holds the master extended private key
+// in-process. It can stand in for an external signing device, e.g. for
+// testing, and may be served to external signer wallets with ServeSignerSocket
+// or ServeSignerDir.
+type LocalSigner struct {
+	master      *hdkeychain.ExtendedKey
+	fingerprint uint32
+}
+
+var _ PSBTSigner = (*LocalSigner)(nil)
+
+// NewLocalSigner is the constructor for a LocalSigner.
+func NewLocalSigner(master *hdkeychain.ExtendedKey) (*LocalSigner, error) {
+	if !master.IsPrivate() {
+		return nil, hdkeychain.ErrNotPrivExtKey
+	}
+	pubKey, err := master.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return &LocalSigner{
+		master:      master,
+		fingerprint: keyFingerprint(pubKey),
+	}, nil
+}
+
+// keyFingerprint is the BIP32 fingerprint of the key, as a uint32 in the byte
+// order used for psbt.Bip32Derivation.MasterKeyFingerprint.
+func keyFingerprint(pubKey *btcec.PublicKey) uint32 {
+	return binary.LittleEndian.Uint32(btcutil.Hash160(pubKey.SerializeCompressed())[:4])
+}
+
+// privKey derives the private key for the derivation. If the derivation is not
+// from this signer's master key, a nil key is returned without an error.
+func (s *LocalSigner) privKey(deriv *psbt.Bip32Derivation) (*btcec.PrivateKey, error) {
+	if deriv.MasterKeyFingerprint != s.fingerprint {
+		return nil, nil
+	}
+	child, err := dexbtc.DeepChild(s.master, deriv.Bip32Path)
+	if err != nil {
+		return nil, err
+	}
+	if child != s.master {
+		defer child.Zero()
+	}
+	privKey, err := child.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(privKey.PubKey().SerializeCompressed(), deriv.PubKey) {
+		privKey.Zero()
+		return nil, nil // fingerprint collision
+	}
+	return privKey, nil
+}
+
+// SignPSBT adds a signature for every segwit input with a derivation from the
+// master key. Part of the PSBTSigner interface.
+func (s *LocalSigner) SignPSBT(_ context.Context, packet *psbt.Packet) (*psbt.Packet, error) {
+	tx := packet.UnsignedTx
+	sigHashes := txscript.NewTxSigHashes(tx, new(txscript.CannedPrevOutputFetcher))
+	for i := range packet.Inputs {
+		in := &packet.Inputs[i]
+		for _, deriv := range in.Bip32Derivation {
+			privKey, err := s.privKey(deriv)
+			if err != nil {
+				return nil, fmt.Errorf("error deriving key for input %d: %w", i, err)
+			}
+			if privKey == nil {
+				continue
+			}
+			if in.WitnessUtxo == nil {
+				privKey.Zero()
+				return nil, fmt.Errorf("input %d is not a segwit input", i)
+			}
+			if in.SighashType != 0 && in.SighashType != txscript.SigHashAll {
+				privKey.Zero()
+				return nil, fmt.Errorf("unsupported sighash type %d for input %d", in.SighashType, i)
+			}
+			script := in.WitnessScript
+			if len(script) == 0 {
+				script = in.WitnessUtxo.PkScript // P2WPKH
+			}
+			sig, err := txscript.RawTxInWitnessSignature(tx, sigHashes, i, in.WitnessUtxo.Value,
+				script, txscript.SigHashAll, privKey)
+			privKey.Zero()
+			if err != nil {
+				return nil, fmt.Errorf("error signing input %d: %w", i, err)
+			}
+			in.PartialSigs = append(in.PartialSigs, &psbt.PartialSig{
+				PubKey:    deriv.PubKey,
+				Signature: sig,
+			})
+		}
+	}
+	return packet, nil
+}
+
+// SignMessage signs the message with the key for the derivation. Part of the
+// PSBTSigner interface.
+func (s *LocalSigner) SignMessage(_ context.Context, deriv *psbt.Bip32Derivation, msg []byte) ([]byte, error) {
+	privKey, err := s.privKey(deriv)
+	if err != nil {
+		return nil, err
+	}
+	if privKey == nil {
+		return nil, errors.New("unknown key")
+	}
+	defer privKey.Zero()
+	return ecdsa.Sign(privKey, chainhash.HashB(msg)).Serialize(), nil
+}
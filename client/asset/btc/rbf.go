@@ -0,0 +1,386 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package btc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// rbfSequence is the input sequence used for redemption and refund
+	// transactions. Any sequence less than wire.MaxTxInSequenceNum - 1 signals
+	// that the transaction may be replaced by one paying a higher fee.
+	//
+	// https://github.com/bitcoin/bips/blob/master/bip-0125.mediawiki#Summary
+	//
+	// The sequence is not final, so the lock time of a refund is enforced.
+	rbfSequence = wire.MaxTxInSequenceNum - 2
+	// incrementalRelayFeeRate is the minimum amount, in atoms/vbyte, by which
+	// a replacement must increase the fee rate. This is Bitcoin Core's
+	// default -incrementalrelayfee.
+	incrementalRelayFeeRate = 1
+	// spendTxExpiry is how long the information needed to replace a
+	// redemption or refund transaction is kept. This is longer than any swap
+	// lock time.
+	spendTxExpiry = 7 * 24 * time.Hour
+)
+
+// spendTx is a redemption or refund transaction sent by the wallet, along with
+// the information needed to sign a replacement.
+type spendTx struct {
+	msgTx     *wire.MsgTx
+	contracts [][]byte
+	values    []int64
+	// secrets are the secrets of the redeemed contracts. secrets is nil for a
+	// refund.
+	secrets [][]byte
+	// size is the estimated size, in vbytes, used to calculate the fees.
+	size  uint64
+	fees  uint64
+	stamp time.Time
+}
+
+func (st *spendTx) feeRate() uint64 {
+	return st.fees / st.size
+}
+
+// spendTxRecord is the tx log encoding of a spendTx.
+type spendTxRecord struct {
+	Tx        dex.Bytes   `json:"tx"`
+	Contracts []dex.Bytes `json:"contracts"`
+	Values    []int64     `json:"values"`
+	Secrets   []dex.Bytes `json:"secrets,omitempty"`
+	Size      uint64      `json:"size"`
+	Fees      uint64      `json:"fees"`
+	Stamp     int64       `json:"stamp"`
+}
+
+func bytesSlices(bs [][]byte) []dex.Bytes {
+	if bs == nil {
+		return nil
+	}
+	dbs := make([]dex.Bytes, 0, len(bs))
+	for _, b := range bs {
+		dbs = append(dbs, b)
+	}
+	return dbs
+}
+
+func byteSlices(dbs []dex.Bytes) [][]byte {
+	if dbs == nil {
+		return nil
+	}
+	bs := make([][]byte, 0, len(dbs))
+	for _, b := range dbs {
+		bs = append(bs, b)
+	}
+	return bs
+}
+
+// storeSpendTx saves the redemption or refund transaction so that its fees can
+// be bumped if it is slow to be mined. The transaction is also saved in the tx
+// log, if there is one, so that it can still be bumped after a restart.
+func (btc *baseWallet) storeSpendTx(txHash *chainhash.Hash, st *spendTx) {
+	btc.spendTxsMtx.Lock()
+	for h, oldTx := range btc.spendTxs {
+		if time.Since(oldTx.stamp) > spendTxExpiry {
+			delete(btc.spendTxs, h)
+		}
+	}
+	btc.spendTxs[*txHash] = st
+	btc.spendTxsMtx.Unlock()
+
+	btc.txLogMtx.RLock()
+	defer btc.txLogMtx.RUnlock()
+	if btc.txLog == nil {
+		return
+	}
+	txB, err := btc.serializeTx(st.msgTx)
+	if err != nil {
+		btc.log.Errorf("Error serializing transaction %s: %v", txHash, err)
+		return
+	}
+	b, err := json.Marshal(&spendTxRecord{
+		Tx:        txB,
+		Contracts: bytesSlices(st.contracts),
+		Values:    st.values,
+		Secrets:   bytesSlices(st.secrets),
+		Size:      st.size,
+		Fees:      st.fees,
+		Stamp:     st.stamp.Unix(),
+	})
+	if err != nil {
+		btc.log.Errorf("Error encoding transaction %s: %v", txHash, err)
+		return
+	}
+	if err := btc.txLog.StoreTxData(txHash.String(), b); err != nil {
+		btc.log.Errorf("Error storing replacement data for transaction %s: %v", txHash, err)
+	}
+}
+
+// loadSpendTx retrieves a redemption or refund transaction saved in the tx log
+// by storeSpendTx. nil is returned if there is no unexpired record of the
+// transaction.
+func (btc *baseWallet) loadSpendTx(txHash *chainhash.Hash) (*spendTx, error) {
+	btc.txLogMtx.RLock()
+	defer btc.txLogMtx.RUnlock()
+	if btc.txLog == nil {
+		return nil, nil
+	}
+	b, err := btc.txLog.TxData(txHash.String())
+	if err != nil {
+		if errors.Is(err, asset.ErrTxNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var rec spendTxRecord
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, fmt.Errorf("error decoding transaction: %w", err)
+	}
+	stamp := time.Unix(rec.Stamp, 0)
+	if time.Since(stamp) > spendTxExpiry {
+		return nil, nil
+	}
+	msgTx, err := btc.deserializeTx(rec.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("error deserializing transaction: %w", err)
+	}
+	return &spendTx{
+		msgTx:     msgTx,
+		contracts: byteSlices(rec.Contracts),
+		values:    rec.Values,
+		secrets:   byteSlices(rec.Secrets),
+		size:      rec.Size,
+		fees:      rec.Fees,
+		stamp:     stamp,
+	}, nil
+}
+
+// deleteSpendTx forgets a redemption or refund transaction that has been mined
+// or replaced.
+func (btc *baseWallet) deleteSpendTx(txHash *chainhash.Hash) {
+	btc.spendTxsMtx.Lock()
+	delete(btc.spendTxs, *txHash)
+	btc.spendTxsMtx.Unlock()
+
+	btc.txLogMtx.RLock()
+	defer btc.txLogMtx.RUnlock()
+	if btc.txLog == nil {
+		return
+	}
+	if err := btc.txLog.DeleteTxData(txHash.String()); err != nil {
+		btc.log.Errorf("Error deleting replacement data for transaction %s: %v", txHash, err)
+	}
+}
+
+// pendingSpendTx retrieves the stored redemption or refund transaction.
+// asset.ErrTxConfirmed is returned if the transaction has been mined.
+func (btc *baseWallet) pendingSpendTx(txHash *chainhash.Hash) (*spendTx, error) {
+	btc.spendTxsMtx.RLock()
+	st := btc.spendTxs[*txHash]
+	btc.spendTxsMtx.RUnlock()
+	if st == nil {
+		var err error
+		if st, err = btc.loadSpendTx(txHash); err != nil {
+			return nil, fmt.Errorf("error loading transaction %s from tx history: %w", txHash, err)
+		}
+		if st == nil {
+			return nil, fmt.Errorf("no record of redemption or refund transaction %s", txHash)
+		}
+		btc.spendTxsMtx.Lock()
+		btc.spendTxs[*txHash] = st
+		btc.spendTxsMtx.Unlock()
+	}
+	tx, err := btc.node.getWalletTransaction(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("error getting wallet transaction %s: %w", txHash, err)
+	}
+	if tx.Confirmations > 0 {
+		btc.deleteSpendTx(txHash)
+		return nil, asset.ErrTxConfirmed
+	}
+	return st, nil
+}
+
+// BumpableFeeRate returns the fee rate of the unconfirmed redemption or refund
+// transaction with the specified coin ID. Transactions sent before the wallet
+// was restarted can only be bumped if the wallet has a tx history database.
+// Part of the asset.FeeBumper interface.
+func (btc *ExchangeWalletAccelerator) BumpableFeeRate(coinID dex.Bytes) (uint64, error) {
+	return bumpableFeeRate(btc.baseWallet, coinID)
+}
+
+// BumpableFeeRate returns the fee rate of the unconfirmed redemption or refund
+// transaction with the specified coin ID. Transactions sent before the wallet
+// was restarted can only be bumped if the wallet has a tx history database.
+// Part of the asset.FeeBumper interface.
+func (btc *ExchangeWalletSPV) BumpableFeeRate(coinID dex.Bytes) (uint64, error) {
+	return bumpableFeeRate(btc.baseWallet, coinID)
+}
+
+func bumpableFeeRate(btc *baseWallet, coinID dex.Bytes) (uint64, error) {
+	txHash, _, err := decodeCoinID(coinID)
+	if err != nil {
+		return 0, err
+	}
+	st, err := btc.pendingSpendTx(txHash)
+	if err != nil {
+		return 0, err
+	}
+	return st.feeRate(), nil
+}
+
+// BumpFee replaces the unconfirmed redemption or refund transaction with the
+// specified coin ID with a transaction paying newFeeRate. Part of the
+// asset.FeeBumper interface.
+func (btc *ExchangeWalletAccelerator) BumpFee(coinID dex.Bytes, newFeeRate uint64) (map[string]dex.Bytes, uint64, error) {
+	return bumpFee(btc.baseWallet, coinID, newFeeRate)
+}
+
+// BumpFee replaces the unconfirmed redemption or refund transaction with the
+// specified coin ID with a transaction paying newFeeRate. Part of the
+// asset.FeeBumper interface.
+func (btc *ExchangeWalletSPV) BumpFee(coinID dex.Bytes, newFeeRate uint64) (map[string]dex.Bytes, uint64, error) {
+	return bumpFee(btc.baseWallet, coinID, newFeeRate)
+}
+
+func bumpFee(btc *baseWallet, coinID dex.Bytes, newFeeRate uint64) (map[string]dex.Bytes, uint64, error) {
+	txHash, _, err := decodeCoinID(coinID)
+	if err != nil {
+		return nil, 0, err
+	}
+	st, err := btc.pendingSpendTx(txHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	if newFeeRate <= st.feeRate() {
+		return nil, 0, fmt.Errorf("new fee rate %d must be higher than the current fee rate %d", newFeeRate, st.feeRate())
+	}
+	if limit := btc.feeRateLimit(); newFeeRate > limit {
+		return nil, 0, fmt.Errorf("new fee rate %d exceeds the fee rate limit %d", newFeeRate, limit)
+	}
+
+	// The replacement must pay at least the incremental relay fee for its own
+	// size in addition to the fees of the replaced transaction.
+	fees := newFeeRate * st.size
+	if minFees := st.fees + incrementalRelayFeeRate*st.size; fees < minFees {
+		fees = minFees
+	}
+	var totalIn uint64
+	for _, v := range st.values {
+		totalIn += uint64(v)
+	}
+	if fees >= totalIn {
+		return nil, 0, errors.New("replacement not worth the fees")
+	}
+
+	msgTx := st.msgTx.Copy()
+	for _, txIn := range msgTx.TxIn {
+		txIn.SignatureScript, txIn.Witness = nil, nil
+	}
+	msgTx.TxOut[0].Value = int64(totalIn - fees)
+	if btc.IsDust(msgTx.TxOut[0], newFeeRate) {
+		return nil, 0, errors.New("replacement output is dust")
+	}
+	if err := btc.signSpendTx(msgTx, st); err != nil {
+		return nil, 0, fmt.Errorf("error signing replacement transaction: %w", err)
+	}
+
+	checkHash := btc.hashTx(msgTx)
+	newHash, err := btc.node.sendRawTransaction(msgTx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error sending replacement transaction: %w", err)
+	}
+	if *newHash != *checkHash {
+		return nil, 0, fmt.Errorf("replacement sent, but received unexpected transaction ID back from RPC server. "+
+			"expected %s, got %s", *checkHash, *newHash)
+	}
+
+	btc.deleteSpendTx(txHash)
+	btc.storeSpendTx(newHash, &spendTx{
+		msgTx:     msgTx,
+		contracts: st.contracts,
+		values:    st.values,
+		secrets:   st.secrets,
+		size:      st.size,
+		fees:      fees,
+		stamp:     st.stamp,
+	})
+
+	btc.replaceLoggedTx(txHash, newHash, fees)
+
+	btc.log.Infof("Replaced %s transaction %s with %s, increasing the fee rate from %d to %d",
+		btc.symbol, txHash, newHash, st.feeRate(), fees/st.size)
+
+	replacements := make(map[string]dex.Bytes, len(msgTx.TxIn))
+	for i := range msgTx.TxIn {
+		replacements[dex.Bytes(toCoinID(txHash, uint32(i))).String()] = toCoinID(newHash, uint32(i))
+	}
+	return replacements, fees - st.fees, nil
+}
+
+// signSpendTx signs the inputs of a redemption or refund transaction.
+func (btc *baseWallet) signSpendTx(msgTx *wire.MsgTx, st *spendTx) error {
+	var sigHashes *txscript.TxSigHashes
+	var prevScripts [][]byte
+	if btc.segwit {
+		sigHashes = txscript.NewTxSigHashes(msgTx, new(txscript.CannedPrevOutputFetcher))
+	} else {
+		prevScripts = make([][]byte, 0, len(st.contracts))
+		for _, contract := range st.contracts {
+			pkScript, err := btc.scriptHashScript(contract)
+			if err != nil {
+				return fmt.Errorf("error constructing p2sh script: %w", err)
+			}
+			prevScripts = append(prevScripts, pkScript)
+		}
+	}
+
+	refund := st.secrets == nil
+	for i, contract := range st.contracts {
+		sender, receiver, _, _, err := dexbtc.ExtractSwapDetails(contract, btc.segwit, btc.chainParams)
+		if err != nil {
+			return fmt.Errorf("error extracting swap addresses: %w", err)
+		}
+		signer := receiver
+		if refund {
+			signer = sender
+		}
+		var sig, pubKey []byte
+		if btc.segwit {
+			sig, pubKey, err = btc.createWitnessSig(msgTx, i, contract, signer, st.values[i], sigHashes)
+		} else {
+			sig, pubKey, err = btc.createSig(msgTx, i, contract, signer, st.values, prevScripts)
+		}
+		if err != nil {
+			return err
+		}
+		txIn := msgTx.TxIn[i]
+		switch {
+		case refund && btc.segwit:
+			txIn.Witness = dexbtc.RefundP2WSHContract(contract, sig, pubKey)
+		case refund:
+			txIn.SignatureScript, err = dexbtc.RefundP2SHContract(contract, sig, pubKey)
+		case btc.segwit:
+			txIn.Witness = dexbtc.RedeemP2WSHContract(contract, sig, pubKey, st.secrets[i])
+		default:
+			txIn.SignatureScript, err = dexbtc.RedeemP2SHContract(contract, sig, pubKey, st.secrets[i])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
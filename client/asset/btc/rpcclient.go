@@ -40,6 +40,7 @@ const (
 	methodLock               = "walletlock"
 	methodPrivKeyForAddress  = "dumpprivkey"
 	methodGetTransaction     = "gettransaction"
+	methodListSinceBlock     = "listsinceblock"
 	methodSendToAddress      = "sendtoaddress"
 	methodSetTxFee           = "settxfee"
 	methodGetWalletInfo      = "getwalletinfo"
@@ -57,6 +58,11 @@ const (
 	methodGetBlockHeader     = "getblockheader"
 	methodGetNetworkInfo     = "getnetworkinfo"
 	methodGetBlockchainInfo  = "getblockchaininfo"
+	// The following are used by the external signer wallet.
+	methodWalletProcessPSBT      = "walletprocesspsbt"
+	methodWalletCreateFundedPSBT = "walletcreatefundedpsbt"
+	methodGetDescriptorInfo      = "getdescriptorinfo"
+	methodImportDescriptors      = "importdescriptors"
 )
 
 // RawRequester defines decred's rpcclient RawRequest func where all RPC
@@ -719,6 +725,25 @@ func (wc *rpcClient) getWalletTransaction(txHash *chainhash.Hash) (*GetTransacti
 	return tx, nil
 }
 
+// listTransactionsSinceBlock lists the wallet's transactions that are mined
+// after the block at the specified height, or are unmined. If blockHeight is
+// zero, all wallet transactions are listed.
+func (wc *rpcClient) listTransactionsSinceBlock(blockHeight int32) ([]btcjson.ListTransactionsResult, error) {
+	var args anylist
+	if blockHeight > 0 {
+		blockHash, err := wc.getBlockHash(int64(blockHeight))
+		if err != nil {
+			return nil, fmt.Errorf("error getting block hash for height %d: %w", blockHeight, err)
+		}
+		args = anylist{blockHash.String()}
+	}
+	res := new(btcjson.ListSinceBlockResult)
+	if err := wc.call(methodListSinceBlock, args, res); err != nil {
+		return nil, err
+	}
+	return res.Transactions, nil
+}
+
 // walletUnlock unlocks the wallet.
 func (wc *rpcClient) walletUnlock(pw []byte) error {
 	// 100000000 comes from bitcoin-cli help walletpassphrase
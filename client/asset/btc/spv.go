@@ -113,6 +113,7 @@ type btcWallet interface {
 	syncedTo() waddrmgr.BlockStamp
 	signTransaction(*wire.MsgTx) error
 	txNotifications() wallet.TransactionNotificationsClient
+	ListSinceBlock(start, end, syncHeight int32) ([]btcjson.ListTransactionsResult, error)
 }
 
 var _ btcWallet = (*walletExtender)(nil)
@@ -1789,6 +1790,17 @@ func (w *spvWallet) getWalletTransaction(txHash *chainhash.Hash) (*GetTransactio
 	return w.getTransaction(txHash)
 }
 
+// listTransactionsSinceBlock lists the wallet's transactions that are mined
+// after the block at the specified height, or are unmined. The wallet database
+// is queried, so no rescan is necessary.
+func (w *spvWallet) listTransactionsSinceBlock(blockHeight int32) ([]btcjson.ListTransactionsResult, error) {
+	var start int32
+	if blockHeight > 0 {
+		start = blockHeight + 1
+	}
+	return w.wallet.ListSinceBlock(start, -1, w.wallet.syncedTo().Height)
+}
+
 // searchBlockForRedemptions attempts to find spending info for the specified
 // contracts by searching every input of all txs in the provided block range.
 func (w *spvWallet) searchBlockForRedemptions(ctx context.Context, reqs map[outPoint]*findRedemptionReq,
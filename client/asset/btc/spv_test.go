@@ -308,6 +308,10 @@ func (c *tBtcWallet) txNotifications() wallet.TransactionNotificationsClient {
 	return wallet.TransactionNotificationsClient{}
 }
 
+func (c *tBtcWallet) ListSinceBlock(start, end, syncHeight int32) ([]btcjson.ListTransactionsResult, error) {
+	return c.listSinceBlock, nil
+}
+
 type tNeutrinoClient struct {
 	*testData
 }
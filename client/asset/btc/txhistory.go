@@ -0,0 +1,206 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package btc
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/asset/txlog"
+	"decred.org/dcrdex/dex"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// txHistoryScanMargin is the number of blocks below the tip that are scanned
+// again on the next tip change when looking for deposits, in case of a reorg.
+const txHistoryScanMargin = 6
+
+// txLogPath is the path of the tx log database for a wallet with the provided
+// data directory.
+func txLogPath(dataDir string, net dex.Network) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, net.String(), "txhistory.db")
+}
+
+// openTxLog opens the wallet's tx log database. There is no tx log if the
+// wallet was not configured with a data directory.
+func (btc *baseWallet) openTxLog() error {
+	if btc.txLogPath == "" {
+		return nil
+	}
+	db, err := txlog.Open(btc.txLogPath)
+	if err != nil {
+		return fmt.Errorf("error opening tx history database: %w", err)
+	}
+	btc.txLogMtx.Lock()
+	btc.txLog = db
+	btc.txLogMtx.Unlock()
+	return nil
+}
+
+// closeTxLog closes the tx log database.
+func (btc *baseWallet) closeTxLog() {
+	btc.txLogMtx.Lock()
+	defer btc.txLogMtx.Unlock()
+	if btc.txLog == nil {
+		return
+	}
+	if err := btc.txLog.Close(); err != nil {
+		btc.log.Errorf("Error closing tx history database: %v", err)
+	}
+	btc.txLog = nil
+}
+
+// logTx records a transaction sent by the wallet. Errors are logged but not
+// returned, since the transaction has already been broadcast.
+func (btc *baseWallet) logTx(tx *asset.WalletTransaction) {
+	btc.txLogMtx.RLock()
+	defer btc.txLogMtx.RUnlock()
+	if btc.txLog == nil {
+		return
+	}
+	if err := btc.txLog.StoreTx(tx); err != nil {
+		btc.log.Errorf("Error storing %s transaction %s in tx history: %v", tx.Type, tx.ID, err)
+	}
+}
+
+// replaceLoggedTx updates the tx log when a transaction is replaced with one
+// paying a higher fee. The replacement takes the place of the replaced
+// transaction in the history.
+func (btc *baseWallet) replaceLoggedTx(oldHash, newHash *chainhash.Hash, fees uint64) {
+	btc.txLogMtx.RLock()
+	defer btc.txLogMtx.RUnlock()
+	if btc.txLog == nil {
+		return
+	}
+	tx, err := btc.txLog.Tx(oldHash.String())
+	if err != nil {
+		if !errors.Is(err, asset.ErrTxNotFound) {
+			btc.log.Errorf("Error retrieving transaction %s from tx history: %v", oldHash, err)
+		}
+		return
+	}
+	tx.ID = newHash.String()
+	tx.Fees = fees
+	tx.Timestamp = uint64(time.Now().Unix())
+	if err := btc.txLog.ReplaceTx(oldHash.String(), tx); err != nil {
+		btc.log.Errorf("Error replacing transaction %s in tx history: %v", oldHash, err)
+	}
+}
+
+// TxHistory returns up to n of the wallet's transactions, most recent first.
+// If refID is non-nil, the transactions older than the refID transaction are
+// returned. Part of the asset.WalletHistorian interface.
+func (btc *baseWallet) TxHistory(n int, refID *string) ([]*asset.WalletTransaction, error) {
+	btc.txLogMtx.RLock()
+	defer btc.txLogMtx.RUnlock()
+	if btc.txLog == nil {
+		return nil, errors.New("tx history not available")
+	}
+	return btc.txLog.Txs(n, refID)
+}
+
+// updateTxHistory updates the mined status of pending transactions in the tx
+// log and logs any new deposits to the wallet. Deposits are found by listing
+// the wallet's transactions, so SPV wallets do not need to rescan.
+func (btc *baseWallet) updateTxHistory(tipHeight int64) {
+	btc.txHistoryMtx.Lock()
+	defer btc.txHistoryMtx.Unlock()
+
+	btc.txLogMtx.RLock()
+	defer btc.txLogMtx.RUnlock()
+	db := btc.txLog
+	if db == nil {
+		return
+	}
+
+	pending, err := db.PendingTxs()
+	if err != nil {
+		btc.log.Errorf("Error retrieving pending transactions from tx history: %v", err)
+		return
+	}
+	for _, tx := range pending {
+		txHash, err := chainhash.NewHashFromStr(tx.ID)
+		if err != nil {
+			btc.log.Errorf("Invalid tx hash %q in tx history: %v", tx.ID, err)
+			continue
+		}
+		txRes, err := btc.node.getWalletTransaction(txHash)
+		if err != nil {
+			if !isTxNotFoundErr(err) {
+				btc.log.Errorf("Error getting wallet transaction %s: %v", txHash, err)
+			}
+			continue
+		}
+		if txRes.Confirmations == 0 {
+			continue
+		}
+		tx.BlockNumber = txRes.BlockHeight
+		if tx.BlockNumber == 0 {
+			tx.BlockNumber = uint64(tipHeight) - txRes.Confirmations + 1
+		}
+		if err := db.StoreTx(tx); err != nil {
+			btc.log.Errorf("Error updating transaction %s in tx history: %v", tx.ID, err)
+		}
+	}
+
+	scanHeight, err := db.ScanHeight()
+	if err != nil {
+		btc.log.Errorf("Error retrieving tx history scan height: %v", err)
+		return
+	}
+	txs, err := btc.node.listTransactionsSinceBlock(int32(scanHeight))
+	if err != nil {
+		btc.log.Errorf("Error listing wallet transactions since block %d: %v", scanHeight, err)
+		return
+	}
+
+	// Transactions with a send category were funded by this wallet, and are
+	// logged when sent. Anything else with a receive category is a deposit.
+	deposits := make(map[string]*asset.WalletTransaction)
+	funded := make(map[string]bool)
+	for _, tx := range txs {
+		switch tx.Category {
+		case string(TxCatSend):
+			funded[tx.TxID] = true
+		case string(TxCatReceive):
+			dep, found := deposits[tx.TxID]
+			if !found {
+				dep = &asset.WalletTransaction{
+					Type:      asset.Receive,
+					ID:        tx.TxID,
+					Timestamp: uint64(tx.Time),
+				}
+				if tx.Confirmations > 0 {
+					dep.BlockNumber = uint64(tipHeight - tx.Confirmations + 1)
+				}
+				if tx.BlockHeight != nil {
+					dep.BlockNumber = uint64(*tx.BlockHeight)
+				}
+				deposits[tx.TxID] = dep
+			}
+			dep.Amount += toSatoshi(tx.Amount)
+		}
+	}
+	for txID, dep := range deposits {
+		if funded[txID] {
+			continue
+		}
+		if _, err := db.StoreNewTx(dep); err != nil {
+			btc.log.Errorf("Error storing deposit %s in tx history: %v", txID, err)
+			return
+		}
+	}
+
+	if newScanHeight := tipHeight - txHistoryScanMargin; newScanHeight > int64(scanHeight) {
+		if err := db.SetScanHeight(uint64(newScanHeight)); err != nil {
+			btc.log.Errorf("Error storing tx history scan height: %v", err)
+		}
+	}
+}
@@ -45,6 +45,7 @@ type Wallet interface {
 	getBlockHeader(blockHash *chainhash.Hash) (*blockHeader, error)
 	ownsAddress(addr btcutil.Address) (bool, error)
 	getWalletTransaction(txHash *chainhash.Hash) (*GetTransactionResult, error)
+	listTransactionsSinceBlock(blockHeight int32) ([]btcjson.ListTransactionsResult, error)
 	searchBlockForRedemptions(ctx context.Context, reqs map[outPoint]*findRedemptionReq, blockHash chainhash.Hash) (discovered map[outPoint]*findRedemptionResult)
 	findRedemptionsInMempool(ctx context.Context, reqs map[outPoint]*findRedemptionReq) (discovered map[outPoint]*findRedemptionResult)
 	getBlock(h chainhash.Hash) (*wire.MsgBlock, error)
@@ -164,3 +164,34 @@ type listDescriptorsResult struct {
 		Next     int64   `json:"next"`     // next index to addresses generation; only set for ranged descriptors
 	} `json:"descriptors"`
 }
+
+// walletPSBTResult models the data returned from the walletprocesspsbt and
+// walletcreatefundedpsbt commands.
+type walletPSBTResult struct {
+	PSBT     string `json:"psbt"` // base64
+	Complete bool   `json:"complete"`
+}
+
+// getDescriptorInfoResult models the data from the getdescriptorinfo command.
+type getDescriptorInfoResult struct {
+	Descriptor string `json:"descriptor"` // with checksum
+	Checksum   string `json:"checksum"`
+}
+
+// importDescriptorRequest is a single descriptor for the importdescriptors
+// command.
+type importDescriptorRequest struct {
+	Descriptor string      `json:"desc"`
+	Active     bool        `json:"active"`
+	Internal   bool        `json:"internal"`
+	Timestamp  interface{} `json:"timestamp"` // unix time or "now"
+}
+
+// importDescriptorResult is the importdescriptors result for a single
+// descriptor.
+type importDescriptorResult struct {
+	Success bool `json:"success"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
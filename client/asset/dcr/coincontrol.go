@@ -0,0 +1,160 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dcr
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrd/wire"
+)
+
+var _ asset.CoinController = (*ExchangeWallet)(nil)
+
+// ListUnspent lists the wallet's spendable outputs. Outputs that are funding
+// orders are not included. Part of the asset.CoinController interface.
+func (dcr *ExchangeWallet) ListUnspent() ([]*asset.UnspentCoin, error) {
+	dcr.fundingMtx.RLock()
+	defer dcr.fundingMtx.RUnlock()
+	utxos, err := dcr.unspentUTXOs()
+	if err != nil {
+		return nil, err
+	}
+	unspents := make([]*asset.UnspentCoin, 0, len(utxos))
+	for _, utxo := range utxos {
+		txHash, err := chainhash.NewHashFromStr(utxo.rpc.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding txid: %w", err)
+		}
+		if dcr.fundingCoins[newOutPoint(txHash, utxo.rpc.Vout)] != nil {
+			continue
+		}
+		unspents = append(unspents, &asset.UnspentCoin{
+			ID:            toCoinID(txHash, utxo.rpc.Vout),
+			Value:         toAtoms(utxo.rpc.Amount),
+			Address:       utxo.rpc.Address,
+			Confirmations: uint32(utxo.confs),
+		})
+	}
+	return unspents, nil
+}
+
+// fundWithCoins is like fund, but the user-selected coins are used instead of
+// choosing coins from the wallet. All of the coins are used, and an error is
+// returned if they are not enough. The coins are locked. Coins that are already
+// funding an order are rejected.
+func (dcr *ExchangeWallet) fundWithCoins(coinIDs []dex.Bytes, enough func(sum uint64, size uint32, unspent *compositeUTXO) bool) (
+	coins asset.Coins, redeemScripts []dex.Bytes, sum, size uint64, err error) {
+
+	if len(coinIDs) == 0 {
+		return nil, nil, 0, 0, errors.New("no coins selected")
+	}
+
+	dcr.fundingMtx.Lock()
+	defer dcr.fundingMtx.Unlock()
+
+	utxos, err := dcr.unspentUTXOs()
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	utxoMap := make(map[outPoint]*compositeUTXO, len(utxos))
+	for _, utxo := range utxos {
+		txHash, err := chainhash.NewHashFromStr(utxo.rpc.TxID)
+		if err != nil {
+			return nil, nil, 0, 0, fmt.Errorf("error decoding txid: %w", err)
+		}
+		utxoMap[newOutPoint(txHash, utxo.rpc.Vout)] = utxo
+	}
+
+	spents := make([]*fundingCoin, 0, len(coinIDs))
+	seen := make(map[outPoint]bool, len(coinIDs))
+	var last *compositeUTXO
+	var lastSum uint64
+	var lastSize uint32
+	for _, coinID := range coinIDs {
+		txHash, vout, err := decodeCoinID(coinID)
+		if err != nil {
+			return nil, nil, 0, 0, err
+		}
+		pt := newOutPoint(txHash, vout)
+		if seen[pt] {
+			return nil, nil, 0, 0, fmt.Errorf("coin %s selected more than once", pt)
+		}
+		seen[pt] = true
+		if dcr.fundingCoins[pt] != nil {
+			return nil, nil, 0, 0, fmt.Errorf("coin %s is already funding an order", pt)
+		}
+		utxo := utxoMap[pt]
+		if utxo == nil {
+			return nil, nil, 0, 0, fmt.Errorf("coin %s is not a spendable wallet output", pt)
+		}
+		redeemScript, err := hex.DecodeString(utxo.rpc.RedeemScript)
+		if err != nil {
+			return nil, nil, 0, 0, fmt.Errorf("error decoding redeem script for %s, script = %s: %w",
+				utxo.rpc.TxID, utxo.rpc.RedeemScript, err)
+		}
+		lastSum, lastSize, last = sum, uint32(size), utxo
+		v := toAtoms(utxo.rpc.Amount)
+		op := newOutput(txHash, vout, v, utxo.rpc.Tree)
+		coins = append(coins, op)
+		spents = append(spents, &fundingCoin{
+			op:   op,
+			addr: utxo.rpc.Address,
+		})
+		redeemScripts = append(redeemScripts, redeemScript)
+		size += uint64(utxo.input.Size())
+		sum += v
+	}
+	if !enough(lastSum, lastSize, last) {
+		return nil, nil, 0, 0, fmt.Errorf("selected coins worth %s DCR are not enough to cover requested funds",
+			amount(sum))
+	}
+
+	if err = dcr.lockFundingCoins(spents); err != nil {
+		return nil, nil, 0, 0, err
+	}
+	return coins, redeemScripts, sum, size, nil
+}
+
+// SendWithCoins sends the exact value to the specified address, spending all
+// of the selected coins. Change is returned to the wallet. feeRate is in units
+// of atoms/byte. Part of the asset.CoinController interface.
+func (dcr *ExchangeWallet) SendWithCoins(address string, value, feeRate uint64, coinIDs []dex.Bytes) (asset.Coin, error) {
+	addr, err := stdaddr.DecodeAddress(address, dcr.chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %s", address)
+	}
+	feeRate = dcr.feeRateWithFallback(feeRate)
+	enough := func(sum uint64, size uint32, unspent *compositeUTXO) bool {
+		txFee := uint64(size+unspent.input.Size()) * feeRate
+		return sum+toAtoms(unspent.rpc.Amount) >= value+txFee
+	}
+	coins, _, _, _, err := dcr.fundWithCoins(coinIDs, enough)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send %s DCR with the selected coins: %w", amount(value), err)
+	}
+
+	msgTx, sentVal, err := dcr.sendCoins(addr, coins, value, feeRate, false)
+	if err != nil {
+		dcr.fundingMtx.Lock()
+		if _, retErr := dcr.returnCoins(coins); retErr != nil {
+			dcr.log.Errorf("Failed to unlock coins: %v", retErr)
+		}
+		dcr.fundingMtx.Unlock()
+		return nil, err
+	}
+	// The coins are spent, so they are no longer needed in the map.
+	dcr.fundingMtx.Lock()
+	for _, coin := range coins {
+		delete(dcr.fundingCoins, coin.(*output).pt)
+	}
+	dcr.fundingMtx.Unlock()
+	dcr.logSend(msgTx, sentVal, address)
+	return newOutput(msgTx.CachedTxHash(), 0, sentVal, wire.TxTreeRegular), nil
+}
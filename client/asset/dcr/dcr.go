@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/asset/txlog"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/calc"
 	"decred.org/dcrdex/dex/config"
@@ -35,6 +36,8 @@ import (
 	"github.com/decred/dcrd/blockchain/v4"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	"github.com/decred/dcrd/dcrutil/v4"
 	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v3"
@@ -533,12 +536,21 @@ type ExchangeWallet struct {
 
 	externalTxMtx   sync.RWMutex
 	externalTxCache map[chainhash.Hash]*externalTx
+
+	// dataDir is where the tx history database is kept. There is no tx
+	// history if the wallet is not configured with a data directory.
+	dataDir      string
+	txLogMtx     sync.RWMutex
+	txLog        *txlog.DB
+	txHistoryMtx sync.Mutex // serializes updateTxHistory
 }
 
 // Check that ExchangeWallet satisfies the Wallet interface.
 var _ asset.Wallet = (*ExchangeWallet)(nil)
 var _ asset.FeeRater = (*ExchangeWallet)(nil)
 var _ asset.Withdrawer = (*ExchangeWallet)(nil)
+var _ asset.Bonder = (*ExchangeWallet)(nil)
+var _ asset.WalletHistorian = (*ExchangeWallet)(nil)
 
 type block struct {
 	height int64
@@ -673,6 +685,7 @@ func unconnectedWallet(cfg *asset.WalletConfig, dcrCfg *walletConfig, chainParam
 		fundingCoins:        make(map[outPoint]*fundingCoin),
 		findRedemptionQueue: make(map[outPoint]*findRedemptionReq),
 		externalTxCache:     make(map[chainhash.Hash]*externalTx),
+		dataDir:             cfg.DataDir,
 		fallbackFeeRate:     fallbackFeesPerByte,
 		feeRateLimit:        feesLimitPerByte,
 		redeemConfTarget:    redeemConfTarget,
@@ -769,6 +782,10 @@ func (dcr *ExchangeWallet) Connect(ctx context.Context) (*sync.WaitGroup, error)
 		return nil, fmt.Errorf("error initializing best block for DCR: %w", err)
 	}
 
+	if err = dcr.openTxLog(); err != nil {
+		return nil, err
+	}
+
 	success = true // All good, don't disconnect the wallet when this method returns.
 
 	// NotifyOnTipChange will return false if the wallet does not support
@@ -791,6 +808,14 @@ func (dcr *ExchangeWallet) Connect(ctx context.Context) (*sync.WaitGroup, error)
 		defer wg.Done()
 		dcr.monitorPeers(ctx)
 	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dcr.tipMtx.RLock()
+		tipHeight := dcr.currentTip.height
+		dcr.tipMtx.RUnlock()
+		dcr.updateTxHistory(tipHeight)
+	}()
 	return &wg, nil
 }
 
@@ -1390,17 +1415,27 @@ func (dcr *ExchangeWallet) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes
 		dcr.log.Errorf("calcBumpRate error: %v", err)
 	}
 
-	coins, redeemScripts, sum, inputsSize, err := dcr.fund(orderEnough(ord.Value, ord.MaxSwapCount, bumpedMaxRate, ord.DEXConfig))
-	if err != nil {
-		return nil, nil, fmt.Errorf("error funding order value of %s DCR: %w",
-			amount(ord.Value), err)
-	}
-
 	useSplit := dcr.useSplitTx
 	if customCfg.Split != nil {
 		useSplit = *customCfg.Split
 	}
 
+	enough := orderEnough(ord.Value, ord.MaxSwapCount, bumpedMaxRate, ord.DEXConfig)
+	var coins asset.Coins
+	var redeemScripts []dex.Bytes
+	var sum, inputsSize uint64
+	if len(ord.Coins) > 0 {
+		// The user chose the coins, so use all of them, and don't split.
+		coins, redeemScripts, sum, inputsSize, err = dcr.fundWithCoins(ord.Coins, enough)
+		useSplit = false
+	} else {
+		coins, redeemScripts, sum, inputsSize, err = dcr.fund(enough)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error funding order value of %s DCR: %w",
+			amount(ord.Value), err)
+	}
+
 	// Send a split, if preferred.
 	if useSplit && !ord.Immediate {
 		// We apply the bumped fee rate to the split transaction when the
@@ -1470,8 +1505,22 @@ func (dcr *ExchangeWallet) fund(enough func(sum uint64, size uint32, unspent *co
 	return coins, redeemScripts, sum, uint64(sz), nil
 }
 
-// spendableUTXOs generates a slice of spendable *compositeUTXO.
+// spendableUTXOs generates a slice of spendable *compositeUTXO. An error is
+// returned if there are none.
 func (dcr *ExchangeWallet) spendableUTXOs() ([]*compositeUTXO, error) {
+	utxos, err := dcr.unspentUTXOs()
+	if err != nil {
+		return nil, err
+	}
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("no funds available")
+	}
+	return utxos, nil
+}
+
+// unspentUTXOs lists the spendable outputs in the primary and trading
+// accounts. The returned utxos are sorted in ascending order by amount.
+func (dcr *ExchangeWallet) unspentUTXOs() ([]*compositeUTXO, error) {
 	unspents, err := dcr.wallet.Unspents(dcr.ctx, dcr.primaryAcct)
 	if err != nil {
 		return nil, err
@@ -1488,7 +1537,7 @@ func (dcr *ExchangeWallet) spendableUTXOs() ([]*compositeUTXO, error) {
 		unspents = append(unspents, tradingAcctSpendables...)
 	}
 	if len(unspents) == 0 {
-		return nil, fmt.Errorf("insufficient funds. 0 DCR available to spend in account %q", dcr.primaryAcct)
+		return nil, nil
 	}
 
 	// Parse utxos to include script size for spending input. Returned utxos
@@ -1497,9 +1546,6 @@ func (dcr *ExchangeWallet) spendableUTXOs() ([]*compositeUTXO, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error parsing unspent outputs: %w", err)
 	}
-	if len(utxos) == 0 {
-		return nil, fmt.Errorf("no funds available")
-	}
 	return utxos, nil
 }
 
@@ -2015,6 +2061,19 @@ func (dcr *ExchangeWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin
 		return nil, nil, 0, err
 	}
 
+	secretHashes := make([]dex.Bytes, 0, swapCount)
+	for _, contract := range swaps.Contracts {
+		secretHashes = append(secretHashes, contract.SecretHash)
+	}
+	dcr.logTx(&asset.WalletTransaction{
+		Type:         asset.Swap,
+		ID:           txHash.String(),
+		Amount:       totalOut,
+		Fees:         fees,
+		Timestamp:    uint64(time.Now().Unix()),
+		SecretHashes: secretHashes,
+	})
+
 	// Return spent outputs.
 	_, err = dcr.returnCoins(swaps.Inputs)
 	if err != nil {
@@ -2049,6 +2108,7 @@ func (dcr *ExchangeWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Co
 	var totalIn uint64
 	var contracts [][]byte
 	var addresses []stdaddr.Address
+	secretHashes := make([]dex.Bytes, 0, len(form.Redemptions))
 	for _, r := range form.Redemptions {
 		if r.Spends == nil {
 			return nil, nil, 0, fmt.Errorf("no audit info")
@@ -2072,6 +2132,7 @@ func (dcr *ExchangeWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Co
 		}
 		addresses = append(addresses, receiver)
 		contracts = append(contracts, contract)
+		secretHashes = append(secretHashes, secretHash)
 		prevOut := cinfo.output.wireOutPoint()
 		txIn := wire.NewTxIn(prevOut, int64(cinfo.output.value), []byte{})
 		msgTx.AddTxIn(txIn)
@@ -2136,6 +2197,15 @@ func (dcr *ExchangeWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Co
 		return nil, nil, 0, fmt.Errorf("redemption sent, but received unexpected transaction ID back from RPC server. "+
 			"expected %s, got %s", *txHash, checkHash)
 	}
+	dcr.logTx(&asset.WalletTransaction{
+		Type:         asset.Redeem,
+		ID:           txHash.String(),
+		Amount:       totalIn,
+		Fees:         fee,
+		Timestamp:    uint64(time.Now().Unix()),
+		SecretHashes: secretHashes,
+	})
+
 	coinIDs := make([]dex.Bytes, 0, len(form.Redemptions))
 	for i := range form.Redemptions {
 		coinIDs = append(coinIDs, toCoinID(txHash, uint32(i)))
@@ -2808,6 +2878,20 @@ func (dcr *ExchangeWallet) Refund(coinID, contract dex.Bytes, feeSuggestion uint
 		return nil, fmt.Errorf("refund sent, but received unexpected transaction ID back from RPC server. "+
 			"expected %s, got %s", checkHash, *refundHash)
 	}
+
+	valIn := uint64(msgTx.TxIn[0].ValueIn)
+	tx := &asset.WalletTransaction{
+		Type:      asset.Refund,
+		ID:        refundHash.String(),
+		Amount:    valIn,
+		Fees:      valIn - uint64(msgTx.TxOut[0].Value),
+		Timestamp: uint64(time.Now().Unix()),
+	}
+	if _, _, _, secretHash, err := dexdcr.ExtractSwapDetails(contract, dcr.chainParams); err == nil {
+		tx.SecretHashes = []dex.Bytes{secretHash}
+	}
+	dcr.logTx(tx)
+
 	return toCoinID(refundHash, 0), nil
 }
 
@@ -2885,6 +2969,225 @@ func (dcr *ExchangeWallet) refundTx(coinID, contract dex.Bytes, val uint64, refu
 	return msgTx, nil
 }
 
+// MakeBondTx creates a time-locked fidelity bond transaction. The V0
+// transaction has two required outputs:
+//
+// Output 0 is the time-locked P2SH bond output with the bond script created
+// by dexdcr.MakeBondScript.
+//
+// Output 1 is a DEX Account commitment. This is an OP_RETURN output that
+// references the provided account ID.
+//
+//	OP_RETURN <2-byte version> <32-byte account ID> <4-byte locktime> <20-byte pubkey hash>
+//
+// There may also be a change output.
+//
+// A refund transaction spending the bond output to an address controlled by
+// this wallet is also created and signed with the bond key, and returned as
+// the Bond's RedeemTx. The bond transaction is NOT broadcast.
+func (dcr *ExchangeWallet) MakeBondTx(ver uint16, amt, feeRate uint64, lockTime time.Time, bondKey *secp256k1.PrivateKey, acctID []byte) (*asset.Bond, error) {
+	if ver != 0 {
+		return nil, errors.New("only version 0 bonds supported")
+	}
+	if until := time.Until(lockTime); until >= 365*24*time.Hour {
+		return nil, fmt.Errorf("that lock time is nuts: %v", lockTime)
+	} else if until < 0 {
+		return nil, fmt.Errorf("that lock time is already passed: %v", lockTime)
+	}
+
+	pk := bondKey.PubKey().SerializeCompressed()
+	pkh := stdaddr.Hash160(pk)
+
+	feeRate = dcr.feeRateWithFallback(feeRate)
+	baseTx := wire.NewMsgTx()
+
+	// Add the bond output.
+	lockTimeSec := uint32(lockTime.Unix())
+	bondScript, err := dexdcr.MakeBondScript(ver, lockTimeSec, pkh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build bond output redeem script: %w", err)
+	}
+	bondAddr, err := stdaddr.NewAddressScriptHashV0(bondScript, dcr.chainParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build bond output payment script: %w", err)
+	}
+	bondPkScriptVer, bondPkScript := bondAddr.PaymentScript()
+	txOut := newTxOut(int64(amt), bondPkScriptVer, bondPkScript)
+	if dexdcr.IsDust(txOut, feeRate) {
+		return nil, fmt.Errorf("bond output value of %d is dust", amt)
+	}
+	baseTx.AddTxOut(txOut)
+
+	// Add the account ID commitment output.
+	commitPkScript, err := dexdcr.MakeBondCommitScript(ver, acctID, lockTimeSec, pkh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build bond commitment script: %w", err)
+	}
+	baseTx.AddTxOut(newTxOut(0, 0, commitPkScript))
+
+	// Fund the bond transaction, including a change output.
+	baseSize := uint32(baseTx.SerializeSize()) + dexdcr.P2PKHOutputSize
+	enough := func(sum uint64, size uint32, unspent *compositeUTXO) bool {
+		txFee := uint64(baseSize+size+unspent.input.Size()) * feeRate
+		return sum+toAtoms(unspent.rpc.Amount) >= amt+txFee
+	}
+	coins, _, _, _, err := dcr.fund(enough)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fund bond of %s DCR with fee rate of %d atoms/byte: %w",
+			amount(amt), feeRate, err)
+	}
+	var success bool
+	defer func() {
+		if !success {
+			dcr.fundingMtx.Lock()
+			if _, err := dcr.returnCoins(coins); err != nil {
+				dcr.log.Errorf("Failed to unlock bond funding coins: %v", err)
+			}
+			dcr.fundingMtx.Unlock()
+		}
+	}()
+
+	if _, err = dcr.addInputCoins(baseTx, coins); err != nil {
+		return nil, err
+	}
+	unsignedTx := baseTx.Copy() // signTxAndAddChange may add a change output to baseTx
+	signedTx, _, _, fee, err := dcr.signTxAndAddChange(baseTx, feeRate, -1, dcr.depositAccount())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign bond tx: %w", err)
+	}
+	txid := signedTx.TxHash()
+
+	signedTxBytes, err := signedTx.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	unsignedTxBytes, err := unsignedTx.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Prep the redeem / refund tx.
+	redeemAddr, err := dcr.wallet.ExternalAddress(dcr.ctx, dcr.depositAccount())
+	if err != nil {
+		return nil, fmt.Errorf("error creating refund address: %w", err)
+	}
+	redeemMsgTx, err := dcr.makeBondRefundTxV0(&txid, 0, amt, bondScript, bondKey, redeemAddr, feeRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bond refund tx: %w", err)
+	}
+	redeemTx, err := redeemMsgTx.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize bond refund tx: %w", err)
+	}
+
+	dcr.log.Infof("Created DCR bond tx %v paying %s DCR with fees of %s DCR, lock time %v",
+		txid, amount(amt), amount(fee), lockTime)
+
+	success = true
+	return &asset.Bond{
+		Version:    ver,
+		AssetID:    BipID,
+		Amount:     amt,
+		CoinID:     toCoinID(&txid, 0),
+		Data:       bondScript,
+		SignedTx:   signedTxBytes,
+		UnsignedTx: unsignedTxBytes,
+		RedeemTx:   redeemTx,
+	}, nil
+}
+
+// makeBondRefundTxV0 creates and signs a transaction spending the version 0
+// bond output to the provided address. The transaction's lock time is set to
+// the bond's lock time, so it cannot be mined before the bond expires.
+func (dcr *ExchangeWallet) makeBondRefundTxV0(txid *chainhash.Hash, vout uint32, amt uint64,
+	script []byte, priv *secp256k1.PrivateKey, refundAddr stdaddr.Address, feeRate uint64) (*wire.MsgTx, error) {
+	lockTime, pkhPush, err := dexdcr.ExtractBondDetailsV0(0, script)
+	if err != nil {
+		return nil, err
+	}
+
+	pk := priv.PubKey().SerializeCompressed()
+	pkh := stdaddr.Hash160(pk)
+	if !bytes.Equal(pkh, pkhPush) {
+		return nil, asset.ErrIncorrectBondKey
+	}
+
+	msgTx := wire.NewMsgTx()
+	msgTx.LockTime = lockTime
+	bondPrevOut := wire.NewOutPoint(txid, vout, wire.TxTreeRegular)
+	txIn := wire.NewTxIn(bondPrevOut, int64(amt), []byte{})
+	txIn.Sequence = wire.MaxTxInSequenceNum - 1 // not finalized, do not disable cltv
+	msgTx.AddTxIn(txIn)
+
+	// Calculate fees and add the refund output.
+	size := msgTx.SerializeSize() + dexdcr.RefundBondSigScriptSize + dexdcr.P2PKHOutputSize
+	fee := feeRate * uint64(size)
+	if fee > amt {
+		return nil, fmt.Errorf("irredeemable bond at fee rate %d atoms/byte", feeRate)
+	}
+
+	redeemPkScriptVer, redeemPkScript := refundAddr.PaymentScript()
+	redeemTxOut := newTxOut(int64(amt-fee), redeemPkScriptVer, redeemPkScript)
+	if dexdcr.IsDust(redeemTxOut, feeRate) {
+		return nil, fmt.Errorf("bond redeem output (amt = %d, feeRate = %d, outputSize = %d) is dust", amt, feeRate, redeemTxOut.SerializeSize())
+	}
+	msgTx.AddTxOut(redeemTxOut)
+
+	sig, err := sign.RawTxInSignature(msgTx, 0, script, txscript.SigHashAll, priv.Serialize(), dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	txIn.SignatureScript, err = dexdcr.RefundBondScript(script, sig, pk)
+	if err != nil {
+		return nil, fmt.Errorf("RefundBondScript: %w", err)
+	}
+
+	return msgTx, nil
+}
+
+// RefundBond refunds a bond output to a new wallet address given the redeem
+// script and private key. After broadcasting, the refund coin ID is returned.
+func (dcr *ExchangeWallet) RefundBond(ctx context.Context, ver uint16, coinID, script []byte, amt uint64, privKey *secp256k1.PrivateKey) ([]byte, error) {
+	if ver != 0 {
+		return nil, errors.New("only version 0 bonds supported")
+	}
+	txHash, vout, err := decodeCoinID(coinID)
+	if err != nil {
+		return nil, err
+	}
+	feeRate := dcr.targetFeeRateWithFallback(2, 0)
+
+	redeemAddr, err := dcr.wallet.ExternalAddress(ctx, dcr.depositAccount())
+	if err != nil {
+		return nil, fmt.Errorf("error creating refund address: %w", err)
+	}
+	msgTx, err := dcr.makeBondRefundTxV0(txHash, vout, amt, script, privKey, redeemAddr, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dcr.broadcastTx(msgTx); err != nil {
+		return nil, err
+	}
+
+	txid := msgTx.TxHash()
+	return toCoinID(&txid, 0), nil
+}
+
+// SendTransaction broadcasts a valid fully-signed transaction, returning the
+// coin ID of its first output.
+func (dcr *ExchangeWallet) SendTransaction(rawTx []byte) ([]byte, error) {
+	msgTx, err := msgTxFromBytes(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	if err = dcr.broadcastTx(msgTx); err != nil {
+		return nil, err
+	}
+	txid := msgTx.TxHash()
+	return toCoinID(&txid, 0), nil
+}
+
 // DepositAddress returns an address for depositing funds into the exchange
 // wallet.
 func (dcr *ExchangeWallet) DepositAddress() (string, error) {
@@ -2965,6 +3268,7 @@ func (dcr *ExchangeWallet) Withdraw(address string, value, feeRate uint64) (asse
 	if err != nil {
 		return nil, err
 	}
+	dcr.logSend(msgTx, sentVal, address)
 	return newOutput(msgTx.CachedTxHash(), 0, sentVal, wire.TxTreeRegular), nil
 }
 
@@ -2980,6 +3284,7 @@ func (dcr *ExchangeWallet) Send(address string, value, feeRate uint64) (asset.Co
 	if err != nil {
 		return nil, err
 	}
+	dcr.logSend(msgTx, sentVal, address)
 	return newOutput(msgTx.CachedTxHash(), 0, sentVal, wire.TxTreeRegular), nil
 }
 
@@ -3092,6 +3397,8 @@ func (dcr *ExchangeWallet) shutdown() {
 	if dcr.wallet != nil {
 		dcr.wallet.Disconnect()
 	}
+
+	dcr.closeTxLog()
 }
 
 // SyncStatus is information about the blockchain sync status.
@@ -3658,6 +3965,7 @@ func (dcr *ExchangeWallet) handleTipChange(newTipHash *chainhash.Hash, newTipHei
 	dcr.currentTip = &block{newTipHeight, newTipHash}
 	dcr.log.Debugf("tip change: %d (%s) => %d (%s)", prevTip.height, prevTip.hash, newTipHeight, newTipHash)
 	go dcr.tipChange(nil)
+	go dcr.updateTxHistory(newTipHeight)
 
 	// Search for contract redemption in new blocks if there
 	// are contracts pending redemption.
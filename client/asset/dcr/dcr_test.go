@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -1019,6 +1020,136 @@ func TestReturnCoins(t *testing.T) {
 	}
 }
 
+func TestCoinControl(t *testing.T) {
+	wallet, node, shutdown, err := tNewWallet()
+	defer shutdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const feeRate = 10
+	var lots uint64 = 10
+	ordValue := tLotSize * lots
+	reqFunds := calc.RequiredOrderFunds(ordValue, 2*dexdcr.P2PKHInputSize, lots, tDCR)
+	makeUnspent := func(vout uint32, value uint64) walletjson.ListUnspentResult {
+		return walletjson.ListUnspentResult{
+			TxID:          tTxID,
+			Vout:          vout,
+			Address:       tPKHAddr.String(),
+			Account:       tAcctName,
+			Amount:        float64(value) / 1e8,
+			Confirmations: 1,
+			ScriptPubKey:  hex.EncodeToString(tP2PKHScript),
+			Spendable:     true,
+		}
+	}
+	// The big output alone would be enough to fund the order.
+	node.unspent = []walletjson.ListUnspentResult{makeUnspent(0, reqFunds/2), makeUnspent(1, reqFunds)}
+	node.changeAddr = tPKHAddr
+	node.signFunc = func(msgTx *wire.MsgTx) (*wire.MsgTx, bool, error) {
+		return signFunc(msgTx, dexdcr.P2PKHSigScriptSize)
+	}
+	smallID, bigID := toCoinID(tTxHash, 0), toCoinID(tTxHash, 1)
+
+	unspents, err := wallet.ListUnspent()
+	if err != nil {
+		t.Fatalf("ListUnspent error: %v", err)
+	}
+	if len(unspents) != 2 {
+		t.Fatalf("expected 2 unspents, got %d", len(unspents))
+	}
+	if !bytes.Equal(unspents[0].ID, smallID) || unspents[0].Value != reqFunds/2 ||
+		unspents[0].Address != tPKHAddr.String() || unspents[0].Confirmations != 1 {
+		t.Fatalf("wrong unspent %+v", unspents[0])
+	}
+
+	ord := &asset.Order{
+		Value:         ordValue,
+		MaxSwapCount:  lots,
+		DEXConfig:     tDCR,
+		FeeSuggestion: feeRate,
+		Options:       map[string]string{splitKey: "true"},
+	}
+
+	// The small output alone is not enough.
+	ord.Coins = []dex.Bytes{smallID}
+	if _, _, err = wallet.FundOrder(ord); err == nil {
+		t.Fatalf("no error funding with not enough selected coins")
+	}
+
+	// Duplicate and unknown coins are rejected.
+	ord.Coins = []dex.Bytes{bigID, bigID}
+	if _, _, err = wallet.FundOrder(ord); err == nil {
+		t.Fatalf("no error funding with a duplicate coin")
+	}
+	ord.Coins = []dex.Bytes{toCoinID(tTxHash, 2)}
+	if _, _, err = wallet.FundOrder(ord); err == nil {
+		t.Fatalf("no error funding with an unknown coin")
+	}
+
+	// All selected coins are used, even though one would be enough, and no
+	// split is made.
+	ord.Coins = []dex.Bytes{smallID, bigID}
+	coins, redeemScripts, err := wallet.FundOrder(ord)
+	if err != nil {
+		t.Fatalf("error funding with selected coins: %v", err)
+	}
+	if len(coins) != 2 || len(redeemScripts) != 2 {
+		t.Fatalf("expected 2 coins, got %d", len(coins))
+	}
+	if node.sentRawTx != nil {
+		t.Fatalf("split tx sent for selected coins")
+	}
+	if len(node.lockedCoins) != 2 || len(wallet.fundingCoins) != 2 {
+		t.Fatalf("selected coins not locked")
+	}
+
+	// The coins are funding an order, so they are not listed, and can't be
+	// selected again.
+	unspents, err = wallet.ListUnspent()
+	if err != nil {
+		t.Fatalf("ListUnspent error: %v", err)
+	}
+	if len(unspents) != 0 {
+		t.Fatalf("expected 0 unspents, got %d", len(unspents))
+	}
+	ord.Coins = []dex.Bytes{bigID}
+	if _, _, err = wallet.FundOrder(ord); err == nil {
+		t.Fatalf("no error funding with a coin that is already funding an order")
+	}
+	if _, err = wallet.SendWithCoins(tPKHAddr.String(), 1e5, feeRate, []dex.Bytes{bigID}); err == nil {
+		t.Fatalf("no error sending with a coin that is already funding an order")
+	}
+	if err = wallet.ReturnCoins(coins); err != nil {
+		t.Fatalf("ReturnCoins error: %v", err)
+	}
+
+	// Send with the selected coins.
+	const sendVal = 1e5
+	coin, err := wallet.SendWithCoins(tPKHAddr.String(), sendVal, feeRate, []dex.Bytes{smallID})
+	if err != nil {
+		t.Fatalf("SendWithCoins error: %v", err)
+	}
+	if coin.Value() != sendVal {
+		t.Fatalf("wrong sent value %d", coin.Value())
+	}
+	sentTx := node.sentRawTx
+	if len(sentTx.TxIn) != 1 || sentTx.TxIn[0].PreviousOutPoint.Index != 0 {
+		t.Fatalf("send didn't spend the selected coin")
+	}
+	if len(sentTx.TxOut) != 2 || sentTx.TxOut[0].Value != sendVal {
+		t.Fatalf("wrong send outputs")
+	}
+	if len(wallet.fundingCoins) != 0 {
+		t.Fatalf("spent coins still in funding coins map")
+	}
+
+	// Not enough to cover the value.
+	if _, err = wallet.SendWithCoins(tPKHAddr.String(), reqFunds, feeRate, []dex.Bytes{smallID}); err == nil {
+		t.Fatalf("no error sending more than the selected coins")
+	}
+}
+
 func TestFundingCoins(t *testing.T) {
 	wallet, node, shutdown, err := tNewWallet()
 	defer shutdown()
@@ -2061,6 +2192,95 @@ func testSender(t *testing.T, senderType tSenderType) {
 	}
 }
 
+func TestTxHistory(t *testing.T) {
+	wallet, node, shutdown, err := tNewWallet()
+	defer shutdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := wallet.TxHistory(0, nil); err == nil {
+		t.Fatalf("no error for TxHistory without a tx log")
+	}
+
+	wallet.dataDir = t.TempDir()
+	if err := wallet.openTxLog(); err != nil {
+		t.Fatalf("openTxLog error: %v", err)
+	}
+	defer wallet.closeTxLog()
+
+	randHash := func() string {
+		var h chainhash.Hash
+		copy(h[:], randBytes(32))
+		return h.String()
+	}
+
+	// An unmined swap sent by the wallet.
+	swapID := randHash()
+	wallet.logTx(&asset.WalletTransaction{
+		Type:         asset.Swap,
+		ID:           swapID,
+		Amount:       1e8,
+		Fees:         1e4,
+		SecretHashes: []dex.Bytes{randBytes(32)},
+	})
+
+	// A deposit paid to two wallet addresses, a wallet-funded transaction, and
+	// the wallet's own swap.
+	depositID, splitID := randHash(), randHash()
+	node.rawRes[methodListSinceBlock], node.rawErr[methodListSinceBlock] = json.Marshal(&walletjson.ListSinceBlockResult{
+		Transactions: []walletjson.ListTransactionsResult{
+			{Category: "receive", TxID: depositID, Amount: 0.5, Confirmations: 2, Time: 1000},
+			{Category: "receive", TxID: depositID, Amount: 0.25, Confirmations: 2, Time: 1000},
+			{Category: "send", TxID: splitID, Amount: -1},
+			{Category: "receive", TxID: splitID, Amount: 1},
+			{Category: "send", TxID: swapID, Amount: -1},
+		},
+	})
+	// The swap is now mined.
+	node.walletTx = &walletjson.GetTransactionResult{Confirmations: 3}
+
+	const tipHeight = 100
+	wallet.updateTxHistory(tipHeight)
+
+	txs, err := wallet.TxHistory(0, nil)
+	if err != nil {
+		t.Fatalf("TxHistory error: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("expected 2 txs, got %d", len(txs))
+	}
+	deposit, swap := txs[0], txs[1]
+	if deposit.ID != depositID || deposit.Type != asset.Receive {
+		t.Fatalf("deposit not logged")
+	}
+	if deposit.Amount != 0.75e8 {
+		t.Fatalf("wrong deposit amount %d", deposit.Amount)
+	}
+	if deposit.BlockNumber != tipHeight-1 {
+		t.Fatalf("wrong deposit block number %d", deposit.BlockNumber)
+	}
+	if swap.ID != swapID || swap.BlockNumber != tipHeight-2 {
+		t.Fatalf("swap not updated")
+	}
+
+	// Paging.
+	txs, err = wallet.TxHistory(1, &depositID)
+	if err != nil {
+		t.Fatalf("TxHistory error with ref ID: %v", err)
+	}
+	if len(txs) != 1 || txs[0].ID != swapID {
+		t.Fatalf("wrong paged txs")
+	}
+
+	// Listing errors don't affect the logged transactions.
+	node.rawErr[methodListSinceBlock] = tErr
+	wallet.updateTxHistory(tipHeight + 1)
+	if txs, _ = wallet.TxHistory(0, nil); len(txs) != 2 {
+		t.Fatalf("expected 2 txs after listing error, got %d", len(txs))
+	}
+}
+
 func TestWithdraw(t *testing.T) {
 	testSender(t, tWithdrawSender)
 }
@@ -2209,6 +2429,106 @@ func Test_sendToAddress(t *testing.T) {
 	}
 }
 
+func TestMakeBondTx(t *testing.T) {
+	wallet, node, shutdown, err := tNewWallet()
+	defer shutdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.changeAddr = tPKHAddr
+	node.newAddr = tPKHAddr
+
+	var unspentVal uint64 = 100e8
+	node.unspent = []walletjson.ListUnspentResult{{
+		TxID:          tTxID,
+		Address:       tPKHAddr.String(),
+		Account:       tAcctName,
+		Amount:        float64(unspentVal) / 1e8,
+		Confirmations: 5,
+		ScriptPubKey:  hex.EncodeToString(tP2PKHScript),
+		Spendable:     true,
+	}}
+
+	bondKey, _ := secp256k1.GeneratePrivateKey()
+	acctID := randBytes(32)
+	lockTime := time.Now().Add(time.Hour)
+	const bondAmt = 5e8
+
+	// Unsupported version.
+	if _, err = wallet.MakeBondTx(1, bondAmt, optimalFeeRate, lockTime, bondKey, acctID); err == nil {
+		t.Fatalf("no error for unsupported bond version")
+	}
+	// Expired lock time.
+	if _, err = wallet.MakeBondTx(0, bondAmt, optimalFeeRate, time.Now().Add(-time.Minute), bondKey, acctID); err == nil {
+		t.Fatalf("no error for expired lock time")
+	}
+	// Insufficient funds.
+	if _, err = wallet.MakeBondTx(0, unspentVal, optimalFeeRate, lockTime, bondKey, acctID); err == nil {
+		t.Fatalf("no error for insufficient funds")
+	}
+
+	bond, err := wallet.MakeBondTx(0, bondAmt, optimalFeeRate, lockTime, bondKey, acctID)
+	if err != nil {
+		t.Fatalf("MakeBondTx error: %v", err)
+	}
+
+	bondTx, err := msgTxFromBytes(bond.SignedTx)
+	if err != nil {
+		t.Fatalf("error decoding bond tx: %v", err)
+	}
+	if len(bondTx.TxOut) < 2 {
+		t.Fatalf("expected at least 2 outputs, got %d", len(bondTx.TxOut))
+	}
+	if bondTx.TxOut[0].Value != bondAmt {
+		t.Fatalf("wrong bond amount %d", bondTx.TxOut[0].Value)
+	}
+	_, commitAcct, commitLockTime, _, err := dexdcr.ExtractBondCommitDataV0(0, bondTx.TxOut[1].PkScript)
+	if err != nil {
+		t.Fatalf("error extracting bond commitment: %v", err)
+	}
+	if !bytes.Equal(commitAcct[:], acctID) {
+		t.Fatalf("wrong account ID in commitment")
+	}
+	if commitLockTime != uint32(lockTime.Unix()) {
+		t.Fatalf("wrong lock time in commitment")
+	}
+
+	// The pre-signed refund tx must spend the bond output.
+	refundTx, err := msgTxFromBytes(bond.RedeemTx)
+	if err != nil {
+		t.Fatalf("error decoding refund tx: %v", err)
+	}
+	txid := bondTx.TxHash()
+	if refundTx.TxIn[0].PreviousOutPoint.Hash != txid {
+		t.Fatalf("refund tx does not spend the bond tx")
+	}
+	if refundTx.LockTime != uint32(lockTime.Unix()) {
+		t.Fatalf("wrong refund tx lock time")
+	}
+	vm, err := txscript.NewEngine(bondTx.TxOut[0].PkScript, refundTx, 0,
+		txscript.ScriptVerifyCheckLockTimeVerify|txscript.ScriptVerifyCleanStack, 0, nil)
+	if err != nil {
+		t.Fatalf("error creating script engine: %v", err)
+	}
+	if err = vm.Execute(); err != nil {
+		t.Fatalf("refund tx script execution error: %v", err)
+	}
+
+	// Refund with the wrong key.
+	wrongKey, _ := secp256k1.GeneratePrivateKey()
+	_, err = wallet.RefundBond(tCtx, 0, bond.CoinID, bond.Data, bond.Amount, wrongKey)
+	if !errors.Is(err, asset.ErrIncorrectBondKey) {
+		t.Fatalf("expected ErrIncorrectBondKey, got %v", err)
+	}
+
+	if _, err = wallet.RefundBond(tCtx, 0, bond.CoinID, bond.Data, bond.Amount, bondKey); err != nil {
+		t.Fatalf("RefundBond error: %v", err)
+	}
+	if node.sentRawTx == nil || node.sentRawTx.TxIn[0].PreviousOutPoint.Hash != txid {
+		t.Fatalf("refund tx not broadcast")
+	}
+}
+
 func TestLookupTxOutput(t *testing.T) {
 	wallet, node, shutdown, err := tNewWallet()
 	defer shutdown()
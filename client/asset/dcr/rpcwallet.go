@@ -45,6 +45,7 @@ const (
 	methodSignRawTransaction = "signrawtransaction"
 	methodSyncStatus         = "syncstatus"
 	methodGetPeerInfo        = "getpeerinfo"
+	methodListSinceBlock     = "listsinceblock"
 )
 
 // rpcWallet implements Wallet functionality using an rpc client to communicate
@@ -70,6 +71,7 @@ type rpcWallet struct {
 var _ Wallet = (*rpcWallet)(nil)
 var _ Mempooler = (*rpcWallet)(nil)
 var _ FeeRateEstimator = (*rpcWallet)(nil)
+var _ TxLister = (*rpcWallet)(nil)
 
 type walletClient = dcrwallet.Client
 
@@ -604,6 +606,25 @@ func (w *rpcWallet) GetTransaction(ctx context.Context, txHash *chainhash.Hash)
 	}, nil
 }
 
+// ListSinceBlock lists the wallet's transactions mined above the specified
+// block height, and any unmined transactions.
+// Part of the TxLister interface.
+func (w *rpcWallet) ListSinceBlock(ctx context.Context, blockHeight int64) ([]walletjson.ListTransactionsResult, error) {
+	var args anylist
+	if blockHeight > 0 {
+		blockHash, err := w.GetBlockHash(ctx, blockHeight)
+		if err != nil {
+			return nil, fmt.Errorf("error getting hash for block %d: %w", blockHeight, err)
+		}
+		args = anylist{blockHash.String()}
+	}
+	var res walletjson.ListSinceBlockResult
+	if err := w.rpcClientRawRequest(ctx, methodListSinceBlock, args, &res); err != nil {
+		return nil, err
+	}
+	return res.Transactions, nil
+}
+
 // GetRawTransaction returns details of the tx with the provided hash. Returns
 // asset.CoinNotFoundError if the tx is not found.
 // Part of the Wallet interface.
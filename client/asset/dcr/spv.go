@@ -66,6 +66,7 @@ type dcrWallet interface {
 	UnlockOutpoint(txHash *chainhash.Hash, index uint32)
 	LockOutpoint(txHash *chainhash.Hash, index uint32)
 	ListTransactionDetails(ctx context.Context, txHash *chainhash.Hash) ([]walletjson.ListTransactionsResult, error)
+	ListSinceBlock(ctx context.Context, start, end, syncHeight int32) ([]walletjson.ListTransactionsResult, error)
 	MainChainTip(ctx context.Context) (hash chainhash.Hash, height int32)
 	NewExternalAddress(ctx context.Context, account uint32, callOpts ...wallet.NextAddressCallOption) (stdaddr.Address, error)
 	NewInternalAddress(ctx context.Context, account uint32, callOpts ...wallet.NextAddressCallOption) (stdaddr.Address, error)
@@ -135,6 +136,7 @@ type spvWallet struct {
 
 var _ Wallet = (*spvWallet)(nil)
 var _ tipNotifier = (*spvWallet)(nil)
+var _ TxLister = (*spvWallet)(nil)
 
 func createSPVWallet(pw, seed []byte, dataDir string, extIdx, intIdx uint32, chainParams *chaincfg.Params) error {
 	dir := filepath.Join(dataDir, chainParams.Name, "spv")
@@ -641,6 +643,18 @@ func (w *spvWallet) GetBlock(ctx context.Context, blockHash *chainhash.Hash) (*w
 	return block, nil
 }
 
+// ListSinceBlock lists the wallet's transactions mined above the specified
+// block height, and any unmined transactions.
+// Part of the TxLister interface.
+func (w *spvWallet) ListSinceBlock(ctx context.Context, blockHeight int64) ([]walletjson.ListTransactionsResult, error) {
+	_, tipHeight := w.MainChainTip(ctx)
+	var start int32
+	if blockHeight > 0 {
+		start = int32(blockHeight) + 1
+	}
+	return w.dcrWallet.ListSinceBlock(ctx, start, -1, tipHeight)
+}
+
 // GetTransaction returns the details of a wallet tx, if the wallet contains a
 // tx with the provided hash. Returns asset.CoinNotFoundError if the tx is not
 // found in the wallet.
@@ -111,6 +111,10 @@ func (w *tDcrWallet) ListTransactionDetails(ctx context.Context, txHash *chainha
 	return w.listTxs, w.listTxsErr
 }
 
+func (w *tDcrWallet) ListSinceBlock(ctx context.Context, start, end, syncHeight int32) ([]walletjson.ListTransactionsResult, error) {
+	return w.listTxs, w.listTxsErr
+}
+
 func (w *tDcrWallet) MainChainTip(ctx context.Context) (hash chainhash.Hash, height int32) {
 	return w.tip.hash, w.tip.height
 }
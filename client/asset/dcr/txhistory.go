@@ -0,0 +1,185 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dcr
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/asset/txlog"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+// txHistoryScanMargin is the number of blocks below the tip that will be
+// listed again when searching for deposits, so that deposits in reorged blocks
+// are not missed.
+const txHistoryScanMargin = 6
+
+// openTxLog opens the tx log database in the wallet's data directory. Wallets
+// configured without a data directory do not keep a tx history.
+func (dcr *ExchangeWallet) openTxLog() error {
+	if dcr.dataDir == "" {
+		return nil
+	}
+	db, err := txlog.Open(filepath.Join(dcr.dataDir, dcr.chainParams.Name, "txhistory.db"))
+	if err != nil {
+		return fmt.Errorf("error opening tx history database: %w", err)
+	}
+	dcr.txLogMtx.Lock()
+	dcr.txLog = db
+	dcr.txLogMtx.Unlock()
+	return nil
+}
+
+// closeTxLog closes the tx log database, if open.
+func (dcr *ExchangeWallet) closeTxLog() {
+	dcr.txLogMtx.Lock()
+	defer dcr.txLogMtx.Unlock()
+	if dcr.txLog == nil {
+		return
+	}
+	if err := dcr.txLog.Close(); err != nil {
+		dcr.log.Errorf("Error closing tx history database: %v", err)
+	}
+	dcr.txLog = nil
+}
+
+// logTx stores a transaction broadcast by the wallet in the tx log. The
+// transaction is already sent, so errors are only logged.
+func (dcr *ExchangeWallet) logTx(tx *asset.WalletTransaction) {
+	dcr.txLogMtx.RLock()
+	defer dcr.txLogMtx.RUnlock()
+	if dcr.txLog == nil {
+		return
+	}
+	if err := dcr.txLog.StoreTx(tx); err != nil {
+		dcr.log.Errorf("Error storing %s transaction %s in tx history: %v", tx.Type, tx.ID, err)
+	}
+}
+
+// logSend stores a transaction sending value to an address in the tx log.
+func (dcr *ExchangeWallet) logSend(msgTx *wire.MsgTx, value uint64, address string) {
+	_, _, fees, _, _ := reduceMsgTx(msgTx)
+	dcr.logTx(&asset.WalletTransaction{
+		Type:      asset.Send,
+		ID:        msgTx.CachedTxHash().String(),
+		Amount:    value,
+		Fees:      fees,
+		Timestamp: uint64(time.Now().Unix()),
+		Recipient: address,
+	})
+}
+
+// TxHistory returns up to n of the wallet's transactions, newest first. If
+// refID is non-nil, only transactions older than refID are returned.
+// Part of the asset.WalletHistorian interface.
+func (dcr *ExchangeWallet) TxHistory(n int, refID *string) ([]*asset.WalletTransaction, error) {
+	dcr.txLogMtx.RLock()
+	defer dcr.txLogMtx.RUnlock()
+	if dcr.txLog == nil {
+		return nil, errors.New("tx history not available")
+	}
+	return dcr.txLog.Txs(n, refID)
+}
+
+// updateTxHistory sets the block height of logged transactions that have been
+// mined, and adds any new deposits to the tx log if the Wallet is a TxLister.
+func (dcr *ExchangeWallet) updateTxHistory(tipHeight int64) {
+	dcr.txHistoryMtx.Lock()
+	defer dcr.txHistoryMtx.Unlock()
+
+	dcr.txLogMtx.RLock()
+	defer dcr.txLogMtx.RUnlock()
+	db := dcr.txLog
+	if db == nil {
+		return
+	}
+
+	pending, err := db.PendingTxs()
+	if err != nil {
+		dcr.log.Errorf("Error retrieving pending transactions from tx history: %v", err)
+		return
+	}
+	for _, tx := range pending {
+		txHash, err := chainhash.NewHashFromStr(tx.ID)
+		if err != nil {
+			dcr.log.Errorf("Invalid tx hash %q in tx history: %v", tx.ID, err)
+			continue
+		}
+		wtx, err := dcr.wallet.GetTransaction(dcr.ctx, txHash)
+		if err != nil {
+			if !errors.Is(err, asset.CoinNotFoundError) {
+				dcr.log.Errorf("Error getting wallet transaction %s: %v", txHash, err)
+			}
+			continue
+		}
+		if wtx.Confirmations <= 0 {
+			continue
+		}
+		tx.BlockNumber = uint64(tipHeight - wtx.Confirmations + 1)
+		if err := db.StoreTx(tx); err != nil {
+			dcr.log.Errorf("Error updating transaction %s in tx history: %v", tx.ID, err)
+		}
+	}
+
+	lister, is := dcr.wallet.(TxLister)
+	if !is {
+		return
+	}
+	scanHeight, err := db.ScanHeight()
+	if err != nil {
+		dcr.log.Errorf("Error retrieving tx history scan height: %v", err)
+		return
+	}
+	txs, err := lister.ListSinceBlock(dcr.ctx, int64(scanHeight))
+	if err != nil {
+		dcr.log.Errorf("Error listing wallet transactions since block %d: %v", scanHeight, err)
+		return
+	}
+
+	// Swaps, redeems, refunds and sends are logged when they are broadcast.
+	// Transactions with send entries are funded by the wallet, so only the
+	// remaining receive entries are deposits.
+	deposits := make(map[string]*asset.WalletTransaction)
+	funded := make(map[string]bool)
+	for _, tx := range txs {
+		switch tx.Category {
+		case "send":
+			funded[tx.TxID] = true
+		case "receive":
+			dep, found := deposits[tx.TxID]
+			if !found {
+				dep = &asset.WalletTransaction{
+					Type:      asset.Receive,
+					ID:        tx.TxID,
+					Timestamp: uint64(tx.Time),
+				}
+				if tx.Confirmations > 0 {
+					dep.BlockNumber = uint64(tipHeight - tx.Confirmations + 1)
+				}
+				deposits[tx.TxID] = dep
+			}
+			dep.Amount += toAtoms(tx.Amount)
+		}
+	}
+	for txID, dep := range deposits {
+		if funded[txID] {
+			continue
+		}
+		if _, err := db.StoreNewTx(dep); err != nil {
+			dcr.log.Errorf("Error storing deposit %s in tx history: %v", txID, err)
+			return
+		}
+	}
+
+	if newScanHeight := tipHeight - txHistoryScanMargin; newScanHeight > int64(scanHeight) {
+		if err := db.SetScanHeight(uint64(newScanHeight)); err != nil {
+			dcr.log.Errorf("Error storing tx history scan height: %v", err)
+		}
+	}
+}
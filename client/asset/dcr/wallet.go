@@ -175,6 +175,14 @@ type Mempooler interface {
 	GetRawMempool(ctx context.Context) ([]*chainhash.Hash, error)
 }
 
+// TxLister is satisfied by a Wallet that can list its transactions.
+type TxLister interface {
+	// ListSinceBlock lists the wallet's transactions that are mined in blocks
+	// above the specified height, and any unmined transactions. All of the
+	// wallet's transactions are listed if blockHeight is 0.
+	ListSinceBlock(ctx context.Context, blockHeight int64) ([]walletjson.ListTransactionsResult, error)
+}
+
 // TxOutput defines properties of a transaction output, including the
 // details of the block containing the tx, if mined.
 type TxOutput struct {
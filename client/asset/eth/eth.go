@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/asset/txlog"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/config"
 	"decred.org/dcrdex/dex/encode"
@@ -248,6 +249,8 @@ var _ asset.AccountLocker = (*ETHWallet)(nil)
 var _ asset.AccountLocker = (*TokenWallet)(nil)
 var _ asset.TokenMaster = (*ETHWallet)(nil)
 var _ asset.WalletRestorer = (*assetWallet)(nil)
+var _ asset.WalletHistorian = (*ETHWallet)(nil)
+var _ asset.WalletHistorian = (*TokenWallet)(nil)
 
 type baseWallet struct {
 	ctx         context.Context // the asset subsystem starts with Connect(ctx)
@@ -256,6 +259,8 @@ type baseWallet struct {
 	addr        common.Address
 	log         dex.Logger
 	gasFeeLimit uint64
+	// dir is the wallet directory, where the tx history databases are kept.
+	dir string
 
 	walletsMtx sync.RWMutex
 	wallets    map[uint32]*assetWallet
@@ -288,6 +293,10 @@ type assetWallet struct {
 
 	evmify  func(uint64) *big.Int
 	atomize func(*big.Int) uint64
+
+	txLogMtx     sync.RWMutex
+	txLog        *txlog.DB
+	txHistoryMtx sync.Mutex // serializes updateTxHistory
 }
 
 // ETHWallet implements some Ethereum-specific methods.
@@ -365,7 +374,8 @@ func CreateWallet(createWalletParams *asset.CreateWalletParams) error {
 // NewWallet is the exported constructor by which the DEX will import the
 // exchange wallet. It starts an internal light node.
 func NewWallet(assetCFG *asset.WalletConfig, logger dex.Logger, net dex.Network) (*ETHWallet, error) {
-	cl, err := newNodeClient(getWalletDir(assetCFG.DataDir, net), net, logger.SubLogger("NODE"))
+	walletDir := getWalletDir(assetCFG.DataDir, net)
+	cl, err := newNodeClient(walletDir, net, logger.SubLogger("NODE"))
 	if err != nil {
 		return nil, err
 	}
@@ -386,6 +396,7 @@ func NewWallet(assetCFG *asset.WalletConfig, logger dex.Logger, net dex.Network)
 		node:        cl,
 		addr:        cl.address(),
 		gasFeeLimit: gasFeeLimit,
+		dir:         walletDir,
 		wallets:     make(map[uint32]*assetWallet),
 	}
 
@@ -416,6 +427,7 @@ func getWalletDir(dataDir string, network dex.Network) string {
 
 func (eth *ETHWallet) shutdown() {
 	eth.node.shutdown()
+	eth.closeTxLog()
 }
 
 // Connect connects to the node RPC server. Satisfies dex.Connector.
@@ -447,6 +459,10 @@ func (w *ETHWallet) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 	atomic.StoreInt64(&w.tipAtConnect, height.Int64())
 	w.log.Infof("Connected to geth, at height %d", height)
 
+	if err := w.openTxLog(); err != nil {
+		return nil, err
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -471,6 +487,10 @@ func (w *TokenWallet) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 		return nil, fmt.Errorf("parent not connected")
 	}
 
+	if err := w.openTxLog(); err != nil {
+		return nil, err
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -479,6 +499,7 @@ func (w *TokenWallet) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 		case <-ctx.Done():
 		case <-w.baseWallet.ctx.Done():
 		}
+		w.closeTxLog()
 	}()
 	return &wg, nil
 }
@@ -1285,6 +1306,8 @@ func (w *ETHWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, uint6
 		})
 	}
 
+	w.logSwap(txHash, swapVal, fees, swaps.Contracts)
+
 	var change asset.Coin
 	if swaps.LockChange {
 		w.unlockFunds(swapVal+fees, initiationReserve)
@@ -1358,6 +1381,8 @@ func (w *TokenWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, uin
 		})
 	}
 
+	w.logSwap(txHash, swapVal, fees, swaps.Contracts)
+
 	var change asset.Coin
 	if swaps.LockChange {
 		w.unlockFunds(swapVal, initiationReserve)
@@ -1400,6 +1425,7 @@ func (w *assetWallet) Redeem(form *asset.RedeemForm, feeWallet *assetWallet) ([]
 
 	var contractVer uint32 // require a consistent version since this is a single transaction
 	secrets := make([][32]byte, 0, n)
+	secretHashes := make([][32]byte, 0, n)
 	var redeemedValue uint64
 	for i, redemption := range form.Redemptions {
 		// NOTE: redemption.Spends.SecretHash is a dup of the hash extracted
@@ -1424,6 +1450,7 @@ func (w *assetWallet) Redeem(form *asset.RedeemForm, feeWallet *assetWallet) ([]
 		var secret [32]byte
 		copy(secret[:], redemption.Secret)
 		secrets = append(secrets, secret)
+		secretHashes = append(secretHashes, secretHash)
 		redeemable, err := w.isRedeemable(secretHash, secret, ver)
 		if err != nil {
 			return fail(fmt.Errorf("Redeem: failed to check if swap is redeemable: %w", err))
@@ -1516,6 +1543,8 @@ func (w *assetWallet) Redeem(form *asset.RedeemForm, feeWallet *assetWallet) ([]
 	// as has been discussed, then maybe the fees can be updated there.
 	fees := g.RedeemN(len(form.Redemptions)) * form.FeeSuggestion
 
+	w.logTx(asset.Redeem, txHash, redeemedValue, fees, "", secretHashes...)
+
 	return txs, outputCoin, fees, nil
 }
 
@@ -1983,6 +2012,7 @@ func (w *assetWallet) Refund(_, contract dex.Bytes, feeSuggestion uint64) (dex.B
 	}
 
 	txHash := tx.Hash()
+	w.logTx(asset.Refund, txHash, swap.Value, tx.Gas()*feeSuggestion, "", secretHash)
 	return txHash[:], nil
 }
 
@@ -2131,6 +2161,7 @@ func (w *ETHWallet) Send(addr string, value, _ uint64) (asset.Coin, error) {
 		return nil, err
 	}
 	txHash := tx.Hash()
+	w.logTx(asset.Send, txHash, value, maxFee, addr)
 	return &coin{id: txHash, value: value}, nil
 }
 
@@ -2176,6 +2207,7 @@ func (w *TokenWallet) Send(addr string, value, _ uint64) (asset.Coin, error) {
 		return nil, err
 	}
 	txHash := tx.Hash()
+	w.logTx(asset.Send, txHash, value, maxFee, addr)
 	return &coin{id: txHash, value: value}, nil
 }
 
@@ -2343,6 +2375,11 @@ func (eth *ETHWallet) checkForNewBlocks(reportErr func(error)) {
 			w.checkFindRedemptions()
 		}
 	}()
+	go func() {
+		for _, w := range eth.walletList() {
+			w.updateTxHistory(bestHdr.Number.Uint64())
+		}
+	}()
 }
 
 // checkFindRedemptions checks queued findRedemptionRequests.
@@ -107,6 +107,8 @@ type testNode struct {
 	tokenContractor *tTokenContractor
 	contractor      contractor
 	tokenParent     *assetWallet // only set for tokens
+	txConfs         uint32
+	txConfsErr      error
 }
 
 func newBalance(current, in, out uint64) *Balance {
@@ -206,7 +208,7 @@ func tTx(gasFeeCap, gasTipCap, value uint64, to *common.Address, data []byte) *t
 }
 
 func (n *testNode) transactionConfirmations(context.Context, common.Hash) (uint32, error) {
-	return 0, nil
+	return n.txConfs, n.txConfsErr
 }
 
 type tContractor struct {
@@ -3475,6 +3477,71 @@ func testSend(t *testing.T, assetID uint32) {
 	}
 }
 
+func TestTxHistory(t *testing.T) {
+	t.Run("eth", func(t *testing.T) { testTxHistory(t, BipID) })
+	t.Run("token", func(t *testing.T) { testTxHistory(t, testTokenID) })
+}
+
+func testTxHistory(t *testing.T, assetID uint32) {
+	w, eth, node, shutdown := tassetWallet(assetID)
+	defer shutdown()
+
+	historian := w.(asset.WalletHistorian)
+	if _, err := historian.TxHistory(0, nil); err == nil {
+		t.Fatalf("no error for TxHistory without a tx log")
+	}
+
+	eth.dir = t.TempDir()
+	if err := eth.openTxLog(); err != nil {
+		t.Fatalf("openTxLog error: %v", err)
+	}
+	defer eth.closeTxLog()
+
+	tx := tTx(0, 0, 0, &testAddressA, nil)
+	node.sendTxTx = tx
+	node.tokenContractor.transferTx = tx
+
+	maxFeeRate, _ := eth.recommendedMaxFeeRate(eth.ctx)
+	fees := dexeth.WeiToGwei(maxFeeRate) * defaultSendGasLimit
+	const val = 10e9
+	if assetID == BipID {
+		node.bal = dexeth.GweiToWei(val + fees)
+	} else {
+		fees = dexeth.WeiToGwei(maxFeeRate) * tokenGases.Transfer
+		node.tokenContractor.bal = dexeth.GweiToWei(val)
+		node.bal = dexeth.GweiToWei(fees)
+	}
+	const testAddr = "dd93b447f7eBCA361805eBe056259853F3912E04"
+	if _, err := w.Send(testAddr, val, 0); err != nil {
+		t.Fatalf("Send error: %v", err)
+	}
+
+	txs, err := historian.TxHistory(0, nil)
+	if err != nil {
+		t.Fatalf("TxHistory error: %v", err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("expected 1 tx, got %d", len(txs))
+	}
+	sent := txs[0]
+	if sent.Type != asset.Send || sent.ID != tx.Hash().String() || sent.Amount != val ||
+		sent.Fees != fees || sent.Recipient != testAddr {
+		t.Fatalf("wrong send logged: %+v", sent)
+	}
+
+	// Not mined.
+	eth.updateTxHistory(100)
+	if txs, _ = historian.TxHistory(0, nil); txs[0].BlockNumber != 0 {
+		t.Fatalf("unmined tx has block number %d", txs[0].BlockNumber)
+	}
+
+	node.txConfs = 2
+	eth.updateTxHistory(100)
+	if txs, _ = historian.TxHistory(0, nil); txs[0].BlockNumber != 99 {
+		t.Fatalf("wrong block number %d", txs[0].BlockNumber)
+	}
+}
+
 func parseRecoveryID(c asset.Coin) []byte {
 	return c.(asset.RecoveryCoin).RecoveryID()
 }
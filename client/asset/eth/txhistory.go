@@ -0,0 +1,130 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+//go:build lgpl
+
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/asset/txlog"
+	"decred.org/dcrdex/dex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// openTxLog opens the asset's tx log database. The ETH wallet and each of its
+// token wallets keep separate tx logs in the ETH wallet directory. There is no
+// tx log if the wallet directory is not set.
+func (w *assetWallet) openTxLog() error {
+	if w.dir == "" {
+		return nil
+	}
+	path := filepath.Join(w.dir, fmt.Sprintf("txhistory_%s.db", dex.BipIDSymbol(w.assetID)))
+	db, err := txlog.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening tx history database: %w", err)
+	}
+	w.txLogMtx.Lock()
+	w.txLog = db
+	w.txLogMtx.Unlock()
+	return nil
+}
+
+// closeTxLog closes the tx log database, if open.
+func (w *assetWallet) closeTxLog() {
+	w.txLogMtx.Lock()
+	defer w.txLogMtx.Unlock()
+	if w.txLog == nil {
+		return
+	}
+	if err := w.txLog.Close(); err != nil {
+		w.log.Errorf("Error closing tx history database: %v", err)
+	}
+	w.txLog = nil
+}
+
+// logTx stores a transaction sent by the wallet in the tx log. Errors are only
+// logged, since the transaction has already been sent.
+func (w *assetWallet) logTx(typ asset.TransactionType, txHash common.Hash, amt, fees uint64, recipient string, secretHashes ...[32]byte) {
+	w.txLogMtx.RLock()
+	defer w.txLogMtx.RUnlock()
+	if w.txLog == nil {
+		return
+	}
+	tx := &asset.WalletTransaction{
+		Type:      typ,
+		ID:        txHash.String(),
+		Amount:    amt,
+		Fees:      fees,
+		Timestamp: uint64(time.Now().Unix()),
+		Recipient: recipient,
+	}
+	for i := range secretHashes {
+		tx.SecretHashes = append(tx.SecretHashes, secretHashes[i][:])
+	}
+	if err := w.txLog.StoreTx(tx); err != nil {
+		w.log.Errorf("Error storing %s transaction %s in tx history: %v", typ, tx.ID, err)
+	}
+}
+
+// logSwap stores a swap initiation transaction in the tx log.
+func (w *assetWallet) logSwap(txHash common.Hash, swapVal, fees uint64, contracts []*asset.Contract) {
+	secretHashes := make([][32]byte, len(contracts))
+	for i, c := range contracts {
+		copy(secretHashes[i][:], c.SecretHash)
+	}
+	w.logTx(asset.Swap, txHash, swapVal, fees, "", secretHashes...)
+}
+
+// TxHistory returns up to n of the wallet's transactions, newest first. If
+// refID is non-nil, only transactions older than refID are returned. Deposits
+// are not included, since there is no index of transactions by address.
+// Part of the asset.WalletHistorian interface.
+func (w *assetWallet) TxHistory(n int, refID *string) ([]*asset.WalletTransaction, error) {
+	w.txLogMtx.RLock()
+	defer w.txLogMtx.RUnlock()
+	if w.txLog == nil {
+		return nil, errors.New("tx history not available")
+	}
+	return w.txLog.Txs(n, refID)
+}
+
+// updateTxHistory sets the block number of logged transactions that have been
+// mined.
+func (w *assetWallet) updateTxHistory(tipHeight uint64) {
+	w.txHistoryMtx.Lock()
+	defer w.txHistoryMtx.Unlock()
+
+	w.txLogMtx.RLock()
+	defer w.txLogMtx.RUnlock()
+	if w.txLog == nil {
+		return
+	}
+
+	pending, err := w.txLog.PendingTxs()
+	if err != nil {
+		w.log.Errorf("Error retrieving pending transactions from tx history: %v", err)
+		return
+	}
+	for _, tx := range pending {
+		confs, err := w.node.transactionConfirmations(w.ctx, common.HexToHash(tx.ID))
+		if err != nil {
+			if !errors.Is(err, asset.CoinNotFoundError) {
+				w.log.Errorf("Error getting confirmations for transaction %s: %v", tx.ID, err)
+			}
+			continue
+		}
+		if confs == 0 {
+			continue
+		}
+		tx.BlockNumber = tipHeight - uint64(confs) + 1
+		if err := w.txLog.StoreTx(tx); err != nil {
+			w.log.Errorf("Error updating transaction %s in tx history: %v", tx.ID, err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/dex"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
 
 // WalletTrait is a bitset indicating various optional wallet features, such as
@@ -15,15 +16,19 @@ import (
 type WalletTrait uint64
 
 const (
-	WalletTraitRescanner    WalletTrait = 1 << iota // The Wallet is an asset.Rescanner.
-	WalletTraitNewAddresser                         // The Wallet can generate new addresses on demand with NewAddress.
-	WalletTraitLogFiler                             // The Wallet allows for downloading of a log file.
-	WalletTraitFeeRater                             // Wallet can provide a fee rate for non-critical transactions
-	WalletTraitAccelerator                          // This wallet can accelerate transactions using the CPFP technique
-	WalletTraitRecoverer                            // The wallet is an asset.Recoverer.
-	WalletTraitWithdrawer                           // The Wallet can withdraw a specific amount from an exchange wallet.
-	WalletTraitSweeper                              // The Wallet can sweep all the funds, leaving no change.
-	WalletTraitRestorer                             // The wallet is an asset.WalletRestorer
+	WalletTraitRescanner      WalletTrait = 1 << iota // The Wallet is an asset.Rescanner.
+	WalletTraitNewAddresser                           // The Wallet can generate new addresses on demand with NewAddress.
+	WalletTraitLogFiler                               // The Wallet allows for downloading of a log file.
+	WalletTraitFeeRater                               // Wallet can provide a fee rate for non-critical transactions
+	WalletTraitAccelerator                            // This wallet can accelerate transactions using the CPFP technique
+	WalletTraitRecoverer                              // The wallet is an asset.Recoverer.
+	WalletTraitWithdrawer                             // The Wallet can withdraw a specific amount from an exchange wallet.
+	WalletTraitSweeper                                // The Wallet can sweep all the funds, leaving no change.
+	WalletTraitRestorer                               // The wallet is an asset.WalletRestorer
+	WalletTraitBonder                                 // The wallet is an asset.Bonder
+	WalletTraitHistorian                              // The wallet is an asset.WalletHistorian
+	WalletTraitFeeBumper                              // The wallet is an asset.FeeBumper
+	WalletTraitCoinController                         // The wallet is an asset.CoinController
 )
 
 // IsRescanner tests if the WalletTrait has the WalletTraitRescanner bit set.
@@ -80,6 +85,31 @@ func (wt WalletTrait) IsRestorer() bool {
 	return wt&WalletTraitRestorer != 0
 }
 
+// IsBonder tests if the WalletTrait has the WalletTraitBonder bit set, which
+// indicates the presence of a Bonder interface.
+func (wt WalletTrait) IsBonder() bool {
+	return wt&WalletTraitBonder != 0
+}
+
+// IsHistorian tests if the WalletTrait has the WalletTraitHistorian bit set,
+// which indicates the wallet implements the WalletHistorian interface.
+func (wt WalletTrait) IsHistorian() bool {
+	return wt&WalletTraitHistorian != 0
+}
+
+// IsFeeBumper tests if the WalletTrait has the WalletTraitFeeBumper bit set,
+// which indicates the wallet can bump the fees of redemption and refund
+// transactions.
+func (wt WalletTrait) IsFeeBumper() bool {
+	return wt&WalletTraitFeeBumper != 0
+}
+
+// IsCoinController tests if the WalletTrait has the WalletTraitCoinController
+// bit set, which indicates the wallet implements the CoinController interface.
+func (wt WalletTrait) IsCoinController() bool {
+	return wt&WalletTraitCoinController != 0
+}
+
 // DetermineWalletTraits returns the WalletTrait bitset for the provided Wallet.
 func DetermineWalletTraits(w Wallet) (t WalletTrait) {
 	if _, is := w.(Rescanner); is {
@@ -109,6 +139,18 @@ func DetermineWalletTraits(w Wallet) (t WalletTrait) {
 	if _, is := w.(WalletRestorer); is {
 		t |= WalletTraitRestorer
 	}
+	if _, is := w.(Bonder); is {
+		t |= WalletTraitBonder
+	}
+	if _, is := w.(WalletHistorian); is {
+		t |= WalletTraitHistorian
+	}
+	if _, is := w.(FeeBumper); is {
+		t |= WalletTraitFeeBumper
+	}
+	if _, is := w.(CoinController); is {
+		t |= WalletTraitCoinController
+	}
 	return t
 }
 
@@ -126,6 +168,15 @@ const (
 	ErrConnectionDown   = dex.ErrorKind("wallet not connected")
 	ErrNotImplemented   = dex.ErrorKind("not implemented")
 	ErrUnsupported      = dex.ErrorKind("unsupported")
+	// ErrTxNotFound is returned from TxHistory when the reference transaction
+	// is not in the wallet's transaction history.
+	ErrTxNotFound = dex.ErrorKind("transaction not found")
+	// ErrIncorrectBondKey is returned when a provided private key is incorrect
+	// for a bond output.
+	ErrIncorrectBondKey = dex.ErrorKind("incorrect private key")
+	// ErrTxConfirmed is returned from FeeBumper methods when the transaction
+	// has already been mined, and there is no need to bump its fees.
+	ErrTxConfirmed = dex.ErrorKind("transaction already confirmed")
 
 	// InternalNodeLoggerName is the name for a logger that is used to fine
 	// tune log levels for only loggers using this name.
@@ -445,6 +496,43 @@ type WalletRestorer interface {
 	RestorationInfo(seed []byte) ([]*WalletRestoration, error)
 }
 
+// Bond is the fidelity bond info generated for a certain account ID, amount,
+// and lock time. These data are intended for the "post bond" request, in which
+// the client publishes the signed transaction and then submits the bond's coin
+// ID to the server. The caller should manage the private key.
+type Bond struct {
+	Version uint16
+	AssetID uint32
+	Amount  uint64
+	CoinID  []byte
+	Data    []byte // additional data to interpret the bond e.g. redeem script, bond contract, etc.
+	// SignedTx and UnsignedTx are the opaque (raw bytes) signed and unsigned
+	// bond creation transactions, in whatever encoding and funding scheme for
+	// this asset and wallet.
+	SignedTx, UnsignedTx []byte
+	// RedeemTx is a backup transaction that spends the bond output after the
+	// lock time. Normally, RefundBond is used with the bond key when the bond
+	// expires.
+	RedeemTx []byte
+}
+
+// Bonder is a wallet capable of creating and redeeming time-locked fidelity
+// bonds.
+type Bonder interface {
+	// MakeBondTx authors a DEX time-locked fidelity bond transaction for the
+	// provided amount, lock time, and dex account ID. The bond key, which is
+	// required to refund the bond after the lock time expires, is provided by
+	// the caller. The returned Bond contains the signed transaction, which is
+	// NOT broadcast.
+	MakeBondTx(ver uint16, amt, feeRate uint64, lockTime time.Time, bondKey *secp256k1.PrivateKey, acctID []byte) (*Bond, error)
+	// RefundBond will refund the bond given the full bond output details and
+	// private key to spend it. The bond is broadcast and the refund coin ID is
+	// returned.
+	RefundBond(ctx context.Context, ver uint16, coinID, script []byte, amt uint64, privKey *secp256k1.PrivateKey) ([]byte, error)
+	// SendTransaction broadcasts a raw transaction, returning its coin ID.
+	SendTransaction(rawTx []byte) ([]byte, error)
+}
+
 // EarlyAcceleration is returned from the PreAccelerate function to inform the
 // user that either their last acceleration or oldest swap transaction happened
 // very recently, and that they should double check that they really want to do
@@ -492,6 +580,40 @@ type Accelerator interface {
 	PreAccelerate(swapCoins, accelerationCoins []dex.Bytes, changeCoin dex.Bytes, requiredForRemainingSwaps, feeSuggestion uint64) (uint64, *XYRange, *EarlyAcceleration, error)
 }
 
+// FeeBumper is implemented by wallets which can replace their unconfirmed
+// redemption and refund transactions with transactions paying a higher fee
+// rate, e.g. using the Replace-By-Fee technique. This can be used to get a
+// redemption mined before the counterparty is able to refund the redeemed
+// contract.
+type FeeBumper interface {
+	// BumpableFeeRate returns the fee rate of the unconfirmed redemption or
+	// refund transaction with the specified coin ID, as returned by Redeem or
+	// Refund. ErrTxConfirmed is returned if the transaction has been mined.
+	BumpableFeeRate(coinID dex.Bytes) (uint64, error)
+	// BumpFee replaces the unconfirmed redemption or refund transaction with
+	// the specified coin ID with a transaction paying newFeeRate, which must
+	// be greater than the current fee rate. The replacement spends the same
+	// contracts. The returned map is keyed by the hex-encoded coin IDs of the
+	// replaced transaction, including coinID, and the values are the
+	// corresponding coin IDs of the replacement. The additional fees paid by
+	// the replacement are also returned. ErrTxConfirmed is returned if the
+	// transaction has been mined.
+	BumpFee(coinID dex.Bytes, newFeeRate uint64) (replacements map[string]dex.Bytes, fees uint64, err error)
+}
+
+// CoinController is a wallet that allows the user to choose the outputs that
+// fund an order or a send.
+type CoinController interface {
+	// ListUnspent lists the wallet's spendable outputs. Outputs locked to fund
+	// orders are not included.
+	ListUnspent() ([]*UnspentCoin, error)
+	// SendWithCoins sends the value to the address, spending all of the
+	// specified coins, which must be spendable outputs as returned by
+	// ListUnspent. Any change is returned to the wallet. The value does not
+	// include fees, which are paid from the change.
+	SendWithCoins(address string, value, feeRate uint64, coinIDs []dex.Bytes) (Coin, error)
+}
+
 // TokenMaster is implemented by assets which support degenerate tokens.
 type TokenMaster interface {
 	// CreateTokenWallet creates a wallet for the specified token asset. The
@@ -533,6 +655,24 @@ type AccountLocker interface {
 	UnlockRefundReserves(uint64)
 }
 
+// MultiOrderFunder is implemented by wallets that can fund several orders in
+// a single call, e.g. with one split transaction for all of the orders.
+type MultiOrderFunder interface {
+	// FundMultiOrder selects coins for each of the orders in the MultiOrder.
+	// The returned coins and redeem scripts are in the same order as the
+	// MultiOrder's Values. Either all of the orders are funded, or none are.
+	// As with FundOrder, the coins are locked until returned with
+	// ReturnCoins.
+	FundMultiOrder(ord *MultiOrder) (coins []Coins, redeemScripts [][]dex.Bytes, err error)
+}
+
+// FeeReportingRefunder is a wallet that can report the fees paid for a refund.
+type FeeReportingRefunder interface {
+	// RefundWithFees is like Refund, but also returns the fees paid by the
+	// refund transaction.
+	RefundWithFees(coinID, contract dex.Bytes, feeSuggestion uint64) (refundCoin dex.Bytes, fees uint64, err error)
+}
+
 // LiveReconfigurer is a wallet that can possibly handle a reconfiguration
 // without the need for re-initialization.
 type LiveReconfigurer interface {
@@ -542,6 +682,95 @@ type LiveReconfigurer interface {
 	Reconfigure(ctx context.Context, cfg *WalletConfig, currentAddress string) (restartRequired bool, err error)
 }
 
+// TransactionType is the type of a WalletTransaction.
+type TransactionType uint16
+
+const (
+	// Unknown is a transaction of unknown type.
+	Unknown TransactionType = iota
+	// Send is a transaction sending funds to an external address, e.g. a
+	// withdrawal.
+	Send
+	// Receive is a transaction funded externally that pays to the wallet, e.g.
+	// a deposit.
+	Receive
+	// Swap is a swap contract initiation transaction.
+	Swap
+	// Redeem is a swap contract redemption transaction.
+	Redeem
+	// Refund is a swap contract refund transaction.
+	Refund
+)
+
+// String returns a human-readable name for the transaction type.
+func (t TransactionType) String() string {
+	switch t {
+	case Send:
+		return "send"
+	case Receive:
+		return "receive"
+	case Swap:
+		return "swap"
+	case Redeem:
+		return "redeem"
+	case Refund:
+		return "refund"
+	default:
+		return "unknown"
+	}
+}
+
+// WalletTransaction is a transaction in a wallet's transaction history.
+type WalletTransaction struct {
+	Type TransactionType `json:"type"`
+	// ID is the transaction ID, e.g. a transaction hash.
+	ID string `json:"id"`
+	// Amount is the amount sent, received, swapped, redeemed or refunded, not
+	// including fees.
+	Amount uint64 `json:"amount"`
+	// Fees is the transaction fee paid by the wallet. Fees will be zero for
+	// received transactions. For some assets, this may be the maximum fees
+	// that the transaction could pay rather than the actual fees. Fees for
+	// token transactions are in the units of the parent asset.
+	Fees uint64 `json:"fees"`
+	// BlockNumber is the height of the block containing the transaction, or
+	// zero if the transaction is not mined.
+	BlockNumber uint64 `json:"blockNumber"`
+	// Timestamp is the UNIX time, in seconds, that the transaction was sent
+	// or first seen by the wallet.
+	Timestamp uint64 `json:"timestamp"`
+	// Recipient is the destination address of a Send.
+	Recipient string `json:"recipient,omitempty"`
+	// SecretHashes are the secret hashes of the swap contracts initiated,
+	// redeemed or refunded by the transaction, and can be used to reference
+	// the corresponding DEX matches.
+	SecretHashes []dex.Bytes `json:"secretHashes,omitempty"`
+}
+
+// WalletHistorian is a wallet that keeps a record of its transactions.
+type WalletHistorian interface {
+	// TxHistory returns up to n of the wallet's transactions, most recent
+	// first. If refID is non-nil, the transactions returned are those older
+	// than the transaction with that ID, which can be used to page through
+	// the history. If n is <= 0, all matching transactions are returned.
+	// ErrTxNotFound is returned if the refID transaction is not found.
+	TxHistory(n int, refID *string) ([]*WalletTransaction, error)
+}
+
+// UnspentCoin is a spendable wallet output that can be selected to fund an
+// order or a send.
+type UnspentCoin struct {
+	// ID is the coin ID, as would be returned by Coin.ID.
+	ID dex.Bytes `json:"id"`
+	// Value is the value of the output, in the asset's smallest unit.
+	Value uint64 `json:"value"`
+	// Address is the wallet address that the output pays to.
+	Address string `json:"address"`
+	// Confirmations is the number of confirmations of the transaction that
+	// created the output.
+	Confirmations uint32 `json:"confs"`
+}
+
 // Balance is categorized information about a wallet's balance.
 type Balance struct {
 	// Available is the balance that is available for trading immediately.
@@ -701,4 +930,34 @@ type Order struct {
 	// Options are options that corresponds to PreSwap.Options, as well as
 	// their values.
 	Options map[string]string
+	// Coins are the IDs of user-selected coins that must fund the order. If
+	// Coins is non-empty, all of the coins are used, no others are selected,
+	// and no split transaction is made. The wallet must be a CoinController.
+	Coins []dex.Bytes
+}
+
+// MultiOrderValue is the value and lot count of one of the orders in a
+// MultiOrder.
+type MultiOrderValue struct {
+	// Value is the amount required to satisfy the order, not including fees.
+	// See Order.Value.
+	Value uint64
+	// MaxSwapCount is the number of lots in the order. See
+	// Order.MaxSwapCount.
+	MaxSwapCount uint64
+}
+
+// MultiOrder is the details needed for FundMultiOrder. The orders are all
+// standing limit orders on the same market and side.
+type MultiOrder struct {
+	Values []*MultiOrderValue
+	// DEXConfig and RedeemConfig are as in Order.
+	DEXConfig    *dex.Asset
+	RedeemConfig *dex.Asset
+	// FeeSuggestion is a suggested fee from the server. If a split transaction
+	// is used, the fee rate used should be at least the suggested fee.
+	FeeSuggestion uint64
+	// Options are options that corresponds to PreSwap.Options, as well as
+	// their values.
+	Options map[string]string
 }
@@ -0,0 +1,289 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package txlog provides a persistent record of a wallet's transactions, which
+// wallets can use to implement asset.WalletHistorian without needing to rescan
+// the blockchain.
+package txlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex/encode"
+	"go.etcd.io/bbolt"
+)
+
+// Bolt works on []byte keys and values. These are the buckets and keys used
+// by the tx log.
+var (
+	// txsBucket stores the JSON-encoded transactions, keyed by an 8-byte
+	// big-endian sequence number, so iteration is in the order that the
+	// transactions were first logged.
+	txsBucket = []byte("txs")
+	// txIDsBucket maps transaction IDs to their txsBucket keys.
+	txIDsBucket = []byte("txids")
+	// pendingBucket indexes the IDs of transactions that are not yet mined.
+	pendingBucket = []byte("pending")
+	// txDataBucket stores wallet-specific data for transactions, keyed by
+	// transaction ID. The data is opaque to the tx log.
+	txDataBucket  = []byte("txdata")
+	metaBucket    = []byte("meta")
+	versionKey    = []byte("version")
+	scanHeightKey = []byte("scanHeight")
+)
+
+// dbVersion is the current version of the tx log database.
+const dbVersion = 0
+
+// DB is a bbolt-backed transaction log.
+type DB struct {
+	*bbolt.DB
+}
+
+// Open opens the tx log database at path, creating it if it does not exist.
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("error creating tx log directory: %w", err)
+	}
+	bdb, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 3 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = bdb.Update(func(dbTx *bbolt.Tx) error {
+		for _, name := range [][]byte{txsBucket, txIDsBucket, pendingBucket, txDataBucket, metaBucket} {
+			if _, err := dbTx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("error creating %s bucket: %w", string(name), err)
+			}
+		}
+		meta := dbTx.Bucket(metaBucket)
+		if verB := meta.Get(versionKey); verB != nil {
+			if ver := encode.BytesToUint32(verB); ver > dbVersion {
+				return fmt.Errorf("unknown tx log version %d", ver)
+			}
+			return nil
+		}
+		return meta.Put(versionKey, encode.Uint32Bytes(dbVersion))
+	})
+	if err != nil {
+		bdb.Close()
+		return nil, err
+	}
+	return &DB{bdb}, nil
+}
+
+// StoreTx stores the transaction, replacing any transaction with the same ID.
+// A replaced transaction keeps its position in the history.
+func (db *DB) StoreTx(tx *asset.WalletTransaction) error {
+	_, err := db.storeTx(tx, true)
+	return err
+}
+
+// StoreNewTx stores the transaction only if there is not already a
+// transaction with the same ID. The return value indicates whether the
+// transaction was stored.
+func (db *DB) StoreNewTx(tx *asset.WalletTransaction) (bool, error) {
+	return db.storeTx(tx, false)
+}
+
+func (db *DB) storeTx(tx *asset.WalletTransaction, replace bool) (stored bool, err error) {
+	txB, err := json.Marshal(tx)
+	if err != nil {
+		return false, fmt.Errorf("error encoding transaction: %w", err)
+	}
+	id := []byte(tx.ID)
+	return stored, db.Update(func(dbTx *bbolt.Tx) error {
+		txs, ids := dbTx.Bucket(txsBucket), dbTx.Bucket(txIDsBucket)
+		k := ids.Get(id)
+		if k != nil && !replace {
+			return nil
+		}
+		if k == nil {
+			seq, err := txs.NextSequence()
+			if err != nil {
+				return err
+			}
+			k = encode.Uint64Bytes(seq)
+			if err := ids.Put(id, k); err != nil {
+				return err
+			}
+		}
+		if err := txs.Put(k, txB); err != nil {
+			return err
+		}
+		pending := dbTx.Bucket(pendingBucket)
+		if tx.BlockNumber == 0 {
+			err = pending.Put(id, []byte{})
+		} else {
+			err = pending.Delete(id)
+		}
+		stored = err == nil
+		return err
+	})
+}
+
+// ReplaceTx stores tx in place of the transaction with ID oldID, e.g. when a
+// transaction is replaced with one paying a higher fee. The replacement takes
+// the position of the replaced transaction in the history. If oldID is not in
+// the log, tx is stored as with StoreTx.
+func (db *DB) ReplaceTx(oldID string, tx *asset.WalletTransaction) error {
+	txB, err := json.Marshal(tx)
+	if err != nil {
+		return fmt.Errorf("error encoding transaction: %w", err)
+	}
+	return db.Update(func(dbTx *bbolt.Tx) error {
+		txs, ids, pending := dbTx.Bucket(txsBucket), dbTx.Bucket(txIDsBucket), dbTx.Bucket(pendingBucket)
+		k := ids.Get([]byte(oldID))
+		if k == nil {
+			seq, err := txs.NextSequence()
+			if err != nil {
+				return err
+			}
+			k = encode.Uint64Bytes(seq)
+		} else {
+			k = append([]byte(nil), k...) // only valid for the life of the tx
+			if err := ids.Delete([]byte(oldID)); err != nil {
+				return err
+			}
+			if err := pending.Delete([]byte(oldID)); err != nil {
+				return err
+			}
+		}
+		id := []byte(tx.ID)
+		if err := ids.Put(id, k); err != nil {
+			return err
+		}
+		if err := txs.Put(k, txB); err != nil {
+			return err
+		}
+		if tx.BlockNumber == 0 {
+			return pending.Put(id, []byte{})
+		}
+		return pending.Delete(id)
+	})
+}
+
+// Tx retrieves the transaction with the specified ID. asset.ErrTxNotFound is
+// returned if the transaction is not in the log.
+func (db *DB) Tx(id string) (tx *asset.WalletTransaction, err error) {
+	return tx, db.View(func(dbTx *bbolt.Tx) error {
+		k := dbTx.Bucket(txIDsBucket).Get([]byte(id))
+		if k == nil {
+			return asset.ErrTxNotFound
+		}
+		tx, err = decodeTx(dbTx.Bucket(txsBucket).Get(k))
+		return err
+	})
+}
+
+// Txs returns up to n transactions, most recently logged first. If refID is
+// non-nil, only transactions logged before the refID transaction are
+// returned. If n <= 0, all matching transactions are returned. Txs satisfies
+// the asset.WalletHistorian interface's TxHistory semantics.
+func (db *DB) Txs(n int, refID *string) (txs []*asset.WalletTransaction, err error) {
+	return txs, db.View(func(dbTx *bbolt.Tx) error {
+		c := dbTx.Bucket(txsBucket).Cursor()
+		var k, v []byte
+		if refID != nil {
+			refK := dbTx.Bucket(txIDsBucket).Get([]byte(*refID))
+			if refK == nil {
+				return asset.ErrTxNotFound
+			}
+			c.Seek(refK)
+			k, v = c.Prev()
+		} else {
+			k, v = c.Last()
+		}
+		for ; k != nil && (n <= 0 || len(txs) < n); k, v = c.Prev() {
+			tx, err := decodeTx(v)
+			if err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+		return nil
+	})
+}
+
+// PendingTxs returns the logged transactions that are not yet mined.
+func (db *DB) PendingTxs() (txs []*asset.WalletTransaction, err error) {
+	return txs, db.View(func(dbTx *bbolt.Tx) error {
+		ids, txsBkt := dbTx.Bucket(txIDsBucket), dbTx.Bucket(txsBucket)
+		return dbTx.Bucket(pendingBucket).ForEach(func(id, _ []byte) error {
+			k := ids.Get(id)
+			if k == nil {
+				return fmt.Errorf("pending transaction %s not indexed", string(id))
+			}
+			tx, err := decodeTx(txsBkt.Get(k))
+			if err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+			return nil
+		})
+	})
+}
+
+// StoreTxData stores wallet-specific data for the transaction with the
+// specified ID, e.g. the information needed to replace the transaction. The
+// transaction does not need to be in the log.
+func (db *DB) StoreTxData(id string, b []byte) error {
+	return db.Update(func(dbTx *bbolt.Tx) error {
+		return dbTx.Bucket(txDataBucket).Put([]byte(id), b)
+	})
+}
+
+// TxData retrieves the data stored with StoreTxData. asset.ErrTxNotFound is
+// returned if there is no data for the transaction.
+func (db *DB) TxData(id string) (b []byte, err error) {
+	return b, db.View(func(dbTx *bbolt.Tx) error {
+		v := dbTx.Bucket(txDataBucket).Get([]byte(id))
+		if v == nil {
+			return asset.ErrTxNotFound
+		}
+		b = append([]byte(nil), v...) // only valid for the life of the tx
+		return nil
+	})
+}
+
+// DeleteTxData deletes the data stored with StoreTxData. It is not an error if
+// there is no data for the transaction.
+func (db *DB) DeleteTxData(id string) error {
+	return db.Update(func(dbTx *bbolt.Tx) error {
+		return dbTx.Bucket(txDataBucket).Delete([]byte(id))
+	})
+}
+
+// ScanHeight is the last block height recorded with SetScanHeight, or zero if
+// no height has been recorded.
+func (db *DB) ScanHeight() (h uint64, err error) {
+	return h, db.View(func(dbTx *bbolt.Tx) error {
+		if b := dbTx.Bucket(metaBucket).Get(scanHeightKey); len(b) == 8 {
+			h = encode.IntCoder.Uint64(b)
+		}
+		return nil
+	})
+}
+
+// SetScanHeight records the block height up to which the wallet has scanned
+// for transactions that it did not create, such as deposits.
+func (db *DB) SetScanHeight(h uint64) error {
+	return db.Update(func(dbTx *bbolt.Tx) error {
+		return dbTx.Bucket(metaBucket).Put(scanHeightKey, encode.Uint64Bytes(h))
+	})
+}
+
+func decodeTx(b []byte) (*asset.WalletTransaction, error) {
+	if b == nil {
+		return nil, fmt.Errorf("transaction not found")
+	}
+	tx := new(asset.WalletTransaction)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, fmt.Errorf("error decoding transaction: %w", err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,231 @@
+package txlog
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"decred.org/dcrdex/client/asset"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "txlog.db"))
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestTxs(t *testing.T) {
+	db := newTestDB(t)
+
+	const numTxs = 10
+	for i := 0; i < numTxs; i++ {
+		if err := db.StoreTx(&asset.WalletTransaction{
+			Type:   asset.Send,
+			ID:     strconv.Itoa(i),
+			Amount: uint64(i),
+		}); err != nil {
+			t.Fatalf("StoreTx error: %v", err)
+		}
+	}
+
+	checkIDs := func(tag string, txs []*asset.WalletTransaction, ids ...int) {
+		t.Helper()
+		if len(txs) != len(ids) {
+			t.Fatalf("%s: wanted %d txs, got %d", tag, len(ids), len(txs))
+		}
+		for i, tx := range txs {
+			if tx.ID != strconv.Itoa(ids[i]) {
+				t.Fatalf("%s: wanted tx %d at index %d, got %s", tag, ids[i], i, tx.ID)
+			}
+		}
+	}
+
+	txs, err := db.Txs(3, nil)
+	if err != nil {
+		t.Fatalf("Txs error: %v", err)
+	}
+	checkIDs("most recent", txs, 9, 8, 7)
+
+	refID := txs[2].ID
+	txs, err = db.Txs(3, &refID)
+	if err != nil {
+		t.Fatalf("Txs error with ref ID: %v", err)
+	}
+	checkIDs("second page", txs, 6, 5, 4)
+
+	refID = "1"
+	txs, err = db.Txs(3, &refID)
+	if err != nil {
+		t.Fatalf("Txs error for last page: %v", err)
+	}
+	checkIDs("last page", txs, 0)
+
+	txs, err = db.Txs(0, nil)
+	if err != nil {
+		t.Fatalf("Txs error for all txs: %v", err)
+	}
+	if len(txs) != numTxs {
+		t.Fatalf("wanted %d txs, got %d", numTxs, len(txs))
+	}
+
+	refID = "nope"
+	if _, err = db.Txs(3, &refID); !errors.Is(err, asset.ErrTxNotFound) {
+		t.Fatalf("wanted ErrTxNotFound for unknown ref ID, got %v", err)
+	}
+
+	// Replacing a tx keeps its position.
+	if err := db.StoreTx(&asset.WalletTransaction{Type: asset.Redeem, ID: "5", BlockNumber: 100}); err != nil {
+		t.Fatalf("StoreTx error for replacement: %v", err)
+	}
+	refID = "6"
+	txs, err = db.Txs(1, &refID)
+	if err != nil {
+		t.Fatalf("Txs error after replacement: %v", err)
+	}
+	checkIDs("replaced", txs, 5)
+	if txs[0].Type != asset.Redeem || txs[0].BlockNumber != 100 {
+		t.Fatalf("tx not replaced")
+	}
+
+	// StoreNewTx does not replace.
+	stored, err := db.StoreNewTx(&asset.WalletTransaction{Type: asset.Receive, ID: "5"})
+	if err != nil {
+		t.Fatalf("StoreNewTx error: %v", err)
+	}
+	if stored {
+		t.Fatalf("existing tx replaced by StoreNewTx")
+	}
+	tx, err := db.Tx("5")
+	if err != nil {
+		t.Fatalf("Tx error: %v", err)
+	}
+	if tx.Type != asset.Redeem {
+		t.Fatalf("wrong tx type %s", tx.Type)
+	}
+	if _, err := db.Tx("nope"); !errors.Is(err, asset.ErrTxNotFound) {
+		t.Fatalf("wanted ErrTxNotFound, got %v", err)
+	}
+}
+
+func TestPendingTxs(t *testing.T) {
+	db := newTestDB(t)
+
+	store := func(id string, blockNumber uint64) {
+		t.Helper()
+		if err := db.StoreTx(&asset.WalletTransaction{ID: id, BlockNumber: blockNumber}); err != nil {
+			t.Fatalf("StoreTx error: %v", err)
+		}
+	}
+	checkPending := func(wantN int) {
+		t.Helper()
+		txs, err := db.PendingTxs()
+		if err != nil {
+			t.Fatalf("PendingTxs error: %v", err)
+		}
+		if len(txs) != wantN {
+			t.Fatalf("wanted %d pending txs, got %d", wantN, len(txs))
+		}
+	}
+
+	store("a", 0)
+	store("b", 0)
+	store("c", 10)
+	checkPending(2)
+
+	store("a", 11)
+	checkPending(1)
+}
+
+func TestReplaceTx(t *testing.T) {
+	db := newTestDB(t)
+
+	for _, id := range []string{"a", "b", "c"} {
+		if err := db.StoreTx(&asset.WalletTransaction{ID: id}); err != nil {
+			t.Fatalf("StoreTx error: %v", err)
+		}
+	}
+
+	if err := db.ReplaceTx("b", &asset.WalletTransaction{ID: "b2", Fees: 2}); err != nil {
+		t.Fatalf("ReplaceTx error: %v", err)
+	}
+	txs, err := db.Txs(0, nil)
+	if err != nil {
+		t.Fatalf("Txs error: %v", err)
+	}
+	if len(txs) != 3 || txs[1].ID != "b2" || txs[1].Fees != 2 {
+		t.Fatalf("replacement not in place of replaced tx: %+v", txs)
+	}
+	if _, err := db.Tx("b"); !errors.Is(err, asset.ErrTxNotFound) {
+		t.Fatalf("wanted ErrTxNotFound for replaced tx, got %v", err)
+	}
+	pending, err := db.PendingTxs()
+	if err != nil {
+		t.Fatalf("PendingTxs error: %v", err)
+	}
+	for _, tx := range pending {
+		if tx.ID == "b" {
+			t.Fatalf("replaced tx still pending")
+		}
+	}
+	if len(pending) != 3 {
+		t.Fatalf("wanted 3 pending txs, got %d", len(pending))
+	}
+
+	// Replacing an unknown tx stores the new tx.
+	if err := db.ReplaceTx("x", &asset.WalletTransaction{ID: "d", BlockNumber: 5}); err != nil {
+		t.Fatalf("ReplaceTx error for unknown tx: %v", err)
+	}
+	if txs, _ = db.Txs(1, nil); len(txs) != 1 || txs[0].ID != "d" {
+		t.Fatalf("unknown replaced tx not stored as newest tx")
+	}
+}
+
+func TestScanHeight(t *testing.T) {
+	db := newTestDB(t)
+	h, err := db.ScanHeight()
+	if err != nil {
+		t.Fatalf("ScanHeight error: %v", err)
+	}
+	if h != 0 {
+		t.Fatalf("wanted initial scan height 0, got %d", h)
+	}
+	if err := db.SetScanHeight(1234); err != nil {
+		t.Fatalf("SetScanHeight error: %v", err)
+	}
+	if h, _ = db.ScanHeight(); h != 1234 {
+		t.Fatalf("wanted scan height 1234, got %d", h)
+	}
+}
+
+func TestTxData(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := db.TxData("a"); !errors.Is(err, asset.ErrTxNotFound) {
+		t.Fatalf("wanted ErrTxNotFound for missing data, got %v", err)
+	}
+	if err := db.StoreTxData("a", []byte{1, 2}); err != nil {
+		t.Fatalf("StoreTxData error: %v", err)
+	}
+	b, err := db.TxData("a")
+	if err != nil {
+		t.Fatalf("TxData error: %v", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2}) {
+		t.Fatalf("wrong data %x", b)
+	}
+	if err := db.DeleteTxData("a"); err != nil {
+		t.Fatalf("DeleteTxData error: %v", err)
+	}
+	if _, err := db.TxData("a"); !errors.Is(err, asset.ErrTxNotFound) {
+		t.Fatalf("wanted ErrTxNotFound for deleted data, got %v", err)
+	}
+	if err := db.DeleteTxData("a"); err != nil {
+		t.Fatalf("DeleteTxData error for missing data: %v", err)
+	}
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"runtime"
 
 	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/core"
 	"decred.org/dcrdex/dex"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/slog"
@@ -27,6 +29,7 @@ const (
 	defaultRPCPort     = "5757"
 	defaultWebPort     = "5758"
 	configFilename     = "dexc.conf"
+	botsFilename       = "mm_bots.json"
 	defaultLogLevel    = "debug"
 )
 
@@ -100,6 +103,11 @@ type Config struct {
 	Onion        string `long:"onion" description:"Proxy for .onion addresses, if torproxy not set (eg. 127.0.0.1:9050)."`
 	Net          dex.Network
 	CertHosts    []string
+
+	OracleConfig    string  `long:"oraclecfg" description:"Path to a JSON file listing HTTP price oracle sources."`
+	OracleThreshold float64 `long:"oraclethreshold" description:"Fractional deviation of a limit order rate from the oracle price that triggers a warning (default 0.05)."`
+	// OracleSources are loaded from the OracleConfig file.
+	OracleSources []*core.OracleSource
 }
 
 var defaultConfig = Config{
@@ -215,5 +223,15 @@ func configure() (*Config, error) {
 		cfg.DBPath = defaultDBPath
 	}
 
+	if cfg.OracleConfig != "" {
+		b, err := os.ReadFile(dex.CleanAndExpandPath(cfg.OracleConfig))
+		if err != nil {
+			return nil, fmt.Errorf("error reading oracle config: %w", err)
+		}
+		if err := json.Unmarshal(b, &cfg.OracleSources); err != nil {
+			return nil, fmt.Errorf("error parsing oracle config: %w", err)
+		}
+	}
+
 	return cfg, nil
 }
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"runtime/debug"
 	"runtime/pprof"
@@ -25,6 +26,7 @@ import (
 	_ "decred.org/dcrdex/client/asset/zec"  // register zec asset
 
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/client/rpcserver"
 	"decred.org/dcrdex/client/webserver"
 	"decred.org/dcrdex/dex"
@@ -103,6 +105,9 @@ func mainCore() error {
 		TorIsolation: cfg.TorIsolation,
 		Onion:        cfg.Onion,
 		Language:     cfg.Language,
+
+		OracleSources:   cfg.OracleSources,
+		OracleThreshold: cfg.OracleThreshold,
 	})
 	if err != nil {
 		return fmt.Errorf("error creating client core: %w", err)
@@ -132,6 +137,23 @@ func mainCore() error {
 
 	<-clientCore.Ready()
 
+	marketMaker, err := mm.New(&mm.Config{
+		Core:     clientCore,
+		Oracle:   clientCore.Oracle(),
+		BotsFile: filepath.Join(netDirectory, botsFilename),
+		Logger:   logMaker.Logger("MM"),
+	})
+	if err != nil {
+		cancel()
+		wg.Wait()
+		return fmt.Errorf("error creating market maker: %w", err)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		marketMaker.Run(appCtx)
+	}()
+
 	defer func() {
 		log.Info("Exiting dexc main.")
 		cancel()  // no-op with clean rpc/web server setup
@@ -170,6 +192,7 @@ func mainCore() error {
 			Key:         cfg.RPCKey,
 			DexcVersion: dexcVersion,
 			CertHosts:   cfg.CertHosts,
+			MarketMaker: marketMaker,
 		}
 		rpcSrv, err := rpcserver.New(rpcCfg)
 		if err != nil {
@@ -199,6 +222,7 @@ func mainCore() error {
 			ReloadHTML:    cfg.ReloadHTML,
 			HttpProf:      cfg.HTTPProfile,
 			Language:      cfg.Language,
+			MarketMaker:   marketMaker,
 		})
 		if err != nil {
 			return fmt.Errorf("failed creating web server: %w", err)
@@ -58,6 +58,10 @@ var promptPasswords = map[string][]string{
 	"withdraw":     {"App password:"},
 	"send":         {"App password:"},
 	"appseed":      {"App password:"},
+	"createbot":    {"App password:"},
+	"startbot":     {"App password:"},
+	"stoporder":    {"App password:"},
+	"multitrade":   {"App password:"},
 }
 
 // optionalTextFiles is a map of routes to arg index for routes that should read
@@ -70,6 +74,12 @@ var optionalTextFiles = map[string]int{
 	"newwallet":    2,
 }
 
+// joinedArgs is a set of routes for which all cmd args are joined with spaces
+// into a single arg, e.g. the words of a mnemonic seed.
+var joinedArgs = map[string]bool{
+	"init": true,
+}
+
 // promptPWs prompts for passwords on stdin and returns an error if prompting
 // fails or a password is empty. Returns passwords as a slice of []byte. If
 // cmdPWs is provided, the passwords will be drawn from cmdPWs instead of stdin
@@ -159,6 +169,9 @@ func run(ctx context.Context) error {
 		}
 		params = append(params, arg)
 	}
+	if joinedArgs[args[0]] && len(params) > 1 {
+		params = []string{strings.Join(params, " ")}
+	}
 
 	// Prompt for passwords.
 	pws, err := promptPWs(ctx, args[0], cfg.PasswordArgs)
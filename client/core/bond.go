@@ -0,0 +1,804 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/encrypt"
+	"decred.org/dcrdex/dex/keygen"
+	"decred.org/dcrdex/dex/msgjson"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/hdkeychain/v3"
+)
+
+// bondCheckInterval is how often the bond maintenance loop checks for expired
+// bonds, refundable bonds, and the need to post new bonds.
+const bondCheckInterval = time.Minute
+
+// bondOpts returns the DEX's supported bond assets, keyed by asset ID, and the
+// bond expiry duration. The map is nil if the server config is not known.
+func (dc *dexConnection) bondOpts() (map[uint32]*msgjson.BondAsset, time.Duration) {
+	dc.cfgMtx.RLock()
+	defer dc.cfgMtx.RUnlock()
+	if dc.cfg == nil {
+		return nil, 0
+	}
+	bondAssets := make(map[uint32]*msgjson.BondAsset, len(dc.cfg.BondAssets))
+	for _, bondAsset := range dc.cfg.BondAssets {
+		bondAssets[bondAsset.ID] = bondAsset
+	}
+	return bondAssets, time.Duration(dc.cfg.BondExpiry) * time.Second
+}
+
+// setPendingBondConfs records the current confirmation count for a pending
+// bond.
+func (dc *dexConnection) setPendingBondConfs(coinIDStr string, confs uint32) {
+	dc.pendingBondsMtx.Lock()
+	if dc.pendingBondsConfs == nil {
+		dc.pendingBondsConfs = make(map[string]uint32)
+	}
+	dc.pendingBondsConfs[coinIDStr] = confs
+	dc.pendingBondsMtx.Unlock()
+}
+
+// clearPendingBondConfs removes the confirmation count for a bond that is no
+// longer pending.
+func (dc *dexConnection) clearPendingBondConfs(coinIDStr string) {
+	dc.pendingBondsMtx.Lock()
+	delete(dc.pendingBondsConfs, coinIDStr)
+	dc.pendingBondsMtx.Unlock()
+}
+
+// pendingBonds returns the PendingBondState for each of the account's bonds
+// that have not yet been accepted by the server.
+func (dc *dexConnection) pendingBonds() []*PendingBondState {
+	dc.acct.authMtx.RLock()
+	defer dc.acct.authMtx.RUnlock()
+	if len(dc.acct.pendingBonds) == 0 {
+		return nil
+	}
+	dc.pendingBondsMtx.RLock()
+	defer dc.pendingBondsMtx.RUnlock()
+	pbs := make([]*PendingBondState, 0, len(dc.acct.pendingBonds))
+	for _, bond := range dc.acct.pendingBonds {
+		coinIDStr := coinIDString(bond.AssetID, bond.CoinID)
+		pbs = append(pbs, &PendingBondState{
+			Symbol:  unbip(bond.AssetID),
+			AssetID: bond.AssetID,
+			CoinID:  coinIDStr,
+			Confs:   dc.pendingBondsConfs[coinIDStr],
+		})
+	}
+	return pbs
+}
+
+// setBondKeys derives the extended key from which bond keys are derived. This
+// is done on login so that expired bonds may be refunded and new bonds posted
+// without the app password.
+func (c *Core) setBondKeys(crypter encrypt.Crypter) error {
+	creds := c.creds()
+	if creds == nil {
+		return errors.New("primary credentials not set")
+	}
+	seed, err := crypter.Decrypt(creds.EncSeed)
+	if err != nil {
+		return fmt.Errorf("seed decryption error: %w", err)
+	}
+	defer encode.ClearBytes(seed)
+
+	bondXPriv, err := keygen.GenDeepChild(seed, []uint32{HDKeyPurposeBonds})
+	if err != nil {
+		return fmt.Errorf("GenDeepChild error: %w", err)
+	}
+
+	c.bondXPrivMtx.Lock()
+	if c.bondXPriv != nil {
+		c.bondXPriv.Zero()
+	}
+	c.bondXPriv = bondXPriv
+	c.bondXPrivMtx.Unlock()
+	return nil
+}
+
+// clearBondKeys zeros and clears the bond extended key.
+func (c *Core) clearBondKeys() {
+	c.bondXPrivMtx.Lock()
+	if c.bondXPriv != nil {
+		c.bondXPriv.Zero()
+		c.bondXPriv = nil
+	}
+	c.bondXPrivMtx.Unlock()
+}
+
+// bondKeysReady checks if the bond extended key has been derived.
+func (c *Core) bondKeysReady() bool {
+	c.bondXPrivMtx.RLock()
+	defer c.bondXPrivMtx.RUnlock()
+	return c.bondXPriv != nil
+}
+
+// bondKeyIdx derives the private key for the bond with the specified asset
+// and key index. The caller should Zero the key when done with it.
+func (c *Core) bondKeyIdx(assetID, keyIndex uint32) (*secp256k1.PrivateKey, error) {
+	if keyIndex >= hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("maximum key generation reached, cannot generate %dth key", keyIndex)
+	}
+
+	c.bondXPrivMtx.RLock()
+	defer c.bondXPrivMtx.RUnlock()
+	if c.bondXPriv == nil {
+		return nil, errors.New("bond keys not prepared, log in first")
+	}
+
+	assetKey, err := c.bondXPriv.Child(assetID%hdkeychain.HardenedKeyStart + hdkeychain.HardenedKeyStart)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving asset key: %w", err)
+	}
+	defer assetKey.Zero()
+
+	bondKey, err := assetKey.Child(keyIndex + hdkeychain.HardenedKeyStart)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving bond key: %w", err)
+	}
+	defer bondKey.Zero()
+
+	privB, err := bondKey.SerializedPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("SerializedPrivKey error: %w", err)
+	}
+	defer encode.ClearBytes(privB)
+	return secp256k1.PrivKeyFromBytes(privB), nil
+}
+
+// PostBond begins the process of posting a new fidelity bond for a new or
+// existing DEX account. If the account is new, the DEX configuration is
+// fetched and the account is discovered or created. The bond transaction is
+// broadcast immediately. A goroutine is started to wait for the requisite
+// confirmations and post the bond to the server. Any error returned from that
+// goroutine is sent as a notification.
+func (c *Core) PostBond(form *PostBondForm) (*PostBondResult, error) {
+	// Make sure the app has been initialized.
+	if !c.IsInitialized() {
+		return nil, fmt.Errorf("cannot post bond because app has not been initialized")
+	}
+
+	// Check the app password.
+	crypter, err := c.encryptionKey(form.AppPass)
+	if err != nil {
+		return nil, codedError(passwordErr, err)
+	}
+	defer crypter.Close()
+	if form.Addr == "" {
+		return nil, newError(emptyHostErr, "no dex address specified")
+	}
+	host, err := addrHost(form.Addr)
+	if err != nil {
+		return nil, newError(addressParseErr, "error parsing address: %w", err)
+	}
+
+	// Default to using DCR unless specified.
+	bondAssetID := uint32(42)
+	if form.Asset != nil {
+		bondAssetID = *form.Asset
+	}
+	bondAssetSymbol := dex.BipIDSymbol(bondAssetID)
+
+	wallet, err := c.connectedWallet(bondAssetID)
+	if err != nil {
+		// Wrap the error from connectedWallet, a core.Error coded as
+		// missingWalletErr or connectWalletErr.
+		return nil, fmt.Errorf("cannot connect to %s wallet to post bond: %w", bondAssetSymbol, err)
+	}
+	bonder, ok := wallet.bonder()
+	if !ok {
+		return nil, newError(assetSupportErr, "%s wallet does not support fidelity bonds", bondAssetSymbol)
+	}
+	if !wallet.unlocked() {
+		err = wallet.Unlock(crypter)
+		if err != nil {
+			return nil, newError(walletAuthErr, "failed to unlock %s wallet: %w", unbip(wallet.AssetID), err)
+		}
+	}
+
+	if !c.bondKeysReady() {
+		if err = c.setBondKeys(crypter); err != nil {
+			return nil, newError(acctKeyErr, "unable to prepare bond keys: %w", err)
+		}
+	}
+
+	// Use the existing connection if there is one. Otherwise, connect to the
+	// DEX, which will be added to the conns map once the account is discovered
+	// or created.
+	c.connMtx.RLock()
+	dc, acctExists := c.conns[host]
+	c.connMtx.RUnlock()
+	if acctExists {
+		if dc.acct.locked() {
+			if err = dc.acct.unlock(crypter); err != nil {
+				return nil, newError(acctKeyErr, "failed to unlock account: %w", err)
+			}
+		}
+	} else {
+		cert, err := parseCert(host, form.Cert, c.net)
+		if err != nil {
+			return nil, newError(fileReadErr, "failed to read certificate file from %s: %w", cert, err)
+		}
+		dc, err = c.connectDEX(&db.AccountInfo{
+			Host: host,
+			Cert: cert,
+		})
+		if err != nil {
+			if dc != nil {
+				// Stop (re)connect loop, which may be running even if err != nil.
+				dc.connMaster.Disconnect()
+			}
+			return nil, codedError(connectionErr, err)
+		}
+		// Close the connection to the dex server if the account is not
+		// established.
+		defer func() {
+			if !acctExists {
+				dc.connMaster.Disconnect()
+			}
+		}()
+	}
+
+	// Ensure this DEX supports this asset for bonds, and get the required
+	// confirmations and bond increment.
+	bondAssets, bondExpiry := dc.bondOpts()
+	bondAsset := bondAssets[bondAssetID]
+	if bondAsset == nil {
+		return nil, newError(assetSupportErr, "dex server does not support fidelity bonds in asset %q", bondAssetSymbol)
+	}
+	if bondAsset.Amt == 0 || form.Bond == 0 || form.Bond%bondAsset.Amt != 0 {
+		return nil, newError(bondAmtErr, "bond amount %d is not a multiple of the tier increment %d",
+			form.Bond, bondAsset.Amt)
+	}
+	lockTime := time.Now().Add(2 * bondExpiry).Truncate(time.Second)
+	if form.LockTime > 0 {
+		lockTime = time.Unix(int64(form.LockTime), 0)
+	}
+	if time.Until(lockTime) <= bondExpiry {
+		return nil, newError(bondTimeErr, "lock time of %v is too soon, must be after %v",
+			lockTime, time.Now().Add(bondExpiry))
+	}
+
+	// Make sure we have sufficient funds.
+	balance, err := wallet.Balance()
+	if err != nil {
+		return nil, newError(walletErr, "unable to retrieve wallet balance for %v: %w",
+			bondAssetSymbol, err)
+	}
+	if balance.Available <= form.Bond {
+		return nil, newError(walletBalanceErr, "insufficient balance for bond %v, have %v, "+
+			"but need bond amount plus network fees", form.Bond, balance.Available)
+	}
+
+	if !acctExists {
+		paid, err := c.discoverAccount(dc, crypter)
+		if err != nil {
+			return nil, err
+		}
+		if !paid {
+			// dc.acct is now configured with encKey, privKey, and id for a new
+			// (unregistered) account. Store it so the bond may be recorded.
+			err = c.db.CreateAccount(&db.AccountInfo{
+				Host:        dc.acct.host,
+				Cert:        dc.acct.cert,
+				DEXPubKey:   dc.acct.dexPubKey,
+				EncKeyV2:    dc.acct.encKey,
+				BondAssetID: bondAssetID,
+			})
+			if err != nil {
+				return nil, newError(dbErr, "error saving new account: %w", err)
+			}
+			dc.acct.authMtx.Lock()
+			dc.acct.bondAsset = bondAssetID
+			dc.acct.authMtx.Unlock()
+		}
+		acctExists = true
+		// The listen goroutine is already running, now track the conn.
+		c.connMtx.Lock()
+		c.conns[dc.acct.host] = dc
+		c.connMtx.Unlock()
+	}
+
+	return c.makeAndPostBond(dc, wallet, bonder, bondAsset, form.Bond, lockTime)
+}
+
+// makeAndPostBond creates and broadcasts a new bond transaction, stores the
+// bond, and starts monitoring its confirmations so it may be posted to the
+// server.
+func (c *Core) makeAndPostBond(dc *dexConnection, wallet *xcWallet, bonder asset.Bonder,
+	bondAsset *msgjson.BondAsset, amt uint64, lockTime time.Time) (*PostBondResult, error) {
+
+	assetID := bondAsset.ID
+	keyIndex, err := c.db.NextBondKeyIndex(assetID)
+	if err != nil {
+		return nil, newError(dbErr, "error getting bond key index: %w", err)
+	}
+	bondKey, err := c.bondKeyIdx(assetID, keyIndex)
+	if err != nil {
+		return nil, newError(acctKeyErr, "error deriving bond key: %w", err)
+	}
+	defer bondKey.Zero()
+
+	acctID := dc.acct.ID()
+	feeRate := c.feeSuggestionAny(assetID, dc)
+	bond, err := bonder.MakeBondTx(bondAsset.Version, amt, feeRate, lockTime, bondKey, acctID[:])
+	if err != nil {
+		return nil, newError(bondPostErr, "error creating bond transaction: %w", err)
+	}
+
+	bondCoinStr := coinIDString(assetID, bond.CoinID)
+	dbBond := &db.Bond{
+		Version:    bond.Version,
+		AssetID:    assetID,
+		CoinID:     bond.CoinID,
+		UnsignedTx: bond.UnsignedTx,
+		SignedTx:   bond.SignedTx,
+		Data:       bond.Data,
+		Amount:     amt,
+		LockTime:   uint64(lockTime.Unix()),
+		KeyIndex:   keyIndex,
+		RefundTx:   bond.RedeemTx,
+		// Confirmed set with ConfirmBond after postbond.
+	}
+	// Save the bond before broadcasting it, so that a bond is never locked
+	// without a record of the refund transaction.
+	if err = c.db.AddBond(dc.acct.host, dbBond); err != nil {
+		return nil, newError(dbErr, "error saving bond %s: %w", bondCoinStr, err)
+	}
+
+	c.log.Infof("Broadcasting bond %s of %d units of %s for account %v at %s, lock time %v. "+
+		"Backup refund transaction: %x", bondCoinStr, amt, unbip(assetID), acctID, dc.acct.host,
+		lockTime, bond.RedeemTx)
+	coinID, err := bonder.SendTransaction(bond.SignedTx)
+	if err != nil {
+		// The saved bond is kept in case the transaction was broadcast
+		// despite the error.
+		return nil, newError(bondPostErr, "error broadcasting bond transaction: %w", err)
+	}
+	if !bytes.Equal(coinID, bond.CoinID) {
+		c.log.Warnf("Broadcast bond transaction coin ID %s does not match expected %s",
+			coinIDString(assetID, coinID), bondCoinStr)
+	}
+
+	dc.acct.authMtx.Lock()
+	dc.acct.pendingBonds = append(dc.acct.pendingBonds, dbBond)
+	dc.acct.authMtx.Unlock()
+
+	c.updateAssetBalance(assetID)
+
+	subject, details := c.formatDetails(TopicBondConfirming, bondAsset.Confs, bondCoinStr, unbip(assetID), dc.acct.host)
+	c.notify(newBondPostNoteWithConfirmations(TopicBondConfirming, subject, details, db.Success, assetID, 0, dc.acct.host))
+
+	// Set up the coin waiter, which waits for the required number of
+	// confirmations to post the bond to the DEX.
+	c.monitorBondConfs(dc, dbBond, bondAsset.Confs)
+
+	return &PostBondResult{BondID: bondCoinStr, ReqConfirms: uint16(bondAsset.Confs)}, nil
+}
+
+// monitorBondConfs waits for the required number of confirmations of the bond
+// transaction, and then posts the bond to the server.
+func (c *Core) monitorBondConfs(dc *dexConnection, bond *db.Bond, reqConfs uint32) {
+	assetID := bond.AssetID
+	coinIDStr := coinIDString(assetID, bond.CoinID)
+	dc.setPendingBondConfs(coinIDStr, 0)
+
+	trigger := func() (bool, error) {
+		wallet, found := c.wallet(assetID)
+		if !found || !wallet.connected() {
+			return false, nil // keep waiting
+		}
+		confs, err := wallet.RegFeeConfirmations(c.ctx, bond.CoinID)
+		if err != nil && !errors.Is(err, asset.CoinNotFoundError) {
+			return false, fmt.Errorf("error getting confirmations for %s: %w", coinIDStr, err)
+		}
+
+		if confs < reqConfs {
+			dc.setPendingBondConfs(coinIDStr, confs)
+			subject, details := c.formatDetails(TopicBondConfirming, reqConfs, coinIDStr, unbip(assetID), dc.acct.host)
+			c.notify(newBondPostNoteWithConfirmations(TopicBondConfirming, subject, details, db.Data, assetID, int32(confs), dc.acct.host))
+		}
+
+		return confs >= reqConfs, nil
+	}
+
+	c.wait(bond.CoinID, assetID, trigger, func(err error) {
+		if err == nil {
+			c.log.Debugf("Bond txn %s now has %d confirmations. Posting to %s.", coinIDStr, reqConfs, dc.acct.host)
+			err = c.postBond(dc, bond)
+		}
+		if err != nil {
+			subject, details := c.formatDetails(TopicBondPostError, dc.acct.host, err)
+			c.notify(newBondPostNote(TopicBondPostError, subject, details, db.ErrorLevel, dc.acct.host))
+		}
+	})
+}
+
+// resumePendingBonds starts monitoring any bonds that were broadcast but not
+// yet accepted by the server, such as when the app was restarted before the
+// bond transaction reached the required number of confirmations.
+func (c *Core) resumePendingBonds(dc *dexConnection) {
+	dc.acct.authMtx.RLock()
+	pending := make([]*db.Bond, len(dc.acct.pendingBonds))
+	copy(pending, dc.acct.pendingBonds)
+	dc.acct.authMtx.RUnlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	bondAssets, _ := dc.bondOpts()
+	for _, bond := range pending {
+		coinIDStr := coinIDString(bond.AssetID, bond.CoinID)
+		bondAsset := bondAssets[bond.AssetID]
+		if bondAsset == nil {
+			c.log.Warnf("Unable to resume monitoring of bond %s at %s. Bond asset %s not reported by server.",
+				coinIDStr, dc.acct.host, unbip(bond.AssetID))
+			continue
+		}
+		c.waiterMtx.RLock()
+		_, found := c.blockWaiters[coinIDStr]
+		c.waiterMtx.RUnlock()
+		if found {
+			continue
+		}
+		c.log.Infof("Resuming monitoring of pending bond %s for %s.", coinIDStr, dc.acct.host)
+		c.monitorBondConfs(dc, bond, bondAsset.Confs)
+	}
+}
+
+// postBond sends the 'postbond' request for a bond that has the required
+// number of confirmations, and records the bond as confirmed when the server
+// accepts it.
+func (c *Core) postBond(dc *dexConnection, bond *db.Bond) error {
+	if dc.acct.locked() {
+		return fmt.Errorf("%s account locked. cannot post bond. log in first", dc.acct.host)
+	}
+	dc.acct.keyMtx.RLock()
+	acctPubKey := dc.acct.privKey.PubKey().SerializeCompressed()
+	dc.acct.keyMtx.RUnlock()
+
+	postBond := &msgjson.PostBond{
+		AcctPubKey: acctPubKey,
+		AssetID:    bond.AssetID,
+		Version:    bond.Version,
+		CoinID:     bond.CoinID,
+	}
+
+	// The server waits an unspecified amount of time to discover the
+	// transaction, so time out after this DEX's configured broadcast timeout
+	// plus a healthy buffer, as with 'notifyfee'.
+	dc.cfgMtx.RLock()
+	timeout := time.Millisecond*time.Duration(dc.cfg.BroadcastTimeout) + 10*time.Second
+	dc.cfgMtx.RUnlock()
+
+	postBondRes := new(msgjson.PostBondResult)
+	err := dc.signAndRequest(postBond, msgjson.PostBondRoute, postBondRes, timeout)
+	if err != nil {
+		return fmt.Errorf("'postbond' request error: %w", err)
+	}
+	err = dc.acct.checkSig(postBondRes.Serialize(), postBondRes.Sig)
+	if err != nil {
+		c.log.Warnf("Bond was accepted, but DEX signature could not be verified: %v", err)
+	}
+	acctID := dc.acct.ID()
+	if !bytes.Equal(postBondRes.AccountID, acctID[:]) {
+		return fmt.Errorf("server reported account ID %x, expected %v", postBondRes.AccountID, acctID)
+	}
+	if postBondRes.AssetID != bond.AssetID || !bytes.Equal(postBondRes.BondID, bond.CoinID) {
+		return fmt.Errorf("server reported bond %s, expected %s",
+			coinIDString(postBondRes.AssetID, postBondRes.BondID), coinIDString(bond.AssetID, bond.CoinID))
+	}
+
+	c.bondConfirmed(dc, bond.AssetID, bond.CoinID, postBondRes.Tier)
+	return nil
+}
+
+// bondConfirmed records a pending bond as confirmed and active, updates the
+// account tier, and authenticates the account if it has not been already.
+func (c *Core) bondConfirmed(dc *dexConnection, assetID uint32, coinID []byte, tier int64) {
+	coinIDStr := coinIDString(assetID, coinID)
+	if err := c.db.ConfirmBond(dc.acct.host, assetID, coinID); err != nil {
+		c.log.Errorf("Error marking bond %s as confirmed: %v", coinIDStr, err)
+	}
+
+	dc.acct.authMtx.Lock()
+	for i, bond := range dc.acct.pendingBonds {
+		if bond.AssetID == assetID && bytes.Equal(bond.CoinID, coinID) {
+			bond.Confirmed = true
+			dc.acct.bonds = append(dc.acct.bonds, bond)
+			dc.acct.pendingBonds = append(dc.acct.pendingBonds[:i], dc.acct.pendingBonds[i+1:]...)
+			break
+		}
+	}
+	dc.acct.tier = tier
+	dc.acct.authMtx.Unlock()
+	dc.clearPendingBondConfs(coinIDStr)
+
+	c.log.Infof("Bond %s accepted by %s. New tier = %d.", coinIDStr, dc.acct.host, tier)
+	subject, details := c.formatDetails(TopicBondConfirmed, coinIDStr, unbip(assetID), dc.acct.host, tier)
+	c.notify(newBondPostNoteWithTier(TopicBondConfirmed, subject, details, db.Success, dc.acct.host, tier))
+
+	// A new account, or one that was suspended because its tier dropped, must
+	// (re)authenticate to refresh its status.
+	if !dc.acct.authed() || dc.acct.suspended() {
+		if err := c.authDEX(dc); err != nil {
+			c.log.Errorf("Bond accepted, but failed to authenticate connection to %s: %v", dc.acct.host, err)
+			subject, details := c.formatDetails(TopicDexAuthError, dc.acct.host, err)
+			c.notify(newDEXAuthNote(TopicDexAuthError, subject, dc.acct.host, false, details, db.ErrorLevel))
+		}
+	}
+}
+
+// reconcileBonds updates the account's bonds using the active bonds reported
+// by the server in the 'connect' response. Pending bonds that the server
+// reports were accepted while we were away. Active bonds that the server no
+// longer reports have expired.
+func (c *Core) reconcileBonds(dc *dexConnection, activeBonds []*msgjson.Bond) {
+	serverBonds := make(map[string]*msgjson.Bond, len(activeBonds))
+	for _, bond := range activeBonds {
+		serverBonds[string(db.BondUID(bond.AssetID, bond.CoinID))] = bond
+	}
+
+	var accepted []*db.Bond
+	dc.acct.authMtx.Lock()
+	known := make(map[string]bool, len(dc.acct.bonds)+len(dc.acct.pendingBonds))
+	var stillPending []*db.Bond
+	for _, bond := range dc.acct.pendingBonds {
+		uid := string(bond.UniqueID())
+		known[uid] = true
+		if serverBonds[uid] == nil {
+			stillPending = append(stillPending, bond)
+			continue
+		}
+		bond.Confirmed = true
+		dc.acct.bonds = append(dc.acct.bonds, bond)
+		accepted = append(accepted, bond)
+	}
+	dc.acct.pendingBonds = stillPending
+	var active []*db.Bond
+	for _, bond := range dc.acct.bonds {
+		uid := string(bond.UniqueID())
+		known[uid] = true
+		if serverBonds[uid] != nil {
+			active = append(active, bond)
+			continue
+		}
+		c.log.Infof("Bond %s is no longer active at %s.", coinIDString(bond.AssetID, bond.CoinID), dc.acct.host)
+		dc.acct.expiredBonds = append(dc.acct.expiredBonds, bond)
+	}
+	dc.acct.bonds = active
+	dc.acct.authMtx.Unlock()
+
+	for uid, bond := range serverBonds {
+		if !known[uid] {
+			c.log.Warnf("Server %s reported an unknown active bond %s.", dc.acct.host,
+				coinIDString(bond.AssetID, bond.CoinID))
+		}
+	}
+
+	for _, bond := range accepted {
+		coinIDStr := coinIDString(bond.AssetID, bond.CoinID)
+		c.log.Infof("Pending bond %s was accepted by %s.", coinIDStr, dc.acct.host)
+		if err := c.db.ConfirmBond(dc.acct.host, bond.AssetID, bond.CoinID); err != nil {
+			c.log.Errorf("Error marking bond %s as confirmed: %v", coinIDStr, err)
+		}
+		dc.clearPendingBondConfs(coinIDStr)
+		c.removeWaiter(coinIDStr)
+	}
+}
+
+// watchBonds runs the bond maintenance loop until the context is canceled.
+func (c *Core) watchBonds(ctx context.Context) {
+	ticker := time.NewTicker(bondCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.rotateBonds(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// rotateBonds moves active bonds that have reached the server's bond expiry
+// to the expired list, refunds expired bonds whose lock time has passed, and
+// posts new bonds to maintain the target tier of each account.
+func (c *Core) rotateBonds(ctx context.Context) {
+	if !c.bondKeysReady() {
+		return // not logged in
+	}
+	now := time.Now()
+	for _, dc := range c.dexConnections() {
+		bondAssets, bondExpiry := dc.bondOpts()
+
+		dc.acct.authMtx.Lock()
+		if bondExpiry > 0 {
+			var active []*db.Bond
+			for _, bond := range dc.acct.bonds {
+				lockTime := time.Unix(int64(bond.LockTime), 0)
+				if now.Add(bondExpiry).Before(lockTime) {
+					active = append(active, bond)
+					continue
+				}
+				c.log.Infof("Bond %s at %s has expired. It may be refunded after %v.",
+					coinIDString(bond.AssetID, bond.CoinID), dc.acct.host, lockTime)
+				dc.acct.expiredBonds = append(dc.acct.expiredBonds, bond)
+			}
+			dc.acct.bonds = active
+		}
+		var refundable []*db.Bond
+		for _, bond := range dc.acct.expiredBonds {
+			if now.After(time.Unix(int64(bond.LockTime), 0)) {
+				refundable = append(refundable, bond)
+			}
+		}
+		// The strength of active and pending bonds counts toward the target
+		// tier, so that bonds are not posted twice while confirming.
+		var strength uint64
+		for _, bonds := range [][]*db.Bond{dc.acct.bonds, dc.acct.pendingBonds} {
+			for _, bond := range bonds {
+				if bondAsset := bondAssets[bond.AssetID]; bondAsset != nil && bondAsset.Amt > 0 {
+					strength += bond.Amount / bondAsset.Amt
+				}
+			}
+		}
+		targetTier, bondAssetID := dc.acct.targetTier, dc.acct.bondAsset
+		dc.acct.authMtx.Unlock()
+
+		for _, bond := range refundable {
+			if err := c.refundBond(ctx, dc, bond); err != nil {
+				c.log.Errorf("Unable to refund bond %s for %s: %v",
+					coinIDString(bond.AssetID, bond.CoinID), dc.acct.host, err)
+			}
+		}
+
+		if targetTier > strength && bondExpiry > 0 {
+			c.renewBonds(dc, bondAssets[bondAssetID], bondAssetID, targetTier-strength, now.Add(2*bondExpiry))
+		}
+	}
+}
+
+// renewBonds posts a new bond to make up for the tiers lost to bond expiry.
+func (c *Core) renewBonds(dc *dexConnection, bondAsset *msgjson.BondAsset, bondAssetID uint32, tiers uint64, lockTime time.Time) {
+	if bondAsset == nil {
+		c.log.Warnf("Cannot maintain target tier at %s. Bond asset %s is not supported.",
+			dc.acct.host, unbip(bondAssetID))
+		return
+	}
+	if dc.acct.locked() || !dc.acct.authed() {
+		return
+	}
+	wallet, found := c.wallet(bondAssetID)
+	if !found || !wallet.connected() || !wallet.unlocked() {
+		c.log.Warnf("Cannot maintain target tier at %s. %s wallet not connected and unlocked.",
+			dc.acct.host, unbip(bondAssetID))
+		return
+	}
+	bonder, ok := wallet.bonder()
+	if !ok {
+		c.log.Warnf("Cannot maintain target tier at %s. %s wallet does not support bonds.",
+			dc.acct.host, unbip(bondAssetID))
+		return
+	}
+	amt := tiers * bondAsset.Amt
+	c.log.Infof("Posting a new bond of %d units of %s to maintain the target tier at %s.",
+		amt, unbip(bondAssetID), dc.acct.host)
+	if _, err := c.makeAndPostBond(dc, wallet, bonder, bondAsset, amt, lockTime.Truncate(time.Second)); err != nil {
+		c.log.Errorf("Error posting bond to maintain the target tier at %s: %v", dc.acct.host, err)
+	}
+}
+
+// refundBond refunds an expired bond whose lock time has passed.
+func (c *Core) refundBond(ctx context.Context, dc *dexConnection, bond *db.Bond) error {
+	wallet, found := c.wallet(bond.AssetID)
+	if !found || !wallet.connected() {
+		return fmt.Errorf("no connected %s wallet", unbip(bond.AssetID))
+	}
+	if !wallet.unlocked() {
+		return fmt.Errorf("%s wallet locked", unbip(bond.AssetID))
+	}
+	bonder, ok := wallet.bonder()
+	if !ok {
+		return fmt.Errorf("%s wallet does not support bonds", unbip(bond.AssetID))
+	}
+	privKey, err := c.bondKeyIdx(bond.AssetID, bond.KeyIndex)
+	if err != nil {
+		return err
+	}
+	defer privKey.Zero()
+
+	bondCoinStr := coinIDString(bond.AssetID, bond.CoinID)
+	refundCoinID, err := bonder.RefundBond(ctx, bond.Version, bond.CoinID, bond.Data, bond.Amount, privKey)
+	if err != nil {
+		subject, details := c.formatDetails(TopicBondRefundError, bondCoinStr, unbip(bond.AssetID), dc.acct.host, err)
+		c.notify(newBondPostNote(TopicBondRefundError, subject, details, db.WarningLevel, dc.acct.host))
+		return err
+	}
+	refundCoinStr := coinIDString(bond.AssetID, refundCoinID)
+
+	if err = c.db.BondRefunded(dc.acct.host, bond.AssetID, bond.CoinID); err != nil {
+		c.log.Errorf("Error marking bond %s as refunded: %v", bondCoinStr, err)
+	}
+
+	dc.acct.authMtx.Lock()
+	bond.Refunded = true
+	for i, b := range dc.acct.expiredBonds {
+		if b == bond {
+			dc.acct.expiredBonds = append(dc.acct.expiredBonds[:i], dc.acct.expiredBonds[i+1:]...)
+			break
+		}
+	}
+	dc.acct.authMtx.Unlock()
+
+	c.updateAssetBalance(bond.AssetID)
+
+	subject, details := c.formatDetails(TopicBondRefunded, bondCoinStr, unbip(bond.AssetID), dc.acct.host, refundCoinStr)
+	c.notify(newBondPostNote(TopicBondRefunded, subject, details, db.Success, dc.acct.host))
+	return nil
+}
+
+// UpdateBondOptions sets the bond maintenance options for a DEX account. When
+// a target tier is set, new bonds will be posted automatically as existing
+// bonds expire.
+func (c *Core) UpdateBondOptions(form *BondOptionsForm) error {
+	dc, _, err := c.dex(form.Addr)
+	if err != nil {
+		return err
+	}
+
+	dc.acct.authMtx.RLock()
+	targetTier, bondAssetID := dc.acct.targetTier, dc.acct.bondAsset
+	dc.acct.authMtx.RUnlock()
+
+	if form.TargetTier != nil {
+		targetTier = *form.TargetTier
+	}
+	if form.BondAsset != nil {
+		bondAssetID = *form.BondAsset
+	}
+	if targetTier > 0 {
+		bondAssets, _ := dc.bondOpts()
+		if bondAssets[bondAssetID] == nil {
+			return newError(assetSupportErr, "dex server does not support fidelity bonds in asset %q",
+				unbip(bondAssetID))
+		}
+		wallet, found := c.wallet(bondAssetID)
+		if !found {
+			return newError(missingWalletErr, "no %s wallet", unbip(bondAssetID))
+		}
+		if _, ok := wallet.bonder(); !ok {
+			return newError(assetSupportErr, "%s wallet does not support fidelity bonds", unbip(bondAssetID))
+		}
+	}
+
+	ai, err := c.db.Account(dc.acct.host)
+	if err != nil {
+		return codedError(accountRetrieveErr, err)
+	}
+	ai.TargetTier = targetTier
+	ai.BondAssetID = bondAssetID
+	if err = c.db.UpdateAccountInfo(ai); err != nil {
+		return codedError(dbErr, err)
+	}
+
+	dc.acct.authMtx.Lock()
+	dc.acct.targetTier = targetTier
+	dc.acct.bondAsset = bondAssetID
+	dc.acct.authMtx.Unlock()
+	return nil
+}
@@ -0,0 +1,306 @@
+//go:build !harness
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/msgjson"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+const tBondAmt uint64 = 1e8
+
+type TBonderWallet struct {
+	*TXCWallet
+	bond        *asset.Bond
+	makeBondErr error
+	lastBondAmt uint64
+	sendTxErr   error
+	sentTxs     int
+	refundCoin  []byte
+	refundErr   error
+	refundKey   []byte
+}
+
+var _ asset.Bonder = (*TBonderWallet)(nil)
+
+func (w *TBonderWallet) MakeBondTx(ver uint16, amt, feeRate uint64, lockTime time.Time, bondKey *secp256k1.PrivateKey, acctID []byte) (*asset.Bond, error) {
+	w.lastBondAmt = amt
+	return w.bond, w.makeBondErr
+}
+
+func (w *TBonderWallet) RefundBond(_ context.Context, ver uint16, coinID, script []byte, amt uint64, privKey *secp256k1.PrivateKey) ([]byte, error) {
+	w.refundKey = privKey.Serialize()
+	return w.refundCoin, w.refundErr
+}
+
+func (w *TBonderWallet) SendTransaction(rawTx []byte) ([]byte, error) {
+	w.sentTxs++
+	return w.bond.CoinID, w.sendTxErr
+}
+
+func newTBonderWallet(assetID uint32) (*xcWallet, *TBonderWallet) {
+	xcWallet, tWallet := newTWallet(assetID)
+	bonder := &TBonderWallet{
+		TXCWallet: tWallet,
+		bond: &asset.Bond{
+			AssetID:  assetID,
+			Amount:   tBondAmt,
+			CoinID:   encode.RandomBytes(36),
+			Data:     encode.RandomBytes(60),
+			SignedTx: encode.RandomBytes(200),
+			RedeemTx: encode.RandomBytes(150),
+		},
+		refundCoin: encode.RandomBytes(36),
+	}
+	tWallet.bal = &asset.Balance{Available: 4 * tBondAmt}
+	xcWallet.Wallet = bonder
+	xcWallet.traits = asset.DetermineWalletTraits(bonder)
+	return xcWallet, bonder
+}
+
+func (rig *testRig) setBondConfig() {
+	rig.dc.cfg.BondExpiry = 3600
+	rig.dc.cfg.BondAssets = map[string]*msgjson.BondAsset{
+		tUTXOAssetA.Symbol: {
+			ID:    tUTXOAssetA.ID,
+			Confs: 1,
+			Amt:   tBondAmt,
+		},
+	}
+}
+
+func (rig *testRig) queuePostBond(tier int64) {
+	rig.ws.queueResponse(msgjson.PostBondRoute, func(msg *msgjson.Message, f msgFunc) error {
+		req := new(msgjson.PostBond)
+		json.Unmarshal(msg.Payload, req)
+		acctID := rig.acct.ID()
+		result := &msgjson.PostBondResult{
+			AccountID: acctID[:],
+			AssetID:   req.AssetID,
+			Amount:    tBondAmt,
+			BondID:    req.CoinID,
+			Tier:      tier,
+		}
+		sign(tDexPriv, result)
+		resp, _ := msgjson.NewResponse(msg.ID, result, nil)
+		f(resp)
+		return nil
+	})
+}
+
+func TestPostBond(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.setBondConfig()
+	rig.acct.auth(false)
+
+	wallet, bonder := newTBonderWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+
+	assetID := tUTXOAssetA.ID
+	newForm := func() *PostBondForm {
+		return &PostBondForm{
+			Addr:    tDexHost,
+			AppPass: tPW,
+			Asset:   &assetID,
+			Bond:    tBondAmt,
+		}
+	}
+
+	ensureErr := func(tag string, form *PostBondForm, code int) {
+		t.Helper()
+		_, err := tCore.PostBond(form)
+		if err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
+		if code >= 0 {
+			var cErr *Error
+			if !errors.As(err, &cErr) || *cErr.Code() != code {
+				t.Fatalf("%s: wrong error %v, wanted code %d", tag, err, code)
+			}
+		}
+	}
+
+	// Not a multiple of the bond increment.
+	form := newForm()
+	form.Bond = tBondAmt * 3 / 2
+	ensureErr("bad amount", form, bondAmtErr)
+
+	// Lock time too soon.
+	form = newForm()
+	form.LockTime = uint64(time.Now().Add(time.Minute).Unix())
+	ensureErr("lock time", form, bondTimeErr)
+
+	// Unsupported asset.
+	wallet2, _ := newTBonderWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = wallet2
+	form = newForm()
+	form.Asset = &tUTXOAssetB.ID
+	ensureErr("unsupported asset", form, assetSupportErr)
+
+	// Wallet is not a Bonder.
+	plainWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = plainWallet
+	ensureErr("not a bonder", form, assetSupportErr)
+
+	// Insufficient balance.
+	bonder.bal = &asset.Balance{Available: tBondAmt}
+	ensureErr("balance", newForm(), walletBalanceErr)
+	bonder.bal = &asset.Balance{Available: 4 * tBondAmt}
+
+	// MakeBondTx error.
+	bonder.makeBondErr = tErr
+	ensureErr("MakeBondTx", newForm(), bondPostErr)
+	bonder.makeBondErr = nil
+
+	// DB error. The bond is not broadcast.
+	rig.db.addBondErr = tErr
+	ensureErr("AddBond", newForm(), dbErr)
+	rig.db.addBondErr = nil
+	if bonder.sentTxs != 0 {
+		t.Fatalf("bond broadcast after a DB error")
+	}
+
+	// Broadcast error.
+	bonder.sendTxErr = tErr
+	ensureErr("SendTransaction", newForm(), bondPostErr)
+	bonder.sendTxErr = nil
+
+	if len(rig.acct.pendingBonds) != 0 {
+		t.Fatalf("pending bond recorded for failed PostBond")
+	}
+
+	// Success.
+	ch := tCore.NotificationFeed()
+	res, err := tCore.PostBond(newForm())
+	if err != nil {
+		t.Fatalf("PostBond error: %v", err)
+	}
+	if res.ReqConfirms != 1 {
+		t.Fatalf("wrong required confirmations %d", res.ReqConfirms)
+	}
+	if len(rig.db.bonds) != 1 || !bytes.Equal(rig.db.bonds[0].CoinID, bonder.bond.CoinID) {
+		t.Fatalf("bond not stored")
+	}
+	if pbs := rig.dc.pendingBonds(); len(pbs) != 1 || pbs[0].Confs != 0 {
+		t.Fatalf("wrong pending bonds %+v", pbs)
+	}
+
+	// Reaching the required confirmations should post the bond.
+	rig.queuePostBond(2)
+	bonder.setConfs(bonder.bond.CoinID, 1, nil)
+	tCore.tipChange(tUTXOAssetA.ID, nil)
+
+	timeout := time.After(time.Second)
+out:
+	for {
+		select {
+		case n := <-ch:
+			if n.Topic() == TopicBondPostError {
+				t.Fatalf("bond post error: %s", n.Details())
+			}
+			if n.Topic() == TopicBondConfirmed {
+				break out
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for bond confirmation")
+		}
+	}
+
+	if tier := rig.acct.bondTier(); tier != 2 {
+		t.Fatalf("wrong tier %d", tier)
+	}
+	if !rig.acct.hasActiveBonds() || rig.dc.pendingBonds() != nil {
+		t.Fatalf("bond not moved to active")
+	}
+	if len(rig.db.confirmedBonds) != 1 {
+		t.Fatalf("bond not confirmed in db")
+	}
+}
+
+func TestRotateBonds(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.setBondConfig()
+	rig.acct.auth(false)
+
+	wallet, bonder := newTBonderWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+
+	// Nothing happens until the bond keys are ready.
+	now := time.Now()
+	newBond := func(lockTime time.Time, keyIndex uint32) *db.Bond {
+		return &db.Bond{
+			AssetID:   tUTXOAssetA.ID,
+			CoinID:    encode.RandomBytes(36),
+			Amount:    tBondAmt,
+			LockTime:  uint64(lockTime.Unix()),
+			KeyIndex:  keyIndex,
+			Confirmed: true,
+		}
+	}
+	refundable := newBond(now.Add(-time.Minute), 0)
+	expired := newBond(now.Add(time.Minute*30), 1)
+	active := newBond(now.Add(time.Hour*2), 2)
+	rig.acct.bonds = []*db.Bond{refundable, expired, active}
+	tCore.rotateBonds(tCtx)
+	if len(rig.acct.bonds) != 3 {
+		t.Fatalf("bonds rotated before login")
+	}
+
+	if err := tCore.setBondKeys(rig.crypter); err != nil {
+		t.Fatalf("setBondKeys error: %v", err)
+	}
+
+	tCore.rotateBonds(tCtx)
+	if len(rig.acct.bonds) != 1 || rig.acct.bonds[0] != active {
+		t.Fatalf("expected 1 active bond, got %d", len(rig.acct.bonds))
+	}
+	if len(rig.acct.expiredBonds) != 1 || rig.acct.expiredBonds[0] != expired {
+		t.Fatalf("expected 1 expired bond, got %d", len(rig.acct.expiredBonds))
+	}
+	if len(rig.db.refundedBonds) != 1 || !bytes.Equal(rig.db.refundedBonds[0], refundable.CoinID) {
+		t.Fatalf("refundable bond not refunded")
+	}
+	// The refund must be signed with the key derived from the app seed.
+	bondKey, err := tCore.bondKeyIdx(refundable.AssetID, refundable.KeyIndex)
+	if err != nil {
+		t.Fatalf("bondKeyIdx error: %v", err)
+	}
+	if !bytes.Equal(bonder.refundKey, bondKey.Serialize()) {
+		t.Fatalf("wrong refund key")
+	}
+	if len(rig.db.bonds) != 0 {
+		t.Fatalf("bond posted without a target tier")
+	}
+
+	// With a target tier, a new bond is posted to make up the difference.
+	rig.acct.targetTier = 3
+	rig.acct.bondAsset = tUTXOAssetA.ID
+	tCore.rotateBonds(tCtx)
+	if bonder.lastBondAmt != 2*tBondAmt {
+		t.Fatalf("wrong renewal bond amount %d", bonder.lastBondAmt)
+	}
+	if len(rig.acct.pendingBonds) != 1 {
+		t.Fatalf("renewal bond not pending")
+	}
+
+	// The pending bond counts toward the target, so no more are posted.
+	bonder.lastBondAmt = 0
+	tCore.rotateBonds(tCtx)
+	if bonder.lastBondAmt != 0 {
+		t.Fatalf("bond posted twice")
+	}
+}
@@ -28,6 +28,11 @@ var (
 	outdatedClientErr = errors.New("outdated client")
 )
 
+// matchProofsToKeep is the number of epochs for which a bookie keeps the
+// match_proof notes received from the server, for epoch transcript
+// verification.
+const matchProofsToKeep = 1000
+
 // BookFeed manages a channel for receiving order book updates. It is imperative
 // that the feeder (BookFeed).Close() when no longer using the feed.
 type BookFeed interface {
@@ -119,6 +124,9 @@ type bookie struct {
 	timerMtx   sync.Mutex
 	closeTimer *time.Timer
 
+	proofsMtx   sync.RWMutex
+	matchProofs map[uint64]*msgjson.MatchProofNote // keyed by epoch index
+
 	base, quote           uint32
 	baseUnits, quoteUnits dex.UnitInfo
 }
@@ -174,6 +182,7 @@ func newBookie(dc *dexConnection, base, quote uint32, binSizes []string, logger
 		candleCaches: candleCaches,
 		log:          logger,
 		feeds:        make(map[uint32]*bookFeed, 1),
+		matchProofs:  make(map[uint64]*msgjson.MatchProofNote),
 		base:         base,
 		quote:        quote,
 		baseUnits:    parseUnitInfo(base),
@@ -181,6 +190,30 @@ func newBookie(dc *dexConnection, base, quote uint32, binSizes []string, logger
 	}
 }
 
+// recordMatchProof stores the match_proof note for the epoch, discarding notes
+// for epochs older than the most recent matchProofsToKeep.
+func (b *bookie) recordMatchProof(note *msgjson.MatchProofNote) {
+	b.proofsMtx.Lock()
+	defer b.proofsMtx.Unlock()
+	b.matchProofs[note.Epoch] = note
+	if len(b.matchProofs) <= matchProofsToKeep {
+		return
+	}
+	for epoch := range b.matchProofs {
+		if epoch+matchProofsToKeep <= note.Epoch {
+			delete(b.matchProofs, epoch)
+		}
+	}
+}
+
+// matchProof returns the match_proof note received for the epoch, or nil if
+// none was received.
+func (b *bookie) matchProof(epoch uint64) *msgjson.MatchProofNote {
+	b.proofsMtx.RLock()
+	defer b.proofsMtx.RUnlock()
+	return b.matchProofs[epoch]
+}
+
 // logEpochReport handles the epoch candle in the epoch_report message.
 func (b *bookie) logEpochReport(note *msgjson.EpochReportNote) error {
 	err := b.LogEpochReport(note)
@@ -196,6 +229,7 @@ func (b *bookie) logEpochReport(note *msgjson.EpochReportNote) error {
 		if c == nil {
 			continue
 		}
+		b.storeCandles(cache.BinSize, []*msgjson.Candle{c})
 		dur, _ := time.ParseDuration(durStr)
 		b.send(&BookUpdate{
 			Action:   CandleUpdateAction,
@@ -286,20 +320,117 @@ func (b *bookie) candles(durStr string, feedID uint32) error {
 		return nil
 	}
 	// Subscribe to the feed.
+	if err = b.fetchCandles(durStr, cache); err != nil {
+		return err
+	}
+	atomic.StoreUint32(&cache.on, 1)
+	return nil
+}
+
+// fetchCandles requests the server's candles for the bin size, resets the
+// cache with them, and stores them in the database.
+func (b *bookie) fetchCandles(durStr string, cache *candleCache) error {
+	cands, err := b.dc.requestCandles(b.base, b.quote, durStr, candles.CacheSize)
+	if err != nil {
+		return err
+	}
+	cache.init(cands)
+	b.storeCandles(cache.BinSize, cands)
+	return nil
+}
+
+// refreshCandles refetches the candles for any active candle caches and sends
+// them to the subscribers, and backfills the stored candles for the other bin
+// sizes. Candles that were missed while disconnected are stored, so long as
+// the server still has them.
+func (b *bookie) refreshCandles() {
+	for durStr, cache := range b.candleCaches {
+		if atomic.LoadUint32(&cache.on) == 0 {
+			if err := b.dc.backfillCandles(b.base, b.quote, durStr); err != nil {
+				b.log.Errorf("Error backfilling %s candles: %v", durStr, err)
+			}
+			continue
+		}
+		if err := b.fetchCandles(durStr, cache); err != nil {
+			b.log.Errorf("Error refreshing %s candles: %v", durStr, err)
+			continue
+		}
+		b.send(&BookUpdate{
+			Action:   FreshCandlesAction,
+			Host:     b.dc.acct.host,
+			MarketID: marketName(b.base, b.quote),
+			Payload: &CandlesPayload{
+				Dur:          durStr,
+				DurMilliSecs: cache.BinSize,
+				Candles:      cache.copy(),
+			},
+		})
+	}
+}
+
+// storeCandles saves the candles to the database.
+func (b *bookie) storeCandles(binSize uint64, cands []*msgjson.Candle) {
+	if len(cands) == 0 {
+		return
+	}
+	err := b.dc.db.UpdateCandles(b.dc.acct.host, b.base, b.quote, binSize, cands)
+	if err != nil {
+		b.log.Errorf("Error storing candles: %v", err)
+	}
+}
+
+// requestCandles requests the server's most recent n candles for the market
+// and bin size.
+func (dc *dexConnection) requestCandles(base, quote uint32, durStr string, n int) ([]*msgjson.Candle, error) {
 	payload := &msgjson.CandlesRequest{
-		BaseID:     b.base,
-		QuoteID:    b.quote,
+		BaseID:     base,
+		QuoteID:    quote,
 		BinSize:    durStr,
-		NumCandles: candles.CacheSize,
+		NumCandles: n,
 	}
 	wireCandles := new(msgjson.WireCandles)
-	err = sendRequest(b.dc.WsConn, msgjson.CandlesRoute, payload, wireCandles, DefaultResponseTimeout)
+	err := sendRequest(dc.WsConn, msgjson.CandlesRoute, payload, wireCandles, DefaultResponseTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return wireCandles.Candles(), nil
+}
+
+// backfillCandles requests the server's candles for the market and bin size
+// starting with the last stored candle, which may have been incomplete, and
+// stores them. If no candles are stored, the server's full candle history is
+// requested. The server only keeps the most recent candles.CacheSize candles,
+// so a longer gap can only be partially filled.
+func (dc *dexConnection) backfillCandles(base, quote uint32, durStr string) error {
+	dur, err := time.ParseDuration(durStr)
+	if err != nil || dur <= 0 {
+		return fmt.Errorf("invalid bin size %q", durStr)
+	}
+	binSize := uint64(dur.Milliseconds())
+	now := uint64(time.Now().UnixMilli())
+	stored, err := dc.db.Candles(dc.acct.host, base, quote, binSize, now-candles.CacheSize*binSize, 0)
+	if err != nil {
+		return fmt.Errorf("error retrieving stored candles: %w", err)
+	}
+	n := candles.CacheSize
+	if len(stored) > 0 {
+		if last := stored[len(stored)-1].StartStamp; last < now {
+			n = int((now-last)/binSize) + 1
+		} else {
+			n = 1
+		}
+		if n > candles.CacheSize {
+			n = candles.CacheSize
+		}
+	}
+	cands, err := dc.requestCandles(base, quote, durStr, n)
 	if err != nil {
 		return err
 	}
-	cache.init(wireCandles.Candles())
-	atomic.StoreUint32(&cache.on, 1)
-	return nil
+	if len(cands) == 0 {
+		return nil
+	}
+	return dc.db.UpdateCandles(dc.acct.host, base, quote, binSize, cands)
 }
 
 // closeFeed closes the specified feed, and if no more feeds are open, sets a
@@ -411,6 +542,7 @@ func (dc *dexConnection) syncBook(base, quote uint32) (BookFeed, error) {
 		if err != nil {
 			return nil, err
 		}
+		booky.syncHidden(obRes)
 		dc.books[mktID] = booky
 	}
 
@@ -526,6 +658,51 @@ func (c *Core) SyncBook(host string, base, quote uint32) (BookFeed, error) {
 	return dc.syncBook(base, quote)
 }
 
+// Candles retrieves the stored candles for the market and bin size, with start
+// times in the range [start, end), in milliseconds. end = 0 applies no upper
+// limit. Candles are stored while a market's candles are subscribed, and the
+// server's candle history is stored each time a subscription is made. If the
+// candles are not subscribed, any candles missing since the last stored candle
+// are first requested from the server.
+func (c *Core) Candles(host string, base, quote uint32, binSize string, start, end uint64) ([]candles.Candle, error) {
+	dc, _, err := c.dex(host)
+	if err != nil {
+		return nil, err
+	}
+	dur, err := time.ParseDuration(binSize)
+	if err != nil || dur <= 0 {
+		return nil, fmt.Errorf("invalid bin size %q", binSize)
+	}
+	if end != 0 && end <= start {
+		return nil, fmt.Errorf("end time must be after the start time")
+	}
+	// Subscribed candles are stored as they are received.
+	var live bool
+	if booky := dc.bookie(marketName(base, quote)); booky != nil {
+		if cache := booky.candleCaches[binSize]; cache != nil {
+			live = atomic.LoadUint32(&cache.on) == 1
+		}
+	}
+	var backfillErr error
+	if !live {
+		if backfillErr = dc.backfillCandles(base, quote, binSize); backfillErr != nil {
+			c.log.Warnf("Unable to backfill %s candles for %s: %v", binSize, marketName(base, quote), backfillErr)
+		}
+	}
+	stored, err := c.db.Candles(dc.acct.host, base, quote, uint64(dur.Milliseconds()), start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving candles: %w", err)
+	}
+	if len(stored) == 0 && backfillErr != nil {
+		return nil, fmt.Errorf("no stored candles, and error requesting candles from the server: %w", backfillErr)
+	}
+	cands := make([]candles.Candle, 0, len(stored))
+	for _, cand := range stored {
+		cands = append(cands, *cand)
+	}
+	return cands, nil
+}
+
 // Book fetches the order book. If a subscription doesn't exist, one will be
 // attempted and immediately closed.
 func (c *Core) Book(dex string, base, quote uint32) (*OrderBook, error) {
@@ -576,18 +753,67 @@ func (c *Core) Book(dex string, base, quote uint32) (*OrderBook, error) {
 func (b *bookie) translateBookSide(ins []*orderbook.Order) (outs []*MiniOrder) {
 	for _, o := range ins {
 		outs = append(outs, &MiniOrder{
-			Qty:       float64(o.Quantity) / float64(b.baseUnits.Conventional.ConversionFactor),
-			QtyAtomic: o.Quantity,
-			Rate:      calc.ConventionalRate(o.Rate, b.baseUnits, b.quoteUnits),
-			MsgRate:   o.Rate,
-			Sell:      o.Side == msgjson.SellOrderNum,
-			Token:     token(o.OrderID[:]),
-			Epoch:     o.Epoch,
+			Qty:          float64(o.Quantity) / float64(b.baseUnits.Conventional.ConversionFactor),
+			QtyAtomic:    o.Quantity,
+			Rate:         calc.ConventionalRate(o.Rate, b.baseUnits, b.quoteUnits),
+			MsgRate:      o.Rate,
+			Sell:         o.Side == msgjson.SellOrderNum,
+			Token:        token(o.OrderID[:]),
+			Epoch:        o.Epoch,
+			Hidden:       float64(o.Hidden) / float64(b.baseUnits.Conventional.ConversionFactor),
+			HiddenAtomic: o.Hidden,
 		})
 	}
 	return
 }
 
+// setHidden sets the hidden reserve quantity in the order book for the user's
+// own iceberg order, which the server does not broadcast. The hidden quantity
+// is returned. Zero is returned for other orders.
+func (b *bookie) setHidden(oid order.OrderID, visible uint64) uint64 {
+	hidden := b.dc.icebergHidden(oid, visible)
+	if hidden == 0 {
+		return 0
+	}
+	if err := b.SetHidden(oid, hidden); err != nil {
+		// The note may have been cached if the book is not yet synced.
+		b.log.Debugf("Unable to set hidden quantity for order %s: %v", oid, err)
+		return 0
+	}
+	return hidden
+}
+
+// syncHidden sets the hidden reserve quantity for the user's own iceberg
+// orders in a book snapshot.
+func (b *bookie) syncHidden(snap *msgjson.OrderBook) {
+	for _, note := range snap.Orders {
+		var oid order.OrderID
+		copy(oid[:], note.OrderID)
+		b.setHidden(oid, note.Quantity)
+	}
+}
+
+// icebergHidden returns the hidden reserve quantity of the user's own iceberg
+// order, given the quantity displayed on the book. Zero is returned if the
+// order is not an iceberg order belonging to the user.
+func (dc *dexConnection) icebergHidden(oid order.OrderID, visible uint64) uint64 {
+	dc.tradeMtx.RLock()
+	tracker := dc.trades[oid]
+	dc.tradeMtx.RUnlock()
+	if tracker == nil {
+		return 0
+	}
+	lo, ok := tracker.Order.(*order.LimitOrder)
+	if !ok || lo.DisplayQty == 0 {
+		return 0
+	}
+	// Our record of the fills may lag the book notifications.
+	if rem := lo.Remaining(); rem > visible {
+		return rem - visible
+	}
+	return 0
+}
+
 // handleBookOrderMsg is called when a book_order notification is received.
 func handleBookOrderMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) error {
 	note := new(msgjson.BookOrderNote)
@@ -605,11 +831,16 @@ func handleBookOrderMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) error
 	if err != nil {
 		return err
 	}
+	miniOrder := book.minifyOrder(note.OrderID, &note.TradeNote, 0)
+	var oid order.OrderID
+	copy(oid[:], note.OrderID)
+	miniOrder.HiddenAtomic = book.setHidden(oid, note.Quantity)
+	miniOrder.Hidden = float64(miniOrder.HiddenAtomic) / float64(book.baseUnits.Conventional.ConversionFactor)
 	book.send(&BookUpdate{
 		Action:   BookOrderAction,
 		Host:     dc.acct.host,
 		MarketID: note.MarketID,
-		Payload:  book.minifyOrder(note.OrderID, &note.TradeNote, 0),
+		Payload:  miniOrder,
 	})
 	return nil
 }
@@ -784,12 +1015,9 @@ func handleTradeResumptionMsg(c *Core, dc *dexConnection, msg *msgjson.Message)
 	dc.epoch[rs.MarketID] = rs.StartEpoch
 	dc.epochMtx.Unlock()
 
-	// TODO: Server config change without restart is not implemented on the
-	// server, but it would involve either getting the config response or adding
-	// the entire market config to the TradeResumption payload.
-	//
-	// Fetch the updated DEX configuration.
-	// dc.refreshServerConfig()
+	// Changes to the market's configuration while it was suspended are pushed
+	// by the server in a config_update notification, handled by
+	// handleConfigUpdateMsg.
 
 	subject, detail := c.formatDetails(TopicMarketResumed, rs.MarketID, dc.acct.host, rs.StartEpoch)
 	c.notify(newServerNotifyNote(TopicMarketResumed, subject, detail, db.Success))
@@ -799,6 +1027,24 @@ func handleTradeResumptionMsg(c *Core, dc *dexConnection, msg *msgjson.Message)
 	return nil
 }
 
+// handleConfigUpdateMsg is called when the server pushes a new config response,
+// such as when a market is added, retired, or reconfigured by the operator.
+func handleConfigUpdateMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
+	cfg := new(msgjson.ConfigResult)
+	err := msg.Unmarshal(cfg)
+	if err != nil {
+		return fmt.Errorf("config update unmarshal error: %w", err)
+	}
+
+	if err = dc.applyServerConfig(cfg); err != nil {
+		return fmt.Errorf("error applying config update from %s: %w", dc.acct.host, err)
+	}
+
+	subject, detail := c.formatDetails(TopicMarketsUpdated, dc.acct.host)
+	c.notify(newServerNotifyNote(TopicMarketsUpdated, subject, detail, db.Data))
+	return nil
+}
+
 // refreshServerConfig fetches and replaces server configuration data. It also
 // initially checks that a server's API version is one of serverAPIVers.
 func (dc *dexConnection) refreshServerConfig() error {
@@ -808,7 +1054,12 @@ func (dc *dexConnection) refreshServerConfig() error {
 	if err != nil {
 		return fmt.Errorf("unable to fetch server config: %w", err)
 	}
+	return dc.applyServerConfig(cfg)
+}
 
+// applyServerConfig checks and replaces server configuration data. It also
+// checks that the server's API version is one of serverAPIVers.
+func (dc *dexConnection) applyServerConfig(cfg *msgjson.ConfigResult) error {
 	// Check that we are able to communicate with this DEX.
 	apiVer := atomic.LoadInt32(&dc.apiVer)
 	cfgAPIVer := int32(cfg.APIVersion)
@@ -956,7 +1207,7 @@ func (dc *dexConnection) subPriceFeed() {
 
 // handlePriceUpdateNote handles the price_update note that is part of the
 // price feed.
-func handlePriceUpdateNote(_ *Core, dc *dexConnection, msg *msgjson.Message) error {
+func handlePriceUpdateNote(c *Core, dc *dexConnection, msg *msgjson.Message) error {
 	spot := new(msgjson.Spot)
 	if err := msg.Unmarshal(spot); err != nil {
 		return fmt.Errorf("error unmarshaling price update: %v", err)
@@ -970,12 +1221,13 @@ func handlePriceUpdateNote(_ *Core, dc *dexConnection, msg *msgjson.Message) err
 	dc.spotsMtx.Unlock()
 
 	dc.notify(newSpotPriceNote(dc.acct.host, map[string]*msgjson.Spot{mktName: spot}))
+	c.checkStopOrders(dc, mktName)
 	return nil
 }
 
 // handleUnbookOrderMsg is called when an unbook_order notification is
 // received.
-func handleUnbookOrderMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) error {
+func handleUnbookOrderMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
 	note := new(msgjson.UnbookOrderNote)
 	err := msg.Unmarshal(note)
 	if err != nil {
@@ -998,6 +1250,16 @@ func handleUnbookOrderMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) erro
 		Payload:  &MiniOrder{Token: token(note.OrderID)},
 	})
 
+	// The server unbooks our good-til-time orders when they expire.
+	var oid order.OrderID
+	copy(oid[:], note.OrderID)
+	if tracker, _, _ := dc.findOrder(oid); tracker != nil &&
+		tracker.pastExpiry(dc.marketEpoch(note.MarketID, time.Now())) {
+		// Checking the order status requires a response from the server, so
+		// this must not block the message handler.
+		go c.checkOrderExpiry(dc, tracker)
+	}
+
 	return nil
 }
 
@@ -1019,21 +1281,26 @@ func handleUpdateRemainingMsg(_ *Core, dc *dexConnection, msg *msgjson.Message)
 	if err != nil {
 		return err
 	}
+	var oid order.OrderID
+	copy(oid[:], note.OrderID)
+	hidden := book.setHidden(oid, note.Remaining)
 	book.send(&BookUpdate{
 		Action:   UpdateRemainingAction,
 		Host:     dc.acct.host,
 		MarketID: note.MarketID,
 		Payload: &RemainderUpdate{
-			Token:     token(note.OrderID),
-			Qty:       float64(note.Remaining) / float64(book.baseUnits.Conventional.ConversionFactor),
-			QtyAtomic: note.Remaining,
+			Token:        token(note.OrderID),
+			Qty:          float64(note.Remaining) / float64(book.baseUnits.Conventional.ConversionFactor),
+			QtyAtomic:    note.Remaining,
+			Hidden:       float64(hidden) / float64(book.baseUnits.Conventional.ConversionFactor),
+			HiddenAtomic: hidden,
 		},
 	})
 	return nil
 }
 
 // handleEpochReportMsg is called when an epoch_report notification is received.
-func handleEpochReportMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) error {
+func handleEpochReportMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
 	note := new(msgjson.EpochReportNote)
 	err := msg.Unmarshal(note)
 	if err != nil {
@@ -1048,6 +1315,7 @@ func handleEpochReportMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) erro
 	if err != nil {
 		return fmt.Errorf("error logging epoch report: %w", err)
 	}
+	c.checkStopOrders(dc, note.MarketID)
 	return nil
 }
 
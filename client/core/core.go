@@ -42,6 +42,7 @@ import (
 	"github.com/decred/dcrd/crypto/blake256"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/decred/dcrd/hdkeychain/v3"
 	"github.com/decred/go-socks/socks"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
@@ -58,6 +59,12 @@ const (
 	defaultTickInterval = 30 * time.Second
 
 	marketBuyRedemptionSlippageBuffer = 2
+
+	// feeBumpLockTimeDivisor determines when the fee of an unconfirmed
+	// redemption is bumped. Once less than 1/feeBumpLockTimeDivisor of the
+	// redeemed contract's lock time duration remains, the redemption's fee
+	// rate is bumped to the current fee suggestion.
+	feeBumpLockTimeDivisor = 2
 )
 
 var (
@@ -115,6 +122,8 @@ type dexConnection struct {
 	acct       *dexAccount
 	notify     func(Notification)
 	ticker     *dexTicker
+	// db is used to persist market data, such as candles.
+	db db.DB
 	// apiVer is an atomic. An uninitiated connection should be set to -1.
 	apiVer int32
 
@@ -141,6 +150,11 @@ type dexConnection struct {
 	pendingFeeMtx sync.RWMutex
 	pendingFee    *pendingFeeState
 
+	// pendingBondsConfs are the confirmation counts of the account's pending
+	// bonds, keyed by coin ID string.
+	pendingBondsMtx   sync.RWMutex
+	pendingBondsConfs map[string]uint32
+
 	reportingConnects uint32
 
 	spotsMtx sync.RWMutex
@@ -300,12 +314,20 @@ func (dc *dexConnection) exchangeInfo() *Exchange {
 	dc.cfgMtx.RLock()
 	cfg := dc.cfg
 	dc.cfgMtx.RUnlock()
+	dc.acct.authMtx.RLock()
+	tier, targetTier, bondAssetID := dc.acct.tier, dc.acct.targetTier, dc.acct.bondAsset
+	dc.acct.authMtx.RUnlock()
+
 	if cfg == nil { // no config, assets, or markets data
 		return &Exchange{
 			Host:             dc.acct.host,
 			AcctID:           acctID,
 			ConnectionStatus: dc.status(),
 			PendingFee:       dc.getPendingFee(),
+			Tier:             tier,
+			TargetTier:       targetTier,
+			BondAssetID:      bondAssetID,
+			PendingBonds:     dc.pendingBonds(),
 		}
 	}
 
@@ -334,6 +356,16 @@ func (dc *dexConnection) exchangeInfo() *Exchange {
 		feeAssets["dcr"] = dcrAsset
 	}
 
+	bondAssets := make(map[string]*BondAsset, len(cfg.BondAssets))
+	for symb, bondAsset := range cfg.BondAssets {
+		bondAssets[symb] = &BondAsset{
+			Version: bondAsset.Version,
+			ID:      bondAsset.ID,
+			Confs:   bondAsset.Confs,
+			Amt:     bondAsset.Amt,
+		}
+	}
+
 	return &Exchange{
 		Host:             dc.acct.host,
 		AcctID:           acctID,
@@ -344,6 +376,12 @@ func (dc *dexConnection) exchangeInfo() *Exchange {
 		RegFees:          feeAssets,
 		PendingFee:       dc.getPendingFee(),
 		CandleDurs:       cfg.BinSizes,
+		BondAssets:       bondAssets,
+		BondExpiry:       cfg.BondExpiry,
+		Tier:             tier,
+		TargetTier:       targetTier,
+		BondAssetID:      bondAssetID,
+		PendingBonds:     dc.pendingBonds(),
 	}
 }
 
@@ -405,7 +443,7 @@ func (c *Core) tryCancel(dc *dexConnection, oid order.OrderID) (found bool, err
 // tryCancelTrade attempts to cancel the order.
 func (c *Core) tryCancelTrade(dc *dexConnection, tracker *trackedTrade) error {
 	oid := tracker.ID()
-	if lo, ok := tracker.Order.(*order.LimitOrder); !ok || lo.Force != order.StandingTiF {
+	if lo, ok := tracker.Order.(*order.LimitOrder); !ok || lo.Force == order.ImmediateTiF {
 		return fmt.Errorf("cannot cancel %s order %s that is not a standing limit order", tracker.Type(), oid)
 	}
 
@@ -870,7 +908,7 @@ func (dc *dexConnection) reconcileTrades(srvOrderStatuses []*msgjson.OrderStatus
 		} else if trade.metaData.Status == order.OrderStatusEpoch && serverStatus == order.OrderStatusBooked {
 			// Only standing orders can move from Epoch to Booked. This must have
 			// happened in the client's absence (maybe a missed nomatch message).
-			if lo, ok := trade.Order.(*order.LimitOrder); ok && lo.Force == order.StandingTiF {
+			if lo, ok := trade.Order.(*order.LimitOrder); ok && lo.Force != order.ImmediateTiF {
 				updateOrder(trade, srvOrderStatus)
 			} else {
 				dc.log.Warnf("Incorrect status %q reported for non-standing order %v by DEX %s, client status = %q",
@@ -1087,6 +1125,13 @@ type Config struct {
 	TorIsolation bool
 	// Language. A BCP 47 language tag. Default is en-US.
 	Language string
+	// OracleSources are HTTP JSON price sources that are aggregated into a
+	// reference price for a market. If none are specified, there is no price
+	// oracle.
+	OracleSources []*OracleSource
+	// OracleThreshold is the fractional deviation of a limit order's rate from
+	// the oracle price beyond which PreOrder warns. Default is 0.05.
+	OracleThreshold float64
 }
 
 // Core is the core client application. Core manages DEX connections, wallets,
@@ -1108,8 +1153,16 @@ type Core struct {
 	credMtx     sync.RWMutex
 	credentials *db.PrimaryCredentials
 
+	// bondXPriv is the extended key from which bond keys are derived. It is
+	// set on login and cleared on logout.
+	bondXPrivMtx sync.RWMutex
+	bondXPriv    *hdkeychain.ExtendedKey
+
 	seedGenerationTime uint64
 
+	// oracle is nil if no price oracle sources are configured.
+	oracle PriceOracle
+
 	wsConstructor func(*comms.WsCfg) (comms.WsConn, error)
 	newCrypter    func([]byte) encrypt.Crypter
 	reCrypter     func([]byte, []byte) (encrypt.Crypter, error)
@@ -1135,6 +1188,13 @@ type Core struct {
 
 	sentCommitsMtx sync.Mutex
 	sentCommits    map[order.Commitment]chan struct{}
+
+	// stopOrders are the pending stop orders, keyed by hex-encoded ID.
+	stopOrdersMtx sync.RWMutex
+	stopOrders    map[string]*db.StopOrder
+	// stopOrderRetries are the failed placement attempts of triggered stop
+	// orders, keyed by hex-encoded ID. Guarded by stopOrdersMtx.
+	stopOrderRetries map[string]*stopOrderRetry
 }
 
 // New is the constructor for a new Core.
@@ -1191,6 +1251,21 @@ func New(cfg *Config) (*Core, error) {
 		return nil, fmt.Errorf("No translations for language %s", lang)
 	}
 
+	if cfg.OracleThreshold < 0 {
+		return nil, fmt.Errorf("negative oracle threshold %v", cfg.OracleThreshold)
+	}
+	if cfg.OracleThreshold == 0 {
+		cfg.OracleThreshold = defaultOracleThreshold
+	}
+	var oracle PriceOracle
+	if len(cfg.OracleSources) > 0 {
+		po, err := newPriceOracle(cfg.OracleSources, cfg.TorProxy, cfg.TorIsolation, cfg.Logger.SubLogger("ORCL"))
+		if err != nil {
+			return nil, fmt.Errorf("error creating price oracle: %w", err)
+		}
+		oracle = po
+	}
+
 	// Try to get the primary credentials, but ignore no-credentials error here
 	// because the client may not be initialized.
 	creds, err := boltDB.PrimaryCredentials()
@@ -1204,20 +1279,22 @@ func New(cfg *Config) (*Core, error) {
 	}
 
 	core := &Core{
-		cfg:           cfg,
-		credentials:   creds,
-		ready:         make(chan struct{}),
-		log:           cfg.Logger,
-		db:            boltDB,
-		conns:         make(map[string]*dexConnection),
-		wallets:       make(map[uint32]*xcWallet),
-		net:           cfg.Net,
-		lockTimeTaker: dex.LockTimeTaker(cfg.Net),
-		lockTimeMaker: dex.LockTimeMaker(cfg.Net),
-		blockWaiters:  make(map[string]*blockWaiter),
-		piSyncers:     make(map[order.OrderID]chan struct{}),
-		sentCommits:   make(map[order.Commitment]chan struct{}),
-		tickSched:     make(map[order.OrderID]*time.Timer),
+		cfg:              cfg,
+		credentials:      creds,
+		ready:            make(chan struct{}),
+		log:              cfg.Logger,
+		db:               boltDB,
+		conns:            make(map[string]*dexConnection),
+		wallets:          make(map[uint32]*xcWallet),
+		net:              cfg.Net,
+		lockTimeTaker:    dex.LockTimeTaker(cfg.Net),
+		lockTimeMaker:    dex.LockTimeMaker(cfg.Net),
+		blockWaiters:     make(map[string]*blockWaiter),
+		piSyncers:        make(map[order.OrderID]chan struct{}),
+		sentCommits:      make(map[order.Commitment]chan struct{}),
+		tickSched:        make(map[order.OrderID]*time.Timer),
+		stopOrders:       make(map[string]*db.StopOrder),
+		stopOrderRetries: make(map[string]*stopOrderRetry),
 		// Allowing to change the constructor makes testing a lot easier.
 		wsConstructor: comms.NewWsConn,
 		newCrypter:    encrypt.NewCrypter,
@@ -1227,6 +1304,7 @@ func New(cfg *Config) (*Core, error) {
 		locale:             locale,
 		localePrinter:      message.NewPrinter(lang),
 		seedGenerationTime: seedGenerationTime,
+		oracle:             oracle,
 	}
 
 	// Populate the initial user data. User won't include any DEX info yet, as
@@ -1259,6 +1337,12 @@ func (c *Core) Run(ctx context.Context) {
 		c.latencyQ.Run(ctx)
 	}()
 
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.watchBonds(ctx)
+	}()
+
 	c.wg.Wait() // block here until all goroutines except DB complete
 
 	// Stop the DB after dexConnections and other goroutines are done.
@@ -1568,6 +1652,26 @@ func (c *Core) connectAndUnlock(crypter encrypt.Crypter, wallet *xcWallet) error
 	return nil
 }
 
+// connectAndRefreshUnlock is like connectAndUnlock, but without a crypter. The
+// wallet must already be unlocked, or have its decrypted password cached.
+func (c *Core) connectAndRefreshUnlock(wallet *xcWallet) error {
+	if !wallet.connected() {
+		c.log.Infof("Connecting wallet for %s", unbip(wallet.AssetID))
+		err := c.connectAndUpdateWallet(wallet)
+		if err != nil {
+			return err
+		}
+	}
+	if !wallet.locallyUnlocked() {
+		return newError(walletAuthErr, "%s wallet is locked", unbip(wallet.AssetID))
+	}
+	if _, err := wallet.refreshUnlock(); err != nil {
+		return newError(walletAuthErr, "failed to unlock %s wallet: %w",
+			unbip(wallet.AssetID), err)
+	}
+	return nil
+}
+
 // walletBalance gets the xcWallet's current WalletBalance, which includes the
 // db.Balance plus order/contract locked amounts. The data is not stored. Use
 // updateWalletBalance instead to also update xcWallet.balance and the DB.
@@ -1973,7 +2077,7 @@ func (c *Core) assetHasActiveOrders(assetID uint32) bool {
 }
 
 // walletIsActive combines assetHasActiveOrders with a check for pending
-// registration fee payments.
+// registration fee payments and unrefunded bonds.
 func (c *Core) walletIsActive(assetID uint32) bool {
 	if c.assetHasActiveOrders(assetID) {
 		return true
@@ -1982,6 +2086,9 @@ func (c *Core) walletIsActive(assetID uint32) bool {
 		if pf := dc.getPendingFee(); pf != nil && pf.AssetID == assetID {
 			return true
 		}
+		if dc.acct.hasUnrefundedBonds(assetID) {
+			return true
+		}
 	}
 	return false
 }
@@ -3391,13 +3498,30 @@ func (c *Core) IsInitialized() bool {
 }
 
 // InitializeClient sets the initial app-wide password and app seed for the
-// client. The seed argument should be left nil unless restoring from seed.
-func (c *Core) InitializeClient(pw, restorationSeed []byte) error {
+// client. The restorationSeed argument should be empty unless restoring from
+// seed, in which case it can be a mnemonic seed from ExportSeed or a legacy
+// hex-encoded seed. The seed generation time encoded in a mnemonic seed is
+// restored so that wallets can skip older blocks when rescanning.
+func (c *Core) InitializeClient(pw []byte, restorationSeed string) error {
 	if c.IsInitialized() {
 		return fmt.Errorf("already initialized, login instead")
 	}
 
-	_, creds, err := c.generateCredentials(pw, restorationSeed)
+	freshSeed := restorationSeed == ""
+	var seed []byte
+	var seedGenTime uint64
+	if freshSeed {
+		seedGenTime = uint64(time.Now().Unix())
+	} else {
+		var err error
+		seed, seedGenTime, err = decodeSeed(restorationSeed)
+		if err != nil {
+			return err
+		}
+		c.log.Infof("Restoring from a seed generated on %s", seedGenTimeString(seedGenTime))
+	}
+
+	_, creds, err := c.generateCredentials(pw, seed)
 	if err != nil {
 		return err
 	}
@@ -3407,19 +3531,17 @@ func (c *Core) InitializeClient(pw, restorationSeed []byte) error {
 		return fmt.Errorf("SetPrimaryCredentials error: %w", err)
 	}
 
-	freshSeed := len(restorationSeed) == 0
-	if freshSeed {
-		now := uint64(time.Now().Unix())
-		err = c.db.SetSeedGenerationTime(now)
+	if seedGenTime > 0 {
+		err = c.db.SetSeedGenerationTime(seedGenTime)
 		if err != nil {
 			return fmt.Errorf("SetSeedGenerationTime error: %w", err)
 		}
-		c.seedGenerationTime = now
+		c.seedGenerationTime = seedGenTime
 	}
 
 	c.setCredentials(creds)
 
-	if len(restorationSeed) == 0 {
+	if freshSeed {
 		subject, details := c.formatDetails(TopicSeedNeedsSaving)
 		c.notify(newSecurityNote(TopicSeedNeedsSaving, subject, details, db.Success))
 	}
@@ -3427,25 +3549,27 @@ func (c *Core) InitializeClient(pw, restorationSeed []byte) error {
 	return nil
 }
 
-// ExportSeed exports the application seed.
-func (c *Core) ExportSeed(pw []byte) ([]byte, error) {
+// ExportSeed exports the application seed as a mnemonic, which includes the
+// seed generation time, if known.
+func (c *Core) ExportSeed(pw []byte) (string, error) {
 	crypter, err := c.encryptionKey(pw)
 	if err != nil {
-		return nil, fmt.Errorf("ExportSeed password error: %w", err)
+		return "", fmt.Errorf("ExportSeed password error: %w", err)
 	}
 	defer crypter.Close()
 
 	creds := c.creds()
 	if creds == nil {
-		return nil, fmt.Errorf("no v2 credentials stored")
+		return "", fmt.Errorf("no v2 credentials stored")
 	}
 
 	seed, err := crypter.Decrypt(creds.EncSeed)
 	if err != nil {
-		return nil, fmt.Errorf("app seed decryption error: %w", err)
+		return "", fmt.Errorf("app seed decryption error: %w", err)
 	}
+	defer encode.ClearBytes(seed)
 
-	return seed, nil
+	return encodeSeedMnemonic(seed, c.seedGenerationTime)
 }
 
 // generateCredentials generates a new set of *PrimaryCredentials. The
@@ -3468,11 +3592,10 @@ func (c *Core) generateCredentials(pw, seed []byte) (encrypt.Crypter, *db.Primar
 	}
 
 	// Generate a seed to use as the root for all future key generation.
-	const seedLen = 64
 	if len(seed) == 0 {
-		seed = encode.RandomBytes(seedLen)
-	} else if len(seed) != seedLen {
-		return nil, nil, fmt.Errorf("invalid seed length %d. expected %d", len(seed), seedLen)
+		seed = encode.RandomBytes(appSeedLen)
+	} else if len(seed) != appSeedLen {
+		return nil, nil, fmt.Errorf("invalid seed length %d. expected %d", len(seed), appSeedLen)
 	}
 	defer encode.ClearBytes(seed)
 
@@ -3562,6 +3685,11 @@ func (c *Core) Login(pw []byte) (*LoginResult, error) {
 		c.log.Infof("loaded %d incomplete orders", loaded)
 	}
 
+	// The bond keys are needed to post new bonds and to refund expired bonds.
+	if err := c.setBondKeys(crypter); err != nil {
+		c.log.Errorf("Unable to prepare bond keys: %v", err)
+	}
+
 	dexStats := c.initializeDEXConnections(crypter)
 	notes, err := c.db.NotificationsN(100)
 	if err != nil {
@@ -3650,6 +3778,8 @@ func (c *Core) Logout() error {
 		dc.acct.lock()
 	}
 
+	c.clearBondKeys()
+
 	return nil
 }
 
@@ -3929,7 +4059,18 @@ func (c *Core) initializeDEXConnections(crypter encrypt.Crypter) []*DEXBrief {
 		}
 		result.AcctID = dc.acct.ID().String()
 
-		if !dc.acct.feePaid() {
+		// Resume monitoring of any bonds that were broadcast but not yet
+		// accepted by the server.
+		c.resumePendingBonds(dc)
+
+		if !dc.acct.feePaid() && dc.acct.hasBonds() {
+			if !dc.acct.hasActiveBonds() {
+				// The account will be authenticated when a pending bond is
+				// accepted.
+				result.AuthErr = "waiting for bond confirmation"
+				continue
+			}
+		} else if !dc.acct.feePaid() {
 			if len(dc.acct.feeCoin) == 0 {
 				subject, details := c.formatDetails(TopicFeeCoinError, dc.acct.host)
 				c.notify(newFeePaymentNote(TopicFeeCoinError, subject, details, db.ErrorLevel, dc.acct.host))
@@ -4133,14 +4274,16 @@ func (c *Core) feeSuggestion(dc *dexConnection, assetID uint32) (feeSuggestion u
 // must be provided as an additional verification. This method is DEPRECATED. Use
 // Send with the subtract option instead.
 func (c *Core) Withdraw(pw []byte, assetID uint32, value uint64, address string) (asset.Coin, error) {
-	return c.Send(pw, assetID, value, address, true)
+	return c.Send(pw, assetID, value, address, true, nil)
 }
 
 // Send initiates either send or withdraw from an exchange wallet. if subtract
 // is true, fees are subtracted from the value else fees are taken from the
-// exchange wallet. The client password must be provided as an additional
+// exchange wallet. If coinIDs are specified, the send spends exactly those
+// coins, which requires a wallet that supports coin control, and fees cannot
+// be subtracted. The client password must be provided as an additional
 // verification.
-func (c *Core) Send(pw []byte, assetID uint32, value uint64, address string, subtract bool) (asset.Coin, error) {
+func (c *Core) Send(pw []byte, assetID uint32, value uint64, address string, subtract bool, coinIDs []dex.Bytes) (asset.Coin, error) {
 	crypter, err := c.encryptionKey(pw)
 	if err != nil {
 		return nil, fmt.Errorf("password error: %w", err)
@@ -4160,7 +4303,15 @@ func (c *Core) Send(pw []byte, assetID uint32, value uint64, address string, sub
 
 	var coin asset.Coin
 	feeSuggestion := c.feeSuggestionAny(assetID)
-	if !subtract {
+	if len(coinIDs) > 0 {
+		if subtract {
+			return nil, newError(coinControlErr, "cannot subtract fees when sending with selected coins")
+		}
+		if !wallet.traits.IsCoinController() {
+			return nil, newError(coinControlErr, "%s wallet does not support coin control", unbip(assetID))
+		}
+		coin, err = wallet.sendWithCoins(address, value, feeSuggestion, coinIDs)
+	} else if !subtract {
 		coin, err = wallet.Wallet.Send(address, value, feeSuggestion)
 	} else {
 		if withdrawer, isWithdrawer := wallet.Wallet.(asset.Withdrawer); isWithdrawer {
@@ -4304,10 +4455,26 @@ func (c *Core) PreOrder(form *TradeForm) (*OrderEstimate, error) {
 		return nil, fmt.Errorf("error getting redemption estimate: %v", err)
 	}
 
-	return &OrderEstimate{
+	est := &OrderEstimate{
 		Swap:   swapEstimate,
 		Redeem: redeemEstimate,
-	}, nil
+	}
+
+	if form.IsLimit && c.oracle != nil {
+		est.OracleCheck, err = oracleCheck(c.oracle, form.Base, form.Quote, form.Rate,
+			wallets.baseAsset.UnitInfo, wallets.quoteAsset.UnitInfo, c.cfg.OracleThreshold)
+		if err != nil {
+			c.log.Warnf("Unable to check %s rate against the price oracle: %v", mktID, err)
+		}
+	}
+
+	return est, nil
+}
+
+// Oracle returns the price oracle, or nil if no price oracle sources are
+// configured.
+func (c *Core) Oracle() PriceOracle {
+	return c.oracle
 }
 
 // Trade is used to place a market or limit order.
@@ -4355,6 +4522,32 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 		return nil, 0, newError(orderParamsErr, "zero-rate order not allowed")
 	}
 
+	// A good-til-time order stays on the book through the epoch that includes
+	// the expiry time.
+	var expiryEpoch uint64
+	if form.Expiry != 0 {
+		if !form.IsLimit || form.TifNow {
+			return nil, 0, newError(orderParamsErr, "expiry is only allowed for standing limit orders")
+		}
+		expiryEpoch = form.Expiry / mktConf.EpochLen
+		if expiryEpoch <= dc.marketEpoch(mktID, time.Now()) {
+			return nil, 0, newError(orderParamsErr, "order expiry must be after the current epoch")
+		}
+	}
+
+	// An iceberg order displays only part of its quantity on the book.
+	if form.DisplayQty != 0 {
+		if !form.IsLimit || form.TifNow {
+			return nil, 0, newError(orderParamsErr, "display quantity is only allowed for standing limit orders")
+		}
+		if form.DisplayQty%mktConf.LotSize != 0 {
+			return nil, 0, newError(orderParamsErr, "display quantity must be a multiple of %d", mktConf.LotSize)
+		}
+		if form.DisplayQty >= qty {
+			return nil, 0, newError(orderParamsErr, "display quantity must be less than the order quantity")
+		}
+	}
+
 	wallets, err := c.walletSet(dc, form.Base, form.Quote, form.Sell)
 	if err != nil {
 		return nil, 0, err
@@ -4364,34 +4557,12 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 	accountRedeemer, isAccountRedemption := toWallet.Wallet.(asset.AccountLocker)
 	accountRefunder, isAccountRefund := fromWallet.Wallet.(asset.AccountLocker)
 
-	prepareWallet := func(w *xcWallet) error {
-		// NOTE: If the wallet is already internally unlocked (the decrypted
-		// password cached in xcWallet.pw), this could be done without the
-		// crypter via refreshUnlock.
-		err := c.connectAndUnlock(crypter, w)
-		if err != nil {
-			return fmt.Errorf("%s connectAndUnlock error: %w",
-				wallets.fromAsset.Symbol, err)
-		}
-		w.mtx.RLock()
-		defer w.mtx.RUnlock()
-		if w.peerCount == 0 {
-			return fmt.Errorf("%s wallet has no network peers (check your network or firewall)",
-				unbip(w.AssetID))
-		}
-		if !w.synced {
-			return fmt.Errorf("%s still syncing. progress = %.2f%%", unbip(w.AssetID),
-				w.syncProgress*100)
-		}
-		return nil
-	}
-
-	err = prepareWallet(fromWallet)
+	err = c.prepareTradeWallet(fromWallet, crypter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	err = prepareWallet(toWallet)
+	err = c.prepareTradeWallet(toWallet, crypter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -4452,6 +4623,11 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 			qty, wallets.baseAsset.Symbol, rate, lotSize)
 	}
 
+	if len(form.Coins) > 0 && !fromWallet.traits.IsCoinController() {
+		return nil, 0, newError(orderParamsErr, "%s wallet does not support coin control",
+			wallets.fromAsset.Symbol)
+	}
+
 	coins, redeemScripts, err := fromWallet.FundOrder(&asset.Order{
 		Value:         fundQty,
 		MaxSwapCount:  lots,
@@ -4460,6 +4636,7 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 		Immediate:     isImmediate,
 		FeeSuggestion: c.feeSuggestion(dc, wallets.fromAsset.ID),
 		Options:       form.Options,
+		Coins:         form.Coins,
 	})
 	if err != nil {
 		return nil, 0, codedError(walletErr, fmt.Errorf("FundOrder error for %s, funding quantity %d (%d lots): %w",
@@ -4512,6 +4689,8 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 		tif := order.StandingTiF
 		if form.TifNow {
 			tif = order.ImmediateTiF
+		} else if expiryEpoch > 0 {
+			tif = order.GoodTilTimeTiF
 		}
 		ord = &order.LimitOrder{
 			P: *prefix,
@@ -4521,8 +4700,10 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 				Quantity: form.Qty,
 				Address:  addr,
 			},
-			Rate:  form.Rate,
-			Force: tif,
+			Rate:        form.Rate,
+			Force:       tif,
+			ExpiryEpoch: expiryEpoch,
+			DisplayQty:  form.DisplayQty,
 		}
 	} else {
 		ord = &order.MarketOrder{
@@ -4611,8 +4792,81 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 		// and created the trade order, but we lost the connection before
 		// receiving the response with the trade's order ID. Any preimage
 		// request will be unrecognized. This order is ABANDONED.
-		return nil, 0, fmt.Errorf("new order request with DEX server %v market %v failed: %w", dc.acct.host, mktID, err)
+		return nil, 0, codedError(orderSubmitErr, fmt.Errorf("new order request with DEX server %v market %v failed: %w",
+			dc.acct.host, mktID, err))
+	}
+
+	corder, err := c.storeTrade(dc, &tradeRequest{
+		ord:                ord,
+		msgOrder:           msgOrder,
+		preImg:             preImg,
+		coins:              coins,
+		recoveryCoin:       recoveryCoin,
+		changeID:           changeID,
+		redemptionReserves: redemptionReserves,
+		refundReserves:     refundReserves,
+		options:            form.Options,
+	}, result, wallets)
+	if err != nil {
+		// The server accepted the order.
+		return nil, 0, codedError(orderSubmitErr, err)
+	}
+
+	success = true
+
+	return corder, wallets.fromWallet.AssetID, nil
+}
+
+// prepareTradeWallet connects and unlocks a wallet for trading, and checks
+// that it is synced and has peers. If the crypter is nil, the wallet must
+// already be unlocked.
+func (c *Core) prepareTradeWallet(w *xcWallet, crypter encrypt.Crypter) error {
+	// NOTE: If the wallet is already internally unlocked (the decrypted
+	// password cached in xcWallet.pw), this could be done without the
+	// crypter via refreshUnlock.
+	var err error
+	if crypter == nil { // e.g. a triggered stop order
+		err = c.connectAndRefreshUnlock(w)
+	} else {
+		err = c.connectAndUnlock(crypter, w)
 	}
+	if err != nil {
+		return fmt.Errorf("%s connectAndUnlock error: %w", unbip(w.AssetID), err)
+	}
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	if w.peerCount == 0 {
+		return fmt.Errorf("%s wallet has no network peers (check your network or firewall)",
+			unbip(w.AssetID))
+	}
+	if !w.synced {
+		return fmt.Errorf("%s still syncing. progress = %.2f%%", unbip(w.AssetID),
+			w.syncProgress*100)
+	}
+	return nil
+}
+
+// tradeRequest is a funded order that has been sent to the server.
+type tradeRequest struct {
+	ord                order.Order
+	msgOrder           msgjson.Stampable
+	preImg             order.Preimage
+	coins              asset.Coins
+	recoveryCoin       asset.Coin
+	changeID           []byte
+	redemptionReserves uint64
+	refundReserves     uint64
+	options            map[string]string
+	replaces           *trackedTrade
+}
+
+// storeTrade validates the server's response to a new order request, then
+// stores the order and starts tracking the trade.
+func (c *Core) storeTrade(dc *dexConnection, req *tradeRequest, result *msgjson.OrderResult, wallets *walletSet) (*Order, error) {
+	ord, preImg, msgOrder := req.ord, req.preImg, req.msgOrder
+	mktID := marketName(ord.Base(), ord.Quote())
+	_, isLimit := ord.(*order.LimitOrder)
+	sell := ord.Trade().Sell
 
 	// If we encounter an error, perform some basic logging.
 	logAbandon := func(err interface{}) {
@@ -4620,10 +4874,10 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 			preImg[:], result.ServerTime, err)
 	}
 
-	err = validateOrderResponse(dc, result, ord, msgOrder) // stamps the order, giving it a valid ID
+	err := validateOrderResponse(dc, result, ord, msgOrder) // stamps the order, giving it a valid ID
 	if err != nil {
 		logAbandon(fmt.Sprintf("order response validation failure: %v", err))
-		return nil, 0, fmt.Errorf("validateOrderResponse error: %w", err)
+		return nil, fmt.Errorf("validateOrderResponse error: %w", err)
 	}
 
 	// Store the order.
@@ -4639,31 +4893,36 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 			},
 			FromVersion:        wallets.fromAsset.Version,
 			ToVersion:          wallets.toAsset.Version,
-			Options:            form.Options,
-			RedemptionReserves: redemptionReserves,
-			ChangeCoin:         changeID,
+			Options:            req.options,
+			RedemptionReserves: req.redemptionReserves,
+			ChangeCoin:         req.changeID,
 		},
 		Order: ord,
 	}
 	err = c.db.UpdateOrder(dbOrder)
 	if err != nil {
 		logAbandon(fmt.Sprintf("failed to store order in database: %v", err))
-		return nil, 0, fmt.Errorf("Order abandoned due to database error: %w", err)
+		return nil, fmt.Errorf("Order abandoned due to database error: %w", err)
 	}
 
 	// Prepare and store the tracker and get the core.Order to return.
 	tracker := newTrackedTrade(dbOrder, preImg, dc, dc.marketEpochDuration(mktID), c.lockTimeTaker, c.lockTimeMaker,
-		c.db, c.latencyQ, wallets, coins, c.notify, c.formatDetails, form.Options, redemptionReserves, refundReserves)
+		c.db, c.latencyQ, wallets, req.coins, c.notify, c.formatDetails, req.options, req.redemptionReserves, req.refundReserves)
 
 	tracker.redemptionLocked = tracker.redemptionReserves
 	tracker.refundLocked = tracker.refundReserves
 
-	if recoveryCoin != nil {
-		tracker.change = recoveryCoin
+	if req.recoveryCoin != nil {
+		tracker.change = req.recoveryCoin
 		tracker.coinsLocked = false
 		tracker.changeLocked = true
 	}
 
+	if req.replaces != nil {
+		tracker.replaces = req.replaces
+		tracker.coinsLocked = false
+	}
+
 	dc.tradeMtx.Lock()
 	dc.trades[tracker.ID()] = tracker
 	dc.tradeMtx.Unlock()
@@ -4673,14 +4932,14 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 
 	// Send a low-priority notification.
 	corder := tracker.coreOrder()
-	if !form.IsLimit && !form.Sell {
+	if !isLimit && !sell {
 		ui := wallets.quoteWallet.Info().UnitInfo
 		subject, details := c.formatDetails(TopicYoloPlaced,
 			ui.ConventionalString(corder.Qty), ui.Conventional.Unit, tracker.token())
 		c.notify(newOrderNote(TopicYoloPlaced, subject, details, db.Poke, corder))
 	} else {
 		rateString := "market"
-		if form.IsLimit {
+		if isLimit {
 			rateString = wallets.trimmedConventionalRateString(corder.Rate)
 		}
 		ui := wallets.baseWallet.Info().UnitInfo
@@ -4689,9 +4948,7 @@ func (c *Core) prepareTrackedTrade(dc *dexConnection, form *TradeForm, crypter e
 		c.notify(newOrderNote(TopicOrderPlaced, subject, details, db.Poke, corder))
 	}
 
-	success = true
-
-	return corder, wallets.fromWallet.AssetID, nil
+	return corder, nil
 }
 
 // walletSet is a pair of wallets with asset configurations identified in useful
@@ -4779,7 +5036,18 @@ func (c *Core) Cancel(pw []byte, oidB dex.Bytes) error {
 	if err != nil {
 		return fmt.Errorf("Cancel password error: %w", err)
 	}
+	return c.cancel(oidB)
+}
+
+// CancelUnlocked is like Cancel, but does not require the app password. This
+// allows an automated trader that has unlocked its wallets to cancel its orders
+// without holding the password.
+func (c *Core) CancelUnlocked(oidB dex.Bytes) error {
+	return c.cancel(oidB)
+}
 
+// cancel cancels the order with the specified ID.
+func (c *Core) cancel(oidB dex.Bytes) error {
 	oid, err := order.IDFromBytes(oidB)
 	if err != nil {
 		return err
@@ -4798,6 +5066,170 @@ func (c *Core) Cancel(pw []byte, oidB dex.Bytes) error {
 	return fmt.Errorf("Cancel: failed to find order %s", oidB)
 }
 
+// Replace replaces a booked standing limit order with a new order at a
+// different rate and quantity. The new order is funded by the same coins as
+// the replaced order, so the quantity may not be increased, and for a buy
+// order, the total quote asset amount may not be increased either. The server
+// swaps the orders on the book when the new order's epoch is processed if the
+// replaced order is still booked and unfilled. The replacement keeps the time
+// in force, expiry epoch, and display quantity of the replaced order. Unlike a
+// cancel order, a replacement does not count against the user's cancellation
+// rate.
+func (c *Core) Replace(pw []byte, oidB dex.Bytes, rate, qty uint64) (*Order, error) {
+	// Check the user password.
+	_, err := c.encryptionKey(pw)
+	if err != nil {
+		return nil, fmt.Errorf("Replace password error: %w", err)
+	}
+
+	oid, err := order.IDFromBytes(oidB)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dc := range c.dexConnections() {
+		tracker, _, isCancel := dc.findOrder(oid)
+		if tracker == nil || isCancel {
+			continue
+		}
+		return c.replaceTrade(dc, tracker, rate, qty)
+	}
+
+	return nil, fmt.Errorf("Replace: failed to find order %s", oidB)
+}
+
+// replaceTrade sends a replacement order for the booked order, and starts
+// tracking the replacement. The funding coins remain with the replaced order
+// until the server reports the outcome of the replacement.
+func (c *Core) replaceTrade(dc *dexConnection, tracker *trackedTrade, rate, qty uint64) (*Order, error) {
+	oid := tracker.ID()
+	target, ok := tracker.Order.(*order.LimitOrder)
+	if !ok || target.Force == order.ImmediateTiF {
+		return nil, newError(orderParamsErr, "cannot replace %s order %s that is not a standing limit order", tracker.Type(), oid)
+	}
+
+	mktID := marketName(target.BaseAsset, target.QuoteAsset)
+	mktConf := dc.marketConfig(mktID)
+	if mktConf == nil {
+		return nil, newError(marketErr, "order placed for unknown market %q", mktID)
+	}
+	if !dc.running(mktID) {
+		return nil, newError(marketErr, "%s market trading is suspended", mktID)
+	}
+	if rate == 0 {
+		return nil, newError(orderParamsErr, "zero-rate order not allowed")
+	}
+
+	dc.tradeMtx.RLock()
+	for _, t := range dc.trades {
+		if t.replaces == tracker {
+			dc.tradeMtx.RUnlock()
+			return nil, newError(orderParamsErr, "order %s is already being replaced by order %s", oid, t.ID())
+		}
+	}
+	dc.tradeMtx.RUnlock()
+
+	tracker.mtx.RLock()
+	status, cancel, filled := tracker.metaData.Status, tracker.cancel, target.Filled()
+	coins := make(asset.Coins, 0, len(tracker.coins))
+	for _, coin := range tracker.coins {
+		coins = append(coins, coin)
+	}
+	metaData := *tracker.metaData
+	tracker.mtx.RUnlock()
+	if status != order.OrderStatusBooked {
+		return nil, newError(orderParamsErr, "order %s not replaceable in status %v", oid, status)
+	}
+	if cancel != nil {
+		return nil, newError(orderParamsErr, "order %s has a pending cancel order", oid)
+	}
+	if filled > 0 {
+		return nil, newError(orderParamsErr, "order %s is partially filled", oid)
+	}
+
+	// Construct the order. The coins, address, time in force, expiry, and
+	// display quantity of the replaced order are reused. If the quantity is
+	// reduced to the display quantity or less, the entire order is shown.
+	displayQty := target.DisplayQty
+	if displayQty >= qty {
+		displayQty = 0
+	}
+	preImg := newPreimage()
+	lo := &order.LimitOrder{
+		P: order.Prefix{
+			AccountID:  target.AccountID,
+			BaseAsset:  target.BaseAsset,
+			QuoteAsset: target.QuoteAsset,
+			OrderType:  order.LimitOrderType,
+			ClientTime: time.Now(),
+			Commit:     preImg.Commit(),
+		},
+		T: order.Trade{
+			Coins:    target.Coins,
+			Sell:     target.Sell,
+			Quantity: qty,
+			Address:  target.Address,
+		},
+		Rate:        rate,
+		Force:       target.Force,
+		ExpiryEpoch: target.ExpiryEpoch,
+		DisplayQty:  displayQty,
+		Replaces:    oid,
+	}
+	err := order.ValidateOrder(lo, order.OrderStatusEpoch, mktConf.LotSize)
+	if err != nil {
+		return nil, newError(orderParamsErr, "ValidateOrder error: %v", err)
+	}
+	if err = order.ValidateReplacement(lo, target); err != nil {
+		return nil, newError(orderParamsErr, "invalid replacement: %v", err)
+	}
+
+	wallets, err := c.walletSet(dc, target.BaseAsset, target.QuoteAsset, target.Sell)
+	if err != nil {
+		return nil, err
+	}
+
+	// The server already has the coins from the replaced order, so only the
+	// coin IDs are sent.
+	msgCoins := make([]*msgjson.Coin, 0, len(target.Coins))
+	for _, coinID := range target.Coins {
+		msgCoins = append(msgCoins, &msgjson.Coin{ID: []byte(coinID)})
+	}
+	route, msgOrder, _ := messageOrder(lo, msgCoins)
+
+	commitSig := make(chan struct{})
+	defer close(commitSig) // signals on both success and failure, unlike syncOrderPlaced/piSyncers
+	c.sentCommitsMtx.Lock()
+	c.sentCommits[lo.Commit] = commitSig
+	c.sentCommitsMtx.Unlock()
+
+	// Send and get the result.
+	result := new(msgjson.OrderResult)
+	err = dc.signAndRequest(msgOrder, route, result, DefaultResponseTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("replace order request with DEX server %v market %v failed: %w", dc.acct.host, mktID, err)
+	}
+
+	// The replacement does not lock the coins or reserves until it takes the
+	// place of the replaced order.
+	corder, err := c.storeTrade(dc, &tradeRequest{
+		ord:      lo,
+		msgOrder: msgOrder,
+		preImg:   preImg,
+		changeID: metaData.ChangeCoin,
+		options:  metaData.Options,
+		replaces: tracker,
+		coins:    coins,
+	}, result, wallets)
+	if err != nil {
+		return nil, err
+	}
+
+	c.log.Infof("Replacement order %s for order %s at %s has been placed", corder.ID, oid, dc.acct.host)
+
+	return corder, nil
+}
+
 // authDEX authenticates the connection for a DEX.
 func (c *Core) authDEX(dc *dexConnection) error {
 	// Prepare and sign the message for the 'connect' route.
@@ -4848,10 +5280,23 @@ func (c *Core) authDEX(dc *dexConnection) error {
 		suspended = *result.Suspended
 	}
 
+	// Servers that support bonds report the account tier. Legacy servers
+	// only report suspension, which we treat as tier 0.
+	tier := int64(1)
+	if result.Tier != nil {
+		tier = *result.Tier
+	} else if suspended {
+		tier = 0
+	}
+
 	// Set the account as authenticated.
-	c.log.Debugf("Authenticated connection to %s, acct %v, %d active orders, %d active matches, score %d (suspended = %v)",
-		dc.acct.host, acctID, len(result.ActiveOrderStatuses), len(result.ActiveMatches), result.Score, suspended)
+	c.log.Debugf("Authenticated connection to %s, acct %v, %d active orders, %d active matches, score %d (suspended = %v, tier = %d)",
+		dc.acct.host, acctID, len(result.ActiveOrderStatuses), len(result.ActiveMatches), result.Score, suspended, tier)
 	dc.acct.auth(suspended)
+	dc.acct.setTier(tier)
+	if result.Tier != nil {
+		c.reconcileBonds(dc, result.ActiveBonds)
+	}
 
 	// Associate the matches with known trades.
 	matches, _, err := dc.parseMatches(result.ActiveMatches, false)
@@ -4959,7 +5404,7 @@ func (c *Core) authDEX(dc *dexConnection) error {
 	var brokenTrades []*trackedTrade
 	dc.tradeMtx.RLock()
 	for _, trade := range dc.trades {
-		if lo, ok := trade.Order.(*order.LimitOrder); !ok || lo.Force != order.StandingTiF {
+		if lo, ok := trade.Order.(*order.LimitOrder); !ok || lo.Force == order.ImmediateTiF {
 			continue // only standing limit orders need to be canceled
 		}
 		trade.mtx.RLock()
@@ -5037,6 +5482,8 @@ func (c *Core) initialize() {
 		c.log.Infof("successfully loaded %d of %d wallets", numWallets, len(dbWallets))
 	}
 
+	c.loadStopOrders()
+
 	// Wait for DEXes to be connected to ensure DEXes are ready for
 	// authentication when Login is triggered. NOTE/TODO: Login could just as
 	// easily make the connection, but arguably configured DEXs should be
@@ -5123,8 +5570,8 @@ func (c *Core) checkUnpaidFees(wallet *xcWallet) {
 	}
 	var wg sync.WaitGroup
 	for _, dc := range c.dexConnections() {
-		if dc.acct.feePaid() {
-			continue
+		if dc.acct.feePaid() || dc.acct.hasBonds() {
+			continue // bonded accounts have no fee to check
 		}
 		if dc.acct.feeAssetID != wallet.AssetID {
 			continue // different wallet
@@ -5979,6 +6426,7 @@ func (c *Core) connectDEX(acctInfo *db.AccountInfo, temporary ...bool) (*dexConn
 		log:               c.log,
 		acct:              newDEXAccount(acctInfo),
 		notify:            c.notify,
+		db:                c.db,
 		ticker:            newDexTicker(defaultTickInterval), // updated when server config obtained
 		books:             make(map[string]*bookie),
 		trades:            make(map[order.OrderID]*trackedTrade),
@@ -6100,7 +6548,7 @@ func (c *Core) handleReconnect(host string) {
 
 	go dc.subPriceFeed()
 
-	if !dc.acct.locked() && dc.acct.feePaid() {
+	if !dc.acct.locked() && (dc.acct.feePaid() || dc.acct.hasActiveBonds()) {
 		err = c.authDEX(dc)
 		if err != nil {
 			c.log.Errorf("handleReconnect: Unable to authorize DEX at %s: %v", host, err)
@@ -6150,6 +6598,9 @@ func (c *Core) handleReconnect(host string) {
 				Book:  booky.book(),
 			},
 		})
+
+		// Refetch any subscribed candles to fill the gap while disconnected.
+		booky.refreshCandles()
 	}
 
 	// Create a list of books to check.
@@ -6220,6 +6671,10 @@ func handleMatchProofMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error
 			note.MarketID)
 	}
 
+	// Keep the proof for epoch transcript verification, even if it fails
+	// validation here.
+	book.recordMatchProof(&note)
+
 	err = book.ValidateMatchProof(note)
 	if err != nil {
 		return fmt.Errorf("match proof validation failed: %w", err)
@@ -6273,6 +6728,36 @@ func handleRevokeOrderMsg(c *Core, dc *dexConnection, msg *msgjson.Message) erro
 	return nil
 }
 
+// checkOrderExpiry is called when a good-til-time order is unbooked after its
+// expiry epoch. An order that is unbooked in its last epoch may have been
+// filled instead, so the order status is requested from the server before the
+// order is marked as expired.
+func (c *Core) checkOrderExpiry(dc *dexConnection, tracker *trackedTrade) {
+	req := []*msgjson.OrderStatusRequest{{
+		Base:    tracker.Base(),
+		Quote:   tracker.Quote(),
+		OrderID: tracker.ID().Bytes(),
+	}}
+	var res []*msgjson.OrderStatus
+	err := sendRequest(dc.WsConn, msgjson.OrderStatusRoute, req, &res, DefaultResponseTimeout)
+	if err != nil {
+		c.log.Errorf("Error retrieving status of order %s from DEX %s: %v", tracker.token(), dc.acct.host, err)
+		return
+	}
+	if len(res) != 1 || order.OrderStatus(res[0].Status) != order.OrderStatusExpired {
+		return
+	}
+	if !tracker.expire() {
+		return
+	}
+
+	subject, details := c.formatDetails(TopicOrderExpired, tracker.token(), tracker.mktID, dc.acct.host)
+	c.notify(newOrderNote(TopicOrderExpired, subject, details, db.Poke, tracker.coreOrder()))
+
+	// Update market orders, and the balance to account for unlocked coins.
+	c.updateAssetBalance(tracker.fromAssetID)
+}
+
 // handleRevokeMatchMsg is called when a revoke_match message is received.
 func handleRevokeMatchMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
 	var revocation msgjson.RevokeMatch
@@ -6342,6 +6827,42 @@ func handlePenaltyMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
 	return nil
 }
 
+// handleBondExpiredMsg is called when a bondexpired notification is received.
+// The bond is moved to the expired list, where it will be refunded by the bond
+// maintenance loop once its lock time has passed.
+func handleBondExpiredMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
+	var note msgjson.BondExpiredNotification
+	err := msg.Unmarshal(&note)
+	if err != nil {
+		return fmt.Errorf("bondexpired note unmarshal error: %w", err)
+	}
+	// Check the signature.
+	err = dc.acct.checkSig(note.Serialize(), note.Sig)
+	if err != nil {
+		return newError(signatureErr, "handleBondExpiredMsg: DEX signature validation error: %w", err)
+	}
+	acctID := dc.acct.ID()
+	if !bytes.Equal(note.AccountID, acctID[:]) {
+		return fmt.Errorf("invalid account ID %x, expected %v", note.AccountID, acctID)
+	}
+
+	dc.acct.authMtx.Lock()
+	for i, bond := range dc.acct.bonds {
+		if bond.AssetID == note.AssetID && bytes.Equal(bond.CoinID, note.BondID) {
+			dc.acct.bonds = append(dc.acct.bonds[:i], dc.acct.bonds[i+1:]...)
+			dc.acct.expiredBonds = append(dc.acct.expiredBonds, bond)
+			break
+		}
+	}
+	dc.acct.tier = note.Tier
+	dc.acct.authMtx.Unlock()
+
+	subject, details := c.formatDetails(TopicBondExpired, coinIDString(note.AssetID, note.BondID),
+		unbip(note.AssetID), dc.acct.host, note.Tier)
+	c.notify(newBondPostNoteWithTier(TopicBondExpired, subject, details, db.WarningLevel, dc.acct.host, note.Tier))
+	return nil
+}
+
 // routeHandler is a handler for a message from the DEX.
 type routeHandler func(*Core, *dexConnection, *msgjson.Message) error
 
@@ -6362,8 +6883,10 @@ var noteHandlers = map[string]routeHandler{
 	msgjson.EpochReportRoute:     handleEpochReportMsg,
 	msgjson.SuspensionRoute:      handleTradeSuspensionMsg,
 	msgjson.ResumptionRoute:      handleTradeResumptionMsg,
+	msgjson.ConfigUpdateRoute:    handleConfigUpdateMsg,
 	msgjson.NotifyRoute:          handleNotifyMsg,
 	msgjson.PenaltyRoute:         handlePenaltyMsg,
+	msgjson.BondExpiredRoute:     handleBondExpiredMsg,
 	msgjson.NoMatchRoute:         handleNoMatchRoute,
 	msgjson.RevokeOrderRoute:     handleRevokeOrderMsg,
 	msgjson.RevokeMatchRoute:     handleRevokeMatchMsg,
@@ -6805,7 +7328,7 @@ func handleNoMatchRoute(c *Core, dc *dexConnection, msg *msgjson.Message) error
 	if tracker == nil {
 		return newError(unknownOrderErr, "nomatch request received for unknown order %v from %s", oid, dc.acct.host)
 	}
-	updatedAssets, err := tracker.nomatch(oid)
+	updatedAssets, err := tracker.nomatch(oid, nomatchMsg.Failed)
 	if len(updatedAssets) > 0 {
 		c.updateBalances(updatedAssets)
 	}
@@ -7188,14 +7711,23 @@ func messageOrder(ord order.Order, coins []*msgjson.Coin) (string, msgjson.Stamp
 	switch o := ord.(type) {
 	case *order.LimitOrder:
 		tifFlag := uint8(msgjson.StandingOrderNum)
-		if o.Force == order.ImmediateTiF {
+		switch o.Force {
+		case order.ImmediateTiF:
 			tifFlag = msgjson.ImmediateOrderNum
+		case order.GoodTilTimeTiF:
+			tifFlag = msgjson.GoodTilTimeOrderNum
 		}
 		msgOrd := &msgjson.LimitOrder{
-			Prefix: *messagePrefix(prefix),
-			Trade:  *messageTrade(trade, coins),
-			Rate:   o.Rate,
-			TiF:    tifFlag,
+			Prefix:      *messagePrefix(prefix),
+			Trade:       *messageTrade(trade, coins),
+			Rate:        o.Rate,
+			TiF:         tifFlag,
+			ExpiryEpoch: o.ExpiryEpoch,
+			DisplayQty:  o.DisplayQty,
+		}
+		if o.IsReplacement() {
+			msgOrd.Replaces = o.Replaces[:]
+			return msgjson.ReplaceRoute, msgOrd, &msgOrd.Trade
 		}
 		return msgjson.LimitRoute, msgOrd, &msgOrd.Trade
 	case *order.MarketOrder:
@@ -7300,6 +7832,72 @@ func (c *Core) WalletLogFilePath(assetID uint32) (string, error) {
 	return wallet.logFilePath()
 }
 
+// ListUnspent lists the spendable outputs of a wallet that supports coin
+// control. Outputs that are funding orders are not included. The coin IDs can
+// be used to fund a trade with TradeForm.Coins, or in a Send.
+func (c *Core) ListUnspent(assetID uint32) ([]*asset.UnspentCoin, error) {
+	wallet, err := c.connectedWallet(assetID)
+	if err != nil {
+		return nil, err
+	}
+	if !wallet.traits.IsCoinController() {
+		return nil, newError(coinControlErr, "%s wallet does not support coin control", unbip(assetID))
+	}
+	unspents, err := wallet.listUnspent()
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s unspent outputs: %w", unbip(assetID), err)
+	}
+	return unspents, nil
+}
+
+// TxHistory returns up to n of the transactions in a wallet's transaction
+// history, most recent first. If refID is not nil, only transactions older than
+// the refID transaction are returned. If n <= 0, all transactions are returned.
+// Swap, redeem and refund transactions are matched to the active trades' matches
+// by secret hash.
+func (c *Core) TxHistory(assetID uint32, n int, refID *string) ([]*WalletTransaction, error) {
+	wallet, err := c.connectedWallet(assetID)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := wallet.txHistory(n, refID)
+	if err != nil {
+		if errors.Is(err, asset.ErrTxNotFound) {
+			return nil, newError(unknownTransactionErr, "transaction %s not found in %s wallet history",
+				*refID, unbip(assetID))
+		}
+		return nil, fmt.Errorf("error retrieving %s transaction history: %w", unbip(assetID), err)
+	}
+
+	matchIDs := make(map[string]order.MatchID)
+	for _, dc := range c.dexConnections() {
+		for _, t := range dc.trackedTrades() {
+			if t.Base() != assetID && t.Quote() != assetID {
+				continue
+			}
+			t.mtx.RLock()
+			for _, match := range t.matches {
+				if secretHash := match.MetaData.Proof.SecretHash; len(secretHash) > 0 {
+					matchIDs[string(secretHash)] = match.MatchID
+				}
+			}
+			t.mtx.RUnlock()
+		}
+	}
+
+	history := make([]*WalletTransaction, 0, len(txs))
+	for _, tx := range txs {
+		wtx := &WalletTransaction{WalletTransaction: tx}
+		for _, secretHash := range tx.SecretHashes {
+			if mid, found := matchIDs[string(secretHash)]; found {
+				wtx.MatchIDs = append(wtx.MatchIDs, mid[:])
+			}
+		}
+		history = append(history, wtx)
+	}
+	return history, nil
+}
+
 // WalletRestorationInfo returns information about how to restore the currently
 // loaded wallet for assetID in various external wallet software. This function
 // will return an error if the currently loaded wallet for assetID does not
@@ -7713,6 +8311,67 @@ func (c *Core) PreAccelerateOrder(oidB dex.Bytes) (*PreAccelerate, error) {
 	}, nil
 }
 
+// BumpFee replaces the order's unconfirmed redemption and refund transactions
+// with transactions paying newFeeRate, if they pay a lower fee rate. If
+// newFeeRate is zero, the current fee suggestion is used. The transactions are
+// otherwise bumped automatically as the lock time of the redeemed contract
+// approaches. The wallets must be FeeBumpers.
+func (c *Core) BumpFee(pw []byte, oidB dex.Bytes, newFeeRate uint64) error {
+	_, err := c.encryptionKey(pw)
+	if err != nil {
+		return fmt.Errorf("BumpFee password error: %w", err)
+	}
+
+	oid, err := order.IDFromBytes(oidB)
+	if err != nil {
+		return err
+	}
+	tracker, err := c.findActiveOrder(oid)
+	if err != nil {
+		return err
+	}
+
+	tracker.mtx.Lock()
+	defer tracker.mtx.Unlock()
+
+	var bumped int
+	for _, match := range tracker.matches {
+		if !match.MetaData.Proof.PendingSpend {
+			continue
+		}
+		coinID, refund := tracker.spendCoin(match)
+		wallet := tracker.wallets.toWallet
+		if refund {
+			wallet = tracker.wallets.fromWallet
+		}
+		feeRate, err := wallet.bumpableFeeRate(dex.Bytes(coinID))
+		if err != nil {
+			if errors.Is(err, asset.ErrTxConfirmed) {
+				tracker.clearPendingSpend(match)
+				continue
+			}
+			return fmt.Errorf("error checking fee rate of %s transaction %s: %w",
+				unbip(wallet.AssetID), coinIDString(wallet.AssetID, coinID), err)
+		}
+		bumpRate := newFeeRate
+		if bumpRate == 0 {
+			bumpRate = c.feeSuggestionAny(wallet.AssetID)
+		}
+		if bumpRate <= feeRate {
+			continue // already paying at least the new rate, or replaced for another match
+		}
+		if err := c.bumpSpendFee(tracker, match, bumpRate); err != nil {
+			return fmt.Errorf("error bumping fee of %s transaction %s: %w",
+				unbip(wallet.AssetID), coinIDString(wallet.AssetID, coinID), err)
+		}
+		bumped++
+	}
+	if bumped == 0 {
+		return errors.New("no unconfirmed redemption or refund transactions paying a lower fee rate")
+	}
+	return nil
+}
+
 // findActiveOrder will search the dex connections for an active order by order
 // id. An error is returned if it cannot be found.
 func (c *Core) findActiveOrder(oid order.OrderID) (*trackedTrade, error) {
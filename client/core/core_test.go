@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
 	"reflect"
@@ -28,6 +29,7 @@ import (
 	dbtest "decred.org/dcrdex/client/db/test"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/candles"
 	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/encrypt"
 	"decred.org/dcrdex/dex/msgjson"
@@ -343,6 +345,8 @@ type TDB struct {
 	activeDEXOrders          []*db.MetaOrder
 	matchesForOID            []*db.MetaMatch
 	matchesForOIDErr         error
+	matchesByOID             map[order.OrderID][]*db.MetaMatch
+	orders                   []*db.MetaOrder
 	updateMatchChan          chan order.MatchStatus
 	activeMatchOIDs          []order.OrderID
 	activeMatchOIDSErr       error
@@ -371,6 +375,13 @@ type TDB struct {
 	deleteInactiveOrdersErr  error
 	deleteInactiveMatchesErr error
 	updateAccountInfoErr     error
+	bonds                    []*db.Bond
+	addBondErr               error
+	bondKeyIdx               uint32
+	confirmedBonds           [][]byte
+	refundedBonds            [][]byte
+	stopOrders               map[string]*db.StopOrder
+	candles                  map[uint64][]*candles.Candle
 }
 
 func (tdb *TDB) Run(context.Context) {}
@@ -428,7 +439,7 @@ func (tdb *TDB) Order(oid order.OrderID) (*db.MetaOrder, error) {
 }
 
 func (tdb *TDB) Orders(*db.OrderFilter) ([]*db.MetaOrder, error) {
-	return nil, nil
+	return tdb.orders, nil
 }
 
 func (tdb *TDB) MarketOrders(dex string, base, quote uint32, n int, since uint64) ([]*db.MetaOrder, error) {
@@ -463,6 +474,9 @@ func (tdb *TDB) ActiveMatches() ([]*db.MetaMatch, error) {
 }
 
 func (tdb *TDB) MatchesForOrder(oid order.OrderID, excludeCancels bool) ([]*db.MetaMatch, error) {
+	if tdb.matchesByOID != nil {
+		return tdb.matchesByOID[oid], tdb.matchesForOIDErr
+	}
 	return tdb.matchesForOID, tdb.matchesForOIDErr
 }
 
@@ -501,6 +515,87 @@ func (tdb *TDB) AccountPaid(proof *db.AccountProof) error {
 	return tdb.accountPaidErr
 }
 
+func (tdb *TDB) AddBond(host string, bond *db.Bond) error {
+	if tdb.addBondErr != nil {
+		return tdb.addBondErr
+	}
+	for i, b := range tdb.bonds {
+		if b.AssetID == bond.AssetID && bytes.Equal(b.CoinID, bond.CoinID) {
+			tdb.bonds[i] = bond
+			return nil
+		}
+	}
+	tdb.bonds = append(tdb.bonds, bond)
+	return nil
+}
+
+func (tdb *TDB) NextBondKeyIndex(assetID uint32) (uint32, error) {
+	idx := tdb.bondKeyIdx
+	tdb.bondKeyIdx++
+	return idx, nil
+}
+
+func (tdb *TDB) ConfirmBond(host string, assetID uint32, bondCoinID []byte) error {
+	tdb.confirmedBonds = append(tdb.confirmedBonds, bondCoinID)
+	return nil
+}
+
+func (tdb *TDB) BondRefunded(host string, assetID uint32, bondCoinID []byte) error {
+	tdb.refundedBonds = append(tdb.refundedBonds, bondCoinID)
+	return nil
+}
+
+func (tdb *TDB) UpdateStopOrder(so *db.StopOrder) error {
+	if tdb.stopOrders == nil {
+		tdb.stopOrders = make(map[string]*db.StopOrder)
+	}
+	tdb.stopOrders[hex.EncodeToString(so.ID)] = so
+	return nil
+}
+
+func (tdb *TDB) StopOrders() ([]*db.StopOrder, error) {
+	sos := make([]*db.StopOrder, 0, len(tdb.stopOrders))
+	for _, so := range tdb.stopOrders {
+		sos = append(sos, so)
+	}
+	return sos, nil
+}
+
+func (tdb *TDB) DeleteStopOrder(id []byte) error {
+	delete(tdb.stopOrders, hex.EncodeToString(id))
+	return nil
+}
+
+func (tdb *TDB) UpdateCandles(host string, base, quote uint32, binSize uint64, cands []*candles.Candle) error {
+	if tdb.candles == nil {
+		tdb.candles = make(map[uint64][]*candles.Candle)
+	}
+next:
+	for _, c := range cands {
+		c := *c
+		stored := tdb.candles[binSize]
+		for i, s := range stored {
+			if s.StartStamp == c.StartStamp {
+				stored[i] = &c
+				continue next
+			}
+		}
+		tdb.candles[binSize] = append(stored, &c)
+	}
+	return nil
+}
+
+func (tdb *TDB) Candles(host string, base, quote uint32, binSize, start, end uint64) ([]*candles.Candle, error) {
+	var cands []*candles.Candle
+	for _, c := range tdb.candles[binSize] {
+		if c.StartStamp >= start && (end == 0 || c.StartStamp < end) {
+			cands = append(cands, c)
+		}
+	}
+	sort.Slice(cands, func(i, j int) bool { return cands[i].StartStamp < cands[j].StartStamp })
+	return cands, nil
+}
+
 func (tdb *TDB) SaveNotification(*db.Notification) error            { return nil }
 func (tdb *TDB) BackupTo(dst string, overwrite, compact bool) error { return nil }
 func (tdb *TDB) NotificationsN(int) ([]*db.Notification, error)     { return nil, nil }
@@ -1011,6 +1106,74 @@ func (w *TFeeRater) FeeRate() uint64 {
 	return w.feeRate
 }
 
+type TWalletHistorian struct {
+	*TXCWallet
+	txs        []*asset.WalletTransaction
+	historyErr error
+}
+
+func (w *TWalletHistorian) TxHistory(n int, refID *string) ([]*asset.WalletTransaction, error) {
+	return w.txs, w.historyErr
+}
+
+type TFeeBumper struct {
+	*TXCWallet
+	feeRates   map[string]uint64 // by coin ID. all coins are in one tx
+	feeRateErr error
+	bumpFees   uint64
+	bumpErr    error
+	bumps      int
+}
+
+func (w *TFeeBumper) BumpableFeeRate(coinID dex.Bytes) (uint64, error) {
+	if w.feeRateErr != nil {
+		return 0, w.feeRateErr
+	}
+	feeRate, found := w.feeRates[coinID.String()]
+	if !found {
+		return 0, asset.ErrTxConfirmed
+	}
+	return feeRate, nil
+}
+
+func (w *TFeeBumper) BumpFee(coinID dex.Bytes, newFeeRate uint64) (map[string]dex.Bytes, uint64, error) {
+	if w.bumpErr != nil {
+		return nil, 0, w.bumpErr
+	}
+	w.bumps++
+	replacements := make(map[string]dex.Bytes, len(w.feeRates))
+	feeRates := make(map[string]uint64, len(w.feeRates))
+	for id := range w.feeRates {
+		newCoinID := dex.Bytes(encode.RandomBytes(36))
+		replacements[id] = newCoinID
+		feeRates[newCoinID.String()] = newFeeRate
+	}
+	w.feeRates = feeRates
+	return replacements, w.bumpFees, nil
+}
+
+type TCoinController struct {
+	*TXCWallet
+	unspents    []*asset.UnspentCoin
+	listErr     error
+	fundedCoins []dex.Bytes
+	sentCoins   []dex.Bytes
+}
+
+func (w *TCoinController) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes, error) {
+	w.fundedCoins = ord.Coins
+	return w.TXCWallet.FundOrder(ord)
+}
+
+func (w *TCoinController) ListUnspent() ([]*asset.UnspentCoin, error) {
+	return w.unspents, w.listErr
+}
+
+func (w *TCoinController) SendWithCoins(address string, value, feeRate uint64, coinIDs []dex.Bytes) (asset.Coin, error) {
+	w.sentCoins = coinIDs
+	return w.Send(address, value, feeRate)
+}
+
 type TLiveReconfigurer struct {
 	*TXCWallet
 	restart     bool
@@ -1122,6 +1285,7 @@ func newTestRig() *testRig {
 
 	crypter := &tCrypter{}
 	dc, conn, acct := testDexConnection(ctx, crypter) // crypter makes acct.encKey consistent with privKey
+	dc.db = tdb
 
 	shutdown := func() {
 		cancel()
@@ -1140,13 +1304,15 @@ func newTestRig() *testRig {
 			conns: map[string]*dexConnection{
 				tDexHost: dc,
 			},
-			lockTimeTaker: dex.LockTimeTaker(dex.Testnet),
-			lockTimeMaker: dex.LockTimeMaker(dex.Testnet),
-			wallets:       make(map[uint32]*xcWallet),
-			blockWaiters:  make(map[string]*blockWaiter),
-			piSyncers:     make(map[order.OrderID]chan struct{}),
-			sentCommits:   make(map[order.Commitment]chan struct{}),
-			tickSched:     make(map[order.OrderID]*time.Timer),
+			lockTimeTaker:    dex.LockTimeTaker(dex.Testnet),
+			lockTimeMaker:    dex.LockTimeMaker(dex.Testnet),
+			wallets:          make(map[uint32]*xcWallet),
+			blockWaiters:     make(map[string]*blockWaiter),
+			piSyncers:        make(map[order.OrderID]chan struct{}),
+			sentCommits:      make(map[order.Commitment]chan struct{}),
+			tickSched:        make(map[order.OrderID]*time.Timer),
+			stopOrders:       make(map[string]*db.StopOrder),
+			stopOrderRetries: make(map[string]*stopOrderRetry),
 			wsConstructor: func(*comms.WsCfg) (comms.WsConn, error) {
 				// This is not very realistic since it doesn't start a fresh
 				// one, and (*Core).connectDEX always gets the same TWebsocket,
@@ -1167,7 +1333,7 @@ func newTestRig() *testRig {
 		crypter: crypter,
 	}
 
-	rig.core.InitializeClient(tPW, nil)
+	rig.core.InitializeClient(tPW, "")
 
 	// tCrypter doesn't actually use random bytes supplied by InitializeClient,
 	// (the crypter is known ahead of time) but if that changes, we would need
@@ -1597,6 +1763,107 @@ func TestBookFeed(t *testing.T) {
 	checkAction(feed2, CandleUpdateAction)
 	checkAction(feed2, CandleUpdateAction)
 
+	// The fetched candles and the updates are stored.
+	cands, err := tCore.Candles(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, "1h", 0, 0)
+	if err != nil {
+		t.Fatalf("Candles error: %v", err)
+	}
+	if len(cands) != 2 || cands[0].StartStamp != 1 || cands[1].StartStamp != 2 {
+		t.Fatalf("wrong stored candles: %+v", cands)
+	}
+	cands, _ = tCore.Candles(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, "1h", 2, 3)
+	if len(cands) != 1 || cands[0].StartStamp != 2 {
+		t.Fatalf("wrong stored candles for time range: %+v", cands)
+	}
+	if _, err := tCore.Candles(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, "1h", 2, 2); err == nil {
+		t.Fatalf("no error for empty time range")
+	}
+	if _, err := tCore.Candles(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, "blue", 0, 0); err == nil {
+		t.Fatalf("no error for bad bin size")
+	}
+	if _, err := tCore.Candles("unknown.dex", tUTXOAssetA.ID, tUTXOAssetB.ID, "1h", 0, 0); err == nil {
+		t.Fatalf("no error for unknown DEX")
+	}
+
+	// Reconnecting refetches the candles.
+	queueCandles()
+	queueCandles()
+	booky := dc.bookie(tDcrBtcMktName)
+	booky.refreshCandles()
+	checkAction(feed2, FreshCandlesAction)
+	checkAction(feed2, FreshCandlesAction)
+}
+
+func TestCandlesBackfill(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	const binSize = uint64(time.Hour / time.Millisecond)
+	now := uint64(time.Now().UnixMilli())
+	lastStamp := now - now%binSize
+
+	var reqN int
+	queueCandles := func(stamps ...uint64) {
+		rig.ws.queueResponse(msgjson.CandlesRoute, func(msg *msgjson.Message, f msgFunc) error {
+			req := new(msgjson.CandlesRequest)
+			msg.Unmarshal(req)
+			reqN = req.NumCandles
+			wc := new(msgjson.WireCandles)
+			for _, stamp := range stamps {
+				wc.StartStamps = append(wc.StartStamps, stamp)
+				wc.EndStamps = append(wc.EndStamps, stamp+binSize)
+				wc.MatchVolumes = append(wc.MatchVolumes, 1)
+				wc.QuoteVolumes = append(wc.QuoteVolumes, 1)
+				wc.HighRates = append(wc.HighRates, 2)
+				wc.LowRates = append(wc.LowRates, 1)
+				wc.StartRates = append(wc.StartRates, 1)
+				wc.EndRates = append(wc.EndRates, 2)
+			}
+			resp, _ := msgjson.NewResponse(msg.ID, wc, nil)
+			f(resp)
+			return nil
+		})
+	}
+	getCandles := func() []candles.Candle {
+		t.Helper()
+		cands, err := tCore.Candles(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, "1h", 0, 0)
+		if err != nil {
+			t.Fatalf("Candles error: %v", err)
+		}
+		return cands
+	}
+
+	// With nothing stored, the server's full history is requested.
+	queueCandles(lastStamp-3*binSize, lastStamp-2*binSize)
+	cands := getCandles()
+	if reqN != candles.CacheSize {
+		t.Fatalf("expected a request for %d candles, got %d", candles.CacheSize, reqN)
+	}
+	if len(cands) != 2 {
+		t.Fatalf("expected 2 candles, got %d", len(cands))
+	}
+
+	// The gap since the last stored candle is backfilled, including the last
+	// stored candle.
+	queueCandles(lastStamp-2*binSize, lastStamp-binSize, lastStamp)
+	cands = getCandles()
+	if reqN != 3 {
+		t.Fatalf("expected a request for 3 candles, got %d", reqN)
+	}
+	if len(cands) != 4 || cands[3].StartStamp != lastStamp {
+		t.Fatalf("wrong backfilled candles: %+v", cands)
+	}
+
+	// If the server can't be reached, the stored candles are returned.
+	rig.ws.reqErr = tErr
+	if cands = getCandles(); len(cands) != 4 {
+		t.Fatalf("expected 4 stored candles, got %d", len(cands))
+	}
+	// But an error is returned if there are none stored.
+	if _, err := tCore.Candles(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, "24h", 0, 0); err == nil {
+		t.Fatalf("no error for no stored candles and a request error")
+	}
 }
 
 type tDriver struct {
@@ -2473,7 +2740,7 @@ func TestInitializeClient(t *testing.T) {
 
 	clearCreds()
 
-	err := tCore.InitializeClient(tPW, nil)
+	err := tCore.InitializeClient(tPW, "")
 	if err != nil {
 		t.Fatalf("InitializeClient error: %v", err)
 	}
@@ -2482,26 +2749,90 @@ func TestInitializeClient(t *testing.T) {
 
 	// Empty password.
 	emptyPass := []byte("")
-	err = tCore.InitializeClient(emptyPass, nil)
+	err = tCore.InitializeClient(emptyPass, "")
 	if err == nil {
 		t.Fatalf("no error for empty password")
 	}
 
 	// Store error. Use a non-empty password to pass empty password check.
 	rig.db.setCredsErr = tErr
-	err = tCore.InitializeClient(tPW, nil)
+	err = tCore.InitializeClient(tPW, "")
 	if err == nil {
 		t.Fatalf("no error for StoreEncryptedKey error")
 	}
 	rig.db.setCredsErr = nil
 
 	// Success again
-	err = tCore.InitializeClient(tPW, nil)
+	err = tCore.InitializeClient(tPW, "")
 	if err != nil {
 		t.Fatalf("final InitializeClient error: %v", err)
 	}
 }
 
+func TestRestoreAndExportSeed(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.db.existValues[keyParamsKey] = false
+	tCore.newCrypter = encrypt.NewCrypter
+	tCore.reCrypter = encrypt.Deserialize
+
+	clearCreds := func() {
+		tCore.credentials = nil
+		tCore.seedGenerationTime = 0
+		rig.db.creds = nil
+	}
+
+	seed := encode.RandomBytes(64)
+	const genTime = 1_650_000_000
+	mnemonic, err := encodeSeedMnemonic(seed, genTime)
+	if err != nil {
+		t.Fatalf("encodeSeedMnemonic error: %v", err)
+	}
+
+	// Restore from a mnemonic. The generation time is restored.
+	clearCreds()
+	if err = tCore.InitializeClient(tPW, mnemonic); err != nil {
+		t.Fatalf("InitializeClient error for mnemonic seed: %v", err)
+	}
+	if tCore.seedGenerationTime != genTime/secondsPerDay*secondsPerDay {
+		t.Fatalf("wrong seed generation time %d", tCore.seedGenerationTime)
+	}
+	exported, err := tCore.ExportSeed(tPW)
+	if err != nil {
+		t.Fatalf("ExportSeed error: %v", err)
+	}
+	if exported != mnemonic {
+		t.Fatalf("exported seed does not match the restored mnemonic")
+	}
+
+	// Restore from a legacy hex seed. The generation time is unknown.
+	clearCreds()
+	if err = tCore.InitializeClient(tPW, hex.EncodeToString(seed)); err != nil {
+		t.Fatalf("InitializeClient error for hex seed: %v", err)
+	}
+	if tCore.seedGenerationTime != 0 {
+		t.Fatalf("seed generation time set for hex seed")
+	}
+	exported, err = tCore.ExportSeed(tPW)
+	if err != nil {
+		t.Fatalf("ExportSeed error: %v", err)
+	}
+	reSeed, _, err := decodeSeed(exported)
+	if err != nil {
+		t.Fatalf("error decoding exported seed: %v", err)
+	}
+	if !bytes.Equal(reSeed, seed) {
+		t.Fatalf("exported seed does not match the restored hex seed")
+	}
+
+	// Invalid seed.
+	clearCreds()
+	if err = tCore.InitializeClient(tPW, "abandon ability"); err == nil {
+		t.Fatalf("no error for invalid seed")
+	}
+}
+
 func TestSend(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
@@ -2511,19 +2842,19 @@ func TestSend(t *testing.T) {
 	address := "addr"
 
 	// Successful
-	_, err := tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	_, err := tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false, nil)
 	if err != nil {
 		t.Fatalf("Send error: %v", err)
 	}
 
 	// 0 value
-	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 0, address, false)
+	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 0, address, false, nil)
 	if err == nil {
 		t.Fatalf("no error for zero value send")
 	}
 
 	// no wallet
-	_, err = tCore.Send(tPW, 12345, 1e8, address, false)
+	_, err = tCore.Send(tPW, 12345, 1e8, address, false, nil)
 	if err == nil {
 		t.Fatalf("no error for unknown wallet")
 	}
@@ -2531,7 +2862,7 @@ func TestSend(t *testing.T) {
 	// connect error
 	wallet.hookedUp = false
 	tWallet.connectErr = tErr
-	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false, nil)
 	if err == nil {
 		t.Fatalf("no error for wallet connect error")
 	}
@@ -2539,7 +2870,7 @@ func TestSend(t *testing.T) {
 
 	// Send error
 	tWallet.sendErr = tErr
-	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false, nil)
 	if err == nil {
 		t.Fatalf("no error for wallet send error")
 	}
@@ -2547,7 +2878,7 @@ func TestSend(t *testing.T) {
 
 	// Check the coin.
 	tWallet.sendCoin = &tCoin{id: []byte{'a'}}
-	coin, err := tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	coin, err := tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false, nil)
 	if err != nil {
 		t.Fatalf("coin check error: %v", err)
 	}
@@ -2570,7 +2901,7 @@ func TestSend(t *testing.T) {
 
 	wallet.Wallet = feeRater
 
-	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false, nil)
 	if err != nil {
 		t.Fatalf("FeeRater Withdraw/send error: %v", err)
 	}
@@ -2580,6 +2911,137 @@ func TestSend(t *testing.T) {
 	}
 }
 
+func TestCoinControl(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+	address := "addr"
+	coinIDs := []dex.Bytes{encode.RandomBytes(36), encode.RandomBytes(36)}
+
+	// Not a coin controller.
+	if _, err := tCore.ListUnspent(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error listing unspents for a wallet without coin control")
+	}
+	if _, err := tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false, coinIDs); err == nil {
+		t.Fatalf("no error sending with coins for a wallet without coin control")
+	}
+
+	controller := &TCoinController{
+		TXCWallet: tWallet,
+		unspents: []*asset.UnspentCoin{
+			{ID: coinIDs[0], Value: 1e8},
+			{ID: coinIDs[1], Value: 2e8},
+		},
+	}
+	wallet.Wallet = controller
+	wallet.traits = asset.DetermineWalletTraits(controller)
+
+	unspents, err := tCore.ListUnspent(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("ListUnspent error: %v", err)
+	}
+	if len(unspents) != 2 {
+		t.Fatalf("expected 2 unspents, got %d", len(unspents))
+	}
+
+	controller.listErr = tErr
+	if _, err = tCore.ListUnspent(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error for ListUnspent error")
+	}
+	controller.listErr = nil
+
+	// Fees can't be subtracted when sending with selected coins.
+	if _, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, true, coinIDs); err == nil {
+		t.Fatalf("no error for subtract with selected coins")
+	}
+
+	if _, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false, coinIDs); err != nil {
+		t.Fatalf("Send with coins error: %v", err)
+	}
+	if len(controller.sentCoins) != 2 || !bytes.Equal(controller.sentCoins[1], coinIDs[1]) {
+		t.Fatalf("selected coins not passed to SendWithCoins")
+	}
+
+	// A regular send doesn't use the coin controller.
+	controller.sentCoins = nil
+	if _, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false, nil); err != nil {
+		t.Fatalf("Send error: %v", err)
+	}
+	if controller.sentCoins != nil {
+		t.Fatalf("SendWithCoins used for a send without selected coins")
+	}
+}
+
+func TestTxHistory(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	// Not a WalletHistorian.
+	if _, err := tCore.TxHistory(tUTXOAssetA.ID, 0, nil); err == nil {
+		t.Fatalf("no error for wallet without tx history")
+	}
+
+	historian := &TWalletHistorian{TXCWallet: tDcrWallet}
+	dcrWallet.Wallet = historian
+
+	// A trade with a swapped match.
+	mkt := dc.marketConfig(tDcrBtcMktName)
+	walletSet, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	tracker := makeTradeTracker(rig, mkt, walletSet, order.StandingTiF, order.OrderStatusBooked)
+	matchID := ordertest.RandomMatchID()
+	secretHash := encode.RandomBytes(32)
+	tracker.matches[matchID] = &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{MatchID: matchID},
+			MetaData: &db.MatchMetaData{
+				Proof: db.MatchProof{SecretHash: secretHash},
+			},
+		},
+	}
+	dc.trades[tracker.ID()] = tracker
+
+	historian.txs = []*asset.WalletTransaction{
+		{Type: asset.Swap, ID: "swap", SecretHashes: []dex.Bytes{encode.RandomBytes(32), secretHash}},
+		{Type: asset.Receive, ID: "deposit"},
+	}
+	txs, err := tCore.TxHistory(tUTXOAssetA.ID, 0, nil)
+	if err != nil {
+		t.Fatalf("TxHistory error: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("expected 2 txs, got %d", len(txs))
+	}
+	if len(txs[0].MatchIDs) != 1 || !bytes.Equal(txs[0].MatchIDs[0], matchID[:]) {
+		t.Fatalf("swap not matched")
+	}
+	if len(txs[1].MatchIDs) != 0 {
+		t.Fatalf("deposit has match IDs")
+	}
+
+	// Unknown reference transaction.
+	historian.historyErr = asset.ErrTxNotFound
+	refID := "abc"
+	_, err = tCore.TxHistory(tUTXOAssetA.ID, 1, &refID)
+	var coreErr *Error
+	if !errors.As(err, &coreErr) || coreErr.code != unknownTransactionErr {
+		t.Fatalf("wrong error for unknown reference transaction: %v", err)
+	}
+
+	// No wallet.
+	if _, err := tCore.TxHistory(12345, 0, nil); err == nil {
+		t.Fatalf("no error for unknown wallet")
+	}
+}
+
 func TestTrade(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
@@ -2712,6 +3174,72 @@ func TestTrade(t *testing.T) {
 	}
 	tDcrWallet.fundedSwaps = 0
 
+	// Coins selected by the user require a wallet that supports coin control.
+	form.Coins = []dex.Bytes{dcrCoin.id}
+	ensureErr("coin control not supported")
+	controller := &TCoinController{TXCWallet: tDcrWallet}
+	dcrWallet.Wallet = controller
+	dcrWallet.traits = asset.DetermineWalletTraits(controller)
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	_, err = tCore.Trade(tPW, form)
+	if err != nil {
+		t.Fatalf("limit order with selected coins error: %v", err)
+	}
+	if len(controller.fundedCoins) != 1 || !bytes.Equal(controller.fundedCoins[0], dcrCoin.id) {
+		t.Fatalf("selected coins not passed to FundOrder")
+	}
+	form.Coins = nil
+	dcrWallet.Wallet = tDcrWallet
+	dcrWallet.traits = asset.DetermineWalletTraits(tDcrWallet)
+
+	// Good-til-time limit order.
+	epochLen := rig.dc.marketEpochDuration(tDcrBtcMktName)
+	form.Expiry = uint64(time.Now().UnixMilli()) + 10*epochLen
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	corder, err := tCore.Trade(tPW, form)
+	if err != nil {
+		t.Fatalf("good-til-time limit order error: %v", err)
+	}
+	if corder.TimeInForce != order.GoodTilTimeTiF || corder.ExpiryEpoch != form.Expiry/epochLen {
+		t.Fatalf("wrong good-til-time order, tif = %s, expiry epoch = %d", corder.TimeInForce, corder.ExpiryEpoch)
+	}
+	tDcrWallet.fundedVal = 0
+	tDcrWallet.fundedSwaps = 0
+	// Expiry in the current epoch.
+	form.Expiry = uint64(time.Now().UnixMilli())
+	ensureErr("expired")
+	// Expiry with immediate time-in-force.
+	form.Expiry = uint64(time.Now().UnixMilli()) + 10*epochLen
+	form.TifNow = true
+	ensureErr("immediate with expiry")
+	form.TifNow = false
+	form.Expiry = 0
+
+	// Iceberg limit order.
+	form.DisplayQty = dcrBtcLotSize
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	corder, err = tCore.Trade(tPW, form)
+	if err != nil {
+		t.Fatalf("iceberg limit order error: %v", err)
+	}
+	if corder.DisplayQty != form.DisplayQty || corder.Visible != form.DisplayQty || corder.Qty != qty {
+		t.Fatalf("wrong iceberg order, display = %d, visible = %d, qty = %d", corder.DisplayQty, corder.Visible, corder.Qty)
+	}
+	tDcrWallet.fundedVal = 0
+	tDcrWallet.fundedSwaps = 0
+	// Display quantity not a lot multiple.
+	form.DisplayQty = dcrBtcLotSize + 1
+	ensureErr("display quantity lot size")
+	// Display quantity not less than the order quantity.
+	form.DisplayQty = qty
+	ensureErr("display quantity too large")
+	// Display quantity with immediate time-in-force.
+	form.DisplayQty = dcrBtcLotSize
+	form.TifNow = true
+	ensureErr("immediate with display quantity")
+	form.TifNow = false
+	form.DisplayQty = 0
+
 	// Should not be able to close wallet now, since there are orders.
 	if tCore.CloseWallet(tUTXOAssetA.ID) == nil {
 		t.Fatalf("no error for closing DCR wallet with active orders")
@@ -3128,7 +3656,7 @@ func TestRefundReserves(t *testing.T) {
 	dc.trades = map[order.OrderID]*trackedTrade{moid: tracker}
 
 	test("nomatch", reserves, func() {
-		tracker.nomatch(moid)
+		tracker.nomatch(moid, false)
 	})
 
 	test("partial market sell match", reserves/3, func() {
@@ -3334,7 +3862,7 @@ func TestRedemptionReserves(t *testing.T) {
 	dc.trades = map[order.OrderID]*trackedTrade{moid: tracker}
 
 	test("nomatch", reserves, func() {
-		tracker.nomatch(moid)
+		tracker.nomatch(moid, false)
 	})
 
 	test("partial market sell match", reserves/3, func() {
@@ -3481,6 +4009,114 @@ func TestCancel(t *testing.T) {
 	rig.ws.reqErr = nil
 }
 
+func TestReplace(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+
+	qty := dcrBtcLotSize * 10
+	rate := dcrBtcRateStep * 1000
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, rate)
+	lo.Force = order.GoodTilTimeTiF
+	lo.ExpiryEpoch = 1000
+	lo.DisplayQty = dcrBtcLotSize * 2
+	coin := &tCoin{id: encode.RandomBytes(36), val: qty}
+	lo.Coins = []order.CoinID{coin.id}
+	dbOrder.MetaData.Status = order.OrderStatusBooked
+	oid := lo.ID()
+	mkt := dc.marketConfig(tDcrBtcMktName)
+	tracker := newTrackedTrade(dbOrder, preImg, dc, mkt.EpochLen, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, asset.Coins{coin}, rig.core.notify, rig.core.formatDetails, nil, 0, 0)
+	dc.trades[oid] = tracker
+
+	queueReplace := func() {
+		rig.ws.queueResponse(msgjson.ReplaceRoute, func(msg *msgjson.Message, f msgFunc) error {
+			msgOrder := new(msgjson.LimitOrder)
+			msg.Unmarshal(msgOrder)
+			f(orderResponse(msg.ID, msgOrder, convertMsgLimitOrder(msgOrder), false, false, false))
+			return nil
+		})
+	}
+
+	ensureErr := func(tag string, rate, qty uint64) {
+		t.Helper()
+		if _, err := tCore.Replace(tPW, oid[:], rate, qty); err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
+	}
+
+	ensureErr("increased quantity", rate, qty+dcrBtcLotSize)
+	ensureErr("bad lot size", rate, qty-1)
+	ensureErr("zero rate", 0, qty)
+
+	replace := func() *trackedTrade {
+		t.Helper()
+		queueReplace()
+		corder, err := tCore.Replace(tPW, oid[:], rate+dcrBtcRateStep, qty-dcrBtcLotSize)
+		if err != nil {
+			t.Fatalf("Replace error: %v", err)
+		}
+		newID, _ := order.IDFromBytes(corder.ID)
+		replacement := dc.trades[newID]
+		if replacement == nil {
+			t.Fatalf("replacement order not tracked")
+		}
+		if replacement.replaces != tracker {
+			t.Fatalf("replacement order not linked to the replaced order")
+		}
+		replacementLO := replacement.Order.(*order.LimitOrder)
+		if replacementLO.Force != lo.Force || replacementLO.ExpiryEpoch != lo.ExpiryEpoch ||
+			replacementLO.DisplayQty != lo.DisplayQty {
+			t.Fatalf("replacement order time in force, expiry, or display quantity not copied")
+		}
+		if replacement.coinsLocked || !tracker.coinsLocked {
+			t.Fatalf("coins moved to the replacement order before the replacement was resolved")
+		}
+		return replacement
+	}
+
+	// A failed replacement leaves the replaced order in effect.
+	replacement := replace()
+	ensureErr("already replacing", rate, qty)
+	if _, err := replacement.nomatch(replacement.ID(), true); err != nil {
+		t.Fatalf("nomatch error: %v", err)
+	}
+	if replacement.metaData.Status != order.OrderStatusExecuted {
+		t.Fatalf("failed replacement order has status %s", replacement.metaData.Status)
+	}
+	if tracker.metaData.Status != order.OrderStatusBooked || !tracker.coinsLocked {
+		t.Fatalf("replaced order not in effect after a failed replacement")
+	}
+
+	// A successful replacement takes the replaced order's coins.
+	replacement = replace()
+	if _, err := replacement.nomatch(replacement.ID(), false); err != nil {
+		t.Fatalf("nomatch error: %v", err)
+	}
+	if replacement.metaData.Status != order.OrderStatusBooked {
+		t.Fatalf("replacement order has status %s", replacement.metaData.Status)
+	}
+	if tracker.metaData.Status != order.OrderStatusCanceled {
+		t.Fatalf("replaced order has status %s", tracker.metaData.Status)
+	}
+	if tracker.coinsLocked || !replacement.coinsLocked {
+		t.Fatalf("coins not moved to the replacement order")
+	}
+	if replacement.coins[coin.String()] == nil {
+		t.Fatalf("replacement order does not have the funding coin")
+	}
+
+	// The replaced order can't be replaced again.
+	ensureErr("canceled order", rate, qty)
+}
+
 func TestHandlePreimageRequest(t *testing.T) {
 	t.Run("basic checks", func(t *testing.T) {
 		rig := newTestRig()
@@ -4126,6 +4762,109 @@ func TestHandleRevokeOrderMsg(t *testing.T) {
 	}
 }
 
+func TestCheckOrderExpiry(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.Unlock(rig.crypter)
+
+	fundCoinDcrID := encode.RandomBytes(36)
+	tDcrWallet.fundingCoins = asset.Coins{&tCoin{id: fundCoinDcrID}}
+
+	walletSet, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
+
+	epoch := dc.marketEpoch(tDcrBtcMktName, time.Now())
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 2*dcrBtcLotSize, dcrBtcRateStep*10)
+	lo.Coins = []order.CoinID{fundCoinDcrID}
+	lo.Force = order.GoodTilTimeTiF
+	lo.ExpiryEpoch = epoch
+	dbOrder.MetaData.Status = order.OrderStatusBooked
+
+	mkt := dc.marketConfig(tDcrBtcMktName)
+	tracker := newTrackedTrade(dbOrder, preImg, dc, mkt.EpochLen,
+		rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, tDcrWallet.fundingCoins, rig.core.notify,
+		rig.core.formatDetails, nil, 0, 0)
+	rig.dc.trades[lo.ID()] = tracker
+
+	if tracker.pastExpiry(epoch) || !tracker.pastExpiry(epoch+1) {
+		t.Fatalf("wrong pastExpiry result")
+	}
+
+	queueStatus := func(status order.OrderStatus) {
+		rig.ws.queueResponse(msgjson.OrderStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
+			resp, _ := msgjson.NewResponse(msg.ID, []*msgjson.OrderStatus{{
+				ID:     lo.ID().Bytes(),
+				Status: uint16(status),
+			}}, nil)
+			f(resp)
+			return nil
+		})
+	}
+
+	// The order was filled in its last epoch.
+	queueStatus(order.OrderStatusExecuted)
+	tCore.checkOrderExpiry(dc, tracker)
+	if tracker.metaData.Status != order.OrderStatusBooked {
+		t.Fatalf("expected order status %v, got %v", order.OrderStatusBooked, tracker.metaData.Status)
+	}
+
+	orderNotes, feedDone := orderNoteFeed(tCore)
+	defer feedDone()
+
+	queueStatus(order.OrderStatusExpired)
+	tCore.checkOrderExpiry(dc, tracker)
+	verifyRevokeNotification(orderNotes, TopicOrderExpired, t)
+	if tracker.metaData.Status != order.OrderStatusExpired {
+		t.Fatalf("expected order status %v, got %v", order.OrderStatusExpired, tracker.metaData.Status)
+	}
+	if len(tDcrWallet.returnedCoins) != 1 {
+		t.Fatalf("funding coins not returned")
+	}
+}
+
+func TestIcebergHidden(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 5*dcrBtcLotSize, dcrBtcRateStep*10)
+	lo.DisplayQty = dcrBtcLotSize
+	lo.FillAmt = dcrBtcLotSize
+	mkt := dc.marketConfig(tDcrBtcMktName)
+	tracker := newTrackedTrade(dbOrder, preImg, dc, mkt.EpochLen,
+		rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, nil, nil, rig.core.notify,
+		rig.core.formatDetails, nil, 0, 0)
+	dc.trades[lo.ID()] = tracker
+
+	if hidden := dc.icebergHidden(lo.ID(), dcrBtcLotSize); hidden != 3*dcrBtcLotSize {
+		t.Fatalf("wrong hidden quantity %d", hidden)
+	}
+	// Visible quantity reported ahead of our record of the fills.
+	if hidden := dc.icebergHidden(lo.ID(), 5*dcrBtcLotSize); hidden != 0 {
+		t.Fatalf("expected no hidden quantity, got %d", hidden)
+	}
+	// Not an iceberg order.
+	lo.DisplayQty = 0
+	if hidden := dc.icebergHidden(lo.ID(), dcrBtcLotSize); hidden != 0 {
+		t.Fatalf("hidden quantity for a non-iceberg order")
+	}
+	// Not our order.
+	if hidden := dc.icebergHidden(order.OrderID{0x01}, dcrBtcLotSize); hidden != 0 {
+		t.Fatalf("hidden quantity for an unknown order")
+	}
+}
+
 func TestHandleRevokeMatchMsg(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
@@ -4690,6 +5429,26 @@ func TestTradeTracking(t *testing.T) {
 	if len(auth.RedeemSig) == 0 {
 		t.Fatalf("redeem ack sig not set for taker")
 	}
+
+	// The maker's replacement redemption only updates the redemption coin.
+	tracker.mtx.Lock()
+	proof.Secret = encode.RandomBytes(32)
+	tracker.mtx.Unlock()
+	replacementCoin := encode.RandomBytes(36)
+	redemption.CoinID = replacementCoin
+	redemption.Secret = proof.Secret
+	sign(tDexPriv, redemption)
+	msg, _ = msgjson.NewRequest(2, msgjson.RedemptionRoute, redemption)
+	if err = handleRedemptionRoute(tCore, rig.dc, msg); err != nil {
+		t.Fatalf("replacement redemption message error: %v", err)
+	}
+	newMatchStatus = <-rig.db.updateMatchChan
+	if newMatchStatus != order.MatchComplete {
+		t.Fatalf("wrong match status after replacement. wanted %v, got %v", order.MatchComplete, newMatchStatus)
+	}
+	if !bytes.Equal(proof.MakerRedeem, replacementCoin) {
+		t.Fatalf("replacement redemption coin ID not logged")
+	}
 	rig.db.updateMatchChan = nil
 	tBtcWallet.redeemErrChan = nil
 
@@ -5880,15 +6639,23 @@ func TestCompareServerMatches(t *testing.T) {
 }
 
 func convertMsgLimitOrder(msgOrder *msgjson.LimitOrder) *order.LimitOrder {
+	var replaces order.OrderID
+	copy(replaces[:], msgOrder.Replaces)
 	tif := order.ImmediateTiF
-	if msgOrder.TiF == msgjson.StandingOrderNum {
+	switch msgOrder.TiF {
+	case msgjson.StandingOrderNum:
 		tif = order.StandingTiF
+	case msgjson.GoodTilTimeOrderNum:
+		tif = order.GoodTilTimeTiF
 	}
 	return &order.LimitOrder{
-		P:     convertMsgPrefix(&msgOrder.Prefix, order.LimitOrderType),
-		T:     convertMsgTrade(&msgOrder.Trade),
-		Rate:  msgOrder.Rate,
-		Force: tif,
+		P:           convertMsgPrefix(&msgOrder.Prefix, order.LimitOrderType),
+		T:           convertMsgTrade(&msgOrder.Trade),
+		Rate:        msgOrder.Rate,
+		Force:       tif,
+		ExpiryEpoch: msgOrder.ExpiryEpoch,
+		DisplayQty:  msgOrder.DisplayQty,
+		Replaces:    replaces,
 	}
 }
 
@@ -6687,6 +7454,46 @@ func TestHandleTradeResumptionMsg(t *testing.T) {
 	}
 }
 
+func TestHandleConfigUpdateMsg(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+
+	notes := rig.core.NotificationFeed()
+
+	// Reconfigure dcr_btc and retire btc_eth.
+	cfg := *rig.dc.cfg
+	dcrBtc := *rig.dc.marketConfig(tDcrBtcMktName)
+	dcrBtc.LotSize *= 2
+	cfg.Markets = []*msgjson.Market{&dcrBtc}
+
+	note, _ := msgjson.NewNotification(msgjson.ConfigUpdateRoute, &cfg)
+	if err := handleConfigUpdateMsg(rig.core, rig.dc, note); err != nil {
+		t.Fatalf("handleConfigUpdateMsg error: %v", err)
+	}
+	mkt := rig.dc.marketConfig(tDcrBtcMktName)
+	if mkt == nil || mkt.LotSize != dcrBtcLotSize*2 {
+		t.Fatalf("market not reconfigured")
+	}
+	if rig.dc.marketConfig(tBtcEthMktName) != nil {
+		t.Fatalf("retired market still configured")
+	}
+
+	select {
+	case n := <-notes:
+		if n.Topic() != TopicMarketsUpdated {
+			t.Fatalf("note topic is %v, not %v", n.Topic(), TopicMarketsUpdated)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("no markets updated notification")
+	}
+
+	// Bad payload.
+	note, _ = msgjson.NewNotification(msgjson.ConfigUpdateRoute, "not a config")
+	if err := handleConfigUpdateMsg(rig.core, rig.dc, note); err == nil {
+		t.Fatalf("no error for bad payload")
+	}
+}
+
 func TestHandleNomatch(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
@@ -7341,6 +8148,166 @@ func TestPreimageSync(t *testing.T) {
 	}
 }
 
+func TestBumpFee(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	bumper := &TFeeBumper{
+		TXCWallet: tBtcWallet,
+		feeRates:  make(map[string]uint64),
+		bumpFees:  100,
+	}
+	btcWallet.Wallet = bumper
+	btcWallet.traits = asset.DetermineWalletTraits(bumper)
+
+	// Sell DCR, redeeming to the BTC wallet.
+	mkt := dc.marketConfig(tDcrBtcMktName)
+	walletSet, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	tracker := makeTradeTracker(rig, mkt, walletSet, order.StandingTiF, order.OrderStatusExecuted)
+	dc.trades[tracker.ID()] = tracker
+	oid := tracker.ID()
+
+	// Two completed taker matches, redeemed in the same unconfirmed tx.
+	addMatch := func(side order.MatchSide) *matchTracker {
+		coinID := encode.RandomBytes(36)
+		match := &matchTracker{
+			MetaMatch: db.MetaMatch{
+				UserMatch: &order.UserMatch{
+					MatchID: ordertest.RandomMatchID(),
+					Side:    side,
+					Status:  order.MatchComplete,
+					Address: "a",
+				},
+				MetaData: &db.MatchMetaData{
+					Proof: db.MatchProof{
+						Auth: db.MatchAuth{
+							MatchStamp: uint64(time.Now().UnixMilli()),
+							RedeemSig:  []byte{1},
+						},
+						PendingSpend: true,
+					},
+				},
+			},
+		}
+		if side == order.Maker {
+			match.MetaData.Proof.MakerRedeem = coinID
+		} else {
+			match.MetaData.Proof.TakerRedeem = coinID
+		}
+		tracker.matches[match.MatchID] = match
+		bumper.feeRates[dex.Bytes(coinID).String()] = 10
+		return match
+	}
+	m1, m2 := addMatch(order.Taker), addMatch(order.Taker)
+
+	tick := func() {
+		t.Helper()
+		if _, err := tCore.tick(tracker); err != nil {
+			t.Fatalf("tick error: %v", err)
+		}
+	}
+	checkCoins := func(tag string, wantRate uint64) {
+		t.Helper()
+		for _, m := range []*matchTracker{m1, m2} {
+			feeRate, found := bumper.feeRates[dex.Bytes(m.MetaData.Proof.TakerRedeem).String()]
+			if !found || feeRate != wantRate {
+				t.Fatalf("%s: redeem coin not updated to replacement with fee rate %d", tag, wantRate)
+			}
+		}
+	}
+
+	// The completed matches are kept active until the redemption is mined.
+	if !tracker.isActive() {
+		t.Fatalf("trade with pending redemption not active")
+	}
+
+	// Not bumped while the redeemed contract's lock time is not near.
+	atomic.StoreUint64(&tracker.redeemFeeSuggestion, 30)
+	tick()
+	if bumper.bumps != 0 {
+		t.Fatalf("fee bumped before lock time is near")
+	}
+
+	// Bumped to the fee suggestion as the lock time approaches.
+	matchStamp := uint64(time.Now().Add(-tracker.lockTimeMaker).UnixMilli())
+	m1.MetaData.Proof.Auth.MatchStamp = matchStamp
+	m2.MetaData.Proof.Auth.MatchStamp = matchStamp
+	tick()
+	if bumper.bumps != 1 {
+		t.Fatalf("expected 1 fee bump, got %d", bumper.bumps)
+	}
+	checkCoins("auto bump", 30)
+	if tracker.metaData.RedemptionFeesPaid != bumper.bumpFees {
+		t.Fatalf("redemption fees not updated")
+	}
+
+	// Already paying the fee suggestion.
+	tick()
+	if bumper.bumps != 1 {
+		t.Fatalf("bumped fee paying the fee suggestion")
+	}
+
+	// Bump through Core.
+	if err := tCore.BumpFee(tPW, oid[:], 20); err == nil {
+		t.Fatalf("no error for lower fee rate")
+	}
+	if err := tCore.BumpFee(tPW, oid[:], 40); err != nil {
+		t.Fatalf("BumpFee error: %v", err)
+	}
+	if bumper.bumps != 2 {
+		t.Fatalf("expected 2 fee bumps, got %d", bumper.bumps)
+	}
+	checkCoins("BumpFee", 40)
+
+	bumper.bumpErr = tErr
+	if err := tCore.BumpFee(tPW, oid[:], 50); err == nil {
+		t.Fatalf("no error for BumpFee error")
+	}
+	bumper.bumpErr = nil
+
+	// Once the redemption is mined, the trade can be retired.
+	bumper.feeRates = nil
+	tick()
+	if m1.MetaData.Proof.PendingSpend || m2.MetaData.Proof.PendingSpend {
+		t.Fatalf("mined redemption still pending")
+	}
+	if tracker.isActive() {
+		t.Fatalf("trade still active after redemption mined")
+	}
+	if err := tCore.BumpFee(tPW, oid[:], 50); err == nil {
+		t.Fatalf("no error with nothing to bump")
+	}
+
+	// The server is informed of the maker's replacement redemption.
+	bumper.feeRates = make(map[string]uint64)
+	m3 := addMatch(order.Maker)
+	m3.MetaData.Proof.Secret = encode.RandomBytes(32)
+	redeemCoins := make(chan dex.Bytes, 1)
+	rig.ws.queueResponse(msgjson.RedeemRoute, func(msg *msgjson.Message, f msgFunc) error {
+		redeem := new(msgjson.Redeem)
+		msg.Unmarshal(redeem)
+		redeemCoins <- redeem.CoinID
+		return redeemAcker(msg, f)
+	})
+	if err := tCore.BumpFee(tPW, oid[:], 60); err != nil {
+		t.Fatalf("BumpFee error for maker redemption: %v", err)
+	}
+	select {
+	case coinID := <-redeemCoins:
+		if !bytes.Equal(coinID, m3.MetaData.Proof.MakerRedeem) {
+			t.Fatalf("server not sent the replacement redemption coin")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("server not informed of the replacement redemption")
+	}
+}
+
 func TestAccelerateOrder(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
@@ -8751,10 +9718,29 @@ func TestPreOrder(t *testing.T) {
 
 	// Limit orders have no such restriction.
 	form.IsLimit = true
-	_, err = tCore.PreOrder(form)
+	est, err := tCore.PreOrder(form)
 	if err != nil {
 		t.Fatalf("PreOrder limit sell error: %v", err)
 	}
+	if est.OracleCheck != nil {
+		t.Fatalf("oracle check without an oracle")
+	}
+
+	// With a price oracle, the limit rate is compared to the oracle price.
+	baseUI, quoteUI := tUTXOAssetA.UnitInfo, tUTXOAssetB.UnitInfo
+	tUTXOAssetA.UnitInfo.Conventional.ConversionFactor = 1e8
+	tUTXOAssetB.UnitInfo.Conventional.ConversionFactor = 1e8
+	tCore.oracle = &tPriceOracle{price: calc.ConventionalRateAlt(form.Rate, 1e8, 1e8) * 2}
+	tCore.cfg.OracleThreshold = defaultOracleThreshold
+	est, err = tCore.PreOrder(form)
+	if err != nil {
+		t.Fatalf("PreOrder limit sell with oracle error: %v", err)
+	}
+	if est.OracleCheck == nil || !est.OracleCheck.Warning || math.Abs(est.OracleCheck.Deviation+0.5) > 1e-9 {
+		t.Fatalf("wrong oracle check %+v", est.OracleCheck)
+	}
+	tCore.oracle = nil
+	tUTXOAssetA.UnitInfo, tUTXOAssetB.UnitInfo = baseUI, quoteUI
 
 	var newBaseFeeRate uint64 = 55
 	var newQuoteFeeRate uint64 = 65
@@ -8899,7 +9885,7 @@ func TestCredentialHandling(t *testing.T) {
 	tCore.newCrypter = encrypt.NewCrypter
 	tCore.reCrypter = encrypt.Deserialize
 
-	err := tCore.InitializeClient(tPW, nil)
+	err := tCore.InitializeClient(tPW, "")
 	if err != nil {
 		t.Fatalf("InitializeClient error: %v", err)
 	}
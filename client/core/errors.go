@@ -48,6 +48,12 @@ const (
 	createWalletErr
 	activeOrdersErr
 	newAddrErr
+	bondAmtErr
+	bondTimeErr
+	bondPostErr
+	unknownTransactionErr
+	coinControlErr
+	orderSubmitErr
 )
 
 // Error is an error code and a wrapped error.
@@ -0,0 +1,273 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/order"
+)
+
+// ExportTrades writes an accounting record of each of the user's matches on a
+// market to w, in CSV or JSON format. See TradeRecord for a description of the
+// fields.
+func (c *Core) ExportTrades(w io.Writer, form *ExportTradesForm) error {
+	records, err := c.tradeRecords(form)
+	if err != nil {
+		return err
+	}
+	switch form.Format {
+	case ExportFormatCSV, "":
+		return writeTradeRecordsCSV(w, records)
+	case ExportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	return newError(orderParamsErr, "unknown export format %q", form.Format)
+}
+
+// tradeRecords builds the TradeRecords for the matches selected by the form.
+// Profit and loss is calculated using all of the market's settled matches, so
+// that sells within the time range use the cost of earlier buys. The swap and
+// redemption fees of a match are added to the cost of a buy, and deducted from
+// the proceeds of a sell.
+func (c *Core) tradeRecords(form *ExportTradesForm) ([]*TradeRecord, error) {
+	if form.Until != 0 && form.Until <= form.Since {
+		return nil, newError(orderParamsErr, "export end time must be after the start time")
+	}
+	filter := &db.OrderFilter{
+		Assets: []uint32{form.Base, form.Quote},
+	}
+	if form.Host != "" {
+		filter.Hosts = []string{form.Host}
+	}
+	ords, err := c.db.Orders(filter)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving orders: %w", err)
+	}
+
+	mktID := marketName(form.Base, form.Quote)
+	var records []*TradeRecord
+	var settled []bool
+	var fees []uint64 // swap and redeem fees in units of the quote asset
+	for _, mOrd := range ords {
+		ord := mOrd.Order
+		if ord.Base() != form.Base || ord.Quote() != form.Quote {
+			continue
+		}
+		matches, err := c.db.MatchesForOrder(ord.ID(), true)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving matches for order %s: %w", ord.ID(), err)
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		// Allocate the order's fees to the matches that paid them.
+		swapQtys := make([]uint64, len(matches))
+		redeemQtys := make([]uint64, len(matches))
+		refundQtys := make([]uint64, len(matches))
+		for i, match := range matches {
+			proof := &match.MetaData.Proof
+			swapCoin, redeemCoin := proof.MakerSwap, proof.MakerRedeem
+			if match.Side == order.Taker {
+				swapCoin, redeemCoin = proof.TakerSwap, proof.TakerRedeem
+			}
+			if len(swapCoin) > 0 {
+				swapQtys[i] = match.Quantity
+			}
+			if len(redeemCoin) > 0 {
+				redeemQtys[i] = match.Quantity
+			}
+			if len(proof.RefundCoin) > 0 {
+				refundQtys[i] = match.Quantity
+			}
+		}
+		md := mOrd.MetaData
+		swapFees := allocateFees(md.SwapFeesPaid, swapQtys)
+		redeemFees := allocateFees(md.RedemptionFeesPaid, redeemQtys)
+		refundFees := allocateFees(md.RefundFeesPaid, refundQtys)
+
+		oid := ord.ID()
+		sell := ord.Trade().Sell
+		// The swap is paid from the asset sold, and the redemption to the
+		// asset bought.
+		fromAsset, toAsset := form.Quote, form.Base
+		if sell {
+			fromAsset, toAsset = form.Base, form.Quote
+		}
+		for i, match := range matches {
+			proof := &match.MetaData.Proof
+			counterpartySide := order.Taker
+			if match.Side == order.Taker {
+				counterpartySide = order.Maker
+			}
+			refunded := len(proof.RefundCoin) > 0
+			records = append(records, &TradeRecord{
+				Stamp:            match.MetaData.Stamp,
+				Host:             md.Host,
+				Market:           mktID,
+				OrderID:          oid[:],
+				MatchID:          match.MatchID[:],
+				Sell:             sell,
+				Side:             match.Side.String(),
+				CounterpartySide: counterpartySide.String(),
+				Status:           match.Status.String(),
+				Refunded:         refunded,
+				Qty:              match.Quantity,
+				Rate:             match.Rate,
+				QuoteQty:         calc.BaseToQuote(match.Rate, match.Quantity),
+				SwapFees:         swapFees[i],
+				RedeemFees:       redeemFees[i],
+				RefundFees:       refundFees[i],
+			})
+			// A match is settled once the maker has redeemed. The taker may
+			// not have redeemed yet, but will.
+			settled = append(settled, !refunded && match.Status >= order.MakerRedeemed)
+			fees = append(fees, quoteFeeValue(swapFees[i], fromAsset, form.Base, form.Quote, match.Rate)+
+				quoteFeeValue(redeemFees[i], toAsset, form.Base, form.Quote, match.Rate))
+		}
+	}
+
+	idxs := make([]int, len(records))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	sort.Slice(idxs, func(i, j int) bool {
+		ri, rj := records[idxs[i]], records[idxs[j]]
+		if ri.Stamp != rj.Stamp {
+			return ri.Stamp < rj.Stamp
+		}
+		return bytes.Compare(ri.MatchID, rj.MatchID) < 0
+	})
+
+	// First-in-first-out cost basis of the base asset.
+	type lot struct {
+		qty, cost uint64
+	}
+	var lots []*lot
+	selected := make([]*TradeRecord, 0, len(records))
+	for _, i := range idxs {
+		r := records[i]
+		if settled[i] {
+			if r.Sell {
+				r.Proceeds = r.QuoteQty - fees[i]
+				if fees[i] > r.QuoteQty {
+					r.Proceeds = 0
+				}
+				remain := r.Qty
+				for remain > 0 && len(lots) > 0 {
+					l := lots[0]
+					if l.qty <= remain {
+						r.CostBasis += l.cost
+						remain -= l.qty
+						lots = lots[1:]
+						continue
+					}
+					cost := mulDiv(l.cost, remain, l.qty)
+					r.CostBasis += cost
+					l.cost -= cost
+					l.qty -= remain
+					remain = 0
+				}
+				// The proceeds from quantity with no known cost basis are not
+				// counted as profit.
+				r.UnknownBasisQty = remain
+				known := mulDiv(r.Proceeds, r.Qty-remain, r.Qty)
+				r.ProfitLoss = int64(known) - int64(r.CostBasis)
+			} else {
+				lots = append(lots, &lot{qty: r.Qty, cost: r.QuoteQty + fees[i]})
+			}
+		}
+		if r.Stamp < form.Since || (form.Until != 0 && r.Stamp >= form.Until) {
+			continue
+		}
+		selected = append(selected, r)
+	}
+
+	return selected, nil
+}
+
+// quoteFeeValue is the value, in units of the quote asset, of a fee paid for a
+// transaction of the specified asset. A token's fees are paid in its parent
+// asset. A fee paid in the base asset is converted at the match rate. A fee
+// paid in any other asset can't be valued, and is zero.
+func quoteFeeValue(fee uint64, assetID, base, quote uint32, rate uint64) uint64 {
+	feeAsset := assetID
+	if token := asset.TokenInfo(assetID); token != nil {
+		feeAsset = token.ParentID
+	}
+	switch feeAsset {
+	case quote:
+		return fee
+	case base:
+		return calc.BaseToQuote(rate, fee)
+	}
+	return 0
+}
+
+// allocateFees splits the fees between the matches in proportion to their
+// quantities. Any remainder from integer division goes to the last match with
+// a non-zero quantity.
+func allocateFees(fees uint64, qtys []uint64) []uint64 {
+	allocs := make([]uint64, len(qtys))
+	var total uint64
+	last := -1
+	for i, qty := range qtys {
+		total += qty
+		if qty > 0 {
+			last = i
+		}
+	}
+	if fees == 0 || total == 0 {
+		return allocs
+	}
+	var allocated uint64
+	for i, qty := range qtys {
+		allocs[i] = mulDiv(fees, qty, total)
+		allocated += allocs[i]
+	}
+	allocs[last] += fees - allocated
+	return allocs
+}
+
+// mulDiv computes a * b / c without overflow.
+func mulDiv(a, b, c uint64) uint64 {
+	r := new(big.Int).Mul(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b))
+	return r.Div(r, new(big.Int).SetUint64(c)).Uint64()
+}
+
+// writeTradeRecordsCSV writes the TradeRecords as CSV with a header row.
+func writeTradeRecordsCSV(w io.Writer, records []*TradeRecord) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"stamp", "host", "market", "orderID", "matchID", "sell", "side",
+		"counterpartySide", "status", "refunded", "qty", "rate", "quoteQty", "swapFees",
+		"redeemFees", "refundFees", "proceeds", "costBasis", "unknownBasisQty", "profitLoss"})
+	if err != nil {
+		return err
+	}
+	u := func(v uint64) string { return strconv.FormatUint(v, 10) }
+	for _, r := range records {
+		err = cw.Write([]string{u(r.Stamp), r.Host, r.Market, r.OrderID.String(), r.MatchID.String(),
+			strconv.FormatBool(r.Sell), r.Side, r.CounterpartySide, r.Status, strconv.FormatBool(r.Refunded),
+			u(r.Qty), u(r.Rate), u(r.QuoteQty), u(r.SwapFees), u(r.RedeemFees), u(r.RefundFees),
+			u(r.Proceeds), u(r.CostBasis), u(r.UnknownBasisQty), strconv.FormatInt(r.ProfitLoss, 10)})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
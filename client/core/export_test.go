@@ -0,0 +1,198 @@
+//go:build !harness
+
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/order"
+)
+
+func TestExportTrades(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	lotSize := dcrBtcLotSize
+	rate1, rate2 := dcrBtcRateStep*1000, dcrBtcRateStep*1500
+
+	newMatch := func(lo *order.LimitOrder, qty, rate, stamp uint64, side order.MatchSide, status order.MatchStatus) *db.MetaMatch {
+		var mid order.MatchID
+		copy(mid[:], encode.RandomBytes(32))
+		m := &db.MetaMatch{
+			UserMatch: &order.UserMatch{
+				OrderID:  lo.ID(),
+				MatchID:  mid,
+				Quantity: qty,
+				Rate:     rate,
+				Side:     side,
+				Status:   status,
+			},
+			MetaData: &db.MatchMetaData{
+				Base:  lo.Base(),
+				Quote: lo.Quote(),
+				Stamp: stamp,
+			},
+		}
+		if status >= order.MakerSwapCast {
+			if side == order.Maker {
+				m.MetaData.Proof.MakerSwap = encode.RandomBytes(36)
+			} else {
+				m.MetaData.Proof.TakerSwap = encode.RandomBytes(36)
+			}
+		}
+		if status >= order.MatchComplete {
+			if side == order.Maker {
+				m.MetaData.Proof.MakerRedeem = encode.RandomBytes(36)
+			} else {
+				m.MetaData.Proof.TakerRedeem = encode.RandomBytes(36)
+			}
+		}
+		return m
+	}
+
+	// A buy of 3 lots in two matches, then a sell of 4 lots in two matches,
+	// one of which is refunded, then a sell of 2 lots, only 1 of which has a
+	// known cost basis.
+	buy, buyOrder, _, _ := makeLimitOrder(dc, false, lotSize*3, rate1)
+	buyOrder.MetaData.SwapFeesPaid = 301
+	buyOrder.MetaData.RedemptionFeesPaid = 30
+	sell, sellOrder, _, _ := makeLimitOrder(dc, true, lotSize*4, rate2)
+	sellOrder.MetaData.SwapFeesPaid = 40
+	sellOrder.MetaData.RedemptionFeesPaid = 20
+	sellOrder.MetaData.RefundFeesPaid = 5
+	sell2, sellOrder2, _, _ := makeLimitOrder(dc, true, lotSize*2, rate2)
+
+	refunded := newMatch(sell, lotSize*2, rate2, 4000, order.Maker, order.MakerSwapCast)
+	refunded.MetaData.Proof.RefundCoin = encode.RandomBytes(36)
+	rig.db.orders = []*db.MetaOrder{buyOrder, sellOrder, sellOrder2}
+	rig.db.matchesByOID = map[order.OrderID][]*db.MetaMatch{
+		buy.ID(): {
+			newMatch(buy, lotSize, rate1, 1000, order.Taker, order.MatchComplete),
+			newMatch(buy, lotSize*2, rate1, 2000, order.Maker, order.MatchComplete),
+		},
+		sell.ID(): {
+			newMatch(sell, lotSize*2, rate2, 3000, order.Taker, order.MatchComplete),
+			refunded,
+		},
+		sell2.ID(): {
+			newMatch(sell2, lotSize*2, rate2, 5000, order.Maker, order.MatchComplete),
+		},
+	}
+
+	form := &ExportTradesForm{
+		Host:  tDexHost,
+		Base:  tUTXOAssetA.ID,
+		Quote: tUTXOAssetB.ID,
+	}
+	records, err := tCore.tradeRecords(form)
+	if err != nil {
+		t.Fatalf("tradeRecords error: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(records))
+	}
+	for i, r := range records {
+		if r.Stamp != uint64(i+1)*1000 {
+			t.Fatalf("record %d out of order", i)
+		}
+	}
+
+	// Fees are split by quantity, with the remainder to the last match.
+	if records[0].SwapFees != 100 || records[1].SwapFees != 201 || records[0].RedeemFees != 10 ||
+		records[1].RedeemFees != 20 {
+		t.Fatalf("wrong buy fees: %+v, %+v", records[0], records[1])
+	}
+	if records[2].SwapFees != 20 || records[3].SwapFees != 20 || records[2].RedeemFees != 20 ||
+		records[3].RedeemFees != 0 || records[2].RefundFees != 0 || records[3].RefundFees != 5 {
+		t.Fatalf("wrong sell fees: %+v, %+v", records[2], records[3])
+	}
+	if records[0].Side != "Taker" || records[0].CounterpartySide != "Maker" {
+		t.Fatalf("wrong sides: %s, %s", records[0].Side, records[0].CounterpartySide)
+	}
+
+	// The settled sell of 2 lots uses the cost of the first buy and half of
+	// the second. The buys' swap fees are in the quote asset, and their redeem
+	// fees are in the base asset. The sell's swap fees are in the base asset,
+	// and its redeem fees are in the quote asset.
+	buyCost1 := calc.BaseToQuote(rate1, lotSize) + 100 + calc.BaseToQuote(rate1, 10)
+	buyCost2 := calc.BaseToQuote(rate1, lotSize*2) + 201 + calc.BaseToQuote(rate1, 20)
+	wantBasis := buyCost1 + buyCost2/2
+	wantProceeds := calc.BaseToQuote(rate2, lotSize*2) - calc.BaseToQuote(rate2, 20) - 20
+	wantPL := int64(wantProceeds) - int64(wantBasis)
+	if records[2].CostBasis != wantBasis || records[2].Proceeds != wantProceeds ||
+		records[2].ProfitLoss != wantPL || records[2].UnknownBasisQty != 0 {
+		t.Fatalf("wrong cost basis, proceeds, or P&L. wanted %d, %d, %d, got %d, %d, %d", wantBasis,
+			wantProceeds, wantPL, records[2].CostBasis, records[2].Proceeds, records[2].ProfitLoss)
+	}
+	if !records[3].Refunded || records[3].CostBasis != 0 || records[3].ProfitLoss != 0 {
+		t.Fatalf("refunded match has cost basis or P&L: %+v", records[3])
+	}
+
+	// Only 1 lot of the last sell has a cost basis, the rest of the second
+	// buy. The proceeds from the other lot are not counted as profit.
+	wantBasis2 := buyCost2 - buyCost2/2
+	wantProceeds2 := calc.BaseToQuote(rate2, lotSize*2)
+	wantPL2 := int64(wantProceeds2/2) - int64(wantBasis2)
+	if records[4].CostBasis != wantBasis2 || records[4].Proceeds != wantProceeds2 ||
+		records[4].UnknownBasisQty != lotSize || records[4].ProfitLoss != wantPL2 {
+		t.Fatalf("wrong unknown basis record: %+v", records[4])
+	}
+
+	// A time range only selects some of the records, but the cost basis still
+	// includes the earlier buys.
+	form.Since, form.Until = 2500, 4000
+	records, err = tCore.tradeRecords(form)
+	if err != nil {
+		t.Fatalf("tradeRecords error: %v", err)
+	}
+	if len(records) != 1 || records[0].CostBasis != wantBasis {
+		t.Fatalf("wrong records for time range: %+v", records)
+	}
+	form.Until = form.Since
+	if _, err = tCore.tradeRecords(form); err == nil {
+		t.Fatalf("no error for empty time range")
+	}
+	form.Since, form.Until = 0, 0
+
+	// CSV
+	var buf bytes.Buffer
+	if err = tCore.ExportTrades(&buf, form); err != nil {
+		t.Fatalf("ExportTrades CSV error: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("error reading CSV: %v", err)
+	}
+	if len(rows) != 6 || rows[0][0] != "stamp" || rows[3][17] != strconv.FormatUint(wantBasis, 10) ||
+		rows[5][18] != strconv.FormatUint(lotSize, 10) {
+		t.Fatalf("wrong CSV: %v", rows)
+	}
+
+	// JSON
+	buf.Reset()
+	form.Format = ExportFormatJSON
+	if err = tCore.ExportTrades(&buf, form); err != nil {
+		t.Fatalf("ExportTrades JSON error: %v", err)
+	}
+	var decoded []*TradeRecord
+	if err = json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("error decoding JSON: %v", err)
+	}
+	if len(decoded) != 5 || decoded[2].ProfitLoss != wantPL || decoded[4].UnknownBasisQty != lotSize {
+		t.Fatalf("wrong JSON records")
+	}
+
+	form.Format = "xml"
+	if err = tCore.ExportTrades(&buf, form); err == nil {
+		t.Fatalf("no error for unknown format")
+	}
+}
@@ -57,7 +57,7 @@ func (ord *OrderReader) ToID() uint32 {
 // Cancelable will be true for standing limit orders in status epoch or booked.
 func (ord *OrderReader) Cancelable() bool {
 	return ord.Type == order.LimitOrderType &&
-		ord.TimeInForce == order.StandingTiF &&
+		ord.TimeInForce != order.ImmediateTiF &&
 		ord.Status <= order.OrderStatusBooked
 }
 
@@ -66,8 +66,11 @@ func (ord *OrderReader) TypeString() string {
 	s := "market"
 	if ord.Type == order.LimitOrderType {
 		s = "limit"
-		if ord.TimeInForce == order.ImmediateTiF {
+		switch ord.TimeInForce {
+		case order.ImmediateTiF:
 			s += " (i)"
+		case order.GoodTilTimeTiF:
+			s += " (gtt)"
 		}
 	}
 	if ord.Sell {
@@ -267,6 +270,11 @@ func (ord *OrderReader) StatusString() string {
 			return "revoked/settling"
 		}
 		return "revoked"
+	case order.OrderStatusExpired:
+		if isLive {
+			return "expired/settling"
+		}
+		return "expired"
 	}
 	return "unknown"
 }
@@ -39,6 +39,36 @@ var enUS = map[Topic]*translation{
 		subject:  "Account unlock error",
 		template: "error unlocking account for %s: %v",
 	},
+	// [confs, bond coin id, asset, host]
+	TopicBondConfirming: {
+		subject:  "Confirming bond",
+		template: "Waiting for %d confirmations to post bond %v (%s) to %s",
+	},
+	// [bond coin id, asset, host, tier]
+	TopicBondConfirmed: {
+		subject:  "Bond confirmed",
+		template: "New bond %v (%s) accepted by %s. New tier = %d.",
+	},
+	// [host, error]
+	TopicBondPostError: {
+		subject:  "Bond post error",
+		template: "Error encountered while posting a bond to %s: %v",
+	},
+	// [bond coin id, asset, host, tier]
+	TopicBondExpired: {
+		subject:  "Bond expired",
+		template: "Bond %v (%s) at %s has expired. New tier = %d.",
+	},
+	// [bond coin id, asset, host, refund coin id]
+	TopicBondRefunded: {
+		subject:  "Bond refunded",
+		template: "Bond %v (%s) for %s refunded in %v",
+	},
+	// [bond coin id, asset, host, error]
+	TopicBondRefundError: {
+		subject:  "Bond refund error",
+		template: "Error refunding bond %v (%s) for %s: %v",
+	},
 	// [host]
 	TopicFeeCoinError: {
 		subject:  "Fee coin error",
@@ -94,6 +124,16 @@ var enUS = map[Topic]*translation{
 		subject:  "Order placed",
 		template: "%sing %s %s, rate = %s (%s)",
 	},
+	// [stop order token, market, rate string, order token]
+	TopicStopOrderTriggered: {
+		subject:  "Stop order triggered",
+		template: "Stop order %s on %s triggered at rate %s. Placed order %s.",
+	},
+	// [stop order token, market, rate string, error]
+	TopicStopOrderFailed: {
+		subject:  "Stop order failed",
+		template: "Stop order %s on %s triggered at rate %s, but the order could not be placed: %v",
+	},
 	// [missing count, token, host]
 	TopicMissingMatches: {
 		subject:  "Missing matches",
@@ -139,6 +179,16 @@ var enUS = map[Topic]*translation{
 		subject:  "Order canceled",
 		template: "%s order on %s-%s at %s has been canceled (%s)",
 	},
+	// [old token, new token]
+	TopicOrderReplaced: {
+		subject:  "Order replaced",
+		template: "Order %s has been replaced by order %s",
+	},
+	// [new token, old token]
+	TopicMissedReplace: {
+		subject:  "Missed replace",
+		template: "Replacement order %s did not take effect because order %s was matched first.",
+	},
 	// [capitalized sell string, base ticker, quote ticker, fill percent, token]
 	TopicMatchesMade: {
 		subject:  "Matches made",
@@ -199,6 +249,11 @@ var enUS = map[Topic]*translation{
 		subject:  "Order auto-revoked",
 		template: "Order %s on market %s at %s revoked due to market suspension",
 	},
+	// [token, market name, host]
+	TopicOrderExpired: {
+		subject:  "Order expired",
+		template: "Good-til-time order %s on market %s at %s has expired and was removed from the book",
+	},
 	// [ticker, coin ID, match]
 	TopicMatchRecovered: {
 		subject:  "Match recovered",
@@ -280,6 +335,11 @@ var enUS = map[Topic]*translation{
 		template: "Market %s at %s has resumed trading at epoch %d",
 	},
 	// [host]
+	TopicMarketsUpdated: {
+		subject:  "Markets updated",
+		template: "The market configuration at %s has been updated",
+	},
+	// [host]
 	TopicUpgradeNeeded: {
 		subject:  "Upgrade needed",
 		template: "You may need to update your client to trade at %s.",
@@ -0,0 +1,124 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+const (
+	// appSeedLen is the length of the app seed.
+	appSeedLen = 64
+	// mnemonicWordBits is the number of bits encoded by each word of a
+	// mnemonic seed.
+	mnemonicWordBits = 11
+	// mnemonicChecksumBits is the number of checksum bits in a mnemonic seed.
+	mnemonicChecksumBits = mnemonicWordBits
+	// mnemonicPayloadLen is the length of the data encoded by a mnemonic seed,
+	// the app seed followed by the 2-byte seed birthday.
+	mnemonicPayloadLen = appSeedLen + 2
+	// mnemonicWords is the number of words in a mnemonic seed.
+	mnemonicWords = (mnemonicPayloadLen*8 + mnemonicChecksumBits) / mnemonicWordBits
+
+	secondsPerDay = 86400
+)
+
+var mnemonicWordIndex = func() map[string]int {
+	m := make(map[string]int, len(wordlists.English))
+	for i, w := range wordlists.English {
+		m[w] = i
+	}
+	return m
+}()
+
+// encodeSeedMnemonic encodes the app seed and the time the seed was generated
+// as a mnemonic of words from the BIP-39 English word list. The seed is
+// followed by the seed birthday, in days since the UNIX epoch, and an 11-bit
+// checksum, which is the first bits of the SHA-256 hash of the seed and
+// birthday. Each word encodes 11 bits. A zero generation time indicates that
+// the seed birthday is unknown.
+func encodeSeedMnemonic(seed []byte, genTime uint64) (string, error) {
+	if len(seed) != appSeedLen {
+		return "", fmt.Errorf("invalid seed length %d. expected %d", len(seed), appSeedLen)
+	}
+	days := genTime / secondsPerDay
+	if days > 0xffff {
+		return "", fmt.Errorf("seed generation time %d out of range", genTime)
+	}
+	payload := make([]byte, mnemonicPayloadLen)
+	copy(payload, seed)
+	binary.BigEndian.PutUint16(payload[appSeedLen:], uint16(days))
+
+	x := new(big.Int).SetBytes(payload)
+	x.Lsh(x, mnemonicChecksumBits)
+	x.Or(x, big.NewInt(int64(mnemonicChecksum(payload))))
+
+	words := make([]string, mnemonicWords)
+	mask := big.NewInt(1<<mnemonicWordBits - 1)
+	idx := new(big.Int)
+	for i := mnemonicWords - 1; i >= 0; i-- {
+		words[i] = wordlists.English[idx.And(x, mask).Int64()]
+		x.Rsh(x, mnemonicWordBits)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// decodeSeed decodes a mnemonic seed created with encodeSeedMnemonic, or a
+// hex-encoded seed. The seed generation time is rounded down to the start of
+// the day. The generation time is zero for hex-encoded seeds and mnemonics
+// without a birthday.
+func decodeSeed(s string) (seed []byte, genTime uint64, err error) {
+	s = strings.TrimSpace(s)
+	if len(s) == appSeedLen*2 {
+		if seed, err = hex.DecodeString(s); err == nil {
+			return seed, 0, nil
+		}
+	}
+
+	words := strings.Fields(strings.ToLower(s))
+	if len(words) != mnemonicWords {
+		return nil, 0, fmt.Errorf("invalid seed. expected a %d-word mnemonic or %d hex characters",
+			mnemonicWords, appSeedLen*2)
+	}
+	x := new(big.Int)
+	for i, w := range words {
+		idx, found := mnemonicWordIndex[w]
+		if !found {
+			return nil, 0, fmt.Errorf("invalid seed word %q at position %d", w, i+1)
+		}
+		x.Lsh(x, mnemonicWordBits)
+		x.Or(x, big.NewInt(int64(idx)))
+	}
+	checksum := uint16(new(big.Int).And(x, big.NewInt(1<<mnemonicChecksumBits-1)).Uint64())
+	payload := x.Rsh(x, mnemonicChecksumBits).FillBytes(make([]byte, mnemonicPayloadLen))
+	if checksum != mnemonicChecksum(payload) {
+		return nil, 0, errors.New("invalid seed checksum. check the words for typos")
+	}
+	days := binary.BigEndian.Uint16(payload[appSeedLen:])
+	return payload[:appSeedLen], uint64(days) * secondsPerDay, nil
+}
+
+// mnemonicChecksum is the first mnemonicChecksumBits bits of the SHA-256 hash
+// of the payload.
+func mnemonicChecksum(payload []byte) uint16 {
+	h := sha256.Sum256(payload)
+	return binary.BigEndian.Uint16(h[:2]) >> (16 - mnemonicChecksumBits)
+}
+
+// seedGenTimeString is a human-readable seed generation time for logging.
+func seedGenTimeString(genTime uint64) string {
+	if genTime == 0 {
+		return "unknown"
+	}
+	return time.Unix(int64(genTime), 0).UTC().Format("2006-01-02")
+}
@@ -0,0 +1,77 @@
+//go:build !harness
+
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSeedMnemonic(t *testing.T) {
+	// A fixed seed, since an 11-bit checksum could miss the swapped words for
+	// a random seed.
+	seed := make([]byte, appSeedLen)
+	for i := range seed {
+		seed[i] = byte(i * 7)
+	}
+	const genTime = 1_650_000_000
+	const genDay = genTime / secondsPerDay * secondsPerDay
+
+	mnemonic, err := encodeSeedMnemonic(seed, genTime)
+	if err != nil {
+		t.Fatalf("encodeSeedMnemonic error: %v", err)
+	}
+	words := strings.Fields(mnemonic)
+	if len(words) != mnemonicWords {
+		t.Fatalf("expected %d words, got %d", mnemonicWords, len(words))
+	}
+
+	checkDecode := func(tag, s string, wantGenTime uint64) {
+		t.Helper()
+		reSeed, reGenTime, err := decodeSeed(s)
+		if err != nil {
+			t.Fatalf("%s: decodeSeed error: %v", tag, err)
+		}
+		if !bytes.Equal(reSeed, seed) {
+			t.Fatalf("%s: wrong seed", tag)
+		}
+		if reGenTime != wantGenTime {
+			t.Fatalf("%s: wanted generation time %d, got %d", tag, wantGenTime, reGenTime)
+		}
+	}
+	checkDecode("mnemonic", mnemonic, genDay)
+	checkDecode("mnemonic with extra whitespace and capitals",
+		"  "+strings.ToUpper(words[0])+"\n\t"+strings.Join(words[1:], "  ")+"\n", genDay)
+	checkDecode("legacy hex", hex.EncodeToString(seed), 0)
+
+	// Unknown generation time.
+	noBirthday, err := encodeSeedMnemonic(seed, 0)
+	if err != nil {
+		t.Fatalf("encodeSeedMnemonic error for unknown generation time: %v", err)
+	}
+	checkDecode("unknown generation time", noBirthday, 0)
+
+	checkErr := func(tag, s string) {
+		t.Helper()
+		if _, _, err := decodeSeed(s); err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
+	}
+	checkErr("missing word", strings.Join(words[1:], " "))
+	checkErr("unknown word", strings.Join(append([]string{"dcrdex"}, words[1:]...), " "))
+	swapped := append([]string{}, words...)
+	for i := 1; i < len(swapped); i++ {
+		if swapped[i] != swapped[0] {
+			swapped[0], swapped[i] = swapped[i], swapped[0]
+			break
+		}
+	}
+	checkErr("swapped words", strings.Join(swapped, " "))
+	checkErr("short hex", hex.EncodeToString(seed[1:]))
+
+	if _, err := encodeSeedMnemonic(seed[1:], genTime); err == nil {
+		t.Fatalf("no error for short seed")
+	}
+}
@@ -0,0 +1,354 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/encrypt"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+)
+
+// maxMultiTradeOrders is the most orders that can be placed with a single
+// MultiTrade. This matches the server's limit.
+const maxMultiTradeOrders = 50
+
+// MultiTrade places a batch of standing limit orders on one side of a market.
+// The orders are funded together, using the wallet's asset.MultiOrderFunder
+// implementation if available, so that a wallet can split coins for all of
+// the orders at once. The orders are submitted to the server in a single
+// request and are either all accepted into the same epoch, or all rejected.
+func (c *Core) MultiTrade(pw []byte, form *MultiTradeForm) ([]*Order, error) {
+	crypter, err := c.encryptionKey(pw)
+	if err != nil {
+		return nil, fmt.Errorf("MultiTrade password error: %w", err)
+	}
+	defer crypter.Close()
+	return c.multiTrade(form, crypter)
+}
+
+// MultiTradeUnlocked is like MultiTrade, but does not require the app
+// password. The wallets for the market must already be unlocked, e.g. with
+// OpenWallet. This allows an automated trader to place orders without holding
+// the password.
+func (c *Core) MultiTradeUnlocked(form *MultiTradeForm) ([]*Order, error) {
+	return c.multiTrade(form, nil)
+}
+
+// multiTrade places the orders of a MultiTradeForm. If the crypter is nil, the
+// wallets must already be unlocked.
+func (c *Core) multiTrade(form *MultiTradeForm, crypter encrypt.Crypter) ([]*Order, error) {
+	dc, err := c.connectedDEX(form.Host)
+	if err != nil {
+		return nil, err
+	}
+	if dc.acct.suspended() {
+		return nil, newError(suspendedAcctErr, "may not trade while account is suspended")
+	}
+
+	corders, fromID, err := c.prepareMultiTrade(dc, form, crypter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.updateAssetBalance(fromID)
+
+	return corders, nil
+}
+
+// prepareMultiTrade funds and sends the orders of a MultiTradeForm, and starts
+// tracking the trades.
+func (c *Core) prepareMultiTrade(dc *dexConnection, form *MultiTradeForm, crypter encrypt.Crypter) ([]*Order, uint32, error) {
+	n := len(form.Placements)
+	if n == 0 {
+		return nil, 0, newError(orderParamsErr, "no orders")
+	}
+	if n > maxMultiTradeOrders {
+		return nil, 0, newError(orderParamsErr, "too many orders. %d > %d", n, maxMultiTradeOrders)
+	}
+
+	mktID := marketName(form.Base, form.Quote)
+	mktConf := dc.marketConfig(mktID)
+	if mktConf == nil {
+		return nil, 0, newError(marketErr, "order placed for unknown market %q", mktID)
+	}
+	if !dc.running(mktID) {
+		return nil, 0, newError(marketErr, "%s market trading is suspended", mktID)
+	}
+	lotSize := mktConf.LotSize
+
+	wallets, err := c.walletSet(dc, form.Base, form.Quote, form.Sell)
+	if err != nil {
+		return nil, 0, err
+	}
+	fromWallet, toWallet := wallets.fromWallet, wallets.toWallet
+
+	values := make([]*asset.MultiOrderValue, 0, n)
+	for i, p := range form.Placements {
+		if p.Rate == 0 {
+			return nil, 0, newError(orderParamsErr, "order %d: zero-rate order not allowed", i)
+		}
+		lots := p.Qty / lotSize
+		if lots == 0 || p.Qty%lotSize != 0 {
+			return nil, 0, newError(orderParamsErr, "order %d: quantity %d is not a non-zero multiple of the lot size %d",
+				i, p.Qty, lotSize)
+		}
+		fundQty := p.Qty
+		if !form.Sell {
+			fundQty = calc.BaseToQuote(p.Rate, fundQty)
+		}
+		values = append(values, &asset.MultiOrderValue{
+			Value:        fundQty,
+			MaxSwapCount: lots,
+		})
+	}
+
+	accountRedeemer, isAccountRedemption := toWallet.Wallet.(asset.AccountLocker)
+	accountRefunder, isAccountRefund := fromWallet.Wallet.(asset.AccountLocker)
+
+	if err = c.prepareTradeWallet(fromWallet, crypter); err != nil {
+		return nil, 0, err
+	}
+	if err = c.prepareTradeWallet(toWallet, crypter); err != nil {
+		return nil, 0, err
+	}
+
+	// Fund the orders.
+	allCoins, allRedeemScripts, err := c.fundMultiOrder(fromWallet, &asset.MultiOrder{
+		Values:        values,
+		DEXConfig:     wallets.fromAsset,
+		RedeemConfig:  wallets.toAsset,
+		FeeSuggestion: c.feeSuggestion(dc, wallets.fromAsset.ID),
+		Options:       form.Options,
+	})
+	if err != nil {
+		return nil, 0, codedError(walletErr, fmt.Errorf("error funding %d %s orders: %w",
+			n, wallets.fromAsset.Symbol, err))
+	}
+
+	// Any coins or reserves not claimed by a tracked trade are released if
+	// the orders do not get to the server successfully.
+	var success bool
+	var redemptionReserves, refundReserves uint64
+	defer func() {
+		if success {
+			return
+		}
+		for _, coins := range allCoins {
+			if err := fromWallet.ReturnCoins(coins); err != nil {
+				c.log.Warnf("Unable to return %s funding coins: %v", unbip(fromWallet.AssetID), err)
+			}
+		}
+		if redemptionReserves > 0 {
+			accountRedeemer.UnlockRedemptionReserves(redemptionReserves)
+		}
+		if refundReserves > 0 {
+			accountRefunder.UnlockRefundReserves(refundReserves)
+		}
+	}()
+
+	reqs := make([]*tradeRequest, 0, n)
+	msgOrders := make([]*msgjson.LimitOrder, 0, n)
+	for i, p := range form.Placements {
+		coins, redeemScripts := allCoins[i], allRedeemScripts[i]
+
+		addr, err := toWallet.DepositAddress()
+		if err != nil {
+			return nil, 0, codedError(walletErr, fmt.Errorf("%s Address error: %w", wallets.toAsset.Symbol, err))
+		}
+
+		coinIDs := make([]order.CoinID, 0, len(coins))
+		for i := range coins {
+			coinIDs = append(coinIDs, []byte(coins[i].ID()))
+		}
+
+		var recoveryCoin asset.Coin
+		var changeID []byte
+		if len(coins) == 1 {
+			if rc, is := coins[0].(asset.RecoveryCoin); is {
+				recoveryCoin = coins[0]
+				changeID = rc.RecoveryID()
+			}
+		}
+
+		preImg := newPreimage()
+		lo := &order.LimitOrder{
+			P: order.Prefix{
+				AccountID:  dc.acct.ID(),
+				BaseAsset:  form.Base,
+				QuoteAsset: form.Quote,
+				OrderType:  order.LimitOrderType,
+				ClientTime: time.Now(),
+				Commit:     preImg.Commit(),
+			},
+			T: order.Trade{
+				Coins:    coinIDs,
+				Sell:     form.Sell,
+				Quantity: p.Qty,
+				Address:  addr,
+			},
+			Rate:  p.Rate,
+			Force: order.StandingTiF,
+		}
+		if err = order.ValidateOrder(lo, order.OrderStatusEpoch, lotSize); err != nil {
+			return nil, 0, fmt.Errorf("order %d: ValidateOrder error: %w", i, err)
+		}
+
+		msgCoins, err := messageCoins(fromWallet, coins, redeemScripts)
+		if err != nil {
+			return nil, 0, fmt.Errorf("wallet %v failed to sign coins: %w", wallets.fromAsset.ID, err)
+		}
+		_, msgOrder, msgTrade := messageOrder(lo, msgCoins)
+
+		lots := values[i].MaxSwapCount
+		var ordRedemptionReserves, ordRefundReserves uint64
+		if isAccountRedemption {
+			pubKeys, sigs, err := toWallet.SignMessage(nil, msgOrder.Serialize())
+			if err != nil {
+				return nil, 0, codedError(signatureErr, fmt.Errorf("SignMessage error: %w", err))
+			}
+			if len(pubKeys) == 0 || len(sigs) == 0 {
+				return nil, 0, newError(signatureErr, "wrong number of pubkeys or signatures, %d & %d", len(pubKeys), len(sigs))
+			}
+			ordRedemptionReserves, err = accountRedeemer.ReserveNRedemptions(lots, wallets.toAsset)
+			if err != nil {
+				return nil, 0, codedError(walletErr, fmt.Errorf("ReserveNRedemptions error: %w", err))
+			}
+			redemptionReserves += ordRedemptionReserves
+			msgTrade.RedeemSig = &msgjson.RedeemSig{
+				PubKey: pubKeys[0],
+				Sig:    sigs[0],
+			}
+		}
+		if isAccountRefund {
+			ordRefundReserves, err = accountRefunder.ReserveNRefunds(lots, wallets.fromAsset)
+			if err != nil {
+				return nil, 0, codedError(walletErr, fmt.Errorf("ReserveNRefunds error: %w", err))
+			}
+			refundReserves += ordRefundReserves
+		}
+
+		if changeID != nil {
+			if _, msgTrade.Coins[0].Sigs, err = fromWallet.SignMessage(nil, msgOrder.Serialize()); err != nil {
+				return nil, 0, fmt.Errorf("wallet %v failed to sign for redeem: %w", wallets.fromAsset.ID, err)
+			}
+		}
+
+		reqs = append(reqs, &tradeRequest{
+			ord:                lo,
+			msgOrder:           msgOrder,
+			preImg:             preImg,
+			coins:              coins,
+			recoveryCoin:       recoveryCoin,
+			changeID:           changeID,
+			redemptionReserves: ordRedemptionReserves,
+			refundReserves:     ordRefundReserves,
+			options:            form.Options,
+		})
+		msgOrders = append(msgOrders, msgOrder.(*msgjson.LimitOrder))
+	}
+
+	if dc.acct.locked() {
+		return nil, 0, fmt.Errorf("cannot sign: %s account locked", dc.acct.host)
+	}
+	for _, msgOrder := range msgOrders {
+		sign(dc.acct.privKey, msgOrder)
+	}
+
+	c.sentCommitsMtx.Lock()
+	for _, req := range reqs {
+		commitSig := make(chan struct{})
+		defer close(commitSig) // signals on both success and failure
+		c.sentCommits[req.ord.Commitment()] = commitSig
+	}
+	c.sentCommitsMtx.Unlock()
+
+	var results []*msgjson.OrderResult
+	err = sendRequest(dc.WsConn, msgjson.MultiTradeRoute, &msgjson.MultiTrade{Orders: msgOrders},
+		&results, fundingTxWait+DefaultResponseTimeout)
+	if err != nil {
+		// As with a single order, the server may have accepted the orders
+		// even though we did not get the response. These orders are
+		// ABANDONED.
+		return nil, 0, fmt.Errorf("multi-order request with DEX server %v market %v failed: %w", dc.acct.host, mktID, err)
+	}
+	if len(results) != n {
+		return nil, 0, fmt.Errorf("expected %d order results, got %d", n, len(results))
+	}
+
+	// The server has accepted the orders, so the funding coins and reserves
+	// now belong to the tracked trades, even if one of them fails
+	// validation.
+	success = true
+	corders := make([]*Order, 0, n)
+	for i, req := range reqs {
+		corder, err := c.storeTrade(dc, req, results[i], wallets)
+		if err != nil {
+			c.log.Errorf("Error storing order %d of %d: %v", i+1, n, err)
+			if err := fromWallet.ReturnCoins(req.coins); err != nil {
+				c.log.Warnf("Unable to return %s funding coins: %v", unbip(fromWallet.AssetID), err)
+			}
+			if req.redemptionReserves > 0 {
+				accountRedeemer.UnlockRedemptionReserves(req.redemptionReserves)
+			}
+			if req.refundReserves > 0 {
+				accountRefunder.UnlockRefundReserves(req.refundReserves)
+			}
+			continue
+		}
+		corders = append(corders, corder)
+	}
+	if len(corders) == 0 {
+		return nil, 0, fmt.Errorf("all %d orders abandoned", n)
+	}
+
+	return corders, fromWallet.AssetID, nil
+}
+
+// fundMultiOrder funds the orders of a MultiOrder. If the wallet is not an
+// asset.MultiOrderFunder, each order is funded with a separate FundOrder call.
+func (c *Core) fundMultiOrder(w *xcWallet, mo *asset.MultiOrder) ([]asset.Coins, [][]dex.Bytes, error) {
+	if funder, is := w.Wallet.(asset.MultiOrderFunder); is {
+		coins, redeemScripts, err := funder.FundMultiOrder(mo)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(coins) != len(mo.Values) || len(redeemScripts) != len(mo.Values) {
+			for _, cs := range coins {
+				w.ReturnCoins(cs)
+			}
+			return nil, nil, fmt.Errorf("wallet funded %d of %d orders", len(coins), len(mo.Values))
+		}
+		return coins, redeemScripts, nil
+	}
+
+	allCoins := make([]asset.Coins, 0, len(mo.Values))
+	allRedeemScripts := make([][]dex.Bytes, 0, len(mo.Values))
+	for i, v := range mo.Values {
+		coins, redeemScripts, err := w.FundOrder(&asset.Order{
+			Value:         v.Value,
+			MaxSwapCount:  v.MaxSwapCount,
+			DEXConfig:     mo.DEXConfig,
+			RedeemConfig:  mo.RedeemConfig,
+			FeeSuggestion: mo.FeeSuggestion,
+			Options:       mo.Options,
+		})
+		if err != nil {
+			for _, cs := range allCoins {
+				if err := w.ReturnCoins(cs); err != nil {
+					c.log.Warnf("Unable to return %s funding coins: %v", unbip(w.AssetID), err)
+				}
+			}
+			return nil, nil, fmt.Errorf("order %d: FundOrder error: %w", i, err)
+		}
+		allCoins = append(allCoins, coins)
+		allRedeemScripts = append(allRedeemScripts, redeemScripts)
+	}
+	return allCoins, allRedeemScripts, nil
+}
@@ -0,0 +1,139 @@
+//go:build !harness
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/msgjson"
+)
+
+func TestMultiTrade(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	qty := dcrBtcLotSize * 10
+	rate := dcrBtcRateStep * 1000
+	tDcrWallet.fundingCoins = asset.Coins{&tCoin{id: encode.RandomBytes(36), val: qty * 2}}
+	tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+
+	newForm := func() *MultiTradeForm {
+		return &MultiTradeForm{
+			Host:  tDexHost,
+			Sell:  true,
+			Base:  tUTXOAssetA.ID,
+			Quote: tUTXOAssetB.ID,
+			Placements: []*QtyRate{
+				{Qty: qty, Rate: rate},
+				{Qty: qty * 2, Rate: rate + dcrBtcRateStep},
+				{Qty: qty, Rate: rate + dcrBtcRateStep*2},
+			},
+		}
+	}
+
+	var numOrders int
+	queueResults := func() {
+		rig.ws.queueResponse(msgjson.MultiTradeRoute, func(msg *msgjson.Message, f msgFunc) error {
+			req := new(msgjson.MultiTrade)
+			msg.Unmarshal(req)
+			numOrders = len(req.Orders)
+			results := make([]*msgjson.OrderResult, 0, len(req.Orders))
+			for _, msgOrder := range req.Orders {
+				stamp := time.Now()
+				msgOrder.Stamp(uint64(stamp.UnixMilli()))
+				sign(tDexPriv, msgOrder)
+				lo := convertMsgLimitOrder(msgOrder)
+				lo.SetTime(stamp)
+				oid := lo.ID()
+				results = append(results, &msgjson.OrderResult{
+					Sig:        msgOrder.SigBytes(),
+					OrderID:    oid[:],
+					ServerTime: uint64(stamp.UnixMilli()),
+				})
+			}
+			resp, _ := msgjson.NewResponse(msg.ID, results, nil)
+			f(resp)
+			return nil
+		})
+	}
+
+	ensureErr := func(tag string, form *MultiTradeForm) {
+		t.Helper()
+		if _, err := tCore.MultiTrade(tPW, form); err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
+	}
+
+	form := newForm()
+	form.Placements = nil
+	ensureErr("no placements", form)
+
+	form = newForm()
+	form.Placements[1].Qty++
+	ensureErr("bad lot size", form)
+
+	form = newForm()
+	form.Placements[2].Rate = 0
+	ensureErr("zero rate", form)
+
+	form = newForm()
+	form.Quote = 12345
+	ensureErr("unknown market", form)
+
+	// Funding error.
+	tDcrWallet.fundingCoinErr = tErr
+	ensureErr("funding error", newForm())
+	tDcrWallet.fundingCoinErr = nil
+
+	// Server error. The coins are returned.
+	tDcrWallet.returnedCoins = nil
+	rig.ws.queueResponse(msgjson.MultiTradeRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, nil, msgjson.NewError(msgjson.OrderParameterError, "test error"))
+		f(resp)
+		return nil
+	})
+	ensureErr("server error", newForm())
+	if tDcrWallet.returnedCoins == nil {
+		t.Fatalf("coins not returned after server error")
+	}
+
+	// Success.
+	queueResults()
+	corders, err := tCore.MultiTrade(tPW, newForm())
+	if err != nil {
+		t.Fatalf("MultiTrade error: %v", err)
+	}
+	if numOrders != 3 || len(corders) != 3 {
+		t.Fatalf("expected 3 orders, sent %d, got %d", numOrders, len(corders))
+	}
+	for i, p := range newForm().Placements {
+		if corders[i].Qty != p.Qty || corders[i].Rate != p.Rate {
+			t.Fatalf("wrong order %d", i)
+		}
+	}
+	rig.dc.tradeMtx.RLock()
+	numTrades := len(rig.dc.trades)
+	rig.dc.tradeMtx.RUnlock()
+	if numTrades != 3 {
+		t.Fatalf("expected 3 trades, got %d", numTrades)
+	}
+
+	// The wallets are unlocked, so orders can be placed without the password.
+	queueResults()
+	if corders, err = tCore.MultiTradeUnlocked(newForm()); err != nil {
+		t.Fatalf("MultiTradeUnlocked error: %v", err)
+	}
+	if len(corders) != 3 {
+		t.Fatalf("expected 3 orders, got %d", len(corders))
+	}
+}
@@ -15,6 +15,8 @@ import (
 // Notifications should use the following note type strings.
 const (
 	NoteTypeFeePayment   = "feepayment"
+	NoteTypeBondPost     = "bondpost"
+	NoteTypeStopOrder    = "stoporder"
 	NoteTypeSend         = "send"
 	NoteTypeOrder        = "order"
 	NoteTypeMatch        = "match"
@@ -199,6 +201,46 @@ func newFeePaymentNoteWithConfirmations(topic Topic, subject, details string, se
 	return feePmtNt
 }
 
+// BondPostNote is a notification regarding bond posting, confirmation,
+// expiry, and refund.
+type BondPostNote struct {
+	db.Notification
+	Asset         *uint32 `json:"asset,omitempty"`
+	Confirmations *int32  `json:"confirmations,omitempty"`
+	Tier          *int64  `json:"tier,omitempty"`
+	Dex           string  `json:"dex,omitempty"`
+}
+
+const (
+	TopicBondConfirming  Topic = "BondConfirming"
+	TopicBondConfirmed   Topic = "BondConfirmed"
+	TopicBondPostError   Topic = "BondPostError"
+	TopicBondExpired     Topic = "BondExpired"
+	TopicBondRefunded    Topic = "BondRefunded"
+	TopicBondRefundError Topic = "BondRefundError"
+)
+
+func newBondPostNote(topic Topic, subject, details string, severity db.Severity, dexAddr string) *BondPostNote {
+	host, _ := addrHost(dexAddr)
+	return &BondPostNote{
+		Notification: db.NewNotification(NoteTypeBondPost, topic, subject, details, severity),
+		Dex:          host,
+	}
+}
+
+func newBondPostNoteWithConfirmations(topic Topic, subject, details string, severity db.Severity, asset uint32, currConfs int32, dexAddr string) *BondPostNote {
+	bondPmtNt := newBondPostNote(topic, subject, details, severity, dexAddr)
+	bondPmtNt.Asset = &asset
+	bondPmtNt.Confirmations = &currConfs
+	return bondPmtNt
+}
+
+func newBondPostNoteWithTier(topic Topic, subject, details string, severity db.Severity, dexAddr string, tier int64) *BondPostNote {
+	bondPmtNt := newBondPostNote(topic, subject, details, severity, dexAddr)
+	bondPmtNt.Tier = &tier
+	return bondPmtNt
+}
+
 // SendNote is a notification regarding a requested send or withdraw.
 type SendNote struct {
 	db.Notification
@@ -239,6 +281,8 @@ const (
 	TopicNoMatch              Topic = "NoMatch"
 	TopicOrderCanceled        Topic = "OrderCanceled"
 	TopicCancel               Topic = "Cancel"
+	TopicOrderReplaced        Topic = "OrderReplaced"
+	TopicMissedReplace        Topic = "MissedReplace"
 	TopicMatchesMade          Topic = "MatchesMade"
 	TopicSwapSendError        Topic = "SwapSendError"
 	TopicInitError            Topic = "InitError"
@@ -251,6 +295,7 @@ const (
 	TopicMatchRevoked         Topic = "MatchRevoked"
 	TopicOrderRevoked         Topic = "OrderRevoked"
 	TopicOrderAutoRevoked     Topic = "OrderAutoRevoked"
+	TopicOrderExpired         Topic = "OrderExpired"
 	TopicMatchRecovered       Topic = "MatchRecovered"
 	TopicCancellingOrder      Topic = "CancellingOrder"
 	TopicOrderStatusUpdate    Topic = "OrderStatusUpdate"
@@ -267,6 +312,24 @@ func newOrderNote(topic Topic, subject, details string, severity db.Severity, co
 	}
 }
 
+// StopOrderNote is a notification about a triggered stop order.
+type StopOrderNote struct {
+	db.Notification
+	StopOrder *db.StopOrder `json:"stopOrder"`
+}
+
+const (
+	TopicStopOrderTriggered Topic = "StopOrderTriggered"
+	TopicStopOrderFailed    Topic = "StopOrderFailed"
+)
+
+func newStopOrderNote(topic Topic, subject, details string, severity db.Severity, so *db.StopOrder) *StopOrderNote {
+	return &StopOrderNote{
+		Notification: db.NewNotification(NoteTypeStopOrder, topic, subject, details, severity),
+		StopOrder:    copyStopOrder(so),
+	}
+}
+
 // MatchNote is a notification about a match.
 type MatchNote struct {
 	db.Notification
@@ -454,6 +517,7 @@ const (
 	TopicMarketSuspendedWithPurge Topic = "MarketSuspendedWithPurge"
 	TopicMarketResumeScheduled    Topic = "MarketResumeScheduled"
 	TopicMarketResumed            Topic = "MarketResumed"
+	TopicMarketsUpdated           Topic = "MarketsUpdated"
 	TopicPenalized                Topic = "Penalized"
 	TopicDEXNotification          Topic = "DEXNotification"
 )
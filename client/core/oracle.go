@@ -0,0 +1,351 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"github.com/decred/go-socks/socks"
+)
+
+const (
+	// oracleCacheExpiry is how long an aggregated price is reused before the
+	// sources are queried again.
+	oracleCacheExpiry = time.Minute
+	// oracleRequestTimeout is the time allowed for all sources to respond.
+	oracleRequestTimeout = 10 * time.Second
+	// defaultOracleMaxDeviation is the fractional deviation from the median
+	// price beyond which a source is considered an outlier.
+	defaultOracleMaxDeviation = 0.1
+	// defaultOracleThreshold is the fractional deviation of a limit order
+	// rate from the oracle price beyond which PreOrder warns the user.
+	defaultOracleThreshold = 0.05
+)
+
+// PriceOracle is a source of exchange rates that is independent of the DEX
+// order books. Price returns the conventional exchange rate, i.e. the number
+// of whole quote asset units per whole base asset unit.
+type PriceOracle interface {
+	Price(base, quote uint32) (float64, error)
+}
+
+// OracleSource describes an HTTP endpoint that returns a JSON document with a
+// price for a market.
+type OracleSource struct {
+	// Name is used for logging.
+	Name string `json:"name"`
+	// URL is the request URL. The placeholders {base} and {quote} are
+	// replaced with the lower-case asset symbols, and {BASE} and {QUOTE} with
+	// the upper-case symbols.
+	URL string `json:"url"`
+	// PricePath is the location of the price in the response, as a
+	// dot-separated list of object keys and array indices, e.g.
+	// "data.0.last". The value may be a JSON number or a numeric string.
+	PricePath string `json:"pricePath"`
+	// VolumePath is the optional location of the trading volume in the
+	// response, in the same format as PricePath. Sources that report volume
+	// are weighted by it.
+	VolumePath string `json:"volumePath,omitempty"`
+	// Invert should be set if the source reports the price of the quote asset
+	// in units of the base asset.
+	Invert bool `json:"invert,omitempty"`
+}
+
+// sourcePrice is a price and volume reported by a single source.
+type sourcePrice struct {
+	price  float64
+	volume float64
+}
+
+type cachedPrice struct {
+	price float64
+	stamp time.Time
+}
+
+// priceFetch is an in-progress price request. done is closed when price and
+// err are set.
+type priceFetch struct {
+	done  chan struct{}
+	price float64
+	err   error
+}
+
+// priceOracle is a PriceOracle that aggregates the prices reported by a set
+// of HTTP JSON sources.
+type priceOracle struct {
+	log          dex.Logger
+	sources      []*OracleSource
+	client       *http.Client
+	maxDeviation float64
+
+	cacheMtx sync.Mutex
+	cache    map[[2]uint32]*cachedPrice
+	fetches  map[[2]uint32]*priceFetch
+}
+
+var _ PriceOracle = (*priceOracle)(nil)
+
+// newPriceOracle is the constructor for a priceOracle. If torProxy is set, the
+// sources are queried through the Tor proxy.
+func newPriceOracle(sources []*OracleSource, torProxy string, torIsolation bool, log dex.Logger) (*priceOracle, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("no price oracle sources")
+	}
+	for i, src := range sources {
+		if src.URL == "" {
+			return nil, fmt.Errorf("price oracle source %d has no URL", i)
+		}
+		if src.PricePath == "" {
+			return nil, fmt.Errorf("price oracle source %d has no price path", i)
+		}
+		if src.Name == "" {
+			src.Name = src.URL
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if torProxy != "" {
+		proxy := &socks.Proxy{
+			Addr:         torProxy,
+			TorIsolation: torIsolation,
+		}
+		transport.Proxy = nil
+		transport.DialContext = proxy.DialContext
+	}
+	return &priceOracle{
+		log:          log,
+		sources:      sources,
+		client:       &http.Client{Transport: transport},
+		maxDeviation: defaultOracleMaxDeviation,
+		cache:        make(map[[2]uint32]*cachedPrice),
+		fetches:      make(map[[2]uint32]*priceFetch),
+	}, nil
+}
+
+// Price returns the aggregated conventional exchange rate for the market.
+// Recently aggregated prices are cached. Only one request for a market is made
+// at a time, and concurrent callers wait for its result.
+func (o *priceOracle) Price(base, quote uint32) (float64, error) {
+	k := [2]uint32{base, quote}
+	o.cacheMtx.Lock()
+	if cp := o.cache[k]; cp != nil && time.Since(cp.stamp) < oracleCacheExpiry {
+		o.cacheMtx.Unlock()
+		return cp.price, nil
+	}
+	if f := o.fetches[k]; f != nil {
+		o.cacheMtx.Unlock()
+		<-f.done
+		return f.price, f.err
+	}
+	f := &priceFetch{done: make(chan struct{})}
+	o.fetches[k] = f
+	o.cacheMtx.Unlock()
+
+	// The sources are queried without holding the lock, so requests for other
+	// markets are not blocked.
+	f.price, f.err = o.fetchPrice(base, quote)
+
+	o.cacheMtx.Lock()
+	if f.err == nil {
+		o.cache[k] = &cachedPrice{price: f.price, stamp: time.Now()}
+	}
+	delete(o.fetches, k)
+	o.cacheMtx.Unlock()
+	close(f.done)
+	return f.price, f.err
+}
+
+// fetchPrice queries all sources concurrently and aggregates the results.
+func (o *priceOracle) fetchPrice(base, quote uint32) (float64, error) {
+	baseSymbol, quoteSymbol := dex.BipIDSymbol(base), dex.BipIDSymbol(quote)
+	if baseSymbol == "" || quoteSymbol == "" {
+		return 0, fmt.Errorf("unknown asset in market %d-%d", base, quote)
+	}
+	replacer := strings.NewReplacer(
+		"{base}", strings.ToLower(baseSymbol),
+		"{quote}", strings.ToLower(quoteSymbol),
+		"{BASE}", strings.ToUpper(baseSymbol),
+		"{QUOTE}", strings.ToUpper(quoteSymbol),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), oracleRequestTimeout)
+	defer cancel()
+
+	var mtx sync.Mutex
+	prices := make([]*sourcePrice, 0, len(o.sources))
+	var wg sync.WaitGroup
+	for _, src := range o.sources {
+		wg.Add(1)
+		go func(src *OracleSource) {
+			defer wg.Done()
+			sp, err := o.fetchSource(ctx, src, replacer.Replace(src.URL))
+			if err != nil {
+				o.log.Warnf("Error fetching %s-%s price from %s: %v", baseSymbol, quoteSymbol, src.Name, err)
+				return
+			}
+			mtx.Lock()
+			prices = append(prices, sp)
+			mtx.Unlock()
+		}(src)
+	}
+	wg.Wait()
+
+	return aggregatePrices(prices, o.maxDeviation)
+}
+
+// fetchSource requests the price from a single source.
+func (o *priceOracle) fetchSource(ctx context.Context, src *OracleSource, uri string) (*sourcePrice, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	var doc interface{}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	price, err := jsonPathFloat(doc, src.PricePath)
+	if err != nil {
+		return nil, fmt.Errorf("price: %w", err)
+	}
+	if price <= 0 || math.IsInf(price, 0) || math.IsNaN(price) {
+		return nil, fmt.Errorf("invalid price %v", price)
+	}
+	if src.Invert {
+		price = 1 / price
+	}
+	sp := &sourcePrice{price: price}
+	if src.VolumePath != "" {
+		vol, err := jsonPathFloat(doc, src.VolumePath)
+		if err != nil {
+			return nil, fmt.Errorf("volume: %w", err)
+		}
+		if vol < 0 || math.IsInf(vol, 0) || math.IsNaN(vol) {
+			return nil, fmt.Errorf("invalid volume %v", vol)
+		}
+		sp.volume = vol
+	}
+	return sp, nil
+}
+
+// jsonPathFloat finds the value at the dot-separated path in a JSON document
+// decoded with UseNumber, and parses it as a float. Path elements are object
+// keys or array indices.
+func jsonPathFloat(doc interface{}, path string) (float64, error) {
+	v := doc
+	for _, k := range strings.Split(path, ".") {
+		switch tv := v.(type) {
+		case map[string]interface{}:
+			var found bool
+			if v, found = tv[k]; !found {
+				return 0, fmt.Errorf("key %q not found", k)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			if err != nil {
+				return 0, fmt.Errorf("invalid array index %q", k)
+			}
+			if i < 0 || i >= len(tv) {
+				return 0, fmt.Errorf("array index %d out of range", i)
+			}
+			v = tv[i]
+		default:
+			return 0, fmt.Errorf("cannot index %T with %q", v, k)
+		}
+	}
+	switch tv := v.(type) {
+	case json.Number:
+		return tv.Float64()
+	case string:
+		return strconv.ParseFloat(tv, 64)
+	default:
+		return 0, fmt.Errorf("value at %q is a %T, not a number", path, v)
+	}
+}
+
+// aggregatePrices combines the source prices into a single price. Sources
+// that deviate from the median price by more than the fractional maxDeviation
+// are discarded as outliers, and a majority of sources must remain. If every
+// remaining source reports a volume, the result is the volume-weighted mean,
+// otherwise it is the simple mean.
+func aggregatePrices(prices []*sourcePrice, maxDeviation float64) (float64, error) {
+	if len(prices) == 0 {
+		return 0, errors.New("no prices available")
+	}
+	sorted := make([]float64, 0, len(prices))
+	for _, sp := range prices {
+		sorted = append(sorted, sp.price)
+	}
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (median + sorted[len(sorted)/2-1]) / 2
+	}
+
+	var sum, weightedSum, volSum float64
+	var n int
+	weighted := true
+	for _, sp := range prices {
+		if math.Abs(sp.price-median)/median > maxDeviation {
+			continue
+		}
+		n++
+		sum += sp.price
+		weightedSum += sp.price * sp.volume
+		volSum += sp.volume
+		if sp.volume == 0 {
+			weighted = false
+		}
+	}
+	if n*2 <= len(prices) {
+		return 0, fmt.Errorf("price sources disagree: %d of %d within %.1f%% of the median",
+			n, len(prices), maxDeviation*100)
+	}
+	if weighted {
+		return weightedSum / volSum, nil
+	}
+	return sum / float64(n), nil
+}
+
+// oracleCheck compares a limit order's rate to the oracle price.
+func oracleCheck(oracle PriceOracle, base, quote uint32, msgRate uint64, baseInfo, quoteInfo dex.UnitInfo, threshold float64) (*OracleCheck, error) {
+	if baseInfo.Conventional.ConversionFactor == 0 || quoteInfo.Conventional.ConversionFactor == 0 {
+		return nil, errors.New("unknown conversion factor")
+	}
+	oraclePrice, err := oracle.Price(base, quote)
+	if err != nil {
+		return nil, err
+	}
+	if oraclePrice <= 0 {
+		return nil, fmt.Errorf("invalid oracle price %v", oraclePrice)
+	}
+	rate := calc.ConventionalRate(msgRate, baseInfo, quoteInfo)
+	dev := (rate - oraclePrice) / oraclePrice
+	return &OracleCheck{
+		OraclePrice: oraclePrice,
+		Rate:        rate,
+		Deviation:   dev,
+		Warning:     math.Abs(dev) > threshold,
+	}, nil
+}
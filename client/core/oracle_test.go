@@ -0,0 +1,277 @@
+//go:build !harness
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+)
+
+type tPriceOracle struct {
+	price float64
+	err   error
+}
+
+func (o *tPriceOracle) Price(base, quote uint32) (float64, error) {
+	return o.price, o.err
+}
+
+func TestAggregatePrices(t *testing.T) {
+	tests := []struct {
+		name    string
+		prices  []*sourcePrice
+		want    float64
+		wantErr bool
+	}{{
+		name:    "none",
+		wantErr: true,
+	}, {
+		name:   "single",
+		prices: []*sourcePrice{{price: 1.5}},
+		want:   1.5,
+	}, {
+		name:   "simple mean",
+		prices: []*sourcePrice{{price: 1}, {price: 1.1}, {price: 1.05, volume: 10}},
+		want:   1.05,
+	}, {
+		name:   "volume weighted",
+		prices: []*sourcePrice{{price: 1, volume: 3}, {price: 1.1, volume: 1}},
+		want:   1.025,
+	}, {
+		name:   "outlier rejected",
+		prices: []*sourcePrice{{price: 1, volume: 1}, {price: 1.02, volume: 1}, {price: 2, volume: 100}},
+		want:   1.01,
+	}, {
+		name:    "two disagree",
+		prices:  []*sourcePrice{{price: 1}, {price: 2}},
+		wantErr: true,
+	}, {
+		name:    "no majority",
+		prices:  []*sourcePrice{{price: 1}, {price: 1.01}, {price: 2}, {price: 3}},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		p, err := aggregatePrices(tt.prices, defaultOracleMaxDeviation)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: aggregatePrices error: %v", tt.name, err)
+		}
+		if math.Abs(p-tt.want) > 1e-9 {
+			t.Fatalf("%s: wanted %f, got %f", tt.name, tt.want, p)
+		}
+	}
+}
+
+func TestJSONPathFloat(t *testing.T) {
+	// Decode as the oracle does, with UseNumber.
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(`{"a":["1.5",{"b":"x"}],"c":{"d":2.5}}`))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		path    string
+		want    float64
+		wantErr bool
+	}{
+		{path: "a.0", want: 1.5},
+		{path: "c.d", want: 2.5},
+		{path: "a.1.b", wantErr: true},
+		{path: "a.2", wantErr: true},
+		{path: "a.x", wantErr: true},
+		{path: "e", wantErr: true},
+		{path: "c", wantErr: true},
+		{path: "c.d.e", wantErr: true},
+	} {
+		v, err := jsonPathFloat(doc, tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: no error", tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: error: %v", tt.path, err)
+		}
+		if v != tt.want {
+			t.Fatalf("%s: wanted %f, got %f", tt.path, tt.want, v)
+		}
+	}
+}
+
+func TestPriceOracle(t *testing.T) {
+	var reqs uint32
+	newSource := func(path, body string) *OracleSource {
+		mux := http.NewServeMux()
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddUint32(&reqs, 1)
+			fmt.Fprint(w, body)
+		})
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+		return &OracleSource{URL: srv.URL + "/{base}/{QUOTE}"}
+	}
+
+	src1 := newSource("/dcr/BTC", `{"last":"0.0010","vol":"300"}`)
+	src1.PricePath, src1.VolumePath = "last", "vol"
+	src2 := newSource("/dcr/BTC", `{"data":[{"price":0.0011,"volume":100}]}`)
+	src2.PricePath, src2.VolumePath = "data.0.price", "data.0.volume"
+	// Reports BTC per DCR the other way around.
+	src3 := newSource("/dcr/BTC", `{"price":1000}`)
+	src3.PricePath, src3.Invert = "price", true
+	src3.VolumePath = "price" // volume 1000
+	// An outlier.
+	outlier := newSource("/dcr/BTC", `{"p":0.002,"v":1e6}`)
+	outlier.PricePath, outlier.VolumePath = "p", "v"
+	// A source for a different market is an error and is ignored.
+	missing := newSource("/eth/BTC", `{"p":1}`)
+	missing.PricePath = "p"
+
+	o, err := newPriceOracle([]*OracleSource{src1, src2, src3, outlier, missing}, "", false, tLogger)
+	if err != nil {
+		t.Fatalf("newPriceOracle error: %v", err)
+	}
+
+	p, err := o.Price(tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err != nil {
+		t.Fatalf("Price error: %v", err)
+	}
+	want := (0.0010*300 + 0.0011*100 + 0.001*1000) / 1400
+	if math.Abs(p-want) > 1e-12 {
+		t.Fatalf("wanted %.10f, got %.10f", want, p)
+	}
+	if reqs != 4 {
+		t.Fatalf("expected 4 requests, got %d", reqs)
+	}
+
+	// Cached.
+	if _, err = o.Price(tUTXOAssetA.ID, tUTXOAssetB.ID); err != nil {
+		t.Fatalf("cached Price error: %v", err)
+	}
+	if reqs != 4 {
+		t.Fatalf("price not cached")
+	}
+
+	if _, err = newPriceOracle([]*OracleSource{{URL: src1.URL}}, "", false, tLogger); err == nil {
+		t.Fatalf("no error for source without a price path")
+	}
+}
+
+func TestPriceOracleConcurrency(t *testing.T) {
+	var dcrReqs uint32
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dcr/BTC", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint32(&dcrReqs, 1)
+		started <- struct{}{}
+		<-release
+		fmt.Fprint(w, `{"p":0.001}`)
+	})
+	mux.HandleFunc("/eth/BTC", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"p":0.05}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	o, err := newPriceOracle([]*OracleSource{{URL: srv.URL + "/{base}/{QUOTE}", PricePath: "p"}}, "", false, tLogger)
+	if err != nil {
+		t.Fatalf("newPriceOracle error: %v", err)
+	}
+
+	const n = 5
+	type result struct {
+		price float64
+		err   error
+	}
+	results := make(chan *result, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			p, err := o.Price(tUTXOAssetA.ID, tUTXOAssetB.ID)
+			results <- &result{p, err}
+		}()
+	}
+
+	// Another market's price is not blocked by the pending request.
+	<-started
+	ethDone := make(chan error, 1)
+	go func() {
+		_, err := o.Price(60, tUTXOAssetB.ID)
+		ethDone <- err
+	}()
+	select {
+	case err := <-ethDone:
+		if err != nil {
+			t.Fatalf("eth Price error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		close(release)
+		t.Fatalf("eth Price blocked by pending dcr request")
+	}
+
+	close(release)
+	for i := 0; i < n; i++ {
+		r := <-results
+		if r.err != nil {
+			t.Fatalf("Price error: %v", r.err)
+		}
+		if r.price != 0.001 {
+			t.Fatalf("wrong price %f", r.price)
+		}
+	}
+	if reqs := atomic.LoadUint32(&dcrReqs); reqs != 1 {
+		t.Fatalf("expected 1 request, got %d", reqs)
+	}
+}
+
+func TestOracleCheck(t *testing.T) {
+	baseInfo := dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e8}}
+	quoteInfo := dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e8}}
+	oracle := &tPriceOracle{price: 0.001}
+	msgRate := calc.MessageRate(0.00106, baseInfo, quoteInfo)
+
+	check, err := oracleCheck(oracle, 42, 0, msgRate, baseInfo, quoteInfo, 0.05)
+	if err != nil {
+		t.Fatalf("oracleCheck error: %v", err)
+	}
+	if math.Abs(check.Deviation-0.06) > 1e-9 || !check.Warning {
+		t.Fatalf("wrong check %+v", check)
+	}
+
+	check, _ = oracleCheck(oracle, 42, 0, msgRate, baseInfo, quoteInfo, 0.1)
+	if check.Warning {
+		t.Fatalf("warning below threshold")
+	}
+
+	msgRate = calc.MessageRate(0.0009, baseInfo, quoteInfo)
+	check, _ = oracleCheck(oracle, 42, 0, msgRate, baseInfo, quoteInfo, 0.05)
+	if math.Abs(check.Deviation+0.1) > 1e-9 || !check.Warning {
+		t.Fatalf("wrong check for low rate %+v", check)
+	}
+
+	if _, err = oracleCheck(oracle, 42, 0, msgRate, dex.UnitInfo{}, quoteInfo, 0.05); err == nil {
+		t.Fatalf("no error for missing unit info")
+	}
+	oracle.err = errors.New("no price")
+	if _, err = oracleCheck(oracle, 42, 0, msgRate, baseInfo, quoteInfo, 0.05); err == nil {
+		t.Fatalf("no error for oracle error")
+	}
+}
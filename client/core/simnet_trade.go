@@ -245,7 +245,7 @@ func (s *simulationTest) startClients() error {
 		<-c.core.Ready()
 
 		// init app
-		err = c.core.InitializeClient(c.appPass, nil)
+		err = c.core.InitializeClient(c.appPass, "")
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,352 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+)
+
+const (
+	// stopOrderIDSize is the length of a stop order ID, in bytes.
+	stopOrderIDSize = 16
+	// maxStopOrderAttempts is the number of times that placing the order for
+	// a triggered stop order is attempted before the stop order is dropped.
+	maxStopOrderAttempts = 5
+	// stopOrderRetryDelay is the delay before placing the order for a
+	// triggered stop order is retried after the first failed attempt. The
+	// delay is doubled after each subsequent failure.
+	stopOrderRetryDelay = 30 * time.Second
+)
+
+// stopOrderRetry tracks the failed placement attempts of a triggered stop
+// order.
+type stopOrderRetry struct {
+	attempts int
+	next     time.Time
+}
+
+// StopOrderForm is used to create a stop or trailing-stop order. When the
+// market rate crosses the StopRate, the Trade order is placed.
+type StopOrderForm struct {
+	Trade *TradeForm `json:"trade"`
+	// StopRate is the market rate that triggers the order. For a trailing
+	// stop, StopRate may be zero, in which case it is set TrailRate away from
+	// the current market rate.
+	StopRate uint64 `json:"stopRate"`
+	// TrailRate is the distance that a trailing stop follows the market rate.
+	// Zero for a plain stop order.
+	TrailRate uint64 `json:"trailRate"`
+}
+
+// stopTriggered checks whether the market rate has crossed the stop order's
+// stop rate. Sell stops trigger at or below the stop rate, and buy stops at or
+// above it.
+func stopTriggered(so *db.StopOrder, rate uint64) bool {
+	if so.Sell {
+		return rate <= so.StopRate
+	}
+	return rate >= so.StopRate
+}
+
+// updateTrailingStop moves the stop rate of a trailing stop toward the market
+// rate if the market has moved away from the stop by more than the trail
+// distance. The return value indicates whether the stop rate was changed.
+func updateTrailingStop(so *db.StopOrder, rate uint64) bool {
+	if so.TrailRate == 0 {
+		return false
+	}
+	if so.Sell {
+		if rate > so.TrailRate && rate-so.TrailRate > so.StopRate {
+			so.StopRate = rate - so.TrailRate
+			return true
+		}
+		return false
+	}
+	if rate+so.TrailRate < so.StopRate {
+		so.StopRate = rate + so.TrailRate
+		return true
+	}
+	return false
+}
+
+// stopOrderRate is the market rate against which stop orders are checked. The
+// spot rate, i.e. the rate of the most recent match, is used if known.
+// Otherwise, the mid-gap rate of a synced order book is used.
+func (dc *dexConnection) stopOrderRate(mktID string) (uint64, bool) {
+	dc.spotsMtx.RLock()
+	spot := dc.spots[mktID]
+	dc.spotsMtx.RUnlock()
+	if spot != nil && spot.Rate > 0 {
+		return spot.Rate, true
+	}
+	if book := dc.bookie(mktID); book != nil {
+		if midGap, err := book.MidGap(); err == nil && midGap > 0 {
+			return midGap, true
+		}
+	}
+	return 0, false
+}
+
+// StopOrder creates a stop or trailing-stop order. The order is stored and
+// watched by Core until it is triggered or canceled. When triggered, the
+// order described by the form's TradeForm is placed as if by Trade. Triggered
+// orders are only placed while logged in to the DEX and the wallets are
+// unlocked.
+func (c *Core) StopOrder(pw []byte, form *StopOrderForm) (*db.StopOrder, error) {
+	crypter, err := c.encryptionKey(pw)
+	if err != nil {
+		return nil, fmt.Errorf("StopOrder password error: %w", err)
+	}
+	crypter.Close()
+
+	tf := form.Trade
+	if tf == nil {
+		return nil, newError(orderParamsErr, "no trade form")
+	}
+	dc, err := c.connectedDEX(tf.Host)
+	if err != nil {
+		return nil, err
+	}
+	mktID := marketName(tf.Base, tf.Quote)
+	mktConf := dc.marketConfig(mktID)
+	if mktConf == nil {
+		return nil, newError(marketErr, "unknown market %q", mktID)
+	}
+	if _, err = c.walletSet(dc, tf.Base, tf.Quote, tf.Sell); err != nil {
+		return nil, err
+	}
+	if tf.Qty == 0 {
+		return nil, newError(orderParamsErr, "zero quantity not allowed")
+	}
+	if (tf.IsLimit || tf.Sell) && tf.Qty%mktConf.LotSize != 0 {
+		return nil, newError(orderParamsErr, "order quantity must be a multiple of %d", mktConf.LotSize)
+	}
+	if tf.IsLimit {
+		if tf.Rate == 0 {
+			return nil, newError(orderParamsErr, "zero-rate order not allowed")
+		}
+		if tf.Rate%mktConf.RateStep != 0 {
+			return nil, newError(orderParamsErr, "order rate must be a multiple of %d", mktConf.RateStep)
+		}
+	}
+
+	so := &db.StopOrder{
+		ID:        encode.RandomBytes(stopOrderIDSize),
+		Host:      dc.acct.host,
+		BaseID:    tf.Base,
+		QuoteID:   tf.Quote,
+		Sell:      tf.Sell,
+		IsLimit:   tf.IsLimit,
+		Qty:       tf.Qty,
+		Rate:      tf.Rate,
+		TifNow:    tf.TifNow,
+		Options:   tf.Options,
+		StopRate:  form.StopRate,
+		TrailRate: form.TrailRate,
+		Stamp:     uint64(time.Now().UnixMilli()),
+	}
+
+	rate, haveRate := dc.stopOrderRate(mktID)
+	if so.StopRate == 0 {
+		if so.TrailRate == 0 {
+			return nil, newError(orderParamsErr, "no stop rate")
+		}
+		if !haveRate {
+			return nil, newError(orderParamsErr, "a stop rate is required when the market rate is unknown")
+		}
+		if so.Sell && rate <= so.TrailRate {
+			return nil, newError(orderParamsErr, "trail rate %d exceeds the market rate %d", so.TrailRate, rate)
+		}
+		so.StopRate = rate + so.TrailRate
+		if so.Sell {
+			so.StopRate = rate - so.TrailRate
+		}
+	}
+	if haveRate && stopTriggered(so, rate) {
+		return nil, newError(orderParamsErr, "stop rate %d has already been crossed by the market rate %d",
+			so.StopRate, rate)
+	}
+
+	if err := c.db.UpdateStopOrder(so); err != nil {
+		return nil, newError(dbErr, "error storing stop order: %w", err)
+	}
+	c.stopOrdersMtx.Lock()
+	c.stopOrders[hex.EncodeToString(so.ID)] = so
+	c.stopOrdersMtx.Unlock()
+
+	c.log.Infof("Created %s stop order %s on %s, stop rate = %d, trail = %d",
+		sellString(so.Sell), so.ID, mktID, so.StopRate, so.TrailRate)
+
+	return copyStopOrder(so), nil
+}
+
+// StopOrders returns the pending stop orders, sorted by creation time.
+func (c *Core) StopOrders() []*db.StopOrder {
+	c.stopOrdersMtx.RLock()
+	sos := make([]*db.StopOrder, 0, len(c.stopOrders))
+	for _, so := range c.stopOrders {
+		sos = append(sos, copyStopOrder(so))
+	}
+	c.stopOrdersMtx.RUnlock()
+	sort.Slice(sos, func(i, j int) bool { return sos[i].Stamp < sos[j].Stamp })
+	return sos
+}
+
+// CancelStopOrder cancels a pending stop order.
+func (c *Core) CancelStopOrder(id dex.Bytes) error {
+	k := id.String()
+	c.stopOrdersMtx.Lock()
+	defer c.stopOrdersMtx.Unlock()
+	if _, found := c.stopOrders[k]; !found {
+		return newError(unknownOrderErr, "unknown stop order %s", id)
+	}
+	if err := c.db.DeleteStopOrder(id); err != nil {
+		return newError(dbErr, "error deleting stop order: %w", err)
+	}
+	delete(c.stopOrders, k)
+	delete(c.stopOrderRetries, k)
+	return nil
+}
+
+// loadStopOrders loads the pending stop orders from the database.
+func (c *Core) loadStopOrders() {
+	sos, err := c.db.StopOrders()
+	if err != nil {
+		c.log.Errorf("Error loading stop orders: %v", err)
+		return
+	}
+	c.stopOrdersMtx.Lock()
+	for _, so := range sos {
+		c.stopOrders[hex.EncodeToString(so.ID)] = so
+	}
+	c.stopOrdersMtx.Unlock()
+	if len(sos) > 0 {
+		c.log.Infof("Loaded %d pending stop orders", len(sos))
+	}
+}
+
+// checkStopOrders checks the stop orders for the market against the current
+// market rate, adjusting trailing stops and placing the orders that have been
+// triggered. Triggered orders are not placed, and remain pending, if the
+// account is not authenticated, or if a failed placement is not yet due to be
+// retried.
+func (c *Core) checkStopOrders(dc *dexConnection, mktID string) {
+	rate, found := dc.stopOrderRate(mktID)
+	if !found {
+		return
+	}
+	ready := dc.acct.authed() && !dc.acct.suspended()
+
+	var triggered []*db.StopOrder
+	c.stopOrdersMtx.Lock()
+	for k, so := range c.stopOrders {
+		if so.Host != dc.acct.host || marketName(so.BaseID, so.QuoteID) != mktID {
+			continue
+		}
+		if stopTriggered(so, rate) {
+			if retry := c.stopOrderRetries[k]; retry != nil && time.Now().Before(retry.next) {
+				continue
+			}
+			if ready {
+				triggered = append(triggered, so)
+				delete(c.stopOrders, k)
+			}
+			continue
+		}
+		if updateTrailingStop(so, rate) {
+			c.log.Debugf("Trailing stop order %s moved to %d", so.ID, so.StopRate)
+			if err := c.db.UpdateStopOrder(so); err != nil {
+				c.log.Errorf("Error updating trailing stop order %s: %v", so.ID, err)
+			}
+		}
+	}
+	c.stopOrdersMtx.Unlock()
+
+	for _, so := range triggered {
+		// Placing the order requires a response from the server, so this
+		// must not block the message handler.
+		go c.placeStopOrder(dc, so, rate)
+	}
+}
+
+// placeStopOrder places the order for a triggered stop order and removes the
+// stop order from the database. If the order cannot be prepared, the stop order
+// remains pending, and placement is attempted again the next time the stop
+// order is triggered after a delay, up to maxStopOrderAttempts times. If the
+// order request was sent to the server, the server may have accepted the order,
+// so the stop order is dropped on failure rather than risk a duplicate order.
+func (c *Core) placeStopOrder(dc *dexConnection, so *db.StopOrder, rate uint64) {
+	mktID := marketName(so.BaseID, so.QuoteID)
+	rateStr := fmt.Sprintf("%d", rate)
+	if wallets, err := c.walletSet(dc, so.BaseID, so.QuoteID, so.Sell); err == nil {
+		rateStr = wallets.trimmedConventionalRateString(rate)
+	}
+
+	form := &TradeForm{
+		Host:    so.Host,
+		IsLimit: so.IsLimit,
+		Sell:    so.Sell,
+		Base:    so.BaseID,
+		Quote:   so.QuoteID,
+		Qty:     so.Qty,
+		Rate:    so.Rate,
+		TifNow:  so.TifNow,
+		Options: so.Options,
+	}
+	// There is no password, so the wallets must already be unlocked.
+	k := hex.EncodeToString(so.ID)
+	corder, fromID, err := c.prepareTrackedTrade(dc, form, nil)
+	if err != nil {
+		c.stopOrdersMtx.Lock()
+		retry := c.stopOrderRetries[k]
+		if retry == nil {
+			retry = new(stopOrderRetry)
+			c.stopOrderRetries[k] = retry
+		}
+		retry.attempts++
+		requeue := !errorHasCode(err, orderSubmitErr) && retry.attempts < maxStopOrderAttempts
+		if requeue {
+			delay := stopOrderRetryDelay << (retry.attempts - 1)
+			retry.next = time.Now().Add(delay)
+			c.stopOrders[k] = so
+			c.log.Warnf("Error placing triggered stop order %s (attempt %d of %d). Retrying in %v: %v",
+				so.ID, retry.attempts, maxStopOrderAttempts, delay, err)
+		} else {
+			delete(c.stopOrderRetries, k)
+		}
+		c.stopOrdersMtx.Unlock()
+		if requeue {
+			return
+		}
+		c.log.Errorf("Error placing triggered stop order %s: %v", so.ID, err)
+		if err := c.db.DeleteStopOrder(so.ID); err != nil {
+			c.log.Errorf("Error deleting failed stop order %s: %v", so.ID, err)
+		}
+		subject, details := c.formatDetails(TopicStopOrderFailed, token(so.ID), mktID, rateStr, err)
+		c.notify(newStopOrderNote(TopicStopOrderFailed, subject, details, db.ErrorLevel, so))
+		return
+	}
+	c.stopOrdersMtx.Lock()
+	delete(c.stopOrderRetries, k)
+	c.stopOrdersMtx.Unlock()
+	if err := c.db.DeleteStopOrder(so.ID); err != nil {
+		c.log.Errorf("Error deleting triggered stop order %s: %v", so.ID, err)
+	}
+	c.updateAssetBalance(fromID)
+
+	subject, details := c.formatDetails(TopicStopOrderTriggered, token(so.ID), mktID, rateStr, token(corder.ID))
+	c.notify(newStopOrderNote(TopicStopOrderTriggered, subject, details, db.Success, so))
+}
+
+func copyStopOrder(so *db.StopOrder) *db.StopOrder {
+	soCopy := *so
+	return &soCopy
+}
@@ -0,0 +1,351 @@
+//go:build !harness
+
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/msgjson"
+)
+
+func TestStopTriggered(t *testing.T) {
+	sell := &db.StopOrder{Sell: true, StopRate: 100}
+	buy := &db.StopOrder{StopRate: 100}
+	for _, tt := range []struct {
+		so   *db.StopOrder
+		rate uint64
+		want bool
+	}{
+		{sell, 101, false},
+		{sell, 100, true},
+		{sell, 99, true},
+		{buy, 99, false},
+		{buy, 100, true},
+		{buy, 101, true},
+	} {
+		if got := stopTriggered(tt.so, tt.rate); got != tt.want {
+			t.Fatalf("sell = %t, stop = %d, rate = %d: wanted %t, got %t",
+				tt.so.Sell, tt.so.StopRate, tt.rate, tt.want, got)
+		}
+	}
+}
+
+func TestUpdateTrailingStop(t *testing.T) {
+	// A plain stop never moves.
+	so := &db.StopOrder{Sell: true, StopRate: 100}
+	if updateTrailingStop(so, 200) || so.StopRate != 100 {
+		t.Fatalf("plain stop moved")
+	}
+
+	// A trailing sell stop follows the rate up, but not down.
+	so = &db.StopOrder{Sell: true, StopRate: 90, TrailRate: 10}
+	if updateTrailingStop(so, 100) {
+		t.Fatalf("sell stop moved without a rate increase")
+	}
+	if !updateTrailingStop(so, 120) || so.StopRate != 110 {
+		t.Fatalf("sell stop did not follow rate up. stop = %d", so.StopRate)
+	}
+	if updateTrailingStop(so, 115) || so.StopRate != 110 {
+		t.Fatalf("sell stop followed rate down. stop = %d", so.StopRate)
+	}
+	// Can't underflow.
+	so = &db.StopOrder{Sell: true, StopRate: 1, TrailRate: 10}
+	if updateTrailingStop(so, 5) {
+		t.Fatalf("sell stop moved for a rate below the trail")
+	}
+
+	// A trailing buy stop follows the rate down, but not up.
+	so = &db.StopOrder{StopRate: 110, TrailRate: 10}
+	if updateTrailingStop(so, 100) {
+		t.Fatalf("buy stop moved without a rate decrease")
+	}
+	if !updateTrailingStop(so, 80) || so.StopRate != 90 {
+		t.Fatalf("buy stop did not follow rate down. stop = %d", so.StopRate)
+	}
+	if updateTrailingStop(so, 85) || so.StopRate != 90 {
+		t.Fatalf("buy stop followed rate up. stop = %d", so.StopRate)
+	}
+}
+
+func TestStopOrder(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	rate := dcrBtcRateStep * 1000
+	newForm := func() *StopOrderForm {
+		return &StopOrderForm{
+			Trade: &TradeForm{
+				Host:    tDexHost,
+				IsLimit: true,
+				Sell:    true,
+				Base:    tUTXOAssetA.ID,
+				Quote:   tUTXOAssetB.ID,
+				Qty:     dcrBtcLotSize * 10,
+				Rate:    rate,
+			},
+			StopRate: rate + dcrBtcRateStep,
+		}
+	}
+
+	ensureErr := func(tag string, form *StopOrderForm) {
+		t.Helper()
+		if _, err := tCore.StopOrder(tPW, form); err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
+	}
+
+	form := newForm()
+	form.Trade.Qty++
+	ensureErr("bad lot size", form)
+
+	form = newForm()
+	form.Trade.Rate++
+	ensureErr("bad rate step", form)
+
+	form = newForm()
+	form.StopRate = 0
+	ensureErr("no stop rate", form)
+
+	// Trailing stop without a stop rate requires a market rate.
+	form.TrailRate = dcrBtcRateStep * 10
+	ensureErr("no market rate", form)
+
+	rig.dc.spotsMtx.Lock()
+	rig.dc.spots[tDcrBtcMktName] = &msgjson.Spot{Rate: rate}
+	rig.dc.spotsMtx.Unlock()
+
+	// Sell stop above the market rate has already triggered.
+	ensureErr("already crossed", newForm())
+
+	form = newForm()
+	form.StopRate = rate - dcrBtcRateStep
+	so, err := tCore.StopOrder(tPW, form)
+	if err != nil {
+		t.Fatalf("StopOrder error: %v", err)
+	}
+	if len(so.ID) != stopOrderIDSize || so.StopRate != rate-dcrBtcRateStep {
+		t.Fatalf("wrong stop order %+v", so)
+	}
+
+	// Trailing stop with the stop rate set from the market rate.
+	form = newForm()
+	form.StopRate = 0
+	form.TrailRate = dcrBtcRateStep * 10
+	trailing, err := tCore.StopOrder(tPW, form)
+	if err != nil {
+		t.Fatalf("StopOrder trailing error: %v", err)
+	}
+	if trailing.StopRate != rate-form.TrailRate {
+		t.Fatalf("wrong trailing stop rate %d", trailing.StopRate)
+	}
+
+	if len(rig.db.stopOrders) != 2 {
+		t.Fatalf("expected 2 stored stop orders, got %d", len(rig.db.stopOrders))
+	}
+	sos := tCore.StopOrders()
+	if len(sos) != 2 {
+		t.Fatalf("expected 2 stop orders, got %d", len(sos))
+	}
+
+	if err := tCore.CancelStopOrder(so.ID); err != nil {
+		t.Fatalf("CancelStopOrder error: %v", err)
+	}
+	err = tCore.CancelStopOrder(so.ID)
+	var cErr *Error
+	if !errors.As(err, &cErr) || *cErr.Code() != unknownOrderErr {
+		t.Fatalf("wrong error for unknown stop order: %v", err)
+	}
+	if sos = tCore.StopOrders(); len(sos) != 1 || !sos[0].ID.Equal(trailing.ID) {
+		t.Fatalf("stop order not canceled")
+	}
+	if len(rig.db.stopOrders) != 1 {
+		t.Fatalf("stop order not deleted from db")
+	}
+}
+
+func TestCheckStopOrders(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.Unlock(rig.crypter)
+
+	rate := dcrBtcRateStep * 1000
+	qty := dcrBtcLotSize * 10
+	tDcrWallet.fundingCoins = asset.Coins{&tCoin{id: encode.RandomBytes(36), val: qty * 2}}
+	tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+
+	setRate := func(r uint64) {
+		dc.spotsMtx.Lock()
+		dc.spots[tDcrBtcMktName] = &msgjson.Spot{Rate: r}
+		dc.spotsMtx.Unlock()
+	}
+
+	so := &db.StopOrder{
+		ID:        encode.RandomBytes(stopOrderIDSize),
+		Host:      tDexHost,
+		BaseID:    tUTXOAssetA.ID,
+		QuoteID:   tUTXOAssetB.ID,
+		Sell:      true,
+		IsLimit:   true,
+		Qty:       qty,
+		Rate:      rate - dcrBtcRateStep*20,
+		StopRate:  rate - dcrBtcRateStep*10,
+		TrailRate: dcrBtcRateStep * 10,
+	}
+	rig.db.UpdateStopOrder(so)
+	tCore.loadStopOrders()
+
+	// Rate rises. The trailing stop follows.
+	setRate(rate + dcrBtcRateStep*5)
+	tCore.checkStopOrders(dc, tDcrBtcMktName)
+	if sos := tCore.StopOrders(); len(sos) != 1 || sos[0].StopRate != rate-dcrBtcRateStep*5 {
+		t.Fatalf("trailing stop not updated")
+	}
+	if rig.db.stopOrders[so.ID.String()].StopRate != rate-dcrBtcRateStep*5 {
+		t.Fatalf("trailing stop not updated in db")
+	}
+
+	// Triggered, but the account is not authenticated.
+	setRate(rate - dcrBtcRateStep*10)
+	rig.acct.unauth()
+	tCore.checkStopOrders(dc, tDcrBtcMktName)
+	if len(tCore.StopOrders()) != 1 {
+		t.Fatalf("stop order triggered while not authenticated")
+	}
+
+	ch := tCore.NotificationFeed()
+	waitNote := func(wantTopic Topic) {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case n := <-ch:
+				switch n.Topic() {
+				case TopicStopOrderFailed, TopicStopOrderTriggered:
+					if n.Topic() != wantTopic {
+						t.Fatalf("wanted %s note, got %s: %s", wantTopic, n.Topic(), n.Details())
+					}
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for stop order note")
+			}
+		}
+	}
+
+	retry := func() *stopOrderRetry {
+		tCore.stopOrdersMtx.RLock()
+		defer tCore.stopOrdersMtx.RUnlock()
+		if r := tCore.stopOrderRetries[so.ID.String()]; r != nil {
+			rCopy := *r
+			return &rCopy
+		}
+		return nil
+	}
+	waitRetry := func(attempts int) {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for {
+			if r := retry(); r != nil && r.attempts == attempts && len(tCore.StopOrders()) == 1 {
+				return
+			}
+			select {
+			case <-time.After(10 * time.Millisecond):
+			case <-timeout:
+				t.Fatalf("stop order not restored after failed attempt %d", attempts)
+			}
+		}
+	}
+
+	// Triggered, but the order can't be funded. The stop order remains pending,
+	// and is not retried until the retry delay has passed.
+	rig.acct.auth(false)
+	tDcrWallet.fundingCoinErr = errors.New("test error")
+	tCore.checkStopOrders(dc, tDcrBtcMktName)
+	waitRetry(1)
+	if len(rig.db.stopOrders) != 1 {
+		t.Fatalf("stop order deleted from db after a failed placement")
+	}
+	tCore.checkStopOrders(dc, tDcrBtcMktName)
+	time.Sleep(50 * time.Millisecond)
+	if r := retry(); r == nil || r.attempts != 1 {
+		t.Fatalf("stop order placement retried before the retry delay")
+	}
+
+	// The stop order is dropped after maxStopOrderAttempts failures.
+	for i := 2; i < maxStopOrderAttempts; i++ {
+		tCore.stopOrdersMtx.Lock()
+		tCore.stopOrderRetries[so.ID.String()].next = time.Time{}
+		tCore.stopOrdersMtx.Unlock()
+		tCore.checkStopOrders(dc, tDcrBtcMktName)
+		waitRetry(i)
+	}
+	tCore.stopOrdersMtx.Lock()
+	tCore.stopOrderRetries[so.ID.String()].next = time.Time{}
+	tCore.stopOrdersMtx.Unlock()
+	tCore.checkStopOrders(dc, tDcrBtcMktName)
+	waitNote(TopicStopOrderFailed)
+	if len(tCore.StopOrders()) != 0 || len(rig.db.stopOrders) != 0 || retry() != nil {
+		t.Fatalf("stop order not dropped after %d failed attempts", maxStopOrderAttempts)
+	}
+	tDcrWallet.fundingCoinErr = nil
+
+	// The server rejects the order. The order request was sent, so the stop
+	// order is dropped without a retry.
+	rig.db.UpdateStopOrder(so)
+	tCore.loadStopOrders()
+	rig.ws.queueResponse(msgjson.LimitRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, nil, msgjson.NewError(msgjson.OrderParameterError, "test error"))
+		f(resp)
+		return nil
+	})
+	tCore.checkStopOrders(dc, tDcrBtcMktName)
+	waitNote(TopicStopOrderFailed)
+	if len(tCore.StopOrders()) != 0 || len(rig.db.stopOrders) != 0 || retry() != nil {
+		t.Fatalf("stop order not dropped after a failed order request")
+	}
+
+	rig.db.UpdateStopOrder(so)
+	tCore.loadStopOrders()
+
+	// Triggered and placed.
+	rig.ws.queueResponse(msgjson.LimitRoute, func(msg *msgjson.Message, f msgFunc) error {
+		msgOrder := new(msgjson.LimitOrder)
+		msg.Unmarshal(msgOrder)
+		f(orderResponse(msg.ID, msgOrder, convertMsgLimitOrder(msgOrder), false, false, false))
+		return nil
+	})
+	tCore.checkStopOrders(dc, tDcrBtcMktName)
+	if len(tCore.StopOrders()) != 0 {
+		t.Fatalf("stop order not triggered")
+	}
+	waitNote(TopicStopOrderTriggered)
+	if len(rig.db.stopOrders) != 0 {
+		t.Fatalf("triggered stop order not deleted from db")
+	}
+	dc.tradeMtx.RLock()
+	numTrades := len(dc.trades)
+	dc.tradeMtx.RUnlock()
+	if numTrades != 1 {
+		t.Fatalf("expected 1 trade, got %d", numTrades)
+	}
+}
@@ -149,6 +149,7 @@ type trackedTrade struct {
 	change             asset.Coin
 	changeLocked       bool
 	cancel             *trackedCancel
+	replaces           *trackedTrade // booked order being replaced, until the replacement is resolved
 	matches            map[order.MatchID]*matchTracker
 	notify             func(Notification)
 	formatDetails      func(Topic, ...interface{}) (string, string)
@@ -487,7 +488,7 @@ func (t *trackedTrade) cancelTrade(co *order.CancelOrder, preImg order.Preimage)
 }
 
 // nomatch sets the appropriate order status and returns funding coins.
-func (t *trackedTrade) nomatch(oid order.OrderID) (assetMap, error) {
+func (t *trackedTrade) nomatch(oid order.OrderID, failed bool) (assetMap, error) {
 	assets := make(assetMap)
 	// Check if this is the cancel order.
 	t.mtx.Lock()
@@ -518,7 +519,21 @@ func (t *trackedTrade) nomatch(oid order.OrderID) (assetMap, error) {
 	if t.metaData.Status != order.OrderStatusEpoch {
 		return assets, fmt.Errorf("nomatch sent for non-epoch order %s", oid)
 	}
-	if lo, ok := t.Order.(*order.LimitOrder); ok && lo.Force == order.StandingTiF {
+	if t.replaces != nil {
+		if failed {
+			// The replaced order is still in effect, and it keeps the funding
+			// coins.
+			target := t.replaces
+			t.replaces = nil
+			t.dc.log.Warnf("Replacement order %s failed. Order %s was matched first.", t.token(), target.token())
+			t.metaData.Status = order.OrderStatusExecuted
+			subject, details := t.formatDetails(TopicMissedReplace, t.token(), target.token())
+			t.notify(newOrderNote(TopicMissedReplace, subject, details, db.WarningLevel, t.coreOrderInternal()))
+			return assets, t.db.UpdateOrderStatus(t.ID(), t.metaData.Status)
+		}
+		t.completeReplacement()
+	}
+	if lo, ok := t.Order.(*order.LimitOrder); ok && lo.Force != order.ImmediateTiF {
 		t.dc.log.Infof("Standing order %s did not match and is now booked.", t.token())
 		t.metaData.Status = order.OrderStatusBooked
 		t.notify(newOrderNote(TopicOrderBooked, "", "", db.Data, t.coreOrderInternal()))
@@ -534,11 +549,43 @@ func (t *trackedTrade) nomatch(oid order.OrderID) (assetMap, error) {
 	return assets, t.db.UpdateOrderStatus(t.ID(), t.metaData.Status)
 }
 
+// completeReplacement moves the funding coins and reserves of the replaced
+// order to this replacement order, and sets the status of the replaced order
+// to canceled. The replaced order is retired with other inactive orders. This
+// method MUST be called with the trackedTrade mutex lock held for writes.
+func (t *trackedTrade) completeReplacement() {
+	target := t.replaces
+	t.replaces = nil
+
+	target.mtx.Lock()
+	t.coins, t.coinsLocked = target.coins, target.coinsLocked
+	t.change, t.changeLocked = target.change, target.changeLocked
+	t.redemptionReserves, t.redemptionLocked = target.redemptionLocked, target.redemptionLocked
+	t.refundReserves, t.refundLocked = target.refundLocked, target.refundLocked
+	target.coinsLocked, target.changeLocked = false, false
+	target.redemptionLocked, target.refundLocked = 0, 0
+	target.metaData.Status = order.OrderStatusCanceled
+	corder := target.coreOrderInternal()
+	target.mtx.Unlock()
+
+	if err := t.db.UpdateOrderStatus(target.ID(), order.OrderStatusCanceled); err != nil {
+		t.dc.log.Errorf("Error updating status in db for replaced order %v: %v", target.ID(), err)
+	}
+	t.dc.log.Infof("Order %s has been replaced by order %s.", target.token(), t.token())
+	subject, details := t.formatDetails(TopicOrderReplaced, target.token(), t.token())
+	t.notify(newOrderNote(TopicOrderReplaced, subject, details, db.Poke, corder))
+}
+
 // negotiate creates and stores matchTrackers for the []*msgjson.Match, and
 // updates (UserMatch).Filled. Match negotiation can then be progressed by
 // calling (*trackedTrade).tick when a relevant event occurs, such as a request
 // from the DEX or a tip change.
 func (t *trackedTrade) negotiate(msgMatches []*msgjson.Match) error {
+	// A replacement order that was matched has taken the place of its target.
+	if t.replaces != nil {
+		t.completeReplacement()
+	}
+
 	trade := t.Trade()
 	// Validate matches and check if a cancel match is included.
 	// Non-cancel matches should be negotiated and are added to
@@ -692,8 +739,9 @@ func (t *trackedTrade) negotiate(msgMatches []*msgjson.Match) error {
 	}
 
 	// Set the order as executed depending on type and fill.
-	if t.metaData.Status != order.OrderStatusCanceled && t.metaData.Status != order.OrderStatusRevoked {
-		if lo, ok := t.Order.(*order.LimitOrder); ok && lo.Force == order.StandingTiF && filled < trade.Quantity {
+	if t.metaData.Status != order.OrderStatusCanceled && t.metaData.Status != order.OrderStatusRevoked &&
+		t.metaData.Status != order.OrderStatusExpired {
+		if lo, ok := t.Order.(*order.LimitOrder); ok && lo.Force != order.ImmediateTiF && filled < trade.Quantity {
 			t.metaData.Status = order.OrderStatusBooked
 		} else {
 			t.metaData.Status = order.OrderStatusExecuted
@@ -963,7 +1011,10 @@ func (t *trackedTrade) isActive() bool {
 			"Order: %v, Refund coin: %v, ContractData: %x, Revoked: %v", match,
 			match.Side, match.Status, t.ID(),
 			proof.RefundCoin, proof.ContractData, proof.IsRevoked())
-		if t.matchIsActive(match) {
+		// The trade is not retired while a redemption or refund is pending,
+		// so that the fee can be bumped if the transaction is slow to be
+		// mined.
+		if t.matchIsActive(match) || proof.PendingSpend {
 			return true
 		}
 	}
@@ -1351,6 +1402,10 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 	// Make sure we have a redemption fee suggestion cached.
 	c.cacheRedemptionFeeSuggestion(t)
 
+	// Bump the fees of any redemptions or refunds that are at risk of not
+	// being mined in time.
+	c.bumpSpendFees(t)
+
 	// Check all matches and send swap, redeem or refund as necessary.
 	var sent, quoteSent, received, quoteReceived uint64
 	for _, match := range t.matches {
@@ -1484,6 +1539,155 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 	return assets, errs.ifAny()
 }
 
+// bumpSpendFees bumps the fees of this side's unconfirmed redemption and
+// refund transactions when they pay less than the current fee suggestion and
+// there is a risk of the counterparty refunding the redeemed contract. Refunds
+// are bumped as soon as their fee rate is below the fee suggestion, since the
+// lock time of the refunded contract has already expired. Matches no longer
+// have a pending spend once the transaction is mined.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) bumpSpendFees(t *trackedTrade) {
+	for _, match := range t.matches {
+		if !match.MetaData.Proof.PendingSpend {
+			continue
+		}
+		coinID, refund := t.spendCoin(match)
+		wallet := t.wallets.toWallet
+		if refund {
+			wallet = t.wallets.fromWallet
+		}
+		if !wallet.connected() {
+			continue // e.g. not yet reconnected after a restart
+		}
+		feeRate, err := wallet.bumpableFeeRate(dex.Bytes(coinID))
+		if err != nil {
+			if !errors.Is(err, asset.ErrTxConfirmed) {
+				t.dc.log.Warnf("Unable to bump the fee of %s transaction %s for match %s: %v",
+					unbip(wallet.AssetID), coinIDString(wallet.AssetID, coinID), match, err)
+			}
+			t.clearPendingSpend(match)
+			continue
+		}
+		var feeSuggestion uint64
+		if refund {
+			feeSuggestion = c.feeSuggestionAny(wallet.AssetID)
+		} else {
+			if !t.redeemedLockTimeNear(match) {
+				continue
+			}
+			feeSuggestion = atomic.LoadUint64(&t.redeemFeeSuggestion)
+		}
+		if feeSuggestion <= feeRate {
+			continue
+		}
+		t.dc.log.Infof("Bumping the fee rate of %s transaction %s for match %s from %d to %d",
+			unbip(wallet.AssetID), coinIDString(wallet.AssetID, coinID), match, feeRate, feeSuggestion)
+		if err := c.bumpSpendFee(t, match, feeSuggestion); err != nil {
+			t.dc.log.Errorf("Error bumping the fee of %s transaction %s for match %s: %v",
+				unbip(wallet.AssetID), coinIDString(wallet.AssetID, coinID), match, err)
+		}
+	}
+}
+
+// clearPendingSpend records that this side's redemption or refund for the
+// match is no longer pending, so that the trade can be retired.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (t *trackedTrade) clearPendingSpend(match *matchTracker) {
+	match.MetaData.Proof.PendingSpend = false
+	if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+		t.dc.log.Errorf("Error updating match %s in the database: %v", match, err)
+	}
+}
+
+// spendCoin returns the coin ID of this side's redemption or refund for the
+// match, and whether it is a refund.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (t *trackedTrade) spendCoin(match *matchTracker) (coinID order.CoinID, refund bool) {
+	proof := &match.MetaData.Proof
+	switch {
+	case len(proof.RefundCoin) > 0:
+		return proof.RefundCoin, true
+	case match.Side == order.Maker:
+		return proof.MakerRedeem, false
+	default:
+		return proof.TakerRedeem, false
+	}
+}
+
+// redeemedLockTimeNear checks whether less than 1/feeBumpLockTimeDivisor of
+// the lock time duration of the counterparty's contract remains, after which
+// an unconfirmed redemption of the contract is at risk of the counterparty
+// refunding it.
+func (t *trackedTrade) redeemedLockTimeNear(match *matchTracker) bool {
+	lockDur := t.lockTimeMaker
+	if match.Side == order.Maker {
+		lockDur = t.lockTimeTaker // maker redeems taker's contract
+	}
+	lockTime := match.matchTime().Add(lockDur)
+	return time.Until(lockTime) < lockDur/feeBumpLockTimeDivisor
+}
+
+// bumpSpendFee replaces this side's unconfirmed redemption or refund
+// transaction for the match with one paying feeRate. The coin IDs of all
+// matches spent by the replaced transaction are updated, and the server is
+// informed of the maker's replacement redemptions.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) bumpSpendFee(t *trackedTrade, match *matchTracker, feeRate uint64) error {
+	coinID, refund := t.spendCoin(match)
+	wallet := t.wallets.toWallet
+	if refund {
+		wallet = t.wallets.fromWallet
+	}
+	replacements, fees, err := wallet.bumpFee(dex.Bytes(coinID), feeRate)
+	if err != nil {
+		return err
+	}
+	for _, m := range t.matches {
+		oldCoinID, isRefund := t.spendCoin(m)
+		if isRefund != refund || len(oldCoinID) == 0 {
+			continue
+		}
+		newCoinID, found := replacements[dex.Bytes(oldCoinID).String()]
+		if !found {
+			continue
+		}
+		proof := &m.MetaData.Proof
+		switch {
+		case refund:
+			proof.RefundCoin = order.CoinID(newCoinID)
+		case m.Side == order.Maker:
+			proof.MakerRedeem = order.CoinID(newCoinID)
+		default:
+			proof.TakerRedeem = order.CoinID(newCoinID)
+		}
+		if err := t.db.UpdateMatch(&m.MetaMatch); err != nil {
+			t.dc.log.Errorf("Error updating match %s in the database: %v", m, err)
+		}
+		// If the server has not yet acknowledged the redemption,
+		// resendPendingRequests sends the replacement instead.
+		if !refund && m.Side == order.Maker && len(proof.Auth.RedeemSig) > 0 && !proof.IsRevoked() {
+			c.sendRedeemReplacementAsync(t, m, newCoinID, proof.Secret)
+		}
+	}
+	if refund {
+		t.metaData.RefundFeesPaid += fees
+	} else {
+		t.metaData.RedemptionFeesPaid += fees
+	}
+	if err := t.db.UpdateOrderMetaData(t.ID(), t.metaData); err != nil {
+		t.dc.log.Errorf("Error updating order metadata for order %s: %v", t.ID(), err)
+	}
+	return nil
+}
+
 // resendPendingRequests checks all matches for this order to re-attempt
 // sending the `init` or `redeem` request where necessary.
 //
@@ -1535,8 +1739,39 @@ func (t *trackedTrade) revoke() {
 
 	t.dc.log.Warnf("Revoking order %v", t.ID())
 
+	t.retireUnbooked(order.OrderStatusRevoked)
+}
+
+// pastExpiry checks whether the trade is a good-til-time order with an expiry
+// epoch before the specified epoch.
+func (t *trackedTrade) pastExpiry(epochIdx uint64) bool {
+	lo, ok := t.Order.(*order.LimitOrder)
+	return ok && lo.Force == order.GoodTilTimeTiF && epochIdx > lo.ExpiryEpoch
+}
+
+// expire sets the trade status to expired for a good-til-time order that the
+// server has removed from the book, and returns the remaining funding coins
+// and reserves. expire returns false if the order is not booked.
+func (t *trackedTrade) expire() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.metaData.Status != order.OrderStatusBooked {
+		return false
+	}
+
+	t.dc.log.Infof("Good-til-time order %v has expired", t.ID())
+
+	t.retireUnbooked(order.OrderStatusExpired)
+	return true
+}
+
+// retireUnbooked sets the status of an order that is no longer on the book,
+// returns the funding coins if no matches may require them, and unlocks the
+// reserves for the unfilled quantity. The mtx must be write-locked.
+func (t *trackedTrade) retireUnbooked(status order.OrderStatus) {
 	metaOrder := t.metaOrder()
-	metaOrder.MetaData.Status = order.OrderStatusRevoked
+	metaOrder.MetaData.Status = status
 	err := t.db.UpdateOrder(metaOrder)
 	if err != nil {
 		t.dc.log.Errorf("unable to update order: %v", err)
@@ -2116,6 +2351,7 @@ func (c *Core) redeemMatchGroup(t *trackedTrade, matches []*matchTracker, errs *
 		} else {
 			redeemNum += match.Quantity * limitMult
 		}
+		proof.PendingSpend = t.wallets.toWallet.traits.IsFeeBumper()
 		if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
 			errs.add("error storing swap details in database for match %s, coin %s: %v",
 				match, coinIDString(t.wallets.fromAsset.ID, coinID), err)
@@ -2214,6 +2450,56 @@ func (c *Core) sendRedeemAsync(t *trackedTrade, match *matchTracker, coinID, sec
 	}()
 }
 
+// sendRedeemReplacementAsync informs the server that our redemption for the
+// match was replaced, e.g. by a transaction paying a higher fee, so that the
+// server and the counterparty have the replacement coin ID. Only the maker's
+// redemption can be replaced, since the taker's redemption completes the match
+// server-side. If the taker has already redeemed, the server no longer tracks
+// the match, and there is no one left to inform.
+func (c *Core) sendRedeemReplacementAsync(t *trackedTrade, match *matchTracker, coinID, secret []byte) {
+	c.log.Infof("Notifying DEX %s of our replacement %s swap redemption %v for match %s",
+		t.dc.acct.host, t.wallets.toAsset.Symbol, coinIDString(t.wallets.toAsset.ID, coinID), match)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		msgRedeem := &msgjson.Redeem{
+			OrderID: t.ID().Bytes(),
+			MatchID: match.MatchID.Bytes(),
+			CoinID:  coinID,
+			Secret:  secret,
+		}
+		ack := new(msgjson.Acknowledgement)
+		timeout := t.broadcastTimeout() / 4
+		if timeout < time.Minute {
+			timeout = time.Minute
+		}
+		err := t.dc.signAndRequest(msgRedeem, msgjson.RedeemRoute, ack, timeout)
+		if err != nil {
+			var msgErr *msgjson.Error
+			if errors.As(err, &msgErr) && msgErr.Code == msgjson.RPCUnknownMatch {
+				c.log.Debugf("Match %s is already complete on the server. Not reporting replacement redemption.", match)
+				return
+			}
+			c.log.Errorf("Error sending 'redeem' message for replacement redemption for match %s: %v", match, err)
+			return
+		}
+		if err = t.dc.acct.checkSig(msgRedeem.Serialize(), ack.Sig); err != nil {
+			c.log.Errorf("'redeem' ack signature error for replacement redemption for match %s: %v", match, err)
+			return
+		}
+
+		t.mtx.Lock()
+		defer t.mtx.Unlock()
+		auth := &match.MetaData.Proof.Auth
+		auth.RedeemSig = ack.Sig
+		auth.RedeemStamp = uint64(time.Now().UnixMilli())
+		if err = t.db.UpdateMatch(&match.MetaMatch); err != nil {
+			c.log.Errorf("Error storing redeem ack sig in database for match %s: %v", match, err)
+		}
+	}()
+}
+
 // findMakersRedemption starts a goroutine to search for the redemption of
 // taker's contract.
 //
@@ -2341,7 +2627,14 @@ func (c *Core) refundMatches(t *trackedTrade, matches []*matchTracker) (uint64,
 			feeSuggestion = c.feeSuggestionAny(refundAsset.ID)
 		}
 
-		refundCoin, err := refundWallet.Refund(swapCoinID, contractToRefund, feeSuggestion)
+		var refundCoin dex.Bytes
+		var fees uint64
+		var err error
+		if refunder, is := refundWallet.Wallet.(asset.FeeReportingRefunder); is {
+			refundCoin, fees, err = refunder.RefundWithFees(swapCoinID, contractToRefund, feeSuggestion)
+		} else {
+			refundCoin, err = refundWallet.Refund(swapCoinID, contractToRefund, feeSuggestion)
+		}
 		if err != nil {
 			// CRITICAL - Refund must indicate if the swap is spent (i.e.
 			// redeemed already) so that as taker we will start the
@@ -2388,10 +2681,17 @@ func (c *Core) refundMatches(t *trackedTrade, matches []*matchTracker) (uint64,
 		}
 		match.MetaData.Proof.RefundCoin = []byte(refundCoin)
 		match.MetaData.Proof.SelfRevoked = true // Set match as revoked.
+		match.MetaData.Proof.PendingSpend = refundWallet.traits.IsFeeBumper()
 		err = t.db.UpdateMatch(&match.MetaMatch)
 		if err != nil {
 			errs.add("error storing match info in database: %v", err)
 		}
+		if fees > 0 {
+			t.metaData.RefundFeesPaid += fees
+			if err = t.db.UpdateOrderMetaData(t.ID(), t.metaData); err != nil {
+				errs.add("error updating order metadata in database: %v", err)
+			}
+		}
 	}
 
 	return refundedQty, errs.ifAny()
@@ -2629,8 +2929,15 @@ func (t *trackedTrade) processRedemption(msgID uint64, redemption *msgjson.Redem
 	// the order was loaded from the DB and we've already redeemed
 	// Taker's swap, the counterSwap (AuditInfo for Taker's swap) will
 	// not have been retrieved.
+	var replacement bool
 	if match.Side == order.Taker {
+		proof := &match.MetaData.Proof
 		switch {
+		case match.Status >= order.MakerRedeemed && len(proof.Auth.RedemptionSig) > 0 &&
+			len(proof.Secret) > 0 && bytes.Equal(redemption.Secret, proof.Secret):
+			// The maker replaced their redemption, e.g. with a transaction
+			// paying a higher fee.
+			replacement = true
 		case match.counterSwap == nil:
 			return errs.add("redemption received before audit request")
 		case match.Status == order.TakerSwapCast:
@@ -2660,7 +2967,12 @@ func (t *trackedTrade) processRedemption(msgID uint64, redemption *msgjson.Redem
 	match.MetaData.Proof.Auth.RedemptionSig = redemption.Sig
 	match.MetaData.Proof.Auth.RedemptionStamp = redemption.Time
 
-	if match.Side == order.Taker {
+	switch {
+	case replacement:
+		t.dc.log.Infof("Notified of maker's replacement redemption (%s: %v) for match %s",
+			t.wallets.fromAsset.Symbol, coinIDString(t.wallets.fromAsset.ID, redemption.CoinID), match)
+		match.MetaData.Proof.MakerRedeem = order.CoinID(redemption.CoinID)
+	case match.Side == order.Taker:
 		err = t.processMakersRedemption(match, redemption.CoinID, redemption.Secret)
 		if err != nil {
 			errs.addErr(err)
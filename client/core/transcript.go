@@ -0,0 +1,572 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/matcher"
+	"github.com/decred/dcrd/crypto/blake256"
+)
+
+// VerifyEpochTranscript requests the transcript of a market's epoch from the
+// server and independently verifies it. The commitment checksum and the shuffle
+// seed are recomputed from the revealed preimages, the shuffled queue order is
+// reproduced, and the matching of the queue with the recorded book orders is
+// replayed. The transcript is also checked against what this client saw live.
+// The user's own active orders in the epoch must be in the transcript with
+// their commitments and preimages, and the commitment checksum and seed must
+// match the epoch's match_proof note, if one was received. A nil error
+// indicates that the server matched the epoch's orders in the order dictated by
+// the preimages, without front-running or reordering.
+func (c *Core) VerifyEpochTranscript(host string, base, quote uint32, epochIdx uint64) (*order.EpochTranscript, error) {
+	dc, connected, err := c.dex(host)
+	if err != nil {
+		return nil, codedError(unknownDEXErr, err)
+	}
+	if !connected {
+		return nil, newError(connectionErr, "currently disconnected from %s", dc.acct.host)
+	}
+	mktID := marketName(base, quote)
+	mkt := dc.marketConfig(mktID)
+	if mkt == nil {
+		return nil, newError(marketErr, "market %s not known by %s", mktID, dc.acct.host)
+	}
+
+	wireTranscript := new(msgjson.EpochTranscript)
+	err = sendRequest(dc.WsConn, msgjson.EpochTranscriptRoute, &msgjson.EpochTranscriptRequest{
+		BaseID:   base,
+		QuoteID:  quote,
+		Epoch:    epochIdx,
+		Duration: mkt.EpochLen,
+	}, wireTranscript, DefaultResponseTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting epoch %d transcript for market %s: %w", epochIdx, mktID, err)
+	}
+	if wireTranscript.MarketID != mktID || wireTranscript.Epoch != epochIdx || wireTranscript.Duration != mkt.EpochLen {
+		return nil, fmt.Errorf("requested epoch %d:%d transcript for market %s, received epoch %d:%d for %s",
+			epochIdx, mkt.EpochLen, mktID, wireTranscript.Epoch, wireTranscript.Duration, wireTranscript.MarketID)
+	}
+	t, err := decodeWireTranscript(base, quote, wireTranscript)
+	if err != nil {
+		return nil, fmt.Errorf("invalid epoch transcript: %w", err)
+	}
+	if err = verifyEpochTranscript(t); err != nil {
+		return nil, fmt.Errorf("epoch %d transcript for market %s failed verification: %w", epochIdx, mktID, err)
+	}
+	if err = verifyTranscriptOwnOrders(t, dc.epochOwnOrders(mktID, epochIdx, mkt.EpochLen)); err != nil {
+		return nil, fmt.Errorf("epoch %d transcript for market %s does not match the user's orders: %w", epochIdx, mktID, err)
+	}
+	if book := dc.bookie(mktID); book != nil {
+		if proof := book.matchProof(epochIdx); proof != nil {
+			if !bytes.Equal(proof.CSum, t.CSum) || !bytes.Equal(proof.Seed, t.Seed) {
+				return nil, fmt.Errorf("epoch %d transcript for market %s does not match the match_proof received from the server",
+					epochIdx, mktID)
+			}
+		}
+	}
+	return t, nil
+}
+
+// transcriptOwnOrder is one of the user's orders placed in an epoch.
+type transcriptOwnOrder struct {
+	id     order.OrderID
+	commit order.Commitment
+	preImg order.Preimage
+	// csum is the commitment checksum from the server's preimage request. It
+	// is nil if the preimage was not sent, or if the client was restarted.
+	csum dex.Bytes
+}
+
+// epochOwnOrders returns the user's active orders, including cancel orders,
+// that were placed in the market's epoch.
+func (dc *dexConnection) epochOwnOrders(mktID string, epochIdx, epochLen uint64) []*transcriptOwnOrder {
+	var ords []*transcriptOwnOrder
+	for _, t := range dc.trackedTrades() {
+		if t.mktID != mktID {
+			continue
+		}
+		t.mtx.RLock()
+		if t.epochLen == epochLen && t.epochIdx() == epochIdx {
+			ords = append(ords, &transcriptOwnOrder{
+				id:     t.ID(),
+				commit: t.Commitment(),
+				preImg: t.preImg,
+				csum:   t.csum,
+			})
+		}
+		if t.cancel != nil && t.epochLen == epochLen && t.cancelEpochIdx() == epochIdx {
+			ords = append(ords, &transcriptOwnOrder{
+				id:     t.cancel.ID(),
+				commit: t.cancel.Commitment(),
+				preImg: t.cancel.preImg,
+				csum:   t.cancel.csum,
+			})
+		}
+		t.mtx.RUnlock()
+	}
+	return ords
+}
+
+// verifyTranscriptOwnOrders checks that the user's orders from the epoch are in
+// the transcript with the commitments and preimages that the user sent. An order
+// may only be recorded as a miss if its preimage was not sent.
+func verifyTranscriptOwnOrders(t *order.EpochTranscript, own []*transcriptOwnOrder) error {
+	queued := make(map[order.OrderID]int, len(t.Queue))
+	for i, o := range t.Queue {
+		queued[o.ID] = i
+	}
+	missed := make(map[order.OrderID]*order.TranscriptOrder, len(t.Misses))
+	for _, o := range t.Misses {
+		missed[o.ID] = o
+	}
+	for _, o := range own {
+		if o.csum != nil && !bytes.Equal(o.csum, t.CSum) {
+			return fmt.Errorf("commitment checksum %s does not match the checksum %s from the preimage request for order %s",
+				t.CSum, o.csum, o.id)
+		}
+		if i, found := queued[o.id]; found {
+			if t.Queue[i].Commit != o.commit {
+				return fmt.Errorf("wrong commitment for order %s", o.id)
+			}
+			if !o.preImg.IsZero() && t.Preimages[i] != o.preImg {
+				return fmt.Errorf("wrong preimage for order %s", o.id)
+			}
+			continue
+		}
+		if miss, found := missed[o.id]; found {
+			if miss.Commit != o.commit {
+				return fmt.Errorf("wrong commitment for order %s", o.id)
+			}
+			if o.csum != nil {
+				return fmt.Errorf("order %s recorded as a miss, but its preimage was sent", o.id)
+			}
+			continue
+		}
+		return fmt.Errorf("order %s is not in the transcript", o.id)
+	}
+	return nil
+}
+
+// decodeWireTranscript converts the msgjson.EpochTranscript to an
+// order.EpochTranscript.
+func decodeWireTranscript(base, quote uint32, wt *msgjson.EpochTranscript) (*order.EpochTranscript, error) {
+	t := &order.EpochTranscript{
+		Base:    base,
+		Quote:   quote,
+		Epoch:   order.EpochID{Idx: wt.Epoch, Dur: wt.Duration},
+		LotSize: wt.LotSize,
+		CSum:    wt.CSum,
+		Seed:    wt.Seed,
+	}
+	var err error
+	if t.Queue, err = decodeWireTranscriptOrders(wt.Queue); err != nil {
+		return nil, fmt.Errorf("queue: %w", err)
+	}
+	if t.Misses, err = decodeWireTranscriptOrders(wt.Misses); err != nil {
+		return nil, fmt.Errorf("misses: %w", err)
+	}
+	if t.Makers, err = decodeWireTranscriptOrders(wt.Makers); err != nil {
+		return nil, fmt.Errorf("makers: %w", err)
+	}
+	t.Preimages = make([]order.Preimage, 0, len(wt.Preimages))
+	for _, b := range wt.Preimages {
+		if len(b) != order.PreimageSize {
+			return nil, fmt.Errorf("invalid preimage length %d", len(b))
+		}
+		var pimg order.Preimage
+		copy(pimg[:], b)
+		t.Preimages = append(t.Preimages, pimg)
+	}
+	t.Matches = make([]*order.TranscriptMatch, 0, len(wt.Matches))
+	for _, wm := range wt.Matches {
+		if len(wm.TakerID) != order.OrderIDSize || len(wm.MakerID) != order.OrderIDSize {
+			return nil, fmt.Errorf("invalid match order ID length")
+		}
+		m := &order.TranscriptMatch{
+			Quantity: wm.Quantity,
+			Rate:     wm.Rate,
+		}
+		copy(m.Taker[:], wm.TakerID)
+		copy(m.Maker[:], wm.MakerID)
+		t.Matches = append(t.Matches, m)
+	}
+	return t, nil
+}
+
+func decodeWireTranscriptOrders(wireOrds []*msgjson.TranscriptOrder) ([]*order.TranscriptOrder, error) {
+	ords := make([]*order.TranscriptOrder, 0, len(wireOrds))
+	for _, wo := range wireOrds {
+		if len(wo.OrderID) != order.OrderIDSize || len(wo.Commit) != order.CommitmentSize {
+			return nil, fmt.Errorf("invalid order ID or commitment length")
+		}
+		o := &order.TranscriptOrder{
+			Quantity:   wo.Quantity,
+			Rate:       wo.Rate,
+			Filled:     wo.Filled,
+			Stamp:      int64(wo.Stamp),
+			DisplayQty: wo.DisplayQty,
+		}
+		copy(o.ID[:], wo.OrderID)
+		copy(o.Commit[:], wo.Commit)
+		switch wo.OrderType {
+		case msgjson.LimitOrderNum:
+			o.Type = order.LimitOrderType
+			switch wo.TiF {
+			case msgjson.StandingOrderNum:
+				o.Force = order.StandingTiF
+			case msgjson.ImmediateOrderNum:
+				o.Force = order.ImmediateTiF
+			case msgjson.GoodTilTimeOrderNum:
+				o.Force = order.GoodTilTimeTiF
+			default:
+				return nil, fmt.Errorf("order %s has unknown time-in-force %d", o.ID, wo.TiF)
+			}
+		case msgjson.MarketOrderNum:
+			o.Type = order.MarketOrderType
+			o.Force = order.ImmediateTiF
+		case msgjson.CancelOrderNum:
+			o.Type = order.CancelOrderType
+		default:
+			return nil, fmt.Errorf("order %s has unknown order type %d", o.ID, wo.OrderType)
+		}
+		if o.Type != order.CancelOrderType {
+			switch wo.Side {
+			case msgjson.BuyOrderNum:
+			case msgjson.SellOrderNum:
+				o.Sell = true
+			default:
+				return nil, fmt.Errorf("order %s has unknown side %d", o.ID, wo.Side)
+			}
+		}
+		if len(wo.TargetID) > 0 {
+			if len(wo.TargetID) != order.OrderIDSize {
+				return nil, fmt.Errorf("order %s has invalid target ID length %d", o.ID, len(wo.TargetID))
+			}
+			copy(o.Target[:], wo.TargetID)
+		}
+		ords = append(ords, o)
+	}
+	return ords, nil
+}
+
+// verifyEpochTranscript verifies the commitment checksum, the shuffle seed and
+// queue order, and the matches of the epoch transcript.
+//
+// The transcript orders do not include the account, funding coins, and address
+// of the orders, so the orders cannot be reconstructed with their actual IDs.
+// The shuffle is verified with the actual IDs, but matching is replayed with
+// stand-in orders, and the IDs of the resulting matches are mapped back to the
+// actual IDs. The replay book prioritizes orders at the same rate with the same
+// time priority by their actual IDs, as the server's book does.
+func verifyEpochTranscript(t *order.EpochTranscript) error {
+	if len(t.Preimages) != len(t.Queue) {
+		return fmt.Errorf("%d preimages for %d queued orders", len(t.Preimages), len(t.Queue))
+	}
+
+	// The revealed preimages must match the commitments.
+	for i, o := range t.Queue {
+		if t.Preimages[i].Commit() != o.Commit {
+			return fmt.Errorf("preimage for order %s does not match the commitment", o.ID)
+		}
+	}
+
+	// The commitment checksum is the hash of the sorted commitments of all of
+	// the epoch's orders, including misses.
+	commits := make([]order.Commitment, 0, len(t.Queue)+len(t.Misses))
+	for _, o := range append(append([]*order.TranscriptOrder{}, t.Queue...), t.Misses...) {
+		commits = append(commits, o.Commit)
+	}
+	if !bytes.Equal(transcriptCSum(commits), t.CSum) {
+		return fmt.Errorf("commitment checksum mismatch")
+	}
+
+	// The queue must be in the order dictated by the preimages.
+	oids := make([]order.OrderID, 0, len(t.Queue))
+	for _, o := range t.Queue {
+		oids = append(oids, o.ID)
+	}
+	seed, shuffled, err := matcher.ShuffleIDs(oids, t.Preimages)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(seed, t.Seed) {
+		return fmt.Errorf("shuffle seed mismatch")
+	}
+	for i, oid := range shuffled {
+		if oids[i] != oid {
+			return fmt.Errorf("queue order %d is %s, expected %s", i, oids[i], oid)
+		}
+	}
+
+	// Replay the matching of the shuffled queue with the book orders. Targeted
+	// orders are mapped to their stand-ins.
+	realIDs := make(map[order.OrderID]order.OrderID, len(t.Makers)+len(t.Queue))
+	standInIDs := make(map[order.OrderID]order.OrderID, len(t.Makers)+len(t.Queue))
+	standIn := func(o *order.TranscriptOrder) order.Order {
+		ord := transcriptStandInOrder(t.Base, t.Quote, o, standInIDs)
+		realIDs[ord.ID()] = o.ID
+		standInIDs[o.ID] = ord.ID()
+		return ord
+	}
+	bk := newReplayBook(t.LotSize, realIDs)
+	for _, o := range t.Makers {
+		lo, ok := standIn(o).(*order.LimitOrder)
+		if !ok {
+			return fmt.Errorf("book order %s is not a limit order", o.ID)
+		}
+		if !bk.Insert(lo) {
+			return fmt.Errorf("unable to book order %s", o.ID)
+		}
+	}
+	queue := make([]order.Order, 0, len(t.Queue))
+	for _, o := range t.Queue {
+		queue = append(queue, standIn(o))
+	}
+
+	var matches []*order.TranscriptMatch
+	for _, ms := range matcher.New().Replay(bk, queue) {
+		takerID := realIDs[ms.Taker.ID()]
+		for i, maker := range ms.Makers {
+			matches = append(matches, &order.TranscriptMatch{
+				Taker:    takerID,
+				Maker:    realIDs[maker.ID()],
+				Quantity: ms.Amounts[i],
+				Rate:     ms.Rates[i],
+			})
+		}
+	}
+	if len(matches) != len(t.Matches) {
+		return fmt.Errorf("replay produced %d matches, transcript has %d", len(matches), len(t.Matches))
+	}
+	for i, m := range matches {
+		if *m != *t.Matches[i] {
+			return fmt.Errorf("match %d mismatch: replay matched %s with %s for %d at rate %d, transcript has %s with %s for %d at rate %d",
+				i, m.Taker, m.Maker, m.Quantity, m.Rate, t.Matches[i].Taker, t.Matches[i].Maker, t.Matches[i].Quantity, t.Matches[i].Rate)
+		}
+	}
+	return nil
+}
+
+// replayBook is a matcher.Booker for replaying an epoch's matching with stand-in
+// orders. Like the server's book, orders are prioritized by rate, then by time
+// priority, then by order ID, but the actual order IDs are used instead of the
+// IDs of the stand-in orders. A replay involves few orders, so the best orders
+// are found by scanning.
+type replayBook struct {
+	lotSize uint64
+	realIDs map[order.OrderID]order.OrderID
+	orders  map[order.OrderID]*replayEntry
+}
+
+// replayEntry is a booked order and its time priority.
+type replayEntry struct {
+	lo    *order.LimitOrder
+	stamp int64
+}
+
+func newReplayBook(lotSize uint64, realIDs map[order.OrderID]order.OrderID) *replayBook {
+	return &replayBook{
+		lotSize: lotSize,
+		realIDs: realIDs,
+		orders:  make(map[order.OrderID]*replayEntry),
+	}
+}
+
+var _ matcher.Booker = (*replayBook)(nil)
+
+func (b *replayBook) LotSize() uint64 {
+	return b.lotSize
+}
+
+func (b *replayBook) count(sell bool) (n int) {
+	for _, e := range b.orders {
+		if e.lo.Sell == sell {
+			n++
+		}
+	}
+	return
+}
+
+func (b *replayBook) BuyCount() int {
+	return b.count(false)
+}
+
+func (b *replayBook) SellCount() int {
+	return b.count(true)
+}
+
+// realID is the actual ID of the stand-in order.
+func (b *replayBook) realID(lo *order.LimitOrder) order.OrderID {
+	oid := lo.ID()
+	if id, found := b.realIDs[oid]; found {
+		return id
+	}
+	return oid
+}
+
+// higherPriority returns true if booked order ei has a higher priority than
+// booked order ej on the same side of the book.
+func (b *replayBook) higherPriority(ei, ej *replayEntry) bool {
+	if ei.lo.Rate != ej.lo.Rate {
+		if ei.lo.Sell {
+			return ei.lo.Rate < ej.lo.Rate
+		}
+		return ei.lo.Rate > ej.lo.Rate
+	}
+	if ei.stamp != ej.stamp {
+		return ei.stamp < ej.stamp
+	}
+	idi, idj := b.realID(ei.lo), b.realID(ej.lo)
+	return bytes.Compare(idi[:], idj[:]) < 0
+}
+
+func (b *replayBook) best(sell bool) *order.LimitOrder {
+	var best *replayEntry
+	for _, e := range b.orders {
+		if e.lo.Sell == sell && (best == nil || b.higherPriority(e, best)) {
+			best = e
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.lo
+}
+
+func (b *replayBook) BestSell() *order.LimitOrder {
+	return b.best(true)
+}
+
+func (b *replayBook) BestBuy() *order.LimitOrder {
+	return b.best(false)
+}
+
+func (b *replayBook) Insert(lo *order.LimitOrder) bool {
+	oid := lo.ID()
+	if lo.Quantity%b.lotSize != 0 || b.orders[oid] != nil {
+		return false
+	}
+	b.orders[oid] = &replayEntry{lo: lo, stamp: lo.Time()}
+	return true
+}
+
+func (b *replayBook) Remove(oid order.OrderID) (*order.LimitOrder, bool) {
+	e := b.orders[oid]
+	if e == nil {
+		return nil, false
+	}
+	delete(b.orders, oid)
+	return e.lo, true
+}
+
+func (b *replayBook) Order(oid order.OrderID) *order.LimitOrder {
+	if e := b.orders[oid]; e != nil {
+		return e.lo
+	}
+	return nil
+}
+
+func (b *replayBook) Requeue(oid order.OrderID, stamp int64) bool {
+	e := b.orders[oid]
+	if e == nil {
+		return false
+	}
+	if stamp > e.stamp {
+		e.stamp = stamp
+	}
+	return true
+}
+
+func (b *replayBook) side(sell bool) []*order.LimitOrder {
+	var entries []*replayEntry
+	for _, e := range b.orders {
+		if e.lo.Sell == sell {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return b.higherPriority(entries[i], entries[j])
+	})
+	los := make([]*order.LimitOrder, 0, len(entries))
+	for _, e := range entries {
+		los = append(los, e.lo)
+	}
+	return los
+}
+
+func (b *replayBook) BuyOrders() []*order.LimitOrder {
+	return b.side(false)
+}
+
+func (b *replayBook) SellOrders() []*order.LimitOrder {
+	return b.side(true)
+}
+
+// transcriptCSum computes the commitment checksum in the same way as
+// matcher.CSum, but from the commitments alone.
+func transcriptCSum(commits []order.Commitment) []byte {
+	if len(commits) == 0 {
+		return nil
+	}
+	sort.Slice(commits, func(i, j int) bool {
+		return bytes.Compare(commits[i][:], commits[j][:]) < 0
+	})
+	hasher := blake256.New()
+	for i := range commits {
+		hasher.Write(commits[i][:])
+	}
+	return hasher.Sum(nil)
+}
+
+// transcriptStandInOrder creates an order with the matching-relevant properties
+// of the TranscriptOrder. Targeted orders with known stand-ins are mapped to the
+// stand-in ID.
+func transcriptStandInOrder(base, quote uint32, o *order.TranscriptOrder, standInIDs map[order.OrderID]order.OrderID) order.Order {
+	target := o.Target
+	if id, found := standInIDs[target]; found {
+		target = id
+	}
+	prefix := order.Prefix{
+		BaseAsset:  base,
+		QuoteAsset: quote,
+		OrderType:  o.Type,
+		ServerTime: time.UnixMilli(o.Stamp),
+		Commit:     o.Commit,
+	}
+	switch o.Type {
+	case order.CancelOrderType:
+		return &order.CancelOrder{
+			P:             prefix,
+			TargetOrderID: target,
+		}
+	case order.MarketOrderType:
+		return &order.MarketOrder{
+			P: prefix,
+			T: order.Trade{
+				Sell:     o.Sell,
+				Quantity: o.Quantity,
+				FillAmt:  o.Filled,
+			},
+		}
+	}
+	return &order.LimitOrder{
+		P: prefix,
+		T: order.Trade{
+			Sell:     o.Sell,
+			Quantity: o.Quantity,
+			FillAmt:  o.Filled,
+		},
+		Rate:       o.Rate,
+		Force:      o.Force,
+		DisplayQty: o.DisplayQty,
+		Replaces:   target,
+	}
+}
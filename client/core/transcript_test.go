@@ -0,0 +1,361 @@
+//go:build !harness
+
+package core
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/order"
+	ordertest "decred.org/dcrdex/dex/order/test"
+	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/book"
+	"decred.org/dcrdex/server/matcher"
+)
+
+func tTranscriptPreimage() (pimg order.Preimage) {
+	rand.Read(pimg[:])
+	return
+}
+
+func tTranscriptLimit(sell bool, rate, qty uint64, force order.TimeInForce, stamp int64) (*order.LimitOrder, order.Preimage) {
+	var acctID account.AccountID
+	rand.Read(acctID[:])
+	pimg := tTranscriptPreimage()
+	return &order.LimitOrder{
+		P: order.Prefix{
+			AccountID:  acctID,
+			BaseAsset:  tUTXOAssetA.ID,
+			QuoteAsset: tUTXOAssetB.ID,
+			OrderType:  order.LimitOrderType,
+			ClientTime: time.UnixMilli(stamp),
+			ServerTime: time.UnixMilli(stamp),
+			Commit:     pimg.Commit(),
+		},
+		T: order.Trade{
+			Coins:    []order.CoinID{encode.RandomBytes(36)},
+			Sell:     sell,
+			Quantity: qty,
+			Address:  ordertest.RandomAddress(),
+		},
+		Rate:  rate,
+		Force: force,
+	}, pimg
+}
+
+// tEpochTranscript matches an epoch with the server's matcher and book, and
+// builds the transcript as the server does.
+func tEpochTranscript(t *testing.T) *order.EpochTranscript {
+	const lotSize = 1e8
+	bk := book.New(lotSize, 0)
+	stamp := time.Now().UnixMilli() - 60_000
+	var bookBuys []*order.LimitOrder
+	for i, rate := range []uint64{4e6, 4.1e6, 4.1e6} {
+		lo, _ := tTranscriptLimit(false, rate, 2*lotSize, order.StandingTiF, stamp+int64(i))
+		bookBuys = append(bookBuys, lo)
+	}
+	for i, rate := range []uint64{4.3e6, 4.2e6} {
+		lo, _ := tTranscriptLimit(true, rate, 3*lotSize, order.StandingTiF, stamp+int64(i))
+		if i == 1 {
+			lo.AddFill(lotSize)
+		}
+		bk.Insert(lo)
+	}
+	for _, lo := range bookBuys {
+		bk.Insert(lo)
+	}
+
+	stamp += 30_000
+	var queue []*matcher.OrderRevealed
+	addLimit := func(sell bool, rate, qty uint64, force order.TimeInForce) {
+		lo, pimg := tTranscriptLimit(sell, rate, qty, force, stamp)
+		stamp++
+		queue = append(queue, &matcher.OrderRevealed{Order: lo, Preimage: pimg})
+	}
+	addLimit(false, 4.3e6, 3*lotSize, order.ImmediateTiF)
+	addLimit(true, 4e6, 3*lotSize, order.StandingTiF)
+	addLimit(true, 3.9e6, 2*lotSize, order.ImmediateTiF)
+	mkt, pimg := tTranscriptLimit(true, 0, lotSize, order.ImmediateTiF, stamp)
+	queue = append(queue, &matcher.OrderRevealed{
+		Order: &order.MarketOrder{
+			P: order.Prefix{
+				AccountID:  mkt.AccountID,
+				BaseAsset:  mkt.BaseAsset,
+				QuoteAsset: mkt.QuoteAsset,
+				OrderType:  order.MarketOrderType,
+				ClientTime: mkt.ClientTime,
+				ServerTime: mkt.ServerTime,
+				Commit:     mkt.Commit,
+			},
+			T: *mkt.T.Copy(),
+		},
+		Preimage: pimg,
+	})
+	cancelPimg := tTranscriptPreimage()
+	queue = append(queue, &matcher.OrderRevealed{
+		Order: &order.CancelOrder{
+			P: order.Prefix{
+				AccountID:  bookBuys[0].AccountID,
+				BaseAsset:  tUTXOAssetA.ID,
+				QuoteAsset: tUTXOAssetB.ID,
+				OrderType:  order.CancelOrderType,
+				ClientTime: time.UnixMilli(stamp),
+				ServerTime: time.UnixMilli(stamp),
+				Commit:     cancelPimg.Commit(),
+			},
+			TargetOrderID: bookBuys[0].ID(),
+		},
+		Preimage: cancelPimg,
+	})
+	miss, _ := tTranscriptLimit(false, 4e6, lotSize, order.StandingTiF, stamp+1)
+
+	tr := tMatchTranscript(t, bk, queue, miss)
+	if len(tr.Matches) < 4 {
+		t.Fatalf("expected at least 4 matches, got %d", len(tr.Matches))
+	}
+	return tr
+}
+
+// tMatchTranscript matches the queue with the book, and builds the transcript
+// as the server does.
+func tMatchTranscript(t *testing.T, bk *book.Book, queue []*matcher.OrderRevealed, misses ...order.Order) *order.EpochTranscript {
+	t.Helper()
+
+	// Record the book orders before matching.
+	var makers []*order.TranscriptOrder
+	for _, lo := range append(bk.SellOrders(), bk.BuyOrders()...) {
+		makers = append(makers, order.NewTranscriptOrder(lo))
+	}
+
+	queueRecords := make(map[order.OrderID]*order.TranscriptOrder, len(queue))
+	epochOrders := append([]order.Order{}, misses...)
+	for _, or := range queue {
+		queueRecords[or.Order.ID()] = order.NewTranscriptOrder(or.Order)
+		epochOrders = append(epochOrders, or.Order)
+	}
+	cSum := matcher.CSum(epochOrders)
+
+	seed, matchSets, _, _, _, _, _, _, _, _, _ := matcher.New().Match(bk, queue)
+
+	tr := &order.EpochTranscript{
+		Base:    tUTXOAssetA.ID,
+		Quote:   tUTXOAssetB.ID,
+		Epoch:   order.EpochID{Idx: 100, Dur: 60_000},
+		LotSize: bk.LotSize(),
+		CSum:    cSum,
+		Seed:    seed,
+		Makers:  makers,
+	}
+	for _, miss := range misses {
+		tr.Misses = append(tr.Misses, order.NewTranscriptOrder(miss))
+	}
+	for _, or := range queue {
+		tr.Queue = append(tr.Queue, queueRecords[or.Order.ID()])
+		tr.Preimages = append(tr.Preimages, or.Preimage)
+	}
+	for _, ms := range matchSets {
+		for i, maker := range ms.Makers {
+			tr.Matches = append(tr.Matches, &order.TranscriptMatch{
+				Taker:    ms.Taker.ID(),
+				Maker:    maker.ID(),
+				Quantity: ms.Amounts[i],
+				Rate:     ms.Rates[i],
+			})
+		}
+	}
+	return tr
+}
+
+func TestVerifyEpochTranscript(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(tr *order.EpochTranscript)
+		wantErr bool
+	}{
+		{
+			name:   "ok",
+			tamper: func(tr *order.EpochTranscript) {},
+		},
+		{
+			name: "reordered queue",
+			tamper: func(tr *order.EpochTranscript) {
+				tr.Queue[0], tr.Queue[1] = tr.Queue[1], tr.Queue[0]
+				tr.Preimages[0], tr.Preimages[1] = tr.Preimages[1], tr.Preimages[0]
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong preimage",
+			tamper: func(tr *order.EpochTranscript) {
+				tr.Preimages[2] = tTranscriptPreimage()
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong seed",
+			tamper: func(tr *order.EpochTranscript) {
+				tr.Seed = tr.CSum
+			},
+			wantErr: true,
+		},
+		{
+			name: "omitted miss",
+			tamper: func(tr *order.EpochTranscript) {
+				tr.Misses = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong match quantity",
+			tamper: func(tr *order.EpochTranscript) {
+				tr.Matches[0].Quantity += tr.LotSize
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong match maker",
+			tamper: func(tr *order.EpochTranscript) {
+				tr.Matches[0].Maker = ordertest.RandomOrderID()
+			},
+			wantErr: true,
+		},
+		{
+			name: "omitted match",
+			tamper: func(tr *order.EpochTranscript) {
+				tr.Matches = tr.Matches[1:]
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong maker fills",
+			tamper: func(tr *order.EpochTranscript) {
+				for _, m := range tr.Makers {
+					m.Filled = m.Quantity - tr.LotSize
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tr := tEpochTranscript(t)
+		tt.tamper(tr)
+		err := verifyEpochTranscript(tr)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: wantErr = %t, err = %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestVerifyEpochTranscriptTimeTie(t *testing.T) {
+	// Book orders at the same rate with the same time priority are prioritized
+	// by order ID. Repeat so that both orderings of the IDs are likely tested.
+	const lotSize = 1e8
+	stamp := time.Now().UnixMilli() - 60_000
+	for i := 0; i < 20; i++ {
+		bk := book.New(lotSize, 0)
+		for j := 0; j < 2; j++ {
+			lo, _ := tTranscriptLimit(true, 4e6, lotSize, order.StandingTiF, stamp)
+			bk.Insert(lo)
+		}
+		buy, pimg := tTranscriptLimit(false, 4e6, lotSize, order.ImmediateTiF, stamp+30_000)
+		queue := []*matcher.OrderRevealed{{Order: buy, Preimage: pimg}}
+		tr := tMatchTranscript(t, bk, queue)
+		if len(tr.Matches) != 1 {
+			t.Fatalf("expected 1 match, got %d", len(tr.Matches))
+		}
+		if err := verifyEpochTranscript(tr); err != nil {
+			t.Fatalf("verification failed for a time tie: %v", err)
+		}
+	}
+}
+
+func TestVerifyTranscriptOwnOrders(t *testing.T) {
+	tr := tEpochTranscript(t)
+	queued := tr.Queue[1]
+	miss := tr.Misses[0]
+	ownQueued := func() *transcriptOwnOrder {
+		return &transcriptOwnOrder{
+			id:     queued.ID,
+			commit: queued.Commit,
+			preImg: tr.Preimages[1],
+			csum:   tr.CSum,
+		}
+	}
+	ownMiss := func() *transcriptOwnOrder {
+		return &transcriptOwnOrder{
+			id:     miss.ID,
+			commit: miss.Commit,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		own     func() *transcriptOwnOrder
+		wantErr bool
+	}{
+		{
+			name: "queued",
+			own:  ownQueued,
+		},
+		{
+			name: "missed without preimage",
+			own:  ownMiss,
+		},
+		{
+			name: "missed with preimage sent",
+			own: func() *transcriptOwnOrder {
+				o := ownMiss()
+				o.csum = tr.CSum
+				return o
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong preimage",
+			own: func() *transcriptOwnOrder {
+				o := ownQueued()
+				o.preImg = tTranscriptPreimage()
+				return o
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong commitment",
+			own: func() *transcriptOwnOrder {
+				o := ownQueued()
+				pimg := tTranscriptPreimage()
+				o.commit = pimg.Commit()
+				return o
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong checksum",
+			own: func() *transcriptOwnOrder {
+				o := ownQueued()
+				o.csum = encode.RandomBytes(32)
+				return o
+			},
+			wantErr: true,
+		},
+		{
+			name: "omitted",
+			own: func() *transcriptOwnOrder {
+				o := ownQueued()
+				o.id = ordertest.RandomOrderID()
+				return o
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		err := verifyTranscriptOwnOrders(tr, []*transcriptOwnOrder{tt.own()})
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: wantErr = %t, err = %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
@@ -31,6 +31,11 @@ import (
 // hdkeychain.ExtendedKey.
 var HDKeyPurpose uint32 = hdkeychain.HardenedKeyStart + 0x646578 // ASCII "dex"
 
+// HDKeyPurposeBonds is the purpose field of the extended key from which
+// fidelity bond keys are derived. Bond keys are derived from the app seed, so
+// bonds may be refunded even if the database is lost.
+var HDKeyPurposeBonds uint32 = hdkeychain.HardenedKeyStart + 0x626f6e64 // ASCII "bond"
+
 // errorSet is a slice of orders with a prefix prepended to the Error output.
 type errorSet struct {
 	prefix string
@@ -80,6 +85,15 @@ type WalletForm struct {
 	Type    string
 }
 
+// WalletTransaction is a transaction from a wallet's transaction history, with
+// the IDs of any matches that the transaction was sent for.
+type WalletTransaction struct {
+	*asset.WalletTransaction
+	// MatchIDs are the IDs of the matches with swaps initiated, redeemed or
+	// refunded by the transaction. Only active trades are searched.
+	MatchIDs []dex.Bytes `json:"matchIDs,omitempty"`
+}
+
 // WalletBalance is an exchange wallet's balance which includes contractlocked
 // amounts in addition to other balance details stored in db.
 type WalletBalance struct {
@@ -139,6 +153,32 @@ type RegisterForm struct {
 	Cert interface{} `json:"cert"`
 }
 
+// PostBondForm is information necessary to post a new bond for a new or
+// existing DEX account at the specified DEX address.
+type PostBondForm struct {
+	Addr    string           `json:"host"`
+	AppPass encode.PassBytes `json:"appPass"`
+	Asset   *uint32          `json:"assetID,omitempty"` // do not default to 0
+	Bond    uint64           `json:"bond"`
+	// LockTime is the bond's lock time in unix seconds. If zero, a lock time
+	// of twice the DEX's bond expiry duration from now is used.
+	LockTime uint64 `json:"lockTime"`
+	// Cert can be a string, which is interpreted as a filepath, or a []byte,
+	// which is interpreted as the file contents of the certificate. Cert is
+	// only needed if the account is new.
+	Cert interface{} `json:"cert"`
+}
+
+// BondOptionsForm is used from the settings page to change the auto-bond
+// maintenance settings for a DEX.
+type BondOptionsForm struct {
+	Addr string `json:"host"`
+	// TargetTier is the tier to maintain by automatically posting new bonds
+	// as existing bonds expire. Zero disables bond renewal.
+	TargetTier *uint64 `json:"targetTier,omitempty"`
+	BondAsset  *uint32 `json:"bondAsset,omitempty"`
+}
+
 // Match represents a match on an order. An order may have many matches.
 type Match struct {
 	MatchID       dex.Bytes         `json:"matchID"`
@@ -307,6 +347,9 @@ type Order struct {
 	AccelerationCoins []*Coin           `json:"accelerationCoins"`
 	Rate              uint64            `json:"rate"`          // limit only
 	TimeInForce       order.TimeInForce `json:"tif"`           // limit only
+	ExpiryEpoch       uint64            `json:"expiryEpoch"`   // good-til-time only
+	DisplayQty        uint64            `json:"displayQty"`    // iceberg only
+	Visible           uint64            `json:"visible"`       // limit only, remaining qty displayed on the book
 	TargetOrderID     dex.Bytes         `json:"targetOrderID"` // cancel only
 }
 
@@ -314,6 +357,7 @@ type Order struct {
 type FeeBreakdown struct {
 	Swap       uint64 `json:"swap"`
 	Redemption uint64 `json:"redemption"`
+	Refund     uint64 `json:"refund"`
 }
 
 // coreOrderFromTrade constructs an *Order from the supplied limit or market
@@ -322,12 +366,15 @@ func coreOrderFromTrade(ord order.Order, metaData *db.OrderMetaData) *Order {
 	prefix, trade := ord.Prefix(), ord.Trade()
 	baseID, quoteID := ord.Base(), ord.Quote()
 
-	var rate uint64
+	var rate, expiryEpoch, displayQty, visible uint64
 	var tif order.TimeInForce
 	switch ot := ord.(type) {
 	case *order.LimitOrder:
 		rate = ot.Rate
 		tif = ot.Force
+		expiryEpoch = ot.ExpiryEpoch
+		displayQty = ot.DisplayQty
+		visible = ot.Visible()
 	case *order.CancelOrder:
 		return &Order{
 			Host:          metaData.Host,
@@ -389,11 +436,15 @@ func coreOrderFromTrade(ord order.Order, metaData *db.OrderMetaData) *Order {
 		Sell:        trade.Sell,
 		Filled:      trade.Filled(),
 		TimeInForce: tif,
+		ExpiryEpoch: expiryEpoch,
+		DisplayQty:  displayQty,
+		Visible:     visible,
 		Canceled:    canceled,
 		Cancelling:  cancelling,
 		FeesPaid: &FeeBreakdown{
 			Swap:       metaData.SwapFeesPaid,
 			Redemption: metaData.RedemptionFeesPaid,
+			Refund:     metaData.RefundFeesPaid,
 		},
 		FundingCoins:      fundingCoins,
 		AccelerationCoins: accelerationCoins,
@@ -492,6 +543,24 @@ type FeeAsset struct {
 	Amt   uint64 `json:"amount"`
 }
 
+// BondAsset has an analogous msgjson type for server providing supported
+// fidelity bond assets. Amt is the bond amount for one tier.
+type BondAsset struct {
+	Version uint16 `json:"ver"`
+	ID      uint32 `json:"id"`
+	Confs   uint32 `json:"confs"`
+	Amt     uint64 `json:"amount"`
+}
+
+// PendingBondState conveys a pending bond's asset and current confirmation
+// count.
+type PendingBondState struct {
+	Symbol  string `json:"symbol"`
+	AssetID uint32 `json:"assetID"`
+	CoinID  string `json:"coinID"`
+	Confs   uint32 `json:"confs"`
+}
+
 // PendingFeeState conveys a pending registration fee's asset and current
 // confirmation count.
 type PendingFeeState struct {
@@ -511,6 +580,12 @@ type Exchange struct {
 	RegFees          map[string]*FeeAsset   `json:"regFees"`
 	PendingFee       *PendingFeeState       `json:"pendingFee,omitempty"`
 	CandleDurs       []string               `json:"candleDurs"`
+	BondAssets       map[string]*BondAsset  `json:"bondAssets,omitempty"`
+	BondExpiry       uint64                 `json:"bondExpiry,omitempty"` // seconds
+	Tier             int64                  `json:"tier"`
+	TargetTier       uint64                 `json:"targetTier"`
+	BondAssetID      uint32                 `json:"bondAssetID"`
+	PendingBonds     []*PendingBondState    `json:"pendingBonds,omitempty"`
 }
 
 // newDisplayID creates a display-friendly market ID for a base/quote ID pair.
@@ -534,6 +609,10 @@ type MiniOrder struct {
 	Epoch     uint64  `json:"epoch,omitempty"`
 	Sell      bool    `json:"sell"`
 	Token     string  `json:"token"`
+	// Hidden is the hidden reserve of the user's own iceberg orders, which is
+	// not displayed to other users.
+	Hidden       float64 `json:"hidden,omitempty"`
+	HiddenAtomic uint64  `json:"hiddenAtomic,omitempty"`
 }
 
 // RemainderUpdate is an update to the quantity for an order on the order book.
@@ -542,6 +621,9 @@ type RemainderUpdate struct {
 	Token     string  `json:"token"`
 	Qty       float64 `json:"qty"`
 	QtyAtomic uint64  `json:"qtyAtomic"`
+	// Hidden is the hidden reserve of the user's own iceberg orders.
+	Hidden       float64 `json:"hidden,omitempty"`
+	HiddenAtomic uint64  `json:"hiddenAtomic,omitempty"`
 }
 
 // OrderBook represents an order book, which are sorted buys and sells, and
@@ -609,11 +691,24 @@ type dexAccount struct {
 	isPaid      bool // feeCoin fully confirmed, ready to trade
 	isAuthed    bool
 	isSuspended bool
+	// tier is the account tier reported by the server. It is the sum of the
+	// strengths of the active bonds, plus one if the legacy fee was paid.
+	tier int64
+	// targetTier and bondAsset are the options for automatic bond renewal.
+	targetTier uint64
+	bondAsset  uint32
+	// bonds are the active bonds that the server has accepted. pendingBonds
+	// are broadcast, but not yet confirmed or accepted by the server.
+	// expiredBonds no longer count toward the tier, and are awaiting refund
+	// once their lock time has passed.
+	bonds        []*db.Bond
+	pendingBonds []*db.Bond
+	expiredBonds []*db.Bond
 }
 
 // newDEXAccount is a constructor for a new *dexAccount.
 func newDEXAccount(acctInfo *db.AccountInfo) *dexAccount {
-	return &dexAccount{
+	acct := &dexAccount{
 		host:       acctInfo.Host,
 		encKey:     acctInfo.EncKey(),
 		dexPubKey:  acctInfo.DEXPubKey,
@@ -621,8 +716,20 @@ func newDEXAccount(acctInfo *db.AccountInfo) *dexAccount {
 		feeAssetID: acctInfo.FeeAssetID,
 		feeCoin:    acctInfo.FeeCoin,
 		cert:       acctInfo.Cert,
-		// isSuspended is determined on connect, not stored
+		targetTier: acctInfo.TargetTier,
+		bondAsset:  acctInfo.BondAssetID,
+		// isSuspended and tier are determined on connect, not stored
 	}
+	// Bonds that were accepted by the server are considered active until the
+	// bond maintenance loop sees that they have expired.
+	for _, bond := range acctInfo.Bonds {
+		if bond.Confirmed {
+			acct.bonds = append(acct.bonds, bond)
+		} else {
+			acct.pendingBonds = append(acct.pendingBonds, bond)
+		}
+	}
+	return acct
 }
 
 // ID returns the account ID.
@@ -787,6 +894,52 @@ func (a *dexAccount) markFeePaid() {
 	a.authMtx.Unlock()
 }
 
+// bondTier returns the account tier last reported by the server.
+func (a *dexAccount) bondTier() int64 {
+	a.authMtx.RLock()
+	defer a.authMtx.RUnlock()
+	return a.tier
+}
+
+// setTier sets the account tier. A tier less than one means the account may
+// not place new orders.
+func (a *dexAccount) setTier(tier int64) {
+	a.authMtx.Lock()
+	a.tier = tier
+	a.authMtx.Unlock()
+}
+
+// hasBonds checks if the account has any bonds that have not been refunded,
+// including pending and expired bonds.
+func (a *dexAccount) hasBonds() bool {
+	a.authMtx.RLock()
+	defer a.authMtx.RUnlock()
+	return len(a.bonds)+len(a.pendingBonds)+len(a.expiredBonds) > 0
+}
+
+// hasActiveBonds checks if the account has any bonds that have been accepted
+// by the server and have not yet expired.
+func (a *dexAccount) hasActiveBonds() bool {
+	a.authMtx.RLock()
+	defer a.authMtx.RUnlock()
+	return len(a.bonds) > 0
+}
+
+// hasUnrefundedBonds checks if the account has any bonds for the specified
+// asset that have not been refunded.
+func (a *dexAccount) hasUnrefundedBonds(assetID uint32) bool {
+	a.authMtx.RLock()
+	defer a.authMtx.RUnlock()
+	for _, bonds := range [][]*db.Bond{a.bonds, a.pendingBonds, a.expiredBonds} {
+		for _, bond := range bonds {
+			if bond.AssetID == assetID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // sign uses the account private key to sign the message. If the account is
 // locked, an error will be returned.
 func (a *dexAccount) sign(msg []byte) ([]byte, error) {
@@ -805,15 +958,102 @@ func (a *dexAccount) checkSig(msg []byte, sig []byte) error {
 
 // TradeForm is used to place a market or limit order
 type TradeForm struct {
-	Host    string            `json:"host"`
-	IsLimit bool              `json:"isLimit"`
-	Sell    bool              `json:"sell"`
-	Base    uint32            `json:"base"`
-	Quote   uint32            `json:"quote"`
-	Qty     uint64            `json:"qty"`
-	Rate    uint64            `json:"rate"`
-	TifNow  bool              `json:"tifnow"`
-	Options map[string]string `json:"options"`
+	Host    string `json:"host"`
+	IsLimit bool   `json:"isLimit"`
+	Sell    bool   `json:"sell"`
+	Base    uint32 `json:"base"`
+	Quote   uint32 `json:"quote"`
+	Qty     uint64 `json:"qty"`
+	Rate    uint64 `json:"rate"`
+	TifNow  bool   `json:"tifnow"`
+	// Expiry is the time, in unix milliseconds, after which a standing limit
+	// order is removed from the book by the server. The order remains on the
+	// book until the end of the epoch that includes the expiry time. Zero for
+	// an order that stays booked until it is filled or canceled.
+	Expiry uint64 `json:"expiry,omitempty"`
+	// DisplayQty is the quantity of a standing limit order that is displayed
+	// on the book. The rest of the order is held in reserve, and replenishes
+	// the displayed quantity as it is filled. DisplayQty must be a multiple of
+	// the lot size, and less than Qty. Zero to display the full quantity.
+	DisplayQty uint64            `json:"displayQty,omitempty"`
+	Options    map[string]string `json:"options"`
+	// Coins are the IDs of coins selected by the user to fund the order. All
+	// of the coins are used, and the wallet selects no others. Empty to let
+	// the wallet choose. The funding wallet must support coin control.
+	Coins []dex.Bytes `json:"coins,omitempty"`
+}
+
+// QtyRate is the quantity and rate of one order in a MultiTradeForm.
+type QtyRate struct {
+	Qty  uint64 `json:"qty"`
+	Rate uint64 `json:"rate"`
+}
+
+// MultiTradeForm is used to place a batch of standing limit orders on the same
+// side of a market. The orders are funded together and are accepted or
+// rejected by the server as a group.
+type MultiTradeForm struct {
+	Host       string            `json:"host"`
+	Sell       bool              `json:"sell"`
+	Base       uint32            `json:"base"`
+	Quote      uint32            `json:"quote"`
+	Placements []*QtyRate        `json:"placements"`
+	Options    map[string]string `json:"options"`
+}
+
+// Trade export formats.
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// ExportTradesForm selects the matches to export with ExportTrades.
+type ExportTradesForm struct {
+	// Host limits the export to a single DEX host. An empty Host exports
+	// the market's matches from all hosts.
+	Host  string `json:"host"`
+	Base  uint32 `json:"base"`
+	Quote uint32 `json:"quote"`
+	// Since and Until bound the match time, in milliseconds since the unix
+	// epoch. A zero value is unbounded. Until is exclusive.
+	Since  uint64 `json:"since"`
+	Until  uint64 `json:"until"`
+	Format string `json:"format"` // ExportFormatCSV or ExportFormatJSON
+}
+
+// TradeRecord is the accounting record of a single match. Quantities, fees,
+// and profit/loss are in atomic units. Qty is in units of the base asset, and
+// QuoteQty, CostBasis, and ProfitLoss are in units of the quote asset. Fees
+// are in units of the asset that paid them, which is the parent asset for a
+// token. Fees are allocated to the order's matches in proportion to quantity.
+type TradeRecord struct {
+	Stamp            uint64    `json:"stamp"`
+	Host             string    `json:"host"`
+	Market           string    `json:"market"`
+	OrderID          dex.Bytes `json:"orderID"`
+	MatchID          dex.Bytes `json:"matchID"`
+	Sell             bool      `json:"sell"`
+	Side             string    `json:"side"`
+	CounterpartySide string    `json:"counterpartySide"`
+	Status           string    `json:"status"`
+	Refunded         bool      `json:"refunded"`
+	Qty              uint64    `json:"qty"`
+	Rate             uint64    `json:"rate"`
+	QuoteQty         uint64    `json:"quoteQty"`
+	SwapFees         uint64    `json:"swapFees"`
+	RedeemFees       uint64    `json:"redeemFees"`
+	RefundFees       uint64    `json:"refundFees"`
+	// Proceeds, CostBasis, UnknownBasisQty, and ProfitLoss are set for settled
+	// sells. Proceeds is QuoteQty less the match's swap and redeem fees. The
+	// cost basis is the first-in-first-out cost of the base asset sold, from
+	// the settled buys on the market, including their fees. Fees paid in an
+	// asset other than the base or quote asset are not included. Base asset
+	// sold in excess of the prior buys is reported as UnknownBasisQty, and the
+	// proceeds from it are not included in ProfitLoss.
+	Proceeds        uint64 `json:"proceeds"`
+	CostBasis       uint64 `json:"costBasis"`
+	UnknownBasisQty uint64 `json:"unknownBasisQty"`
+	ProfitLoss      int64  `json:"profitLoss"`
 }
 
 // marketName is a string ID constructed from the asset IDs.
@@ -864,6 +1104,12 @@ type RegisterResult struct {
 	ReqConfirms uint16 `json:"reqConfirms"`
 }
 
+// PostBondResult holds the data returned from PostBond.
+type PostBondResult struct {
+	BondID      string `json:"bondID"`
+	ReqConfirms uint16 `json:"reqConfirms"`
+}
+
 // OrderFilter is almost the same as db.OrderFilter, except the Offset order ID
 // is a dex.Bytes instead of a order.OrderID.
 type OrderFilter struct {
@@ -913,6 +1159,21 @@ type MaxOrderEstimate struct {
 type OrderEstimate struct {
 	Swap   *asset.PreSwap   `json:"swap"`
 	Redeem *asset.PreRedeem `json:"redeem"`
+	// OracleCheck is only set for limit orders when a price oracle is
+	// configured and has a price for the market.
+	OracleCheck *OracleCheck `json:"oracleCheck,omitempty"`
+}
+
+// OracleCheck compares a limit order's rate to the price oracle. Rates are
+// conventional.
+type OracleCheck struct {
+	OraclePrice float64 `json:"oraclePrice"`
+	Rate        float64 `json:"rate"`
+	// Deviation is the fractional difference of the order rate from the
+	// oracle price. A negative value indicates a rate below the oracle price.
+	Deviation float64 `json:"deviation"`
+	// Warning is true if the deviation exceeds the configured threshold.
+	Warning bool `json:"warning"`
 }
 
 // PreAccelerate gives information that the user can use to decide on
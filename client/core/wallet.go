@@ -300,6 +300,16 @@ func (w *xcWallet) rescan(ctx context.Context) error {
 	return rescanner.Rescan(ctx)
 }
 
+// txHistory returns the wallet's transaction history if the asset.Wallet
+// implementation is a WalletHistorian.
+func (w *xcWallet) txHistory(n int, refID *string) ([]*asset.WalletTransaction, error) {
+	historian, ok := w.Wallet.(asset.WalletHistorian)
+	if !ok {
+		return nil, errors.New("wallet does not support transaction history")
+	}
+	return historian.TxHistory(n, refID)
+}
+
 // logFilePath returns the path of the wallet's log file if the
 // asset.Wallet implementation is a LogFiler.
 func (w *xcWallet) logFilePath() (string, error) {
@@ -342,6 +352,46 @@ func (w *xcWallet) preAccelerate(swapCoins, accelerationCoins []dex.Bytes, chang
 	return accelerator.PreAccelerate(swapCoins, accelerationCoins, changeCoin, requiredForRemainingSwaps, feeSuggestion)
 }
 
+// bumpableFeeRate returns the fee rate of an unconfirmed redemption or refund
+// transaction if the wallet is a FeeBumper.
+func (w *xcWallet) bumpableFeeRate(coinID dex.Bytes) (uint64, error) {
+	bumper, ok := w.Wallet.(asset.FeeBumper)
+	if !ok {
+		return 0, errors.New("wallet does not support fee bumping")
+	}
+	return bumper.BumpableFeeRate(coinID)
+}
+
+// bumpFee replaces an unconfirmed redemption or refund transaction with one
+// paying a higher fee rate if the wallet is a FeeBumper.
+func (w *xcWallet) bumpFee(coinID dex.Bytes, newFeeRate uint64) (map[string]dex.Bytes, uint64, error) {
+	bumper, ok := w.Wallet.(asset.FeeBumper)
+	if !ok {
+		return nil, 0, errors.New("wallet does not support fee bumping")
+	}
+	return bumper.BumpFee(coinID, newFeeRate)
+}
+
+// listUnspent lists the wallet's spendable outputs if the asset.Wallet
+// implementation is a CoinController.
+func (w *xcWallet) listUnspent() ([]*asset.UnspentCoin, error) {
+	controller, ok := w.Wallet.(asset.CoinController)
+	if !ok {
+		return nil, errors.New("wallet does not support coin control")
+	}
+	return controller.ListUnspent()
+}
+
+// sendWithCoins sends the value to the address, spending the selected coins,
+// if the asset.Wallet implementation is a CoinController.
+func (w *xcWallet) sendWithCoins(address string, value, feeRate uint64, coinIDs []dex.Bytes) (asset.Coin, error) {
+	controller, ok := w.Wallet.(asset.CoinController)
+	if !ok {
+		return nil, errors.New("wallet does not support coin control")
+	}
+	return controller.SendWithCoins(address, value, feeRate, coinIDs)
+}
+
 // swapConfirmations calls (asset.Wallet).SwapConfirmations with a timeout
 // Context. If the coin cannot be located, an asset.CoinNotFoundError is
 // returned. If the coin is located, but recognized as spent, no error is
@@ -355,3 +405,9 @@ func (w *xcWallet) feeRater() (asset.FeeRater, bool) {
 	rater, is := w.Wallet.(asset.FeeRater)
 	return rater, is
 }
+
+// bonder is identical to calling w.Wallet.(asset.Bonder).
+func (w *xcWallet) bonder() (asset.Bonder, bool) {
+	bonder, is := w.Wallet.(asset.Bonder)
+	return bonder, is
+}
@@ -17,6 +17,7 @@ import (
 	"decred.org/dcrdex/client/db"
 	dexdb "decred.org/dcrdex/client/db"
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/candles"
 	"decred.org/dcrdex/dex/config"
 	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/encrypt"
@@ -87,6 +88,7 @@ var (
 	maxFeeRateKey          = []byte("maxFeeRate")
 	redeemMaxFeeRateKey    = []byte("redeemMaxFeeRate")
 	redemptionFeesKey      = []byte("redeemFees")
+	refundFeesKey          = []byte("refundFees")
 	accelerationsKey       = []byte("accelerations")
 	typeKey                = []byte("type")
 	credentialsBucket      = []byte("credentials")
@@ -101,6 +103,13 @@ var (
 	optionsKey             = []byte("options")
 	redemptionReservesKey  = []byte("redemptionReservesKey")
 	refundReservesKey      = []byte("refundReservesKey")
+	bondsSubBucket         = []byte("bonds") // sub bucket of accounts
+	bondKey                = []byte("bond")
+	confirmedKey           = []byte("confirmed")
+	refundedKey            = []byte("refunded")
+	bondIndexesBucket      = []byte("bondIndexes")
+	stopOrdersBucket       = []byte("stopOrders")
+	candlesBucket          = []byte("candles")
 	byteTrue               = encode.ByteTrue
 	backupDir              = "backup"
 )
@@ -136,6 +145,7 @@ func NewDB(dbPath string, logger dex.Logger) (dexdb.DB, error) {
 		activeOrdersBucket, archivedOrdersBucket,
 		activeMatchesBucket, archivedMatchesBucket,
 		walletsBucket, notesBucket, credentialsBucket,
+		bondIndexesBucket, stopOrdersBucket, candlesBucket,
 	}); err != nil {
 		return nil, err
 	}
@@ -462,6 +472,10 @@ func (db *BoltDB) Accounts() ([]*dexdb.AccountInfo, error) {
 				return err
 			}
 			acctInfo.Paid = len(acct.Get(feeProofKey)) > 0
+			acctInfo.Bonds, err = loadBonds(acct)
+			if err != nil {
+				return err
+			}
 			accounts = append(accounts, acctInfo)
 		}
 		return nil
@@ -487,7 +501,33 @@ func (db *BoltDB) Account(url string) (*dexdb.AccountInfo, error) {
 			return err
 		}
 		acctInfo.Paid = len(acct.Get(feeProofKey)) > 0
+		acctInfo.Bonds, err = loadBonds(acct)
+		return err
+	})
+}
 
+// loadBonds loads the unrefunded bonds from the account bucket's bonds
+// sub-bucket.
+func loadBonds(acct *bbolt.Bucket) ([]*dexdb.Bond, error) {
+	bondsBkt := acct.Bucket(bondsSubBucket)
+	if bondsBkt == nil {
+		return nil, nil // no bonds, OK for legacy fee-paid accounts
+	}
+	var bonds []*dexdb.Bond
+	return bonds, bondsBkt.ForEach(func(bondUID, _ []byte) error {
+		bondBkt := bondsBkt.Bucket(bondUID)
+		if bondBkt == nil {
+			return fmt.Errorf("bond bucket %x value not a nested bucket", bondUID)
+		}
+		if bEqual(bondBkt.Get(refundedKey), byteTrue) {
+			return nil
+		}
+		bond, err := dexdb.DecodeBond(getCopy(bondBkt, bondKey))
+		if err != nil {
+			return err
+		}
+		bond.Confirmed = bEqual(bondBkt.Get(confirmedKey), byteTrue)
+		bonds = append(bonds, bond)
 		return nil
 	})
 }
@@ -622,6 +662,79 @@ func (db *BoltDB) AccountPaid(proof *dexdb.AccountProof) error {
 	})
 }
 
+// AddBond saves a new Bond or updates an existing bond for an existing DEX
+// account.
+func (db *BoltDB) AddBond(host string, bond *dexdb.Bond) error {
+	acctKey := []byte(host)
+	return db.acctsUpdate(func(accts *bbolt.Bucket) error {
+		acct := accts.Bucket(acctKey)
+		if acct == nil {
+			return fmt.Errorf("account not found for %s", host)
+		}
+		bonds, err := acct.CreateBucketIfNotExists(bondsSubBucket)
+		if err != nil {
+			return fmt.Errorf("unable to access bonds sub-bucket for account %s: %w", host, err)
+		}
+		bondBkt, err := bonds.CreateBucketIfNotExists(bond.UniqueID())
+		if err != nil {
+			return fmt.Errorf("failed to create bond %x bucket: %w", bond.UniqueID(), err)
+		}
+		return newBucketPutter(bondBkt).
+			put(bondKey, bond.Encode()).
+			put(confirmedKey, encode.ByteFalse).
+			put(refundedKey, encode.ByteFalse).
+			err()
+	})
+}
+
+// setBondFlag sets the boolean value of the specified key in a bond's bucket.
+func (db *BoltDB) setBondFlag(host string, assetID uint32, bondCoinID []byte, flagKey []byte) error {
+	acctKey := []byte(host)
+	return db.acctsUpdate(func(accts *bbolt.Bucket) error {
+		acct := accts.Bucket(acctKey)
+		if acct == nil {
+			return fmt.Errorf("account not found for %s", host)
+		}
+		bonds := acct.Bucket(bondsSubBucket)
+		if bonds == nil {
+			return fmt.Errorf("bonds bucket does not exist for account %s", host)
+		}
+		bondUID := dexdb.BondUID(assetID, bondCoinID)
+		bondBkt := bonds.Bucket(bondUID)
+		if bondBkt == nil {
+			return fmt.Errorf("bond bucket does not exist for bond %x", bondUID)
+		}
+		return bondBkt.Put(flagKey, byteTrue)
+	})
+}
+
+// ConfirmBond marks a DEX account bond as confirmed by the DEX.
+func (db *BoltDB) ConfirmBond(host string, assetID uint32, bondCoinID []byte) error {
+	return db.setBondFlag(host, assetID, bondCoinID, confirmedKey)
+}
+
+// BondRefunded marks a DEX account bond as refunded by the client wallet.
+func (db *BoltDB) BondRefunded(host string, assetID uint32, bondCoinID []byte) error {
+	return db.setBondFlag(host, assetID, bondCoinID, refundedKey)
+}
+
+// NextBondKeyIndex returns the next bond key index and increments the stored
+// value so that subsequent calls will always return a higher index.
+func (db *BoltDB) NextBondKeyIndex(assetID uint32) (uint32, error) {
+	var bondIndex uint32
+	return bondIndex, db.Update(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket(bondIndexesBucket)
+		if bkt == nil {
+			return fmt.Errorf("bond indexes bucket not found")
+		}
+		assetKey := uint32Bytes(assetID)
+		if idxB := bkt.Get(assetKey); len(idxB) == 4 {
+			bondIndex = intCoder.Uint32(idxB)
+		}
+		return bkt.Put(assetKey, uint32Bytes(bondIndex+1))
+	})
+}
+
 // acctsView is a convenience function for reading from the account bucket.
 func (db *BoltDB) acctsView(f bucketFunc) error {
 	return db.withBucket(accountsBucket, db.View, f)
@@ -711,6 +824,7 @@ func (db *BoltDB) UpdateOrder(m *dexdb.MetaOrder) error {
 			put(maxFeeRateKey, uint64Bytes(md.MaxFeeRate)).
 			put(redeemMaxFeeRateKey, uint64Bytes(md.RedeemMaxFeeRate)).
 			put(redemptionFeesKey, uint64Bytes(md.RedemptionFeesPaid)).
+			put(refundFeesKey, uint64Bytes(md.RefundFeesPaid)).
 			put(optionsKey, config.Data(md.Options)).
 			put(accelerationsKey, accelerationsB).
 			err()
@@ -992,6 +1106,11 @@ func decodeOrderBucket(oid []byte, oBkt *bbolt.Bucket) (*dexdb.MetaOrder, error)
 		refundReserves = intCoder.Uint64(refundReservesB)
 	}
 
+	var refundFees uint64
+	if refundFeesB := oBkt.Get(refundFeesKey); len(refundFeesB) == 8 {
+		refundFees = intCoder.Uint64(refundFeesB)
+	}
+
 	var linkedID order.OrderID
 	copy(linkedID[:], oBkt.Get(linkedKey))
 
@@ -1048,6 +1167,7 @@ func decodeOrderBucket(oid []byte, oBkt *bbolt.Bucket) (*dexdb.MetaOrder, error)
 			MaxFeeRate:         maxFeeRate,
 			RedeemMaxFeeRate:   redeemMaxFeeRate,
 			RedemptionFeesPaid: intCoder.Uint64(oBkt.Get(redemptionFeesKey)),
+			RefundFeesPaid:     refundFees,
 			FromVersion:        fromVersion,
 			ToVersion:          toVersion,
 			Options:            options,
@@ -1133,6 +1253,7 @@ func (db *BoltDB) UpdateOrderMetaData(oid order.OrderID, md *db.OrderMetaData) e
 			put(swapFeesKey, uint64Bytes(md.SwapFeesPaid)).
 			put(maxFeeRateKey, uint64Bytes(md.MaxFeeRate)).
 			put(redemptionFeesKey, uint64Bytes(md.RedemptionFeesPaid)).
+			put(refundFeesKey, uint64Bytes(md.RefundFeesPaid)).
 			put(fromVersionKey, uint32Bytes(md.FromVersion)).
 			put(toVersionKey, uint32Bytes(md.ToVersion)).
 			put(optionsKey, config.Data(md.Options)).
@@ -1257,7 +1378,10 @@ func (db *BoltDB) UpdateMatch(m *dexdb.MetaMatch) error {
 	}
 	return db.matchesUpdate(func(mb, archivedMB *bbolt.Bucket) error {
 		metaID := m.MatchOrderUniqueID()
-		active := dexdb.MatchIsActive(m.UserMatch, &m.MetaData.Proof)
+		// A match with a pending redemption or refund is kept in the active
+		// bucket so that its order is loaded on startup, and the fee can
+		// still be bumped.
+		active := dexdb.MatchIsActive(m.UserMatch, &m.MetaData.Proof) || md.Proof.PendingSpend
 		mBkt, err := matchBucket(mb, archivedMB, metaID, active)
 		if err != nil {
 			return err
@@ -1606,6 +1730,179 @@ func (db *BoltDB) NotificationsN(n int) ([]*dexdb.Notification, error) {
 	})
 }
 
+// UpdateStopOrder saves a new stop order or updates an existing one.
+func (db *BoltDB) UpdateStopOrder(so *dexdb.StopOrder) error {
+	if len(so.ID) == 0 {
+		return fmt.Errorf("stop order has no ID")
+	}
+	return db.stopOrdersUpdate(func(bkt *bbolt.Bucket) error {
+		return bkt.Put(so.ID, so.Encode())
+	})
+}
+
+// StopOrders retrieves all pending stop orders, sorted by creation time.
+func (db *BoltDB) StopOrders() ([]*dexdb.StopOrder, error) {
+	var sos []*dexdb.StopOrder
+	err := db.stopOrdersView(func(bkt *bbolt.Bucket) error {
+		return bkt.ForEach(func(k, v []byte) error {
+			so, err := dexdb.DecodeStopOrder(v)
+			if err != nil {
+				return fmt.Errorf("error decoding stop order %x: %w", k, err)
+			}
+			sos = append(sos, so)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sos, func(i, j int) bool { return sos[i].Stamp < sos[j].Stamp })
+	return sos, nil
+}
+
+// DeleteStopOrder deletes the stop order with the specified ID.
+func (db *BoltDB) DeleteStopOrder(id []byte) error {
+	return db.stopOrdersUpdate(func(bkt *bbolt.Bucket) error {
+		if bkt.Get(id) == nil {
+			return fmt.Errorf("stop order %x not found", id)
+		}
+		return bkt.Delete(id)
+	})
+}
+
+// UpdateCandles stores the candles for the specified market and bin size,
+// overwriting any stored candle with the same start time. Candles are stored in
+// nested host -> market -> bin size buckets, keyed by start time.
+func (db *BoltDB) UpdateCandles(host string, base, quote uint32, binSize uint64, cands []*candles.Candle) error {
+	if binSize == 0 {
+		return fmt.Errorf("zero bin size")
+	}
+	return db.candlesUpdate(func(master *bbolt.Bucket) error {
+		hostBkt, err := master.CreateBucketIfNotExists([]byte(host))
+		if err != nil {
+			return fmt.Errorf("error creating candles bucket for %s: %w", host, err)
+		}
+		mktBkt, err := hostBkt.CreateBucketIfNotExists(marketKey(base, quote))
+		if err != nil {
+			return fmt.Errorf("error creating candles market bucket: %w", err)
+		}
+		binBkt, err := mktBkt.CreateBucketIfNotExists(uint64Bytes(binSize))
+		if err != nil {
+			return fmt.Errorf("error creating candles bin bucket: %w", err)
+		}
+		for _, c := range cands {
+			if err = binBkt.Put(uint64Bytes(c.StartStamp), encodeCandle(c)); err != nil {
+				return fmt.Errorf("error storing candle: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Candles retrieves the stored candles for the specified market and bin size
+// with start times in the range [start, end), sorted by start time. end = 0
+// applies no upper limit.
+func (db *BoltDB) Candles(host string, base, quote uint32, binSize, start, end uint64) ([]*candles.Candle, error) {
+	var cands []*candles.Candle
+	return cands, db.candlesView(func(master *bbolt.Bucket) error {
+		hostBkt := master.Bucket([]byte(host))
+		if hostBkt == nil {
+			return nil
+		}
+		mktBkt := hostBkt.Bucket(marketKey(base, quote))
+		if mktBkt == nil {
+			return nil
+		}
+		binBkt := mktBkt.Bucket(uint64Bytes(binSize))
+		if binBkt == nil {
+			return nil
+		}
+		cursor := binBkt.Cursor()
+		for k, v := cursor.Seek(uint64Bytes(start)); k != nil; k, v = cursor.Next() {
+			if end != 0 && intCoder.Uint64(k) >= end {
+				break
+			}
+			c, err := decodeCandle(v)
+			if err != nil {
+				return fmt.Errorf("error decoding candle at %d: %w", intCoder.Uint64(k), err)
+			}
+			cands = append(cands, c)
+		}
+		return nil
+	})
+}
+
+// marketKey is the candles bucket key for the market.
+func marketKey(base, quote uint32) []byte {
+	return append(uint32Bytes(base), uint32Bytes(quote)...)
+}
+
+// encodeCandle serializes the candle as a versioned blob.
+func encodeCandle(c *candles.Candle) []byte {
+	return encode.BuildyBytes{0}.
+		AddData(uint64Bytes(c.StartStamp)).
+		AddData(uint64Bytes(c.EndStamp)).
+		AddData(uint64Bytes(c.MatchVolume)).
+		AddData(uint64Bytes(c.QuoteVolume)).
+		AddData(uint64Bytes(c.HighRate)).
+		AddData(uint64Bytes(c.LowRate)).
+		AddData(uint64Bytes(c.StartRate)).
+		AddData(uint64Bytes(c.EndRate))
+}
+
+// decodeCandle decodes the versioned blob into a *candles.Candle.
+func decodeCandle(b []byte) (*candles.Candle, error) {
+	ver, pushes, err := encode.DecodeBlob(b, 8)
+	if err != nil {
+		return nil, err
+	}
+	if ver != 0 {
+		return nil, fmt.Errorf("unknown candle version %d", ver)
+	}
+	if len(pushes) != 8 {
+		return nil, fmt.Errorf("expected 8 candle data pushes, got %d", len(pushes))
+	}
+	vals := make([]uint64, len(pushes))
+	for i, push := range pushes {
+		if len(push) != 8 {
+			return nil, fmt.Errorf("invalid candle data length %d", len(push))
+		}
+		vals[i] = intCoder.Uint64(push)
+	}
+	return &candles.Candle{
+		StartStamp:  vals[0],
+		EndStamp:    vals[1],
+		MatchVolume: vals[2],
+		QuoteVolume: vals[3],
+		HighRate:    vals[4],
+		LowRate:     vals[5],
+		StartRate:   vals[6],
+		EndRate:     vals[7],
+	}, nil
+}
+
+// candlesView is a convenience function to read from the candles bucket.
+func (db *BoltDB) candlesView(f bucketFunc) error {
+	return db.withBucket(candlesBucket, db.View, f)
+}
+
+// candlesUpdate is a convenience function for updating the candles bucket.
+func (db *BoltDB) candlesUpdate(f bucketFunc) error {
+	return db.withBucket(candlesBucket, db.Update, f)
+}
+
+// stopOrdersView is a convenience function to read from the stop orders
+// bucket.
+func (db *BoltDB) stopOrdersView(f bucketFunc) error {
+	return db.withBucket(stopOrdersBucket, db.View, f)
+}
+
+// stopOrdersUpdate is a convenience function for updating the stop orders
+// bucket.
+func (db *BoltDB) stopOrdersUpdate(f bucketFunc) error {
+	return db.withBucket(stopOrdersBucket, db.Update, f)
+}
+
 // notesView is a convenience function to read from the notifications bucket.
 func (db *BoltDB) notesView(f bucketFunc) error {
 	return db.withBucket(notesBucket, db.View, f)
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -15,6 +16,8 @@ import (
 	"decred.org/dcrdex/client/db"
 	dbtest "decred.org/dcrdex/client/db/test"
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/candles"
+	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/order"
 	ordertest "decred.org/dcrdex/dex/order/test"
 	"go.etcd.io/bbolt"
@@ -355,6 +358,207 @@ func TestAccountProof(t *testing.T) {
 	}
 }
 
+func TestBonds(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	acct := dbtest.RandomAccountInfo()
+	host := acct.Host
+
+	bond := dbtest.RandomBond()
+	if err := boltdb.AddBond(host, bond); err == nil {
+		t.Fatalf("no error adding bond for unknown account")
+	}
+
+	if err := boltdb.CreateAccount(acct); err != nil {
+		t.Fatalf("Unexpected CreateAccount error: %v", err)
+	}
+
+	if err := boltdb.AddBond(host, bond); err != nil {
+		t.Fatalf("AddBond error: %v", err)
+	}
+	bond2 := dbtest.RandomBond()
+	if err := boltdb.AddBond(host, bond2); err != nil {
+		t.Fatalf("AddBond error: %v", err)
+	}
+
+	if err := boltdb.ConfirmBond(host, bond.AssetID, bond.CoinID); err != nil {
+		t.Fatalf("ConfirmBond error: %v", err)
+	}
+	bond.Confirmed = true
+	if err := boltdb.ConfirmBond(host, bond.AssetID+1, bond.CoinID); err == nil {
+		t.Fatalf("no error confirming unknown bond")
+	}
+
+	acctInfo, err := boltdb.Account(host)
+	if err != nil {
+		t.Fatalf("Account error: %v", err)
+	}
+	dbtest.MustCompareAccountInfo(t, acct, acctInfo)
+	if len(acctInfo.Bonds) != 2 {
+		t.Fatalf("expected 2 bonds, got %d", len(acctInfo.Bonds))
+	}
+	for _, b := range acctInfo.Bonds {
+		switch {
+		case bytes.Equal(b.CoinID, bond.CoinID):
+			dbtest.MustCompareBonds(t, bond, b)
+		case bytes.Equal(b.CoinID, bond2.CoinID):
+			dbtest.MustCompareBonds(t, bond2, b)
+		default:
+			t.Fatalf("unknown bond %x", b.CoinID)
+		}
+	}
+
+	// Refunded bonds are not loaded.
+	if err := boltdb.BondRefunded(host, bond2.AssetID, bond2.CoinID); err != nil {
+		t.Fatalf("BondRefunded error: %v", err)
+	}
+	accts, err := boltdb.Accounts()
+	if err != nil {
+		t.Fatalf("Accounts error: %v", err)
+	}
+	if len(accts) != 1 || len(accts[0].Bonds) != 1 {
+		t.Fatalf("expected 1 account with 1 bond")
+	}
+	dbtest.MustCompareBonds(t, bond, accts[0].Bonds[0])
+
+	// Bond key indexes increment per asset.
+	for i := uint32(0); i < 3; i++ {
+		idx, err := boltdb.NextBondKeyIndex(42)
+		if err != nil {
+			t.Fatalf("NextBondKeyIndex error: %v", err)
+		}
+		if idx != i {
+			t.Fatalf("expected bond key index %d, got %d", i, idx)
+		}
+	}
+	if idx, _ := boltdb.NextBondKeyIndex(0); idx != 0 {
+		t.Fatalf("expected bond key index 0 for new asset, got %d", idx)
+	}
+}
+
+func TestStopOrders(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	so1, so2 := dbtest.RandomStopOrder(), dbtest.RandomStopOrder()
+	so1.Stamp, so2.Stamp = 2, 1
+	so2.Options = nil
+	for _, so := range []*db.StopOrder{so1, so2} {
+		if err := boltdb.UpdateStopOrder(so); err != nil {
+			t.Fatalf("UpdateStopOrder error: %v", err)
+		}
+	}
+	if err := boltdb.UpdateStopOrder(&db.StopOrder{}); err == nil {
+		t.Fatalf("no error for stop order without an ID")
+	}
+
+	// Trailing stops update the stop rate.
+	so1.StopRate++
+	if err := boltdb.UpdateStopOrder(so1); err != nil {
+		t.Fatalf("UpdateStopOrder error for existing order: %v", err)
+	}
+
+	sos, err := boltdb.StopOrders()
+	if err != nil {
+		t.Fatalf("StopOrders error: %v", err)
+	}
+	if len(sos) != 2 {
+		t.Fatalf("expected 2 stop orders, got %d", len(sos))
+	}
+	// Sorted by stamp.
+	dbtest.MustCompareStopOrders(t, so2, sos[0])
+	dbtest.MustCompareStopOrders(t, so1, sos[1])
+
+	if err := boltdb.DeleteStopOrder(so1.ID); err != nil {
+		t.Fatalf("DeleteStopOrder error: %v", err)
+	}
+	if err := boltdb.DeleteStopOrder(so1.ID); err == nil {
+		t.Fatalf("no error deleting unknown stop order")
+	}
+	sos, _ = boltdb.StopOrders()
+	if len(sos) != 1 {
+		t.Fatalf("expected 1 stop order after delete, got %d", len(sos))
+	}
+
+	// A stop order with a short integer field is rejected.
+	b := encode.BuildyBytes{0}.AddData(so2.ID).AddData([]byte(so2.Host)).
+		AddData(encode.Uint32Bytes(so2.BaseID)).AddData(encode.Uint32Bytes(so2.QuoteID)).
+		AddData([]byte{0}).AddData(encode.Uint64Bytes(so2.Qty)).AddData(encode.Uint64Bytes(so2.Rate)).
+		AddData(nil).AddData(encode.Uint64Bytes(so2.StopRate)).AddData(encode.Uint64Bytes(so2.TrailRate)).
+		AddData(encode.Uint64Bytes(so2.Stamp)[:7])
+	if _, err := db.DecodeStopOrder(b); err == nil {
+		t.Fatalf("no error decoding a stop order with a short stamp")
+	}
+}
+
+func TestCandles(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	const host = "somedex.com"
+	const binSize = 3600000
+	cands := make([]*candles.Candle, 0, 5)
+	for i := uint64(0); i < 5; i++ {
+		cands = append(cands, &candles.Candle{
+			StartStamp:  i * binSize,
+			EndStamp:    (i+1)*binSize - 1,
+			MatchVolume: i + 1,
+			QuoteVolume: i + 2,
+			HighRate:    i + 3,
+			LowRate:     i + 4,
+			StartRate:   i + 5,
+			EndRate:     i + 6,
+		})
+	}
+	if err := boltdb.UpdateCandles(host, 42, 0, binSize, cands); err != nil {
+		t.Fatalf("UpdateCandles error: %v", err)
+	}
+	if err := boltdb.UpdateCandles(host, 42, 0, 0, cands); err == nil {
+		t.Fatalf("no error for zero bin size")
+	}
+
+	// An update to the last candle overwrites it.
+	last := *cands[4]
+	last.MatchVolume = 100
+	if err := boltdb.UpdateCandles(host, 42, 0, binSize, []*candles.Candle{&last}); err != nil {
+		t.Fatalf("UpdateCandles error for existing candle: %v", err)
+	}
+	cands[4] = &last
+
+	stored, err := boltdb.Candles(host, 42, 0, binSize, 0, 0)
+	if err != nil {
+		t.Fatalf("Candles error: %v", err)
+	}
+	if !reflect.DeepEqual(stored, cands) {
+		t.Fatalf("wrong candles retrieved")
+	}
+
+	stored, _ = boltdb.Candles(host, 42, 0, binSize, binSize, binSize*3)
+	if !reflect.DeepEqual(stored, cands[1:3]) {
+		t.Fatalf("wrong candles retrieved for time range")
+	}
+
+	// Other markets, bin sizes, and hosts have no candles.
+	for _, test := range []struct {
+		host        string
+		base, quote uint32
+		binSize     uint64
+	}{
+		{host, 0, 42, binSize},
+		{host, 42, 0, binSize * 24},
+		{"otherdex.com", 42, 0, binSize},
+	} {
+		stored, err = boltdb.Candles(test.host, test.base, test.quote, test.binSize, 0, 0)
+		if err != nil {
+			t.Fatalf("Candles error: %v", err)
+		}
+		if len(stored) != 0 {
+			t.Fatalf("unexpected candles for %+v", test)
+		}
+	}
+}
+
 func TestWallets(t *testing.T) {
 	boltdb, shutdown := newTestDB(t)
 	defer shutdown()
@@ -509,6 +713,7 @@ func TestOrders(t *testing.T) {
 				Proof:              db.OrderProof{DEXSig: randBytes(73)},
 				SwapFeesPaid:       rand.Uint64(),
 				RedemptionFeesPaid: rand.Uint64(),
+				RefundFeesPaid:     rand.Uint64(),
 				MaxFeeRate:         rand.Uint64(),
 			},
 			Order: ord,
@@ -547,6 +752,9 @@ func TestOrders(t *testing.T) {
 	if firstOrd.MetaData.RedemptionFeesPaid != mord.MetaData.RedemptionFeesPaid {
 		t.Fatalf("wrong RedemptionFeesPaid. wanted %d, got %d", firstOrd.MetaData.RedemptionFeesPaid, mord.MetaData.RedemptionFeesPaid)
 	}
+	if firstOrd.MetaData.RefundFeesPaid != mord.MetaData.RefundFeesPaid {
+		t.Fatalf("wrong RefundFeesPaid. wanted %d, got %d", firstOrd.MetaData.RefundFeesPaid, mord.MetaData.RefundFeesPaid)
+	}
 	if firstOrd.MetaData.MaxFeeRate != mord.MetaData.MaxFeeRate {
 		t.Fatalf("wrong MaxFeeRate. wanted %d, got %d", firstOrd.MetaData.MaxFeeRate, mord.MetaData.MaxFeeRate)
 	}
@@ -1011,6 +1219,39 @@ func TestMatches(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error after fixing match: %v", err)
 	}
+
+	// A complete match stays active while its redemption is pending.
+	m.Status = order.MatchComplete
+	m.MetaData.Proof.Auth.InitSig = randBytes(73)
+	m.MetaData.Proof.Auth.RedeemSig = randBytes(73)
+	m.MetaData.Proof.RefundCoin = nil
+	m.MetaData.Proof.PendingSpend = true
+	if err = boltdb.UpdateMatch(m); err != nil {
+		t.Fatalf("error updating match with pending spend: %v", err)
+	}
+	isActive := func() bool {
+		t.Helper()
+		actives, err := boltdb.ActiveMatches()
+		if err != nil {
+			t.Fatalf("error retrieving active matches: %v", err)
+		}
+		for _, am := range actives {
+			if am.MatchID == m.MatchID {
+				return true
+			}
+		}
+		return false
+	}
+	if !isActive() {
+		t.Fatalf("match with pending spend not active")
+	}
+	m.MetaData.Proof.PendingSpend = false
+	if err = boltdb.UpdateMatch(m); err != nil {
+		t.Fatalf("error updating match without pending spend: %v", err)
+	}
+	if isActive() {
+		t.Fatalf("complete match still active after spend confirmed")
+	}
 }
 
 var randU32 = func() uint32 { return uint32(rand.Int31()) }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/candles"
 	"decred.org/dcrdex/dex/encrypt"
 	"decred.org/dcrdex/dex/order"
 )
@@ -44,6 +45,16 @@ type DB interface {
 	AccountProof(host string) (*AccountProof, error)
 	// AccountPaid marks the account as paid.
 	AccountPaid(proof *AccountProof) error
+	// AddBond saves a new Bond or updates an existing bond for an existing DEX
+	// account.
+	AddBond(host string, bond *Bond) error
+	// NextBondKeyIndex returns the next bond key index and increments the
+	// stored value so that subsequent calls will always return a higher index.
+	NextBondKeyIndex(assetID uint32) (uint32, error)
+	// ConfirmBond records that a bond has been accepted by a DEX.
+	ConfirmBond(host string, assetID uint32, bondCoinID []byte) error
+	// BondRefunded records that a bond has been refunded.
+	BondRefunded(host string, assetID uint32, bondCoinID []byte) error
 	// UpdateOrder saves the order information in the database. Any existing
 	// order info will be overwritten without indication.
 	UpdateOrder(m *MetaOrder) error
@@ -116,6 +127,19 @@ type DB interface {
 	NotificationsN(int) ([]*Notification, error)
 	// AckNotification sets the acknowledgement for a notification.
 	AckNotification(id []byte) error
+	// UpdateStopOrder saves a new stop order or updates an existing one.
+	UpdateStopOrder(so *StopOrder) error
+	// StopOrders retrieves all pending stop orders.
+	StopOrders() ([]*StopOrder, error)
+	// DeleteStopOrder deletes the stop order with the specified ID.
+	DeleteStopOrder(id []byte) error
+	// UpdateCandles stores the candles for the specified market and bin size,
+	// overwriting any stored candle with the same start time.
+	UpdateCandles(host string, base, quote uint32, binSize uint64, cands []*candles.Candle) error
+	// Candles retrieves the stored candles for the specified market and bin
+	// size with start times in the range [start, end), sorted by start time.
+	// end = 0 applies no upper limit.
+	Candles(host string, base, quote uint32, binSize, start, end uint64) ([]*candles.Candle, error)
 	// DeleteInactiveOrders deletes inactive orders from the database that
 	// have been updated after the supplied time. If no time is supplied
 	// the current time is used. Accepts an optional function to perform on
@@ -5,6 +5,7 @@ package dbtest
 
 import (
 	"bytes"
+	"encoding/hex"
 	"math/rand"
 	"strings"
 	"time"
@@ -42,11 +43,48 @@ func RandomAccountInfo() *db.AccountInfo {
 	return &db.AccountInfo{
 		Host: ordertest.RandomAddress(),
 		// LegacyEncKey: randBytes(32),
-		EncKeyV2:   randBytes(32),
-		DEXPubKey:  randomPubKey(),
-		FeeAssetID: uint32(rand.Intn(64)),
-		FeeCoin:    randBytes(32),
-		Cert:       randBytes(100),
+		EncKeyV2:    randBytes(32),
+		DEXPubKey:   randomPubKey(),
+		FeeAssetID:  uint32(rand.Intn(64)),
+		FeeCoin:     randBytes(32),
+		Cert:        randBytes(100),
+		TargetTier:  uint64(rand.Intn(10)),
+		BondAssetID: uint32(rand.Intn(64)),
+	}
+}
+
+// RandomBond creates a random bond.
+func RandomBond() *db.Bond {
+	return &db.Bond{
+		Version:    uint16(rand.Intn(2)),
+		AssetID:    uint32(rand.Intn(64)),
+		CoinID:     randBytes(36),
+		UnsignedTx: randBytes(200),
+		SignedTx:   randBytes(300),
+		Data:       randBytes(32),
+		Amount:     rand.Uint64(),
+		LockTime:   rand.Uint64(),
+		KeyIndex:   rand.Uint32(),
+		RefundTx:   randBytes(150),
+	}
+}
+
+// RandomStopOrder creates a random StopOrder.
+func RandomStopOrder() *db.StopOrder {
+	return &db.StopOrder{
+		ID:        randBytes(16),
+		Host:      randString(20),
+		BaseID:    uint32(rand.Intn(64)),
+		QuoteID:   uint32(rand.Intn(64)),
+		Sell:      rand.Intn(2) == 0,
+		IsLimit:   rand.Intn(2) == 0,
+		Qty:       rand.Uint64(),
+		Rate:      rand.Uint64(),
+		TifNow:    rand.Intn(2) == 0,
+		Options:   map[string]string{"option": hex.EncodeToString(randBytes(8))},
+		StopRate:  rand.Uint64(),
+		TrailRate: rand.Uint64(),
+		Stamp:     rand.Uint64(),
 	}
 }
 
@@ -250,6 +288,15 @@ func MustCompareMatchProof(t testKiller, m1, m2 *db.MatchProof) {
 	if !bytes.Equal(m1.TakerRedeem, m2.TakerRedeem) {
 		t.Fatalf("TakerRedeem mismatch. %x != %x", m1.TakerRedeem, m2.TakerRedeem)
 	}
+	if m1.ServerRevoked != m2.ServerRevoked {
+		t.Fatalf("ServerRevoked mismatch. %t != %t", m1.ServerRevoked, m2.ServerRevoked)
+	}
+	if m1.SelfRevoked != m2.SelfRevoked {
+		t.Fatalf("SelfRevoked mismatch. %t != %t", m1.SelfRevoked, m2.SelfRevoked)
+	}
+	if m1.PendingSpend != m2.PendingSpend {
+		t.Fatalf("PendingSpend mismatch. %t != %t", m1.PendingSpend, m2.PendingSpend)
+	}
 	MustCompareMatchAuth(t, &m1.Auth, &m2.Auth)
 }
 
@@ -272,6 +319,99 @@ func MustCompareAccountInfo(t testKiller, a1, a2 *db.AccountInfo) {
 	if !bytes.Equal(a1.FeeCoin, a2.FeeCoin) {
 		t.Fatalf("EncKey mismatch. %x != %x", a1.FeeCoin, a2.FeeCoin)
 	}
+	if a1.TargetTier != a2.TargetTier {
+		t.Fatalf("TargetTier mismatch. %d != %d", a1.TargetTier, a2.TargetTier)
+	}
+	if a1.BondAssetID != a2.BondAssetID {
+		t.Fatalf("BondAssetID mismatch. %d != %d", a1.BondAssetID, a2.BondAssetID)
+	}
+}
+
+// MustCompareBonds ensures the two Bonds are identical, calling the Fatalf
+// method of the testKiller if not.
+func MustCompareBonds(t testKiller, b1, b2 *db.Bond) {
+	if b1.Version != b2.Version {
+		t.Fatalf("Version mismatch. %d != %d", b1.Version, b2.Version)
+	}
+	if b1.AssetID != b2.AssetID {
+		t.Fatalf("AssetID mismatch. %d != %d", b1.AssetID, b2.AssetID)
+	}
+	if !bytes.Equal(b1.CoinID, b2.CoinID) {
+		t.Fatalf("CoinID mismatch. %x != %x", b1.CoinID, b2.CoinID)
+	}
+	if !bytes.Equal(b1.UnsignedTx, b2.UnsignedTx) {
+		t.Fatalf("UnsignedTx mismatch. %x != %x", b1.UnsignedTx, b2.UnsignedTx)
+	}
+	if !bytes.Equal(b1.SignedTx, b2.SignedTx) {
+		t.Fatalf("SignedTx mismatch. %x != %x", b1.SignedTx, b2.SignedTx)
+	}
+	if !bytes.Equal(b1.Data, b2.Data) {
+		t.Fatalf("Data mismatch. %x != %x", b1.Data, b2.Data)
+	}
+	if b1.Amount != b2.Amount {
+		t.Fatalf("Amount mismatch. %d != %d", b1.Amount, b2.Amount)
+	}
+	if b1.LockTime != b2.LockTime {
+		t.Fatalf("LockTime mismatch. %d != %d", b1.LockTime, b2.LockTime)
+	}
+	if b1.KeyIndex != b2.KeyIndex {
+		t.Fatalf("KeyIndex mismatch. %d != %d", b1.KeyIndex, b2.KeyIndex)
+	}
+	if !bytes.Equal(b1.RefundTx, b2.RefundTx) {
+		t.Fatalf("RefundTx mismatch. %x != %x", b1.RefundTx, b2.RefundTx)
+	}
+	if b1.Confirmed != b2.Confirmed {
+		t.Fatalf("Confirmed mismatch. %t != %t", b1.Confirmed, b2.Confirmed)
+	}
+	if b1.Refunded != b2.Refunded {
+		t.Fatalf("Refunded mismatch. %t != %t", b1.Refunded, b2.Refunded)
+	}
+}
+
+// MustCompareStopOrders ensures the two StopOrder are identical, calling the
+// Fatalf method of the testKiller if not.
+func MustCompareStopOrders(t testKiller, s1, s2 *db.StopOrder) {
+	if !bytes.Equal(s1.ID, s2.ID) {
+		t.Fatalf("ID mismatch. %x != %x", s1.ID, s2.ID)
+	}
+	if s1.Host != s2.Host {
+		t.Fatalf("Host mismatch. %s != %s", s1.Host, s2.Host)
+	}
+	if s1.BaseID != s2.BaseID || s1.QuoteID != s2.QuoteID {
+		t.Fatalf("market mismatch. %d-%d != %d-%d", s1.BaseID, s1.QuoteID, s2.BaseID, s2.QuoteID)
+	}
+	if s1.Sell != s2.Sell {
+		t.Fatalf("Sell mismatch. %t != %t", s1.Sell, s2.Sell)
+	}
+	if s1.IsLimit != s2.IsLimit {
+		t.Fatalf("IsLimit mismatch. %t != %t", s1.IsLimit, s2.IsLimit)
+	}
+	if s1.TifNow != s2.TifNow {
+		t.Fatalf("TifNow mismatch. %t != %t", s1.TifNow, s2.TifNow)
+	}
+	if s1.Qty != s2.Qty {
+		t.Fatalf("Qty mismatch. %d != %d", s1.Qty, s2.Qty)
+	}
+	if s1.Rate != s2.Rate {
+		t.Fatalf("Rate mismatch. %d != %d", s1.Rate, s2.Rate)
+	}
+	if len(s1.Options) != len(s2.Options) {
+		t.Fatalf("Options length mismatch. %d != %d", len(s1.Options), len(s2.Options))
+	}
+	for k, v := range s1.Options {
+		if s2.Options[k] != v {
+			t.Fatalf("Options mismatch for %q. %q != %q", k, v, s2.Options[k])
+		}
+	}
+	if s1.StopRate != s2.StopRate {
+		t.Fatalf("StopRate mismatch. %d != %d", s1.StopRate, s2.StopRate)
+	}
+	if s1.TrailRate != s2.TrailRate {
+		t.Fatalf("TrailRate mismatch. %d != %d", s1.TrailRate, s2.TrailRate)
+	}
+	if s1.Stamp != s2.Stamp {
+		t.Fatalf("Stamp mismatch. %d != %d", s1.Stamp, s2.Stamp)
+	}
 }
 
 // MustCompareOrderProof ensures the two OrderProof are identical, calling the
@@ -99,18 +99,30 @@ type AccountInfo struct {
 	FeeCoin    []byte
 	// Paid is set on retrieval based on whether there is an AccountProof set.
 	Paid bool
+
+	// TargetTier is the desired account tier that should be maintained by
+	// automatically posting new fidelity bonds as existing bonds expire. Zero
+	// disables bond renewal.
+	TargetTier uint64
+	// BondAssetID is the asset used for automatically posted bonds.
+	BondAssetID uint32
+	// Bonds are the account's unrefunded fidelity bonds. Bonds are stored
+	// separately, and are set on retrieval.
+	Bonds []*Bond
 }
 
-// Encode the AccountInfo as bytes.
+// Encode the AccountInfo as bytes. The Bonds are not included.
 func (ai *AccountInfo) Encode() []byte {
-	return versionedBytes(2).
+	return versionedBytes(3).
 		AddData([]byte(ai.Host)).
 		AddData(ai.Cert).
 		AddData(ai.DEXPubKey.SerializeCompressed()).
 		AddData(ai.EncKeyV2).
 		AddData(ai.LegacyEncKey).
 		AddData(encode.Uint32Bytes(ai.FeeAssetID)).
-		AddData(ai.FeeCoin)
+		AddData(ai.FeeCoin).
+		AddData(encode.Uint64Bytes(ai.TargetTier)).
+		AddData(encode.Uint32Bytes(ai.BondAssetID))
 }
 
 // EncKey is the encrypted account private key.
@@ -135,6 +147,8 @@ func DecodeAccountInfo(b []byte) (*AccountInfo, error) {
 		return decodeAccountInfo_v1(pushes)
 	case 2:
 		return decodeAccountInfo_v2(pushes)
+	case 3:
+		return decodeAccountInfo_v3(pushes)
 	}
 	return nil, fmt.Errorf("unknown AccountInfo version %d", ver)
 }
@@ -186,6 +200,20 @@ func decodeAccountInfo_v2(pushes [][]byte) (*AccountInfo, error) {
 	}, nil
 }
 
+func decodeAccountInfo_v3(pushes [][]byte) (*AccountInfo, error) {
+	if len(pushes) != 9 {
+		return nil, fmt.Errorf("decodeAccountInfo: expected 9 data pushes, got %d", len(pushes))
+	}
+	ai, err := decodeAccountInfo_v2(pushes[:7])
+	if err != nil {
+		return nil, err
+	}
+	tierB, bondAssetB := pushes[7], pushes[8] // bond options
+	ai.TargetTier = intCoder.Uint64(tierB)
+	ai.BondAssetID = intCoder.Uint32(bondAssetB)
+	return ai, nil
+}
+
 // Account proof is information necessary to prove that the DEX server accepted
 // the account's fee payment. The fee coin is not part of the proof, since it
 // is already stored as part of the AccountInfo blob.
@@ -228,6 +256,197 @@ func decodeAccountProof_v0(pushes [][]byte) (*AccountProof, error) {
 	}, nil
 }
 
+// Bond is stored in a sub-bucket of an account bucket. The dex.Bytes type is
+// used for certain fields so that the data marshals to/from hexadecimal.
+type Bond struct {
+	Version    uint16    `json:"ver"`
+	AssetID    uint32    `json:"asset"`
+	CoinID     dex.Bytes `json:"coinID"`
+	UnsignedTx dex.Bytes `json:"utx"`
+	SignedTx   dex.Bytes `json:"stx"`
+	Data       dex.Bytes `json:"data"` // e.g. redeem script
+	Amount     uint64    `json:"amt"`
+	LockTime   uint64    `json:"lockTime"`
+	KeyIndex   uint32    `json:"keyIndex"` // child index for the bond key derived from the app seed
+	RefundTx   dex.Bytes `json:"refundTx"` // pays to wallet that created it - only a backup for emergency!
+	// Confirmed should be set when the bond's transaction is fully confirmed
+	// and the server has acknowledged the bond with a postbond response.
+	Confirmed bool `json:"confirmed"`
+	// Refunded should be set when the bond has been refunded.
+	Refunded bool `json:"refunded"`
+}
+
+// UniqueID computes the bond's unique ID for keying purposes.
+func (b *Bond) UniqueID() []byte {
+	return BondUID(b.AssetID, b.CoinID)
+}
+
+// BondUID generates a unique identifier from a bond's asset ID and coin ID.
+func BondUID(assetID uint32, bondCoinID []byte) []byte {
+	return hashKey(append(uint32Bytes(assetID), bondCoinID...))
+}
+
+// Encode serializes the Bond. Confirmed and Refunded are not included.
+func (b *Bond) Encode() []byte {
+	return versionedBytes(0).
+		AddData(uint16Bytes(b.Version)).
+		AddData(uint32Bytes(b.AssetID)).
+		AddData(b.CoinID).
+		AddData(b.UnsignedTx).
+		AddData(b.SignedTx).
+		AddData(b.Data).
+		AddData(uint64Bytes(b.Amount)).
+		AddData(uint64Bytes(b.LockTime)).
+		AddData(uint32Bytes(b.KeyIndex)).
+		AddData(b.RefundTx)
+}
+
+// DecodeBond decodes the versioned blob into a *Bond.
+func DecodeBond(b []byte) (*Bond, error) {
+	ver, pushes, err := encode.DecodeBlob(b)
+	if err != nil {
+		return nil, err
+	}
+	switch ver {
+	case 0:
+		return decodeBond_v0(pushes)
+	}
+	return nil, fmt.Errorf("unknown Bond version %d", ver)
+}
+
+func decodeBond_v0(pushes [][]byte) (*Bond, error) {
+	if len(pushes) != 10 {
+		return nil, fmt.Errorf("decodeBond_v0: expected 10 data pushes, got %d", len(pushes))
+	}
+	ver, assetIDB, coinID := pushes[0], pushes[1], pushes[2]
+	utx, stx := pushes[3], pushes[4]
+	data, amtB, lockTimeB := pushes[5], pushes[6], pushes[7]
+	keyIndexB, refundTx := pushes[8], pushes[9]
+	return &Bond{
+		Version:    intCoder.Uint16(ver),
+		AssetID:    intCoder.Uint32(assetIDB),
+		CoinID:     coinID,
+		UnsignedTx: utx,
+		SignedTx:   stx,
+		Data:       data,
+		Amount:     intCoder.Uint64(amtB),
+		LockTime:   intCoder.Uint64(lockTimeB),
+		KeyIndex:   intCoder.Uint32(keyIndexB),
+		RefundTx:   refundTx,
+	}, nil
+}
+
+// StopOrder is a conditional order that is held by the client until the
+// market price crosses its stop rate, at which point a regular order is
+// placed. A stop order with a non-zero TrailRate is a trailing stop, for which
+// the StopRate follows the market price as it moves away from the stop.
+type StopOrder struct {
+	ID      dex.Bytes         `json:"id"`
+	Host    string            `json:"host"`
+	BaseID  uint32            `json:"baseID"`
+	QuoteID uint32            `json:"quoteID"`
+	Sell    bool              `json:"sell"`
+	IsLimit bool              `json:"isLimit"`
+	Qty     uint64            `json:"qty"`
+	Rate    uint64            `json:"rate"` // limit orders only
+	TifNow  bool              `json:"tifnow"`
+	Options map[string]string `json:"options"`
+	// StopRate is the market rate at which the order is triggered. A sell
+	// order triggers when the market rate falls to the StopRate, and a buy
+	// order when it rises to the StopRate.
+	StopRate uint64 `json:"stopRate"`
+	// TrailRate is the distance kept between the StopRate and the best market
+	// rate seen for a trailing stop. Zero for a plain stop order.
+	TrailRate uint64 `json:"trailRate"`
+	Stamp     uint64 `json:"stamp"`
+}
+
+// Stop order flag bits.
+const (
+	stopOrderSell byte = 1 << iota
+	stopOrderLimit
+	stopOrderTifNow
+)
+
+// Encode serializes the StopOrder.
+func (s *StopOrder) Encode() []byte {
+	var flags byte
+	if s.Sell {
+		flags |= stopOrderSell
+	}
+	if s.IsLimit {
+		flags |= stopOrderLimit
+	}
+	if s.TifNow {
+		flags |= stopOrderTifNow
+	}
+	return versionedBytes(0).
+		AddData(s.ID).
+		AddData([]byte(s.Host)).
+		AddData(uint32Bytes(s.BaseID)).
+		AddData(uint32Bytes(s.QuoteID)).
+		AddData([]byte{flags}).
+		AddData(uint64Bytes(s.Qty)).
+		AddData(uint64Bytes(s.Rate)).
+		AddData(config.Data(s.Options)).
+		AddData(uint64Bytes(s.StopRate)).
+		AddData(uint64Bytes(s.TrailRate)).
+		AddData(uint64Bytes(s.Stamp))
+}
+
+// DecodeStopOrder decodes the versioned blob into a *StopOrder.
+func DecodeStopOrder(b []byte) (*StopOrder, error) {
+	ver, pushes, err := encode.DecodeBlob(b)
+	if err != nil {
+		return nil, err
+	}
+	switch ver {
+	case 0:
+		return decodeStopOrder_v0(pushes)
+	}
+	return nil, fmt.Errorf("unknown StopOrder version %d", ver)
+}
+
+func decodeStopOrder_v0(pushes [][]byte) (*StopOrder, error) {
+	if len(pushes) != 11 {
+		return nil, fmt.Errorf("decodeStopOrder_v0: expected 11 data pushes, got %d", len(pushes))
+	}
+	id, hostB, baseB, quoteB, flagsB := pushes[0], pushes[1], pushes[2], pushes[3], pushes[4]
+	qtyB, rateB, optionsB := pushes[5], pushes[6], pushes[7]
+	stopRateB, trailRateB, stampB := pushes[8], pushes[9], pushes[10]
+	if len(flagsB) != 1 {
+		return nil, fmt.Errorf("decodeStopOrder_v0: invalid flags length %d", len(flagsB))
+	}
+	if len(baseB) != 4 || len(quoteB) != 4 {
+		return nil, fmt.Errorf("decodeStopOrder_v0: invalid asset ID lengths %d and %d", len(baseB), len(quoteB))
+	}
+	for i, b := range [][]byte{qtyB, rateB, stopRateB, trailRateB, stampB} {
+		if len(b) != 8 {
+			return nil, fmt.Errorf("decodeStopOrder_v0: invalid uint64 length %d for data push %d", len(b), i)
+		}
+	}
+	options, err := config.Parse(optionsB)
+	if err != nil {
+		return nil, fmt.Errorf("decodeStopOrder_v0: unable to decode options: %w", err)
+	}
+	flags := flagsB[0]
+	return &StopOrder{
+		ID:        id,
+		Host:      string(hostB),
+		BaseID:    intCoder.Uint32(baseB),
+		QuoteID:   intCoder.Uint32(quoteB),
+		Sell:      flags&stopOrderSell != 0,
+		IsLimit:   flags&stopOrderLimit != 0,
+		TifNow:    flags&stopOrderTifNow != 0,
+		Qty:       intCoder.Uint64(qtyB),
+		Rate:      intCoder.Uint64(rateB),
+		Options:   options,
+		StopRate:  intCoder.Uint64(stopRateB),
+		TrailRate: intCoder.Uint64(trailRateB),
+		Stamp:     intCoder.Uint64(stampB),
+	}, nil
+}
+
 // MetaOrder is an order and its metadata.
 type MetaOrder struct {
 	// MetaData is important auxiliary information about the order.
@@ -257,6 +476,9 @@ type OrderMetaData struct {
 	// RedemptionFeesPaid is the sum of the actual fees paid for all
 	// redemptions.
 	RedemptionFeesPaid uint64
+	// RefundFeesPaid is the sum of the actual fees paid for all refunds. This
+	// is only recorded for wallets that report refund fees.
+	RefundFeesPaid uint64
 	// MaxFeeRate is the dex.Asset.MaxFeeRate at the time of ordering. The rates
 	// assigned to matches will be validated against this value.
 	MaxFeeRate uint64
@@ -392,12 +614,15 @@ type MatchProof struct {
 	Auth            MatchAuth
 	ServerRevoked   bool
 	SelfRevoked     bool
+	// PendingSpend is set while this side's redemption or refund transaction
+	// is unconfirmed and its fee can be bumped.
+	PendingSpend bool
 }
 
 // MatchProofVer is the current serialization version of a MatchProof.
 const (
-	MatchProofVer    = 2
-	matchProofPushes = 22
+	MatchProofVer    = 3
+	matchProofPushes = 23
 )
 
 // Encode encodes the MatchProof to a versioned blob.
@@ -411,6 +636,10 @@ func (p *MatchProof) Encode() []byte {
 	if p.SelfRevoked {
 		selfRevoked = encode.ByteTrue
 	}
+	pendingSpend := encode.ByteFalse
+	if p.PendingSpend {
+		pendingSpend = encode.ByteTrue
+	}
 
 	return versionedBytes(MatchProofVer).
 		AddData(p.ContractData).
@@ -434,7 +663,8 @@ func (p *MatchProof) Encode() []byte {
 		AddData(uint64Bytes(auth.RedemptionStamp)).
 		AddData(srvRevoked).
 		AddData(selfRevoked).
-		AddData(p.CounterTxData)
+		AddData(p.CounterTxData).
+		AddData(pendingSpend)
 }
 
 // DecodeMatchProof decodes the versioned blob to a *MatchProof.
@@ -444,7 +674,10 @@ func DecodeMatchProof(b []byte) (*MatchProof, uint8, error) {
 		return nil, 0, err
 	}
 	switch ver {
-	case 2: // MatchProofVer
+	case 3: // MatchProofVer
+		proof, err := decodeMatchProof_v3(pushes)
+		return proof, ver, err
+	case 2:
 		proof, err := decodeMatchProof_v2(pushes)
 		return proof, ver, err
 	case 1:
@@ -468,6 +701,11 @@ func decodeMatchProof_v1(pushes [][]byte) (*MatchProof, error) {
 }
 
 func decodeMatchProof_v2(pushes [][]byte) (*MatchProof, error) {
+	pushes = append(pushes, encode.ByteFalse)
+	return decodeMatchProof_v3(pushes)
+}
+
+func decodeMatchProof_v3(pushes [][]byte) (*MatchProof, error) {
 	if len(pushes) != matchProofPushes {
 		return nil, fmt.Errorf("DecodeMatchProof: expected %d pushes, got %d",
 			matchProofPushes, len(pushes))
@@ -497,6 +735,7 @@ func decodeMatchProof_v2(pushes [][]byte) (*MatchProof, error) {
 		},
 		ServerRevoked: bytes.Equal(pushes[19], encode.ByteTrue),
 		SelfRevoked:   bytes.Equal(pushes[20], encode.ByteTrue),
+		PendingSpend:  bytes.Equal(pushes[22], encode.ByteTrue),
 	}, nil
 }
 
@@ -692,6 +931,7 @@ func versionedBytes(v byte) encode.BuildyBytes {
 
 var uint64Bytes = encode.Uint64Bytes
 var uint32Bytes = encode.Uint32Bytes
+var uint16Bytes = encode.Uint16Bytes
 var intCoder = encode.IntCoder
 
 // AccountBackup represents a user account backup.
@@ -0,0 +1,627 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+)
+
+// minRebalanceInterval limits how often book updates can trigger a
+// rebalance. Rebalances also happen once per epoch regardless of book
+// activity.
+const minRebalanceInterval = time.Second
+
+// GapStrategy specifies how the distance between a bot's orders and the basis
+// price is calculated.
+type GapStrategy string
+
+const (
+	// GapStrategyPercent places orders around the book's mid-gap rate, with
+	// the gap set as a fraction of the mid-gap rate.
+	GapStrategyPercent GapStrategy = "percent"
+	// GapStrategyMultiplier places orders around the book's mid-gap rate,
+	// with the gap set as a multiple of the book's current half-spread. The
+	// bot's own orders are not considered when calculating the spread.
+	GapStrategyMultiplier GapStrategy = "multiplier"
+	// GapStrategyOracle places orders around the oracle price, with the gap
+	// set as a fraction of the oracle price.
+	GapStrategyOracle GapStrategy = "oracle"
+)
+
+// OrderPlacement describes a pair of buy and sell orders placed on either
+// side of the basis price.
+type OrderPlacement struct {
+	// Lots is the number of lots in each order.
+	Lots uint64 `json:"lots"`
+	// GapFactor is interpreted according to the bot's GapStrategy. For
+	// GapStrategyPercent and GapStrategyOracle, it is the fraction of the
+	// basis price, e.g. 0.01 for 1%. For GapStrategyMultiplier, it is the
+	// multiple of the half-spread.
+	GapFactor float64 `json:"gapFactor"`
+}
+
+// BotConfig is the configuration of a market making bot.
+type BotConfig struct {
+	Host        string            `json:"host"`
+	BaseID      uint32            `json:"baseID"`
+	QuoteID     uint32            `json:"quoteID"`
+	GapStrategy GapStrategy       `json:"gapStrategy"`
+	Placements  []*OrderPlacement `json:"placements"`
+	// DriftTolerance is how far an order's rate may drift from its target
+	// rate, as a fraction of the basis price, before it is canceled and
+	// replaced.
+	DriftTolerance float64 `json:"driftTolerance"`
+	// BaseBalance and QuoteBalance are the maximum amounts of the base and
+	// quote assets that the bot may commit to standing orders. Zero means no
+	// limit other than the wallet balance.
+	BaseBalance  uint64 `json:"baseBalance"`
+	QuoteBalance uint64 `json:"quoteBalance"`
+}
+
+// validate checks the BotConfig for errors.
+func (cfg *BotConfig) validate() error {
+	if cfg == nil {
+		return errors.New("no bot config")
+	}
+	if cfg.Host == "" {
+		return errors.New("no host specified")
+	}
+	if cfg.BaseID == cfg.QuoteID {
+		return errors.New("base and quote assets must differ")
+	}
+	switch cfg.GapStrategy {
+	case GapStrategyPercent, GapStrategyMultiplier, GapStrategyOracle:
+	default:
+		return fmt.Errorf("unknown gap strategy %q", cfg.GapStrategy)
+	}
+	if len(cfg.Placements) == 0 {
+		return errors.New("no order placements")
+	}
+	for i, p := range cfg.Placements {
+		if p == nil || p.Lots == 0 {
+			return fmt.Errorf("placement %d: zero lots", i)
+		}
+		if p.GapFactor <= 0 || math.IsNaN(p.GapFactor) || math.IsInf(p.GapFactor, 0) {
+			return fmt.Errorf("placement %d: invalid gap factor %f", i, p.GapFactor)
+		}
+		if cfg.GapStrategy != GapStrategyMultiplier && p.GapFactor >= 1 {
+			return fmt.Errorf("placement %d: gap factor %f must be less than 1 for the %s strategy",
+				i, p.GapFactor, cfg.GapStrategy)
+		}
+	}
+	if cfg.DriftTolerance < 0 || cfg.DriftTolerance >= 1 || math.IsNaN(cfg.DriftTolerance) {
+		return fmt.Errorf("invalid drift tolerance %f", cfg.DriftTolerance)
+	}
+	return nil
+}
+
+// copy makes a deep copy of the BotConfig.
+func (cfg *BotConfig) copy() *BotConfig {
+	c := *cfg
+	c.Placements = make([]*OrderPlacement, 0, len(cfg.Placements))
+	for _, p := range cfg.Placements {
+		pc := *p
+		c.Placements = append(c.Placements, &pc)
+	}
+	return &c
+}
+
+// botOrder is a standing order placed by a bot.
+type botOrder struct {
+	id         dex.Bytes
+	sell       bool
+	rate       uint64
+	qty        uint64 // remaining
+	cancelling bool
+}
+
+// bot is a market maker for a single market.
+type bot struct {
+	id     uint64
+	core   clientCore
+	oracle Oracle
+	log    dex.Logger
+
+	cfgMtx sync.RWMutex
+	cfg    *BotConfig
+
+	runMtx sync.Mutex
+	cancel context.CancelFunc // nil when not running
+	done   chan struct{}
+
+	// The order maps are keyed by placement index. They are only modified
+	// by the bot's run goroutine, but are read by status.
+	ordMtx sync.RWMutex
+	buys   map[int]*botOrder
+	sells  map[int]*botOrder
+
+	lastRebalance time.Time
+}
+
+// newBot creates a new, stopped bot.
+func (m *MarketMaker) newBot(id uint64, cfg *BotConfig) *bot {
+	return &bot{
+		id:     id,
+		core:   m.core,
+		oracle: m.oracle,
+		log:    m.log.SubLogger(fmt.Sprintf("BOT%d", id)),
+		cfg:    cfg.copy(),
+		buys:   make(map[int]*botOrder),
+		sells:  make(map[int]*botOrder),
+	}
+}
+
+// config returns a copy of the bot's current configuration.
+func (b *bot) config() *BotConfig {
+	b.cfgMtx.RLock()
+	defer b.cfgMtx.RUnlock()
+	return b.cfg.copy()
+}
+
+// setConfig updates the bot's configuration.
+func (b *bot) setConfig(cfg *BotConfig) {
+	b.cfgMtx.Lock()
+	b.cfg = cfg.copy()
+	b.cfgMtx.Unlock()
+}
+
+// setRunning records the bot's context cancel function, marking it as
+// running.
+func (b *bot) setRunning(cancel context.CancelFunc) {
+	b.runMtx.Lock()
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	b.runMtx.Unlock()
+}
+
+// running is true if the bot has been started and has not yet stopped.
+func (b *bot) running() bool {
+	b.runMtx.Lock()
+	defer b.runMtx.Unlock()
+	return b.cancel != nil
+}
+
+// stop signals the bot to stop and waits for it to cancel its orders and
+// exit. stop is a no-op if the bot is not running.
+func (b *bot) stop() {
+	b.runMtx.Lock()
+	cancel, done := b.cancel, b.done
+	b.runMtx.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// status returns the bot's current status.
+func (b *bot) status() *BotStatus {
+	b.ordMtx.RLock()
+	ords := make([]dex.Bytes, 0, len(b.buys)+len(b.sells))
+	for _, side := range []map[int]*botOrder{b.buys, b.sells} {
+		for _, ord := range side {
+			ords = append(ords, ord.id)
+		}
+	}
+	b.ordMtx.RUnlock()
+	return &BotStatus{
+		ID:      b.id,
+		Config:  b.config(),
+		Running: b.running(),
+		Orders:  ords,
+	}
+}
+
+// run is the bot's main loop. The bot rebalances its orders whenever the
+// order book changes, and at least once per epoch. When the context is
+// canceled, all of the bot's standing orders are canceled.
+func (b *bot) run(ctx context.Context) {
+	defer func() {
+		b.runMtx.Lock()
+		b.cancel()
+		b.cancel = nil
+		close(b.done)
+		b.runMtx.Unlock()
+	}()
+
+	cfg := b.config()
+	mkt, _, err := b.market(cfg)
+	if err != nil {
+		b.log.Errorf("Error starting bot: %v", err)
+		return
+	}
+
+	var feed core.BookFeed
+	var feedC <-chan *core.BookUpdate
+	syncBook := func() {
+		feed, err = b.core.SyncBook(cfg.Host, cfg.BaseID, cfg.QuoteID)
+		if err != nil {
+			b.log.Errorf("Error syncing %s order book: %v", mkt.Name, err)
+			feed, feedC = nil, nil
+			return
+		}
+		feedC = feed.Next()
+	}
+	syncBook()
+	defer func() {
+		if feed != nil {
+			feed.Close()
+		}
+	}()
+
+	b.log.Infof("Starting market maker on %s at %s", mkt.Name, cfg.Host)
+
+	ticker := time.NewTicker(time.Duration(mkt.EpochLen) * time.Millisecond)
+	defer ticker.Stop()
+
+	b.rebalance()
+	for {
+		select {
+		case u, ok := <-feedC:
+			if !ok {
+				// The feed is closed when the DEX connection is lost.
+				// Resubscribe on the next tick.
+				b.log.Warnf("Order book feed closed for %s", mkt.Name)
+				feed, feedC = nil, nil
+				continue
+			}
+			switch u.Action {
+			case core.FreshBookAction, core.BookOrderAction, core.UnbookOrderAction,
+				core.UpdateRemainingAction:
+				if time.Since(b.lastRebalance) >= minRebalanceInterval {
+					b.rebalance()
+				}
+			}
+		case <-ticker.C:
+			if feed == nil {
+				syncBook()
+			}
+			b.rebalance()
+		case <-ctx.Done():
+			b.log.Infof("Stopping market maker on %s at %s", mkt.Name, cfg.Host)
+			b.cancelAll()
+			return
+		}
+	}
+}
+
+// market returns the bot's market and the market's assets.
+func (b *bot) market(cfg *BotConfig) (*core.Market, *core.Exchange, error) {
+	xc, err := b.core.Exchange(cfg.Host)
+	if err != nil {
+		return nil, nil, err
+	}
+	mktName, err := dex.MarketName(cfg.BaseID, cfg.QuoteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	mkt := xc.Markets[mktName]
+	if mkt == nil {
+		return nil, nil, fmt.Errorf("%s does not have a %s market", cfg.Host, mktName)
+	}
+	return mkt, xc, nil
+}
+
+// refreshOrders updates the bot's orders with their current status from Core,
+// dropping any orders that are no longer active.
+func (b *bot) refreshOrders() {
+	b.ordMtx.Lock()
+	defer b.ordMtx.Unlock()
+	for _, ords := range []map[int]*botOrder{b.buys, b.sells} {
+		for i, bo := range ords {
+			ord, err := b.core.Order(bo.id)
+			if err != nil {
+				b.log.Errorf("Error retrieving order %s: %v", bo.id, err)
+				continue
+			}
+			if !ord.Status.IsActive() {
+				b.log.Debugf("Order %s is no longer active: %s", bo.id, ord.Status)
+				delete(ords, i)
+				continue
+			}
+			bo.qty = ord.Qty - ord.Filled
+			bo.cancelling = bo.cancelling || ord.Cancelling
+		}
+	}
+}
+
+// committed sums the funds locked in the bot's standing orders. The ordMtx
+// must be locked.
+func (b *bot) committed() (base, quote uint64) {
+	for _, bo := range b.sells {
+		base += bo.qty
+	}
+	for _, bo := range b.buys {
+		quote += calc.BaseToQuote(bo.rate, bo.qty)
+	}
+	return
+}
+
+// ownTokens is the set of book tokens for the bot's orders. The tokens match
+// the order book's MiniOrder.Token.
+func (b *bot) ownTokens() map[string]bool {
+	b.ordMtx.RLock()
+	defer b.ordMtx.RUnlock()
+	tokens := make(map[string]bool, len(b.buys)+len(b.sells))
+	for _, ords := range []map[int]*botOrder{b.buys, b.sells} {
+		for _, bo := range ords {
+			if len(bo.id) >= 4 {
+				tokens[hex.EncodeToString(bo.id[:4])] = true
+			}
+		}
+	}
+	return tokens
+}
+
+// bestRates finds the best buy and sell rates on the book, ignoring the bot's
+// own orders. A zero rate indicates that side of the book is empty.
+func (b *bot) bestRates(book *core.OrderBook) (bestBuy, bestSell uint64) {
+	own := b.ownTokens()
+	for _, ord := range book.Buys {
+		if !own[ord.Token] {
+			bestBuy = ord.MsgRate
+			break
+		}
+	}
+	for _, ord := range book.Sells {
+		if !own[ord.Token] {
+			bestSell = ord.MsgRate
+			break
+		}
+	}
+	return
+}
+
+// basisPrice calculates the price around which orders are placed, along with
+// the book's half-spread, which is only needed for GapStrategyMultiplier.
+func (b *bot) basisPrice(cfg *BotConfig, mkt *core.Market, xc *core.Exchange) (basis, halfSpread uint64, err error) {
+	if cfg.GapStrategy == GapStrategyOracle {
+		if b.oracle == nil {
+			if mkt.SpotPrice == nil || mkt.SpotPrice.Rate == 0 {
+				return 0, 0, errors.New("no oracle configured and no spot price available")
+			}
+			return mkt.SpotPrice.Rate, 0, nil
+		}
+		baseAsset, quoteAsset := xc.Assets[cfg.BaseID], xc.Assets[cfg.QuoteID]
+		if baseAsset == nil || quoteAsset == nil {
+			return 0, 0, fmt.Errorf("missing asset info for %s", mkt.Name)
+		}
+		price, err := b.oracle.Price(cfg.BaseID, cfg.QuoteID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("oracle error: %w", err)
+		}
+		basis = calc.MessageRate(price, baseAsset.UnitInfo, quoteAsset.UnitInfo)
+		if basis == 0 {
+			return 0, 0, fmt.Errorf("oracle returned zero price for %s", mkt.Name)
+		}
+		return basis, 0, nil
+	}
+
+	book, err := b.core.Book(cfg.Host, cfg.BaseID, cfg.QuoteID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error retrieving order book: %w", err)
+	}
+	bestBuy, bestSell := b.bestRates(book)
+	switch {
+	case bestBuy > 0 && bestSell > 0:
+		return (bestBuy + bestSell) / 2, (bestSell - bestBuy) / 2, nil
+	case cfg.GapStrategy == GapStrategyMultiplier:
+		return 0, 0, errors.New("cannot calculate spread without orders on both sides of the book")
+	case bestBuy > 0:
+		return bestBuy, 0, nil
+	case bestSell > 0:
+		return bestSell, 0, nil
+	}
+	return 0, 0, errors.New("cannot calculate mid-gap from empty order book")
+}
+
+// targetRates calculates the buy and sell rates for the placement. A zero buy
+// rate indicates that no buy order should be placed.
+func targetRates(cfg *BotConfig, p *OrderPlacement, basis, halfSpread, rateStep uint64) (buyRate, sellRate uint64) {
+	var gap uint64
+	if cfg.GapStrategy == GapStrategyMultiplier {
+		gap = uint64(math.Round(float64(halfSpread) * p.GapFactor))
+	} else {
+		gap = uint64(math.Round(float64(basis) * p.GapFactor))
+	}
+	if rateStep == 0 {
+		rateStep = 1
+	}
+	if gap < basis {
+		buyRate = (basis - gap) / rateStep * rateStep
+	}
+	sellRate = (basis + gap + rateStep - 1) / rateStep * rateStep
+	return
+}
+
+// rebalance cancels any orders that have drifted too far from their target
+// rates and places orders for any empty placements, subject to the balance
+// limits.
+func (b *bot) rebalance() {
+	b.lastRebalance = time.Now()
+	cfg := b.config()
+	b.refreshOrders()
+
+	mkt, xc, err := b.market(cfg)
+	if err != nil {
+		b.log.Errorf("Market error: %v", err)
+		return
+	}
+	basis, halfSpread, err := b.basisPrice(cfg, mkt, xc)
+	if err != nil {
+		b.log.Errorf("Error calculating basis price for %s: %v", mkt.Name, err)
+		return
+	}
+	driftLimit := uint64(math.Round(float64(basis) * cfg.DriftTolerance))
+
+	b.ordMtx.Lock()
+	defer b.ordMtx.Unlock()
+
+	// Cancel orders for placements that were removed from the config.
+	for _, ords := range []map[int]*botOrder{b.buys, b.sells} {
+		for i, bo := range ords {
+			if i >= len(cfg.Placements) {
+				b.cancelOrder(bo)
+			}
+		}
+	}
+
+	baseCommitted, quoteCommitted := b.committed()
+	var baseAvail, quoteAvail uint64
+	if bal, err := b.core.AssetBalance(cfg.BaseID); err != nil {
+		b.log.Errorf("Error getting %s balance: %v", dex.BipIDSymbol(cfg.BaseID), err)
+	} else {
+		baseAvail = bal.Available
+	}
+	if bal, err := b.core.AssetBalance(cfg.QuoteID); err != nil {
+		b.log.Errorf("Error getting %s balance: %v", dex.BipIDSymbol(cfg.QuoteID), err)
+	} else {
+		quoteAvail = bal.Available
+	}
+
+	// New orders are collected and placed with a single MultiTrade for each
+	// side of the market.
+	type placement struct {
+		idx  int
+		qty  uint64
+		rate uint64
+	}
+	newOrds := make(map[bool][]*placement)
+
+	for i, p := range cfg.Placements {
+		buyRate, sellRate := targetRates(cfg, p, basis, halfSpread, mkt.RateStep)
+		qty := p.Lots * mkt.LotSize
+
+		for _, sell := range []bool{false, true} {
+			ords, rate := b.buys, buyRate
+			if sell {
+				ords, rate = b.sells, sellRate
+			}
+			if bo := ords[i]; bo != nil {
+				if !bo.cancelling && absDiff(bo.rate, rate) > driftLimit {
+					b.cancelOrder(bo)
+				}
+				continue
+			}
+			if rate == 0 {
+				continue
+			}
+			if sell {
+				if cfg.BaseBalance > 0 && baseCommitted+qty > cfg.BaseBalance {
+					b.log.Debugf("Base balance limit reached. Skipping sell placement %d", i)
+					continue
+				}
+				if qty > baseAvail {
+					b.log.Debugf("Insufficient %s balance. Skipping sell placement %d", mkt.BaseSymbol, i)
+					continue
+				}
+			} else {
+				quoteQty := calc.BaseToQuote(rate, qty)
+				if cfg.QuoteBalance > 0 && quoteCommitted+quoteQty > cfg.QuoteBalance {
+					b.log.Debugf("Quote balance limit reached. Skipping buy placement %d", i)
+					continue
+				}
+				if quoteQty > quoteAvail {
+					b.log.Debugf("Insufficient %s balance. Skipping buy placement %d", mkt.QuoteSymbol, i)
+					continue
+				}
+			}
+			newOrds[sell] = append(newOrds[sell], &placement{idx: i, qty: qty, rate: rate})
+			if sell {
+				baseCommitted += qty
+				baseAvail -= qty
+			} else {
+				quoteQty := calc.BaseToQuote(rate, qty)
+				quoteCommitted += quoteQty
+				quoteAvail -= quoteQty
+			}
+		}
+	}
+
+	for _, sell := range []bool{false, true} {
+		ps := newOrds[sell]
+		if len(ps) == 0 {
+			continue
+		}
+		qtyRates := make([]*core.QtyRate, 0, len(ps))
+		for _, p := range ps {
+			qtyRates = append(qtyRates, &core.QtyRate{Qty: p.qty, Rate: p.rate})
+		}
+		placed, err := b.core.MultiTradeUnlocked(&core.MultiTradeForm{
+			Host:       cfg.Host,
+			Sell:       sell,
+			Base:       cfg.BaseID,
+			Quote:      cfg.QuoteID,
+			Placements: qtyRates,
+		})
+		if err != nil {
+			b.log.Errorf("Error placing %d orders (sell = %t): %v", len(ps), sell, err)
+			continue
+		}
+		ords := b.buys
+		if sell {
+			ords = b.sells
+		}
+		// Orders are returned in placement order. If any were abandoned,
+		// match the rest up by rate and quantity.
+		for _, ord := range placed {
+			for j, p := range ps {
+				if p == nil || p.rate != ord.Rate || p.qty != ord.Qty {
+					continue
+				}
+				ords[p.idx] = &botOrder{
+					id:   ord.ID,
+					sell: sell,
+					rate: p.rate,
+					qty:  p.qty,
+				}
+				ps[j] = nil
+				break
+			}
+		}
+	}
+}
+
+// cancelOrder requests cancellation of the order. The order remains tracked
+// until its status shows that it is no longer active. The ordMtx must be
+// locked.
+func (b *bot) cancelOrder(bo *botOrder) {
+	if bo.cancelling {
+		return
+	}
+	if err := b.core.CancelUnlocked(bo.id); err != nil {
+		b.log.Errorf("Error canceling order %s: %v", bo.id, err)
+		return
+	}
+	bo.cancelling = true
+}
+
+// cancelAll cancels all of the bot's standing orders.
+func (b *bot) cancelAll() {
+	b.refreshOrders()
+	b.ordMtx.Lock()
+	defer b.ordMtx.Unlock()
+	for _, ords := range []map[int]*botOrder{b.buys, b.sells} {
+		for _, bo := range ords {
+			b.cancelOrder(bo)
+		}
+	}
+}
+
+func absDiff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,357 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package mm provides automated market making bots that drive the client Core.
+// A bot maintains standing buy and sell limit orders around a basis price,
+// re-placing them as the market moves. Bot configurations are persisted to
+// disk, but bots must be started with the app password before they will trade.
+package mm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/dex"
+)
+
+// clientCore is satisfied by core.Core.
+type clientCore interface {
+	Exchange(host string) (*core.Exchange, error)
+	SyncBook(host string, base, quote uint32) (core.BookFeed, error)
+	Book(host string, base, quote uint32) (*core.OrderBook, error)
+	OpenWallet(assetID uint32, appPW []byte) error
+	MultiTradeUnlocked(form *core.MultiTradeForm) ([]*core.Order, error)
+	CancelUnlocked(oid dex.Bytes) error
+	Order(oid dex.Bytes) (*core.Order, error)
+	AssetBalance(assetID uint32) (*core.WalletBalance, error)
+}
+
+var _ clientCore = (*core.Core)(nil)
+
+// Oracle is a source of exchange rates that is independent of the DEX order
+// book. Price returns the conventional exchange rate, i.e. the number of whole
+// quote asset units per whole base asset unit.
+type Oracle interface {
+	Price(base, quote uint32) (float64, error)
+}
+
+var (
+	// ErrUnknownBot is returned when the requested bot ID is not known.
+	ErrUnknownBot = errors.New("unknown bot")
+	// ErrBotRunning is returned when attempting to start a bot that is
+	// already running.
+	ErrBotRunning = errors.New("bot already running")
+	// ErrNotRunning is returned when a bot is started before Run.
+	ErrNotRunning = errors.New("market maker not running")
+)
+
+// Config is the configuration for the MarketMaker.
+type Config struct {
+	Core clientCore
+	// Oracle is optional. If not provided, bots using GapStrategyOracle will
+	// use the DEX's spot price.
+	Oracle Oracle
+	// BotsFile is the path of the JSON file where bot configurations are
+	// stored.
+	BotsFile string
+	Logger   dex.Logger
+}
+
+// BotStatus is the current state of a bot.
+type BotStatus struct {
+	ID      uint64      `json:"id"`
+	Config  *BotConfig  `json:"config"`
+	Running bool        `json:"running"`
+	Orders  []dex.Bytes `json:"orders"`
+}
+
+// botRecord is the on-disk representation of a bot.
+type botRecord struct {
+	ID     uint64     `json:"id"`
+	Config *BotConfig `json:"config"`
+}
+
+// MarketMaker manages a collection of market making bots.
+type MarketMaker struct {
+	core     clientCore
+	oracle   Oracle
+	log      dex.Logger
+	botsFile string
+
+	mtx    sync.RWMutex
+	ctx    context.Context
+	bots   map[uint64]*bot
+	nextID uint64
+	wg     sync.WaitGroup
+}
+
+// New is the constructor for a MarketMaker. Any previously saved bot
+// configurations are loaded, but none are started.
+func New(cfg *Config) (*MarketMaker, error) {
+	if cfg.Core == nil {
+		return nil, errors.New("no Core provided")
+	}
+	if cfg.BotsFile == "" {
+		return nil, errors.New("no bots file path provided")
+	}
+	log := cfg.Logger
+	if log == nil {
+		log = dex.Disabled
+	}
+	m := &MarketMaker{
+		core:     cfg.Core,
+		oracle:   cfg.Oracle,
+		log:      log,
+		botsFile: cfg.BotsFile,
+		bots:     make(map[uint64]*bot),
+		nextID:   1,
+	}
+	recs, err := m.loadBots()
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range recs {
+		if err := rec.Config.validate(); err != nil {
+			m.log.Errorf("Skipping invalid saved bot %d: %v", rec.ID, err)
+			continue
+		}
+		m.bots[rec.ID] = m.newBot(rec.ID, rec.Config)
+		if rec.ID >= m.nextID {
+			m.nextID = rec.ID + 1
+		}
+	}
+	return m, nil
+}
+
+// Run starts the MarketMaker. Bots may only be started while Run is running.
+// When the context is canceled, all running bots are stopped and their
+// standing orders are canceled.
+func (m *MarketMaker) Run(ctx context.Context) {
+	m.mtx.Lock()
+	m.ctx = ctx
+	m.mtx.Unlock()
+
+	<-ctx.Done()
+
+	m.mtx.Lock()
+	m.ctx = nil
+	m.mtx.Unlock()
+	m.wg.Wait()
+}
+
+// CreateBot saves a new bot configuration and starts the bot. The password is
+// used to unlock the market's wallets, and is not retained. The ID of the new
+// bot is returned.
+func (m *MarketMaker) CreateBot(pw []byte, cfg *BotConfig) (uint64, error) {
+	if err := cfg.validate(); err != nil {
+		return 0, err
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.ctx == nil {
+		return 0, ErrNotRunning
+	}
+	if err := m.checkMarket(cfg); err != nil {
+		return 0, err
+	}
+	if err := m.unlockWallets(cfg, pw); err != nil {
+		return 0, err
+	}
+	id := m.nextID
+	b := m.newBot(id, cfg)
+	m.bots[id] = b
+	if err := m.saveBots(); err != nil {
+		delete(m.bots, id)
+		return 0, err
+	}
+	m.nextID++
+	m.startBot(b)
+	return id, nil
+}
+
+// StartBot starts a stopped bot. The password is used to unlock the market's
+// wallets, and is not retained.
+func (m *MarketMaker) StartBot(pw []byte, id uint64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.ctx == nil {
+		return ErrNotRunning
+	}
+	b, found := m.bots[id]
+	if !found {
+		return ErrUnknownBot
+	}
+	if b.running() {
+		return ErrBotRunning
+	}
+	cfg := b.config()
+	if err := m.checkMarket(cfg); err != nil {
+		return err
+	}
+	if err := m.unlockWallets(cfg, pw); err != nil {
+		return err
+	}
+	m.startBot(b)
+	return nil
+}
+
+// unlockWallets unlocks the wallets for the bot's market, so that the bot can
+// trade without the password.
+func (m *MarketMaker) unlockWallets(cfg *BotConfig, pw []byte) error {
+	for _, assetID := range []uint32{cfg.BaseID, cfg.QuoteID} {
+		if err := m.core.OpenWallet(assetID, pw); err != nil {
+			return fmt.Errorf("error unlocking %s wallet: %w", dex.BipIDSymbol(assetID), err)
+		}
+	}
+	return nil
+}
+
+// startBot starts the bot's goroutine. The market's wallets must be unlocked.
+// The mtx must be locked.
+func (m *MarketMaker) startBot(b *bot) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	b.setRunning(cancel)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		b.run(ctx)
+	}()
+}
+
+// StopBot stops a running bot and cancels its standing orders. StopBot does
+// not return until the bot has stopped.
+func (m *MarketMaker) StopBot(id uint64) error {
+	m.mtx.RLock()
+	b, found := m.bots[id]
+	m.mtx.RUnlock()
+	if !found {
+		return ErrUnknownBot
+	}
+	b.stop()
+	return nil
+}
+
+// UpdateBot replaces the configuration of a bot. The new configuration must
+// be for the same market. If the bot is running, the new settings will take
+// effect on its next rebalance.
+func (m *MarketMaker) UpdateBot(id uint64, cfg *BotConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	b, found := m.bots[id]
+	if !found {
+		return ErrUnknownBot
+	}
+	oldCfg := b.config()
+	if oldCfg.Host != cfg.Host || oldCfg.BaseID != cfg.BaseID || oldCfg.QuoteID != cfg.QuoteID {
+		return errors.New("cannot change a bot's market")
+	}
+	b.setConfig(cfg)
+	if err := m.saveBots(); err != nil {
+		b.setConfig(oldCfg)
+		return err
+	}
+	return nil
+}
+
+// RetireBot stops the bot, cancels its orders, and deletes its configuration.
+func (m *MarketMaker) RetireBot(id uint64) error {
+	m.mtx.Lock()
+	b, found := m.bots[id]
+	if !found {
+		m.mtx.Unlock()
+		return ErrUnknownBot
+	}
+	delete(m.bots, id)
+	err := m.saveBots()
+	if err != nil {
+		m.bots[id] = b
+	}
+	m.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+	b.stop()
+	return nil
+}
+
+// Bots returns the status of every bot, sorted by ID.
+func (m *MarketMaker) Bots() []*BotStatus {
+	m.mtx.RLock()
+	bots := make([]*BotStatus, 0, len(m.bots))
+	for _, b := range m.bots {
+		bots = append(bots, b.status())
+	}
+	m.mtx.RUnlock()
+	sort.Slice(bots, func(i, j int) bool { return bots[i].ID < bots[j].ID })
+	return bots
+}
+
+// checkMarket checks that the bot's market is known to Core.
+func (m *MarketMaker) checkMarket(cfg *BotConfig) error {
+	xc, err := m.core.Exchange(cfg.Host)
+	if err != nil {
+		return err
+	}
+	mktName, err := dex.MarketName(cfg.BaseID, cfg.QuoteID)
+	if err != nil {
+		return err
+	}
+	if xc.Markets[mktName] == nil {
+		return fmt.Errorf("%s does not have a %s market", cfg.Host, mktName)
+	}
+	return nil
+}
+
+// loadBots reads the bot configurations from the bots file. A missing file is
+// not an error.
+func (m *MarketMaker) loadBots() ([]*botRecord, error) {
+	b, err := os.ReadFile(m.botsFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading bots file: %w", err)
+	}
+	var recs []*botRecord
+	if err := json.Unmarshal(b, &recs); err != nil {
+		return nil, fmt.Errorf("error decoding bots file: %w", err)
+	}
+	return recs, nil
+}
+
+// saveBots writes all bot configurations to the bots file. The mtx must be
+// locked.
+func (m *MarketMaker) saveBots() error {
+	recs := make([]*botRecord, 0, len(m.bots))
+	for id, b := range m.bots {
+		recs = append(recs, &botRecord{ID: id, Config: b.config()})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].ID < recs[j].ID })
+	b, err := json.MarshalIndent(recs, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error encoding bots: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.botsFile), 0700); err != nil {
+		return fmt.Errorf("error creating bots file directory: %w", err)
+	}
+	// Write to a temporary file first so that a failed write does not
+	// clobber the existing configurations.
+	tmpPath := m.botsFile + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0600); err != nil {
+		return fmt.Errorf("error writing bots file: %w", err)
+	}
+	if err := os.Rename(tmpPath, m.botsFile); err != nil {
+		return fmt.Errorf("error replacing bots file: %w", err)
+	}
+	return nil
+}
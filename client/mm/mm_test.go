@@ -0,0 +1,609 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package mm
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+)
+
+const (
+	tHost            = "somedex.tld"
+	tBaseID   uint32 = 42
+	tQuoteID  uint32 = 0
+	tLotSize  uint64 = 1e8
+	tRateStep uint64 = 100
+)
+
+var (
+	tMktName, _ = dex.MarketName(tBaseID, tQuoteID)
+	tErr        = errors.New("test error")
+)
+
+type tBookFeed struct {
+	c chan *core.BookUpdate
+}
+
+func (f *tBookFeed) Next() <-chan *core.BookUpdate { return f.c }
+func (f *tBookFeed) Close()                        {}
+func (f *tBookFeed) Candles(dur string) error      { return nil }
+
+type TCore struct {
+	mtx         sync.Mutex
+	xc          *core.Exchange
+	book        *core.OrderBook
+	orders      map[string]*core.Order
+	trades      []*core.TradeForm
+	multiTrades int
+	tradeErr    error
+	cancels     []dex.Bytes
+	cancelErr   error
+	bals        map[uint32]uint64
+	unlocked    map[uint32]bool
+	unlockErr   error
+}
+
+var _ clientCore = (*TCore)(nil)
+
+func newTCore() *TCore {
+	return &TCore{
+		xc: &core.Exchange{
+			Host: tHost,
+			Markets: map[string]*core.Market{
+				tMktName: {
+					Name:        tMktName,
+					BaseID:      tBaseID,
+					BaseSymbol:  "dcr",
+					QuoteID:     tQuoteID,
+					QuoteSymbol: "btc",
+					LotSize:     tLotSize,
+					RateStep:    tRateStep,
+					EpochLen:    60000,
+				},
+			},
+			Assets: map[uint32]*dex.Asset{
+				tBaseID:  {ID: tBaseID, Symbol: "dcr", UnitInfo: dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e8}}},
+				tQuoteID: {ID: tQuoteID, Symbol: "btc", UnitInfo: dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e8}}},
+			},
+		},
+		book:   new(core.OrderBook),
+		orders: make(map[string]*core.Order),
+		bals: map[uint32]uint64{
+			tBaseID:  100 * tLotSize,
+			tQuoteID: 100 * tLotSize,
+		},
+		unlocked: make(map[uint32]bool),
+	}
+}
+
+func (c *TCore) Exchange(host string) (*core.Exchange, error) {
+	if host != tHost {
+		return nil, tErr
+	}
+	return c.xc, nil
+}
+
+func (c *TCore) SyncBook(host string, base, quote uint32) (core.BookFeed, error) {
+	return &tBookFeed{c: make(chan *core.BookUpdate)}, nil
+}
+
+func (c *TCore) Book(host string, base, quote uint32) (*core.OrderBook, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.book, nil
+}
+
+func (c *TCore) OpenWallet(assetID uint32, appPW []byte) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.unlockErr != nil {
+		return c.unlockErr
+	}
+	c.unlocked[assetID] = true
+	return nil
+}
+
+func (c *TCore) MultiTradeUnlocked(form *core.MultiTradeForm) ([]*core.Order, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.tradeErr != nil {
+		return nil, c.tradeErr
+	}
+	c.multiTrades++
+	ords := make([]*core.Order, 0, len(form.Placements))
+	for _, p := range form.Placements {
+		c.trades = append(c.trades, &core.TradeForm{
+			Host:    form.Host,
+			IsLimit: true,
+			Sell:    form.Sell,
+			Base:    form.Base,
+			Quote:   form.Quote,
+			Qty:     p.Qty,
+			Rate:    p.Rate,
+		})
+		ord := &core.Order{
+			ID:     encode.RandomBytes(32),
+			Status: order.OrderStatusBooked,
+			Qty:    p.Qty,
+			Rate:   p.Rate,
+			Sell:   form.Sell,
+		}
+		c.orders[ord.ID.String()] = ord
+		ords = append(ords, ord)
+	}
+	return ords, nil
+}
+
+func (c *TCore) CancelUnlocked(oid dex.Bytes) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.cancelErr != nil {
+		return c.cancelErr
+	}
+	c.cancels = append(c.cancels, oid)
+	return nil
+}
+
+func (c *TCore) Order(oid dex.Bytes) (*core.Order, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	ord, found := c.orders[oid.String()]
+	if !found {
+		return nil, tErr
+	}
+	return ord, nil
+}
+
+func (c *TCore) AssetBalance(assetID uint32) (*core.WalletBalance, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return &core.WalletBalance{Balance: &db.Balance{
+		Balance: asset.Balance{Available: c.bals[assetID]},
+	}}, nil
+}
+
+func (c *TCore) setBook(bestBuy, bestSell uint64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.book = new(core.OrderBook)
+	if bestBuy > 0 {
+		c.book.Buys = []*core.MiniOrder{{MsgRate: bestBuy, Token: "aaaaaaaa"}}
+	}
+	if bestSell > 0 {
+		c.book.Sells = []*core.MiniOrder{{MsgRate: bestSell, Sell: true, Token: "bbbbbbbb"}}
+	}
+}
+
+func (c *TCore) setOrderStatus(oid dex.Bytes, status order.OrderStatus) {
+	c.mtx.Lock()
+	c.orders[oid.String()].Status = status
+	c.mtx.Unlock()
+}
+
+func (c *TCore) clearHistory() {
+	c.mtx.Lock()
+	c.trades = nil
+	c.multiTrades = 0
+	c.cancels = nil
+	c.mtx.Unlock()
+}
+
+type tOracle struct {
+	price float64
+	err   error
+}
+
+func (o *tOracle) Price(base, quote uint32) (float64, error) {
+	return o.price, o.err
+}
+
+func newTestBot(tCore *TCore, cfg *BotConfig) *bot {
+	m := &MarketMaker{core: tCore, log: dex.StdOutLogger("T", dex.LevelTrace)}
+	return m.newBot(1, cfg)
+}
+
+func tBotConfig(strategy GapStrategy, placements ...*OrderPlacement) *BotConfig {
+	return &BotConfig{
+		Host:           tHost,
+		BaseID:         tBaseID,
+		QuoteID:        tQuoteID,
+		GapStrategy:    strategy,
+		Placements:     placements,
+		DriftTolerance: 0.005,
+	}
+}
+
+func TestBotConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *BotConfig
+		wantErr bool
+	}{{
+		name: "ok",
+		cfg:  tBotConfig(GapStrategyPercent, &OrderPlacement{Lots: 1, GapFactor: 0.01}),
+	}, {
+		name: "multiplier > 1 ok",
+		cfg:  tBotConfig(GapStrategyMultiplier, &OrderPlacement{Lots: 1, GapFactor: 2}),
+	}, {
+		name:    "percent >= 1",
+		cfg:     tBotConfig(GapStrategyPercent, &OrderPlacement{Lots: 1, GapFactor: 1}),
+		wantErr: true,
+	}, {
+		name:    "zero lots",
+		cfg:     tBotConfig(GapStrategyOracle, &OrderPlacement{GapFactor: 0.01}),
+		wantErr: true,
+	}, {
+		name:    "zero gap",
+		cfg:     tBotConfig(GapStrategyOracle, &OrderPlacement{Lots: 1}),
+		wantErr: true,
+	}, {
+		name:    "no placements",
+		cfg:     tBotConfig(GapStrategyPercent),
+		wantErr: true,
+	}, {
+		name:    "unknown strategy",
+		cfg:     tBotConfig("fancy", &OrderPlacement{Lots: 1, GapFactor: 0.01}),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		err := tt.cfg.validate()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: wanted error = %t, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+	cfg := tBotConfig(GapStrategyPercent, &OrderPlacement{Lots: 1, GapFactor: 0.01})
+	cfg.QuoteID = cfg.BaseID
+	if cfg.validate() == nil {
+		t.Fatalf("no error for same base and quote")
+	}
+}
+
+func TestTargetRates(t *testing.T) {
+	cfg := tBotConfig(GapStrategyPercent)
+	p := &OrderPlacement{Lots: 1, GapFactor: 0.01}
+	buy, sell := targetRates(cfg, p, 1e6, 0, tRateStep)
+	if buy != 990000 || sell != 1010000 {
+		t.Fatalf("wrong percent rates %d, %d", buy, sell)
+	}
+
+	// Rates are rounded away from the basis price to the rate step.
+	buy, sell = targetRates(cfg, p, 1e6+50, 0, tRateStep)
+	if buy != 990000 || sell != 1010100 {
+		t.Fatalf("wrong rounded rates %d, %d", buy, sell)
+	}
+
+	cfg.GapStrategy = GapStrategyMultiplier
+	p.GapFactor = 2
+	buy, sell = targetRates(cfg, p, 1e6, 5000, tRateStep)
+	if buy != 990000 || sell != 1010000 {
+		t.Fatalf("wrong multiplier rates %d, %d", buy, sell)
+	}
+
+	// A gap wider than the basis price results in no buy.
+	buy, _ = targetRates(cfg, p, 1e6, 6e5, tRateStep)
+	if buy != 0 {
+		t.Fatalf("expected no buy rate, got %d", buy)
+	}
+}
+
+func TestBasisPrice(t *testing.T) {
+	tCore := newTCore()
+	mkt := tCore.xc.Markets[tMktName]
+	b := newTestBot(tCore, tBotConfig(GapStrategyMultiplier, &OrderPlacement{Lots: 1, GapFactor: 1}))
+
+	checkBasis := func(tag string, expBasis, expHalfSpread uint64, expErr bool) {
+		t.Helper()
+		basis, halfSpread, err := b.basisPrice(b.config(), mkt, tCore.xc)
+		if expErr {
+			if err == nil {
+				t.Fatalf("%s: no error", tag)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tag, err)
+		}
+		if basis != expBasis || halfSpread != expHalfSpread {
+			t.Fatalf("%s: wanted basis %d, half-spread %d, got %d, %d",
+				tag, expBasis, expHalfSpread, basis, halfSpread)
+		}
+	}
+
+	tCore.setBook(0, 0)
+	checkBasis("empty book", 0, 0, true)
+
+	tCore.setBook(9e5, 0)
+	checkBasis("one-sided multiplier", 0, 0, true)
+
+	b.cfg.GapStrategy = GapStrategyPercent
+	checkBasis("one-sided percent", 9e5, 0, false)
+
+	tCore.setBook(9e5, 11e5)
+	checkBasis("two-sided", 1e6, 1e5, false)
+
+	// The bot's own orders are not considered.
+	ownID := encode.RandomBytes(32)
+	b.buys[0] = &botOrder{id: ownID, rate: 95e4}
+	tCore.book.Buys = append([]*core.MiniOrder{{MsgRate: 95e4, Token: hex.EncodeToString(ownID[:4])}}, tCore.book.Buys...)
+	checkBasis("own orders", 1e6, 1e5, false)
+	delete(b.buys, 0)
+
+	// Oracle without an oracle falls back to the spot price.
+	b.cfg.GapStrategy = GapStrategyOracle
+	checkBasis("no spot price", 0, 0, true)
+	mkt.SpotPrice = &msgjson.Spot{Rate: 12e5}
+	checkBasis("spot price", 12e5, 0, false)
+
+	oracle := &tOracle{price: 0.013}
+	b.oracle = oracle
+	checkBasis("oracle", 13e5, 0, false)
+	oracle.err = tErr
+	checkBasis("oracle error", 0, 0, true)
+}
+
+func TestRebalance(t *testing.T) {
+	tCore := newTCore()
+	tCore.setBook(99e4, 101e4)
+	cfg := tBotConfig(GapStrategyPercent,
+		&OrderPlacement{Lots: 1, GapFactor: 0.01},
+		&OrderPlacement{Lots: 2, GapFactor: 0.02},
+	)
+	// Only enough base balance for the first sell.
+	cfg.BaseBalance = 2 * tLotSize
+	b := newTestBot(tCore, cfg)
+	b.rebalance()
+	if len(tCore.trades) != 3 {
+		t.Fatalf("expected 3 orders, got %d", len(tCore.trades))
+	}
+	// One batch for each side.
+	if tCore.multiTrades != 2 {
+		t.Fatalf("expected 2 multi-trades, got %d", tCore.multiTrades)
+	}
+	if len(b.buys) != 2 || len(b.sells) != 1 {
+		t.Fatalf("wrong number of tracked orders. %d buys, %d sells", len(b.buys), len(b.sells))
+	}
+	expRates := map[bool][]uint64{
+		false: {990000, 980000},
+		true:  {1010000},
+	}
+	for _, form := range tCore.trades {
+		if !form.IsLimit || form.TifNow {
+			t.Fatalf("expected standing limit order")
+		}
+		rates := expRates[form.Sell]
+		var found bool
+		for i, r := range rates {
+			if r == form.Rate {
+				expRates[form.Sell] = append(rates[:i], rates[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("unexpected order rate %d, sell = %t", form.Rate, form.Sell)
+		}
+	}
+	if b.buys[1].qty != 2*tLotSize {
+		t.Fatalf("wrong placement lots")
+	}
+
+	// No change in the market. Nothing to do.
+	tCore.clearHistory()
+	b.rebalance()
+	if len(tCore.trades) != 0 || len(tCore.cancels) != 0 {
+		t.Fatalf("unexpected orders or cancels with no market change")
+	}
+
+	// Move the market beyond the drift tolerance. All orders are canceled.
+	tCore.setBook(104e4, 106e4)
+	b.rebalance()
+	if len(tCore.cancels) != 3 {
+		t.Fatalf("expected 3 cancels, got %d", len(tCore.cancels))
+	}
+	if len(tCore.trades) != 0 {
+		t.Fatalf("orders placed before cancels completed")
+	}
+
+	// Orders aren't canceled twice.
+	tCore.clearHistory()
+	b.rebalance()
+	if len(tCore.cancels) != 0 {
+		t.Fatalf("orders canceled twice")
+	}
+
+	// Once the cancels are processed, new orders are placed.
+	for _, side := range []map[int]*botOrder{b.buys, b.sells} {
+		for _, bo := range side {
+			tCore.setOrderStatus(bo.id, order.OrderStatusCanceled)
+		}
+	}
+	b.rebalance()
+	if len(tCore.trades) != 3 {
+		t.Fatalf("expected 3 new orders, got %d", len(tCore.trades))
+	}
+	if b.sells[0].rate != 1060500 {
+		t.Fatalf("wrong new sell rate %d", b.sells[0].rate)
+	}
+
+	// A filled order is replaced.
+	tCore.clearHistory()
+	tCore.setOrderStatus(b.sells[0].id, order.OrderStatusExecuted)
+	b.rebalance()
+	if len(tCore.trades) != 1 || !tCore.trades[0].Sell {
+		t.Fatalf("filled sell not replaced")
+	}
+
+	// Trade errors are not tracked.
+	tCore.clearHistory()
+	tCore.setOrderStatus(b.sells[0].id, order.OrderStatusExecuted)
+	tCore.tradeErr = tErr
+	b.rebalance()
+	if len(b.sells) != 0 {
+		t.Fatalf("failed order tracked")
+	}
+	tCore.tradeErr = nil
+
+	// Removing a placement cancels its orders.
+	tCore.clearHistory()
+	b.setConfig(tBotConfig(GapStrategyPercent, &OrderPlacement{Lots: 1, GapFactor: 0.01}))
+	b.rebalance()
+	if len(tCore.cancels) != 1 || !bytes.Equal(tCore.cancels[0], b.buys[1].id) {
+		t.Fatalf("removed placement not canceled")
+	}
+}
+
+func TestMarketMaker(t *testing.T) {
+	tCore := newTCore()
+	tCore.setBook(99e4, 101e4)
+	botsFile := filepath.Join(t.TempDir(), "bots.json")
+	newMM := func() *MarketMaker {
+		t.Helper()
+		m, err := New(&Config{
+			Core:     tCore,
+			BotsFile: botsFile,
+			Logger:   dex.StdOutLogger("T", dex.LevelTrace),
+		})
+		if err != nil {
+			t.Fatalf("New error: %v", err)
+		}
+		return m
+	}
+	m := newMM()
+
+	cfg := tBotConfig(GapStrategyPercent, &OrderPlacement{Lots: 1, GapFactor: 0.01})
+	pw := []byte("abc")
+	if _, err := m.CreateBot(pw, cfg); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.Run(ctx)
+	}()
+	timeout := time.After(time.Second)
+	for {
+		m.mtx.RLock()
+		running := m.ctx != nil
+		m.mtx.RUnlock()
+		if running {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("MarketMaker not started")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	badCfg := cfg.copy()
+	badCfg.QuoteID = 60
+	if _, err := m.CreateBot(pw, badCfg); err == nil {
+		t.Fatalf("no error for unknown market")
+	}
+
+	tCore.unlockErr = tErr
+	if _, err := m.CreateBot(pw, cfg); err == nil {
+		t.Fatalf("no error for a wallet unlock failure")
+	}
+	tCore.unlockErr = nil
+
+	id, err := m.CreateBot(pw, cfg)
+	if err != nil {
+		t.Fatalf("CreateBot error: %v", err)
+	}
+	tCore.mtx.Lock()
+	unlocked := tCore.unlocked[tBaseID] && tCore.unlocked[tQuoteID]
+	tCore.mtx.Unlock()
+	if !unlocked {
+		t.Fatalf("wallets not unlocked when the bot was created")
+	}
+	if err := m.StartBot(pw, id); !errors.Is(err, ErrBotRunning) {
+		t.Fatalf("expected ErrBotRunning, got %v", err)
+	}
+
+	// Wait for the initial orders.
+	timeout = time.After(time.Second)
+	for {
+		if bots := m.Bots(); len(bots) == 1 && len(bots[0].Orders) == 2 {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("bot orders not placed")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	if err := m.StopBot(id); err != nil {
+		t.Fatalf("StopBot error: %v", err)
+	}
+	if m.Bots()[0].Running {
+		t.Fatalf("bot still running after StopBot")
+	}
+	tCore.mtx.Lock()
+	nCancels := len(tCore.cancels)
+	tCore.mtx.Unlock()
+	if nCancels != 2 {
+		t.Fatalf("expected 2 cancels on stop, got %d", nCancels)
+	}
+
+	newCfg := cfg.copy()
+	newCfg.Placements[0].Lots = 3
+	if err := m.UpdateBot(id, newCfg); err != nil {
+		t.Fatalf("UpdateBot error: %v", err)
+	}
+	badCfg = newCfg.copy()
+	badCfg.BaseID = 3
+	if err := m.UpdateBot(id, badCfg); err == nil {
+		t.Fatalf("no error for changing the market")
+	}
+
+	// The configuration is loaded from file.
+	m2 := newMM()
+	bots := m2.Bots()
+	if len(bots) != 1 || bots[0].ID != id || bots[0].Running {
+		t.Fatalf("bot not loaded from file")
+	}
+	if bots[0].Config.Placements[0].Lots != 3 {
+		t.Fatalf("updated config not saved")
+	}
+	if m2.nextID != id+1 {
+		t.Fatalf("wrong next ID %d", m2.nextID)
+	}
+
+	if err := m.RetireBot(id); err != nil {
+		t.Fatalf("RetireBot error: %v", err)
+	}
+	if err := m.RetireBot(id); !errors.Is(err, ErrUnknownBot) {
+		t.Fatalf("expected ErrUnknownBot, got %v", err)
+	}
+	if bots := newMM().Bots(); len(bots) != 0 {
+		t.Fatalf("retired bot still saved")
+	}
+
+	cancel()
+	wg.Wait()
+	if _, err := os.Stat(botsFile); err != nil {
+		t.Fatalf("bots file error: %v", err)
+	}
+}
@@ -118,8 +118,27 @@ func (d *bookSide) Remove(oid order.OrderID, rateBin uint64) error {
 	return fmt.Errorf("order %s not found with rate %d", oid, rateBin)
 }
 
-// UpdateRemaining updates the remaining quantity for an order.
+// UpdateRemaining updates the remaining quantity for an order. Any hidden
+// quantity is cleared.
 func (d *bookSide) UpdateRemaining(oid order.OrderID, rateBin, remaining uint64) {
+	d.updateOrder(oid, rateBin, func(ord *Order) {
+		ord.Quantity = remaining
+		ord.Hidden = 0
+	})
+}
+
+// SetHidden sets the hidden quantity of an iceberg order. The return value
+// indicates if the order was found.
+func (d *bookSide) SetHidden(oid order.OrderID, rateBin, hidden uint64) bool {
+	return d.updateOrder(oid, rateBin, func(ord *Order) {
+		ord.Hidden = hidden
+	})
+}
+
+// updateOrder replaces an order with a modified copy so that copies returned
+// by Orders and BestNOrders aren't modified. The return value indicates if the
+// order was found.
+func (d *bookSide) updateOrder(oid order.OrderID, rateBin uint64, update func(*Order)) bool {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
@@ -128,11 +147,12 @@ func (d *bookSide) UpdateRemaining(oid order.OrderID, rateBin, remaining uint64)
 		if ord.OrderID == oid {
 			// Make a new Order so other copies aren't modified.
 			newOrder := *ord // deep copy
-			newOrder.Quantity = remaining
+			update(&newOrder)
 			bin[i] = &newOrder
-			return
+			return true
 		}
 	}
+	return false
 }
 
 // Orders is all orders for the side, sorted. Returned orders are copies and
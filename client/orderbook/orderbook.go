@@ -23,6 +23,10 @@ type Order struct {
 	Time     uint64
 	// Epoch is only used in the epoch queue, otherwise it is ignored.
 	Epoch uint64
+	// Hidden is the quantity held in reserve by an iceberg order, in addition
+	// to the displayed Quantity. The server does not reveal the hidden
+	// quantity, so it is only set for the user's own orders. See SetHidden.
+	Hidden uint64
 }
 
 func (o *Order) sell() bool {
@@ -384,6 +388,28 @@ func (ob *OrderBook) UpdateRemaining(note *msgjson.UpdateRemainingNote) error {
 	return ob.updateRemaining(note, false)
 }
 
+// SetHidden sets the hidden reserve quantity of a booked iceberg order. The
+// hidden quantity is not broadcast by the server, so this is only possible for
+// the user's own orders. The hidden quantity must be set again after the order's
+// remaining quantity is updated.
+func (ob *OrderBook) SetHidden(oid order.OrderID, hidden uint64) error {
+	ob.ordersMtx.Lock()
+	ordInfo, found := ob.orders[oid]
+	ob.ordersMtx.Unlock()
+	if !found {
+		return fmt.Errorf("order %s not found", oid)
+	}
+
+	side := ob.buys
+	if ordInfo.sell {
+		side = ob.sells
+	}
+	if !side.SetHidden(oid, ordInfo.rate, hidden) {
+		return fmt.Errorf("order %s not found with rate %d", oid, ordInfo.rate)
+	}
+	return nil
+}
+
 // LogEpochReport is currently a no-op, and will update market history charts in
 // the future.
 func (ob *OrderBook) LogEpochReport(note *msgjson.EpochReportNote) error {
@@ -473,6 +473,52 @@ func TestOrderBookUpdateRemaining(t *testing.T) {
 	}
 }
 
+func TestOrderBookSetHidden(t *testing.T) {
+	mid := "abc_xyz"
+	oid := order.OrderID{0x01}
+
+	book := makeOrderBook(
+		1,
+		mid,
+		[]*Order{
+			makeOrder(oid, msgjson.SellOrderNum, 10, 1, 2),
+		},
+		make([]*cachedOrderNote, 0),
+		true,
+	)
+
+	_, before, _ := book.Orders()
+	if err := book.SetHidden(oid, 30); err != nil {
+		t.Fatalf("SetHidden error: %v", err)
+	}
+	_, sells, _ := book.Orders()
+	if sells[0].Hidden != 30 || sells[0].Quantity != 10 {
+		t.Fatalf("wrong hidden/visible quantity %d/%d", sells[0].Hidden, sells[0].Quantity)
+	}
+	if before[0].Hidden != 0 {
+		t.Fatalf("previously returned order was modified")
+	}
+
+	// Updating the remaining quantity clears the hidden quantity.
+	err := book.UpdateRemaining(&msgjson.UpdateRemainingNote{
+		OrderNote: msgjson.OrderNote{
+			OrderID:  oid[:],
+			MarketID: mid,
+		},
+		Remaining: 10,
+	})
+	if err != nil {
+		t.Fatalf("error updating remaining qty: %v", err)
+	}
+	if _, sells, _ = book.Orders(); sells[0].Hidden != 0 {
+		t.Fatalf("hidden quantity not cleared")
+	}
+
+	if err = book.SetHidden(order.OrderID{0x02}, 30); err == nil {
+		t.Fatalf("no error setting hidden quantity for unknown order")
+	}
+}
+
 func TestOrderBookUnbook(t *testing.T) {
 	tests := []struct {
 		label     string
@@ -4,6 +4,7 @@
 package rpcserver
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -12,7 +13,6 @@ import (
 
 	"decred.org/dcrdex/client/core"
 	"decred.org/dcrdex/dex"
-	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/order"
 )
@@ -41,6 +41,19 @@ const (
 	sendRoute                  = "send"
 	appSeedRoute               = "appseed"
 	deleteArchivedRecordsRoute = "deletearchivedrecords"
+	createBotRoute             = "createbot"
+	startBotRoute              = "startbot"
+	stopBotRoute               = "stopbot"
+	updateBotRoute             = "updatebot"
+	retireBotRoute             = "retirebot"
+	botsRoute                  = "bots"
+	stopOrderRoute             = "stoporder"
+	stopOrdersRoute            = "stoporders"
+	cancelStopOrderRoute       = "cancelstoporder"
+	multiTradeRoute            = "multitrade"
+	exportTradesRoute          = "exporttrades"
+	txHistoryRoute             = "txhistory"
+	listUnspentRoute           = "listunspent"
 )
 
 const (
@@ -50,6 +63,11 @@ const (
 	walletUnlockedStr = "%s wallet unlocked"
 	canceledOrderStr  = "canceled order %s"
 	logoutStr         = "goodbye"
+	botStartedStr     = "bot %d started"
+	botStoppedStr     = "bot %d stopped"
+	botUpdatedStr     = "bot %d updated"
+	botRetiredStr     = "bot %d retired"
+	canceledStopStr   = "canceled stop order %s"
 )
 
 // createResponse creates a msgjson response payload.
@@ -94,6 +112,19 @@ var routes = map[string]func(s *RPCServer, params *RawParams) *msgjson.ResponseP
 	sendRoute:                  handleSend,
 	appSeedRoute:               handleAppSeed,
 	deleteArchivedRecordsRoute: handleDeleteArchivedRecords,
+	createBotRoute:             handleCreateBot,
+	startBotRoute:              handleStartBot,
+	stopBotRoute:               handleStopBot,
+	updateBotRoute:             handleUpdateBot,
+	retireBotRoute:             handleRetireBot,
+	botsRoute:                  handleBots,
+	stopOrderRoute:             handleStopOrder,
+	multiTradeRoute:            handleMultiTrade,
+	exportTradesRoute:          handleExportTrades,
+	txHistoryRoute:             handleTxHistory,
+	listUnspentRoute:           handleListUnspent,
+	stopOrdersRoute:            handleStopOrders,
+	cancelStopOrderRoute:       handleCancelStopOrder,
 }
 
 // handleHelp handles requests for help. Returns general help for all commands
@@ -127,10 +158,7 @@ func handleInit(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	if err != nil {
 		return usage(initRoute, err)
 	}
-	defer func() {
-		appPass.Clear()
-		seed.Clear()
-	}()
+	defer appPass.Clear()
 	if err := s.core.InitializeClient(appPass, seed); err != nil {
 		errMsg := fmt.Sprintf("unable to initialize client: %v", err)
 		resErr := msgjson.NewError(msgjson.RPCInitError, errMsg)
@@ -404,6 +432,31 @@ func handleTrade(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	return createResponse(tradeRoute, &tradeRes, nil)
 }
 
+// handleMultiTrade handles requests for multitrade.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleMultiTrade(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseMultiTradeArgs(params)
+	if err != nil {
+		return usage(multiTradeRoute, err)
+	}
+	defer form.appPass.Clear()
+	ords, err := s.core.MultiTrade(form.appPass, form.srvForm)
+	if err != nil {
+		errMsg := fmt.Sprintf("unable to place orders: %v", err)
+		resErr := msgjson.NewError(msgjson.RPCTradeError, errMsg)
+		return createResponse(multiTradeRoute, nil, resErr)
+	}
+	res := make([]*tradeResponse, 0, len(ords))
+	for _, ord := range ords {
+		res = append(res, &tradeResponse{
+			OrderID: ord.ID.String(),
+			Sig:     ord.Sig.String(),
+			Stamp:   ord.Stamp,
+		})
+	}
+	return createResponse(multiTradeRoute, res, nil)
+}
+
 // handleCancel handles requests for cancel. *msgjson.ResponsePayload.Error is
 // empty if successful.
 func handleCancel(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
@@ -443,7 +496,7 @@ func send(s *RPCServer, params *RawParams, route string) *msgjson.ResponsePayloa
 	if route == withdrawRoute {
 		subtract = true
 	}
-	coin, err := s.core.Send(form.appPass, form.assetID, form.value, form.address, subtract)
+	coin, err := s.core.Send(form.appPass, form.assetID, form.value, form.address, subtract, form.coins)
 	if err != nil {
 		errMsg := fmt.Sprintf("unable to %s: %v", err, route)
 		resErr := msgjson.NewError(msgjson.RPCFundTransferError, errMsg)
@@ -619,6 +672,58 @@ func handleMyOrders(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	return createResponse(myOrdersRoute, myOrders, nil)
 }
 
+// handleExportTrades handles requests for exporttrades. The result is a string
+// for CSV, or an array of trade records for JSON.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleExportTrades(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseExportTradesArgs(params)
+	if err != nil {
+		return usage(exportTradesRoute, err)
+	}
+	var b bytes.Buffer
+	if err := s.core.ExportTrades(&b, form); err != nil {
+		errMsg := fmt.Sprintf("unable to export trades: %v", err)
+		resErr := msgjson.NewError(msgjson.RPCExportTradesError, errMsg)
+		return createResponse(exportTradesRoute, nil, resErr)
+	}
+	if form.Format == core.ExportFormatJSON {
+		return createResponse(exportTradesRoute, json.RawMessage(b.Bytes()), nil)
+	}
+	return createResponse(exportTradesRoute, b.String(), nil)
+}
+
+// handleTxHistory handles requests for a wallet's transaction history.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleTxHistory(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseTxHistoryArgs(params)
+	if err != nil {
+		return usage(txHistoryRoute, err)
+	}
+	txs, err := s.core.TxHistory(form.assetID, form.n, form.refID)
+	if err != nil {
+		errMsg := fmt.Sprintf("unable to get transaction history: %v", err)
+		resErr := msgjson.NewError(msgjson.RPCTxHistoryError, errMsg)
+		return createResponse(txHistoryRoute, nil, resErr)
+	}
+	return createResponse(txHistoryRoute, txs, nil)
+}
+
+// handleListUnspent handles requests to list a wallet's spendable outputs.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleListUnspent(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseListUnspentArgs(params)
+	if err != nil {
+		return usage(listUnspentRoute, err)
+	}
+	unspents, err := s.core.ListUnspent(form.assetID)
+	if err != nil {
+		errMsg := fmt.Sprintf("unable to list unspent outputs: %v", err)
+		resErr := msgjson.NewError(msgjson.RPCListUnspentError, errMsg)
+		return createResponse(listUnspentRoute, nil, resErr)
+	}
+	return createResponse(listUnspentRoute, unspents, nil)
+}
+
 // handleAppSeed handles requests for the app seed. *msgjson.ResponsePayload.Error
 // is empty if successful.
 func handleAppSeed(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
@@ -628,16 +733,12 @@ func handleAppSeed(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	}
 	defer appPass.Clear()
 	seed, err := s.core.ExportSeed(appPass)
-	defer encode.ClearBytes(seed)
 	if err != nil {
 		errMsg := fmt.Sprintf("unable to retrieve app seed: %v", err)
 		resErr := msgjson.NewError(msgjson.RPCExportSeedError, errMsg)
 		return createResponse(appSeedRoute, nil, resErr)
 	}
-	// Zero seed and hex representation after use.
-	seedHex := fmt.Sprintf("%x", seed[:])
-
-	return createResponse(appSeedRoute, seedHex, nil)
+	return createResponse(appSeedRoute, seed, nil)
 }
 
 // handleDeleteArchivedRecords handles requests for deleting archived records.
@@ -655,6 +756,158 @@ func handleDeleteArchivedRecords(s *RPCServer, params *RawParams) *msgjson.Respo
 	return createResponse(deleteArchivedRecordsRoute, nil, nil)
 }
 
+// errNoMarketMaker is the response for the bot routes when the RPCServer was
+// not configured with a MarketMaker.
+func errNoMarketMaker(route string) *msgjson.ResponsePayload {
+	resErr := msgjson.NewError(msgjson.RPCMarketMakerError, "market maker not enabled")
+	return createResponse(route, nil, resErr)
+}
+
+// handleCreateBot handles requests for createbot.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleCreateBot(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	if s.mm == nil {
+		return errNoMarketMaker(createBotRoute)
+	}
+	form, err := parseCreateBotArgs(params)
+	if err != nil {
+		return usage(createBotRoute, err)
+	}
+	defer form.appPass.Clear()
+	id, err := s.mm.CreateBot(form.appPass, form.cfg)
+	if err != nil {
+		errMsg := fmt.Sprintf("unable to create bot: %v", err)
+		resErr := msgjson.NewError(msgjson.RPCMarketMakerError, errMsg)
+		return createResponse(createBotRoute, nil, resErr)
+	}
+	return createResponse(createBotRoute, id, nil)
+}
+
+// handleStartBot handles requests for startbot. *msgjson.ResponsePayload.Error
+// is empty if successful.
+func handleStartBot(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	if s.mm == nil {
+		return errNoMarketMaker(startBotRoute)
+	}
+	form, err := parseStartBotArgs(params)
+	if err != nil {
+		return usage(startBotRoute, err)
+	}
+	defer form.appPass.Clear()
+	if err := s.mm.StartBot(form.appPass, form.id); err != nil {
+		errMsg := fmt.Sprintf("unable to start bot %d: %v", form.id, err)
+		resErr := msgjson.NewError(msgjson.RPCMarketMakerError, errMsg)
+		return createResponse(startBotRoute, nil, resErr)
+	}
+	res := fmt.Sprintf(botStartedStr, form.id)
+	return createResponse(startBotRoute, &res, nil)
+}
+
+// handleStopBot handles requests for stopbot. *msgjson.ResponsePayload.Error
+// is empty if successful.
+func handleStopBot(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	if s.mm == nil {
+		return errNoMarketMaker(stopBotRoute)
+	}
+	id, err := parseBotIDArgs(params)
+	if err != nil {
+		return usage(stopBotRoute, err)
+	}
+	if err := s.mm.StopBot(id); err != nil {
+		errMsg := fmt.Sprintf("unable to stop bot %d: %v", id, err)
+		resErr := msgjson.NewError(msgjson.RPCMarketMakerError, errMsg)
+		return createResponse(stopBotRoute, nil, resErr)
+	}
+	res := fmt.Sprintf(botStoppedStr, id)
+	return createResponse(stopBotRoute, &res, nil)
+}
+
+// handleUpdateBot handles requests for updatebot.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleUpdateBot(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	if s.mm == nil {
+		return errNoMarketMaker(updateBotRoute)
+	}
+	form, err := parseUpdateBotArgs(params)
+	if err != nil {
+		return usage(updateBotRoute, err)
+	}
+	if err := s.mm.UpdateBot(form.id, form.cfg); err != nil {
+		errMsg := fmt.Sprintf("unable to update bot %d: %v", form.id, err)
+		resErr := msgjson.NewError(msgjson.RPCMarketMakerError, errMsg)
+		return createResponse(updateBotRoute, nil, resErr)
+	}
+	res := fmt.Sprintf(botUpdatedStr, form.id)
+	return createResponse(updateBotRoute, &res, nil)
+}
+
+// handleRetireBot handles requests for retirebot.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleRetireBot(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	if s.mm == nil {
+		return errNoMarketMaker(retireBotRoute)
+	}
+	id, err := parseBotIDArgs(params)
+	if err != nil {
+		return usage(retireBotRoute, err)
+	}
+	if err := s.mm.RetireBot(id); err != nil {
+		errMsg := fmt.Sprintf("unable to retire bot %d: %v", id, err)
+		resErr := msgjson.NewError(msgjson.RPCMarketMakerError, errMsg)
+		return createResponse(retireBotRoute, nil, resErr)
+	}
+	res := fmt.Sprintf(botRetiredStr, id)
+	return createResponse(retireBotRoute, &res, nil)
+}
+
+// handleBots handles requests for bots. *msgjson.ResponsePayload.Error is
+// empty if successful.
+func handleBots(s *RPCServer, _ *RawParams) *msgjson.ResponsePayload {
+	if s.mm == nil {
+		return errNoMarketMaker(botsRoute)
+	}
+	return createResponse(botsRoute, s.mm.Bots(), nil)
+}
+
+// handleStopOrder handles requests for stoporder.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleStopOrder(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	form, err := parseStopOrderArgs(params)
+	if err != nil {
+		return usage(stopOrderRoute, err)
+	}
+	defer form.appPass.Clear()
+	so, err := s.core.StopOrder(form.appPass, form.srvForm)
+	if err != nil {
+		errMsg := fmt.Sprintf("unable to create stop order: %v", err)
+		resErr := msgjson.NewError(msgjson.RPCStopOrderError, errMsg)
+		return createResponse(stopOrderRoute, nil, resErr)
+	}
+	return createResponse(stopOrderRoute, so, nil)
+}
+
+// handleStopOrders handles requests for stoporders.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleStopOrders(s *RPCServer, _ *RawParams) *msgjson.ResponsePayload {
+	return createResponse(stopOrdersRoute, s.core.StopOrders(), nil)
+}
+
+// handleCancelStopOrder handles requests for cancelstoporder.
+// *msgjson.ResponsePayload.Error is empty if successful.
+func handleCancelStopOrder(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	id, err := parseCancelStopOrderArgs(params)
+	if err != nil {
+		return usage(cancelStopOrderRoute, err)
+	}
+	if err := s.core.CancelStopOrder(id); err != nil {
+		errMsg := fmt.Sprintf("unable to cancel stop order %s: %v", id, err)
+		resErr := msgjson.NewError(msgjson.RPCStopOrderError, errMsg)
+		return createResponse(cancelStopOrderRoute, nil, resErr)
+	}
+	res := fmt.Sprintf(canceledStopStr, id)
+	return createResponse(cancelStopOrderRoute, &res, nil)
+}
+
 // format concatenates thing and tail. If thing is empty, returns an empty
 // string.
 func format(thing, tail string) string {
@@ -773,7 +1026,9 @@ var helpMsgs = map[string]helpMsg{
 		pwArgsLong: `Password Args:
     appPass (string): The DEX client password.`,
 		argsLong: `Args:
-    seed (string): Optional. hex-encoded 512-bit restoration seed.`,
+    seed (string): Optional. The mnemonic restoration seed from appseed, or a
+      legacy hex-encoded 512-bit seed. With dexcctl, the words of the mnemonic
+      can be given as separate arguments.`,
 		returns: `Returns:
     string: The message "` + initializedStr + `"`,
 	},
@@ -959,7 +1214,7 @@ Registration is complete after the fee transaction has been confirmed.`,
 	},
 	tradeRoute: {
 		pwArgsShort: `"appPass"`,
-		argsShort:   `"host" isLimit sell base quote qty rate immediate`,
+		argsShort:   `"host" isLimit sell base quote qty rate immediate "options" ("coins")`,
 		cmdSummary:  `Make an order to buy or sell an asset.`,
 		pwArgsLong: `Password Args:
     appPass (string): The DEX client password.`,
@@ -974,7 +1229,10 @@ Registration is complete after the fee transaction has been confirmed.`,
       156000 satoshi/DCR for the DCR(base)_BTC(quote).
     immediate (bool): Require immediate match. Do not book the order.
     options (string): A JSON-encoded string->string mapping of additional
-       trade options.`,
+       trade options.
+    coins (string): Optional. A JSON-encoded array of hex coin IDs, as listed
+      by listunspent, that must fund the order. All of the coins are used.
+      The wallet chooses the coins by default.`,
 		returns: `Returns:
     obj: The order details.
     {
@@ -983,6 +1241,136 @@ Registration is complete after the fee transaction has been confirmed.`,
       "stamp" (int): The time the order was signed in milliseconds since 00:00:00
         Jan 1 1970.
     }`,
+	},
+	multiTradeRoute: {
+		pwArgsShort: `"appPass"`,
+		argsShort:   `"host" sell base quote "placements" ("options")`,
+		cmdSummary: `Place a batch of standing limit orders on one side of a market. The
+  orders are funded together and are all accepted by the server, or all
+  rejected.`,
+		pwArgsLong: `Password Args:
+    appPass (string): The DEX client password.`,
+		argsLong: `Args:
+    host (string): The DEX to trade on.
+    sell (bool): Whether the orders are selling.
+    base (int): The BIP-44 coin index for the market's base asset.
+    quote (int): The BIP-44 coin index for the market's quote asset.
+    placements (string): A JSON-encoded array of [qty, rate] pairs, one for
+      each order, e.g. [[100000000,156000],[200000000,157000]]. qty must be a
+      multiple of the lot size.
+    options (string): A JSON-encoded string->string mapping of additional
+       trade options.`,
+		returns: `Returns:
+    array: The details of each order, in placement order.
+    [
+      {
+        "orderid" (string): The order's unique hex identifier.
+        "sig" (string): The DEX's signature of the order information.
+        "stamp" (int): The time the order was signed in milliseconds since
+          00:00:00 Jan 1 1970.
+      },...
+    ]`,
+	},
+	exportTradesRoute: {
+		argsShort: `base quote ("format") ("host") (since) (until)`,
+		cmdSummary: `Export an accounting record of each of the user's matches on a
+  market, with the fees paid and the first-in-first-out cost basis and
+  profit/loss of sells.`,
+		argsLong: `Args:
+    base (int): The BIP-44 coin index for the market's base asset.
+    quote (int): The BIP-44 coin index for the market's quote asset.
+    format (string): Optional. "csv" (default) or "json".
+    host (string): Optional. The DEX to export matches from. Default is all
+      DEXes.
+    since (int): Optional. The earliest match time to export, in milliseconds
+      since 00:00:00 Jan 1 1970.
+    until (int): Optional. Export matches before this time, in milliseconds
+      since 00:00:00 Jan 1 1970.`,
+		returns: `Returns:
+    string: The CSV records, with a header row. For the json format, an array
+      of records.
+    [
+      {
+        "stamp" (int): The match time in milliseconds since 00:00:00 Jan 1 1970.
+        "host" (string): The DEX address.
+        "market" (string): The market name.
+        "orderID" (string): The order's hex ID.
+        "matchID" (string): The match's hex ID.
+        "sell" (bool): Whether the order was selling.
+        "side" (string): The user's side of the match, "Maker" or "Taker".
+        "counterpartySide" (string): The counterparty's side of the match.
+        "status" (string): The match status.
+        "refunded" (bool): Whether the user's swap was refunded.
+        "qty" (int): The matched quantity of the base asset.
+        "rate" (int): The match rate.
+        "quoteQty" (int): The matched quantity of the quote asset.
+        "swapFees" (int): The share of the order's swap fees.
+        "redeemFees" (int): The share of the order's redemption fees.
+        "refundFees" (int): The share of the order's refund fees.
+        "proceeds" (int): The quote asset received, less the swap and
+          redemption fees, in units of the quote asset. Settled sells only.
+        "costBasis" (int): The cost of the base asset sold, including the
+          fees of the buys, in units of the quote asset. Settled sells only.
+        "unknownBasisQty" (int): The quantity of the base asset sold in excess
+          of the prior buys, which has no known cost basis. Settled sells
+          only.
+        "profitLoss" (int): The proceeds of a settled sell, less the cost
+          basis. The proceeds from quantity with no known cost basis are not
+          included.
+      },...
+    ]`,
+	},
+	txHistoryRoute: {
+		argsShort: `assetID (n) ("refID")`,
+		cmdSummary: `List a wallet's transactions, most recent first. Only wallets that keep
+  a transaction history are supported.`,
+		argsLong: `Args:
+    assetID (int): The asset's BIP-44 registered coin index. e.g. 42 for DCR.
+      See https://github.com/satoshilabs/slips/blob/master/slip-0044.md
+    n (int): Optional. The maximum number of transactions to list. Default is
+      all transactions.
+    refID (string): Optional. List transactions older than the transaction
+      with this ID, for paging through the history.`,
+		returns: `Returns:
+    array: The transactions.
+    [
+      {
+        "type" (int): The transaction type. 0: unknown, 1: send, 2: receive,
+          3: swap, 4: redeem, 5: refund.
+        "id" (string): The transaction ID.
+        "amount" (int): The amount sent, received, swapped, redeemed or
+          refunded, in atomic units, not including fees.
+        "fees" (int): The fees paid by the wallet, in atomic units.
+        "blockNumber" (int): The block containing the transaction, or 0 if
+          unmined.
+        "timestamp" (int): The time the transaction was sent or first seen,
+          in seconds since 00:00:00 Jan 1 1970.
+        "recipient" (string): The recipient address of a send.
+        "secretHashes" ([string]): The secret hashes of the swap contracts
+          initiated, redeemed or refunded.
+        "matchIDs" ([string]): The IDs of the active trades' matches for the
+          secret hashes.
+      },...
+    ]`,
+	},
+	listUnspentRoute: {
+		argsShort: `assetID`,
+		cmdSummary: `List a wallet's spendable outputs, which can be selected to fund a trade
+  or a send. Outputs funding orders are not listed. Only wallets that support
+  coin control are supported.`,
+		argsLong: `Args:
+    assetID (int): The asset's BIP-44 registered coin index. e.g. 42 for DCR.
+      See https://github.com/satoshilabs/slips/blob/master/slip-0044.md`,
+		returns: `Returns:
+    array: The spendable outputs.
+    [
+      {
+        "id" (string): The hex coin ID.
+        "value" (int): The value of the output, in atomic units.
+        "address" (string): The address that the output pays to.
+        "confs" (int): The number of confirmations.
+      },...
+    ]`,
 	},
 	cancelRoute: {
 		pwArgsShort: `"appPass"`,
@@ -1031,7 +1419,7 @@ needed to complete a swap.`,
 	},
 	sendRoute: {
 		pwArgsShort: `"appPass"`,
-		argsShort:   `assetID value "address"`,
+		argsShort:   `assetID value "address" ("coins")`,
 		cmdSummary:  `Sends exact value from an exchange wallet to address.`,
 		pwArgsLong: `Password Args:
     appPass (string): The DEX client password.`,
@@ -1041,7 +1429,10 @@ needed to complete a swap.`,
       https://github.com/satoshilabs/slips/blob/master/slip-0044.md
     value (int): The amount to send in units of the asset's smallest
       denomination (e.g. satoshis, atoms, etc.)"
-    address (string): The address to which funds are sent.`,
+    address (string): The address to which funds are sent.
+    coins (string): Optional. A JSON-encoded array of hex coin IDs, as listed
+      by listunspent, to spend. All of the coins are spent, and change is
+      returned to the wallet. The wallet chooses the coins by default.`,
 		returns: `Returns:
     string: "[coin ID]"`,
 	},
@@ -1157,6 +1548,177 @@ needed to complete a swap.`,
 		pwArgsLong: `Password Args:
     appPass (string): The DEX client password.`,
 		returns: `Returns:
-    string: The application's seed as hex.`,
+    string: The application's mnemonic seed. The mnemonic also encodes the
+      date the seed was generated, which is used to limit wallet rescans when
+      restoring.`,
+	},
+	createBotRoute: {
+		pwArgsShort: `"appPass"`,
+		argsShort:   `"config"`,
+		cmdSummary: `Create and start a market making bot. The bot's configuration is saved,
+  but the bot must be started again with startbot after a restart.`,
+		pwArgsLong: `Password Args:
+    appPass (string): The DEX client password.`,
+		argsLong: `Args:
+    config (string): A JSON-encoded bot configuration.
+      {
+        "host" (string): The DEX host.
+        "baseID" (int): The BIP-44 coin index for the market's base asset.
+        "quoteID" (int): The BIP-44 coin index for the market's quote asset.
+        "gapStrategy" (string): How the distance between the orders and the
+          basis price is determined. One of "percent", "multiplier", or
+          "oracle". "percent" and "multiplier" use the order book's mid-gap
+          rate as the basis price, "oracle" uses the oracle price.
+        "placements" (array): The orders to place on each side of the book.
+          [
+            {
+              "lots" (int): The number of lots in each order.
+              "gapFactor" (float): For "percent" and "oracle", the fraction of
+                the basis price, e.g. 0.01 for 1%. For "multiplier", the
+                multiple of the order book's half-spread.
+            },...
+          ]
+        "driftTolerance" (float): How far an order's rate may drift from its
+          target, as a fraction of the basis price, before it is replaced.
+        "baseBalance" (int): The maximum amount of the base asset to commit to
+          orders. 0 for no limit.
+        "quoteBalance" (int): The maximum amount of the quote asset to commit
+          to orders. 0 for no limit.
+      }`,
+		returns: `Returns:
+    int: The new bot's ID.`,
+	},
+	startBotRoute: {
+		pwArgsShort: `"appPass"`,
+		argsShort:   `botID`,
+		cmdSummary:  `Start a stopped market making bot.`,
+		pwArgsLong: `Password Args:
+    appPass (string): The DEX client password.`,
+		argsLong: `Args:
+    botID (int): The bot's ID.`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(botStartedStr, 0) + `"`,
+	},
+	stopBotRoute: {
+		argsShort:  `botID`,
+		cmdSummary: `Stop a market making bot and cancel its orders.`,
+		argsLong: `Args:
+    botID (int): The bot's ID.`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(botStoppedStr, 0) + `"`,
+	},
+	updateBotRoute: {
+		argsShort: `botID "config"`,
+		cmdSummary: `Update a market making bot's configuration. The market cannot be
+  changed.`,
+		argsLong: `Args:
+    botID (int): The bot's ID.
+    config (string): A JSON-encoded bot configuration.
+      {
+        "host" (string): The DEX host.
+        "baseID" (int): The BIP-44 coin index for the market's base asset.
+        "quoteID" (int): The BIP-44 coin index for the market's quote asset.
+        "gapStrategy" (string): How the distance between the orders and the
+          basis price is determined. One of "percent", "multiplier", or
+          "oracle". "percent" and "multiplier" use the order book's mid-gap
+          rate as the basis price, "oracle" uses the oracle price.
+        "placements" (array): The orders to place on each side of the book.
+          [
+            {
+              "lots" (int): The number of lots in each order.
+              "gapFactor" (float): For "percent" and "oracle", the fraction of
+                the basis price, e.g. 0.01 for 1%. For "multiplier", the
+                multiple of the order book's half-spread.
+            },...
+          ]
+        "driftTolerance" (float): How far an order's rate may drift from its
+          target, as a fraction of the basis price, before it is replaced.
+        "baseBalance" (int): The maximum amount of the base asset to commit to
+          orders. 0 for no limit.
+        "quoteBalance" (int): The maximum amount of the quote asset to commit
+          to orders. 0 for no limit.
+      }`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(botUpdatedStr, 0) + `"`,
+	},
+	retireBotRoute: {
+		argsShort:  `botID`,
+		cmdSummary: `Stop a market making bot, cancel its orders, and delete its configuration.`,
+		argsLong: `Args:
+    botID (int): The bot's ID.`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(botRetiredStr, 0) + `"`,
+	},
+	botsRoute: {
+		cmdSummary: `List the market making bots.`,
+		returns: `Returns:
+    array: An array of bots.
+    [
+      {
+        "id" (int): The bot's ID.
+        "config" (obj): The bot's configuration. See createbot.
+        "running" (bool): Whether the bot is running.
+        "orders" (array): The hex IDs of the bot's standing orders.
+      },...
+    ]`,
+	},
+	stopOrderRoute: {
+		pwArgsShort: `"appPass"`,
+		argsShort:   `"host" isLimit sell base quote qty rate immediate stopRate trailRate`,
+		cmdSummary: `Create a stop or trailing-stop order. The order is held by the client
+  until the market rate crosses the stop rate, at which time the order is
+  placed. Triggered orders are only placed while logged in.`,
+		pwArgsLong: `Password Args:
+    appPass (string): The DEX client password.`,
+		argsLong: `Args:
+    host (string): The DEX to trade on.
+    isLimit (bool): Whether the triggered order is a limit order.
+    sell (bool): Whether the order is selling. Sell orders trigger when the
+      market rate falls to the stop rate, buy orders when it rises to it.
+    base (int): The BIP-44 coin index for the market's base asset.
+    quote (int): The BIP-44 coin index for the market's quote asset.
+    qty (int): The number of units to buy/sell. Must be a multiple of the lot size.
+    rate (int): The limit rate for the triggered order, in atoms quote asset
+      per unit base asset. Ignored for market orders.
+    immediate (bool): Require immediate match. Do not book the order.
+    stopRate (int): The market rate that triggers the order. May be 0 for a
+      trailing stop, in which case it is set trailRate away from the current
+      market rate.
+    trailRate (int): For a trailing stop, the distance that the stop rate
+      follows the market rate as it moves away from the stop. 0 for a plain
+      stop order.
+    options (string): A JSON-encoded string->string mapping of additional
+       trade options.`,
+		returns: `Returns:
+    obj: The stop order.
+    {
+      "id" (string): The stop order's hex ID.
+      "host" (string): The DEX host.
+      "baseID" (int): The market's base asset.
+      "quoteID" (int): The market's quote asset.
+      "sell" (bool): Whether the order is selling.
+      "isLimit" (bool): Whether the triggered order is a limit order.
+      "qty" (int): The order quantity.
+      "rate" (int): The limit rate.
+      "tifnow" (bool): Whether the order requires an immediate match.
+      "options" (obj): The trade options.
+      "stopRate" (int): The current stop rate.
+      "trailRate" (int): The trail distance. 0 for a plain stop order.
+      "stamp" (int): The time the stop order was created in milliseconds
+        since 00:00:00 Jan 1 1970.
+    }`,
+	},
+	stopOrdersRoute: {
+		cmdSummary: `List the pending stop orders.`,
+		returns: `Returns:
+    array: An array of stop orders. See stoporder.`,
+	},
+	cancelStopOrderRoute: {
+		argsShort:  `"id"`,
+		cmdSummary: `Cancel a pending stop order.`,
+		argsLong: `Args:
+    id (string): The hex ID of the stop order.`,
+		returns: `Returns:
+    string: The message "` + fmt.Sprintf(canceledStopStr, "[stop order ID]") + `"`,
 	},
 }
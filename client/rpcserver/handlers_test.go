@@ -9,11 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strings"
 	"testing"
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/client/websocket"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/encode"
@@ -732,6 +733,270 @@ func TestHandleCancel(t *testing.T) {
 	}
 }
 
+func TestHandleMultiTrade(t *testing.T) {
+	params := &RawParams{
+		PWArgs: []encode.PassBytes{encode.PassBytes("abc")}, // 0. AppPass
+		Args: []string{
+			"1.2.3.4:3000",  // 0. DEX
+			"true",          // 1. Sell
+			"0",             // 2. Base
+			"42",            // 3. Quote
+			"[[1,1],[2,2]]", // 4. Placements
+			"{}",            // 5. Options
+		}}
+	tests := []struct {
+		name          string
+		params        *RawParams
+		multiTradeErr error
+		wantErrCode   int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:          "core.MultiTrade error",
+		params:        params,
+		multiTradeErr: errors.New("error"),
+		wantErrCode:   msgjson.RPCTradeError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{
+			multiTradeOrders: []*core.Order{{ID: dex.Bytes{0x01}}, {ID: dex.Bytes{0x02}}},
+			multiTradeErr:    test.multiTradeErr,
+		}
+		r := &RPCServer{core: tc}
+		payload := handleMultiTrade(r, test.params)
+		var res []*tradeResponse
+		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if test.wantErrCode == -1 && (len(res) != 2 || res[1].OrderID != "02") {
+			t.Fatalf("%s: wrong response %+v", test.name, res)
+		}
+	}
+}
+
+func TestHandleExportTrades(t *testing.T) {
+	params := &RawParams{Args: []string{"42", "0"}}
+	jsonParams := &RawParams{Args: []string{"42", "0", "json"}}
+	tests := []struct {
+		name            string
+		params          *RawParams
+		exportTradesErr error
+		wantErrCode     int
+	}{{
+		name:        "ok csv",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:        "ok json",
+		params:      jsonParams,
+		wantErrCode: -1,
+	}, {
+		name:            "core.ExportTrades error",
+		params:          params,
+		exportTradesErr: errors.New("error"),
+		wantErrCode:     msgjson.RPCExportTradesError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{exportTradesErr: test.exportTradesErr}
+		r := &RPCServer{core: tc}
+		payload := handleExportTrades(r, test.params)
+		var res interface{}
+		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if test.wantErrCode != -1 {
+			continue
+		}
+		if test.params == jsonParams {
+			if _, ok := res.([]interface{}); !ok {
+				t.Fatalf("%s: expected array result, got %T", test.name, res)
+			}
+		} else if res != "stamp\n" {
+			t.Fatalf("%s: wrong result %v", test.name, res)
+		}
+	}
+}
+
+func TestHandleTxHistory(t *testing.T) {
+	params := &RawParams{Args: []string{"42", "10", "abc"}}
+	tests := []struct {
+		name         string
+		params       *RawParams
+		txHistoryErr error
+		wantErrCode  int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:         "core.TxHistory error",
+		params:       params,
+		txHistoryErr: errors.New("error"),
+		wantErrCode:  msgjson.RPCTxHistoryError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{
+			txHistory: []*core.WalletTransaction{{
+				WalletTransaction: &asset.WalletTransaction{Type: asset.Receive, ID: "def"},
+			}},
+			txHistoryErr: test.txHistoryErr,
+		}
+		r := &RPCServer{core: tc}
+		payload := handleTxHistory(r, test.params)
+		var res []*core.WalletTransaction
+		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if test.wantErrCode == -1 && (len(res) != 1 || res[0].ID != "def") {
+			t.Fatalf("%s: wrong result", test.name)
+		}
+	}
+}
+
+func TestHandleListUnspent(t *testing.T) {
+	params := &RawParams{Args: []string{"42"}}
+	tests := []struct {
+		name           string
+		params         *RawParams
+		listUnspentErr error
+		wantErrCode    int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:           "core.ListUnspent error",
+		params:         params,
+		listUnspentErr: errors.New("error"),
+		wantErrCode:    msgjson.RPCListUnspentError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{
+			unspents:       []*asset.UnspentCoin{{ID: dex.Bytes{0x0a}, Value: 5}},
+			listUnspentErr: test.listUnspentErr,
+		}
+		r := &RPCServer{core: tc}
+		payload := handleListUnspent(r, test.params)
+		var res []*asset.UnspentCoin
+		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if test.wantErrCode == -1 && (len(res) != 1 || res[0].Value != 5) {
+			t.Fatalf("%s: wrong result", test.name)
+		}
+	}
+}
+
+func TestHandleStopOrder(t *testing.T) {
+	params := &RawParams{
+		PWArgs: []encode.PassBytes{encode.PassBytes("abc")}, // 0. AppPass
+		Args: []string{
+			"1.2.3.4:3000", // 0. DEX
+			"true",         // 1. IsLimit
+			"true",         // 2. Sell
+			"0",            // 3. Base
+			"42",           // 4. Quote
+			"1",            // 5. Qty
+			"1",            // 6. Rate
+			"false",        // 7. TifNow
+			"2",            // 8. StopRate
+			"0",            // 9. TrailRate
+			"{}",           // 10. Options
+		}}
+	tests := []struct {
+		name         string
+		params       *RawParams
+		stopOrderErr error
+		wantErrCode  int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:         "core.StopOrder error",
+		params:       params,
+		stopOrderErr: errors.New("error"),
+		wantErrCode:  msgjson.RPCStopOrderError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{stopOrder: new(db.StopOrder), stopOrderErr: test.stopOrderErr}
+		r := &RPCServer{core: tc}
+		payload := handleStopOrder(r, test.params)
+		res := new(db.StopOrder)
+		if err := verifyResponse(payload, res, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+	}
+
+	tc := &TCore{stopOrder: &db.StopOrder{ID: dex.Bytes{0x01}, StopRate: 5}}
+	payload := handleStopOrders(&RPCServer{core: tc}, &RawParams{})
+	var sos []*db.StopOrder
+	if err := verifyResponse(payload, &sos, -1); err != nil {
+		t.Fatal(err)
+	}
+	if len(sos) != 1 || sos[0].StopRate != 5 {
+		t.Fatalf("wrong stop orders result")
+	}
+}
+
+func TestHandleCancelStopOrder(t *testing.T) {
+	params := &RawParams{Args: []string{"0a0b0c"}}
+	tests := []struct {
+		name               string
+		params             *RawParams
+		cancelStopOrderErr error
+		wantErrCode        int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:               "core.CancelStopOrder error",
+		params:             params,
+		cancelStopOrderErr: errors.New("error"),
+		wantErrCode:        msgjson.RPCStopOrderError,
+	}, {
+		name:        "bad id",
+		params:      &RawParams{Args: []string{"xyz"}},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{cancelStopOrderErr: test.cancelStopOrderErr}
+		r := &RPCServer{core: tc}
+		payload := handleCancelStopOrder(r, test.params)
+		res := ""
+		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+	}
+}
+
 // tCoin satisfies the asset.Coin interface.
 type tCoin struct{}
 
@@ -1104,16 +1369,16 @@ func TestHandleAppSeed(t *testing.T) {
 		wantErrCode: msgjson.RPCArgumentsError,
 	}}
 	for _, test := range tests {
-		tc := &TCore{exportSeed: []byte{255}, exportSeedErr: test.exportSeedErr}
+		const seed = "zoo abandon ability"
+		tc := &TCore{exportSeed: seed, exportSeedErr: test.exportSeedErr}
 		r := &RPCServer{core: tc}
 		payload := handleAppSeed(r, test.params)
 		res := ""
 		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
 			t.Fatal(err)
 		}
-		res = strings.ToLower(res)
-		if test.wantErrCode == -1 && res != "ff" {
-			t.Fatalf("expected ff but got %v", res)
+		if test.wantErrCode == -1 && res != seed {
+			t.Fatalf("expected %q but got %q", seed, res)
 		}
 
 	}
@@ -1201,3 +1466,119 @@ func TestDeleteRecords(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleCreateBot(t *testing.T) {
+	params := &RawParams{
+		PWArgs: []encode.PassBytes{encode.PassBytes("abc")},
+		Args:   []string{`{"host":"dex.tld","baseID":42,"quoteID":0,"gapStrategy":"percent","placements":[{"lots":1,"gapFactor":0.01}]}`},
+	}
+	tests := []struct {
+		name        string
+		params      *RawParams
+		mm          *TMarketMaker
+		wantErrCode int
+	}{{
+		name:        "ok",
+		params:      params,
+		mm:          &TMarketMaker{createID: 5},
+		wantErrCode: -1,
+	}, {
+		name:        "CreateBot error",
+		params:      params,
+		mm:          &TMarketMaker{createErr: errors.New("error")},
+		wantErrCode: msgjson.RPCMarketMakerError,
+	}, {
+		name:        "no market maker",
+		params:      params,
+		wantErrCode: msgjson.RPCMarketMakerError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		mm:          &TMarketMaker{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		r := &RPCServer{core: &TCore{}}
+		if test.mm != nil {
+			r.mm = test.mm
+		}
+		payload := handleCreateBot(r, test.params)
+		var id uint64
+		if err := verifyResponse(payload, &id, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if test.wantErrCode == -1 && id != test.mm.createID {
+			t.Fatalf("%s: wrong bot ID %d", test.name, id)
+		}
+	}
+}
+
+func TestHandleBotRoutes(t *testing.T) {
+	pwParams := &RawParams{
+		PWArgs: []encode.PassBytes{encode.PassBytes("abc")},
+		Args:   []string{"1"},
+	}
+	idParams := &RawParams{Args: []string{"1"}}
+	updateParams := &RawParams{Args: []string{"1", `{"host":"dex.tld"}`}}
+	tErr := errors.New("error")
+	tests := []struct {
+		name    string
+		handler func(*RPCServer, *RawParams) *msgjson.ResponsePayload
+		params  *RawParams
+		mm      *TMarketMaker
+		errMM   *TMarketMaker
+	}{{
+		name:    "startbot",
+		handler: handleStartBot,
+		params:  pwParams,
+		mm:      &TMarketMaker{},
+		errMM:   &TMarketMaker{startErr: tErr},
+	}, {
+		name:    "stopbot",
+		handler: handleStopBot,
+		params:  idParams,
+		mm:      &TMarketMaker{},
+		errMM:   &TMarketMaker{stopErr: tErr},
+	}, {
+		name:    "updatebot",
+		handler: handleUpdateBot,
+		params:  updateParams,
+		mm:      &TMarketMaker{},
+		errMM:   &TMarketMaker{updateErr: tErr},
+	}, {
+		name:    "retirebot",
+		handler: handleRetireBot,
+		params:  idParams,
+		mm:      &TMarketMaker{},
+		errMM:   &TMarketMaker{retireErr: tErr},
+	}}
+	for _, test := range tests {
+		var res string
+		payload := test.handler(&RPCServer{mm: test.mm}, test.params)
+		if err := verifyResponse(payload, &res, -1); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		payload = test.handler(&RPCServer{mm: test.errMM}, test.params)
+		if err := verifyResponse(payload, &res, msgjson.RPCMarketMakerError); err != nil {
+			t.Fatalf("%s error: %v", test.name, err)
+		}
+		payload = test.handler(&RPCServer{}, test.params)
+		if err := verifyResponse(payload, &res, msgjson.RPCMarketMakerError); err != nil {
+			t.Fatalf("%s no market maker: %v", test.name, err)
+		}
+		payload = test.handler(&RPCServer{mm: test.mm}, &RawParams{})
+		if err := verifyResponse(payload, &res, msgjson.RPCArgumentsError); err != nil {
+			t.Fatalf("%s bad params: %v", test.name, err)
+		}
+	}
+
+	bots := []*mm.BotStatus{{ID: 1, Running: true}}
+	payload := handleBots(&RPCServer{mm: &TMarketMaker{bots: bots}}, &RawParams{})
+	var res []*mm.BotStatus
+	if err := verifyResponse(payload, &res, -1); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].ID != 1 || !res[0].Running {
+		t.Fatalf("wrong bots result")
+	}
+}
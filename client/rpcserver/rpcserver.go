@@ -22,6 +22,8 @@ import (
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/client/websocket"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/msgjson"
@@ -36,7 +38,7 @@ const (
 	// patch for bug fixes. Dexcctl requiredRPCSemVer should be kept up to date
 	// with this version.
 	rpcSemverMajor uint32 = 0
-	rpcSemverMinor uint32 = 2
+	rpcSemverMinor uint32 = 3
 	rpcSemverPatch uint32 = 0
 
 	// rpcTimeoutSeconds is the number of seconds a connection to the RPC server
@@ -62,7 +64,7 @@ type clientCore interface {
 	CreateWallet(appPass, walletPass []byte, form *core.WalletForm) error
 	DiscoverAccount(dexAddr string, pass []byte, certI interface{}) (*core.Exchange, bool, error)
 	Exchanges() (exchanges map[string]*core.Exchange)
-	InitializeClient(appPass, seed []byte) error
+	InitializeClient(appPass []byte, seed string) error
 	Login(appPass []byte) (*core.LoginResult, error)
 	Logout() error
 	OpenWallet(assetID uint32, appPass []byte) error
@@ -72,15 +74,35 @@ type clientCore interface {
 	Wallets() (walletsStates []*core.WalletState)
 	WalletState(assetID uint32) *core.WalletState
 	RescanWallet(assetID uint32, force bool) error
-	Send(appPass []byte, assetID uint32, value uint64, addr string, subtract bool) (asset.Coin, error)
-	ExportSeed(pw []byte) ([]byte, error)
+	Send(appPass []byte, assetID uint32, value uint64, addr string, subtract bool, coinIDs []dex.Bytes) (asset.Coin, error)
+	ExportSeed(pw []byte) (string, error)
 	DeleteArchivedRecords(olderThan *time.Time, matchesFileStr, ordersFileStr string) error
+	StopOrder(appPass []byte, form *core.StopOrderForm) (*db.StopOrder, error)
+	StopOrders() []*db.StopOrder
+	CancelStopOrder(id dex.Bytes) error
+	MultiTrade(appPass []byte, form *core.MultiTradeForm) ([]*core.Order, error)
+	ExportTrades(w io.Writer, form *core.ExportTradesForm) error
+	TxHistory(assetID uint32, n int, refID *string) ([]*core.WalletTransaction, error)
+	ListUnspent(assetID uint32) ([]*asset.UnspentCoin, error)
 }
 
+// marketMaker is satisfied by mm.MarketMaker.
+type marketMaker interface {
+	CreateBot(pw []byte, cfg *mm.BotConfig) (uint64, error)
+	StartBot(pw []byte, id uint64) error
+	StopBot(id uint64) error
+	UpdateBot(id uint64, cfg *mm.BotConfig) error
+	RetireBot(id uint64) error
+	Bots() []*mm.BotStatus
+}
+
+var _ marketMaker = (*mm.MarketMaker)(nil)
+
 // RPCServer is a single-client http and websocket server enabling a JSON
 // interface to the DEX client.
 type RPCServer struct {
 	core        clientCore
+	mm          marketMaker
 	mux         *chi.Mux
 	wsServer    *websocket.Server
 	addr        string
@@ -173,6 +195,9 @@ type Config struct {
 	Addr, User, Pass, Cert, Key string
 	DexcVersion                 *SemVersion
 	CertHosts                   []string
+	// MarketMaker is optional. If not provided, the bot routes will return
+	// errors.
+	MarketMaker marketMaker
 }
 
 // SetLogger sets the logger for the RPCServer package.
@@ -221,6 +246,7 @@ func New(cfg *Config) (*RPCServer, error) {
 	// Make the server.
 	s := &RPCServer{
 		core:        cfg.Core,
+		mm:          cfg.MarketMaker,
 		mux:         mux,
 		srv:         httpServer,
 		addr:        cfg.Addr,
@@ -12,6 +12,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"testing"
@@ -19,6 +20,8 @@ import (
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/msgjson"
 )
@@ -57,10 +60,20 @@ type TCore struct {
 	logoutErr                error
 	book                     *core.OrderBook
 	bookErr                  error
-	exportSeed               []byte
+	exportSeed               string
 	exportSeedErr            error
 	discoverAcctErr          error
 	deleteArchivedRecordsErr error
+	stopOrder                *db.StopOrder
+	stopOrderErr             error
+	cancelStopOrderErr       error
+	multiTradeOrders         []*core.Order
+	multiTradeErr            error
+	exportTradesErr          error
+	txHistory                []*core.WalletTransaction
+	txHistoryErr             error
+	unspents                 []*asset.UnspentCoin
+	listUnspentErr           error
 }
 
 func (c *TCore) Balance(uint32) (uint64, error) {
@@ -91,7 +104,7 @@ func (c *TCore) Exchange(host string) (*core.Exchange, error) {
 	}
 	return exchange, nil
 }
-func (c *TCore) InitializeClient(pw, seed []byte) error {
+func (c *TCore) InitializeClient(pw []byte, seed string) error {
 	return c.initializeClientErr
 }
 func (c *TCore) Login(appPass []byte) (*core.LoginResult, error) {
@@ -124,10 +137,10 @@ func (c *TCore) Wallets() []*core.WalletState {
 func (c *TCore) WalletState(assetID uint32) *core.WalletState {
 	return c.walletState
 }
-func (c *TCore) Send(pw []byte, assetID uint32, value uint64, addr string, subtract bool) (asset.Coin, error) {
+func (c *TCore) Send(pw []byte, assetID uint32, value uint64, addr string, subtract bool, coinIDs []dex.Bytes) (asset.Coin, error) {
 	return c.coin, c.sendErr
 }
-func (c *TCore) ExportSeed(pw []byte) ([]byte, error) {
+func (c *TCore) ExportSeed(pw []byte) (string, error) {
 	return c.exportSeed, c.exportSeedErr
 }
 func (c *TCore) DiscoverAccount(dexAddr string, pass []byte, certI interface{}) (*core.Exchange, bool, error) {
@@ -139,6 +152,67 @@ func (c *TCore) DeleteArchivedRecords(olderThan *time.Time, matchesFileStr, orde
 func (c *TCore) AssetHasActiveOrders(uint32) bool {
 	return false
 }
+func (c *TCore) StopOrder(appPass []byte, form *core.StopOrderForm) (*db.StopOrder, error) {
+	return c.stopOrder, c.stopOrderErr
+}
+func (c *TCore) StopOrders() []*db.StopOrder {
+	if c.stopOrder == nil {
+		return nil
+	}
+	return []*db.StopOrder{c.stopOrder}
+}
+func (c *TCore) MultiTrade(appPass []byte, form *core.MultiTradeForm) ([]*core.Order, error) {
+	return c.multiTradeOrders, c.multiTradeErr
+}
+func (c *TCore) TxHistory(assetID uint32, n int, refID *string) ([]*core.WalletTransaction, error) {
+	return c.txHistory, c.txHistoryErr
+}
+func (c *TCore) ListUnspent(assetID uint32) ([]*asset.UnspentCoin, error) {
+	return c.unspents, c.listUnspentErr
+}
+func (c *TCore) ExportTrades(w io.Writer, form *core.ExportTradesForm) error {
+	if c.exportTradesErr != nil {
+		return c.exportTradesErr
+	}
+	if form.Format == core.ExportFormatJSON {
+		_, err := w.Write([]byte(`[]`))
+		return err
+	}
+	_, err := w.Write([]byte("stamp\n"))
+	return err
+}
+func (c *TCore) CancelStopOrder(id dex.Bytes) error {
+	return c.cancelStopOrderErr
+}
+
+type TMarketMaker struct {
+	createID  uint64
+	createErr error
+	startErr  error
+	stopErr   error
+	updateErr error
+	retireErr error
+	bots      []*mm.BotStatus
+}
+
+func (m *TMarketMaker) CreateBot(pw []byte, cfg *mm.BotConfig) (uint64, error) {
+	return m.createID, m.createErr
+}
+func (m *TMarketMaker) StartBot(pw []byte, id uint64) error {
+	return m.startErr
+}
+func (m *TMarketMaker) StopBot(id uint64) error {
+	return m.stopErr
+}
+func (m *TMarketMaker) UpdateBot(id uint64, cfg *mm.BotConfig) error {
+	return m.updateErr
+}
+func (m *TMarketMaker) RetireBot(id uint64) error {
+	return m.retireErr
+}
+func (m *TMarketMaker) Bots() []*mm.BotStatus {
+	return m.bots
+}
 
 type tBookFeed struct{}
 
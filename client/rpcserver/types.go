@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/config"
 	"decred.org/dcrdex/dex/encode"
@@ -134,6 +135,19 @@ type tradeForm struct {
 	srvForm *core.TradeForm
 }
 
+// multiTradeForm combines the application password and the details of a batch
+// of orders.
+type multiTradeForm struct {
+	appPass encode.PassBytes
+	srvForm *core.MultiTradeForm
+}
+
+// stopOrderForm combines the application password and the stop order details.
+type stopOrderForm struct {
+	appPass encode.PassBytes
+	srvForm *core.StopOrderForm
+}
+
 // cancelForm is information necessary to cancel a trade.
 type cancelForm struct {
 	appPass encode.PassBytes
@@ -146,6 +160,7 @@ type sendOrWithdrawForm struct {
 	assetID uint32
 	value   uint64
 	address string
+	coins   []dex.Bytes
 }
 
 // orderBookForm is information necessary to fetch an order book.
@@ -157,12 +172,42 @@ type orderBookForm struct {
 }
 
 // myOrdersForm is information necessary to fetch the user's orders.
+// txHistoryForm is information necessary to list a wallet's transactions.
+type txHistoryForm struct {
+	assetID uint32
+	n       int
+	refID   *string
+}
+
+// listUnspentForm is information necessary to list a wallet's unspent outputs.
+type listUnspentForm struct {
+	assetID uint32
+}
+
 type myOrdersForm struct {
 	host  string
 	base  *uint32
 	quote *uint32
 }
 
+// createBotForm is information necessary to create a market making bot.
+type createBotForm struct {
+	appPass encode.PassBytes
+	cfg     *mm.BotConfig
+}
+
+// startBotForm is information necessary to start a market making bot.
+type startBotForm struct {
+	appPass encode.PassBytes
+	id      uint64
+}
+
+// updateBotForm is information necessary to update a market making bot.
+type updateBotForm struct {
+	id  uint64
+	cfg *mm.BotConfig
+}
+
 type deleteRecordsForm struct {
 	olderThan                     *time.Time
 	ordersFileStr, matchesFileStr string
@@ -217,6 +262,15 @@ func checkMapArg(arg, name string) (map[string]string, error) {
 	return m, nil
 }
 
+func checkCoinIDsArg(arg, name string) ([]dex.Bytes, error) {
+	var coinIDs []dex.Bytes
+	err := json.Unmarshal([]byte(arg), &coinIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be a JSON-encoded array of hex coin IDs: %v", errArgs, name, err)
+	}
+	return coinIDs, nil
+}
+
 func parseDiscoverAcctArgs(params *RawParams) (*discoverAcctForm, error) {
 	if err := checkNArgs(params, []int{1}, []int{1, 2}); err != nil {
 		return nil, err
@@ -255,20 +309,16 @@ func parseHelpArgs(params *RawParams) (*helpForm, error) {
 	}, nil
 }
 
-func parseInitArgs(params *RawParams) (encode.PassBytes, encode.PassBytes, error) {
+func parseInitArgs(params *RawParams) (encode.PassBytes, string, error) {
 	if err := checkNArgs(params, []int{1}, []int{0, 1}); err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 	if len(params.PWArgs[0]) == 0 {
-		return nil, nil, fmt.Errorf("app password cannot be empty")
+		return nil, "", fmt.Errorf("app password cannot be empty")
 	}
-	var seed encode.PassBytes
+	var seed string
 	if len(params.Args) == 1 {
-		var err error
-		seed, err = hex.DecodeString(params.Args[0])
-		if err != nil {
-			return nil, nil, fmt.Errorf("invalid seed format")
-		}
+		seed = params.Args[0]
 	}
 	return params.PWArgs[0], seed, nil
 }
@@ -378,56 +428,156 @@ func parseRegisterArgs(params *RawParams) (*core.RegisterForm, error) {
 }
 
 func parseTradeArgs(params *RawParams) (*tradeForm, error) {
-	if err := checkNArgs(params, []int{1}, []int{9}); err != nil {
+	if err := checkNArgs(params, []int{1}, []int{9, 10}); err != nil {
+		return nil, err
+	}
+	srvForm, err := parseTradeFormArgs(params.Args)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.Args) > 9 {
+		srvForm.Coins, err = checkCoinIDsArg(params.Args[9], "coins")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &tradeForm{
+		appPass: params.PWArgs[0],
+		srvForm: srvForm,
+	}, nil
+}
+
+// parseTradeFormArgs parses the host, isLimit, sell, base, quote, qty, rate,
+// immediate, and options arguments of a trade.
+func parseTradeFormArgs(args []string) (*core.TradeForm, error) {
+	isLimit, err := checkBoolArg(args[1], "isLimit")
+	if err != nil {
+		return nil, err
+	}
+	sell, err := checkBoolArg(args[2], "sell")
+	if err != nil {
 		return nil, err
 	}
-	isLimit, err := checkBoolArg(params.Args[1], "isLimit")
+	base, err := checkUIntArg(args[3], "base", 32)
 	if err != nil {
 		return nil, err
 	}
-	sell, err := checkBoolArg(params.Args[2], "sell")
+	quote, err := checkUIntArg(args[4], "quote", 32)
 	if err != nil {
 		return nil, err
 	}
-	base, err := checkUIntArg(params.Args[3], "base", 32)
+	qty, err := checkUIntArg(args[5], "qty", 64)
 	if err != nil {
 		return nil, err
 	}
-	quote, err := checkUIntArg(params.Args[4], "quote", 32)
+	rate, err := checkUIntArg(args[6], "rate", 64)
 	if err != nil {
 		return nil, err
 	}
-	qty, err := checkUIntArg(params.Args[5], "qty", 64)
+	tifnow, err := checkBoolArg(args[7], "immediate")
 	if err != nil {
 		return nil, err
 	}
-	rate, err := checkUIntArg(params.Args[6], "rate", 64)
+	options, err := checkMapArg(args[8], "options")
 	if err != nil {
 		return nil, err
 	}
-	tifnow, err := checkBoolArg(params.Args[7], "immediate")
+	return &core.TradeForm{
+		Host:    args[0],
+		IsLimit: isLimit,
+		Sell:    sell,
+		Base:    uint32(base),
+		Quote:   uint32(quote),
+		Qty:     qty,
+		Rate:    rate,
+		TifNow:  tifnow,
+		Options: options,
+	}, nil
+}
+
+func parseMultiTradeArgs(params *RawParams) (*multiTradeForm, error) {
+	if err := checkNArgs(params, []int{1}, []int{5, 6}); err != nil {
+		return nil, err
+	}
+	sell, err := checkBoolArg(params.Args[1], "sell")
 	if err != nil {
 		return nil, err
 	}
-	options, err := checkMapArg(params.Args[8], "options")
+	base, err := checkUIntArg(params.Args[2], "base", 32)
 	if err != nil {
 		return nil, err
 	}
-	req := &tradeForm{
+	quote, err := checkUIntArg(params.Args[3], "quote", 32)
+	if err != nil {
+		return nil, err
+	}
+	var pairs [][2]uint64
+	if err := json.Unmarshal([]byte(params.Args[4]), &pairs); err != nil {
+		return nil, fmt.Errorf("%w: placements must be a JSON-encoded array of [qty, rate] pairs: %v", errArgs, err)
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("%w: no placements", errArgs)
+	}
+	placements := make([]*core.QtyRate, 0, len(pairs))
+	for _, p := range pairs {
+		placements = append(placements, &core.QtyRate{Qty: p[0], Rate: p[1]})
+	}
+	var options map[string]string
+	if len(params.Args) > 5 {
+		if options, err = checkMapArg(params.Args[5], "options"); err != nil {
+			return nil, err
+		}
+	}
+	return &multiTradeForm{
 		appPass: params.PWArgs[0],
-		srvForm: &core.TradeForm{
-			Host:    params.Args[0],
-			IsLimit: isLimit,
-			Sell:    sell,
-			Base:    uint32(base),
-			Quote:   uint32(quote),
-			Qty:     qty,
-			Rate:    rate,
-			TifNow:  tifnow,
-			Options: options,
+		srvForm: &core.MultiTradeForm{
+			Host:       params.Args[0],
+			Sell:       sell,
+			Base:       uint32(base),
+			Quote:      uint32(quote),
+			Placements: placements,
+			Options:    options,
 		},
+	}, nil
+}
+
+func parseExportTradesArgs(params *RawParams) (*core.ExportTradesForm, error) {
+	if err := checkNArgs(params, []int{0}, []int{2, 6}); err != nil {
+		return nil, err
 	}
-	return req, nil
+	base, err := checkUIntArg(params.Args[0], "base", 32)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := checkUIntArg(params.Args[1], "quote", 32)
+	if err != nil {
+		return nil, err
+	}
+	form := &core.ExportTradesForm{
+		Base:   uint32(base),
+		Quote:  uint32(quote),
+		Format: core.ExportFormatCSV,
+	}
+	if len(params.Args) > 2 && params.Args[2] != "" {
+		form.Format = params.Args[2]
+		if form.Format != core.ExportFormatCSV && form.Format != core.ExportFormatJSON {
+			return nil, fmt.Errorf("%w: unknown format %q", errArgs, form.Format)
+		}
+	}
+	if len(params.Args) > 3 {
+		form.Host = params.Args[3]
+	}
+	if len(params.Args) > 4 {
+		if form.Since, err = checkUIntArg(params.Args[4], "since", 64); err != nil {
+			return nil, err
+		}
+	}
+	if len(params.Args) > 5 {
+		if form.Until, err = checkUIntArg(params.Args[5], "until", 64); err != nil {
+			return nil, err
+		}
+	}
+	return form, nil
 }
 
 func parseCancelArgs(params *RawParams) (*cancelForm, error) {
@@ -445,8 +595,46 @@ func parseCancelArgs(params *RawParams) (*cancelForm, error) {
 	return &cancelForm{appPass: params.PWArgs[0], orderID: oidB}, nil
 }
 
+func parseStopOrderArgs(params *RawParams) (*stopOrderForm, error) {
+	if err := checkNArgs(params, []int{1}, []int{11}); err != nil {
+		return nil, err
+	}
+	tradeArgs := append(params.Args[:8:8], params.Args[10])
+	tf, err := parseTradeFormArgs(tradeArgs)
+	if err != nil {
+		return nil, err
+	}
+	stopRate, err := checkUIntArg(params.Args[8], "stopRate", 64)
+	if err != nil {
+		return nil, err
+	}
+	trailRate, err := checkUIntArg(params.Args[9], "trailRate", 64)
+	if err != nil {
+		return nil, err
+	}
+	return &stopOrderForm{
+		appPass: params.PWArgs[0],
+		srvForm: &core.StopOrderForm{
+			Trade:     tf,
+			StopRate:  stopRate,
+			TrailRate: trailRate,
+		},
+	}, nil
+}
+
+func parseCancelStopOrderArgs(params *RawParams) (dex.Bytes, error) {
+	if err := checkNArgs(params, []int{0}, []int{1}); err != nil {
+		return nil, err
+	}
+	id, err := hex.DecodeString(params.Args[0])
+	if err != nil || len(id) == 0 {
+		return nil, fmt.Errorf("%w: invalid stop order id hex", errArgs)
+	}
+	return id, nil
+}
+
 func parseSendOrWithdrawArgs(params *RawParams) (*sendOrWithdrawForm, error) {
-	if err := checkNArgs(params, []int{1}, []int{3}); err != nil {
+	if err := checkNArgs(params, []int{1}, []int{3, 4}); err != nil {
 		return nil, err
 	}
 	assetID, err := checkUIntArg(params.Args[0], "assetID", 32)
@@ -463,9 +651,48 @@ func parseSendOrWithdrawArgs(params *RawParams) (*sendOrWithdrawForm, error) {
 		value:   value,
 		address: params.Args[2],
 	}
+	if len(params.Args) > 3 {
+		req.coins, err = checkCoinIDsArg(params.Args[3], "coins")
+		if err != nil {
+			return nil, err
+		}
+	}
 	return req, nil
 }
 
+func parseListUnspentArgs(params *RawParams) (*listUnspentForm, error) {
+	if err := checkNArgs(params, []int{0}, []int{1}); err != nil {
+		return nil, err
+	}
+	assetID, err := checkUIntArg(params.Args[0], "assetID", 32)
+	if err != nil {
+		return nil, err
+	}
+	return &listUnspentForm{assetID: uint32(assetID)}, nil
+}
+
+func parseTxHistoryArgs(params *RawParams) (*txHistoryForm, error) {
+	if err := checkNArgs(params, []int{0}, []int{1, 3}); err != nil {
+		return nil, err
+	}
+	assetID, err := checkUIntArg(params.Args[0], "assetID", 32)
+	if err != nil {
+		return nil, err
+	}
+	form := &txHistoryForm{assetID: uint32(assetID)}
+	if len(params.Args) > 1 {
+		n, err := checkUIntArg(params.Args[1], "n", 32)
+		if err != nil {
+			return nil, err
+		}
+		form.n = int(n)
+	}
+	if len(params.Args) > 2 && params.Args[2] != "" {
+		form.refID = &params.Args[2]
+	}
+	return form, nil
+}
+
 func parseRescanWalletArgs(params *RawParams) (uint32, bool, error) {
 	if err := checkNArgs(params, []int{0}, []int{1, 2}); err != nil {
 		return 0, false, err
@@ -577,3 +804,55 @@ func parseDeleteArchivedRecordsArgs(params *RawParams) (form *deleteRecordsForm,
 	}
 	return form, nil
 }
+
+func checkBotConfigArg(arg string) (*mm.BotConfig, error) {
+	cfg := new(mm.BotConfig)
+	if err := json.Unmarshal([]byte(arg), cfg); err != nil {
+		return nil, fmt.Errorf("%w: config must be a JSON-encoded bot configuration: %v", errArgs, err)
+	}
+	return cfg, nil
+}
+
+func parseCreateBotArgs(params *RawParams) (*createBotForm, error) {
+	if err := checkNArgs(params, []int{1}, []int{1}); err != nil {
+		return nil, err
+	}
+	cfg, err := checkBotConfigArg(params.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &createBotForm{appPass: params.PWArgs[0], cfg: cfg}, nil
+}
+
+func parseStartBotArgs(params *RawParams) (*startBotForm, error) {
+	if err := checkNArgs(params, []int{1}, []int{1}); err != nil {
+		return nil, err
+	}
+	id, err := checkUIntArg(params.Args[0], "botID", 64)
+	if err != nil {
+		return nil, err
+	}
+	return &startBotForm{appPass: params.PWArgs[0], id: id}, nil
+}
+
+func parseBotIDArgs(params *RawParams) (uint64, error) {
+	if err := checkNArgs(params, []int{0}, []int{1}); err != nil {
+		return 0, err
+	}
+	return checkUIntArg(params.Args[0], "botID", 64)
+}
+
+func parseUpdateBotArgs(params *RawParams) (*updateBotForm, error) {
+	if err := checkNArgs(params, []int{0}, []int{2}); err != nil {
+		return nil, err
+	}
+	id, err := checkUIntArg(params.Args[0], "botID", 64)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := checkBotConfigArg(params.Args[1])
+	if err != nil {
+		return nil, err
+	}
+	return &updateBotForm{id: id, cfg: cfg}, nil
+}
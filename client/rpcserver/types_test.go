@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"testing"
 
+	"decred.org/dcrdex/client/core"
 	"decred.org/dcrdex/dex/encode"
 )
 
@@ -492,6 +493,153 @@ func TestTradeArgs(t *testing.T) {
 		if wantOptions != test.params.Args[8] {
 			t.Fatalf("Options doesn't match")
 		}
+		if reg.srvForm.Coins != nil {
+			t.Fatalf("Coins set without the coins argument")
+		}
+	}
+
+	// Coins selected to fund the order.
+	coinsParams := &RawParams{
+		PWArgs: goodParams.PWArgs,
+		Args:   append(append([]string(nil), goodParams.Args...), `["0a0b","0c0d"]`), // 9. Coins
+	}
+	reg, err := parseTradeArgs(coinsParams)
+	if err != nil {
+		t.Fatalf("unexpected error with coins: %v", err)
+	}
+	if len(reg.srvForm.Coins) != 2 || reg.srvForm.Coins[1].String() != "0c0d" {
+		t.Fatalf("Coins don't match")
+	}
+	coinsParams.Args[9] = `["zz"]`
+	if _, err = parseTradeArgs(coinsParams); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for bad coins, got %v", err)
+	}
+}
+
+func TestParseStopOrderArgs(t *testing.T) {
+	pwArgs := []encode.PassBytes{encode.PassBytes("password123")}
+	args := []string{"1.2.3.4:3000", "true", "true", "0", "42", "1", "1", "false", "2", "3", `{"a":"b"}`}
+	form, err := parseStopOrderArgs(&RawParams{PWArgs: pwArgs, Args: args})
+	if err != nil {
+		t.Fatalf("parseStopOrderArgs error: %v", err)
+	}
+	if !bytes.Equal(form.appPass, pwArgs[0]) {
+		t.Fatalf("AppPass doesn't match")
+	}
+	so := form.srvForm
+	if so.StopRate != 2 || so.TrailRate != 3 || so.Trade.Host != args[0] ||
+		so.Trade.Quote != 42 || so.Trade.Options["a"] != "b" {
+		t.Fatalf("wrong form parsed: %+v", so)
+	}
+	for i, bad := range map[int]string{8: "-1", 9: "x", 10: "blue", 1: "blue"} {
+		badArgs := append([]string(nil), args...)
+		badArgs[i] = bad
+		if _, err := parseStopOrderArgs(&RawParams{PWArgs: pwArgs, Args: badArgs}); !errors.Is(err, errArgs) {
+			t.Fatalf("expected errArgs for bad arg %d, got %v", i, err)
+		}
+	}
+	if _, err := parseStopOrderArgs(&RawParams{PWArgs: pwArgs, Args: args[:10]}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for missing arg, got %v", err)
+	}
+
+	id, err := parseCancelStopOrderArgs(&RawParams{Args: []string{"0a0b"}})
+	if err != nil {
+		t.Fatalf("parseCancelStopOrderArgs error: %v", err)
+	}
+	if !bytes.Equal(id, []byte{0x0a, 0x0b}) {
+		t.Fatalf("wrong id %s", id)
+	}
+	if _, err := parseCancelStopOrderArgs(&RawParams{Args: []string{"zz"}}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for bad id, got %v", err)
+	}
+}
+
+func TestParseMultiTradeArgs(t *testing.T) {
+	pwArgs := []encode.PassBytes{encode.PassBytes("password123")}
+	args := []string{"1.2.3.4:3000", "false", "42", "0", "[[100,5],[200,6]]", `{"a":"b"}`}
+	form, err := parseMultiTradeArgs(&RawParams{PWArgs: pwArgs, Args: args})
+	if err != nil {
+		t.Fatalf("parseMultiTradeArgs error: %v", err)
+	}
+	if !bytes.Equal(form.appPass, pwArgs[0]) {
+		t.Fatalf("AppPass doesn't match")
+	}
+	mt := form.srvForm
+	if mt.Host != args[0] || mt.Sell || mt.Base != 42 || mt.Quote != 0 || mt.Options["a"] != "b" ||
+		len(mt.Placements) != 2 || mt.Placements[1].Qty != 200 || mt.Placements[1].Rate != 6 {
+		t.Fatalf("wrong form parsed: %+v", mt)
+	}
+	// Options are optional.
+	if _, err := parseMultiTradeArgs(&RawParams{PWArgs: pwArgs, Args: args[:5]}); err != nil {
+		t.Fatalf("parseMultiTradeArgs error without options: %v", err)
+	}
+	for i, bad := range map[int]string{1: "blue", 2: "-1", 4: "[]", 5: "blue"} {
+		badArgs := append([]string(nil), args...)
+		badArgs[i] = bad
+		if _, err := parseMultiTradeArgs(&RawParams{PWArgs: pwArgs, Args: badArgs}); !errors.Is(err, errArgs) {
+			t.Fatalf("expected errArgs for bad arg %d, got %v", i, err)
+		}
+	}
+	if _, err := parseMultiTradeArgs(&RawParams{PWArgs: pwArgs, Args: args[:4]}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for missing arg, got %v", err)
+	}
+}
+
+func TestParseExportTradesArgs(t *testing.T) {
+	args := []string{"42", "0", "json", "1.2.3.4:3000", "1000", "2000"}
+	form, err := parseExportTradesArgs(&RawParams{Args: args})
+	if err != nil {
+		t.Fatalf("parseExportTradesArgs error: %v", err)
+	}
+	if form.Base != 42 || form.Quote != 0 || form.Format != core.ExportFormatJSON || form.Host != args[3] ||
+		form.Since != 1000 || form.Until != 2000 {
+		t.Fatalf("wrong form parsed: %+v", form)
+	}
+	// Everything after the market is optional.
+	form, err = parseExportTradesArgs(&RawParams{Args: args[:2]})
+	if err != nil {
+		t.Fatalf("parseExportTradesArgs error without options: %v", err)
+	}
+	if form.Format != core.ExportFormatCSV || form.Host != "" || form.Since != 0 || form.Until != 0 {
+		t.Fatalf("wrong defaults parsed: %+v", form)
+	}
+	for i, bad := range map[int]string{0: "-1", 1: "blue", 2: "xml", 4: "blue", 5: "-5"} {
+		badArgs := append([]string(nil), args...)
+		badArgs[i] = bad
+		if _, err := parseExportTradesArgs(&RawParams{Args: badArgs}); !errors.Is(err, errArgs) {
+			t.Fatalf("expected errArgs for bad arg %d, got %v", i, err)
+		}
+	}
+	if _, err := parseExportTradesArgs(&RawParams{Args: args[:1]}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for missing arg, got %v", err)
+	}
+}
+
+func TestParseTxHistoryArgs(t *testing.T) {
+	args := []string{"42", "10", "abc"}
+	form, err := parseTxHistoryArgs(&RawParams{Args: args})
+	if err != nil {
+		t.Fatalf("parseTxHistoryArgs error: %v", err)
+	}
+	if form.assetID != 42 || form.n != 10 || form.refID == nil || *form.refID != "abc" {
+		t.Fatalf("wrong form parsed: %+v", form)
+	}
+	form, err = parseTxHistoryArgs(&RawParams{Args: args[:1]})
+	if err != nil {
+		t.Fatalf("parseTxHistoryArgs error without options: %v", err)
+	}
+	if form.n != 0 || form.refID != nil {
+		t.Fatalf("wrong defaults parsed: %+v", form)
+	}
+	for i, bad := range map[int]string{0: "-1", 1: "blue"} {
+		badArgs := append([]string(nil), args...)
+		badArgs[i] = bad
+		if _, err := parseTxHistoryArgs(&RawParams{Args: badArgs}); !errors.Is(err, errArgs) {
+			t.Fatalf("expected errArgs for bad arg %d, got %v", i, err)
+		}
+	}
+	if _, err := parseTxHistoryArgs(&RawParams{}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for missing arg, got %v", err)
 	}
 }
 
@@ -583,6 +731,40 @@ func TestParseSendOrWithdrawArgs(t *testing.T) {
 		if res.address != test.params.Args[2] {
 			t.Fatalf("address doesn't match")
 		}
+		if res.coins != nil {
+			t.Fatalf("coins set without the coins argument")
+		}
+	}
+
+	// Coins selected for the send.
+	params := paramsWithArgs("42", "5000")
+	params.Args = append(params.Args, `["0a0b"]`)
+	res, err := parseSendOrWithdrawArgs(params)
+	if err != nil {
+		t.Fatalf("unexpected error with coins: %v", err)
+	}
+	if len(res.coins) != 1 || res.coins[0].String() != "0a0b" {
+		t.Fatalf("coins don't match")
+	}
+	params.Args[3] = "blue"
+	if _, err = parseSendOrWithdrawArgs(params); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for bad coins, got %v", err)
+	}
+}
+
+func TestParseListUnspentArgs(t *testing.T) {
+	form, err := parseListUnspentArgs(&RawParams{Args: []string{"42"}})
+	if err != nil {
+		t.Fatalf("parseListUnspentArgs error: %v", err)
+	}
+	if form.assetID != 42 {
+		t.Fatalf("wrong asset ID %d", form.assetID)
+	}
+	if _, err := parseListUnspentArgs(&RawParams{Args: []string{"-1"}}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for bad asset ID, got %v", err)
+	}
+	if _, err := parseListUnspentArgs(&RawParams{}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for missing arg, got %v", err)
 	}
 }
 
@@ -814,3 +996,46 @@ func TestDeleteArchivedRecordsArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestParseBotArgs(t *testing.T) {
+	pwArgs := []encode.PassBytes{encode.PassBytes("password123")}
+	cfgJSON := `{"host":"dex.tld","baseID":42,"quoteID":0,"gapStrategy":"oracle","placements":[{"lots":2,"gapFactor":0.02}],"baseBalance":100}`
+
+	form, err := parseCreateBotArgs(&RawParams{PWArgs: pwArgs, Args: []string{cfgJSON}})
+	if err != nil {
+		t.Fatalf("parseCreateBotArgs error: %v", err)
+	}
+	if !bytes.Equal(form.appPass, pwArgs[0]) {
+		t.Fatalf("appPass doesn't match")
+	}
+	cfg := form.cfg
+	if cfg.Host != "dex.tld" || cfg.BaseID != 42 || cfg.GapStrategy != "oracle" ||
+		len(cfg.Placements) != 1 || cfg.Placements[0].Lots != 2 || cfg.BaseBalance != 100 {
+		t.Fatalf("wrong config parsed: %+v", cfg)
+	}
+	if _, err := parseCreateBotArgs(&RawParams{PWArgs: pwArgs, Args: []string{"{"}}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for bad config, got %v", err)
+	}
+	if _, err := parseCreateBotArgs(&RawParams{Args: []string{cfgJSON}}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for missing password, got %v", err)
+	}
+
+	startForm, err := parseStartBotArgs(&RawParams{PWArgs: pwArgs, Args: []string{"3"}})
+	if err != nil {
+		t.Fatalf("parseStartBotArgs error: %v", err)
+	}
+	if startForm.id != 3 {
+		t.Fatalf("wrong bot ID %d", startForm.id)
+	}
+	if _, err := parseBotIDArgs(&RawParams{Args: []string{"-1"}}); !errors.Is(err, errArgs) {
+		t.Fatalf("expected errArgs for bad ID, got %v", err)
+	}
+
+	updateForm, err := parseUpdateBotArgs(&RawParams{Args: []string{"4", cfgJSON}})
+	if err != nil {
+		t.Fatalf("parseUpdateBotArgs error: %v", err)
+	}
+	if updateForm.id != 4 || updateForm.cfg.Host != "dex.tld" {
+		t.Fatalf("wrong update form")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/core"
 	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/config"
 	"decred.org/dcrdex/dex/encode"
@@ -218,6 +219,54 @@ func (s *WebServer) apiRescanWallet(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, simpleAck(), s.indent)
 }
 
+// apiTxHistory is the handler for the '/txhistory' API request. Retrieves a
+// page of the wallet's transaction history.
+func (s *WebServer) apiTxHistory(w http.ResponseWriter, r *http.Request) {
+	var form struct {
+		AssetID uint32  `json:"assetID"`
+		N       int     `json:"n"`
+		RefID   *string `json:"refID"`
+	}
+	if !readPost(w, r, &form) {
+		return
+	}
+	txs, err := s.core.TxHistory(form.AssetID, form.N, form.RefID)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error retrieving %s transaction history: %w", unbip(form.AssetID), err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK  bool                      `json:"ok"`
+		Txs []*core.WalletTransaction `json:"txs"`
+	}{
+		OK:  true,
+		Txs: txs,
+	}, s.indent)
+}
+
+// apiListUnspent is the handler for the '/listunspent' API request. Lists the
+// wallet's spendable outputs, which can be selected to fund a trade or a send.
+func (s *WebServer) apiListUnspent(w http.ResponseWriter, r *http.Request) {
+	var form struct {
+		AssetID uint32 `json:"assetID"`
+	}
+	if !readPost(w, r, &form) {
+		return
+	}
+	unspents, err := s.core.ListUnspent(form.AssetID)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error listing %s unspent outputs: %w", unbip(form.AssetID), err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK       bool                 `json:"ok"`
+		Unspents []*asset.UnspentCoin `json:"unspents"`
+	}{
+		OK:       true,
+		Unspents: unspents,
+	}, s.indent)
+}
+
 // apiOpenWallet is the handler for the '/openwallet' API request. Unlocks the
 // specified wallet.
 func (s *WebServer) apiOpenWallet(w http.ResponseWriter, r *http.Request) {
@@ -323,6 +372,40 @@ func (s *WebServer) apiTrade(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, resp, s.indent)
 }
 
+// apiMultiTrade is the handler for the '/multitrade' API request.
+func (s *WebServer) apiMultiTrade(w http.ResponseWriter, r *http.Request) {
+	form := new(multiTradeForm)
+	defer form.Pass.Clear()
+	if !readPost(w, r, form) {
+		return
+	}
+	if form.Order == nil {
+		s.writeAPIError(w, errors.New("no multi-trade form"))
+		return
+	}
+	r.Close = true
+	pass, err := s.resolvePass(form.Pass, r)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("password error: %w", err))
+		return
+	}
+	defer zero(pass)
+	ords, err := s.core.MultiTrade(pass, form.Order)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error placing orders: %w", err))
+		return
+	}
+	resp := &struct {
+		OK     bool          `json:"ok"`
+		Orders []*core.Order `json:"orders"`
+	}{
+		OK:     true,
+		Orders: ords,
+	}
+	w.Header().Set("Connection", "close")
+	writeJSON(w, resp, s.indent)
+}
+
 // apiAccountExport is the handler for the '/exportaccount' API request.
 func (s *WebServer) apiAccountExport(w http.ResponseWriter, r *http.Request) {
 	form := new(accountExportForm)
@@ -368,10 +451,9 @@ func (s *WebServer) apiExportSeed(w http.ResponseWriter, r *http.Request) {
 		s.writeAPIError(w, fmt.Errorf("error exporting seed: %w", err))
 		return
 	}
-	defer zero(seed)
 	writeJSON(w, &struct {
-		OK   bool      `json:"ok"`
-		Seed dex.Bytes `json:"seed"`
+		OK   bool   `json:"ok"`
+		Seed string `json:"seed"`
 	}{
 		OK:   true,
 		Seed: seed,
@@ -521,6 +603,63 @@ func (s *WebServer) apiCancel(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, simpleAck(), s.indent)
 }
 
+// apiStopOrder is the handler for the '/stoporder' API request.
+func (s *WebServer) apiStopOrder(w http.ResponseWriter, r *http.Request) {
+	form := new(stopOrderForm)
+	defer form.Pass.Clear()
+	if !readPost(w, r, form) {
+		return
+	}
+	if form.Order == nil {
+		s.writeAPIError(w, errors.New("no stop order form"))
+		return
+	}
+	pass, err := s.resolvePass(form.Pass, r)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("password error: %w", err))
+		return
+	}
+	defer zero(pass)
+	so, err := s.core.StopOrder(pass, form.Order)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error creating stop order: %w", err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK        bool          `json:"ok"`
+		StopOrder *db.StopOrder `json:"stopOrder"`
+	}{
+		OK:        true,
+		StopOrder: so,
+	}, s.indent)
+}
+
+// apiStopOrders is the handler for the '/stoporders' API request.
+func (s *WebServer) apiStopOrders(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, &struct {
+		OK         bool            `json:"ok"`
+		StopOrders []*db.StopOrder `json:"stopOrders"`
+	}{
+		OK:         true,
+		StopOrders: s.core.StopOrders(),
+	}, s.indent)
+}
+
+// apiCancelStopOrder is the handler for the '/cancelstoporder' API request.
+func (s *WebServer) apiCancelStopOrder(w http.ResponseWriter, r *http.Request) {
+	form := &struct {
+		ID dex.Bytes `json:"id"`
+	}{}
+	if !readPost(w, r, form) {
+		return
+	}
+	if err := s.core.CancelStopOrder(form.ID); err != nil {
+		s.writeAPIError(w, fmt.Errorf("error cancelling stop order %s: %w", form.ID, err))
+		return
+	}
+	writeJSON(w, simpleAck(), s.indent)
+}
+
 // apiCloseWallet is the handler for the '/closewallet' API request.
 func (s *WebServer) apiCloseWallet(w http.ResponseWriter, r *http.Request) {
 	form := &struct {
@@ -542,7 +681,6 @@ func (s *WebServer) apiCloseWallet(w http.ResponseWriter, r *http.Request) {
 func (s *WebServer) apiInit(w http.ResponseWriter, r *http.Request) {
 	init := new(initForm)
 	defer init.Pass.Clear()
-	defer zero(init.Seed)
 	if !readPost(w, r, init) {
 		return
 	}
@@ -920,7 +1058,7 @@ func (s *WebServer) send(w http.ResponseWriter, r *http.Request, form *sendOrWit
 		s.writeAPIError(w, fmt.Errorf("no wallet found for %s", unbip(form.AssetID)))
 		return
 	}
-	coin, err := s.core.Send(form.Pass, form.AssetID, form.Value, form.Address, form.Subtract)
+	coin, err := s.core.Send(form.Pass, form.AssetID, form.Value, form.Address, form.Subtract, form.Coins)
 	if err != nil {
 		s.writeAPIError(w, fmt.Errorf("send/withdraw error: %w", err))
 		return
@@ -1053,6 +1191,131 @@ func (s *WebServer) actuallyLogin(w http.ResponseWriter, r *http.Request, login
 	}, s.indent)
 }
 
+// errNoMarketMaker is returned from the bot endpoints when the WebServer was
+// not configured with a MarketMaker.
+var errNoMarketMaker = errors.New("market maker not enabled")
+
+// apiCreateBot is the handler for the '/createbot' API request.
+func (s *WebServer) apiCreateBot(w http.ResponseWriter, r *http.Request) {
+	if s.mm == nil {
+		s.writeAPIError(w, errNoMarketMaker)
+		return
+	}
+	form := new(botForm)
+	defer form.Pass.Clear()
+	if !readPost(w, r, form) {
+		return
+	}
+	pass, err := s.resolvePass(form.Pass, r)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("password error: %w", err))
+		return
+	}
+	defer zero(pass)
+	id, err := s.mm.CreateBot(pass, form.Config)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error creating bot: %w", err))
+		return
+	}
+	writeJSON(w, &struct {
+		OK    bool   `json:"ok"`
+		BotID uint64 `json:"botID"`
+	}{
+		OK:    true,
+		BotID: id,
+	}, s.indent)
+}
+
+// apiStartBot is the handler for the '/startbot' API request.
+func (s *WebServer) apiStartBot(w http.ResponseWriter, r *http.Request) {
+	if s.mm == nil {
+		s.writeAPIError(w, errNoMarketMaker)
+		return
+	}
+	form := new(botForm)
+	defer form.Pass.Clear()
+	if !readPost(w, r, form) {
+		return
+	}
+	pass, err := s.resolvePass(form.Pass, r)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("password error: %w", err))
+		return
+	}
+	defer zero(pass)
+	if err := s.mm.StartBot(pass, form.BotID); err != nil {
+		s.writeAPIError(w, fmt.Errorf("error starting bot %d: %w", form.BotID, err))
+		return
+	}
+	writeJSON(w, simpleAck(), s.indent)
+}
+
+// apiStopBot is the handler for the '/stopbot' API request.
+func (s *WebServer) apiStopBot(w http.ResponseWriter, r *http.Request) {
+	if s.mm == nil {
+		s.writeAPIError(w, errNoMarketMaker)
+		return
+	}
+	form := new(botForm)
+	if !readPost(w, r, form) {
+		return
+	}
+	if err := s.mm.StopBot(form.BotID); err != nil {
+		s.writeAPIError(w, fmt.Errorf("error stopping bot %d: %w", form.BotID, err))
+		return
+	}
+	writeJSON(w, simpleAck(), s.indent)
+}
+
+// apiUpdateBot is the handler for the '/updatebot' API request.
+func (s *WebServer) apiUpdateBot(w http.ResponseWriter, r *http.Request) {
+	if s.mm == nil {
+		s.writeAPIError(w, errNoMarketMaker)
+		return
+	}
+	form := new(botForm)
+	if !readPost(w, r, form) {
+		return
+	}
+	if err := s.mm.UpdateBot(form.BotID, form.Config); err != nil {
+		s.writeAPIError(w, fmt.Errorf("error updating bot %d: %w", form.BotID, err))
+		return
+	}
+	writeJSON(w, simpleAck(), s.indent)
+}
+
+// apiRetireBot is the handler for the '/retirebot' API request.
+func (s *WebServer) apiRetireBot(w http.ResponseWriter, r *http.Request) {
+	if s.mm == nil {
+		s.writeAPIError(w, errNoMarketMaker)
+		return
+	}
+	form := new(botForm)
+	if !readPost(w, r, form) {
+		return
+	}
+	if err := s.mm.RetireBot(form.BotID); err != nil {
+		s.writeAPIError(w, fmt.Errorf("error retiring bot %d: %w", form.BotID, err))
+		return
+	}
+	writeJSON(w, simpleAck(), s.indent)
+}
+
+// apiBots is the handler for the '/bots' API request.
+func (s *WebServer) apiBots(w http.ResponseWriter, r *http.Request) {
+	if s.mm == nil {
+		s.writeAPIError(w, errNoMarketMaker)
+		return
+	}
+	writeJSON(w, &struct {
+		OK   bool            `json:"ok"`
+		Bots []*mm.BotStatus `json:"bots"`
+	}{
+		OK:   true,
+		Bots: s.mm.Bots(),
+	}, s.indent)
+}
+
 // apiUser handles the 'user' API request.
 func (s *WebServer) apiUser(w http.ResponseWriter, r *http.Request) {
 	userInfo := extractUserInfo(r)
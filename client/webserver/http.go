@@ -292,6 +292,7 @@ var allStatuses = map[uint8]string{
 	uint8(order.OrderStatusExecuted): order.OrderStatusExecuted.String(),
 	uint8(order.OrderStatusCanceled): order.OrderStatusCanceled.String(),
 	uint8(order.OrderStatusRevoked):  order.OrderStatusRevoked.String(),
+	uint8(order.OrderStatusExpired):  order.OrderStatusExpired.String(),
 }
 
 // handleOrders is the handler for the /orders page request.
@@ -503,7 +503,7 @@ func (c *TCore) Exchange(host string) (*core.Exchange, error) {
 	return exchange, nil
 }
 
-func (c *TCore) InitializeClient(pw, seed []byte) error {
+func (c *TCore) InitializeClient(pw []byte, seed string) error {
 	randomDelay()
 	c.inited = true
 	return nil
@@ -1426,7 +1426,7 @@ func (c *TCore) SupportedAssets() map[uint32]*core.SupportedAsset {
 	}
 }
 
-func (c *TCore) Send(pw []byte, assetID uint32, value uint64, address string, subtract bool) (asset.Coin, error) {
+func (c *TCore) Send(pw []byte, assetID uint32, value uint64, address string, subtract bool, coinIDs []dex.Bytes) (asset.Coin, error) {
 	return &tCoin{id: []byte{0xde, 0xc7, 0xed}}, nil
 }
 
@@ -1460,6 +1460,15 @@ func (c *TCore) Cancel(pw []byte, oid dex.Bytes) error {
 	return nil
 }
 
+func (c *TCore) StopOrder(pw []byte, form *core.StopOrderForm) (*db.StopOrder, error) {
+	return nil, fmt.Errorf("stop orders not supported")
+}
+func (c *TCore) StopOrders() []*db.StopOrder        { return nil }
+func (c *TCore) MultiTrade(pw []byte, form *core.MultiTradeForm) ([]*core.Order, error) {
+	return nil, nil
+}
+func (c *TCore) CancelStopOrder(id dex.Bytes) error { return nil }
+
 func (c *TCore) NotificationFeed() <-chan core.Notification { return c.noteFeed }
 
 func (c *TCore) runEpochs() {
@@ -1596,9 +1605,14 @@ func (c *TCore) runRandomNotes() {
 	}
 }
 
-func (c *TCore) ExportSeed(pw []byte) ([]byte, error) {
-	b, _ := hex.DecodeString("deadbeef1234567890")
-	return b, nil
+func (c *TCore) ExportSeed(pw []byte) (string, error) {
+	return "zoo abandon ability zoo abandon ability", nil
+}
+func (c *TCore) TxHistory(assetID uint32, n int, refID *string) ([]*core.WalletTransaction, error) {
+	return nil, nil
+}
+func (c *TCore) ListUnspent(assetID uint32) ([]*asset.UnspentCoin, error) {
+	return nil, nil
 }
 func (c *TCore) WalletLogFilePath(uint32) (string, error) {
 	return "", nil
@@ -1643,13 +1657,13 @@ func TestServer(t *testing.T) {
 	rand.Seed(time.Now().UnixNano())
 
 	if initialize {
-		tCore.InitializeClient([]byte(""), nil)
+		tCore.InitializeClient([]byte(""), "")
 	}
 
 	if register {
 		// initialize is implied and forced if register = true.
 		if !initialize {
-			tCore.InitializeClient([]byte(""), nil)
+			tCore.InitializeClient([]byte(""), "")
 		}
 		tCore.Register(new(core.RegisterForm))
 	}
@@ -142,6 +142,7 @@ export interface PendingFeeState {
 export interface FeeBreakdown {
   swap: number
   redemption: number
+  refund: number
 }
 
 export interface SupportedAsset {
@@ -5,6 +5,7 @@ package webserver
 
 import (
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/encode"
 )
@@ -26,7 +27,7 @@ func simpleAck() *standardResponse {
 // The initForm is sent by the client to initialize the DEX.
 type initForm struct {
 	Pass         encode.PassBytes `json:"pass"`
-	Seed         dex.Bytes        `json:"seed,omitempty"`
+	Seed         string           `json:"seed,omitempty"`
 	RememberPass bool             `json:"rememberPass"`
 }
 
@@ -78,6 +79,16 @@ type cancelForm struct {
 	OrderID dex.Bytes        `json:"orderID"`
 }
 
+type multiTradeForm struct {
+	Pass  encode.PassBytes     `json:"pw"`
+	Order *core.MultiTradeForm `json:"order"`
+}
+
+type stopOrderForm struct {
+	Pass  encode.PassBytes    `json:"pw"`
+	Order *core.StopOrderForm `json:"order"`
+}
+
 // sendOrWithdrawForm is sent to initiate either send or withdraw tx.
 type sendOrWithdrawForm struct {
 	AssetID  uint32           `json:"assetID"`
@@ -85,6 +96,9 @@ type sendOrWithdrawForm struct {
 	Address  string           `json:"address"`
 	Subtract bool             `json:"subtract"`
 	Pass     encode.PassBytes `json:"pw"`
+	// Coins are the IDs of the coins to spend. Empty to let the wallet
+	// choose.
+	Coins []dex.Bytes `json:"coins"`
 }
 
 type accountExportForm struct {
@@ -101,3 +115,11 @@ type accountDisableForm struct {
 	Pass encode.PassBytes `json:"pw"`
 	Host string           `json:"host"`
 }
+
+// botForm is used for the market making bot endpoints. Not all fields are
+// used by every endpoint.
+type botForm struct {
+	Pass   encode.PassBytes `json:"pw"`
+	BotID  uint64           `json:"botID"`
+	Config *mm.BotConfig    `json:"config"`
+}
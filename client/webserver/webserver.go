@@ -22,6 +22,8 @@ import (
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/client/websocket"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/encode"
@@ -78,7 +80,7 @@ type clientCore interface {
 	Exchange(host string) (*core.Exchange, error)
 	Register(*core.RegisterForm) (*core.RegisterResult, error)
 	Login(pw []byte) (*core.LoginResult, error)
-	InitializeClient(pw, seed []byte) error
+	InitializeClient(pw []byte, seed string) error
 	AssetBalance(assetID uint32) (*core.WalletBalance, error)
 	CreateWallet(appPW, walletPW []byte, form *core.WalletForm) error
 	OpenWallet(assetID uint32, pw []byte) error
@@ -97,9 +99,13 @@ type clientCore interface {
 	GetDEXConfig(dexAddr string, certI interface{}) (*core.Exchange, error)
 	DiscoverAccount(dexAddr string, pass []byte, certI interface{}) (*core.Exchange, bool, error)
 	SupportedAssets() map[uint32]*core.SupportedAsset
-	Send(pw []byte, assetID uint32, value uint64, address string, subtract bool) (asset.Coin, error)
+	Send(pw []byte, assetID uint32, value uint64, address string, subtract bool, coinIDs []dex.Bytes) (asset.Coin, error)
 	Trade(pw []byte, form *core.TradeForm) (*core.Order, error)
 	Cancel(pw []byte, oid dex.Bytes) error
+	StopOrder(pw []byte, form *core.StopOrderForm) (*db.StopOrder, error)
+	StopOrders() []*db.StopOrder
+	CancelStopOrder(id dex.Bytes) error
+	MultiTrade(pw []byte, form *core.MultiTradeForm) ([]*core.Order, error)
 	NotificationFeed() <-chan core.Notification
 	Logout() error
 	Orders(*core.OrderFilter) ([]*core.Order, error)
@@ -110,9 +116,11 @@ type clientCore interface {
 	AccountImport(pw []byte, account core.Account) error
 	AccountDisable(pw []byte, host string) error
 	IsInitialized() bool
-	ExportSeed(pw []byte) ([]byte, error)
+	ExportSeed(pw []byte) (string, error)
 	PreOrder(*core.TradeForm) (*core.OrderEstimate, error)
 	WalletLogFilePath(assetID uint32) (string, error)
+	TxHistory(assetID uint32, n int, refID *string) ([]*core.WalletTransaction, error)
+	ListUnspent(assetID uint32) ([]*asset.UnspentCoin, error)
 	EstimateRegistrationTxFee(host string, certI interface{}, assetID uint32) (uint64, error)
 	PreAccelerateOrder(oidB dex.Bytes) (*core.PreAccelerate, error)
 	AccelerateOrder(pw []byte, oidB dex.Bytes, newFeeRate uint64) (string, error)
@@ -124,6 +132,18 @@ type clientCore interface {
 
 var _ clientCore = (*core.Core)(nil)
 
+// marketMaker is satisfied by mm.MarketMaker.
+type marketMaker interface {
+	CreateBot(pw []byte, cfg *mm.BotConfig) (uint64, error)
+	StartBot(pw []byte, id uint64) error
+	StopBot(id uint64) error
+	UpdateBot(id uint64, cfg *mm.BotConfig) error
+	RetireBot(id uint64) error
+	Bots() []*mm.BotStatus
+}
+
+var _ marketMaker = (*mm.MarketMaker)(nil)
+
 // cachedPassword consists of the seralized crypter and an encrypted password.
 // A key stored in the cookies is used to deserialize the crypter, then
 // the crypter is used to decrypt the password.
@@ -140,6 +160,9 @@ type Config struct {
 	Logger        dex.Logger
 	ReloadHTML    bool
 	HttpProf      bool
+	// MarketMaker is optional. If not provided, the bot endpoints will return
+	// errors.
+	MarketMaker marketMaker
 }
 
 // WebServer is a single-client http and websocket server enabling a browser
@@ -148,6 +171,7 @@ type WebServer struct {
 	wsServer   *websocket.Server
 	mux        *chi.Mux
 	core       clientCore
+	mm         marketMaker
 	addr       string
 	csp        string
 	srv        *http.Server
@@ -217,6 +241,7 @@ func New(cfg *Config) (*WebServer, error) {
 	// Make the server here so its methods can be registered.
 	s := &WebServer{
 		core:            cfg.Core,
+		mm:              cfg.MarketMaker,
 		mux:             mux,
 		srv:             httpServer,
 		addr:            cfg.Addr,
@@ -334,8 +359,14 @@ func New(cfg *Config) (*WebServer, error) {
 			apiAuth.Post("/connectwallet", s.apiConnectWallet)
 			apiAuth.Post("/rescanwallet", s.apiRescanWallet)
 			apiAuth.Post("/recoverwallet", s.apiRecoverWallet)
+			apiAuth.Post("/txhistory", s.apiTxHistory)
+			apiAuth.Post("/listunspent", s.apiListUnspent)
 			apiAuth.Post("/trade", s.apiTrade)
+			apiAuth.Post("/multitrade", s.apiMultiTrade)
 			apiAuth.Post("/cancel", s.apiCancel)
+			apiAuth.Post("/stoporder", s.apiStopOrder)
+			apiAuth.Get("/stoporders", s.apiStopOrders)
+			apiAuth.Post("/cancelstoporder", s.apiCancelStopOrder)
 			apiAuth.Post("/logout", s.apiLogout)
 			apiAuth.Post("/balance", s.apiGetBalance)
 			apiAuth.Post("/parseconfig", s.apiParseConfig)
@@ -359,6 +390,12 @@ func New(cfg *Config) (*WebServer, error) {
 			apiAuth.Post("/updatecert", s.apiUpdateCert)
 			apiAuth.Post("/updatedexhost", s.apiUpdateDEXHost)
 			apiAuth.Post("/restorewalletinfo", s.apiRestoreWalletInfo)
+			apiAuth.Post("/createbot", s.apiCreateBot)
+			apiAuth.Post("/startbot", s.apiStartBot)
+			apiAuth.Post("/stopbot", s.apiStopBot)
+			apiAuth.Post("/updatebot", s.apiUpdateBot)
+			apiAuth.Post("/retirebot", s.apiRetireBot)
+			apiAuth.Get("/bots", s.apiBots)
 		})
 	})
 
@@ -17,6 +17,8 @@ import (
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/order"
@@ -69,6 +71,13 @@ type TCore struct {
 	notHas           bool
 	notRunning       bool
 	notOpen          bool
+	stopOrderErr     error
+	multiTradeErr    error
+	txHistory        []*core.WalletTransaction
+	txHistoryErr     error
+	unspents         []*asset.UnspentCoin
+	listUnspentErr   error
+	sentCoins        []dex.Bytes
 }
 
 func (c *TCore) Network() dex.Network                         { return dex.Mainnet }
@@ -84,9 +93,9 @@ func (c *TCore) Register(r *core.RegisterForm) (*core.RegisterResult, error) { r
 func (c *TCore) EstimateRegistrationTxFee(host string, certI interface{}, assetID uint32) (uint64, error) {
 	return 0, nil
 }
-func (c *TCore) InitializeClient(pw, seed []byte) error     { return c.initErr }
-func (c *TCore) Login(pw []byte) (*core.LoginResult, error) { return &core.LoginResult{}, c.loginErr }
-func (c *TCore) IsInitialized() bool                        { return c.isInited }
+func (c *TCore) InitializeClient(pw []byte, seed string) error { return c.initErr }
+func (c *TCore) Login(pw []byte) (*core.LoginResult, error)    { return &core.LoginResult{}, c.loginErr }
+func (c *TCore) IsInitialized() bool                           { return c.isInited }
 func (c *TCore) SyncBook(dex string, base, quote uint32) (core.BookFeed, error) {
 	return c.syncFeed, c.syncErr
 }
@@ -127,7 +136,8 @@ func (c *TCore) User() *core.User { return nil }
 func (c *TCore) SupportedAssets() map[uint32]*core.SupportedAsset {
 	return make(map[uint32]*core.SupportedAsset)
 }
-func (c *TCore) Send(pw []byte, assetID uint32, value uint64, address string, subtract bool) (asset.Coin, error) {
+func (c *TCore) Send(pw []byte, assetID uint32, value uint64, address string, subtract bool, coinIDs []dex.Bytes) (asset.Coin, error) {
+	c.sentCoins = coinIDs
 	return &tCoin{id: []byte{0xde, 0xc7, 0xed}}, c.sendErr
 }
 func (c *TCore) Trade(pw []byte, form *core.TradeForm) (*core.Order, error) {
@@ -146,6 +156,28 @@ func (c *TCore) Trade(pw []byte, form *core.TradeForm) (*core.Order, error) {
 
 func (c *TCore) Cancel(pw []byte, oid dex.Bytes) error { return nil }
 
+func (c *TCore) StopOrder(pw []byte, form *core.StopOrderForm) (*db.StopOrder, error) {
+	if c.stopOrderErr != nil {
+		return nil, c.stopOrderErr
+	}
+	return &db.StopOrder{StopRate: form.StopRate}, nil
+}
+
+func (c *TCore) StopOrders() []*db.StopOrder { return nil }
+
+func (c *TCore) MultiTrade(pw []byte, form *core.MultiTradeForm) ([]*core.Order, error) {
+	if c.multiTradeErr != nil {
+		return nil, c.multiTradeErr
+	}
+	ords := make([]*core.Order, 0, len(form.Placements))
+	for _, p := range form.Placements {
+		ords = append(ords, &core.Order{Qty: p.Qty, Rate: p.Rate})
+	}
+	return ords, nil
+}
+
+func (c *TCore) CancelStopOrder(id dex.Bytes) error { return c.stopOrderErr }
+
 func (c *TCore) NotificationFeed() <-chan core.Notification { return make(chan core.Notification, 1) }
 
 func (c *TCore) AckNotes(ids []dex.Bytes) {}
@@ -171,12 +203,18 @@ func (c *TCore) AccountImport(pw []byte, account core.Account) error {
 }
 func (c *TCore) AccountDisable(pw []byte, host string) error { return nil }
 
-func (c *TCore) ExportSeed(pw []byte) ([]byte, error) {
-	return []byte("ab"), nil
+func (c *TCore) ExportSeed(pw []byte) (string, error) {
+	return "zoo abandon ability", nil
 }
 func (c *TCore) WalletLogFilePath(uint32) (string, error) {
 	return "", nil
 }
+func (c *TCore) TxHistory(assetID uint32, n int, refID *string) ([]*core.WalletTransaction, error) {
+	return c.txHistory, c.txHistoryErr
+}
+func (c *TCore) ListUnspent(assetID uint32) ([]*asset.UnspentCoin, error) {
+	return c.unspents, c.listUnspentErr
+}
 func (c *TCore) AccelerateOrder(pw []byte, oidB dex.Bytes, newFeeRate uint64) (string, error) {
 	return "", nil
 }
@@ -486,6 +524,18 @@ func testAPISendAndAPIWithdraw(t *testing.T, withdraw bool) {
 	if !isOK() {
 		t.Fatalf("not ok afterwards: %s", string(writer.b))
 	}
+
+	// Selected coins are passed through.
+	body = &sendOrWithdrawForm{
+		Pass:  encode.PassBytes("dummyAppPass"),
+		Coins: []dex.Bytes{{0x0a}},
+	}
+	if !isOK() {
+		t.Fatalf("not ok with coins: %s", string(writer.b))
+	}
+	if len(tCore.sentCoins) != 1 || tCore.sentCoins[0][0] != 0x0a {
+		t.Fatalf("coins not passed to Send")
+	}
 }
 
 func TestAPIInit(t *testing.T) {
@@ -586,6 +636,171 @@ func TestApiGetBalance(t *testing.T) {
 	tCore.balanceErr = nil
 }
 
+func TestAPITxHistory(t *testing.T) {
+	writer := new(TWriter)
+	reader := new(TReader)
+	s, tCore, shutdown, _ := newTServer(t, false)
+	defer shutdown()
+
+	ensure := func(want string) {
+		t.Helper()
+		ensureResponse(t, s.apiTxHistory, want, reader, writer, map[string]interface{}{"assetID": 42, "n": 1}, nil)
+	}
+	tCore.txHistory = []*core.WalletTransaction{{
+		WalletTransaction: &asset.WalletTransaction{Type: asset.Swap, ID: "abc", Amount: 5, Fees: 1},
+		MatchIDs:          []dex.Bytes{{0x0a}},
+	}}
+	ensure(`{"ok":true,"txs":[{"type":3,"id":"abc","amount":5,"fees":1,"blockNumber":0,"timestamp":0,"matchIDs":["0a"]}]}`)
+
+	tCore.txHistoryErr = tErr
+	ensure(fmt.Sprintf(`{"ok":false,"msg":"%s"}`, tErr))
+}
+
+func TestAPIListUnspent(t *testing.T) {
+	writer := new(TWriter)
+	reader := new(TReader)
+	s, tCore, shutdown, _ := newTServer(t, false)
+	defer shutdown()
+
+	ensure := func(want string) {
+		t.Helper()
+		ensureResponse(t, s.apiListUnspent, want, reader, writer, map[string]interface{}{"assetID": 42}, nil)
+	}
+	tCore.unspents = []*asset.UnspentCoin{{ID: dex.Bytes{0x0a}, Value: 5, Address: "addr", Confirmations: 2}}
+	ensure(`{"ok":true,"unspents":[{"id":"0a","value":5,"address":"addr","confs":2}]}`)
+
+	tCore.listUnspentErr = tErr
+	ensure(fmt.Sprintf(`{"ok":false,"msg":"%s"}`, tErr))
+}
+
+func TestAPIStopOrders(t *testing.T) {
+	writer := new(TWriter)
+	reader := new(TReader)
+	s, tCore, shutdown, _ := newTServer(t, false)
+	defer shutdown()
+
+	ensure := func(f func(http.ResponseWriter, *http.Request), body interface{}, want string) {
+		t.Helper()
+		ensureResponse(t, f, want, reader, writer, body, nil)
+	}
+
+	form := &stopOrderForm{
+		Pass:  encode.PassBytes("abc"),
+		Order: &core.StopOrderForm{Trade: &core.TradeForm{}, StopRate: 5},
+	}
+	ensure(s.apiStopOrder, &stopOrderForm{}, `{"ok":false,"msg":"no stop order form"}`)
+	ensure(s.apiStopOrder, form, `{"ok":true,"stopOrder":{"id":"","host":"","baseID":0,"quoteID":0,`+
+		`"sell":false,"isLimit":false,"qty":0,"rate":0,"tifnow":false,"options":null,"stopRate":5,"trailRate":0,"stamp":0}}`)
+	ensure(s.apiStopOrders, nil, `{"ok":true,"stopOrders":null}`)
+	cancelForm := map[string]string{"id": "0a0b"}
+	ensure(s.apiCancelStopOrder, cancelForm, `{"ok":true}`)
+
+	tCore.stopOrderErr = tErr
+	ensure(s.apiStopOrder, form, fmt.Sprintf(`{"ok":false,"msg":"%s"}`, tErr))
+	ensure(s.apiCancelStopOrder, cancelForm, fmt.Sprintf(`{"ok":false,"msg":"%s"}`, tErr))
+}
+
+func TestAPIMultiTrade(t *testing.T) {
+	writer := new(TWriter)
+	reader := new(TReader)
+	s, tCore, shutdown, _ := newTServer(t, false)
+	defer shutdown()
+
+	ensure := func(body interface{}, want func(string) bool) {
+		t.Helper()
+		reader.msg, _ = json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/", reader)
+		s.apiMultiTrade(writer, req)
+		if got := string(writer.b); !want(got) {
+			t.Fatalf("unexpected response %s", got)
+		}
+		writer.b = nil
+	}
+	hasPrefix := func(pre string) func(string) bool {
+		return func(s string) bool { return strings.HasPrefix(s, pre) }
+	}
+
+	form := &multiTradeForm{
+		Pass: encode.PassBytes("abc"),
+		Order: &core.MultiTradeForm{
+			Placements: []*core.QtyRate{{Qty: 1, Rate: 2}, {Qty: 3, Rate: 4}},
+		},
+	}
+	ensure(&multiTradeForm{}, hasPrefix(`{"ok":false,"msg":"no multi-trade form"}`))
+	ensure(form, func(s string) bool {
+		var resp struct {
+			OK     bool          `json:"ok"`
+			Orders []*core.Order `json:"orders"`
+		}
+		return json.Unmarshal([]byte(s), &resp) == nil && resp.OK && len(resp.Orders) == 2 &&
+			resp.Orders[1].Qty == 3
+	})
+	tCore.multiTradeErr = tErr
+	ensure(form, hasPrefix(fmt.Sprintf(`{"ok":false,"msg":"%s"}`, tErr)))
+}
+
+type TMarketMaker struct {
+	createErr error
+	startErr  error
+	stopErr   error
+	bots      []*mm.BotStatus
+}
+
+func (m *TMarketMaker) CreateBot(pw []byte, cfg *mm.BotConfig) (uint64, error) {
+	return 1, m.createErr
+}
+func (m *TMarketMaker) StartBot(pw []byte, id uint64) error {
+	return m.startErr
+}
+func (m *TMarketMaker) StopBot(id uint64) error {
+	return m.stopErr
+}
+func (m *TMarketMaker) UpdateBot(id uint64, cfg *mm.BotConfig) error {
+	return nil
+}
+func (m *TMarketMaker) RetireBot(id uint64) error {
+	return nil
+}
+func (m *TMarketMaker) Bots() []*mm.BotStatus {
+	return m.bots
+}
+
+func TestAPIBots(t *testing.T) {
+	writer := new(TWriter)
+	reader := new(TReader)
+	s, _, shutdown, _ := newTServer(t, false)
+	defer shutdown()
+
+	body := &botForm{
+		Pass:   encode.PassBytes("abc"),
+		BotID:  1,
+		Config: &mm.BotConfig{Host: "dex.tld"},
+	}
+	ensure := func(f func(http.ResponseWriter, *http.Request), want string) {
+		t.Helper()
+		ensureResponse(t, f, want, reader, writer, body, nil)
+	}
+	noMMResp := `{"ok":false,"msg":"market maker not enabled"}`
+	ensure(s.apiCreateBot, noMMResp)
+	ensure(s.apiBots, noMMResp)
+
+	tMM := &TMarketMaker{bots: []*mm.BotStatus{{ID: 1}}}
+	s.mm = tMM
+	ensure(s.apiCreateBot, `{"ok":true,"botID":1}`)
+	ensure(s.apiStartBot, `{"ok":true}`)
+	ensure(s.apiStopBot, `{"ok":true}`)
+	ensure(s.apiUpdateBot, `{"ok":true}`)
+	ensure(s.apiRetireBot, `{"ok":true}`)
+	ensure(s.apiBots, `{"ok":true,"bots":[{"id":1,"config":null,"running":false,"orders":null}]}`)
+
+	tMM.createErr = tErr
+	ensure(s.apiCreateBot, fmt.Sprintf(`{"ok":false,"msg":"%s"}`, tErr))
+	tMM.startErr = tErr
+	ensure(s.apiStartBot, fmt.Sprintf(`{"ok":false,"msg":"%s"}`, tErr))
+	tMM.stopErr = tErr
+	ensure(s.apiStopBot, fmt.Sprintf(`{"ok":false,"msg":"%s"}`, tErr))
+}
+
 type tHTTPHandler struct {
 	req *http.Request
 }
@@ -4,6 +4,7 @@
 package calc
 
 import (
+	"math"
 	"math/big"
 
 	"decred.org/dcrdex/dex"
@@ -54,3 +55,15 @@ func ConventionalRate(msgRate uint64, baseInfo, quoteInfo dex.UnitInfo) float64
 func ConventionalRateAlt(msgRate uint64, baseFactor, quoteFactor uint64) float64 {
 	return float64(msgRate) / RateEncodingFactor * float64(baseFactor) / float64(quoteFactor)
 }
+
+// MessageRate converts an exchange rate in conventional encoding to one in
+// message-rate encoding, using the base and quote assets' UnitInfo.
+func MessageRate(convRate float64, baseInfo, quoteInfo dex.UnitInfo) uint64 {
+	return MessageRateAlt(convRate, baseInfo.Conventional.ConversionFactor, quoteInfo.Conventional.ConversionFactor)
+}
+
+// MessageRateAlt converts a conventional exchange rate to message-rate
+// encoding using the base and quote assets' conventional conversion factors.
+func MessageRateAlt(convRate float64, baseFactor, quoteFactor uint64) uint64 {
+	return uint64(math.Round(convRate * RateEncodingFactor * float64(quoteFactor) / float64(baseFactor)))
+}
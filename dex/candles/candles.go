@@ -152,6 +152,26 @@ func (c *Cache) Delta(since time.Time) (changePct float64, vol uint64) {
 	return (float64(endRate) - float64(startRate)) / float64(startRate), vol
 }
 
+// HighLow finds the highest and lowest rates of the candles with trading
+// activity that end after the specified time. Candles without any match
+// volume are ignored. Zero values are returned if there is no trading activity.
+func (c *Cache) HighLow(since time.Time) (high, low uint64) {
+	cutoff := uint64(since.UnixMilli())
+	for i := range c.Candles {
+		candle := &c.Candles[i]
+		if candle.EndStamp <= cutoff || candle.MatchVolume == 0 {
+			continue
+		}
+		if candle.HighRate > high {
+			high = candle.HighRate
+		}
+		if candle.LowRate != 0 && (low == 0 || candle.LowRate < low) {
+			low = candle.LowRate
+		}
+	}
+	return
+}
+
 // last gets the most recent candle in the cache.
 func (c *Cache) Last() *Candle {
 	return &c.Candles[c.cursor]
@@ -221,3 +221,48 @@ func TestDelta(t *testing.T) {
 		t.Fatalf("wrong 12-hour volume. wanted 110, got %d", vol6)
 	}
 }
+
+func TestHighLow(t *testing.T) {
+	tNow := time.Now().Truncate(time.Millisecond)
+	now := uint64(tNow.UnixMilli())
+	aDayAgo := now - 86400*1000
+
+	c := NewCache(5, fiveMins)
+	if high, low := c.HighLow(tNow.Add(-time.Hour * 24)); high != 0 || low != 0 {
+		t.Fatalf("wanted zeros for empty cache, got %d, %d", high, low)
+	}
+	// This one shouldn't be included.
+	c.Add(&Candle{
+		MatchVolume: 100,
+		StartStamp:  aDayAgo - fiveMins,
+		EndStamp:    aDayAgo,
+		HighRate:    500,
+		LowRate:     10,
+	})
+	c.Add(&Candle{
+		MatchVolume: 150,
+		StartStamp:  now - 3*fiveMins,
+		EndStamp:    now - 2*fiveMins,
+		HighRate:    150,
+		LowRate:     90,
+	})
+	// No volume, so not included.
+	c.Add(&Candle{
+		StartStamp: now - 2*fiveMins,
+		EndStamp:   now - fiveMins,
+		HighRate:   300,
+		LowRate:    50,
+	})
+	c.Add(&Candle{
+		MatchVolume: 50,
+		StartStamp:  now - fiveMins,
+		EndStamp:    now,
+		HighRate:    175,
+		LowRate:     120,
+	})
+
+	high, low := c.HighLow(tNow.Add(-time.Hour * 24))
+	if high != 175 || low != 90 {
+		t.Fatalf("wrong high/low. wanted 175/90, got %d/%d", high, low)
+	}
+}
@@ -636,6 +636,41 @@ func TestLimit(t *testing.T) {
 	if limitBack.TiF != limit.TiF {
 		t.Fatal(limitBack.TiF, limit.TiF)
 	}
+
+	// A good-til-time order includes the expiry epoch after the time-in-force.
+	limit.TiF = GoodTilTimeOrderNum
+	limit.ExpiryEpoch = 0x0102030405060708
+	b = limit.Serialize()
+	tifIdx := len(prefix.Serialize()) + len(trade.Serialize()) + 8
+	if b[tifIdx] != GoodTilTimeOrderNum {
+		t.Fatalf("wrong time-in-force byte %d", b[tifIdx])
+	}
+	if !bytes.Equal(b[tifIdx+1:tifIdx+9], []byte{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Fatalf("wrong expiry epoch bytes %x", b[tifIdx+1:tifIdx+9])
+	}
+	if string(b[tifIdx+9:]) != addr {
+		t.Fatalf("wrong address %q", string(b[tifIdx+9:]))
+	}
+
+	// An iceberg order includes the display quantity before the address.
+	limit.DisplayQty = 0x1112131415161718
+	b = limit.Serialize()
+	if !bytes.Equal(b[tifIdx+9:tifIdx+17], []byte{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18}) {
+		t.Fatalf("wrong display quantity bytes %x", b[tifIdx+9:tifIdx+17])
+	}
+	if string(b[tifIdx+17:]) != addr {
+		t.Fatalf("wrong address %q", string(b[tifIdx+17:]))
+	}
+
+	// A replacement order includes the replaced order ID before the address.
+	limit.Replaces = bytes.Repeat([]byte{0x21}, 32)
+	b = limit.Serialize()
+	if !bytes.Equal(b[tifIdx+17:tifIdx+49], limit.Replaces) {
+		t.Fatalf("wrong replaced order ID bytes %x", b[tifIdx+17:tifIdx+49])
+	}
+	if string(b[tifIdx+49:]) != addr {
+		t.Fatalf("wrong address %q", string(b[tifIdx+49:]))
+	}
 }
 
 func TestMarket(t *testing.T) {
@@ -1021,6 +1056,95 @@ func TestNotifyFee(t *testing.T) {
 	}
 }
 
+func TestPostBond(t *testing.T) {
+	// serialization: pubkey (33) + asset ID (4) + version (2) + coin ID (36) = 75
+	pk, _ := hex.DecodeString("f06e5cf13fc6debb8b90776da6624991ba50a11e784efed53d0a81c3be98397982")
+	coinID, _ := hex.DecodeString("51891f751b0dd987c0b8ff1703cd0dd3e2712847f4bdbc268c9656dc80d233c700000001")
+	postBond := &PostBond{
+		AcctPubKey: pk,
+		AssetID:    42,
+		Version:    0,
+		CoinID:     coinID,
+	}
+
+	exp := []byte{
+		// PubKey 33 bytes
+		0xf0, 0x6e, 0x5c, 0xf1, 0x3f, 0xc6, 0xde, 0xbb, 0x8b, 0x90, 0x77, 0x6d,
+		0xa6, 0x62, 0x49, 0x91, 0xba, 0x50, 0xa1, 0x1e, 0x78, 0x4e, 0xfe, 0xd5,
+		0x3d, 0x0a, 0x81, 0xc3, 0xbe, 0x98, 0x39, 0x79, 0x82,
+		// Asset ID 4 bytes
+		0x00, 0x00, 0x00, 0x2a,
+		// Version 2 bytes
+		0x00, 0x00,
+		// Coin ID 36 bytes
+		0x51, 0x89, 0x1f, 0x75, 0x1b, 0x0d, 0xd9, 0x87, 0xc0, 0xb8, 0xff, 0x17,
+		0x03, 0xcd, 0x0d, 0xd3, 0xe2, 0x71, 0x28, 0x47, 0xf4, 0xbd, 0xbc, 0x26,
+		0x8c, 0x96, 0x56, 0xdc, 0x80, 0xd2, 0x33, 0xc7, 0x00, 0x00, 0x00, 0x01,
+	}
+
+	b := postBond.Serialize()
+	if !bytes.Equal(b, exp) {
+		t.Fatalf("unexpected serialization. Wanted %x, got %x", exp, b)
+	}
+
+	postBondB, err := json.Marshal(postBond)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var postBondBack PostBond
+	err = json.Unmarshal(postBondB, &postBondBack)
+	if err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+
+	if !bytes.Equal(postBondBack.AcctPubKey, postBond.AcctPubKey) {
+		t.Fatal(postBondBack.AcctPubKey, postBond.AcctPubKey)
+	}
+	if !bytes.Equal(postBondBack.CoinID, postBond.CoinID) {
+		t.Fatal(postBondBack.CoinID, postBond.CoinID)
+	}
+	if postBondBack.AssetID != postBond.AssetID {
+		t.Fatal(postBondBack.AssetID, postBond.AssetID)
+	}
+}
+
+func TestPostBondResult(t *testing.T) {
+	acctID, _ := hex.DecodeString("bd3faf7353b8fc40618527687b3ef99d00da480e354f2c4986479e2da626acf5")
+	coinID, _ := hex.DecodeString("51891f751b0dd987c0b8ff1703cd0dd3e2712847f4bdbc268c9656dc80d233c700000001")
+	res := &PostBondResult{
+		AccountID: acctID,
+		AssetID:   42,
+		Amount:    1e8,
+		Expiry:    1571704611,
+		BondID:    coinID,
+		Tier:      2,
+	}
+
+	b := res.Serialize()
+	if len(b) != 32+4+8+8+len(coinID)+8 {
+		t.Fatalf("wrong serialization length %d", len(b))
+	}
+	if !bytes.Equal(b[:32], acctID) || !bytes.Equal(b[52:52+len(coinID)], coinID) {
+		t.Fatalf("unexpected serialization %x", b)
+	}
+	if b[len(b)-1] != 2 {
+		t.Fatalf("wrong tier serialization %x", b[len(b)-8:])
+	}
+
+	resB, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var resBack PostBondResult
+	if err = json.Unmarshal(resB, &resBack); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if !bytes.Equal(resBack.Serialize(), b) {
+		t.Fatalf("wrong serialization after round trip")
+	}
+}
+
 func TestSignable(t *testing.T) {
 	sig := []byte{
 		0x07, 0xad, 0x7f, 0x33, 0xc5, 0xb0, 0x13, 0xa1, 0xbb, 0xd6, 0xad, 0xc0,
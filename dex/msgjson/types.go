@@ -80,6 +80,12 @@ const (
 	RPCWalletRescanError                 // 62
 	RPCDeleteArchivedRecordsError        // 63
 	DuplicateRequestError                // 64
+	BondError                            // 65
+	RPCMarketMakerError                  // 66
+	RPCStopOrderError                    // 67
+	RPCExportTradesError                 // 68
+	RPCTxHistoryError                    // 69
+	RPCListUnspentError                  // 70
 )
 
 // Routes are destinations for a "payload" of data. The type of data being
@@ -129,6 +135,13 @@ const (
 	// CancelRoute is the client-originating request-type message placing a cancel
 	// order.
 	CancelRoute = "cancel"
+	// MultiTradeRoute is the client-originating request-type message placing a
+	// batch of limit orders on a single market.
+	MultiTradeRoute = "multitrade"
+	// ReplaceRoute is the client-originating request-type message placing a
+	// limit order that replaces one of the user's standing limit orders. The
+	// payload is a LimitOrder with the Replaces field set.
+	ReplaceRoute = "replace"
 	// OrderBookRoute is the client-originating request-type message subscribing
 	// to an order book update notification feed.
 	OrderBookRoute = "orderbook"
@@ -161,9 +174,21 @@ const (
 	// DEX that the fee has been paid and has the requisite number of
 	// confirmations.
 	NotifyFeeRoute = "notifyfee"
+	// PostBondRoute is the client-originating request-type message used to post
+	// a new fidelity bond. This can create a new account or it can add bond to
+	// an existing account.
+	PostBondRoute = "postbond"
+	// BondExpiredRoute is the DEX-originating notification-type message
+	// informing the client that one of their bonds has expired and no longer
+	// counts toward the account's tier.
+	BondExpiredRoute = "bondexpired"
 	// ConfigRoute is the client-originating request-type message requesting the
 	// DEX configuration information.
 	ConfigRoute = "config"
+	// ConfigUpdateRoute is the DEX-originating notification-type message
+	// delivering the updated DEX configuration after the operator adds,
+	// retires, or reconfigures a market. The payload is a ConfigResult.
+	ConfigUpdateRoute = "config_update"
 	// MatchProofRoute is the DEX-originating notification-type message
 	// delivering match cycle results to the client.
 	MatchProofRoute = "match_proof"
@@ -199,6 +224,16 @@ const (
 	// CandlesRoute is the HTTP request to get the set of candlesticks
 	// representing market activity history.
 	CandlesRoute = "candles"
+	// TradesRoute is the HTTP or WebSocket request to get a page of a
+	// market's recently executed trades.
+	TradesRoute = "trades"
+	// TickerRoute is the HTTP or WebSocket request to get the 24-hour
+	// statistics for the DEX's markets.
+	TickerRoute = "ticker"
+	// EpochTranscriptRoute is the HTTP or WebSocket request to get the
+	// transcript of a market's epoch, with which the epoch's shuffle and
+	// matching can be independently verified.
+	EpochTranscriptRoute = "epochtranscript"
 )
 
 const errNullRespPayload = dex.ErrorKind("null response payload")
@@ -473,6 +508,9 @@ func (m *Match) Serialize() []byte {
 // Nomatch is the payload for a server-originating NoMatchRoute notification.
 type NoMatch struct {
 	OrderID Bytes `json:"orderid"`
+	// Failed is set for a replacement order that could not take the place of
+	// its target order, which remains booked.
+	Failed bool `json:"failed,omitempty"`
 }
 
 // MatchRequest details a match for the MatchStatusRoute request. The actual
@@ -631,13 +669,14 @@ func (r *Redemption) Serialize() []byte {
 }
 
 const (
-	BuyOrderNum       = 1
-	SellOrderNum      = 2
-	StandingOrderNum  = 1
-	ImmediateOrderNum = 2
-	LimitOrderNum     = 1
-	MarketOrderNum    = 2
-	CancelOrderNum    = 3
+	BuyOrderNum         = 1
+	SellOrderNum        = 2
+	StandingOrderNum    = 1
+	ImmediateOrderNum   = 2
+	GoodTilTimeOrderNum = 3
+	LimitOrderNum       = 1
+	MarketOrderNum      = 2
+	CancelOrderNum      = 3
 )
 
 // Coin is information for validating funding coins. Some number of
@@ -716,21 +755,47 @@ type LimitOrder struct {
 	Trade
 	Rate uint64 `json:"rate"`
 	TiF  uint8  `json:"timeinforce"`
+	// ExpiryEpoch is the index of the last epoch that a good-til-time order
+	// may remain on the book. Only used with GoodTilTimeOrderNum.
+	ExpiryEpoch uint64 `json:"expiryepoch,omitempty"`
+	// DisplayQty is the quantity displayed on the book for an iceberg order.
+	// Zero if the order's full size is displayed.
+	DisplayQty uint64 `json:"displayqty,omitempty"`
+	// Replaces is the ID of the standing limit order that this order replaces.
+	// Only used with the ReplaceRoute.
+	Replaces Bytes `json:"replaces,omitempty"`
 }
 
 // Serialize serializes the Limit data.
 func (l *LimitOrder) Serialize() []byte {
 	// serialization: prefix (89) + trade (variable) + rate (8)
-	// + time-in-force (1) + address (~35) = 133 + len(trade)
+	// + time-in-force (1) + [expiry epoch (8)] + [display qty (8)]
+	// + [replaced order ID (32)] + address (~35)
+	// = 133 + len(trade) [+ 8] [+ 8] [+ 32]
 	trade := l.Trade.Serialize()
-	b := make([]byte, 0, 133+len(trade))
+	b := make([]byte, 0, 181+len(trade))
 	b = append(b, l.Prefix.Serialize()...)
 	b = append(b, trade...)
 	b = append(b, uint64Bytes(l.Rate)...)
 	b = append(b, l.TiF)
+	if l.TiF == GoodTilTimeOrderNum {
+		b = append(b, uint64Bytes(l.ExpiryEpoch)...)
+	}
+	if l.DisplayQty > 0 {
+		b = append(b, uint64Bytes(l.DisplayQty)...)
+	}
+	b = append(b, l.Replaces...)
 	return append(b, []byte(l.Trade.Address)...)
 }
 
+// MultiTrade is the payload for the MultiTradeRoute, which places a batch of
+// limit orders on a single market. Each order is signed individually. The
+// orders are either all accepted into the same epoch, or all rejected. The
+// response is a []*OrderResult in the same order as Orders.
+type MultiTrade struct {
+	Orders []*LimitOrder `json:"orders"`
+}
+
 // MarketOrder is the payload for the MarketRoute, which places a market order.
 type MarketOrder struct {
 	Prefix
@@ -912,6 +977,19 @@ type ConnectResult struct {
 	ActiveMatches       []*Match       `json:"activematches"`
 	Score               int32          `json:"score"`
 	Suspended           *bool          `json:"suspended,omitempty"` // will be implied (obsolete) with tiers and bonds
+	Tier                *int64         `json:"tier,omitempty"`      // 1+ means bonded and may trade, a function of active bond amounts and conduct, nil for legacy
+	ActiveBonds         []*Bond        `json:"activeBonds,omitempty"`
+	LegacyFeePaid       *bool          `json:"legacyFeePaid,omitempty"` // not set by legacy server
+}
+
+// Bond is information on a fidelity bond. This is part of the ConnectResult
+// and PostBondResult payloads.
+type Bond struct {
+	Version uint16 `json:"version"`
+	Amount  uint64 `json:"amount"`
+	Expiry  uint64 `json:"expiry"` // when it expires for the DEX, not the script's lock time
+	CoinID  Bytes  `json:"coinID"`
+	AssetID uint32 `json:"assetID"`
 }
 
 // PenaltyNote is the payload of a Penalty notification.
@@ -1019,6 +1097,75 @@ type NotifyFeeResult struct {
 	Signature
 }
 
+// PostBond is the payload for a client-originating PostBondRoute request. The
+// bond transaction must be broadcasted and have the required number of
+// confirmations before the request is made.
+type PostBond struct {
+	Signature
+	AcctPubKey Bytes  `json:"acctPubKey"` // acctID = blake256(blake256(acctPubKey))
+	AssetID    uint32 `json:"assetID"`
+	Version    uint16 `json:"version"`
+	CoinID     Bytes  `json:"coinid"`
+}
+
+// Serialize serializes the PostBond data.
+func (pb *PostBond) Serialize() []byte {
+	// serialization: client pubkey (33) + asset ID (4) + bond version (2) +
+	// coin ID (variable, ~36) = 75
+	b := make([]byte, 0, 75)
+	b = append(b, pb.AcctPubKey...)
+	b = append(b, uint32Bytes(pb.AssetID)...)
+	b = append(b, uint16Bytes(pb.Version)...)
+	return append(b, pb.CoinID...)
+}
+
+// PostBondResult is the response to the client's PostBond request. The
+// account ID is included so that a client creating a new account with a bond
+// may verify that the server computed the same ID.
+type PostBondResult struct {
+	Signature
+	AccountID Bytes  `json:"accountID"`
+	AssetID   uint32 `json:"assetID"`
+	Amount    uint64 `json:"amount"`
+	Expiry    uint64 `json:"expiry"` // not the lock time, but when the bond expires for the DEX
+	BondID    Bytes  `json:"bondID"`
+	Tier      int64  `json:"tier"`
+}
+
+// Serialize serializes the PostBondResult data.
+func (pbr *PostBondResult) Serialize() []byte {
+	// serialization: account id (32) + asset id (4) + amount (8) + expiry (8)
+	// + coin ID (variable, ~36) + tier (8) = 96
+	b := make([]byte, 0, 96)
+	b = append(b, pbr.AccountID...)
+	b = append(b, uint32Bytes(pbr.AssetID)...)
+	b = append(b, uint64Bytes(pbr.Amount)...)
+	b = append(b, uint64Bytes(pbr.Expiry)...)
+	b = append(b, pbr.BondID...)
+	return append(b, uint64Bytes(uint64(pbr.Tier))...)
+}
+
+// BondExpiredNotification is a notification from a server when a bond
+// expires and no longer counts toward the account's tier.
+type BondExpiredNotification struct {
+	Signature
+	AccountID Bytes  `json:"accountID"`
+	AssetID   uint32 `json:"assetID"`
+	BondID    Bytes  `json:"bondID"`
+	Tier      int64  `json:"tier"`
+}
+
+// Serialize serializes the BondExpiredNotification data.
+func (bc *BondExpiredNotification) Serialize() []byte {
+	// serialization: account id (32) + asset id (4) + coin ID (variable, ~36)
+	// + tier (8) = 80
+	b := make([]byte, 0, 80)
+	b = append(b, bc.AccountID...)
+	b = append(b, uint32Bytes(bc.AssetID)...)
+	b = append(b, bc.BondID...)
+	return append(b, uint64Bytes(uint64(bc.Tier))...)
+}
+
 // MarketStatus describes the status of the market, where StartEpoch is when the
 // market started or will start. FinalEpoch is a when the market will suspend
 // if it is running, or when the market suspended if it is presently stopped.
@@ -1075,6 +1222,15 @@ type FeeAsset struct {
 	Amt   uint64 `json:"amount"`
 }
 
+// BondAsset describes an asset for which fidelity bonds are supported. Amt is
+// the amount of a single bond increment, i.e. one tier.
+type BondAsset struct {
+	Version uint16 `json:"version"` // latest version supported
+	ID      uint32 `json:"id"`
+	Confs   uint32 `json:"confs"`
+	Amt     uint64 `json:"amount"`
+}
+
 // ConfigResult is the successful result for the ConfigRoute.
 type ConfigResult struct {
 	CancelMax        float64   `json:"cancelmax"`
@@ -1088,6 +1244,12 @@ type ConfigResult struct {
 	DEXPubKey        Bytes     `json:"pubkey"`
 
 	RegFees map[string]*FeeAsset `json:"regFees"`
+
+	// BondAssets and BondExpiry are only set by servers that accept fidelity
+	// bonds. BondExpiry is the number of seconds prior to a bond's lock time
+	// at which the bond is considered expired by the DEX.
+	BondAssets map[string]*BondAsset `json:"bondAssets,omitempty"`
+	BondExpiry uint64                `json:"bondExpiry,omitempty"`
 }
 
 // Spot is a snapshot of a market at the end of a match cycle. A slice of Spot
@@ -1110,6 +1272,99 @@ type CandlesRequest struct {
 	NumCandles int    `json:"numCandles,omitempty"` // default and max defined in apidata.
 }
 
+// TradesRequest is a data API request for a page of a market's recent trades.
+// The next page starts after the trade with ID Before, which is the last trade
+// of the previous page. The first page is requested with no Before.
+type TradesRequest struct {
+	BaseID  uint32 `json:"baseID"`
+	QuoteID uint32 `json:"quoteID"`
+	N       int    `json:"n,omitempty"` // default and max defined in apidata.
+	Before  Bytes  `json:"before,omitempty"`
+}
+
+// ExecutedTrade is an executed match. A slice of ExecutedTrade, most recent
+// first, is sent as the response to the TradesRoute request.
+type ExecutedTrade struct {
+	// ID identifies the trade, and may be used as TradesRequest.Before.
+	ID Bytes `json:"id"`
+	// Stamp is the end of the epoch in which the match was made, in
+	// milliseconds.
+	Stamp uint64 `json:"stamp"`
+	Rate  uint64 `json:"rate"`
+	Qty   uint64 `json:"qty"`
+	// Side is the taker's side, "buy" or "sell".
+	Side string `json:"side"`
+}
+
+// Ticker is a summary of a market's trading activity over the last 24 hours.
+// A slice of Ticker is sent as the response to the TickerRoute request.
+type Ticker struct {
+	Market   string  `json:"market"`
+	BaseID   uint32  `json:"baseID"`
+	QuoteID  uint32  `json:"quoteID"`
+	Rate     uint64  `json:"rate"`
+	High24   uint64  `json:"high24"`
+	Low24    uint64  `json:"low24"`
+	Vol24    uint64  `json:"vol24"`
+	Change24 float64 `json:"change24"`
+}
+
+// EpochTranscriptRequest is a data API request for the transcript of a
+// market's epoch.
+type EpochTranscriptRequest struct {
+	BaseID  uint32 `json:"baseID"`
+	QuoteID uint32 `json:"quoteID"`
+	Epoch   uint64 `json:"epoch"`
+	// Duration is the epoch duration in milliseconds. The market's current
+	// epoch duration is used if Duration is zero.
+	Duration uint64 `json:"duration,omitempty"`
+}
+
+// EpochTranscript is the record of an epoch's order matching, and is the
+// response to the EpochTranscriptRoute request. Queue is in the shuffled order
+// in which the orders were matched, and Preimages are the preimages of the
+// orders in Queue. Misses are the orders with unrevealed preimages. Makers are
+// the book orders considered by the matcher, in their state prior to matching.
+type EpochTranscript struct {
+	MarketID  string             `json:"marketid"`
+	Epoch     uint64             `json:"epoch"`
+	Duration  uint64             `json:"duration"`
+	LotSize   uint64             `json:"lotsize"`
+	CSum      Bytes              `json:"csum"`
+	Seed      Bytes              `json:"seed"`
+	Queue     []*TranscriptOrder `json:"queue"`
+	Preimages []Bytes            `json:"preimages"`
+	Misses    []*TranscriptOrder `json:"misses"`
+	Makers    []*TranscriptOrder `json:"makers"`
+	Matches   []*TranscriptMatch `json:"matches"`
+}
+
+// TranscriptOrder is the public data of an order in an EpochTranscript.
+type TranscriptOrder struct {
+	OrderID   Bytes  `json:"oid"`
+	Commit    Bytes  `json:"com"`
+	OrderType uint8  `json:"otype"`
+	Side      uint8  `json:"side,omitempty"` // omit for cancel orders
+	Quantity  uint64 `json:"qty,omitempty"`
+	Rate      uint64 `json:"rate,omitempty"`
+	TiF       uint8  `json:"tif,omitempty"`
+	Filled    uint64 `json:"filled,omitempty"`
+	// Stamp is the order's time priority in milliseconds.
+	Stamp      uint64 `json:"stamp"`
+	DisplayQty uint64 `json:"displayqty,omitempty"`
+	// TargetID is the order targeted by a cancel order, or replaced by a
+	// replacement order.
+	TargetID Bytes `json:"target,omitempty"`
+}
+
+// TranscriptMatch is a match in an EpochTranscript.
+type TranscriptMatch struct {
+	TakerID  Bytes  `json:"taker"`
+	MakerID  Bytes  `json:"maker"`
+	Quantity uint64 `json:"qty"`
+	Rate     uint64 `json:"rate"`
+}
+
 // Candle is a statistical history of a specified period of market activity.
 type Candle struct {
 	StartStamp  uint64 `json:"startStamp"`
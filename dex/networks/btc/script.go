@@ -9,6 +9,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"math"
 
 	"decred.org/dcrdex/dex"
 	"github.com/btcsuite/btcd/btcutil"
@@ -818,3 +819,185 @@ func ExtractScriptHash(script []byte) []byte {
 	}
 	return nil
 }
+
+// Fidelity bonds are time-locked outputs that pay to a P2SH or P2WSH bond
+// script. The bond script can only be spent by the owner of the bond's private
+// key after the lock time has passed. A companion nulldata output commits to
+// the DEX account ID so that the bond cannot be claimed by another account.
+
+const (
+	// BondScriptSize is the size of a version 0 fidelity bond script. It is
+	// calculated as:
+	//
+	//   - OP_DATA_4
+	//   - 4 bytes lockTime
+	//   - OP_CHECKLOCKTIMEVERIFY
+	//   - OP_DROP
+	//   - OP_DUP
+	//   - OP_HASH160
+	//   - OP_DATA_20
+	//   - 20 bytes pubkey hash
+	//   - OP_EQUALVERIFY
+	//   - OP_CHECKSIG
+	BondScriptSize = 1 + 4 + 1 + 1 + 1 + 1 + 1 + 20 + 1 + 1 // 32
+
+	// RefundBondSigScriptSize is the worst case (largest) serialize size of a
+	// transaction input script that refunds a P2SH bond output. It is
+	// calculated as:
+	//
+	//   - OP_DATA_73
+	//   - 72 bytes DER signature + 1 byte sighash
+	//   - OP_DATA_33
+	//   - 33 bytes serialized compressed pubkey
+	//   - OP_DATA_32
+	//   - 32 bytes bond script
+	RefundBondSigScriptSize = 1 + DERSigLength + 1 + 33 + 1 + BondScriptSize // 141
+
+	// RefundBondWitnessWeight is the worst case weight of a witness for
+	// refunding a P2WSH bond output. It is calculated as:
+	//
+	//   - 1 wu compact int encoding value 3 (number of items)
+	//   - 1 wu compact int encoding value 73
+	//   - 72 wu DER signature + 1 wu sighash
+	//   - 1 wu compact int encoding value 33
+	//   - 33 wu serialized compressed pubkey
+	//   - 1 wu compact int encoding value 32
+	//   - 32 wu bond script
+	RefundBondWitnessWeight = 1 + 1 + DERSigLength + 1 + 33 + 1 + BondScriptSize // 142
+
+	// BondPushDataSize is the size of the nulldata in a bond commitment output:
+	//
+	//   - 2 bytes version
+	//   - 32 bytes account ID
+	//   - 4 bytes lockTime
+	//   - 20 bytes pubkey hash
+	BondPushDataSize = 2 + 32 + 4 + 20 // 58
+
+	// BondVersion is the current (and only) supported bond script version.
+	BondVersion = 0
+
+	bondAcctIDSize = 32
+)
+
+// MakeBondScript constructs a versioned bond output script for the provided
+// lock time and pubkey hash. Only version 0 is supported at present. The lock
+// time must be a UNIX timestamp, not a block height.
+func MakeBondScript(ver uint16, lockTime uint32, pkh []byte) ([]byte, error) {
+	if ver != BondVersion {
+		return nil, fmt.Errorf("unsupported bond script version %d", ver)
+	}
+	if len(pkh) != 20 {
+		return nil, fmt.Errorf("invalid pubkey hash length %d", len(pkh))
+	}
+	if lockTime < txscript.LockTimeThreshold || lockTime > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid bond lock time %d", lockTime)
+	}
+	return txscript.NewScriptBuilder().
+		AddInt64(int64(lockTime)).
+		AddOps([]byte{
+			txscript.OP_CHECKLOCKTIMEVERIFY,
+			txscript.OP_DROP,
+			txscript.OP_DUP,
+			txscript.OP_HASH160,
+		}).AddData(pkh).
+		AddOps([]byte{
+			txscript.OP_EQUALVERIFY,
+			txscript.OP_CHECKSIG,
+		}).Script()
+}
+
+// RefundBondScript returns the signature script to refund a bond output using
+// the bond owner's signature after the lock time has been reached. This
+// function assumes P2SH and appends the bond script as the final data push.
+func RefundBondScript(bondScript, sig, pubkey []byte) ([]byte, error) {
+	return txscript.NewScriptBuilder().
+		AddData(sig).
+		AddData(pubkey).
+		AddData(bondScript).
+		Script()
+}
+
+// RefundBondScriptSegwit returns the witness to refund a P2WSH bond output
+// using the bond owner's signature after the lock time has been reached.
+func RefundBondScriptSegwit(bondScript, sig, pubkey []byte) [][]byte {
+	return [][]byte{
+		sig,
+		pubkey,
+		bondScript,
+	}
+}
+
+// ExtractBondDetailsV0 validates the provided bond script and returns the lock
+// time and pubkey hash to which it commits.
+func ExtractBondDetailsV0(bondScript []byte) (lockTime uint32, pkh []byte, err error) {
+	if len(bondScript) != BondScriptSize {
+		return 0, nil, fmt.Errorf("incorrect bond script length. expected %d, got %d",
+			BondScriptSize, len(bondScript))
+	}
+	if bondScript[0] == txscript.OP_DATA_4 &&
+		// lockTime (4 bytes)
+		bondScript[5] == txscript.OP_CHECKLOCKTIMEVERIFY &&
+		bondScript[6] == txscript.OP_DROP &&
+		bondScript[7] == txscript.OP_DUP &&
+		bondScript[8] == txscript.OP_HASH160 &&
+		bondScript[9] == txscript.OP_DATA_20 &&
+		// pubkey hash (20 bytes)
+		bondScript[30] == txscript.OP_EQUALVERIFY &&
+		bondScript[31] == txscript.OP_CHECKSIG {
+		lockTime = binary.LittleEndian.Uint32(bondScript[1:5])
+		if lockTime < txscript.LockTimeThreshold || lockTime > math.MaxInt32 {
+			return 0, nil, fmt.Errorf("invalid bond lock time %d", lockTime)
+		}
+		pkh = make([]byte, 20)
+		copy(pkh, bondScript[10:30])
+		return lockTime, pkh, nil
+	}
+	return 0, nil, fmt.Errorf("invalid bond script")
+}
+
+// BondPushData creates the data pushed by the nulldata output of a bond
+// transaction, committing the bond to an account ID.
+func BondPushData(ver uint16, acctID []byte, lockTime uint32, pkh []byte) []byte {
+	pushData := make([]byte, BondPushDataSize)
+	binary.BigEndian.PutUint16(pushData[:2], ver)
+	copy(pushData[2:34], acctID)
+	binary.BigEndian.PutUint32(pushData[34:38], lockTime)
+	copy(pushData[38:], pkh)
+	return pushData
+}
+
+// MakeBondCommitScript creates the nulldata output script that commits a bond
+// to an account ID.
+func MakeBondCommitScript(ver uint16, acctID []byte, lockTime uint32, pkh []byte) ([]byte, error) {
+	if len(acctID) != bondAcctIDSize {
+		return nil, fmt.Errorf("invalid account ID length %d", len(acctID))
+	}
+	if len(pkh) != 20 {
+		return nil, fmt.Errorf("invalid pubkey hash length %d", len(pkh))
+	}
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).
+		AddData(BondPushData(ver, acctID, lockTime, pkh)).
+		Script()
+}
+
+// ExtractBondCommitDataV0 parses a version 0 bond commitment output script,
+// returning the bond version, account ID, lock time, and pubkey hash.
+func ExtractBondCommitDataV0(pkScript []byte) (ver uint16, acctID [32]byte, lockTime uint32, pkh [20]byte, err error) {
+	// OP_RETURN OP_DATA_58 <pushData>
+	if len(pkScript) != 2+BondPushDataSize || pkScript[0] != txscript.OP_RETURN ||
+		pkScript[1] != BondPushDataSize {
+		err = fmt.Errorf("not a bond commitment script")
+		return
+	}
+	pushData := pkScript[2:]
+	ver = binary.BigEndian.Uint16(pushData[:2])
+	if ver != BondVersion {
+		err = fmt.Errorf("unsupported bond version %d", ver)
+		return
+	}
+	copy(acctID[:], pushData[2:34])
+	lockTime = binary.BigEndian.Uint32(pushData[34:38])
+	copy(pkh[:], pushData[38:])
+	return
+}
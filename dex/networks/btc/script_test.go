@@ -621,3 +621,113 @@ func TestMsgTxVBytes(t *testing.T) {
 		t.Errorf("wanted tx virtual size %d, got %d", wantVSize, gotVSize)
 	}
 }
+
+func TestBondScripts(t *testing.T) {
+	priv, _ := btcec.NewPrivateKey()
+	pubKey := priv.PubKey().SerializeCompressed()
+	pkh := btcutil.Hash160(pubKey)
+	lockTime := uint32(1650000000)
+
+	bondScript, err := MakeBondScript(BondVersion, lockTime, pkh)
+	if err != nil {
+		t.Fatalf("MakeBondScript error: %v", err)
+	}
+	if len(bondScript) != BondScriptSize {
+		t.Fatalf("wrong bond script size. wanted %d, got %d", BondScriptSize, len(bondScript))
+	}
+
+	lt, h, err := ExtractBondDetailsV0(bondScript)
+	if err != nil {
+		t.Fatalf("ExtractBondDetailsV0 error: %v", err)
+	}
+	if lt != lockTime {
+		t.Fatalf("wrong lock time. wanted %d, got %d", lockTime, lt)
+	}
+	if !bytes.Equal(h, pkh) {
+		t.Fatalf("wrong pubkey hash")
+	}
+
+	if _, err = MakeBondScript(1, lockTime, pkh); err == nil {
+		t.Fatalf("no error for unknown bond version")
+	}
+	if _, err = MakeBondScript(BondVersion, 1000, pkh); err == nil {
+		t.Fatalf("no error for block height lock time")
+	}
+	badScript := append([]byte{}, bondScript...)
+	badScript[6] = txscript.OP_NOP
+	if _, _, err = ExtractBondDetailsV0(badScript); err == nil {
+		t.Fatalf("no error for bad bond script")
+	}
+
+	acctID := randBytes(32)
+	commitScript, err := MakeBondCommitScript(BondVersion, acctID, lockTime, pkh)
+	if err != nil {
+		t.Fatalf("MakeBondCommitScript error: %v", err)
+	}
+	if txscript.GetScriptClass(commitScript) != txscript.NullDataTy {
+		t.Fatalf("commitment script is not a nulldata script")
+	}
+	ver, acct, lt, pkh20, err := ExtractBondCommitDataV0(commitScript)
+	if err != nil {
+		t.Fatalf("ExtractBondCommitDataV0 error: %v", err)
+	}
+	if ver != BondVersion || lt != lockTime || !bytes.Equal(acct[:], acctID) || !bytes.Equal(pkh20[:], pkh) {
+		t.Fatalf("wrong bond commitment data")
+	}
+
+	// Spend P2SH and P2WSH bond outputs with the script engine.
+	const bondAmt = 1e8
+	spend := func(segwit bool, txLockTime uint32) error {
+		var pkScript []byte
+		if segwit {
+			addr, _ := btcutil.NewAddressWitnessScriptHash(sha256Hash(bondScript), tParams)
+			pkScript, _ = txscript.PayToAddrScript(addr)
+		} else {
+			addr, _ := btcutil.NewAddressScriptHash(bondScript, tParams)
+			pkScript, _ = txscript.PayToAddrScript(addr)
+		}
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.LockTime = txLockTime
+		txIn := wire.NewTxIn(&wire.OutPoint{}, nil, nil)
+		txIn.Sequence = wire.MaxTxInSequenceNum - 1
+		tx.AddTxIn(txIn)
+		tx.AddTxOut(wire.NewTxOut(bondAmt-1000, pkScript))
+		prevOuts := txscript.NewCannedPrevOutputFetcher(pkScript, bondAmt)
+		sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+		if segwit {
+			sig, err := txscript.RawTxInWitnessSignature(tx, sigHashes, 0, bondAmt, bondScript, txscript.SigHashAll, priv)
+			if err != nil {
+				return err
+			}
+			txIn.Witness = RefundBondScriptSegwit(bondScript, sig, pubKey)
+		} else {
+			sig, err := txscript.RawTxInSignature(tx, 0, bondScript, txscript.SigHashAll, priv)
+			if err != nil {
+				return err
+			}
+			txIn.SignatureScript, err = RefundBondScript(bondScript, sig, pubKey)
+			if err != nil {
+				return err
+			}
+		}
+		vm, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags,
+			nil, sigHashes, bondAmt, prevOuts)
+		if err != nil {
+			return err
+		}
+		return vm.Execute()
+	}
+	for _, segwit := range []bool{true, false} {
+		if err := spend(segwit, lockTime); err != nil {
+			t.Fatalf("error refunding bond (segwit = %t): %v", segwit, err)
+		}
+		if err := spend(segwit, lockTime-1); err == nil {
+			t.Fatalf("no error refunding bond before lock time (segwit = %t)", segwit)
+		}
+	}
+}
+
+func sha256Hash(b []byte) []byte {
+	h := sha256.Sum256(b)
+	return h[:]
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/txscript/v4"
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"github.com/decred/dcrd/txscript/v4/stdscript"
 	"github.com/decred/dcrd/wire"
 )
 
@@ -612,3 +613,65 @@ func TestDataPrefixSize(t *testing.T) {
 		})
 	}
 }
+
+func TestBondScripts(t *testing.T) {
+	pkh := randBytes(20)
+	lockTime := uint32(1650000000)
+
+	bondScript, err := MakeBondScript(BondVersion, lockTime, pkh)
+	if err != nil {
+		t.Fatalf("MakeBondScript error: %v", err)
+	}
+	if len(bondScript) != BondScriptSize {
+		t.Fatalf("wrong bond script size. wanted %d, got %d", BondScriptSize, len(bondScript))
+	}
+
+	lt, h, err := ExtractBondDetailsV0(0, bondScript)
+	if err != nil {
+		t.Fatalf("ExtractBondDetailsV0 error: %v", err)
+	}
+	if lt != lockTime {
+		t.Fatalf("wrong lock time. wanted %d, got %d", lockTime, lt)
+	}
+	if !bytes.Equal(h, pkh) {
+		t.Fatalf("wrong pubkey hash")
+	}
+
+	if _, err = MakeBondScript(1, lockTime, pkh); err == nil {
+		t.Fatalf("no error for unknown bond version")
+	}
+	if _, err = MakeBondScript(BondVersion, 1000, pkh); err == nil {
+		t.Fatalf("no error for block height lock time")
+	}
+	if _, err = MakeBondScript(BondVersion, lockTime, pkh[1:]); err == nil {
+		t.Fatalf("no error for short pubkey hash")
+	}
+
+	badScript := append([]byte{}, bondScript...)
+	badScript[5] = txscript.OP_CHECKSEQUENCEVERIFY
+	if _, _, err = ExtractBondDetailsV0(0, badScript); err == nil {
+		t.Fatalf("no error for bad bond script")
+	}
+	if _, _, err = ExtractBondDetailsV0(0, bondScript[1:]); err == nil {
+		t.Fatalf("no error for short bond script")
+	}
+
+	acctID := randBytes(32)
+	commitScript, err := MakeBondCommitScript(BondVersion, acctID, lockTime, pkh)
+	if err != nil {
+		t.Fatalf("MakeBondCommitScript error: %v", err)
+	}
+	if !stdscript.IsNullDataScript(0, commitScript) {
+		t.Fatalf("commitment script is not a nulldata script")
+	}
+	ver, acct, lt, pkh20, err := ExtractBondCommitDataV0(0, commitScript)
+	if err != nil {
+		t.Fatalf("ExtractBondCommitDataV0 error: %v", err)
+	}
+	if ver != BondVersion || lt != lockTime || !bytes.Equal(acct[:], acctID) || !bytes.Equal(pkh20[:], pkh) {
+		t.Fatalf("wrong bond commitment data")
+	}
+	if _, _, _, _, err = ExtractBondCommitDataV0(0, bondScript); err == nil {
+		t.Fatalf("no error for non-commitment script")
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"decred.org/dcrdex/dex/calc"
 	"decred.org/dcrdex/server/account"
 	"github.com/decred/dcrd/crypto/blake256"
 )
@@ -147,12 +148,15 @@ func (ot OrderType) String() string {
 type TimeInForce uint8
 
 // The TimeInForce is either ImmediateTiF, which prevents the order from
-// becoming a standing order if there is no match during epoch processing, or
+// becoming a standing order if there is no match during epoch processing,
 // StandingTiF, which allows limit orders to enter the order book if not
-// immediately matched during epoch processing.
+// immediately matched during epoch processing, or GoodTilTimeTiF, which is
+// like StandingTiF except that the order is removed from the book when its
+// expiry epoch closes.
 const (
 	ImmediateTiF TimeInForce = iota
 	StandingTiF
+	GoodTilTimeTiF
 )
 
 // String satisfies the Stringer interface.
@@ -162,6 +166,8 @@ func (t TimeInForce) String() string {
 		return "immediate"
 	case StandingTiF:
 		return "standing"
+	case GoodTilTimeTiF:
+		return "good-til-time"
 	}
 	return fmt.Sprintf("unknown (%d)", t)
 }
@@ -625,6 +631,20 @@ type LimitOrder struct {
 	T
 	Rate  uint64 // price as atoms of quote asset, applied per 1e8 units of the base asset
 	Force TimeInForce
+	// ExpiryEpoch is the index of the last epoch that a GoodTilTimeTiF order
+	// may remain on the book. The order is unbooked when this epoch closes.
+	// ExpiryEpoch is zero for other time in force values.
+	ExpiryEpoch uint64
+	// DisplayQty is the quantity shown on the book for an iceberg order. The
+	// remainder of the order is held in a hidden reserve that replenishes the
+	// displayed quantity after each fill. DisplayQty is zero for orders that
+	// show their full size.
+	DisplayQty uint64
+	// Replaces is the ID of a standing limit order that this order replaces.
+	// A replacement order reuses the funding coins of the order it replaces,
+	// and the replaced order is unbooked when the replacement's epoch is
+	// matched. Replaces is the zero OrderID for other orders.
+	Replaces OrderID
 }
 
 // ID computes the order ID.
@@ -649,7 +669,17 @@ func (o *LimitOrder) String() string {
 
 // serializeSize returns the length of the serialized LimitOrder.
 func (o *LimitOrder) serializeSize() int {
-	return o.P.serializeSize() + o.T.serializeSize() + 8 + 1
+	sz := o.P.serializeSize() + o.T.serializeSize() + 8 + 1
+	if o.Force == GoodTilTimeTiF {
+		sz += 8
+	}
+	if o.DisplayQty > 0 {
+		sz += 8
+	}
+	if o.IsReplacement() {
+		sz += OrderIDSize
+	}
+	return sz
 }
 
 // Serialize marshals the LimitOrder into a []byte.
@@ -668,6 +698,25 @@ func (o *LimitOrder) Serialize() []byte {
 
 	// Time in force
 	b[offset] = uint8(o.Force)
+	offset++
+
+	// Expiry epoch index, only for good-til-time orders so that the
+	// serialization of other orders is unchanged.
+	if o.Force == GoodTilTimeTiF {
+		binary.BigEndian.PutUint64(b[offset:offset+8], o.ExpiryEpoch)
+		offset += 8
+	}
+
+	// Display quantity, only for iceberg orders.
+	if o.DisplayQty > 0 {
+		binary.BigEndian.PutUint64(b[offset:offset+8], o.DisplayQty)
+		offset += 8
+	}
+
+	// Replaced order ID, only for replacement orders.
+	if o.IsReplacement() {
+		copy(b[offset:offset+OrderIDSize], o.Replaces[:])
+	}
 	return b
 }
 
@@ -679,6 +728,34 @@ func (o *LimitOrder) Price() uint64 {
 	return o.Rate
 }
 
+// Expired checks whether a good-til-time order should be removed from the book
+// after the close of the epoch with the specified index.
+func (o *LimitOrder) Expired(closedEpochIdx uint64) bool {
+	return o.Force == GoodTilTimeTiF && closedEpochIdx >= o.ExpiryEpoch
+}
+
+// Visible is the quantity of the order that is displayed on the book. For an
+// iceberg order, this is the lesser of the display quantity and the remaining
+// quantity. For other orders, it is the remaining quantity.
+func (o *LimitOrder) Visible() uint64 {
+	rem := o.Remaining()
+	if o.DisplayQty > 0 && o.DisplayQty < rem {
+		return o.DisplayQty
+	}
+	return rem
+}
+
+// Hidden is the quantity of the order that is held in reserve, not displayed
+// on the book.
+func (o *LimitOrder) Hidden() uint64 {
+	return o.Remaining() - o.Visible()
+}
+
+// IsReplacement is true if the order replaces another standing limit order.
+func (o *LimitOrder) IsReplacement() bool {
+	return !o.Replaces.IsZero()
+}
+
 // CancelOrder defines a cancel order in terms of an order Prefix and the ID of
 // the order to be canceled.
 type CancelOrder struct {
@@ -770,7 +847,7 @@ func ValidateOrder(ord Order, status OrderStatus, lotSize uint64) error {
 
 	case *LimitOrder:
 		// Limit order OK statuses: epoch, booked, executed, and canceled (same
-		// as market plus booked).
+		// as market plus booked), and expired for good-til-time orders.
 		switch status {
 		case OrderStatusEpoch, OrderStatusExecuted, OrderStatusRevoked:
 		case OrderStatusBooked, OrderStatusCanceled:
@@ -779,10 +856,27 @@ func ValidateOrder(ord Order, status OrderStatus, lotSize uint64) error {
 			if ot.Force == ImmediateTiF {
 				return fmt.Errorf("invalid immediate limit order status %d -> %s", status, status)
 			}
+		case OrderStatusExpired:
+			if ot.Force != GoodTilTimeTiF {
+				return fmt.Errorf("invalid %s limit order status %d -> %s", ot.Force, status, status)
+			}
 		default:
 			return fmt.Errorf("invalid limit order status %d -> %s", status, status)
 		}
 
+		switch ot.Force {
+		case ImmediateTiF, StandingTiF:
+			if ot.ExpiryEpoch != 0 {
+				return fmt.Errorf("%s limit order has an expiry epoch", ot.Force)
+			}
+		case GoodTilTimeTiF:
+			if ot.ExpiryEpoch == 0 {
+				return fmt.Errorf("good-til-time limit order has no expiry epoch")
+			}
+		default:
+			return fmt.Errorf("unknown time in force %d", ot.Force)
+		}
+
 		if ot.OrderType != LimitOrderType {
 			return fmt.Errorf("limit order has wrong order type %d -> %s", ot.OrderType, ot.OrderType)
 		}
@@ -791,6 +885,25 @@ func ValidateOrder(ord Order, status OrderStatus, lotSize uint64) error {
 		if ot.Quantity%lotSize != 0 || ot.Remaining()%lotSize != 0 {
 			return fmt.Errorf("limit order fails lot size requirement %d %% %d = %d", ot.Quantity, lotSize, ot.Quantity%lotSize)
 		}
+
+		// An iceberg order's display quantity must be a nonzero number of lots
+		// smaller than the order quantity. Immediate orders are never booked.
+		if ot.DisplayQty > 0 {
+			if ot.Force == ImmediateTiF {
+				return fmt.Errorf("immediate limit order has a display quantity")
+			}
+			if ot.DisplayQty%lotSize != 0 {
+				return fmt.Errorf("display quantity fails lot size requirement %d %% %d = %d", ot.DisplayQty, lotSize, ot.DisplayQty%lotSize)
+			}
+			if ot.DisplayQty >= ot.Quantity {
+				return fmt.Errorf("display quantity %d is not less than the order quantity %d", ot.DisplayQty, ot.Quantity)
+			}
+		}
+
+		// A replacement takes the place of a standing order on the book.
+		if ot.IsReplacement() && ot.Force == ImmediateTiF {
+			return fmt.Errorf("immediate limit order cannot be a replacement")
+		}
 	default:
 		// cannot validate an unknown order type
 		return fmt.Errorf("unknown order type")
@@ -799,6 +912,57 @@ func ValidateOrder(ord Order, status OrderStatus, lotSize uint64) error {
 	return nil
 }
 
+// ValidateReplacement checks that the replacement order may take the place of
+// the target order. The replacement must be from the same account, for the same
+// market and side, and be funded by the same coins with the same address. The
+// target must be unfilled, and the replacement may not require more funding
+// than the target, so that the target's validated and locked coins can be
+// reused without another funding check.
+func ValidateReplacement(lo, target *LimitOrder) error {
+	if lo.Replaces != target.ID() {
+		return fmt.Errorf("order %v does not replace %v", lo, target)
+	}
+	if lo.AccountID != target.AccountID {
+		return fmt.Errorf("replacement order account %v does not match target account %v",
+			lo.AccountID, target.AccountID)
+	}
+	if lo.BaseAsset != target.BaseAsset || lo.QuoteAsset != target.QuoteAsset {
+		return fmt.Errorf("replacement order market does not match the target order")
+	}
+	if lo.Sell != target.Sell {
+		return fmt.Errorf("replacement order side does not match the target order")
+	}
+	if lo.Address != target.Address {
+		return fmt.Errorf("replacement order address does not match the target order")
+	}
+	if len(lo.Coins) != len(target.Coins) {
+		return fmt.Errorf("replacement order has %d coins, target has %d", len(lo.Coins), len(target.Coins))
+	}
+	for i := range lo.Coins {
+		if string(lo.Coins[i]) != string(target.Coins[i]) {
+			return fmt.Errorf("replacement order coin %d does not match the target order", i)
+		}
+	}
+	if target.Force == ImmediateTiF {
+		return fmt.Errorf("target order %v is not a standing order", target)
+	}
+	if target.Filled() > 0 {
+		return fmt.Errorf("target order %v is partially filled", target)
+	}
+	if lo.Quantity > target.Quantity {
+		return fmt.Errorf("replacement order quantity %d exceeds the target order quantity %d",
+			lo.Quantity, target.Quantity)
+	}
+	// A buy order is funded in the quote asset.
+	if !lo.Sell {
+		if qty, targetQty := calc.BaseToQuote(lo.Rate, lo.Quantity), calc.BaseToQuote(target.Rate, target.Quantity); qty > targetQty {
+			return fmt.Errorf("replacement order quote quantity %d exceeds the target order quote quantity %d",
+				qty, targetQty)
+		}
+	}
+	return nil
+}
+
 // ExtractAddress extracts the address from the order. If the order is a cancel
 // order, an empty string is returned.
 func ExtractAddress(ord Order) string {
@@ -525,6 +525,272 @@ func TestLimitOrder_ID(t *testing.T) {
 	}
 }
 
+func TestLimitOrder_GoodTilTime(t *testing.T) {
+	newOrder := func(tif TimeInForce, expiry uint64) *LimitOrder {
+		return &LimitOrder{
+			P: Prefix{
+				AccountID:  acct0,
+				BaseAsset:  AssetDCR,
+				QuoteAsset: AssetBTC,
+				OrderType:  LimitOrderType,
+				ClientTime: time.Unix(1566497653, 0),
+				ServerTime: time.Unix(1566497656, 0),
+				Commit:     commit0,
+			},
+			T: Trade{
+				Coins: []CoinID{
+					utxoCoinID("01516d9c7ffbe260b811dc04462cedd3f8969ce3a3ffe6231ae870775a92e9b0", 1),
+				},
+				Quantity: 200,
+				Address:  "DcqXswjTPnUcd4FRCkX4vRJxmVtfgGVa5ui",
+			},
+			Rate:        13241324,
+			Force:       tif,
+			ExpiryEpoch: expiry,
+		}
+	}
+
+	// The serialization is the standing serialization with a different time in
+	// force and the expiry epoch appended.
+	standing := newOrder(StandingTiF, 0).Serialize()
+	gtt := newOrder(GoodTilTimeTiF, 0x0102030405060708)
+	want := append([]byte{}, standing...)
+	want[len(want)-1] = byte(GoodTilTimeTiF)
+	want = append(want, 1, 2, 3, 4, 5, 6, 7, 8)
+	if got := gtt.Serialize(); !bytes.Equal(got, want) {
+		t.Fatalf("LimitOrder.Serialize() = %x, want %x", got, want)
+	}
+	if gtt.serializeSize() != len(want) {
+		t.Fatalf("LimitOrder.serializeSize() = %d, want %d", gtt.serializeSize(), len(want))
+	}
+
+	ord, err := DecodeOrder(EncodeOrder(gtt))
+	if err != nil {
+		t.Fatalf("DecodeOrder error: %v", err)
+	}
+	lo, ok := ord.(*LimitOrder)
+	if !ok || lo.Force != GoodTilTimeTiF || lo.ExpiryEpoch != gtt.ExpiryEpoch || lo.ID() != gtt.ID() {
+		t.Fatalf("decoded good-til-time order does not match")
+	}
+	ord, err = DecodeOrder(EncodeOrder(newOrder(StandingTiF, 0)))
+	if err != nil {
+		t.Fatalf("DecodeOrder error: %v", err)
+	}
+	if lo = ord.(*LimitOrder); lo.Force != StandingTiF || lo.ExpiryEpoch != 0 {
+		t.Fatalf("decoded standing order does not match")
+	}
+
+	gtt = newOrder(GoodTilTimeTiF, 10)
+	if gtt.Expired(9) || !gtt.Expired(10) || !gtt.Expired(11) {
+		t.Fatalf("wrong expiry")
+	}
+	if newOrder(StandingTiF, 0).Expired(10) {
+		t.Fatalf("standing order expired")
+	}
+
+	for _, tt := range []struct {
+		name    string
+		lo      *LimitOrder
+		status  OrderStatus
+		wantErr bool
+	}{
+		{"booked gtt", newOrder(GoodTilTimeTiF, 10), OrderStatusBooked, false},
+		{"expired gtt", newOrder(GoodTilTimeTiF, 10), OrderStatusExpired, false},
+		{"expired standing", newOrder(StandingTiF, 0), OrderStatusExpired, true},
+		{"gtt without expiry", newOrder(GoodTilTimeTiF, 0), OrderStatusEpoch, true},
+		{"standing with expiry", newOrder(StandingTiF, 10), OrderStatusEpoch, true},
+	} {
+		err := ValidateOrder(tt.lo, tt.status, 100)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: wanted error = %t, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestLimitOrder_Iceberg(t *testing.T) {
+	newOrder := func(tif TimeInForce, expiry, displayQty uint64) *LimitOrder {
+		return &LimitOrder{
+			P: Prefix{
+				AccountID:  acct0,
+				BaseAsset:  AssetDCR,
+				QuoteAsset: AssetBTC,
+				OrderType:  LimitOrderType,
+				ClientTime: time.Unix(1566497653, 0),
+				ServerTime: time.Unix(1566497656, 0),
+				Commit:     commit0,
+			},
+			T: Trade{
+				Coins: []CoinID{
+					utxoCoinID("01516d9c7ffbe260b811dc04462cedd3f8969ce3a3ffe6231ae870775a92e9b0", 1),
+				},
+				Quantity: 500,
+				Address:  "DcqXswjTPnUcd4FRCkX4vRJxmVtfgGVa5ui",
+			},
+			Rate:        13241324,
+			Force:       tif,
+			ExpiryEpoch: expiry,
+			DisplayQty:  displayQty,
+		}
+	}
+
+	// The display quantity is appended to the serialization.
+	standing := newOrder(StandingTiF, 0, 0).Serialize()
+	iceberg := newOrder(StandingTiF, 0, 0x0102030405060708)
+	want := append(append([]byte{}, standing...), 1, 2, 3, 4, 5, 6, 7, 8)
+	if got := iceberg.Serialize(); !bytes.Equal(got, want) {
+		t.Fatalf("LimitOrder.Serialize() = %x, want %x", got, want)
+	}
+	if iceberg.serializeSize() != len(want) {
+		t.Fatalf("LimitOrder.serializeSize() = %d, want %d", iceberg.serializeSize(), len(want))
+	}
+
+	for _, lo := range []*LimitOrder{newOrder(StandingTiF, 0, 200), newOrder(GoodTilTimeTiF, 10, 200)} {
+		ord, err := DecodeOrder(EncodeOrder(lo))
+		if err != nil {
+			t.Fatalf("DecodeOrder error: %v", err)
+		}
+		dec, ok := ord.(*LimitOrder)
+		if !ok || dec.Force != lo.Force || dec.ExpiryEpoch != lo.ExpiryEpoch ||
+			dec.DisplayQty != lo.DisplayQty || dec.ID() != lo.ID() {
+			t.Fatalf("decoded %s iceberg order does not match", lo.Force)
+		}
+	}
+
+	lo := newOrder(StandingTiF, 0, 200)
+	if lo.Visible() != 200 || lo.Hidden() != 300 {
+		t.Fatalf("wrong visible/hidden amounts %d/%d", lo.Visible(), lo.Hidden())
+	}
+	lo.FillAmt = 400
+	if lo.Visible() != 100 || lo.Hidden() != 0 {
+		t.Fatalf("wrong visible/hidden amounts after fill %d/%d", lo.Visible(), lo.Hidden())
+	}
+	lo = newOrder(StandingTiF, 0, 0)
+	if lo.Visible() != 500 || lo.Hidden() != 0 {
+		t.Fatalf("wrong visible/hidden amounts for non-iceberg order")
+	}
+
+	for _, tt := range []struct {
+		name    string
+		lo      *LimitOrder
+		wantErr bool
+	}{
+		{"standing iceberg", newOrder(StandingTiF, 0, 200), false},
+		{"gtt iceberg", newOrder(GoodTilTimeTiF, 10, 100), false},
+		{"immediate iceberg", newOrder(ImmediateTiF, 0, 200), true},
+		{"display not lot multiple", newOrder(StandingTiF, 0, 150), true},
+		{"display not less than quantity", newOrder(StandingTiF, 0, 500), true},
+	} {
+		err := ValidateOrder(tt.lo, OrderStatusEpoch, 100)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: wanted error = %t, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestLimitOrder_Replacement(t *testing.T) {
+	newOrder := func(sell bool, qty, rate uint64) *LimitOrder {
+		return &LimitOrder{
+			P: Prefix{
+				AccountID:  acct0,
+				BaseAsset:  AssetDCR,
+				QuoteAsset: AssetBTC,
+				OrderType:  LimitOrderType,
+				ClientTime: time.Unix(1566497653, 0),
+				ServerTime: time.Unix(1566497656, 0),
+				Commit:     commit0,
+			},
+			T: Trade{
+				Coins: []CoinID{
+					utxoCoinID("01516d9c7ffbe260b811dc04462cedd3f8969ce3a3ffe6231ae870775a92e9b0", 1),
+				},
+				Sell:     sell,
+				Quantity: qty,
+				Address:  "DcqXswjTPnUcd4FRCkX4vRJxmVtfgGVa5ui",
+			},
+			Rate:  rate,
+			Force: StandingTiF,
+		}
+	}
+
+	target := newOrder(true, 500, 1e8)
+	newReplacement := func() *LimitOrder {
+		lo := newOrder(true, 400, 2e8)
+		lo.Commit = Commitment{0x01}
+		lo.Replaces = target.ID()
+		return lo
+	}
+	lo := newReplacement()
+	if !lo.IsReplacement() || target.IsReplacement() {
+		t.Fatalf("wrong IsReplacement")
+	}
+
+	// The replaced order ID is appended to the serialization.
+	plain := newReplacement()
+	plain.Replaces = OrderID{}
+	want := append(plain.Serialize(), lo.Replaces[:]...)
+	if got := lo.Serialize(); !bytes.Equal(got, want) {
+		t.Fatalf("LimitOrder.Serialize() = %x, want %x", got, want)
+	}
+
+	iceberg := newReplacement()
+	iceberg.DisplayQty = 200
+	for _, lo := range []*LimitOrder{lo, iceberg} {
+		ord, err := DecodeOrder(EncodeOrder(lo))
+		if err != nil {
+			t.Fatalf("DecodeOrder error: %v", err)
+		}
+		dec, ok := ord.(*LimitOrder)
+		if !ok || dec.Replaces != lo.Replaces || dec.DisplayQty != lo.DisplayQty || dec.ID() != lo.ID() {
+			t.Fatalf("decoded replacement order does not match")
+		}
+	}
+
+	immediate := newReplacement()
+	immediate.Force = ImmediateTiF
+	if err := ValidateOrder(immediate, OrderStatusEpoch, 100); err == nil {
+		t.Fatalf("no error for immediate replacement order")
+	}
+
+	if err := ValidateReplacement(lo, target); err != nil {
+		t.Fatalf("ValidateReplacement error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		modify func(lo, target *LimitOrder)
+	}{
+		{"wrong target", func(lo, target *LimitOrder) { lo.Replaces = OrderID{0x01} }},
+		{"wrong side", func(lo, target *LimitOrder) { lo.Sell = false }},
+		{"wrong address", func(lo, target *LimitOrder) { lo.Address = "Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx" }},
+		{"wrong coins", func(lo, target *LimitOrder) {
+			lo.Coins = []CoinID{utxoCoinID("01516d9c7ffbe260b811dc04462cedd3f8969ce3a3ffe6231ae870775a92e9b0", 2)}
+		}},
+		{"filled target", func(lo, target *LimitOrder) { target.FillAmt = 100 }},
+		{"quantity too high", func(lo, target *LimitOrder) { lo.Quantity = 600 }},
+	} {
+		target := newOrder(true, 500, 1e8)
+		lo := newOrder(true, 400, 2e8)
+		lo.Commit = Commitment{0x01}
+		lo.Replaces = target.ID()
+		tt.modify(lo, target)
+		if err := ValidateReplacement(lo, target); err == nil {
+			t.Fatalf("%s: no error", tt.name)
+		}
+	}
+
+	// A buy replacement is limited by the quote quantity.
+	target = newOrder(false, 500, 1e8)
+	lo = newOrder(false, 400, 2e8)
+	lo.Replaces = target.ID()
+	if err := ValidateReplacement(lo, target); err == nil {
+		t.Fatalf("no error for buy replacement with larger quote quantity")
+	}
+	lo.Rate = 1.25e8
+	if err := ValidateReplacement(lo, target); err != nil {
+		t.Fatalf("ValidateReplacement buy error: %v", err)
+	}
+}
+
 func TestCancelOrder_ID(t *testing.T) {
 	limitOrderID0, _ := hex.DecodeString("8490aca39a672a79a1d93d70b531bee2297c56040e970cac6d2be755c932508a")
 	var limitOrderID OrderID
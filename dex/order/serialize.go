@@ -210,11 +210,12 @@ func matchDecoder_v0(pushes [][]byte) (*UserMatch, error) {
 
 // Length-1 byte slices used as flags to indicate common order constants.
 var (
-	orderTypeLimit    = []byte{'l'}
-	orderTypeMarket   = []byte{'m'}
-	orderTypeCancel   = []byte{'c'}
-	orderTifImmediate = []byte{'i'}
-	orderTifStanding  = []byte{'s'}
+	orderTypeLimit      = []byte{'l'}
+	orderTypeMarket     = []byte{'m'}
+	orderTypeCancel     = []byte{'c'}
+	orderTifImmediate   = []byte{'i'}
+	orderTifStanding    = []byte{'s'}
+	orderTifGoodTilTime = []byte{'g'}
 )
 
 // EncodeOrder encodes the order to bytes suitable for wire communications or
@@ -222,18 +223,30 @@ var (
 func EncodeOrder(ord Order) []byte {
 	switch o := ord.(type) {
 	case *LimitOrder:
-		tif := orderTifStanding
-		if o.Force == ImmediateTiF {
-			tif = orderTifImmediate
+		limitFlags := encode.BuildyBytes{}.AddData(uint64B(o.Rate))
+		switch o.Force {
+		case ImmediateTiF:
+			limitFlags = limitFlags.AddData(orderTifImmediate)
+		case GoodTilTimeTiF:
+			// The expiry epoch is an additional push.
+			limitFlags = limitFlags.AddData(orderTifGoodTilTime).AddData(uint64B(o.ExpiryEpoch))
+		default:
+			limitFlags = limitFlags.AddData(orderTifStanding)
+		}
+		// The display quantity of an iceberg order and the replaced order ID
+		// of a replacement order are optional trailing pushes, distinguished
+		// by length.
+		if o.DisplayQty > 0 {
+			limitFlags = limitFlags.AddData(uint64B(o.DisplayQty))
+		}
+		if o.IsReplacement() {
+			limitFlags = limitFlags.AddData(o.Replaces[:])
 		}
 		return encode.BuildyBytes{0}.
 			AddData(orderTypeLimit).
 			AddData(EncodePrefix(&o.P)).
 			AddData(EncodeTrade(&o.T)).
-			AddData(encode.BuildyBytes{}.
-				AddData(uint64B(o.Rate)).
-				AddData(tif),
-			)
+			AddData(limitFlags)
 	case *MarketOrder:
 		return encode.BuildyBytes{0}.
 			AddData(orderTypeMarket).
@@ -289,19 +302,44 @@ func decodeOrder_v0(pushes [][]byte) (Order, error) {
 		if err != nil {
 			return nil, fmt.Errorf("decodeOrder_v0: error extracting limit flags: %w", err)
 		}
-		if len(flags) != 2 {
-			return nil, fmt.Errorf("decodeOrder_v0: expected 2 error flags, got %d", len(flags))
+		if len(flags) < 2 || len(flags) > 5 {
+			return nil, fmt.Errorf("decodeOrder_v0: expected 2 to 5 limit flags, got %d", len(flags))
 		}
 		rateB, tifB := flags[0], flags[1]
+		flags = flags[2:]
 		tif := ImmediateTiF
-		if bEqual(tifB, orderTifStanding) {
+		var expiry, displayQty uint64
+		switch {
+		case bEqual(tifB, orderTifStanding):
 			tif = StandingTiF
+		case bEqual(tifB, orderTifGoodTilTime):
+			if len(flags) == 0 || len(flags[0]) != 8 {
+				return nil, fmt.Errorf("decodeOrder_v0: invalid expiry epoch for good-til-time order")
+			}
+			tif = GoodTilTimeTiF
+			expiry = intCoder.Uint64(flags[0])
+			flags = flags[1:]
+		}
+		if len(flags) > 0 && len(flags[0]) == 8 {
+			displayQty = intCoder.Uint64(flags[0])
+			flags = flags[1:]
+		}
+		var replaces OrderID
+		if len(flags) > 0 && len(flags[0]) == OrderIDSize {
+			copy(replaces[:], flags[0])
+			flags = flags[1:]
+		}
+		if len(flags) > 0 {
+			return nil, fmt.Errorf("decodeOrder_v0: unexpected limit flags")
 		}
 		return &LimitOrder{
-			P:     *prefix,
-			T:     *trade.Copy(),
-			Rate:  intCoder.Uint64(rateB),
-			Force: tif,
+			P:           *prefix,
+			T:           *trade.Copy(),
+			Rate:        intCoder.Uint64(rateB),
+			Force:       tif,
+			ExpiryEpoch: expiry,
+			DisplayQty:  displayQty,
+			Replaces:    replaces,
 		}, nil
 
 	case bEqual(oType, orderTypeMarket):
@@ -55,6 +55,11 @@ const (
 	// standing limit orders that were matched but have failed to swap (neither
 	// executed nor canceled), and preimage misses.
 	OrderStatusRevoked
+
+	// OrderStatusExpired is for good-til-time limit orders that were removed
+	// from the book by the DEX when their expiry epoch closed. Like a canceled
+	// order, an expired order may have been partially filled.
+	OrderStatusExpired
 )
 
 var orderStatusNames = map[OrderStatus]string{
@@ -64,6 +69,7 @@ var orderStatusNames = map[OrderStatus]string{
 	OrderStatusExecuted: "executed",
 	OrderStatusCanceled: "canceled",
 	OrderStatusRevoked:  "revoked",
+	OrderStatusExpired:  "expired",
 }
 
 // String implements Stringer.
@@ -5,6 +5,7 @@
 package test
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -90,3 +91,52 @@ func testUserMatch(t *testing.T) {
 	}
 	MustCompareUserMatch(t, match, reMatch)
 }
+
+func TestEpochTranscript(t *testing.T) {
+	lo, pimgLO := RandomLimitOrder()
+	lo.SetTime(time.Now().Truncate(time.Millisecond))
+	mo, pimgMO := RandomMarketOrder()
+	co, _ := RandomCancelOrder()
+	maker, _ := RandomLimitOrder()
+	maker.Replaces = RandomOrderID()
+
+	tr := &order.EpochTranscript{
+		Base:      42,
+		Quote:     0,
+		Epoch:     order.EpochID{Idx: 12345, Dur: 60000},
+		LotSize:   1e8,
+		CSum:      randB(32),
+		Seed:      randB(32),
+		Queue:     []*order.TranscriptOrder{order.NewTranscriptOrder(lo), order.NewTranscriptOrder(mo)},
+		Preimages: []order.Preimage{pimgLO, pimgMO},
+		Misses:    []*order.TranscriptOrder{order.NewTranscriptOrder(co)},
+		Makers:    []*order.TranscriptOrder{order.NewTranscriptOrder(maker)},
+		Matches: []*order.TranscriptMatch{{
+			Taker:    lo.ID(),
+			Maker:    maker.ID(),
+			Quantity: 1e8,
+			Rate:     4e6,
+		}},
+	}
+
+	reTr, err := order.DecodeEpochTranscript(order.EncodeEpochTranscript(tr))
+	if err != nil {
+		t.Fatalf("error decoding EpochTranscript: %v", err)
+	}
+	if !reflect.DeepEqual(tr, reTr) {
+		t.Fatalf("decoded EpochTranscript differs")
+	}
+	if reTr.Misses[0].Target != co.TargetOrderID || reTr.Makers[0].Target != maker.Replaces {
+		t.Fatalf("wrong target IDs decoded")
+	}
+
+	// Empty transcript, and a truncated one.
+	tr = &order.EpochTranscript{Epoch: order.EpochID{Idx: 1, Dur: 1}}
+	trB := order.EncodeEpochTranscript(tr)
+	if _, err = order.DecodeEpochTranscript(trB); err != nil {
+		t.Fatalf("error decoding empty EpochTranscript: %v", err)
+	}
+	if _, err = order.DecodeEpochTranscript(trB[:len(trB)-2]); err == nil {
+		t.Fatalf("no error decoding truncated EpochTranscript")
+	}
+}
@@ -0,0 +1,285 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package order
+
+import (
+	"fmt"
+
+	"decred.org/dcrdex/dex/encode"
+)
+
+// EpochTranscript is the record of an epoch's order matching. It contains
+// everything required to independently reproduce the commitment checksum, the
+// shuffle seed, the shuffled queue order, and the resulting matches.
+type EpochTranscript struct {
+	Base    uint32
+	Quote   uint32
+	Epoch   EpochID
+	LotSize uint64
+	CSum    []byte
+	Seed    []byte
+	// Queue is the epoch queue of orders with revealed preimages, in the
+	// shuffled order in which they were matched.
+	Queue []*TranscriptOrder
+	// Preimages are the revealed preimages of the orders in Queue.
+	Preimages []Preimage
+	// Misses are the orders for which no preimage was revealed. Their
+	// commitments are included in the commitment checksum.
+	Misses []*TranscriptOrder
+	// Makers are the orders that were on the book prior to matching and were
+	// considered by the matcher, in their state prior to matching.
+	Makers []*TranscriptOrder
+	// Matches are the matches made, including cancel order matches, in the
+	// order they were made.
+	Matches []*TranscriptMatch
+}
+
+// TranscriptOrder is the public data of an order in an EpochTranscript that is
+// required to reproduce the epoch's matching. The order's account, funding
+// coins, and address are not included.
+type TranscriptOrder struct {
+	ID       OrderID
+	Commit   Commitment
+	Type     OrderType
+	Sell     bool
+	Quantity uint64
+	Rate     uint64
+	Force    TimeInForce
+	// Filled is the filled amount prior to matching.
+	Filled uint64
+	// Stamp is the time priority of the order in milliseconds. For a book
+	// order, this differs from the server time if an iceberg order was
+	// requeued.
+	Stamp      int64
+	DisplayQty uint64
+	// Target is the order targeted by a cancel order, or replaced by a
+	// replacement order.
+	Target OrderID
+}
+
+// NewTranscriptOrder creates a TranscriptOrder from the Order, using the
+// order's server time as its time priority.
+func NewTranscriptOrder(ord Order) *TranscriptOrder {
+	to := &TranscriptOrder{
+		ID:     ord.ID(),
+		Commit: ord.Commitment(),
+		Type:   ord.Type(),
+		Stamp:  ord.Time(),
+	}
+	switch o := ord.(type) {
+	case *LimitOrder:
+		to.Sell, to.Quantity, to.Filled = o.Sell, o.Quantity, o.Filled()
+		to.Rate, to.Force, to.DisplayQty = o.Rate, o.Force, o.DisplayQty
+		to.Target = o.Replaces
+	case *MarketOrder:
+		to.Sell, to.Quantity, to.Filled = o.Sell, o.Quantity, o.Filled()
+		to.Force = ImmediateTiF
+	case *CancelOrder:
+		to.Target = o.TargetOrderID
+	}
+	return to
+}
+
+// TranscriptMatch is a match in an EpochTranscript.
+type TranscriptMatch struct {
+	Taker    OrderID
+	Maker    OrderID
+	Quantity uint64
+	Rate     uint64
+}
+
+// encodeTranscriptOrder encodes the TranscriptOrder without a version.
+func encodeTranscriptOrder(o *TranscriptOrder) []byte {
+	sell := encode.ByteFalse
+	if o.Sell {
+		sell = encode.ByteTrue
+	}
+	return encode.BuildyBytes{}.
+		AddData(o.ID[:]).
+		AddData(o.Commit[:]).
+		AddData([]byte{byte(o.Type)}).
+		AddData(sell).
+		AddData(uint64B(o.Quantity)).
+		AddData(uint64B(o.Rate)).
+		AddData([]byte{byte(o.Force)}).
+		AddData(uint64B(o.Filled)).
+		AddData(uint64B(uint64(o.Stamp))).
+		AddData(uint64B(o.DisplayQty)).
+		AddData(o.Target[:])
+}
+
+// decodeTranscriptOrder decodes the output of encodeTranscriptOrder.
+func decodeTranscriptOrder(b []byte) (*TranscriptOrder, error) {
+	pushes, err := encode.ExtractPushes(b, 11)
+	if err != nil {
+		return nil, err
+	}
+	if len(pushes) != 11 {
+		return nil, fmt.Errorf("expected 11 pushes for transcript order, got %d", len(pushes))
+	}
+	if len(pushes[0]) != OrderIDSize || len(pushes[1]) != CommitmentSize || len(pushes[10]) != OrderIDSize {
+		return nil, fmt.Errorf("invalid transcript order ID, commitment, or target length")
+	}
+	if len(pushes[2]) != 1 || len(pushes[3]) != 1 || len(pushes[6]) != 1 {
+		return nil, fmt.Errorf("invalid transcript order type, side, or time-in-force length")
+	}
+	for _, i := range []int{4, 5, 7, 8, 9} {
+		if len(pushes[i]) != 8 {
+			return nil, fmt.Errorf("invalid transcript order integer length %d", len(pushes[i]))
+		}
+	}
+	o := &TranscriptOrder{
+		Type:       OrderType(pushes[2][0]),
+		Sell:       bEqual(pushes[3], encode.ByteTrue),
+		Quantity:   intCoder.Uint64(pushes[4]),
+		Rate:       intCoder.Uint64(pushes[5]),
+		Force:      TimeInForce(pushes[6][0]),
+		Filled:     intCoder.Uint64(pushes[7]),
+		Stamp:      int64(intCoder.Uint64(pushes[8])),
+		DisplayQty: intCoder.Uint64(pushes[9]),
+	}
+	copy(o.ID[:], pushes[0])
+	copy(o.Commit[:], pushes[1])
+	copy(o.Target[:], pushes[10])
+	return o, nil
+}
+
+func encodeTranscriptOrders(ords []*TranscriptOrder) []byte {
+	b := encode.BuildyBytes{}
+	for _, o := range ords {
+		b = b.AddData(encodeTranscriptOrder(o))
+	}
+	return b
+}
+
+func decodeTranscriptOrders(b []byte) ([]*TranscriptOrder, error) {
+	pushes, err := encode.ExtractPushes(b)
+	if err != nil {
+		return nil, err
+	}
+	ords := make([]*TranscriptOrder, 0, len(pushes))
+	for _, p := range pushes {
+		o, err := decodeTranscriptOrder(p)
+		if err != nil {
+			return nil, err
+		}
+		ords = append(ords, o)
+	}
+	return ords, nil
+}
+
+// EncodeEpochTranscript encodes the EpochTranscript to a versioned blob
+// suitable for database storage.
+func EncodeEpochTranscript(t *EpochTranscript) []byte {
+	preimages := make([]byte, 0, len(t.Preimages)*PreimageSize)
+	for i := range t.Preimages {
+		preimages = append(preimages, t.Preimages[i][:]...)
+	}
+	matches := encode.BuildyBytes{}
+	for _, m := range t.Matches {
+		matches = matches.AddData(encode.BuildyBytes{}.
+			AddData(m.Taker[:]).
+			AddData(m.Maker[:]).
+			AddData(uint64B(m.Quantity)).
+			AddData(uint64B(m.Rate)))
+	}
+	return encode.BuildyBytes{0}.
+		AddData(uint32B(t.Base)).
+		AddData(uint32B(t.Quote)).
+		AddData(uint64B(t.Epoch.Idx)).
+		AddData(uint64B(t.Epoch.Dur)).
+		AddData(uint64B(t.LotSize)).
+		AddData(t.CSum).
+		AddData(t.Seed).
+		AddData(encodeTranscriptOrders(t.Queue)).
+		AddData(preimages).
+		AddData(encodeTranscriptOrders(t.Misses)).
+		AddData(encodeTranscriptOrders(t.Makers)).
+		AddData(matches)
+}
+
+// DecodeEpochTranscript decodes the versioned blob into an EpochTranscript.
+func DecodeEpochTranscript(b []byte) (*EpochTranscript, error) {
+	ver, pushes, err := encode.DecodeBlob(b, 12)
+	if err != nil {
+		return nil, err
+	}
+	switch ver {
+	case 0:
+		return decodeEpochTranscript_v0(pushes)
+	}
+	return nil, fmt.Errorf("unknown EpochTranscript version %d", ver)
+}
+
+// decodeEpochTranscript_v0 decodes the version 0 payload into an
+// EpochTranscript.
+func decodeEpochTranscript_v0(pushes [][]byte) (*EpochTranscript, error) {
+	if len(pushes) != 12 {
+		return nil, fmt.Errorf("decodeEpochTranscript_v0: expected 12 pushes, got %d", len(pushes))
+	}
+	if len(pushes[0]) != 4 || len(pushes[1]) != 4 {
+		return nil, fmt.Errorf("decodeEpochTranscript_v0: invalid asset ID length")
+	}
+	for _, i := range []int{2, 3, 4} {
+		if len(pushes[i]) != 8 {
+			return nil, fmt.Errorf("decodeEpochTranscript_v0: invalid integer length %d", len(pushes[i]))
+		}
+	}
+	t := &EpochTranscript{
+		Base:  intCoder.Uint32(pushes[0]),
+		Quote: intCoder.Uint32(pushes[1]),
+		Epoch: EpochID{
+			Idx: intCoder.Uint64(pushes[2]),
+			Dur: intCoder.Uint64(pushes[3]),
+		},
+		LotSize: intCoder.Uint64(pushes[4]),
+		CSum:    pushes[5],
+		Seed:    pushes[6],
+	}
+	var err error
+	if t.Queue, err = decodeTranscriptOrders(pushes[7]); err != nil {
+		return nil, fmt.Errorf("decodeEpochTranscript_v0: error decoding queue: %w", err)
+	}
+	preimages := pushes[8]
+	if len(preimages)%PreimageSize != 0 {
+		return nil, fmt.Errorf("decodeEpochTranscript_v0: invalid preimages length %d", len(preimages))
+	}
+	t.Preimages = make([]Preimage, len(preimages)/PreimageSize)
+	for i := range t.Preimages {
+		copy(t.Preimages[i][:], preimages[i*PreimageSize:])
+	}
+	if t.Misses, err = decodeTranscriptOrders(pushes[9]); err != nil {
+		return nil, fmt.Errorf("decodeEpochTranscript_v0: error decoding misses: %w", err)
+	}
+	if t.Makers, err = decodeTranscriptOrders(pushes[10]); err != nil {
+		return nil, fmt.Errorf("decodeEpochTranscript_v0: error decoding makers: %w", err)
+	}
+	matches, err := encode.ExtractPushes(pushes[11])
+	if err != nil {
+		return nil, fmt.Errorf("decodeEpochTranscript_v0: error extracting matches: %w", err)
+	}
+	t.Matches = make([]*TranscriptMatch, 0, len(matches))
+	for _, mB := range matches {
+		mPushes, err := encode.ExtractPushes(mB, 4)
+		if err != nil {
+			return nil, fmt.Errorf("decodeEpochTranscript_v0: error extracting match: %w", err)
+		}
+		if len(mPushes) != 4 || len(mPushes[0]) != OrderIDSize || len(mPushes[1]) != OrderIDSize ||
+			len(mPushes[2]) != 8 || len(mPushes[3]) != 8 {
+			return nil, fmt.Errorf("decodeEpochTranscript_v0: invalid match encoding")
+		}
+		m := &TranscriptMatch{
+			Quantity: intCoder.Uint64(mPushes[2]),
+			Rate:     intCoder.Uint64(mPushes[3]),
+		}
+		copy(m.Taker[:], mPushes[0])
+		copy(m.Maker[:], mPushes[1])
+		t.Matches = append(t.Matches, m)
+	}
+	if len(t.Preimages) != len(t.Queue) {
+		return nil, fmt.Errorf("decodeEpochTranscript_v0: %d preimages for %d queued orders",
+			len(t.Preimages), len(t.Queue))
+	}
+	return t, nil
+}
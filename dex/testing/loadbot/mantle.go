@@ -161,7 +161,7 @@ func newMantle(name string) (*Mantle, error) {
 	waiter := dex.NewStartStopWaiter(c)
 	waiter.Start(ctx)
 
-	err = c.InitializeClient(pass, nil)
+	err = c.InitializeClient(pass, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize client")
 	}
@@ -20,6 +20,7 @@ import (
 	"decred.org/dcrdex/server/account"
 	dexsrv "decred.org/dcrdex/server/dex"
 	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/metrics"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -56,6 +57,16 @@ func apiPing(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, pongStr)
 }
 
+// apiMetrics is the handler for the '/metrics' request. The registered metrics
+// are written in the Prometheus text exposition format.
+func apiMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.WriteHeader(http.StatusOK)
+	if err := metrics.Write(w); err != nil {
+		log.Errorf("error writing metrics: %v", err)
+	}
+}
+
 // apiConfig is the handler for the '/config' API request.
 func (s *Server) apiConfig(w http.ResponseWriter, _ *http.Request) {
 	writeJSON(w, s.core.ConfigMsg())
@@ -431,6 +442,125 @@ func (s *Server) apiSuspend(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// apiAddMarket is the handler for the '/markets' POST API request, which adds
+// a new market for a pair of configured assets.
+func (s *Server) apiAddMarket(w http.ResponseWriter, r *http.Request) {
+	var form MarketPost
+	if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+		http.Error(w, fmt.Sprintf("unable to decode market: %v", err), http.StatusBadRequest)
+		return
+	}
+	if form.LotSize == 0 || form.RateStep == 0 || form.EpochDuration == 0 {
+		http.Error(w, "lot size, rate step, and epoch duration are required", http.StatusBadRequest)
+		return
+	}
+	if form.MarketBuyBuffer < 0 {
+		http.Error(w, "market buy buffer cannot be negative", http.StatusBadRequest)
+		return
+	}
+	mktInfo, err := dex.NewMarketInfoFromSymbols(form.Base, form.Quote, form.LotSize,
+		form.RateStep, form.EpochDuration, form.MarketBuyBuffer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if form.BookedLotLimit != 0 {
+		mktInfo.BookedLotLimit = form.BookedLotLimit
+	}
+	if found, _ := s.core.MarketRunning(mktInfo.Name); found {
+		http.Error(w, fmt.Sprintf("market %q already exists", mktInfo.Name), http.StatusBadRequest)
+		return
+	}
+
+	startEpoch, startTime, err := s.core.AddMarket(mktInfo)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to add market: %v", err)
+		log.Errorf(msg)
+		http.Error(w, msg, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &ResumeResult{
+		Market:     mktInfo.Name,
+		StartEpoch: startEpoch,
+		StartTime:  APITime{startTime},
+	})
+}
+
+// hander for route '/market/{marketName}/retire'
+func (s *Server) apiRetire(w http.ResponseWriter, r *http.Request) {
+	// Ensure the market exists and is not running.
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	found, running := s.core.MarketRunning(mkt)
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+	if running {
+		http.Error(w, fmt.Sprintf("market %q running", mkt), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.core.RetireMarket(mkt); err != nil {
+		msg := fmt.Sprintf("Failed to retire market: %v", err)
+		log.Errorf(msg)
+		http.Error(w, msg, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &RetireResult{
+		Market:     mkt,
+		RetireTime: APITime{time.Now()},
+	})
+}
+
+// hander for route '/market/{marketName}/configure' POST request, which changes
+// the parameters of a suspended market.
+func (s *Server) apiConfigureMarket(w http.ResponseWriter, r *http.Request) {
+	// Ensure the market exists and is not running.
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	found, running := s.core.MarketRunning(mkt)
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+	if running {
+		http.Error(w, fmt.Sprintf("market %q running", mkt), http.StatusBadRequest)
+		return
+	}
+
+	var form MarketConfigPost
+	if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+		http.Error(w, fmt.Sprintf("unable to decode market config: %v", err), http.StatusBadRequest)
+		return
+	}
+	if form.MarketBuyBuffer < 0 {
+		http.Error(w, "market buy buffer cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	mktInfo, err := s.core.ReconfigureMarket(mkt, &dexsrv.MarketParams{
+		LotSize:         form.LotSize,
+		RateStep:        form.RateStep,
+		EpochDuration:   form.EpochDuration,
+		MarketBuyBuffer: form.MarketBuyBuffer,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("Failed to reconfigure market: %v", err)
+		log.Errorf(msg)
+		http.Error(w, msg, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &MarketConfig{
+		Market:          mkt,
+		LotSize:         mktInfo.LotSize,
+		RateStep:        mktInfo.RateStep,
+		EpochDuration:   mktInfo.EpochDuration,
+		MarketBuyBuffer: mktInfo.MarketBuyBuffer,
+	})
+}
+
 // apiAccounts is the handler for the '/accounts' API request.
 func (s *Server) apiAccounts(w http.ResponseWriter, _ *http.Request) {
 	accts, err := s.core.Accounts()
@@ -457,6 +587,27 @@ func (s *Server) apiEnableDataAPI(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, msg)
 }
 
+// apiRateTiers is the handler for the '/ratetiers' API request.
+func (s *Server) apiRateTiers(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, s.core.RateTiers())
+}
+
+// apiSetRateTiers is the handler for the POST '/ratetiers' API request, used to
+// replace the account rate tiers for the order routes. The body is a JSON
+// array of tiers. An empty array removes all tiers.
+func (s *Server) apiSetRateTiers(w http.ResponseWriter, r *http.Request) {
+	var tiers []*dexsrv.RateTier
+	if err := json.NewDecoder(r.Body).Decode(&tiers); err != nil {
+		http.Error(w, fmt.Sprintf("unable to decode rate tiers: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := s.core.SetRateTiers(tiers); err != nil {
+		http.Error(w, fmt.Sprintf("invalid rate tiers: %v", err), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, s.core.RateTiers())
+}
+
 // apiAccountInfo is the handler for the '/account/{account id}' API request.
 func (s *Server) apiAccountInfo(w http.ResponseWriter, r *http.Request) {
 	acctIDStr := chi.URLParam(r, accountIDKey)
@@ -520,6 +671,221 @@ func (s *Server) apiForgiveMatchFail(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, res)
 }
 
+// apiBan is the handler for the '/account/{accountID}/ban?rule=RULE' API
+// request, which closes the account citing the specified rule.
+func (s *Server) apiBan(w http.ResponseWriter, r *http.Request) {
+	acctIDStr := chi.URLParam(r, accountIDKey)
+	acctID, err := decodeAcctID(acctIDStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ruleStr := r.URL.Query().Get(ruleKey)
+	if ruleStr == "" {
+		http.Error(w, "rule not specified", http.StatusBadRequest)
+		return
+	}
+	ruleInt, err := strconv.ParseUint(ruleStr, 10, 8)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid rule %q: %v", ruleStr, err), http.StatusBadRequest)
+		return
+	}
+	rule := account.Rule(ruleInt)
+	if !rule.Punishable() {
+		http.Error(w, fmt.Sprintf("rule %d is not punishable", ruleInt), http.StatusBadRequest)
+		return
+	}
+	if err := s.core.Penalize(acctID, rule, "Account closed by the exchange operator."); err != nil {
+		http.Error(w, fmt.Sprintf("failed to ban account %v: %v", acctID, err), http.StatusInternalServerError)
+		return
+	}
+	log.Infof("Account %v closed by the operator citing rule %v", acctID, rule)
+	writeJSON(w, &BanResult{
+		AccountID:  acctIDStr,
+		BrokenRule: uint8(rule),
+		BanTime:    APITime{time.Now()},
+	})
+}
+
+// apiUnban is the handler for the '/account/{accountID}/unban' API request.
+func (s *Server) apiUnban(w http.ResponseWriter, r *http.Request) {
+	acctIDStr := chi.URLParam(r, accountIDKey)
+	acctID, err := decodeAcctID(acctIDStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.core.Unban(acctID); err != nil {
+		http.Error(w, fmt.Sprintf("failed to unban account %v: %v", acctID, err), http.StatusInternalServerError)
+		return
+	}
+	log.Infof("Account %v restored by the operator", acctID)
+	writeJSON(w, &UnbanResult{
+		AccountID: acctIDStr,
+		UnbanTime: APITime{time.Now()},
+	})
+}
+
+// apiAccountOutcomes is the handler for the '/account/{accountID}/outcomes' API
+// request, which lists the scored match and preimage outcomes that determine
+// the account's score.
+func (s *Server) apiAccountOutcomes(w http.ResponseWriter, r *http.Request) {
+	acctIDStr := chi.URLParam(r, accountIDKey)
+	acctID, err := decodeAcctID(acctIDStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	score, matchOutcomes, preimageOutcomes, err := s.core.AccountOutcomes(acctID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to retrieve outcomes for account %v: %v", acctID, err), http.StatusInternalServerError)
+		return
+	}
+	res := &AccountOutcomes{
+		AccountID:        acctIDStr,
+		Score:            score,
+		MatchOutcomes:    make([]*MatchOutcome, 0, len(matchOutcomes)),
+		PreimageOutcomes: make([]*PreimageOutcome, 0, len(preimageOutcomes)),
+	}
+	for _, mo := range matchOutcomes {
+		mkt, _ := dex.MarketName(mo.Base, mo.Quote)
+		res.MatchOutcomes = append(res.MatchOutcomes, &MatchOutcome{
+			MatchID: mo.ID.String(),
+			Market:  mkt,
+			Outcome: mo.Outcome.String(),
+			Score:   mo.Score,
+			Value:   mo.Value,
+			Time:    APITime{time.UnixMilli(mo.Time)},
+		})
+	}
+	for _, po := range preimageOutcomes {
+		res.PreimageOutcomes = append(res.PreimageOutcomes, &PreimageOutcome{
+			OrderID: po.OrderID.String(),
+			Miss:    po.Miss,
+			Score:   po.Score,
+			Time:    APITime{time.UnixMilli(po.Time)},
+		})
+	}
+	writeJSON(w, res)
+}
+
+// apiActiveOrders is the handler for the '/account/{accountID}/activeorders'
+// API request, which lists the account's epoch and booked orders and active
+// matches on all markets.
+func (s *Server) apiActiveOrders(w http.ResponseWriter, r *http.Request) {
+	acctIDStr := chi.URLParam(r, accountIDKey)
+	acctID, err := decodeAcctID(acctIDStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ords, err := s.core.ActiveUserOrders(r.Context(), acctID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to retrieve orders for account %v: %v", acctID, err), http.StatusInternalServerError)
+		return
+	}
+	matches, err := s.core.ActiveUserMatches(acctID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to retrieve matches for account %v: %v", acctID, err), http.StatusInternalServerError)
+		return
+	}
+	res := &ActiveOrders{
+		AccountID: acctIDStr,
+		Orders:    make([]*msgjson.BookOrderNote, 0, len(ords)),
+		Matches:   make([]*MatchData, 0, len(matches)),
+	}
+	for _, ord := range ords {
+		mkt, _ := dex.MarketName(ord.Base(), ord.Quote())
+		msgOrder, err := market.OrderToMsgOrder(ord, mkt)
+		if err != nil {
+			log.Errorf("unable to encode order: %v", err)
+			continue
+		}
+		res.Orders = append(res.Orders, msgOrder)
+	}
+	for _, match := range matches {
+		res.Matches = append(res.Matches, &MatchData{
+			TakerSell: match.TakerSell,
+			ID:        match.ID.String(),
+			Maker:     match.Maker.String(),
+			MakerAcct: match.MakerAcct.String(),
+			MakerAddr: match.MakerAddr,
+			Taker:     match.Taker.String(),
+			TakerAcct: match.TakerAcct.String(),
+			TakerAddr: match.TakerAddr,
+			EpochIdx:  match.Epoch.Idx,
+			EpochDur:  match.Epoch.Dur,
+			Quantity:  match.Quantity,
+			Rate:      match.Rate,
+			BaseRate:  match.BaseRate,
+			QuoteRate: match.QuoteRate,
+			Active:    match.Active,
+			Status:    match.Status.String(),
+		})
+	}
+	writeJSON(w, res)
+}
+
+// apiUnbookOrder is the handler for the '/market/{marketName}/unbook/{orderID}'
+// API request, which unbooks a standing order without penalizing its owner.
+func (s *Server) apiUnbookOrder(w http.ResponseWriter, r *http.Request) {
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	if found, _ := s.core.MarketRunning(mkt); !found {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+	oid, err := order.IDFromHex(chi.URLParam(r, orderIDKey))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lo, err := s.core.UnbookOrder(mkt, oid)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to unbook order %v: %v", oid, err), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, unbookResult(mkt, []*order.LimitOrder{lo}))
+}
+
+// apiUnbookAccountOrders is the handler for the
+// '/account/{accountID}/unbook/{marketName}' API request, which unbooks all of
+// the account's standing orders on the market without penalizing the user.
+func (s *Server) apiUnbookAccountOrders(w http.ResponseWriter, r *http.Request) {
+	acctID, err := decodeAcctID(chi.URLParam(r, accountIDKey))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	if found, _ := s.core.MarketRunning(mkt); !found {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+	los, err := s.core.UnbookAccountOrders(mkt, acctID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to unbook orders for account %v: %v", acctID, err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, unbookResult(mkt, los))
+}
+
+func unbookResult(mkt string, los []*order.LimitOrder) *UnbookResult {
+	res := &UnbookResult{
+		Market:     mkt,
+		Orders:     make([]*msgjson.BookOrderNote, 0, len(los)),
+		UnbookTime: APITime{time.Now()},
+	}
+	for _, lo := range los {
+		msgOrder, err := market.OrderToMsgOrder(lo, mkt)
+		if err != nil {
+			log.Errorf("unable to encode order: %v", err)
+			continue
+		}
+		res.Orders = append(res.Orders, msgOrder)
+	}
+	return res
+}
+
 func toNote(r *http.Request) (*msgjson.Message, int, error) {
 	body, err := io.ReadAll(r.Body)
 	r.Body.Close()
@@ -18,6 +18,7 @@ import (
 	"sync"
 	"time"
 
+	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/order"
 	"decred.org/dcrdex/server/account"
@@ -40,6 +41,7 @@ const (
 	accountIDKey       = "account"
 	yesKey             = "yes"
 	matchIDKey         = "match"
+	orderIDKey         = "order"
 	assetSymKey        = "asset"
 	ruleKey            = "rule"
 	scaleKey           = "scale"
@@ -66,11 +68,23 @@ type SvrCore interface {
 	MarketStatuses() map[string]*market.Status
 	SuspendMarket(name string, tSusp time.Time, persistBooks bool) (*market.SuspendEpoch, error)
 	ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64, startTime time.Time, err error)
+	AddMarket(mktInfo *dex.MarketInfo) (startEpoch int64, startTime time.Time, err error)
+	RetireMarket(name string) error
+	ReconfigureMarket(name string, params *dexsrv.MarketParams) (*dex.MarketInfo, error)
 	ForgiveMatchFail(aid account.AccountID, mid order.MatchID) (forgiven, unbanned bool, err error)
+	Penalize(aid account.AccountID, rule account.Rule, details string) error
+	Unban(aid account.AccountID) error
+	AccountOutcomes(aid account.AccountID) (score int32, matchOutcomes []*dexsrv.MatchOutcome, preimageOutcomes []*dexsrv.PreimageOutcome, err error)
+	ActiveUserOrders(ctx context.Context, aid account.AccountID) ([]order.Order, error)
+	ActiveUserMatches(aid account.AccountID) ([]*db.MatchData, error)
+	UnbookOrder(mktName string, oid order.OrderID) (*order.LimitOrder, error)
+	UnbookAccountOrders(mktName string, aid account.AccountID) ([]*order.LimitOrder, error)
 	BookOrders(base, quote uint32) (orders []*order.LimitOrder, err error)
 	EpochOrders(base, quote uint32) (orders []order.Order, err error)
 	MarketMatchesStreaming(base, quote uint32, includeInactive bool, N int64, f func(*dexsrv.MatchData) error) (int, error)
 	EnableDataAPI(yes bool)
+	RateTiers() []*dexsrv.RateTier
+	SetRateTiers(tiers []*dexsrv.RateTier) error
 }
 
 // Server is a multi-client https server.
@@ -87,6 +101,8 @@ type SrvConfig struct {
 	Core            SvrCore
 	Addr, Cert, Key string
 	AuthSHA         [32]byte
+	// Metrics enables the Prometheus metrics endpoint at /metrics.
+	Metrics bool
 }
 
 // UseLogger sets the logger for the admin package.
@@ -143,13 +159,20 @@ func NewServer(cfg *SrvConfig) (*Server, error) {
 
 	// api endpoints
 	mux.Route("/api", func(r chi.Router) {
-		r.Use(middleware.AllowContentType("text/plain"))
+		r.Use(middleware.AllowContentType("text/plain", "application/json"))
 		r.Get("/ping", apiPing)
 		r.Get("/config", s.apiConfig)
 		r.Get("/accounts", s.apiAccounts)
 		r.Get("/enabledataapi/{"+yesKey+"}", s.apiEnableDataAPI)
+		r.Get("/ratetiers", s.apiRateTiers)
+		r.Post("/ratetiers", s.apiSetRateTiers)
 		r.Route("/account/{"+accountIDKey+"}", func(rm chi.Router) {
 			rm.Get("/", s.apiAccountInfo)
+			rm.Get("/ban", s.apiBan)
+			rm.Get("/unban", s.apiUnban)
+			rm.Get("/outcomes", s.apiAccountOutcomes)
+			rm.Get("/activeorders", s.apiActiveOrders)
+			rm.Get("/unbook/{"+marketNameKey+"}", s.apiUnbookAccountOrders)
 			rm.Get("/forgive_match/{"+matchIDKey+"}", s.apiForgiveMatchFail)
 			rm.Post("/notify", s.apiNotify)
 		})
@@ -159,16 +182,24 @@ func NewServer(cfg *SrvConfig) (*Server, error) {
 		})
 		r.Post("/notifyall", s.apiNotifyAll)
 		r.Get("/markets", s.apiMarkets)
+		r.Post("/markets", s.apiAddMarket)
 		r.Route("/market/{"+marketNameKey+"}", func(rm chi.Router) {
 			rm.Get("/", s.apiMarketInfo)
 			rm.Get("/orderbook", s.apiMarketOrderBook)
 			rm.Get("/epochorders", s.apiMarketEpochOrders)
 			rm.Get("/matches", s.apiMarketMatches)
+			rm.Get("/unbook/{"+orderIDKey+"}", s.apiUnbookOrder)
 			rm.Get("/suspend", s.apiSuspend)
 			rm.Get("/resume", s.apiResume)
+			rm.Get("/retire", s.apiRetire)
+			rm.Post("/configure", s.apiConfigureMarket)
 		})
 	})
 
+	if cfg.Metrics {
+		mux.Get("/metrics", apiMetrics)
+	}
+
 	return s, nil
 }
 
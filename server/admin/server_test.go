@@ -28,9 +28,11 @@ import (
 	"decred.org/dcrdex/dex/order"
 	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/auth"
 	"decred.org/dcrdex/server/db"
 	dexsrv "decred.org/dcrdex/server/dex"
 	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/metrics"
 	"github.com/decred/dcrd/certgen"
 	"github.com/decred/slog"
 	"github.com/go-chi/chi/v5"
@@ -59,7 +61,18 @@ type TCore struct {
 	account          *db.Account
 	accountErr       error
 	penalizeErr      error
+	penalizedRule    account.Rule
 	unbanErr         error
+	score            int32
+	matchOutcomes    []*dexsrv.MatchOutcome
+	preimageOutcomes []*dexsrv.PreimageOutcome
+	outcomesErr      error
+	activeOrders     []order.Order
+	activeOrdersErr  error
+	activeMatches    []*db.MatchData
+	activeMatchesErr error
+	unbooked         []*order.LimitOrder
+	unbookErr        error
 	book             []*order.LimitOrder
 	bookErr          error
 	epochOrders      []order.Order
@@ -67,6 +80,11 @@ type TCore struct {
 	marketMatches    []*dexsrv.MatchData
 	marketMatchesErr error
 	dataEnabled      uint32
+	addMarketErr     error
+	retireErr        error
+	reconfigErr      error
+	rateTiers        []*dexsrv.RateTier
+	setRateTiersErr  error
 }
 
 func (c *TCore) ConfigMsg() json.RawMessage { return nil }
@@ -96,6 +114,55 @@ func (c *TCore) SuspendMarket(name string, tSusp time.Time, persistBooks bool) (
 	return tMkt.suspend, nil
 }
 
+func (c *TCore) AddMarket(mktInfo *dex.MarketInfo) (startEpoch int64, startTime time.Time, err error) {
+	if c.addMarketErr != nil {
+		return 0, time.Time{}, c.addMarketErr
+	}
+	startTime = time.Now().Add(time.Duration(mktInfo.EpochDuration) * time.Millisecond)
+	startEpoch = startTime.UnixMilli() / int64(mktInfo.EpochDuration)
+	c.markets[mktInfo.Name] = &TMarket{
+		dur:        mktInfo.EpochDuration,
+		startEpoch: startEpoch,
+	}
+	return
+}
+
+func (c *TCore) RetireMarket(name string) error {
+	if c.retireErr != nil {
+		return c.retireErr
+	}
+	delete(c.markets, name)
+	return nil
+}
+
+func (c *TCore) ReconfigureMarket(name string, params *dexsrv.MarketParams) (*dex.MarketInfo, error) {
+	if c.reconfigErr != nil {
+		return nil, c.reconfigErr
+	}
+	tMkt := c.markets[name]
+	mktInfo := &dex.MarketInfo{
+		Name:            name,
+		LotSize:         1e8,
+		RateStep:        1e3,
+		EpochDuration:   tMkt.dur,
+		MarketBuyBuffer: 1.5,
+	}
+	if params.LotSize > 0 {
+		mktInfo.LotSize = params.LotSize
+	}
+	if params.RateStep > 0 {
+		mktInfo.RateStep = params.RateStep
+	}
+	if params.EpochDuration > 0 {
+		mktInfo.EpochDuration = params.EpochDuration
+		tMkt.dur = params.EpochDuration
+	}
+	if params.MarketBuyBuffer > 0 {
+		mktInfo.MarketBuyBuffer = params.MarketBuyBuffer
+	}
+	return mktInfo, nil
+}
+
 func (c *TCore) market(name string) *TMarket {
 	if c.markets == nil {
 		return nil
@@ -181,6 +248,16 @@ func (c *TCore) EnableDataAPI(yes bool) {
 	atomic.StoreUint32(&c.dataEnabled, v)
 }
 
+func (c *TCore) RateTiers() []*dexsrv.RateTier { return c.rateTiers }
+
+func (c *TCore) SetRateTiers(tiers []*dexsrv.RateTier) error {
+	if c.setRateTiersErr != nil {
+		return c.setRateTiersErr
+	}
+	c.rateTiers = tiers
+	return nil
+}
+
 type tResponseWriter struct {
 	b    []byte
 	code int
@@ -201,12 +278,36 @@ func (c *TCore) Accounts() ([]*db.Account, error) { return c.accounts, c.account
 func (c *TCore) AccountInfo(_ account.AccountID) (*db.Account, error) {
 	return c.account, c.accountErr
 }
-func (c *TCore) Penalize(_ account.AccountID, _ account.Rule, _ string) error {
+func (c *TCore) Penalize(_ account.AccountID, rule account.Rule, _ string) error {
+	c.penalizedRule = rule
 	return c.penalizeErr
 }
 func (c *TCore) Unban(_ account.AccountID) error {
 	return c.unbanErr
 }
+func (c *TCore) AccountOutcomes(_ account.AccountID) (int32, []*dexsrv.MatchOutcome, []*dexsrv.PreimageOutcome, error) {
+	return c.score, c.matchOutcomes, c.preimageOutcomes, c.outcomesErr
+}
+func (c *TCore) ActiveUserOrders(_ context.Context, _ account.AccountID) ([]order.Order, error) {
+	return c.activeOrders, c.activeOrdersErr
+}
+func (c *TCore) ActiveUserMatches(_ account.AccountID) ([]*db.MatchData, error) {
+	return c.activeMatches, c.activeMatchesErr
+}
+func (c *TCore) UnbookOrder(_ string, oid order.OrderID) (*order.LimitOrder, error) {
+	if c.unbookErr != nil {
+		return nil, c.unbookErr
+	}
+	for _, lo := range c.unbooked {
+		if lo.ID() == oid {
+			return lo, nil
+		}
+	}
+	return nil, fmt.Errorf("order %v not booked", oid)
+}
+func (c *TCore) UnbookAccountOrders(_ string, _ account.AccountID) ([]*order.LimitOrder, error) {
+	return c.unbooked, c.unbookErr
+}
 func (c *TCore) ForgiveMatchFail(_ account.AccountID, _ order.MatchID) (bool, bool, error) {
 	return false, false, nil // TODO: tests
 }
@@ -304,6 +405,23 @@ func TestPing(t *testing.T) {
 	}
 }
 
+func TestMetrics(t *testing.T) {
+	metrics.NewGaugeVec("admin_test_gauge", "Admin test gauge.", "market").Set(2, "dcr_btc")
+
+	w := httptest.NewRecorder()
+	apiMetrics(w, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiMetrics returned code %d, expected 200", w.Code)
+	}
+	if ctHdr := w.Result().Header.Get("Content-Type"); ctHdr != metrics.ContentType {
+		t.Errorf("Content-Type incorrect. got %q, expected %q", ctHdr, metrics.ContentType)
+	}
+	wantSample := `admin_test_gauge{market="dcr_btc"} 2` + "\n"
+	if !strings.Contains(w.Body.String(), wantSample) {
+		t.Errorf("metrics response missing %q:\n%s", wantSample, w.Body.String())
+	}
+}
+
 func TestMarkets(t *testing.T) {
 	core := &TCore{
 		markets: make(map[string]*TMarket),
@@ -840,6 +958,225 @@ func TestResume(t *testing.T) {
 	}
 }
 
+func TestAddMarket(t *testing.T) {
+	core := &TCore{
+		markets: make(map[string]*TMarket),
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Post("/markets", srv.apiAddMarket)
+
+	tests := []struct {
+		name, body string
+		addErr     error
+		wantCode   int
+	}{{
+		name:     "ok",
+		body:     `{"base":"dcr","quote":"btc","lotSize":100000000,"rateStep":1000,"epochDuration":6000,"marketBuyBuffer":1.5}`,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "already exists",
+		body:     `{"base":"DCR","quote":"BTC","lotSize":100000000,"rateStep":1000,"epochDuration":6000,"marketBuyBuffer":1.5}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "not json",
+		body:     "dcr_btc",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "no lot size",
+		body:     `{"base":"dcr","quote":"ltc","rateStep":1000,"epochDuration":6000,"marketBuyBuffer":1.5}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "negative market buy buffer",
+		body:     `{"base":"dcr","quote":"ltc","lotSize":100000000,"rateStep":1000,"epochDuration":6000,"marketBuyBuffer":-1}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "unknown asset",
+		body:     `{"base":"dcr","quote":"xyz","lotSize":100000000,"rateStep":1000,"epochDuration":6000,"marketBuyBuffer":1.5}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "core error",
+		body:     `{"base":"dcr","quote":"ltc","lotSize":100000000,"rateStep":1000,"epochDuration":6000,"marketBuyBuffer":1.5}`,
+		addErr:   errors.New("asset not configured"),
+		wantCode: http.StatusInternalServerError,
+	}}
+	for _, test := range tests {
+		core.addMarketErr = test.addErr
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "https://localhost/markets", strings.NewReader(test.body))
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiAddMarket returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		res := new(ResumeResult)
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("%q: failed to unmarshal result: %v", test.name, err)
+		}
+		if res.Market != "dcr_btc" {
+			t.Fatalf("%q: incorrect market name %q", test.name, res.Market)
+		}
+		if res.StartEpoch == 0 || res.StartTime.IsZero() {
+			t.Fatalf("%q: start epoch or time not set", test.name)
+		}
+		if core.markets[res.Market] == nil {
+			t.Fatalf("%q: market not added", test.name)
+		}
+	}
+}
+
+func TestRetire(t *testing.T) {
+	core := &TCore{
+		markets: make(map[string]*TMarket),
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Get("/market/{"+marketNameKey+"}/retire", srv.apiRetire)
+
+	name := "dcr_btc"
+	retire := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost/market/"+name+"/retire", nil)
+		r.RemoteAddr = "localhost"
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	// Non-existent market
+	if w := retire(); w.Code != http.StatusBadRequest {
+		t.Fatalf("apiRetire returned code %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	// Running market
+	tMkt := &TMarket{
+		running: true,
+		dur:     6000,
+	}
+	core.markets[name] = tMkt
+	if w := retire(); w.Code != http.StatusBadRequest {
+		t.Fatalf("apiRetire returned code %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	// Suspended, but core error
+	tMkt.running = false
+	core.retireErr = errors.New("boom")
+	if w := retire(); w.Code != http.StatusInternalServerError {
+		t.Fatalf("apiRetire returned code %d, expected %d", w.Code, http.StatusInternalServerError)
+	}
+	core.retireErr = nil
+
+	// Success
+	w := retire()
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiRetire returned code %d, expected %d", w.Code, http.StatusOK)
+	}
+	res := new(RetireResult)
+	if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if res.Market != name {
+		t.Errorf("incorrect market name %q, expected %q", res.Market, name)
+	}
+	if core.markets[name] != nil {
+		t.Errorf("market not retired")
+	}
+}
+
+func TestConfigureMarket(t *testing.T) {
+	name := "dcr_btc"
+	core := &TCore{
+		markets: map[string]*TMarket{
+			name: {
+				running: true,
+				dur:     6000,
+			},
+		},
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Post("/market/{"+marketNameKey+"}/configure", srv.apiConfigureMarket)
+
+	tests := []struct {
+		name, mkt, body string
+		running         bool
+		reconfigErr     error
+		wantCode        int
+		wantLotSize     uint64
+		wantEpochDur    uint64
+	}{{
+		name:     "unknown market",
+		mkt:      "dcr_ltc",
+		body:     `{"lotSize":200000000}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "running",
+		mkt:      name,
+		body:     `{"lotSize":200000000}`,
+		running:  true,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "not json",
+		mkt:      name,
+		body:     "lotSize",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "negative market buy buffer",
+		mkt:      name,
+		body:     `{"marketBuyBuffer":-1}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:        "core error",
+		mkt:         name,
+		body:        `{"lotSize":200000000}`,
+		reconfigErr: errors.New("boom"),
+		wantCode:    http.StatusInternalServerError,
+	}, {
+		name:         "ok",
+		mkt:          name,
+		body:         `{"lotSize":200000000,"epochDuration":10000}`,
+		wantCode:     http.StatusOK,
+		wantLotSize:  2e8,
+		wantEpochDur: 10000,
+	}}
+	for _, test := range tests {
+		core.markets[name].running = test.running
+		core.reconfigErr = test.reconfigErr
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "https://localhost/market/"+test.mkt+"/configure", strings.NewReader(test.body))
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiConfigureMarket returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		res := new(MarketConfig)
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("%q: failed to unmarshal result: %v", test.name, err)
+		}
+		if res.Market != name || res.LotSize != test.wantLotSize || res.EpochDuration != test.wantEpochDur {
+			t.Fatalf("%q: unexpected result %+v", test.name, res)
+		}
+	}
+}
+
 func TestSuspend(t *testing.T) {
 	core := &TCore{
 		markets: make(map[string]*TMarket),
@@ -1298,6 +1635,445 @@ func TestAccountInfo(t *testing.T) {
 	}
 }
 
+func TestBan(t *testing.T) {
+	core := new(TCore)
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Route("/account/{"+accountIDKey+"}", func(rm chi.Router) {
+		rm.Get("/ban", srv.apiBan)
+	})
+	acctIDStr := "0a9912205b2cbab0c25c2de30bda9074de0ae23b065489a99199bad763f102cc"
+	tests := []struct {
+		name, acctID, rule string
+		penalizeErr        error
+		wantCode           int
+	}{{
+		name:     "ok",
+		acctID:   acctIDStr,
+		rule:     "1",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "no rule",
+		acctID:   acctIDStr,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "rule not a number",
+		acctID:   acctIDStr,
+		rule:     "one",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "rule not punishable",
+		acctID:   acctIDStr,
+		rule:     "0",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "rule out of range",
+		acctID:   acctIDStr,
+		rule:     fmt.Sprint(uint8(account.MaxRule)),
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "account id not hex",
+		acctID:   "nothex",
+		rule:     "1",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:        "core.Penalize error",
+		acctID:      acctIDStr,
+		rule:        "1",
+		penalizeErr: errors.New(""),
+		wantCode:    http.StatusInternalServerError,
+	}}
+	for _, test := range tests {
+		core.penalizeErr = test.penalizeErr
+		core.penalizedRule = account.NoRule
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost/account/"+test.acctID+"/ban?rule="+test.rule, nil)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiBan returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		res := new(BanResult)
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("%q: unexpected response %v: %v", test.name, w.Body.String(), err)
+		}
+		if res.AccountID != acctIDStr || res.BrokenRule != 1 || core.penalizedRule != account.Rule(1) {
+			t.Fatalf("%q: wrong ban result %+v", test.name, res)
+		}
+	}
+}
+
+func TestUnban(t *testing.T) {
+	core := new(TCore)
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Route("/account/{"+accountIDKey+"}", func(rm chi.Router) {
+		rm.Get("/unban", srv.apiUnban)
+	})
+	acctIDStr := "0a9912205b2cbab0c25c2de30bda9074de0ae23b065489a99199bad763f102cc"
+	tests := []struct {
+		name, acctID string
+		unbanErr     error
+		wantCode     int
+	}{{
+		name:     "ok",
+		acctID:   acctIDStr,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "account id wrong length",
+		acctID:   acctIDStr[2:],
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "core.Unban error",
+		acctID:   acctIDStr,
+		unbanErr: errors.New(""),
+		wantCode: http.StatusInternalServerError,
+	}}
+	for _, test := range tests {
+		core.unbanErr = test.unbanErr
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost/account/"+test.acctID+"/unban", nil)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiUnban returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+	}
+}
+
+func TestAccountOutcomes(t *testing.T) {
+	core := new(TCore)
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Route("/account/{"+accountIDKey+"}", func(rm chi.Router) {
+		rm.Get("/outcomes", srv.apiAccountOutcomes)
+	})
+	acctIDStr := "0a9912205b2cbab0c25c2de30bda9074de0ae23b065489a99199bad763f102cc"
+	mid := order.MatchID{0x01}
+	oid := order.OrderID{0x02}
+	core.score = 3
+	core.matchOutcomes = []*dexsrv.MatchOutcome{{
+		ID:      mid,
+		Time:    1600000000000,
+		Outcome: auth.ViolationNoSwapAsMaker,
+		Score:   4,
+		Base:    42,
+		Quote:   0,
+		Value:   1e8,
+	}}
+	core.preimageOutcomes = []*dexsrv.PreimageOutcome{{
+		OrderID: oid,
+		Time:    1600000000000,
+		Miss:    true,
+		Score:   2,
+	}}
+
+	getOutcomes := func(acctID string, wantCode int) *AccountOutcomes {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost/account/"+acctID+"/outcomes", nil)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != wantCode {
+			t.Fatalf("apiAccountOutcomes returned code %d, expected %d", w.Code, wantCode)
+		}
+		if w.Code != http.StatusOK {
+			return nil
+		}
+		res := new(AccountOutcomes)
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("unexpected response %v: %v", w.Body.String(), err)
+		}
+		return res
+	}
+
+	res := getOutcomes(acctIDStr, http.StatusOK)
+	if res.AccountID != acctIDStr || res.Score != 3 || len(res.MatchOutcomes) != 1 || len(res.PreimageOutcomes) != 1 {
+		t.Fatalf("wrong outcomes result %+v", res)
+	}
+	mo := res.MatchOutcomes[0]
+	if mo.MatchID != mid.String() || mo.Market != "dcr_btc" || mo.Outcome != auth.ViolationNoSwapAsMaker.String() ||
+		mo.Score != 4 || mo.Value != 1e8 || mo.Time.UnixMilli() != 1600000000000 {
+		t.Fatalf("wrong match outcome %+v", mo)
+	}
+	po := res.PreimageOutcomes[0]
+	if po.OrderID != oid.String() || !po.Miss || po.Score != 2 {
+		t.Fatalf("wrong preimage outcome %+v", po)
+	}
+
+	// Bad account ID.
+	getOutcomes("nothex", http.StatusBadRequest)
+
+	// core.AccountOutcomes error.
+	core.outcomesErr = errors.New("")
+	getOutcomes(acctIDStr, http.StatusInternalServerError)
+}
+
+func TestActiveOrders(t *testing.T) {
+	core := new(TCore)
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Route("/account/{"+accountIDKey+"}", func(rm chi.Router) {
+		rm.Get("/activeorders", srv.apiActiveOrders)
+	})
+	acctIDStr := "0a9912205b2cbab0c25c2de30bda9074de0ae23b065489a99199bad763f102cc"
+	lo := &order.LimitOrder{
+		P: order.Prefix{
+			BaseAsset:  42,
+			QuoteAsset: 0,
+			OrderType:  order.LimitOrderType,
+			ServerTime: time.UnixMilli(1600000000000),
+		},
+		T: order.Trade{
+			Sell:     true,
+			Quantity: 1e8,
+		},
+		Rate:  1e6,
+		Force: order.StandingTiF,
+	}
+	core.activeOrders = []order.Order{lo}
+	core.activeMatches = []*db.MatchData{{
+		ID:       order.MatchID{0x01},
+		Maker:    lo.ID(),
+		Quantity: 1e8,
+		Rate:     1e6,
+		Active:   true,
+		Status:   order.MakerSwapCast,
+	}}
+
+	tests := []struct {
+		name, acctID     string
+		activeOrdersErr  error
+		activeMatchesErr error
+		wantCode         int
+	}{{
+		name:     "ok",
+		acctID:   acctIDStr,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "account id not hex",
+		acctID:   "nothex",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:            "core.ActiveUserOrders error",
+		acctID:          acctIDStr,
+		activeOrdersErr: errors.New(""),
+		wantCode:        http.StatusInternalServerError,
+	}, {
+		name:             "core.ActiveUserMatches error",
+		acctID:           acctIDStr,
+		activeMatchesErr: errors.New(""),
+		wantCode:         http.StatusInternalServerError,
+	}}
+	for _, test := range tests {
+		core.activeOrdersErr = test.activeOrdersErr
+		core.activeMatchesErr = test.activeMatchesErr
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost/account/"+test.acctID+"/activeorders", nil)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiActiveOrders returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		res := new(ActiveOrders)
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("%q: unexpected response %v: %v", test.name, w.Body.String(), err)
+		}
+		if len(res.Orders) != 1 || res.Orders[0].MarketID != "dcr_btc" || res.Orders[0].OrderID.String() != lo.ID().String() {
+			t.Fatalf("%q: wrong orders %+v", test.name, res.Orders)
+		}
+		if len(res.Matches) != 1 || res.Matches[0].Maker != lo.ID().String() || res.Matches[0].Status != order.MakerSwapCast.String() {
+			t.Fatalf("%q: wrong matches %+v", test.name, res.Matches)
+		}
+	}
+}
+
+func tUnbookOrder() *order.LimitOrder {
+	return &order.LimitOrder{
+		P: order.Prefix{
+			BaseAsset:  42,
+			QuoteAsset: 0,
+			OrderType:  order.LimitOrderType,
+			ServerTime: time.UnixMilli(1600000000000),
+		},
+		T: order.Trade{
+			Quantity: 1e8,
+		},
+		Rate:  1e6,
+		Force: order.StandingTiF,
+	}
+}
+
+func TestUnbookOrder(t *testing.T) {
+	core := &TCore{
+		markets: map[string]*TMarket{"dcr_btc": {running: true}},
+	}
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Route("/market/{"+marketNameKey+"}", func(rm chi.Router) {
+		rm.Get("/unbook/{"+orderIDKey+"}", srv.apiUnbookOrder)
+	})
+	lo := tUnbookOrder()
+	core.unbooked = []*order.LimitOrder{lo}
+	oidStr := lo.ID().String()
+
+	tests := []struct {
+		name, mkt, oid string
+		unbookErr      error
+		wantCode       int
+	}{{
+		name:     "ok",
+		mkt:      "dcr_btc",
+		oid:      oidStr,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "unknown market",
+		mkt:      "btc_dcr",
+		oid:      oidStr,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "order id wrong length",
+		mkt:      "dcr_btc",
+		oid:      oidStr[2:],
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "order not booked",
+		mkt:      "dcr_btc",
+		oid:      order.OrderID{0x01}.String(),
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:      "core.UnbookOrder error",
+		mkt:       "dcr_btc",
+		oid:       oidStr,
+		unbookErr: errors.New(""),
+		wantCode:  http.StatusBadRequest,
+	}}
+	for _, test := range tests {
+		core.unbookErr = test.unbookErr
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost/market/"+test.mkt+"/unbook/"+test.oid, nil)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiUnbookOrder returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		res := new(UnbookResult)
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("%q: unexpected response %v: %v", test.name, w.Body.String(), err)
+		}
+		if res.Market != "dcr_btc" || len(res.Orders) != 1 || res.Orders[0].OrderID.String() != oidStr {
+			t.Fatalf("%q: wrong result %+v", test.name, res)
+		}
+	}
+}
+
+func TestUnbookAccountOrders(t *testing.T) {
+	core := &TCore{
+		markets: map[string]*TMarket{"dcr_btc": {running: true}},
+	}
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Route("/account/{"+accountIDKey+"}", func(rm chi.Router) {
+		rm.Get("/unbook/{"+marketNameKey+"}", srv.apiUnbookAccountOrders)
+	})
+	acctIDStr := "0a9912205b2cbab0c25c2de30bda9074de0ae23b065489a99199bad763f102cc"
+	lo1, lo2 := tUnbookOrder(), tUnbookOrder()
+	lo2.Rate *= 2
+
+	tests := []struct {
+		name, acctID, mkt string
+		unbooked          []*order.LimitOrder
+		unbookErr         error
+		wantCode          int
+	}{{
+		name:     "ok",
+		acctID:   acctIDStr,
+		mkt:      "dcr_btc",
+		unbooked: []*order.LimitOrder{lo1, lo2},
+		wantCode: http.StatusOK,
+	}, {
+		name:     "ok no orders",
+		acctID:   acctIDStr,
+		mkt:      "dcr_btc",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "account id not hex",
+		acctID:   "nothex",
+		mkt:      "dcr_btc",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "unknown market",
+		acctID:   acctIDStr,
+		mkt:      "btc_dcr",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:      "core.UnbookAccountOrders error",
+		acctID:    acctIDStr,
+		mkt:       "dcr_btc",
+		unbookErr: errors.New(""),
+		wantCode:  http.StatusInternalServerError,
+	}}
+	for _, test := range tests {
+		core.unbooked = test.unbooked
+		core.unbookErr = test.unbookErr
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost/account/"+test.acctID+"/unbook/"+test.mkt, nil)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiUnbookAccountOrders returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		res := new(UnbookResult)
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("%q: unexpected response %v: %v", test.name, w.Body.String(), err)
+		}
+		if len(res.Orders) != len(test.unbooked) {
+			t.Fatalf("%q: expected %d unbooked orders, got %d", test.name, len(test.unbooked), len(res.Orders))
+		}
+		for i, lo := range test.unbooked {
+			if res.Orders[i].OrderID.String() != lo.ID().String() {
+				t.Fatalf("%q: wrong order %v, expected %v", test.name, res.Orders[i].OrderID, lo.ID())
+			}
+		}
+	}
+}
+
 func TestAPITimeMarshalJSON(t *testing.T) {
 	now := APITime{time.Now()}
 	b, err := json.Marshal(now)
@@ -1463,3 +2239,77 @@ func TestEnableDataAPI(t *testing.T) {
 	}
 
 }
+
+func TestRateTiers(t *testing.T) {
+	core := new(TCore)
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Get("/ratetiers", srv.apiRateTiers)
+	mux.Post("/ratetiers", srv.apiSetRateTiers)
+
+	tests := []struct {
+		name, body string
+		setErr     error
+		wantCode   int
+		wantTiers  int
+	}{{
+		name:      "ok",
+		body:      `[{"name":"maker","maxScore":-10,"order":{"rate":20,"burst":400}}]`,
+		wantCode:  http.StatusOK,
+		wantTiers: 1,
+	}, {
+		name:      "ok empty",
+		body:      `[]`,
+		wantCode:  http.StatusOK,
+		wantTiers: 0,
+	}, {
+		name:     "bad json",
+		body:     `{"name":"maker"}`,
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "invalid tiers",
+		body:     `[{"name":"maker","maxScore":-10,"order":{"rate":0,"burst":400}}]`,
+		setErr:   errors.New("bad rate"),
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, test := range tests {
+		core.rateTiers = nil
+		core.setRateTiersErr = test.setErr
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "https://localhost/ratetiers", strings.NewReader(test.body))
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiSetRateTiers returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		if len(core.rateTiers) != test.wantTiers {
+			t.Fatalf("%q: expected %d tiers, got %d", test.name, test.wantTiers, len(core.rateTiers))
+		}
+		var tiers []*dexsrv.RateTier
+		if err := json.Unmarshal(w.Body.Bytes(), &tiers); err != nil {
+			t.Fatalf("%q: error decoding response: %v", test.name, err)
+		}
+		if len(tiers) != test.wantTiers {
+			t.Fatalf("%q: expected %d tiers in response, got %d", test.name, test.wantTiers, len(tiers))
+		}
+	}
+
+	core.rateTiers = []*dexsrv.RateTier{{Name: "maker", MaxScore: -10}}
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "https://localhost/ratetiers", nil)
+	r.RemoteAddr = "localhost"
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiRateTiers returned code %d, expected %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), `"maker"`) {
+		t.Fatalf("apiRateTiers response missing tier: %s", w.Body.String())
+	}
+}
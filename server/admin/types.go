@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/msgjson"
 )
 
 // AssetPost is the expected structure of the asset POST data.
@@ -36,6 +37,36 @@ type MarketStatus struct {
 	PersistBook   *bool  `json:"persistbook,omitempty"`
 }
 
+// MarketPost is the expected structure of the POST data for adding a market.
+// Base and Quote are the asset symbols.
+type MarketPost struct {
+	Base            string  `json:"base"`
+	Quote           string  `json:"quote"`
+	LotSize         uint64  `json:"lotSize"`
+	RateStep        uint64  `json:"rateStep"`
+	EpochDuration   uint64  `json:"epochDuration"`
+	MarketBuyBuffer float64 `json:"marketBuyBuffer"`
+	BookedLotLimit  uint32  `json:"bookedLotLimit,omitempty"`
+}
+
+// MarketConfigPost is the expected structure of the POST data for
+// reconfiguring a suspended market. Omitted fields are not changed.
+type MarketConfigPost struct {
+	LotSize         uint64  `json:"lotSize,omitempty"`
+	RateStep        uint64  `json:"rateStep,omitempty"`
+	EpochDuration   uint64  `json:"epochDuration,omitempty"`
+	MarketBuyBuffer float64 `json:"marketBuyBuffer,omitempty"`
+}
+
+// MarketConfig is the result of a market reconfigure request.
+type MarketConfig struct {
+	Market          string  `json:"market"`
+	LotSize         uint64  `json:"lotSize"`
+	RateStep        uint64  `json:"rateStep"`
+	EpochDuration   uint64  `json:"epochDuration"`
+	MarketBuyBuffer float64 `json:"marketBuyBuffer"`
+}
+
 // MatchData describes a match.
 type MatchData struct {
 	ID          string `json:"id"`
@@ -82,6 +113,12 @@ type ResumeResult struct {
 	StartTime  APITime `json:"starttime"`
 }
 
+// RetireResult is the result of a market retire request.
+type RetireResult struct {
+	Market     string  `json:"market"`
+	RetireTime APITime `json:"retiretime"`
+}
+
 // RFC3339Milli is the RFC3339 time formatting with millisecond precision.
 const RFC3339Milli = "2006-01-02T15:04:05.999Z07:00"
 
@@ -113,3 +150,57 @@ type ForgiveResult struct {
 	Unbanned    bool    `json:"unbanned"`
 	ForgiveTime APITime `json:"forgivetime"`
 }
+
+// BanResult holds the result of a ban.
+type BanResult struct {
+	AccountID  string  `json:"accountid"`
+	BrokenRule uint8   `json:"brokenrule"`
+	BanTime    APITime `json:"bantime"`
+}
+
+// UnbanResult holds the result of an unban.
+type UnbanResult struct {
+	AccountID string  `json:"accountid"`
+	UnbanTime APITime `json:"unbantime"`
+}
+
+// UnbookResult holds the result of unbooking orders.
+type UnbookResult struct {
+	Market     string                   `json:"market"`
+	Orders     []*msgjson.BookOrderNote `json:"orders"`
+	UnbookTime APITime                  `json:"unbooktime"`
+}
+
+// MatchOutcome is a scored match outcome of an account.
+type MatchOutcome struct {
+	MatchID string  `json:"matchid"`
+	Market  string  `json:"market"`
+	Outcome string  `json:"outcome"`
+	Score   int32   `json:"score"`
+	Value   uint64  `json:"value"`
+	Time    APITime `json:"time"`
+}
+
+// PreimageOutcome is a scored preimage request outcome of an account.
+type PreimageOutcome struct {
+	OrderID string  `json:"orderid"`
+	Miss    bool    `json:"miss"`
+	Score   int32   `json:"score"`
+	Time    APITime `json:"time"`
+}
+
+// AccountOutcomes is the result of an account outcomes request. Score is the
+// sum of the scores of the outcomes.
+type AccountOutcomes struct {
+	AccountID        string             `json:"accountid"`
+	Score            int32              `json:"score"`
+	MatchOutcomes    []*MatchOutcome    `json:"matchoutcomes"`
+	PreimageOutcomes []*PreimageOutcome `json:"preimageoutcomes"`
+}
+
+// ActiveOrders is the result of an account active orders request.
+type ActiveOrders struct {
+	AccountID string                   `json:"accountid"`
+	Orders    []*msgjson.BookOrderNote `json:"orders"`
+	Matches   []*MatchData             `json:"matches"`
+}
@@ -6,6 +6,8 @@ package apidata
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,10 +15,21 @@ import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/candles"
 	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
 	"decred.org/dcrdex/server/comms"
+	"decred.org/dcrdex/server/db"
 	"decred.org/dcrdex/server/matcher"
 )
 
+const (
+	// DefaultTradesRequest is the number of trades to return if the request
+	// does not specify otherwise.
+	DefaultTradesRequest = 100
+	// MaxTradesRequest is the maximum number of trades that can be requested
+	// at once.
+	MaxTradesRequest = 500
+)
+
 var (
 	// Our internal millisecond representation of the bin sizes.
 	binSizes []uint64
@@ -25,9 +38,12 @@ var (
 )
 
 // DBSource is a source of persistent data. DBSource is used to prime the
-// caches at startup.
+// caches at startup, and is the source of the trade history and epoch
+// transcripts.
 type DBSource interface {
 	LoadEpochStats(base, quote uint32, caches []*candles.Cache) error
+	MarketTrades(base, quote uint32, before *order.MatchID, N int) ([]*db.MatchDataWithCoins, error)
+	EpochTranscript(base, quote uint32, epochIdx, epochDur int64) (*order.EpochTranscript, error)
 }
 
 // MarketSource is a source of market information. Markets are added after
@@ -46,22 +62,24 @@ type BookSource interface {
 
 // DataAPI is a data API backend.
 type DataAPI struct {
-	db             DBSource
-	epochDurations map[string]uint64
-	bookSource     BookSource
+	db         DBSource
+	bookSource BookSource
 
 	spotsMtx sync.RWMutex
 	spots    map[string]json.RawMessage
 
-	cacheMtx     sync.RWMutex
-	marketCaches map[string]map[uint64]*candles.Cache
-	cache5min    *candles.Cache
+	// cacheMtx guards markets, epochDurations, and marketCaches.
+	cacheMtx       sync.RWMutex
+	markets        map[string]MarketSource
+	epochDurations map[string]uint64
+	marketCaches   map[string]map[uint64]*candles.Cache
 }
 
 // NewDataAPI is the constructor for a new DataAPI.
 func NewDataAPI(dbSrc DBSource) *DataAPI {
 	s := &DataAPI{
 		db:             dbSrc,
+		markets:        make(map[string]MarketSource),
 		epochDurations: make(map[string]uint64),
 		spots:          make(map[string]json.RawMessage),
 		marketCaches:   make(map[string]map[uint64]*candles.Cache),
@@ -71,39 +89,55 @@ func NewDataAPI(dbSrc DBSource) *DataAPI {
 		comms.RegisterHTTP(msgjson.SpotsRoute, s.handleSpots)
 		comms.RegisterHTTP(msgjson.CandlesRoute, s.handleCandles)
 		comms.RegisterHTTP(msgjson.OrderBookRoute, s.handleOrderBook)
+		comms.RegisterHTTP(msgjson.TradesRoute, s.handleTrades)
+		comms.RegisterHTTP(msgjson.TickerRoute, s.handleTicker)
+		comms.RegisterHTTP(msgjson.EpochTranscriptRoute, s.handleEpochTranscript)
 	}
 	return s
 }
 
-// AddMarketSource should be called before any markets are running.
+// AddMarketSource should be called before the market is running. An existing
+// market with the same name is replaced.
 func (s *DataAPI) AddMarketSource(mkt MarketSource) error {
 	mktName, err := dex.MarketName(mkt.Base(), mkt.Quote())
 	if err != nil {
 		return err
 	}
 	epochDur := mkt.EpochDuration()
-	s.epochDurations[mktName] = epochDur
 	binCaches := make(map[uint64]*candles.Cache, len(binSizes)+1)
-	s.marketCaches[mktName] = binCaches
 	cacheList := make([]*candles.Cache, 0, len(binSizes)+1)
 	for _, binSize := range append([]uint64{epochDur}, binSizes...) {
 		cache := candles.NewCache(candles.CacheSize, binSize)
 		cacheList = append(cacheList, cache)
 		binCaches[binSize] = cache
-		if binSize == bin5min {
-			s.cache5min = cache
-		}
 	}
-	if s.cache5min == nil {
+	if binCaches[bin5min] == nil {
 		panic("no 5-minute cache")
 	}
 	err = s.db.LoadEpochStats(mkt.Base(), mkt.Quote(), cacheList)
 	if err != nil {
 		return err
 	}
+	s.cacheMtx.Lock()
+	s.markets[mktName] = mkt
+	s.epochDurations[mktName] = epochDur
+	s.marketCaches[mktName] = binCaches
+	s.cacheMtx.Unlock()
 	return nil
 }
 
+// RemoveMarketSource removes the named market's data.
+func (s *DataAPI) RemoveMarketSource(mktName string) {
+	s.cacheMtx.Lock()
+	delete(s.markets, mktName)
+	delete(s.epochDurations, mktName)
+	delete(s.marketCaches, mktName)
+	s.cacheMtx.Unlock()
+	s.spotsMtx.Lock()
+	delete(s.spots, mktName)
+	s.spotsMtx.Unlock()
+}
+
 // SetBookSource should be called before the first call to handleBook.
 func (s *DataAPI) SetBookSource(bs BookSource) {
 	s.bookSource = bs
@@ -139,7 +173,7 @@ func (s *DataAPI) ReportEpoch(base, quote uint32, epochIdx uint64, stats *matche
 			EndRate:     stats.EndRate,
 		})
 	}
-	change24, vol24 := s.cache5min.Delta(time.Now().Add(-time.Hour * 24))
+	change24, vol24 := mktCaches[bin5min].Delta(time.Now().Add(-time.Hour * 24))
 	s.cacheMtx.Unlock()
 
 	// Encode the spot price.
@@ -221,6 +255,199 @@ func (s *DataAPI) handleOrderBook(thing interface{}) (interface{}, error) {
 	return s.bookSource.Book(mkt)
 }
 
+// handleTrades implements comms.HTTPHandler for the /trades endpoint. A page of
+// the market's executed trades is returned, most recent first. Matches that
+// failed before the maker redeemed are not included. Pages are keyed by the ID
+// of the last trade of the previous page, so they do not shift as new trades
+// are made.
+func (s *DataAPI) handleTrades(thing interface{}) (interface{}, error) {
+	req, ok := thing.(*msgjson.TradesRequest)
+	if !ok {
+		return nil, fmt.Errorf("trades request unparseable")
+	}
+
+	n := req.N
+	if n == 0 {
+		n = DefaultTradesRequest
+	} else if n < 0 || n > MaxTradesRequest {
+		return nil, fmt.Errorf("requested n %d must be between 1 and %d", n, MaxTradesRequest)
+	}
+	var before *order.MatchID
+	if len(req.Before) > 0 {
+		if len(req.Before) != order.MatchIDSize {
+			return nil, fmt.Errorf("invalid before trade ID")
+		}
+		var mid order.MatchID
+		copy(mid[:], req.Before)
+		before = &mid
+	}
+
+	mkt, err := dex.MarketName(req.BaseID, req.QuoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing market for %d - %d", req.BaseID, req.QuoteID)
+	}
+	s.cacheMtx.RLock()
+	_, found := s.markets[mkt]
+	s.cacheMtx.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("market %s not known", mkt)
+	}
+
+	mds, err := s.db.MarketTrades(req.BaseID, req.QuoteID, before, n)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving trades for market %s", mkt)
+	}
+	trades := make([]*msgjson.ExecutedTrade, 0, len(mds))
+	for _, md := range mds {
+		side := "buy"
+		if md.TakerSell {
+			side = "sell"
+		}
+		trades = append(trades, &msgjson.ExecutedTrade{
+			ID:    md.ID[:],
+			Stamp: uint64(md.Epoch.End().UnixMilli()),
+			Rate:  md.Rate,
+			Qty:   md.Quantity,
+			Side:  side,
+		})
+	}
+	return trades, nil
+}
+
+// handleTicker implements comms.HTTPHandler for the /ticker endpoint. The
+// 24-hour statistics of every market are returned, sorted by market name.
+func (s *DataAPI) handleTicker(interface{}) (interface{}, error) {
+	since := time.Now().Add(-time.Hour * 24)
+	s.cacheMtx.RLock()
+	tickers := make([]*msgjson.Ticker, 0, len(s.markets))
+	for mktName, mkt := range s.markets {
+		cache := s.marketCaches[mktName][bin5min]
+		change24, vol24 := cache.Delta(since)
+		high24, low24 := cache.HighLow(since)
+		var rate uint64
+		if len(cache.Candles) > 0 {
+			rate = cache.Last().EndRate
+		}
+		tickers = append(tickers, &msgjson.Ticker{
+			Market:   mktName,
+			BaseID:   mkt.Base(),
+			QuoteID:  mkt.Quote(),
+			Rate:     rate,
+			High24:   high24,
+			Low24:    low24,
+			Vol24:    vol24,
+			Change24: change24,
+		})
+	}
+	s.cacheMtx.RUnlock()
+	sort.Slice(tickers, func(i, j int) bool {
+		return tickers[i].Market < tickers[j].Market
+	})
+	return tickers, nil
+}
+
+// handleEpochTranscript implements comms.HTTPHandler for the /epochtranscript
+// endpoint. The transcript of the market's epoch is returned, with which the
+// epoch's shuffle and matching can be verified.
+func (s *DataAPI) handleEpochTranscript(thing interface{}) (interface{}, error) {
+	req, ok := thing.(*msgjson.EpochTranscriptRequest)
+	if !ok {
+		return nil, fmt.Errorf("epoch transcript request unparseable")
+	}
+	mkt, err := dex.MarketName(req.BaseID, req.QuoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing market for %d - %d", req.BaseID, req.QuoteID)
+	}
+	s.cacheMtx.RLock()
+	epochDur, found := s.epochDurations[mkt]
+	s.cacheMtx.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("market %s not known", mkt)
+	}
+	if req.Duration != 0 {
+		epochDur = req.Duration
+	}
+	if req.Epoch > math.MaxInt64 || epochDur > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid epoch %d:%d", req.Epoch, epochDur)
+	}
+
+	t, err := s.db.EpochTranscript(req.BaseID, req.QuoteID, int64(req.Epoch), int64(epochDur))
+	if err != nil {
+		if db.IsErrEpochUnknown(err) {
+			return nil, fmt.Errorf("no transcript for epoch %d:%d of market %s", req.Epoch, epochDur, mkt)
+		}
+		return nil, fmt.Errorf("error retrieving epoch %d:%d transcript for market %s", req.Epoch, epochDur, mkt)
+	}
+	return wireTranscript(mkt, t), nil
+}
+
+func wireTranscriptOrders(ords []*order.TranscriptOrder) []*msgjson.TranscriptOrder {
+	wireOrds := make([]*msgjson.TranscriptOrder, 0, len(ords))
+	for _, o := range ords {
+		wo := &msgjson.TranscriptOrder{
+			OrderID:    o.ID[:],
+			Commit:     o.Commit[:],
+			OrderType:  uint8(o.Type),
+			Quantity:   o.Quantity,
+			Rate:       o.Rate,
+			Filled:     o.Filled,
+			Stamp:      uint64(o.Stamp),
+			DisplayQty: o.DisplayQty,
+		}
+		if o.Type != order.CancelOrderType {
+			wo.Side = msgjson.BuyOrderNum
+			if o.Sell {
+				wo.Side = msgjson.SellOrderNum
+			}
+		}
+		if o.Type == order.LimitOrderType {
+			switch o.Force {
+			case order.ImmediateTiF:
+				wo.TiF = msgjson.ImmediateOrderNum
+			case order.GoodTilTimeTiF:
+				wo.TiF = msgjson.GoodTilTimeOrderNum
+			default:
+				wo.TiF = msgjson.StandingOrderNum
+			}
+		}
+		if !o.Target.IsZero() {
+			wo.TargetID = o.Target[:]
+		}
+		wireOrds = append(wireOrds, wo)
+	}
+	return wireOrds
+}
+
+// wireTranscript converts the EpochTranscript to its msgjson form.
+func wireTranscript(mkt string, t *order.EpochTranscript) *msgjson.EpochTranscript {
+	preimages := make([]msgjson.Bytes, 0, len(t.Preimages))
+	for i := range t.Preimages {
+		preimages = append(preimages, t.Preimages[i][:])
+	}
+	matches := make([]*msgjson.TranscriptMatch, 0, len(t.Matches))
+	for _, m := range t.Matches {
+		matches = append(matches, &msgjson.TranscriptMatch{
+			TakerID:  m.Taker[:],
+			MakerID:  m.Maker[:],
+			Quantity: m.Quantity,
+			Rate:     m.Rate,
+		})
+	}
+	return &msgjson.EpochTranscript{
+		MarketID:  mkt,
+		Epoch:     t.Epoch.Idx,
+		Duration:  t.Epoch.Dur,
+		LotSize:   t.LotSize,
+		CSum:      t.CSum,
+		Seed:      t.Seed,
+		Queue:     wireTranscriptOrders(t.Queue),
+		Preimages: preimages,
+		Misses:    wireTranscriptOrders(t.Misses),
+		Makers:    wireTranscriptOrders(t.Makers),
+		Matches:   matches,
+	}
+}
+
 func init() {
 	for _, s := range candles.BinSizes {
 		dur, err := time.ParseDuration(s)
@@ -4,6 +4,7 @@
 package apidata
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -11,6 +12,8 @@ import (
 
 	"decred.org/dcrdex/dex/candles"
 	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/db"
 	"decred.org/dcrdex/server/matcher"
 )
 
@@ -25,13 +28,39 @@ func (m *TMarketSource) Base() uint32          { return m.base }
 func (m *TMarketSource) Quote() uint32         { return m.quote }
 
 type TDBSource struct {
-	loadEpochErr error
+	loadEpochErr  error
+	matches       []*db.MatchDataWithCoins
+	matchesErr    error
+	tradesBefore  *order.MatchID
+	tradesN       int
+	transcript    *order.EpochTranscript
+	epochDur      int64
+	transcriptErr error
 }
 
 func (db *TDBSource) LoadEpochStats(base, quote uint32, caches []*candles.Cache) error {
 	return db.loadEpochErr
 }
 
+func (tdb *TDBSource) MarketTrades(base, quote uint32, before *order.MatchID, N int) ([]*db.MatchDataWithCoins, error) {
+	tdb.tradesBefore, tdb.tradesN = before, N
+	if tdb.matchesErr != nil {
+		return nil, tdb.matchesErr
+	}
+	if len(tdb.matches) > N {
+		return tdb.matches[:N], nil
+	}
+	return tdb.matches, nil
+}
+
+func (tdb *TDBSource) EpochTranscript(base, quote uint32, epochIdx, epochDur int64) (*order.EpochTranscript, error) {
+	tdb.epochDur = epochDur
+	if tdb.transcriptErr != nil {
+		return nil, tdb.transcriptErr
+	}
+	return tdb.transcript, nil
+}
+
 type TBookSource struct {
 	book *msgjson.OrderBook
 }
@@ -186,3 +215,233 @@ func TestOrderBook(t *testing.T) {
 		t.Fatalf("where did this book come from?")
 	}
 }
+
+func TestTrades(t *testing.T) {
+	rig := newTestRig()
+	err := rig.api.AddMarketSource(&TMarketSource{42, 0})
+	if err != nil {
+		t.Fatalf("AddMarketSource error: %v", err)
+	}
+
+	// 5 matches, most recent first.
+	for i := 0; i < 5; i++ {
+		md := &db.MatchDataWithCoins{MatchData: db.MatchData{
+			ID:        order.MatchID{byte(i + 1)},
+			Epoch:     order.EpochID{Idx: uint64(100 - i), Dur: 1000},
+			Quantity:  uint64(i + 1),
+			Rate:      1e8,
+			TakerSell: i%2 == 0,
+			Active:    true,
+			Status:    order.MakerSwapCast,
+		}}
+		rig.db.matches = append(rig.db.matches, md)
+	}
+
+	getTrades := func(n int, before []byte) []*msgjson.ExecutedTrade {
+		t.Helper()
+		tradesI, err := rig.api.handleTrades(&msgjson.TradesRequest{
+			BaseID:  42,
+			QuoteID: 0,
+			N:       n,
+			Before:  before,
+		})
+		if err != nil {
+			t.Fatalf("handleTrades error: %v", err)
+		}
+		return tradesI.([]*msgjson.ExecutedTrade)
+	}
+
+	trades := getTrades(0, nil)
+	if len(trades) != 5 || rig.db.tradesN != DefaultTradesRequest || rig.db.tradesBefore != nil {
+		t.Fatalf("wrong trades request. got %d trades, n = %d, before = %v", len(trades), rig.db.tradesN, rig.db.tradesBefore)
+	}
+	for i, trade := range trades {
+		md := rig.db.matches[i]
+		if !bytes.Equal(trade.ID, md.ID[:]) || trade.Qty != md.Quantity || trade.Rate != md.Rate {
+			t.Fatalf("trade %d: wrong trade %+v", i, trade)
+		}
+	}
+	if trades[0].Side != "sell" || trades[1].Side != "buy" {
+		t.Fatalf("wrong sides %q, %q", trades[0].Side, trades[1].Side)
+	}
+	if trades[0].Stamp != 101000 {
+		t.Fatalf("wrong stamp. wanted 101000, got %d", trades[0].Stamp)
+	}
+
+	// The next page starts after the last trade received.
+	trades = getTrades(2, nil)
+	if len(trades) != 2 {
+		t.Fatalf("wanted 2 trades, got %d", len(trades))
+	}
+	getTrades(2, trades[1].ID)
+	if rig.db.tradesN != 2 || rig.db.tradesBefore == nil || *rig.db.tradesBefore != rig.db.matches[1].ID {
+		t.Fatalf("wrong next page request. n = %d, before = %v", rig.db.tradesN, rig.db.tradesBefore)
+	}
+
+	// Bad requests.
+	for _, req := range []*msgjson.TradesRequest{
+		{BaseID: 42, QuoteID: 0, N: MaxTradesRequest + 1},
+		{BaseID: 42, QuoteID: 0, N: -1},
+		{BaseID: 42, QuoteID: 0, Before: []byte{1, 2, 3}},
+		{BaseID: 0, QuoteID: 42}, // unknown market
+	} {
+		if _, err := rig.api.handleTrades(req); err == nil {
+			t.Fatalf("no error for bad request %+v", req)
+		}
+	}
+
+	// DB error.
+	rig.db.matchesErr = dummyErr
+	if _, err := rig.api.handleTrades(&msgjson.TradesRequest{BaseID: 42, QuoteID: 0}); err == nil {
+		t.Fatalf("no error for DB error")
+	}
+}
+
+func TestEpochTranscript(t *testing.T) {
+	rig := newTestRig()
+	err := rig.api.AddMarketSource(&TMarketSource{42, 0})
+	if err != nil {
+		t.Fatalf("AddMarketSource error: %v", err)
+	}
+
+	var targetID order.OrderID
+	targetID[0] = 1
+	rig.db.transcript = &order.EpochTranscript{
+		Base:    42,
+		Quote:   0,
+		Epoch:   order.EpochID{Idx: 100, Dur: 1000},
+		LotSize: 1e8,
+		CSum:    []byte{0x01},
+		Seed:    []byte{0x02},
+		Queue: []*order.TranscriptOrder{
+			{ID: order.OrderID{0x03}, Type: order.LimitOrderType, Sell: true, Quantity: 1e8, Rate: 1e6, Force: order.ImmediateTiF},
+			{ID: order.OrderID{0x04}, Type: order.CancelOrderType, Target: targetID},
+		},
+		Preimages: make([]order.Preimage, 2),
+		Makers: []*order.TranscriptOrder{
+			{ID: targetID, Type: order.LimitOrderType, Quantity: 2e8, Rate: 1e6, Force: order.StandingTiF, Filled: 1e8},
+		},
+		Matches: []*order.TranscriptMatch{
+			{Taker: order.OrderID{0x03}, Maker: targetID, Quantity: 1e8, Rate: 1e6},
+			{Taker: order.OrderID{0x04}, Maker: targetID},
+		},
+	}
+
+	resp, err := rig.api.handleEpochTranscript(&msgjson.EpochTranscriptRequest{
+		BaseID:  42,
+		QuoteID: 0,
+		Epoch:   100,
+	})
+	if err != nil {
+		t.Fatalf("handleEpochTranscript error: %v", err)
+	}
+	if rig.db.epochDur != 1000 {
+		t.Fatalf("market epoch duration not used. wanted 1000, got %d", rig.db.epochDur)
+	}
+	tr := resp.(*msgjson.EpochTranscript)
+	if tr.MarketID != "dcr_btc" || tr.Epoch != 100 || tr.Duration != 1000 || tr.LotSize != 1e8 {
+		t.Fatalf("wrong transcript header %+v", tr)
+	}
+	if len(tr.Queue) != 2 || len(tr.Preimages) != 2 || len(tr.Makers) != 1 || len(tr.Matches) != 2 {
+		t.Fatalf("wrong transcript lengths")
+	}
+	if tr.Queue[0].Side != msgjson.SellOrderNum || tr.Queue[0].TiF != msgjson.ImmediateOrderNum ||
+		tr.Queue[0].OrderType != msgjson.LimitOrderNum || len(tr.Queue[0].TargetID) != 0 {
+		t.Fatalf("wrong limit order conversion %+v", tr.Queue[0])
+	}
+	if tr.Queue[1].OrderType != msgjson.CancelOrderNum || !bytes.Equal(tr.Queue[1].TargetID, targetID[:]) {
+		t.Fatalf("wrong cancel order conversion %+v", tr.Queue[1])
+	}
+	if tr.Makers[0].Side != msgjson.BuyOrderNum || tr.Makers[0].TiF != msgjson.StandingOrderNum || tr.Makers[0].Filled != 1e8 {
+		t.Fatalf("wrong maker conversion %+v", tr.Makers[0])
+	}
+
+	// Explicit duration.
+	_, err = rig.api.handleEpochTranscript(&msgjson.EpochTranscriptRequest{
+		BaseID:   42,
+		QuoteID:  0,
+		Epoch:    100,
+		Duration: 2000,
+	})
+	if err != nil {
+		t.Fatalf("handleEpochTranscript error: %v", err)
+	}
+	if rig.db.epochDur != 2000 {
+		t.Fatalf("requested epoch duration not used. wanted 2000, got %d", rig.db.epochDur)
+	}
+
+	// Unknown market.
+	if _, err := rig.api.handleEpochTranscript(&msgjson.EpochTranscriptRequest{BaseID: 0, QuoteID: 42}); err == nil {
+		t.Fatalf("no error for unknown market")
+	}
+
+	// Unknown epoch.
+	rig.db.transcriptErr = db.ArchiveError{Code: db.ErrUnknownEpoch}
+	if _, err := rig.api.handleEpochTranscript(&msgjson.EpochTranscriptRequest{BaseID: 42, QuoteID: 0}); err == nil {
+		t.Fatalf("no error for unknown epoch")
+	}
+}
+
+func TestTicker(t *testing.T) {
+	rig := newTestRig()
+	dcrBTC := &TMarketSource{42, 0}
+	btcLTC := &TMarketSource{0, 2}
+	for _, mkt := range []*TMarketSource{dcrBTC, btcLTC} {
+		if err := rig.api.AddMarketSource(mkt); err != nil {
+			t.Fatalf("AddMarketSource error: %v", err)
+		}
+	}
+	epoch := uint64(time.Now().UnixMilli()) / dcrBTC.EpochDuration()
+	_, err := rig.api.ReportEpoch(42, 0, epoch-1, &matcher.MatchCycleStats{
+		MatchVolume: 100,
+		HighRate:    12,
+		LowRate:     8,
+		StartRate:   10,
+		EndRate:     11,
+	})
+	if err != nil {
+		t.Fatalf("ReportEpoch error: %v", err)
+	}
+	_, err = rig.api.ReportEpoch(42, 0, epoch, &matcher.MatchCycleStats{
+		MatchVolume: 50,
+		HighRate:    16,
+		LowRate:     11,
+		StartRate:   11,
+		EndRate:     15,
+	})
+	if err != nil {
+		t.Fatalf("ReportEpoch error: %v", err)
+	}
+
+	tickersI, err := rig.api.handleTicker(nil)
+	if err != nil {
+		t.Fatalf("handleTicker error: %v", err)
+	}
+	tickers := tickersI.([]*msgjson.Ticker)
+	if len(tickers) != 2 {
+		t.Fatalf("expected 2 tickers, got %d", len(tickers))
+	}
+	// Sorted by market name.
+	btcLTCTicker, dcrBTCTicker := tickers[0], tickers[1]
+	if btcLTCTicker.Market != "btc_ltc" || dcrBTCTicker.Market != "dcr_btc" {
+		t.Fatalf("wrong markets %q, %q", btcLTCTicker.Market, dcrBTCTicker.Market)
+	}
+	if *btcLTCTicker != (msgjson.Ticker{Market: "btc_ltc", BaseID: 0, QuoteID: 2}) {
+		t.Fatalf("btc_ltc ticker has data: %+v", btcLTCTicker)
+	}
+	if dcrBTCTicker.BaseID != 42 || dcrBTCTicker.QuoteID != 0 {
+		t.Fatalf("wrong dcr_btc asset IDs %d, %d", dcrBTCTicker.BaseID, dcrBTCTicker.QuoteID)
+	}
+	if dcrBTCTicker.Rate != 15 {
+		t.Fatalf("wrong rate. wanted 15, got %d", dcrBTCTicker.Rate)
+	}
+	if dcrBTCTicker.High24 != 16 || dcrBTCTicker.Low24 != 8 {
+		t.Fatalf("wrong high/low. wanted 16/8, got %d/%d", dcrBTCTicker.High24, dcrBTCTicker.Low24)
+	}
+	if dcrBTCTicker.Vol24 != 150 {
+		t.Fatalf("wrong volume. wanted 150, got %d", dcrBTCTicker.Vol24)
+	}
+	if dcrBTCTicker.Change24 != 0.5 {
+		t.Fatalf("wrong change. wanted 0.5, got %f", dcrBTCTicker.Change24)
+	}
+}
@@ -24,6 +24,13 @@ func (d *Driver) Setup(configPath string, logger dex.Logger, network dex.Network
 	return NewBackend(configPath, logger, network)
 }
 
+// SetupFailover creates a BCH backend that fails over between the nodes
+// configured by the config files, in order of preference. Part of the
+// asset.FailoverDriver interface.
+func (d *Driver) SetupFailover(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend(configPaths, logger, network)
+}
+
 // Version returns the Backend implementation's version number.
 func (d *Driver) Version() uint32 {
 	return version
@@ -54,6 +61,14 @@ const (
 // NewBackend generates the network parameters and creates a bch backend as a
 // btc clone using an asset/btc helper function.
 func NewBackend(configPath string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend([]string{configPath}, logger, network)
+}
+
+// newBackend creates a backend for the nodes configured by the config files,
+// in order of preference. The first config path can be an empty string, in
+// which case the standard system location of the config file is assumed.
+func newBackend(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	configPath := configPaths[0]
 	var params *chaincfg.Params
 	switch network {
 	case dex.Mainnet:
@@ -80,15 +95,16 @@ func NewBackend(configPath string, logger dex.Logger, network dex.Network) (asse
 	}
 
 	be, err := btc.NewBTCClone(&btc.BackendCloneConfig{
-		Name:             assetName,
-		Segwit:           false,
-		ConfigPath:       configPath,
-		AddressDecoder:   dexbch.DecodeCashAddress,
-		Logger:           logger,
-		Net:              network,
-		ChainParams:      params,
-		Ports:            ports,
-		DumbFeeEstimates: true,
+		Name:                assetName,
+		Segwit:              false,
+		ConfigPath:          configPath,
+		FailoverConfigPaths: configPaths[1:],
+		AddressDecoder:      dexbch.DecodeCashAddress,
+		Logger:              logger,
+		Net:                 network,
+		ChainParams:         params,
+		Ports:               ports,
+		DumbFeeEstimates:    true,
 		// Bitcoin cash actually has getblockstats, but the RPC returns floats
 		// in units of BCH/byte.
 		ManualMedianFee:      true,
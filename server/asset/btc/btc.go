@@ -19,6 +19,7 @@ import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/config"
 	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/asset"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
@@ -39,6 +40,13 @@ func (d *Driver) Setup(configPath string, logger dex.Logger, network dex.Network
 	return NewBackend(configPath, logger, network)
 }
 
+// SetupFailover creates a BTC backend that fails over between the nodes
+// configured by the config files, in order of preference. Part of the
+// asset.FailoverDriver interface.
+func (d *Driver) SetupFailover(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend(configPaths, logger, network)
+}
+
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Bitcoin.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
@@ -113,7 +121,10 @@ func netParams(network dex.Network) (*chaincfg.Params, error) {
 // provides exported methods for DEX-related blockchain info.
 type Backend struct {
 	rpcCfg *dexbtc.RPCConfig
-	cfg    *BackendCloneConfig
+	// failoverCfgs are the RPC configurations of the failover nodes, in order
+	// of preference.
+	failoverCfgs []*dexbtc.RPCConfig
+	cfg          *BackendCloneConfig
 	// The asset name (e.g. btc), primarily for logging purposes.
 	name string
 	// segwit should be set to true for blockchains that support segregated
@@ -121,6 +132,9 @@ type Backend struct {
 	segwit bool
 	// node is used throughout for RPC calls. For testing, it can be set to a stub.
 	node *RPCClient
+	// failover is the node's requester. It monitors the health of the primary
+	// node and any failover nodes.
+	failover *failoverRequester
 	// The block cache stores just enough info about the blocks to shortcut future
 	// calls to GetBlockVerbose.
 	blockCache *blockCache
@@ -145,6 +159,10 @@ type Backend struct {
 	initTxSize     uint32
 	initTxSizeBase uint32
 
+	// feeRates is the fee rate estimation chain: estimate(smart)fee, then the
+	// median fee rate of recent blocks, then the configured floor.
+	feeRates *asset.FeeRateChain
+
 	// The feeCache prevents repeated calculations of the median fee rate
 	// between block changes when estimate(smart)fee is unprimed.
 	feeCache struct {
@@ -161,23 +179,33 @@ var _ asset.Backend = (*Backend)(nil)
 // backend. The configPath can be an empty string, in which case the standard
 // system location of the bitcoind config file is assumed.
 func NewBackend(configPath string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend([]string{configPath}, logger, network)
+}
+
+// newBackend creates a backend for the nodes configured by the config files,
+// in order of preference. The first config path can be an empty string, in
+// which case the standard system location of the bitcoind config file is
+// assumed.
+func newBackend(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
 	params, err := netParams(network)
 	if err != nil {
 		return nil, err
 	}
 
+	configPath := configPaths[0]
 	if configPath == "" {
 		configPath = dexbtc.SystemConfigPath("bitcoin")
 	}
 
 	return NewBTCClone(&BackendCloneConfig{
-		Name:        assetName,
-		Segwit:      true,
-		ConfigPath:  configPath,
-		Logger:      logger,
-		Net:         network,
-		ChainParams: params,
-		Ports:       dexbtc.RPCPorts,
+		Name:                assetName,
+		Segwit:              true,
+		ConfigPath:          configPath,
+		FailoverConfigPaths: configPaths[1:],
+		Logger:              logger,
+		Net:                 network,
+		ChainParams:         params,
+		Ports:               dexbtc.RPCPorts,
 	})
 }
 
@@ -215,7 +243,7 @@ func newBTC(cloneCfg *BackendCloneConfig, rpcCfg *dexbtc.RPCConfig) *Backend {
 		}
 	}
 
-	return &Backend{
+	btc := &Backend{
 		rpcCfg:             rpcCfg,
 		cfg:                cloneCfg,
 		name:               cloneCfg.Name,
@@ -233,19 +261,28 @@ func newBTC(cloneCfg *BackendCloneConfig, rpcCfg *dexbtc.RPCConfig) *Backend {
 		initTxSize:         initTxSize,
 		initTxSizeBase:     initTxSizeBase,
 	}
+	btc.feeRates = asset.NewFeeRateChain(cloneCfg.Logger,
+		&asset.FeeEstimator{Name: "node", Estimate: btc.nodeFeeRate},
+		&asset.FeeEstimator{Name: "median", Estimate: btc.medianFeeRate},
+	)
+	return btc
 }
 
 // BackendCloneConfig captures the arguments necessary to configure a BTC clone
 // backend.
 type BackendCloneConfig struct {
-	Name           string
-	Segwit         bool
-	ConfigPath     string
-	AddressDecoder dexbtc.AddressDecoder
-	Logger         dex.Logger
-	Net            dex.Network
-	ChainParams    *chaincfg.Params
-	Ports          dexbtc.NetPorts
+	Name       string
+	Segwit     bool
+	ConfigPath string
+	// FailoverConfigPaths are the paths to the config files of additional nodes
+	// that are used, in order of preference, when the node configured by
+	// ConfigPath is unhealthy.
+	FailoverConfigPaths []string
+	AddressDecoder      dexbtc.AddressDecoder
+	Logger              dex.Logger
+	Net                 dex.Network
+	ChainParams         *chaincfg.Params
+	Ports               dexbtc.NetPorts
 	// ManualFeeScan specifies that median block fees should be calculated by
 	// scanning transactions since the getblockstats rpc is not available.
 	// Median block fees are used to estimate fee rates when the cache is not
@@ -296,16 +333,31 @@ type BackendCloneConfig struct {
 // See ReadCloneParams and CompatibilityCheck for more info.
 func NewBTCClone(cloneCfg *BackendCloneConfig) (*Backend, error) {
 	// Read the configuration parameters
-	cfg := new(dexbtc.RPCConfig)
-	err := config.ParseInto(cloneCfg.ConfigPath, cfg)
-	if err != nil {
-		return nil, err
+	readConfig := func(configPath string) (*dexbtc.RPCConfig, error) {
+		cfg := new(dexbtc.RPCConfig)
+		err := config.ParseInto(configPath, cfg)
+		if err != nil {
+			return nil, err
+		}
+		err = dexbtc.CheckRPCConfig(cfg, cloneCfg.Name, cloneCfg.Net, cloneCfg.Ports)
+		if err != nil {
+			return nil, err
+		}
+		return cfg, nil
 	}
-	err = dexbtc.CheckRPCConfig(cfg, cloneCfg.Name, cloneCfg.Net, cloneCfg.Ports)
+	cfg, err := readConfig(cloneCfg.ConfigPath)
 	if err != nil {
 		return nil, err
 	}
-	return newBTC(cloneCfg, cfg), nil
+	btc := newBTC(cloneCfg, cfg)
+	for _, configPath := range cloneCfg.FailoverConfigPaths {
+		cfg, err := readConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading failover config %q: %w", configPath, err)
+		}
+		btc.failoverCfgs = append(btc.failoverCfgs, cfg)
+	}
+	return btc, nil
 }
 
 func (btc *Backend) shutdown() {
@@ -317,17 +369,29 @@ func (btc *Backend) shutdown() {
 
 // Connect connects to the node RPC server. A dex.Connector.
 func (btc *Backend) Connect(ctx context.Context) (*sync.WaitGroup, error) {
-	client, err := rpcclient.New(&rpcclient.ConnConfig{
-		HTTPPostMode: true,
-		DisableTLS:   true,
-		Host:         btc.rpcCfg.RPCBind,
-		User:         btc.rpcCfg.RPCUser,
-		Pass:         btc.rpcCfg.RPCPass,
-	}, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating %q RPC client: %w", btc.name, err)
+	cfgs := append([]*dexbtc.RPCConfig{btc.rpcCfg}, btc.failoverCfgs...)
+	clients := make([]RawRequester, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		client, err := rpcclient.New(&rpcclient.ConnConfig{
+			HTTPPostMode: true,
+			DisableTLS:   true,
+			Host:         cfg.RPCBind,
+			User:         cfg.RPCUser,
+			Pass:         cfg.RPCPass,
+		}, nil)
+		if err != nil {
+			for _, client := range clients {
+				client.Shutdown()
+			}
+			return nil, fmt.Errorf("error creating %q RPC client for %s: %w", btc.name, cfg.RPCBind, err)
+		}
+		clients = append(clients, client)
 	}
 
+	// Requests go through the failover requester even without failover nodes
+	// so that the primary node's health is still monitored.
+	btc.failover = newFailoverRequester(btc.log, clients)
+
 	maxFeeBlocks := btc.cfg.MaxFeeBlocks
 	if maxFeeBlocks == 0 {
 		maxFeeBlocks = defaultMaxFeeBlocks
@@ -345,7 +409,7 @@ func (btc *Backend) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 
 	btc.node = &RPCClient{
 		ctx:                  ctx,
-		requester:            client,
+		requester:            btc.failover,
 		booleanGetBlockRPC:   btc.booleanGetBlockRPC,
 		maxFeeBlocks:         maxFeeBlocks,
 		arglessFeeEstimates:  btc.cfg.ArglessFeeEstimates,
@@ -368,22 +432,29 @@ func (btc *Backend) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 		}
 	}
 
-	if txindex, err := btc.node.checkTxIndex(); err != nil {
-		if !isMethodNotFoundErr(err) {
+	// Every node must have a transaction index, so check each of them directly.
+	for i, client := range clients {
+		node := *btc.node
+		node.requester = client
+		if txindex, err := node.checkTxIndex(); err != nil {
+			if !isMethodNotFoundErr(err) {
+				btc.shutdown()
+				return nil, fmt.Errorf("%s getindexinfo check failed for %s: %w", btc.name, cfgs[i].RPCBind, err)
+			}
+			// Ignore and log err if getindexinfo method is not found.
+			// getindexinfo method is not currently supported by
+			// pre 0.21 versions of bitcoind, and some forks of
+			// bitcoin core (litecoin).
+			btc.log.Warnf("The getindexinfo RPC is unavailable at %s. Please ensure txindex is enabled in the node config.",
+				cfgs[i].RPCBind)
+		} else if !txindex {
 			btc.shutdown()
-			return nil, fmt.Errorf("%s getindexinfo check failed: %w", btc.name, err)
+			return nil, fmt.Errorf("%s transaction index is not enabled at %s. Please enable txindex in the node config",
+				btc.name, cfgs[i].RPCBind)
 		}
-		// Ignore and log err if getindexinfo method is not found.
-		// getindexinfo method is not currently supported by
-		// pre 0.21 versions of bitcoind, and some forks of
-		// bitcoin core (litecoin).
-		btc.log.Warnf("The getindexinfo RPC is unavailable. Please ensure txindex is enabled in the node config.")
-	} else if !txindex {
-		btc.shutdown()
-		return nil, fmt.Errorf("%s transaction index is not enabled. Please enable txindex in the node config", btc.name)
 	}
 
-	if _, err = btc.estimateFee(ctx); err != nil {
+	if _, err = btc.FeeRate(ctx); err != nil {
 		btc.log.Warnf("Backend started without fee estimation available: %v", err)
 	}
 
@@ -393,9 +464,25 @@ func (btc *Backend) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 		defer wg.Done()
 		btc.run(ctx)
 	}()
+	btc.failover.CheckHealth(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		btc.failover.Run(ctx, asset.DefaultHealthCheckInterval)
+	}()
 	return &wg, nil
 }
 
+// Healthy is true if at least one of the backend's nodes is healthy. A
+// backend that is not connected is considered healthy. Part of the
+// asset.HealthReporter interface.
+func (btc *Backend) Healthy() bool {
+	if btc.failover == nil {
+		return true
+	}
+	return btc.failover.Healthy()
+}
+
 // Net returns the *chaincfg.Params. This is not part of the asset.Backend
 // interface, and is exported as a convenience for embedding types.
 func (btc *Backend) Net() *chaincfg.Params {
@@ -539,6 +626,93 @@ func (btc *Backend) FeeCoin(coinID []byte) (addr string, val uint64, confs int64
 	return
 }
 
+// BondVer returns the latest supported bond version. Part of the asset.Bonder
+// interface.
+func (btc *Backend) BondVer() uint16 {
+	return dexbtc.BondVersion
+}
+
+// BondCoin locates a bond transaction output, validates the entire transaction,
+// and returns the amount, lock time, and confirmations of the bond, as well as
+// the account ID to which the bond commits. The bond output must be a P2WSH (or
+// P2SH if the asset is not segwit) output paying to a bond script that is
+// described by a nulldata commitment output in the same transaction. Part of
+// the asset.Bonder interface.
+func (btc *Backend) BondCoin(ctx context.Context, ver uint16, coinID []byte) (amt, lockTime, confs int64, acct account.AccountID, err error) {
+	if ver != dexbtc.BondVersion {
+		err = fmt.Errorf("unsupported bond version %d", ver)
+		return
+	}
+	txHash, vout, errCoin := decodeCoinID(coinID)
+	if errCoin != nil {
+		err = fmt.Errorf("error decoding coin ID %x: %w", coinID, errCoin)
+		return
+	}
+
+	verboseTx, err := btc.node.GetRawTransactionVerbose(txHash)
+	if err != nil {
+		if isTxNotFoundErr(err) {
+			err = asset.CoinNotFoundError
+		}
+		return
+	}
+	if int(vout) > len(verboseTx.Vout)-1 {
+		err = asset.CoinNotFoundError
+		return
+	}
+
+	bondOut := verboseTx.Vout[vout]
+	bondPkScript, err := hex.DecodeString(bondOut.ScriptPubKey.Hex)
+	if err != nil {
+		err = dex.UnsupportedScriptError
+		return
+	}
+	scriptType := dexbtc.ParseScriptType(bondPkScript, nil)
+	if (btc.segwit && !scriptType.IsP2WSH()) || (!btc.segwit && !scriptType.IsP2SH()) {
+		err = dex.UnsupportedScriptError
+		return
+	}
+	scriptHash := dexbtc.ExtractScriptHash(bondPkScript)
+
+	// Find the account commitment, and ensure it describes the bond script.
+	var found bool
+	for _, out := range verboseTx.Vout {
+		pkScript, errDec := hex.DecodeString(out.ScriptPubKey.Hex)
+		if errDec != nil {
+			continue
+		}
+		bondVer, acctID, lt, pkh, errCommit := dexbtc.ExtractBondCommitDataV0(pkScript)
+		if errCommit != nil {
+			continue
+		}
+		var bondScript []byte
+		bondScript, err = dexbtc.MakeBondScript(bondVer, lt, pkh[:])
+		if err != nil {
+			return
+		}
+		var expHash []byte
+		if btc.segwit {
+			h := sha256.Sum256(bondScript)
+			expHash = h[:]
+		} else {
+			expHash = btcutil.Hash160(bondScript)
+		}
+		if !bytes.Equal(expHash, scriptHash) {
+			continue
+		}
+		acct, lockTime, found = acctID, int64(lt), true
+		break
+	}
+	if !found {
+		err = fmt.Errorf("no bond commitment found for bond output %s:%d", txHash, vout)
+		return
+	}
+
+	amt = int64(toSat(bondOut.Value))
+	confs = int64(verboseTx.Confirmations)
+	return
+}
+
 // txOutData is transaction output data, including recipient addresses, value,
 // script type, and number of required signatures.
 type txOutData struct {
@@ -613,7 +787,14 @@ func (btc *Backend) InitTxSizeBase() uint32 {
 
 // FeeRate returns the current optimal fee rate in sat / byte.
 func (btc *Backend) FeeRate(ctx context.Context) (uint64, error) {
-	return btc.estimateFee(ctx)
+	return btc.feeRates.FeeRate(ctx)
+}
+
+// ConfigureFeeRate sets the fallback fee rate floor, the rate limit, and
+// smoothing of the fee rate estimates. Part of the asset.FeeRateConfigurer
+// interface.
+func (btc *Backend) ConfigureFeeRate(cfg *asset.FeeRateConfig) error {
+	return btc.feeRates.Configure(cfg)
 }
 
 // Info provides some general information about the backend.
@@ -1202,30 +1383,32 @@ out:
 	}
 }
 
-// estimateFee attempts to get a reasonable tx fee rates (units: atomic/(v)byte)
-// to use for the asset by checking estimate(smart)fee. That call can fail or
-// otherwise be useless on an otherwise perfectly functioning node. In that
-// case, an estimate is calculated from the median fees of the previous
-// block(s).
-func (btc *Backend) estimateFee(ctx context.Context) (satsPerB uint64, err error) {
+// nodeFeeRate gets a fee rate estimate (units: atomic/(v)byte) from
+// estimate(smart)fee. That call can fail or otherwise be useless on an
+// otherwise perfectly functioning node, in which case medianFeeRate is the
+// next estimator in the chain.
+func (btc *Backend) nodeFeeRate(context.Context) (satsPerB uint64, err error) {
 	if btc.cfg.DumbFeeEstimates {
 		satsPerB, err = btc.node.EstimateFee(btc.feeConfs)
 	} else {
 		satsPerB, err = btc.node.EstimateSmartFee(btc.feeConfs, &btcjson.EstimateModeConservative)
 	}
-	if err == nil && satsPerB > 0 {
-		return satsPerB, nil
-	} else if err != nil && !errors.Is(err, errNoFeeRate) {
+	if err != nil && !errors.Is(err, errNoFeeRate) {
 		btc.log.Debugf("Estimate fee failure: %v", err)
 	}
+	return satsPerB, err
+}
 
+// medianFeeRate calculates a fee rate estimate from the median fees of the
+// previous block(s). The estimate is cached until the next block.
+func (btc *Backend) medianFeeRate(ctx context.Context) (satsPerB uint64, err error) {
 	tip := btc.blockCache.tipHash()
 
 	btc.feeCache.Lock()
 	defer btc.feeCache.Unlock()
 
 	// If the current block hasn't changed, no need to recalc.
-	if btc.feeCache.hash == tip {
+	if btc.feeCache.hash == tip && btc.feeCache.fee > 0 {
 		return btc.feeCache.fee, nil
 	}
 
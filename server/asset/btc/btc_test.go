@@ -25,6 +25,8 @@ import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/config"
 	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/asset"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcjson"
@@ -298,8 +300,10 @@ func cleanTestChain() {
 
 // A stub to replace rpcclient.Client for offline testing.
 type testNode struct {
-	rawResult []byte
-	rawErr    error
+	rawResult   []byte
+	rawErr      error
+	estimateErr error
+	blockStats  []byte
 }
 
 // Encode utxo info as a concatenated string hash:vout.
@@ -370,7 +374,15 @@ func (t *testNode) RawRequest(_ context.Context, method string, params []json.Ra
 		out := testChain.txOuts[outID]
 		// Unfound is not an error for GetTxOut.
 		return json.Marshal(out)
+	case methodGetBlockStats:
+		if t.blockStats == nil {
+			return nil, fmt.Errorf("no block stats")
+		}
+		return t.blockStats, nil
 	case methodEstimateSmartFee:
+		if t.estimateErr != nil {
+			return nil, t.estimateErr
+		}
 		const optimalFeeRate uint64 = 24
 		optimalRate := float64(optimalFeeRate) * 1e-5
 		return json.Marshal(&btcjson.EstimateSmartFeeResult{
@@ -1394,6 +1406,87 @@ func TestAuxiliary(t *testing.T) {
 	}
 }
 
+func TestBondCoin(t *testing.T) {
+	for _, segwit := range []bool{true, false} {
+		testBondCoin(t, segwit)
+	}
+}
+
+func testBondCoin(t *testing.T, segwit bool) {
+	btc, shutdown := testBackend(segwit)
+	defer shutdown()
+	cleanTestChain()
+
+	pkh := randomBytes(20)
+	var acctID account.AccountID
+	copy(acctID[:], randomBytes(32))
+	lockTime := uint32(time.Now().Add(time.Hour).Unix())
+	bondScript, _ := dexbtc.MakeBondScript(0, lockTime, pkh)
+	var bondAddr btcutil.Address
+	if segwit {
+		h := sha256.Sum256(bondScript)
+		bondAddr, _ = btcutil.NewAddressWitnessScriptHash(h[:], testParams)
+	} else {
+		bondAddr, _ = btcutil.NewAddressScriptHash(bondScript, testParams)
+	}
+	bondPkScript, _ := txscript.PayToAddrScript(bondAddr)
+	commitScript, _ := dexbtc.MakeBondCommitScript(0, acctID[:], lockTime, pkh)
+
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(randomHash(), 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(2e8, bondPkScript))
+	msgTx.AddTxOut(wire.NewTxOut(0, commitScript))
+	txHash := msgTx.TxHash()
+	blockHash := testAddBlockVerbose(nil, nil, 1, 100)
+	testChainMtx.Lock()
+	verboseTx := testAddTxVerbose(msgTx, &txHash, blockHash, 2)
+	for i, txOut := range msgTx.TxOut {
+		verboseTx.Vout = append(verboseTx.Vout, btcjson.Vout{
+			Value: btcutil.Amount(txOut.Value).ToBTC(),
+			N:     uint32(i),
+			ScriptPubKey: btcjson.ScriptPubKeyResult{
+				Hex: hex.EncodeToString(txOut.PkScript),
+			},
+		})
+	}
+	testChainMtx.Unlock()
+
+	ctx := context.Background()
+	amt, lt, confs, acct, err := btc.BondCoin(ctx, 0, toCoinID(&txHash, 0))
+	if err != nil {
+		t.Fatalf("BondCoin error (segwit = %t): %v", segwit, err)
+	}
+	if amt != 2e8 {
+		t.Fatalf("wrong bond amount %d", amt)
+	}
+	if lt != int64(lockTime) {
+		t.Fatalf("wrong lock time %d", lt)
+	}
+	if confs != 2 {
+		t.Fatalf("wrong confs %d", confs)
+	}
+	if acct != acctID {
+		t.Fatalf("wrong account ID %v", acct)
+	}
+
+	// Not a script hash output.
+	if _, _, _, _, err = btc.BondCoin(ctx, 0, toCoinID(&txHash, 1)); err == nil {
+		t.Fatalf("no error for commitment output")
+	}
+	// Missing output.
+	if _, _, _, _, err = btc.BondCoin(ctx, 0, toCoinID(&txHash, 2)); !errors.Is(err, asset.CoinNotFoundError) {
+		t.Fatalf("expected CoinNotFoundError, got %v", err)
+	}
+	// Commitment to a different lock time.
+	badCommit, _ := dexbtc.MakeBondCommitScript(0, acctID[:], lockTime+1, pkh)
+	testChainMtx.Lock()
+	verboseTx.Vout[1].ScriptPubKey.Hex = hex.EncodeToString(badCommit)
+	testChainMtx.Unlock()
+	if _, _, _, _, err = btc.BondCoin(ctx, 0, toCoinID(&txHash, 0)); err == nil {
+		t.Fatalf("no error for mismatched bond commitment")
+	}
+}
+
 // TestCheckAddress checks that addresses are parsing or not parsing as
 // expected.
 func TestCheckAddress(t *testing.T) {
@@ -1504,3 +1597,48 @@ func TestSynced(t *testing.T) {
 	}
 	tNode.rawErr = nil
 }
+
+func TestFeeRate(t *testing.T) {
+	btc, shutdown := testBackend(true)
+	defer shutdown()
+	tNode := btc.node.requester.(*testNode)
+	btc.node.maxFeeBlocks = 1
+	cleanTestChain()
+	defer cleanTestChain()
+	testAddBlockVerbose(nil, nil, 1, 1)
+
+	checkRate := func(tag string, cfg *asset.FeeRateConfig, wantRate uint64, wantErr bool) {
+		t.Helper()
+		if err := btc.ConfigureFeeRate(cfg); err != nil {
+			t.Fatalf("%s: ConfigureFeeRate error: %v", tag, err)
+		}
+		rate, err := btc.FeeRate(context.Background())
+		if (err != nil) != wantErr {
+			t.Fatalf("%s: wantErr = %t, err = %v", tag, wantErr, err)
+		}
+		if rate != wantRate {
+			t.Fatalf("%s: wanted rate %d, got %d", tag, wantRate, rate)
+		}
+	}
+
+	// estimatesmartfee
+	checkRate("node estimate", &asset.FeeRateConfig{}, 24, false)
+	checkRate("max fee rate", &asset.FeeRateConfig{MaxFeeRate: 20}, 20, false)
+
+	// The median fee rate of recent blocks.
+	tNode.estimateErr = errors.New("test error")
+	tNode.blockStats, _ = json.Marshal(&struct {
+		FeeRatePercentiles []uint64 `json:"feerate_percentiles"`
+		TxCount            int      `json:"txs"`
+	}{
+		FeeRatePercentiles: []uint64{5, 10, 15, 20, 25},
+		TxCount:            200,
+	})
+	checkRate("median", &asset.FeeRateConfig{}, 15, false)
+
+	// The configured floor.
+	btc.feeCache.fee = 0
+	tNode.blockStats = nil
+	checkRate("no floor", &asset.FeeRateConfig{}, 0, true)
+	checkRate("floor", &asset.FeeRateConfig{Floor: 12}, 12, false)
+}
@@ -0,0 +1,81 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package btc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/asset"
+	"github.com/decred/dcrd/dcrjson/v4"
+)
+
+// maxTipLag is the number of blocks that a node can be behind the best tip
+// reported by the other nodes before it is considered unhealthy.
+const maxTipLag = 2
+
+// failoverRequester is a RawRequester that sends requests to the active node
+// of a prioritized list of nodes, failing over to the next healthy node if a
+// request fails with a node error.
+type failoverRequester struct {
+	*asset.Failover
+	nodes []RawRequester
+}
+
+var _ RawRequester = (*failoverRequester)(nil)
+
+func newFailoverRequester(log dex.Logger, nodes []RawRequester) *failoverRequester {
+	tips := make([]asset.NodeTipper, 0, len(nodes))
+	for _, node := range nodes {
+		rc := &RPCClient{requester: node}
+		tips = append(tips, func(ctx context.Context) (int64, error) {
+			rc.ctx = ctx
+			chainInfo, err := rc.GetBlockChainInfo()
+			if err != nil {
+				return 0, err
+			}
+			return chainInfo.Blocks, nil
+		})
+	}
+	return &failoverRequester{
+		Failover: asset.NewFailover(log, maxTipLag, tips...),
+		nodes:    nodes,
+	}
+}
+
+// RawRequest sends the request to the active node. Part of the RawRequester
+// interface.
+func (r *failoverRequester) RawRequest(ctx context.Context, method string, params []json.RawMessage) (json.RawMessage, error) {
+	var res json.RawMessage
+	err := r.Do(ctx, func(i int) (err error) {
+		res, err = r.nodes[i].RawRequest(ctx, method, params)
+		return err
+	}, isNodeErr)
+	return res, err
+}
+
+// Shutdown shuts down the connections to all nodes. Part of the RawRequester
+// interface.
+func (r *failoverRequester) Shutdown() {
+	for _, node := range r.nodes {
+		node.Shutdown()
+	}
+}
+
+// WaitForShutdown waits for the connections to all nodes to shut down. Part of
+// the RawRequester interface.
+func (r *failoverRequester) WaitForShutdown() {
+	for _, node := range r.nodes {
+		node.WaitForShutdown()
+	}
+}
+
+// isNodeErr is true if the error is not an error response from the node,
+// indicating a connection problem.
+func isNodeErr(err error) bool {
+	var rpcErr *dcrjson.RPCError
+	return !errors.As(err, &rpcErr)
+}
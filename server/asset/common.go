@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/account"
 )
 
 // Addresser retrieves unique addresses.
@@ -117,6 +118,18 @@ type AccountBalancer interface {
 	RedeemSize() uint64
 }
 
+// Bonder is implemented by backends that support fidelity bonds.
+type Bonder interface {
+	// BondVer returns the latest supported bond version.
+	BondVer() uint16
+	// BondCoin locates a bond transaction output, validates the entire
+	// transaction, and returns the amount, lock time, and confirmations of the
+	// bond, as well as the account ID to which the bond commits. The lock time
+	// is a UNIX timestamp in seconds. If the coin is not found, an
+	// asset.CoinNotFoundError is returned.
+	BondCoin(ctx context.Context, ver uint16, coinID []byte) (amt, lockTime, confs int64, acct account.AccountID, err error)
+}
+
 // TokenBacker is implemented by Backends that support degenerate tokens.
 type TokenBacker interface {
 	TokenBackend(assetID uint32, configPath string) (Backend, error)
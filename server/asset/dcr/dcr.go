@@ -13,12 +13,14 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"decred.org/dcrdex/dex"
 	dexdcr "decred.org/dcrdex/dex/networks/dcr"
+	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/asset"
 	"github.com/decred/dcrd/blockchain/stake/v4"
 	"github.com/decred/dcrd/chaincfg/chainhash"
@@ -47,6 +49,25 @@ func (d *Driver) Setup(configPath string, logger dex.Logger, network dex.Network
 	return NewBackend(configPath, logger, network)
 }
 
+// SetupFailover creates a DCR backend that fails over between the nodes
+// configured by the config files, in order of preference. Part of the
+// asset.FailoverDriver interface.
+func (d *Driver) SetupFailover(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	be, err := d.Setup(configPaths[0], logger, network)
+	if err != nil {
+		return nil, err
+	}
+	dcr := be.(*Backend)
+	for _, configPath := range configPaths[1:] {
+		cfg, err := loadConfig(configPath, network)
+		if err != nil {
+			return nil, fmt.Errorf("error reading failover config %q: %w", configPath, err)
+		}
+		dcr.failoverCfgs = append(dcr.failoverCfgs, cfg)
+	}
+	return dcr, nil
+}
+
 // DecodeCoinID creates a human-readable representation of a coin ID for Decred.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
 	txid, vout, err := decodeCoinID(coinID)
@@ -107,6 +128,14 @@ const (
 	BipID                    = 42
 	assetName                = "dcr"
 	immatureTransactionError = dex.ErrorKind("immature output")
+	errNoCompetition         = dex.ErrorKind("no competition")
+
+	// medianFeeTxs is the number of regular transactions from recent blocks
+	// that are sampled for the median fee rate.
+	medianFeeTxs = 101
+	// maxFeeBlocks is the maximum number of blocks that are scanned for the
+	// median fee rate.
+	maxFeeBlocks = 10
 )
 
 // dcrNode represents a blockchain information fetcher. In practice, it is
@@ -142,8 +171,15 @@ type Backend struct {
 	// If an rpcclient.Client is used for the node, keeping a reference at client
 	// will result in (Client).Shutdown() being called on context cancellation.
 	client *rpcclient.Client
-	// node is used throughout for RPC calls, and in typical use will be the same
-	// as client. For testing, it can be set to a stub.
+	// failoverCfgs are the configurations of the failover nodes, in order of
+	// preference, and failoverClients are their clients.
+	failoverCfgs    []*config
+	failoverClients []*rpcclient.Client
+	// failover wraps client and the failover clients, and monitors the health
+	// of the nodes.
+	failover *failoverNode
+	// node is used throughout for RPC calls, and in typical use will be
+	// failover. For testing, it can be set to a stub.
 	node dcrNode
 	// The backend provides block notification channels through it BlockChannel
 	// method. signalMtx locks the blockChans array.
@@ -155,6 +191,16 @@ type Backend struct {
 	// A logger will be provided by the DEX. All logging should use the provided
 	// logger.
 	log dex.Logger
+	// feeRates is the fee rate estimation chain: estimatesmartfee, then the
+	// median fee rate of recent blocks, then the configured floor.
+	feeRates *asset.FeeRateChain
+	// The feeCache prevents repeated calculations of the median fee rate
+	// between block changes.
+	feeCache struct {
+		sync.Mutex
+		fee  uint64
+		hash chainhash.Hash
+	}
 }
 
 // Check that Backend satisfies the Backend interface.
@@ -163,12 +209,17 @@ var _ asset.Backend = (*Backend)(nil)
 // unconnectedDCR returns a Backend without a node. The node should be set
 // before use.
 func unconnectedDCR(logger dex.Logger, cfg *config) *Backend {
-	return &Backend{
+	dcr := &Backend{
 		cfg:        cfg,
 		blockCache: newBlockCache(logger),
 		log:        logger,
 		blockChans: make(map[chan *asset.BlockUpdate]struct{}),
 	}
+	dcr.feeRates = asset.NewFeeRateChain(logger,
+		&asset.FeeEstimator{Name: "node", Estimate: dcr.nodeFeeRate},
+		&asset.FeeEstimator{Name: "median", Estimate: dcr.medianFeeRate},
+	)
+	return dcr
 }
 
 // NewBackend is the exported constructor by which the DEX will import the
@@ -186,29 +237,104 @@ func NewBackend(configPath string, logger dex.Logger, network dex.Network) (*Bac
 }
 
 func (dcr *Backend) shutdown() {
-	if dcr.client != nil {
-		dcr.client.Shutdown()
-		dcr.client.WaitForShutdown()
+	for _, client := range append([]*rpcclient.Client{dcr.client}, dcr.failoverClients...) {
+		if client != nil {
+			client.Shutdown()
+			client.WaitForShutdown()
+		}
 	}
 }
 
 // Connect connects to the node RPC server. A dex.Connector.
 func (dcr *Backend) Connect(ctx context.Context) (*sync.WaitGroup, error) {
-	client, err := connectNodeRPC(dcr.cfg.RPCListen, dcr.cfg.RPCUser, dcr.cfg.RPCPass, dcr.cfg.RPCCert)
+	client, err := dcr.connectNode(ctx, dcr.cfg)
 	if err != nil {
 		return nil, err
 	}
 	dcr.client = client
 
+	// Requests go through the failover node even without failover nodes so
+	// that the primary node's health is still monitored.
+	nodes := []dcrNode{client}
+	for _, cfg := range dcr.failoverCfgs {
+		client, err := dcr.connectNode(ctx, cfg)
+		if err != nil {
+			dcr.shutdown()
+			return nil, fmt.Errorf("failover node %s: %w", cfg.RPCListen, err)
+		}
+		dcr.failoverClients = append(dcr.failoverClients, client)
+		nodes = append(nodes, client)
+	}
+	dcr.failover = newFailoverNode(dcr.log, nodes)
+	dcr.node = dcr.failover
+
+	dcr.ctx = ctx
+
+	// Prime the cache with the best block.
+	bestHash, err := dcr.node.GetBestBlockHash(ctx)
+	if err != nil {
+		dcr.shutdown()
+		return nil, fmt.Errorf("error getting best block from dcrd: %w", err)
+	}
+	if bestHash != nil {
+		_, err := dcr.getDcrBlock(ctx, bestHash)
+		if err != nil {
+			dcr.shutdown()
+			return nil, fmt.Errorf("error priming the cache: %w", err)
+		}
+	}
+
+	if _, err = dcr.FeeRate(ctx); err != nil {
+		dcr.log.Warnf("Decred backend started without fee estimation available: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dcr.run(ctx)
+	}()
+	dcr.failover.CheckHealth(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dcr.failover.Run(ctx, asset.DefaultHealthCheckInterval)
+	}()
+	return &wg, nil
+}
+
+// Healthy is true if at least one of the backend's nodes is healthy. A
+// backend that is not connected is considered healthy. Part of the
+// asset.HealthReporter interface.
+func (dcr *Backend) Healthy() bool {
+	if dcr.failover == nil {
+		return true
+	}
+	return dcr.failover.Healthy()
+}
+
+// connectNode connects to the dcrd node configured by cfg, and checks that it
+// is on the expected network, has a compatible RPC version, and has the
+// transaction index enabled.
+func (dcr *Backend) connectNode(ctx context.Context, cfg *config) (*rpcclient.Client, error) {
+	client, err := connectNodeRPC(cfg.RPCListen, cfg.RPCUser, cfg.RPCPass, cfg.RPCCert)
+	if err != nil {
+		return nil, err
+	}
+	shutdown := func() {
+		client.Shutdown()
+		client.WaitForShutdown()
+	}
+
 	// Ensure the network of the connected node is correct for the expected
 	// dex.Network.
-	net, err := dcr.client.GetCurrentNet(ctx)
+	net, err := client.GetCurrentNet(ctx)
 	if err != nil {
-		dcr.shutdown()
+		shutdown()
 		return nil, fmt.Errorf("getcurrentnet failure: %w", err)
 	}
 	var wantCurrencyNet wire.CurrencyNet
-	switch dcr.cfg.Network {
+	switch cfg.Network {
 	case dex.Testnet:
 		wantCurrencyNet = wire.TestNet3
 	case dex.Mainnet:
@@ -217,70 +343,42 @@ func (dcr *Backend) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 		wantCurrencyNet = wire.SimNet
 	}
 	if net != wantCurrencyNet {
-		dcr.shutdown()
+		shutdown()
 		return nil, fmt.Errorf("wrong net %v", net.String())
 	}
 
 	// Check the required API versions.
-	versions, err := dcr.client.Version(ctx)
+	versions, err := client.Version(ctx)
 	if err != nil {
-		dcr.shutdown()
+		shutdown()
 		return nil, fmt.Errorf("DCR node version fetch error: %w", err)
 	}
 
 	ver, exists := versions["dcrdjsonrpcapi"]
 	if !exists {
-		dcr.shutdown()
+		shutdown()
 		return nil, fmt.Errorf("dcrd.Version response missing 'dcrdjsonrpcapi'")
 	}
 	nodeSemver := dex.NewSemver(ver.Major, ver.Minor, ver.Patch)
 	if !dex.SemverCompatible(requiredNodeVersion, nodeSemver) {
-		dcr.shutdown()
+		shutdown()
 		return nil, fmt.Errorf("dcrd has an incompatible JSON-RPC version: got %s, expected %s",
 			nodeSemver, requiredNodeVersion)
 	}
 
 	// Verify dcrd has tx index enabled (required for getrawtransaction).
-	info, err := dcr.client.GetInfo(ctx)
+	info, err := client.GetInfo(ctx)
 	if err != nil {
-		dcr.shutdown()
+		shutdown()
 		return nil, fmt.Errorf("dcrd getinfo check failed: %w", err)
 	}
 	if !info.TxIndex {
-		dcr.shutdown()
+		shutdown()
 		return nil, errors.New("dcrd does not have transaction index enabled (specify --txindex)")
 	}
 
-	dcr.log.Infof("Connected to dcrd (JSON-RPC API v%s) on %v", nodeSemver, net)
-
-	dcr.node = dcr.client
-	dcr.ctx = ctx
-
-	// Prime the cache with the best block.
-	bestHash, _, err := dcr.client.GetBestBlock(ctx)
-	if err != nil {
-		dcr.shutdown()
-		return nil, fmt.Errorf("error getting best block from dcrd: %w", err)
-	}
-	if bestHash != nil {
-		_, err := dcr.getDcrBlock(ctx, bestHash)
-		if err != nil {
-			dcr.shutdown()
-			return nil, fmt.Errorf("error priming the cache: %w", err)
-		}
-	}
-
-	if _, err = dcr.FeeRate(ctx); err != nil {
-		dcr.log.Warnf("Decred backend started without fee estimation available: %v", err)
-	}
-
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		dcr.run(ctx)
-	}()
-	return &wg, nil
+	dcr.log.Infof("Connected to dcrd at %s (JSON-RPC API v%s) on %v", cfg.RPCListen, nodeSemver, net)
+	return client, nil
 }
 
 // InitTxSize is an asset.Backend method that must produce the max size of a
@@ -296,6 +394,18 @@ func (dcr *Backend) InitTxSizeBase() uint32 {
 
 // FeeRate returns the current optimal fee rate in atoms / byte.
 func (dcr *Backend) FeeRate(ctx context.Context) (uint64, error) {
+	return dcr.feeRates.FeeRate(ctx)
+}
+
+// ConfigureFeeRate sets the fallback fee rate floor, the rate limit, and
+// smoothing of the fee rate estimates. Part of the asset.FeeRateConfigurer
+// interface.
+func (dcr *Backend) ConfigureFeeRate(cfg *asset.FeeRateConfig) error {
+	return dcr.feeRates.Configure(cfg)
+}
+
+// nodeFeeRate gets a fee rate estimate in atoms / byte from estimatesmartfee.
+func (dcr *Backend) nodeFeeRate(ctx context.Context) (uint64, error) {
 	// estimatesmartfee 1 returns extremely high rates on DCR.
 	estimateFeeResult, err := dcr.node.EstimateSmartFee(ctx, 2, chainjson.EstimateSmartFeeConservative)
 	if err != nil {
@@ -312,6 +422,83 @@ func (dcr *Backend) FeeRate(ctx context.Context) (uint64, error) {
 	return atomsPerB, nil
 }
 
+// medianFeeRate calculates the median fee rate, in atoms / byte, of the
+// regular transactions in recent blocks. Up to medianFeeTxs transactions from
+// as many as maxFeeBlocks blocks are considered. The estimate is cached until
+// the next block.
+func (dcr *Backend) medianFeeRate(ctx context.Context) (uint64, error) {
+	tip := dcr.blockCache.tipHash()
+
+	dcr.feeCache.Lock()
+	defer dcr.feeCache.Unlock()
+
+	// If the current block hasn't changed, no need to recalc.
+	if dcr.feeCache.hash == tip && dcr.feeCache.fee > 0 {
+		return dcr.feeCache.fee, nil
+	}
+
+	blockHash, err := dcr.node.GetBestBlockHash(ctx)
+	if err != nil {
+		return 0, translateRPCCancelErr(err)
+	}
+
+	rates := make([]uint64, 0, medianFeeTxs)
+out:
+	for i := 0; i < maxFeeBlocks; i++ {
+		blk, err := dcr.node.GetBlockVerbose(ctx, blockHash, true)
+		if err != nil {
+			return 0, translateRPCCancelErr(err)
+		}
+		for j := range blk.RawTx {
+			rate, ok := txFeeRate(&blk.RawTx[j])
+			if !ok {
+				continue
+			}
+			rates = append(rates, rate)
+			if len(rates) == medianFeeTxs {
+				break out
+			}
+		}
+		if blk.Height <= 1 {
+			break
+		}
+		blockHash, err = chainhash.NewHashFromStr(blk.PreviousHash)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(rates) == 0 {
+		return 0, errNoCompetition
+	}
+
+	sort.Slice(rates, func(i, j int) bool { return rates[i] < rates[j] })
+	rate := rates[len(rates)/2]
+	dcr.feeCache.fee = rate
+	dcr.feeCache.hash = tip
+	return rate, nil
+}
+
+// txFeeRate calculates the fee rate, in atoms / byte, of a regular transaction
+// from the verbose block data. The coinbase, and any transaction for which the
+// fee rate cannot be determined, are skipped.
+func txFeeRate(tx *chainjson.TxRawResult) (uint64, bool) {
+	if len(tx.Vin) == 0 || tx.Vin[0].IsCoinBase() || len(tx.Hex) == 0 {
+		return 0, false
+	}
+	var in, out float64
+	for i := range tx.Vin {
+		in += tx.Vin[i].AmountIn
+	}
+	for i := range tx.Vout {
+		out += tx.Vout[i].Value
+	}
+	fee := int64(math.Round((in - out) * conventionalConversionFactor))
+	if fee <= 0 {
+		return 0, false
+	}
+	return uint64(math.Round(float64(fee) / float64(len(tx.Hex)/2))), true
+}
+
 // Info provides some general information about the backend.
 func (*Backend) Info() *asset.BackendInfo {
 	return &asset.BackendInfo{}
@@ -524,6 +711,87 @@ func (dcr *Backend) FeeCoin(coinID []byte) (addr string, val uint64, confs int64
 	return
 }
 
+// BondVer returns the latest supported bond version. Part of the asset.Bonder
+// interface.
+func (dcr *Backend) BondVer() uint16 {
+	return dexdcr.BondVersion
+}
+
+// BondCoin locates a bond transaction output, validates the entire transaction,
+// and returns the amount, lock time, and confirmations of the bond, as well as
+// the account ID to which the bond commits. The bond output must be a P2SH
+// output paying to a bond script that is described by a nulldata commitment
+// output in the same transaction. Part of the asset.Bonder interface.
+func (dcr *Backend) BondCoin(ctx context.Context, ver uint16, coinID []byte) (amt, lockTime, confs int64, acct account.AccountID, err error) {
+	if ver != dexdcr.BondVersion {
+		err = fmt.Errorf("unsupported bond version %d", ver)
+		return
+	}
+	txHash, vout, errCoin := decodeCoinID(coinID)
+	if errCoin != nil {
+		err = fmt.Errorf("error decoding coin ID %x: %w", coinID, errCoin)
+		return
+	}
+
+	verboseTx, err := dcr.node.GetRawTransactionVerbose(ctx, txHash)
+	if err != nil {
+		if isTxNotFoundErr(err) {
+			err = asset.CoinNotFoundError
+		} else {
+			err = translateRPCCancelErr(err)
+		}
+		return
+	}
+	if int(vout) > len(verboseTx.Vout)-1 {
+		err = asset.CoinNotFoundError
+		return
+	}
+
+	bondOut := verboseTx.Vout[vout]
+	bondScript, err := hex.DecodeString(bondOut.ScriptPubKey.Hex)
+	if err != nil {
+		err = dex.UnsupportedScriptError
+		return
+	}
+	scriptType := dexdcr.ParseScriptType(bondOut.ScriptPubKey.Version, bondScript)
+	if !scriptType.IsP2SH() || scriptType.IsStake() {
+		err = dex.UnsupportedScriptError
+		return
+	}
+	scriptHash := dexdcr.ExtractScriptHash(bondOut.ScriptPubKey.Version, bondScript)
+
+	// Find the account commitment, and ensure it describes the bond script.
+	var found bool
+	for _, out := range verboseTx.Vout {
+		pkScript, errDec := hex.DecodeString(out.ScriptPubKey.Hex)
+		if errDec != nil {
+			continue
+		}
+		bondVer, acctID, lt, pkh, errCommit := dexdcr.ExtractBondCommitDataV0(out.ScriptPubKey.Version, pkScript)
+		if errCommit != nil {
+			continue
+		}
+		var expScript []byte
+		expScript, err = dexdcr.MakeBondScript(bondVer, lt, pkh[:])
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(dcrutil.Hash160(expScript), scriptHash) {
+			continue
+		}
+		acct, lockTime, found = acctID, int64(lt), true
+		break
+	}
+	if !found {
+		err = fmt.Errorf("no bond commitment found for bond output %s:%d", txHash, vout)
+		return
+	}
+
+	amt = int64(toAtoms(bondOut.Value))
+	confs = verboseTx.Confirmations
+	return
+}
+
 // txOutData is transaction output data, including recipient addresses, value,
 // script type, and number of required signatures.
 type txOutData struct {
@@ -7,18 +7,22 @@ package dcr
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"decred.org/dcrdex/dex"
 	dexdcr "decred.org/dcrdex/dex/networks/dcr"
+	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/asset"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/dcrec"
@@ -175,6 +179,7 @@ func cleanTestChain() {
 type testNode struct {
 	blockchainInfo    *chainjson.GetBlockChainInfoResult
 	blockchainInfoErr error
+	estimateErr       error
 }
 
 // Store utxo info as a concatenated string hash:vout.
@@ -184,7 +189,10 @@ func txOutID(txHash *chainhash.Hash, index uint32) string {
 
 const optimalFeeRate uint64 = 22
 
-func (*testNode) EstimateSmartFee(_ context.Context, confirmations int64, mode chainjson.EstimateSmartFeeMode) (*chainjson.EstimateSmartFeeResult, error) {
+func (t *testNode) EstimateSmartFee(_ context.Context, confirmations int64, mode chainjson.EstimateSmartFeeMode) (*chainjson.EstimateSmartFeeResult, error) {
+	if t.estimateErr != nil {
+		return nil, t.estimateErr
+	}
 	optimalRate := float64(optimalFeeRate) * 1e-5 // optimalFeeRate: 22 atoms/byte = 0.00022 DCR/KB * 1e8 atoms/DCR * 1e-3 KB/Byte
 	// fmt.Println((float64(optimalFeeRate)*1e-5)-0.00022)
 	return &chainjson.EstimateSmartFeeResult{FeeRate: optimalRate}, nil
@@ -1476,6 +1484,66 @@ func TestAuxiliary(t *testing.T) {
 	}
 }
 
+func TestBondCoin(t *testing.T) {
+	dcr, shutdown := testBackend()
+	defer shutdown()
+	ctx := dcr.ctx
+	cleanTestChain()
+
+	pkh := randomBytes(20)
+	var acctID account.AccountID
+	copy(acctID[:], randomBytes(32))
+	lockTime := uint32(time.Now().Add(time.Hour).Unix())
+	bondScript, _ := dexdcr.MakeBondScript(0, lockTime, pkh)
+	bondAddr, _ := stdaddr.NewAddressScriptHashV0(bondScript, chainParams)
+	_, bondPkScript := bondAddr.PaymentScript()
+	commitScript, _ := dexdcr.MakeBondCommitScript(0, acctID[:], lockTime, pkh)
+
+	msgTx := wire.NewMsgTx()
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(randomHash(), 0, 0), 0, nil))
+	msgTx.AddTxOut(wire.NewTxOut(2e8, bondPkScript))
+	msgTx.AddTxOut(wire.NewTxOut(0, commitScript))
+	txHash := msgTx.TxHash()
+	blockHash := testAddBlockVerbose(nil, 1, 100, 1)
+	verboseTx := testAddTxVerbose(msgTx, &txHash, blockHash, 100, 2)
+
+	amt, lt, confs, acct, err := dcr.BondCoin(ctx, 0, toCoinID(&txHash, 0))
+	if err != nil {
+		t.Fatalf("BondCoin error: %v", err)
+	}
+	if amt != 2e8 {
+		t.Fatalf("wrong bond amount %d", amt)
+	}
+	if lt != int64(lockTime) {
+		t.Fatalf("wrong lock time %d", lt)
+	}
+	if confs != 2 {
+		t.Fatalf("wrong confs %d", confs)
+	}
+	if acct != acctID {
+		t.Fatalf("wrong account ID %v", acct)
+	}
+
+	// Unsupported version.
+	if _, _, _, _, err = dcr.BondCoin(ctx, 1, toCoinID(&txHash, 0)); err == nil {
+		t.Fatalf("no error for unknown bond version")
+	}
+	// Not a P2SH output.
+	if _, _, _, _, err = dcr.BondCoin(ctx, 0, toCoinID(&txHash, 1)); err == nil {
+		t.Fatalf("no error for commitment output")
+	}
+	// Missing output.
+	if _, _, _, _, err = dcr.BondCoin(ctx, 0, toCoinID(&txHash, 2)); !errors.Is(err, asset.CoinNotFoundError) {
+		t.Fatalf("expected CoinNotFoundError, got %v", err)
+	}
+	// Commitment to a different pubkey hash.
+	badCommit, _ := dexdcr.MakeBondCommitScript(0, acctID[:], lockTime, randomBytes(20))
+	verboseTx.Vout[1].ScriptPubKey.Hex = hex.EncodeToString(badCommit)
+	if _, _, _, _, err = dcr.BondCoin(ctx, 0, toCoinID(&txHash, 0)); err == nil {
+		t.Fatalf("no error for mismatched bond commitment")
+	}
+}
+
 // TestCheckAddress checks that addresses are parsing or not parsing as
 // expected.
 func TestCheckAddress(t *testing.T) {
@@ -1639,3 +1707,60 @@ func TestSynced(t *testing.T) {
 	}
 	tNode.blockchainInfoErr = nil
 }
+
+func TestFeeRate(t *testing.T) {
+	cleanTestChain()
+	defer cleanTestChain()
+	node := &testNode{}
+	dcr := unconnectedDCR(tLogger, nil)
+	dcr.node = node
+
+	checkRate := func(tag string, cfg *asset.FeeRateConfig, wantRate uint64, wantErr bool) {
+		t.Helper()
+		if err := dcr.ConfigureFeeRate(cfg); err != nil {
+			t.Fatalf("%s: ConfigureFeeRate error: %v", tag, err)
+		}
+		rate, err := dcr.FeeRate(context.Background())
+		if (err != nil) != wantErr {
+			t.Fatalf("%s: wantErr = %t, err = %v", tag, wantErr, err)
+		}
+		if rate != wantRate {
+			t.Fatalf("%s: wanted rate %d, got %d", tag, wantRate, rate)
+		}
+	}
+
+	// estimatesmartfee
+	checkRate("node estimate", &asset.FeeRateConfig{}, optimalFeeRate, false)
+	checkRate("max fee rate", &asset.FeeRateConfig{MaxFeeRate: 20}, 20, false)
+
+	// The median fee rate of the regular transactions in recent blocks. The
+	// coinbase is skipped.
+	node.estimateErr = errors.New("test error")
+	const txSize = 250
+	testTx := func(feeRate uint64) chainjson.TxRawResult {
+		return chainjson.TxRawResult{
+			Hex:  strings.Repeat("00", txSize),
+			Vin:  []chainjson.Vin{{AmountIn: 1}},
+			Vout: []chainjson.Vout{{Value: 1 - float64(feeRate*txSize)/1e8}},
+		}
+	}
+	blockHash := randomHash()
+	testChainMtx.Lock()
+	testChain.hashes[1] = blockHash
+	testChain.blocks[*blockHash] = &chainjson.GetBlockVerboseResult{
+		Hash:   blockHash.String(),
+		Height: 1,
+		RawTx: []chainjson.TxRawResult{
+			{Hex: "00", Vin: []chainjson.Vin{{Coinbase: "00"}}},
+			testTx(30), testTx(10), testTx(20),
+		},
+	}
+	testChainMtx.Unlock()
+	checkRate("median", &asset.FeeRateConfig{}, 20, false)
+
+	// The configured floor.
+	dcr.feeCache.fee = 0
+	cleanTestChain()
+	checkRate("no floor", &asset.FeeRateConfig{}, 0, true)
+	checkRate("floor", &asset.FeeRateConfig{Floor: 12}, 12, false)
+}
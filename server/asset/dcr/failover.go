@@ -0,0 +1,126 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dcr
+
+import (
+	"context"
+	"errors"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/asset"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrjson/v4"
+	"github.com/decred/dcrd/dcrutil/v4"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v3"
+)
+
+// maxTipLag is the number of blocks that a node can be behind the best tip
+// reported by the other nodes before it is considered unhealthy.
+const maxTipLag = 2
+
+// failoverNode is a dcrNode that sends requests to the active node of a
+// prioritized list of nodes, failing over to the next healthy node if a request
+// fails with a node error.
+type failoverNode struct {
+	*asset.Failover
+	nodes []dcrNode
+}
+
+var _ dcrNode = (*failoverNode)(nil)
+
+func newFailoverNode(log dex.Logger, nodes []dcrNode) *failoverNode {
+	tips := make([]asset.NodeTipper, 0, len(nodes))
+	for _, node := range nodes {
+		node := node
+		tips = append(tips, func(ctx context.Context) (int64, error) {
+			chainInfo, err := node.GetBlockChainInfo(ctx)
+			if err != nil {
+				return 0, err
+			}
+			return chainInfo.Blocks, nil
+		})
+	}
+	return &failoverNode{
+		Failover: asset.NewFailover(log, maxTipLag, tips...),
+		nodes:    nodes,
+	}
+}
+
+// do runs the request with the active node, failing over on node errors.
+func (n *failoverNode) do(ctx context.Context, request func(node dcrNode) error) error {
+	return n.Do(ctx, func(i int) error {
+		return request(n.nodes[i])
+	}, isNodeErr)
+}
+
+func (n *failoverNode) EstimateSmartFee(ctx context.Context, confirmations int64, mode chainjson.EstimateSmartFeeMode) (res *chainjson.EstimateSmartFeeResult, err error) {
+	err = n.do(ctx, func(node dcrNode) error {
+		res, err = node.EstimateSmartFee(ctx, confirmations, mode)
+		return err
+	})
+	return
+}
+
+func (n *failoverNode) GetTxOut(ctx context.Context, txHash *chainhash.Hash, index uint32, tree int8, mempool bool) (res *chainjson.GetTxOutResult, err error) {
+	err = n.do(ctx, func(node dcrNode) error {
+		res, err = node.GetTxOut(ctx, txHash, index, tree, mempool)
+		return err
+	})
+	return
+}
+
+func (n *failoverNode) GetRawTransactionVerbose(ctx context.Context, txHash *chainhash.Hash) (res *chainjson.TxRawResult, err error) {
+	err = n.do(ctx, func(node dcrNode) error {
+		res, err = node.GetRawTransactionVerbose(ctx, txHash)
+		return err
+	})
+	return
+}
+
+func (n *failoverNode) GetBlockVerbose(ctx context.Context, blockHash *chainhash.Hash, verboseTx bool) (res *chainjson.GetBlockVerboseResult, err error) {
+	err = n.do(ctx, func(node dcrNode) error {
+		res, err = node.GetBlockVerbose(ctx, blockHash, verboseTx)
+		return err
+	})
+	return
+}
+
+func (n *failoverNode) GetBlockHash(ctx context.Context, blockHeight int64) (res *chainhash.Hash, err error) {
+	err = n.do(ctx, func(node dcrNode) error {
+		res, err = node.GetBlockHash(ctx, blockHeight)
+		return err
+	})
+	return
+}
+
+func (n *failoverNode) GetBestBlockHash(ctx context.Context) (res *chainhash.Hash, err error) {
+	err = n.do(ctx, func(node dcrNode) error {
+		res, err = node.GetBestBlockHash(ctx)
+		return err
+	})
+	return
+}
+
+func (n *failoverNode) GetBlockChainInfo(ctx context.Context) (res *chainjson.GetBlockChainInfoResult, err error) {
+	err = n.do(ctx, func(node dcrNode) error {
+		res, err = node.GetBlockChainInfo(ctx)
+		return err
+	})
+	return
+}
+
+func (n *failoverNode) GetRawTransaction(ctx context.Context, txHash *chainhash.Hash) (res *dcrutil.Tx, err error) {
+	err = n.do(ctx, func(node dcrNode) error {
+		res, err = node.GetRawTransaction(ctx, txHash)
+		return err
+	})
+	return
+}
+
+// isNodeErr is true if the error is not an error response from the node,
+// indicating a connection problem.
+func isNodeErr(err error) bool {
+	var rpcErr *dcrjson.RPCError
+	return !errors.As(err, &rpcErr)
+}
@@ -24,6 +24,13 @@ func (d *Driver) Setup(configPath string, logger dex.Logger, network dex.Network
 	return NewBackend(configPath, logger, network)
 }
 
+// SetupFailover creates a DOGE backend that fails over between the nodes
+// configured by the config files, in order of preference. Part of the
+// asset.FailoverDriver interface.
+func (d *Driver) SetupFailover(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend(configPaths, logger, network)
+}
+
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Litecoin.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
@@ -55,6 +62,14 @@ const (
 // NewBackend generates the network parameters and creates a ltc backend as a
 // btc clone using an asset/btc helper function.
 func NewBackend(configPath string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend([]string{configPath}, logger, network)
+}
+
+// newBackend creates a backend for the nodes configured by the config files,
+// in order of preference. The first config path can be an empty string, in
+// which case the standard system location of the config file is assumed.
+func newBackend(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	configPath := configPaths[0]
 	var params *chaincfg.Params
 	switch network {
 	case dex.Mainnet:
@@ -88,6 +103,7 @@ func NewBackend(configPath string, logger dex.Logger, network dex.Network) (asse
 		// think about how to transition once activated.
 		Segwit:               false,
 		ConfigPath:           configPath,
+		FailoverConfigPaths:  configPaths[1:],
 		Logger:               logger,
 		Net:                  network,
 		ChainParams:          params,
@@ -39,6 +39,14 @@ type Driver interface {
 	Setup(configPath string, logger dex.Logger, network dex.Network) (Backend, error)
 }
 
+// FailoverDriver is implemented by drivers for backends that can fail over
+// between multiple nodes.
+type FailoverDriver interface {
+	// SetupFailover is like Setup, but the backend uses the nodes configured
+	// at configPaths, in order of preference.
+	SetupFailover(configPaths []string, logger dex.Logger, network dex.Network) (Backend, error)
+}
+
 // TokenDriver is the interface required of all token assets.
 type TokenDriver interface {
 	driverBase
@@ -143,6 +151,21 @@ func Setup(assetID uint32, configPath string, logger dex.Logger, network dex.Net
 	return drv.Setup(configPath, logger, network)
 }
 
+// SetupFailover sets up the named asset with a backend that fails over between
+// the nodes configured at configPaths, in order of preference. SetupFailover is
+// only called for base chain assets, not tokens.
+func SetupFailover(assetID uint32, configPaths []string, logger dex.Logger, network dex.Network) (Backend, error) {
+	drv, ok := drivers[assetID]
+	if !ok {
+		return nil, fmt.Errorf("asset: unknown asset driver %d", assetID)
+	}
+	fd, ok := drv.(FailoverDriver)
+	if !ok {
+		return nil, fmt.Errorf("asset: driver for asset %d does not support failover", assetID)
+	}
+	return fd.SetupFailover(configPaths, logger, network)
+}
+
 // Version retrieves the version of the named asset's Backend implementation.
 func Version(assetID uint32) (uint32, error) {
 	drv, ok := baseDriver(assetID)
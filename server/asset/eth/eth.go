@@ -138,6 +138,13 @@ func (d *Driver) Setup(configPath string, logger dex.Logger, network dex.Network
 	return NewBackend(configPath, logger, network)
 }
 
+// SetupFailover creates an ETH backend that fails over between the nodes at
+// the IPC paths, in order of preference. Part of the asset.FailoverDriver
+// interface.
+func (d *Driver) SetupFailover(ipcs []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend(ipcs, logger, network)
+}
+
 type TokenDriver struct {
 	driverBase
 	token *dex.Token
@@ -262,6 +269,13 @@ func unconnectedETH(logger dex.Logger, net dex.Network) (*ETHBackend, error) {
 // NewBackend is the exported constructor by which the DEX will import the
 // Backend.
 func NewBackend(ipc string, logger dex.Logger, net dex.Network) (*ETHBackend, error) {
+	return newBackend([]string{ipc}, logger, net)
+}
+
+// newBackend creates a backend for the nodes at the IPC paths, in order of
+// preference. The first path can be an empty string, in which case the default
+// IPC path is used.
+func newBackend(ipcs []string, logger dex.Logger, net dex.Network) (*ETHBackend, error) {
 	switch net {
 	case dex.Simnet:
 	case dex.Testnet:
@@ -272,15 +286,21 @@ func NewBackend(ipc string, logger dex.Logger, net dex.Network) (*ETHBackend, er
 		return nil, fmt.Errorf("unknown network ID: %d", net)
 	}
 
-	if ipc == "" {
-		ipc = defaultIPC
+	if ipcs[0] == "" {
+		ipcs = append([]string{defaultIPC}, ipcs[1:]...)
 	}
 
 	eth, err := unconnectedETH(logger, net)
 	if err != nil {
 		return nil, err
 	}
-	eth.node = newRPCClient(eth.net, ipc)
+	// The node is a failover fetcher even without failover nodes so that the
+	// primary node's health is still monitored.
+	nodes := make([]ethFetcher, 0, len(ipcs))
+	for _, ipc := range ipcs {
+		nodes = append(nodes, newRPCClient(eth.net, ipc))
+	}
+	eth.node = newFailoverFetcher(eth.log, nodes)
 	return eth, nil
 }
 
@@ -312,9 +332,27 @@ func (eth *ETHBackend) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 		eth.run(ctx)
 		wg.Done()
 	}()
+	if failover, is := eth.node.(*failoverFetcher); is {
+		failover.CheckHealth(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			failover.Run(ctx, asset.DefaultHealthCheckInterval)
+		}()
+	}
 	return &wg, nil
 }
 
+// Healthy is true if at least one of the backend's nodes is healthy. A
+// backend with an unmonitored node is considered healthy. Part of the
+// asset.HealthReporter interface.
+func (eth *baseBackend) Healthy() bool {
+	if failover, is := eth.node.(*failoverFetcher); is {
+		return failover.Healthy()
+	}
+	return true
+}
+
 // Connect for TokenBackend just waits for context cancellation and closes the
 // WaitGroup.
 func (eth *TokenBackend) Connect(ctx context.Context) (*sync.WaitGroup, error) {
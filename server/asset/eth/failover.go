@@ -0,0 +1,165 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+//go:build lgpl
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"syscall"
+
+	"decred.org/dcrdex/dex"
+	dexeth "decred.org/dcrdex/dex/networks/eth"
+	"decred.org/dcrdex/server/asset"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxTipLag is the number of blocks that a node can be behind the best tip
+// reported by the other nodes before it is considered unhealthy.
+const maxTipLag = 3
+
+// failoverFetcher is an ethFetcher that sends requests to the active node of a
+// prioritized list of nodes, failing over to the next healthy node if a request
+// fails with a node error.
+type failoverFetcher struct {
+	*asset.Failover
+	nodes []ethFetcher
+}
+
+// Check that failoverFetcher satisfies the ethFetcher interface.
+var _ ethFetcher = (*failoverFetcher)(nil)
+
+func newFailoverFetcher(log dex.Logger, nodes []ethFetcher) *failoverFetcher {
+	tips := make([]asset.NodeTipper, 0, len(nodes))
+	for _, node := range nodes {
+		node := node
+		tips = append(tips, func(ctx context.Context) (int64, error) {
+			tip, err := node.blockNumber(ctx)
+			return int64(tip), err
+		})
+	}
+	return &failoverFetcher{
+		Failover: asset.NewFailover(log, maxTipLag, tips...),
+		nodes:    nodes,
+	}
+}
+
+// do runs the request with the active node, failing over on node errors.
+func (f *failoverFetcher) do(ctx context.Context, request func(node ethFetcher) error) error {
+	return f.Do(ctx, func(i int) error {
+		return request(f.nodes[i])
+	}, isNodeErr)
+}
+
+// connect connects to every node. All nodes must be available at startup.
+func (f *failoverFetcher) connect(ctx context.Context) error {
+	for i, node := range f.nodes {
+		if err := node.connect(ctx); err != nil {
+			for _, node := range f.nodes[:i] {
+				node.shutdown()
+			}
+			return fmt.Errorf("error connecting to node %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// shutdown shuts down every node.
+func (f *failoverFetcher) shutdown() {
+	for _, node := range f.nodes {
+		node.shutdown()
+	}
+}
+
+// loadToken loads the token for every node.
+func (f *failoverFetcher) loadToken(ctx context.Context, assetID uint32) error {
+	for i, node := range f.nodes {
+		if err := node.loadToken(ctx, assetID); err != nil {
+			return fmt.Errorf("error loading token for node %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (f *failoverFetcher) bestHeader(ctx context.Context) (hdr *types.Header, err error) {
+	err = f.do(ctx, func(node ethFetcher) error {
+		hdr, err = node.bestHeader(ctx)
+		return err
+	})
+	return
+}
+
+func (f *failoverFetcher) blockNumber(ctx context.Context) (tip uint64, err error) {
+	err = f.do(ctx, func(node ethFetcher) error {
+		tip, err = node.blockNumber(ctx)
+		return err
+	})
+	return
+}
+
+func (f *failoverFetcher) headerByHeight(ctx context.Context, height uint64) (hdr *types.Header, err error) {
+	err = f.do(ctx, func(node ethFetcher) error {
+		hdr, err = node.headerByHeight(ctx, height)
+		return err
+	})
+	return
+}
+
+func (f *failoverFetcher) suggestGasTipCap(ctx context.Context) (tipCap *big.Int, err error) {
+	err = f.do(ctx, func(node ethFetcher) error {
+		tipCap, err = node.suggestGasTipCap(ctx)
+		return err
+	})
+	return
+}
+
+func (f *failoverFetcher) syncProgress(ctx context.Context) (prog *ethereum.SyncProgress, err error) {
+	err = f.do(ctx, func(node ethFetcher) error {
+		prog, err = node.syncProgress(ctx)
+		return err
+	})
+	return
+}
+
+func (f *failoverFetcher) transaction(ctx context.Context, hash common.Hash) (tx *types.Transaction, isMempool bool, err error) {
+	err = f.do(ctx, func(node ethFetcher) error {
+		tx, isMempool, err = node.transaction(ctx, hash)
+		return err
+	})
+	return
+}
+
+func (f *failoverFetcher) swap(ctx context.Context, assetID uint32, secretHash [32]byte) (state *dexeth.SwapState, err error) {
+	err = f.do(ctx, func(node ethFetcher) error {
+		state, err = node.swap(ctx, assetID, secretHash)
+		return err
+	})
+	return
+}
+
+func (f *failoverFetcher) accountBalance(ctx context.Context, assetID uint32, addr common.Address) (bal *big.Int, err error) {
+	err = f.do(ctx, func(node ethFetcher) error {
+		bal, err = node.accountBalance(ctx, assetID, addr)
+		return err
+	})
+	return
+}
+
+// isNodeErr is true if the error indicates a problem with the connection to
+// the node. Error responses from the node, such as for a transaction that is
+// not found, are not node errors.
+func isNodeErr(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, rpc.ErrClientQuit) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,238 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package asset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+)
+
+const (
+	// DefaultHealthCheckInterval is the default interval between node health
+	// checks.
+	DefaultHealthCheckInterval = 10 * time.Second
+	// healthCheckTimeout is the time allowed for a node to report its tip.
+	healthCheckTimeout = 5 * time.Second
+)
+
+// HealthReporter is implemented by backends that monitor the health of their
+// nodes.
+type HealthReporter interface {
+	// Healthy is true if at least one of the backend's nodes is healthy.
+	Healthy() bool
+}
+
+// NodeTipper reports the best block height of a node.
+type NodeTipper func(ctx context.Context) (int64, error)
+
+// Failover tracks the health of a prioritized list of nodes, and selects the
+// node that should be used for requests. A node is unhealthy if it fails to
+// report its tip, if a request to it fails with a node error, or if its tip is
+// more than the allowed number of blocks behind the best tip reported by the
+// other nodes. The most preferred healthy node is active. If no node is
+// healthy, requests are attempted with each of the nodes in order of
+// preference, since they may have recovered since the last health check.
+type Failover struct {
+	log    dex.Logger
+	tips   []NodeTipper
+	maxLag int64
+
+	mtx     sync.RWMutex
+	healthy []bool
+	active  int
+}
+
+// NewFailover is the constructor for a Failover. The NodeTippers are for the
+// nodes in order of preference. maxLag is the number of blocks that a node's
+// tip can be behind the best tip before the node is considered unhealthy. All
+// nodes are initially considered healthy.
+func NewFailover(log dex.Logger, maxLag int64, tips ...NodeTipper) *Failover {
+	healthy := make([]bool, len(tips))
+	for i := range healthy {
+		healthy[i] = true
+	}
+	return &Failover{
+		log:     log,
+		tips:    tips,
+		maxLag:  maxLag,
+		healthy: healthy,
+	}
+}
+
+// Run performs periodic health checks until the context is canceled.
+func (f *Failover) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.CheckHealth(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CheckHealth requests the tip of every node, and updates their health.
+func (f *Failover) CheckHealth(ctx context.Context) {
+	heights := make([]int64, len(f.tips))
+	errs := make([]error, len(f.tips))
+	var wg sync.WaitGroup
+	for i, tip := range f.tips {
+		wg.Add(1)
+		go func(i int, tip NodeTipper) {
+			defer wg.Done()
+			tipCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			heights[i], errs[i] = tip(tipCtx)
+		}(i, tip)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	var best int64
+	for i, h := range heights {
+		if errs[i] == nil && h > best {
+			best = h
+		}
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	wasHealthy := f.anyHealthy()
+	for i, err := range errs {
+		var healthy bool
+		switch {
+		case err != nil:
+			if f.healthy[i] {
+				f.log.Errorf("Node %d failed its health check: %v", i, err)
+			}
+		case best-heights[i] > f.maxLag:
+			if f.healthy[i] {
+				f.log.Errorf("Node %d is %d blocks behind the best tip at height %d",
+					i, best-heights[i], best)
+			}
+		default:
+			healthy = true
+			if !f.healthy[i] {
+				f.log.Infof("Node %d has recovered at height %d", i, heights[i])
+			}
+		}
+		f.healthy[i] = healthy
+	}
+	f.selectActive()
+	if isHealthy := f.anyHealthy(); isHealthy != wasHealthy {
+		if isHealthy {
+			f.log.Infof("Healthy node available")
+		} else {
+			f.log.Errorf("No healthy nodes!")
+		}
+	}
+}
+
+// selectActive selects the most preferred healthy node. If no node is healthy,
+// the active node is unchanged. The mtx must be locked.
+func (f *Failover) selectActive() {
+	for i, healthy := range f.healthy {
+		if !healthy {
+			continue
+		}
+		if i != f.active {
+			f.log.Warnf("Switching from node %d to node %d", f.active, i)
+			f.active = i
+		}
+		return
+	}
+}
+
+// Healthy is true if at least one node is healthy. Part of the HealthReporter
+// interface.
+func (f *Failover) Healthy() bool {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return f.anyHealthy()
+}
+
+// anyHealthy is true if at least one node is healthy. The mtx must be locked.
+func (f *Failover) anyHealthy() bool {
+	for _, healthy := range f.healthy {
+		if healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// Active is the index of the node that should be used for requests.
+func (f *Failover) Active() int {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return f.active
+}
+
+// Failed marks the node unhealthy after a failed request, and selects another
+// node. The node's health is restored by a subsequent health check.
+func (f *Failover) Failed(i int, err error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if !f.healthy[i] {
+		return
+	}
+	f.log.Errorf("Request to node %d failed: %v", i, err)
+	f.healthy[i] = false
+	f.selectActive()
+	if !f.anyHealthy() {
+		f.log.Errorf("No healthy nodes!")
+	}
+}
+
+// Do calls request with the index of the active node. If the request fails
+// with an error for which isNodeErr is true, the node is marked unhealthy and
+// the request is attempted with the next healthy node. If no node is healthy,
+// the request is attempted with each node in order of preference. The error
+// from the last attempt is returned.
+func (f *Failover) Do(ctx context.Context, request func(i int) error, isNodeErr func(error) bool) error {
+	tried := make([]bool, len(f.tips))
+	i := f.next(tried)
+	var err error
+	for {
+		tried[i] = true
+		err = request(i)
+		if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) ||
+			errors.Is(err, context.DeadlineExceeded) || !isNodeErr(err) {
+			return err
+		}
+		f.Failed(i, err)
+		if i = f.next(tried); i < 0 {
+			return fmt.Errorf("all nodes failed. last error: %w", err)
+		}
+	}
+}
+
+// next returns the index of the most preferred untried node, preferring
+// healthy nodes, or -1 if all nodes have been tried.
+func (f *Failover) next(tried []bool) int {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	untried := -1
+	for i, healthy := range f.healthy {
+		if tried[i] {
+			continue
+		}
+		if healthy {
+			return i
+		}
+		if untried < 0 {
+			untried = i
+		}
+	}
+	return untried
+}
@@ -0,0 +1,163 @@
+package asset
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"decred.org/dcrdex/dex"
+)
+
+type tNode struct {
+	tip    int64
+	tipErr error
+	reqErr error
+	reqs   int
+}
+
+func tFailover(nodes ...*tNode) *Failover {
+	tips := make([]NodeTipper, 0, len(nodes))
+	for _, n := range nodes {
+		n := n
+		tips = append(tips, func(context.Context) (int64, error) { return n.tip, n.tipErr })
+	}
+	return NewFailover(dex.StdOutLogger("T", dex.LevelOff), 2, tips...)
+}
+
+var (
+	errTNode = errors.New("node error")
+	errTResp = errors.New("response error")
+)
+
+func isTNodeErr(err error) bool {
+	return errors.Is(err, errTNode)
+}
+
+func TestFailoverCheckHealth(t *testing.T) {
+	ctx := context.Background()
+	n0, n1, n2 := &tNode{tip: 100}, &tNode{tip: 100}, &tNode{tip: 100}
+	f := tFailover(n0, n1, n2)
+
+	check := func(tag string, wantActive int, wantHealthy bool) {
+		t.Helper()
+		f.CheckHealth(ctx)
+		if active := f.Active(); active != wantActive {
+			t.Fatalf("%s: wanted active node %d, got %d", tag, wantActive, active)
+		}
+		if healthy := f.Healthy(); healthy != wantHealthy {
+			t.Fatalf("%s: wanted healthy = %t, got %t", tag, wantHealthy, healthy)
+		}
+	}
+
+	check("all synced", 0, true)
+
+	// The preferred node falls behind.
+	n1.tip, n2.tip = 103, 103
+	check("preferred node lagging", 1, true)
+
+	// The next node fails to report its tip.
+	n1.tipErr = errTNode
+	check("second node error", 2, true)
+
+	// A node within the allowed lag is healthy.
+	n0.tip = 101
+	check("preferred node recovered", 0, true)
+
+	// No nodes report a tip.
+	n0.tipErr, n2.tipErr = errTNode, errTNode
+	check("all nodes failing", 0, false)
+
+	n1.tipErr = nil
+	check("second node recovered", 1, true)
+
+	// A lone node without failover nodes is monitored too.
+	n0 = &tNode{tip: 100}
+	f = tFailover(n0)
+	check("lone node synced", 0, true)
+	n0.tipErr = errTNode
+	check("lone node error", 0, false)
+	n0.tipErr = nil
+	check("lone node recovered", 0, true)
+}
+
+func TestFailoverDo(t *testing.T) {
+	ctx := context.Background()
+	n0, n1 := &tNode{tip: 100}, &tNode{tip: 100}
+	nodes := []*tNode{n0, n1}
+	f := tFailover(nodes...)
+
+	request := func(i int) error {
+		nodes[i].reqs++
+		return nodes[i].reqErr
+	}
+	reset := func() {
+		n0.reqs, n1.reqs = 0, 0
+	}
+
+	// A successful request goes to the active node.
+	if err := f.Do(ctx, request, isTNodeErr); err != nil {
+		t.Fatalf("Do error: %v", err)
+	}
+	if n0.reqs != 1 || n1.reqs != 0 {
+		t.Fatalf("wrong requests. n0: %d, n1: %d", n0.reqs, n1.reqs)
+	}
+
+	// A response error is returned without failing over.
+	reset()
+	n0.reqErr = errTResp
+	if err := f.Do(ctx, request, isTNodeErr); !errors.Is(err, errTResp) {
+		t.Fatalf("wanted response error, got %v", err)
+	}
+	if n1.reqs != 0 || f.Active() != 0 {
+		t.Fatalf("failed over for a response error")
+	}
+
+	// A node error fails over to the next node.
+	reset()
+	n0.reqErr = errTNode
+	if err := f.Do(ctx, request, isTNodeErr); err != nil {
+		t.Fatalf("Do error after failover: %v", err)
+	}
+	if n0.reqs != 1 || n1.reqs != 1 {
+		t.Fatalf("wrong requests after failover. n0: %d, n1: %d", n0.reqs, n1.reqs)
+	}
+	if f.Active() != 1 {
+		t.Fatalf("failed node still active")
+	}
+
+	// The failed node is skipped.
+	reset()
+	if err := f.Do(ctx, request, isTNodeErr); err != nil {
+		t.Fatalf("Do error with failed node: %v", err)
+	}
+	if n0.reqs != 0 || n1.reqs != 1 {
+		t.Fatalf("wrong requests with failed node. n0: %d, n1: %d", n0.reqs, n1.reqs)
+	}
+
+	// All nodes failing.
+	reset()
+	n1.reqErr = errTNode
+	if err := f.Do(ctx, request, isTNodeErr); !errors.Is(err, errTNode) {
+		t.Fatalf("wanted node error, got %v", err)
+	}
+	if f.Healthy() {
+		t.Fatalf("healthy after all nodes failed")
+	}
+
+	// With no healthy nodes, requests are still attempted in order of
+	// preference.
+	reset()
+	n0.reqErr = nil
+	if err := f.Do(ctx, request, isTNodeErr); err != nil {
+		t.Fatalf("Do error with no healthy nodes: %v", err)
+	}
+	if n0.reqs != 1 || n1.reqs != 0 {
+		t.Fatalf("wrong requests with no healthy nodes. n0: %d, n1: %d", n0.reqs, n1.reqs)
+	}
+
+	// A health check restores the nodes.
+	f.CheckHealth(ctx)
+	if !f.Healthy() || f.Active() != 0 {
+		t.Fatalf("nodes not restored by health check")
+	}
+}
@@ -0,0 +1,157 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package asset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+)
+
+// FeeRateConfig configures a backend's fee rate estimation chain.
+type FeeRateConfig struct {
+	// Floor is a static fee rate that is used when none of the estimators can
+	// provide a rate. Floor is also the minimum rate returned. A zero Floor
+	// means there is no fallback rate, and a failure of all estimators is an
+	// error.
+	Floor uint64
+	// MaxFeeRate is the maximum rate returned. Estimates above MaxFeeRate are
+	// clamped. Zero means no limit.
+	MaxFeeRate uint64
+	// HalfLife is the time over which the smoothed rate closes half of the
+	// distance to a lower estimate. Increases in the estimated rate take effect
+	// immediately so that swaps are not underfunded when fees spike. Zero
+	// disables smoothing.
+	HalfLife time.Duration
+}
+
+// FeeRateConfigurer is implemented by backends with a configurable fee rate
+// estimation chain.
+type FeeRateConfigurer interface {
+	ConfigureFeeRate(cfg *FeeRateConfig) error
+}
+
+// FeeEstimator is a named source of fee rate estimates for a FeeRateChain.
+// Estimate should return an error if it cannot provide a rate.
+type FeeEstimator struct {
+	Name     string
+	Estimate func(ctx context.Context) (uint64, error)
+}
+
+// FeeRateChain is a fee rate estimation chain. The estimators are tried in
+// order, and the first successful estimate is used. If all of the estimators
+// fail, the configured floor rate is used. The resulting rate is then clamped
+// to the configured limits and smoothed.
+type FeeRateChain struct {
+	log        dex.Logger
+	estimators []*FeeEstimator
+
+	mtx      sync.Mutex
+	cfg      FeeRateConfig
+	smoothed float64
+	stamp    time.Time
+}
+
+// NewFeeRateChain is the constructor for a FeeRateChain. The chain has no floor,
+// limit, or smoothing until configured with Configure.
+func NewFeeRateChain(log dex.Logger, estimators ...*FeeEstimator) *FeeRateChain {
+	return &FeeRateChain{
+		log:        log,
+		estimators: estimators,
+	}
+}
+
+// Configure sets the fee rate floor, limit, and smoothing. The smoothed rate is
+// reset.
+func (c *FeeRateChain) Configure(cfg *FeeRateConfig) error {
+	if cfg.MaxFeeRate > 0 && cfg.Floor > cfg.MaxFeeRate {
+		return fmt.Errorf("fee rate floor %d is greater than the max fee rate %d",
+			cfg.Floor, cfg.MaxFeeRate)
+	}
+	if cfg.HalfLife < 0 {
+		return fmt.Errorf("negative fee rate smoothing half-life %v", cfg.HalfLife)
+	}
+	c.mtx.Lock()
+	c.cfg = *cfg
+	c.smoothed = 0
+	c.stamp = time.Time{}
+	c.mtx.Unlock()
+	return nil
+}
+
+// FeeRate gets a fee rate from the first estimator that can provide one, or
+// the floor rate if none can. The rate is clamped and smoothed according to
+// the chain's configuration.
+func (c *FeeRateChain) FeeRate(ctx context.Context) (uint64, error) {
+	c.mtx.Lock()
+	cfg := c.cfg
+	c.mtx.Unlock()
+
+	var rate uint64
+	var errs []error
+	for _, est := range c.estimators {
+		r, err := est.Estimate(ctx)
+		if err == nil && r > 0 {
+			rate = r
+			break
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if err == nil {
+			err = errors.New("zero rate")
+		}
+		c.log.Debugf("Fee rate estimator %q failed: %v", est.Name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", est.Name, err))
+	}
+	if rate == 0 {
+		if cfg.Floor == 0 {
+			if len(errs) == 0 {
+				return 0, errors.New("no fee rate estimators")
+			}
+			return 0, fmt.Errorf("all fee rate estimators failed. last error: %w", errs[len(errs)-1])
+		}
+		c.log.Debugf("Using the fee rate floor (%d). No estimator could provide a rate.", cfg.Floor)
+		rate = cfg.Floor
+	}
+
+	if rate < cfg.Floor {
+		rate = cfg.Floor
+	}
+	if cfg.MaxFeeRate > 0 && rate > cfg.MaxFeeRate {
+		c.log.Debugf("Estimated fee rate %d is higher than the max fee rate %d. Using the latter.",
+			rate, cfg.MaxFeeRate)
+		rate = cfg.MaxFeeRate
+	}
+
+	return c.smooth(rate, cfg.HalfLife, time.Now()), nil
+}
+
+// smooth applies the smoothing half-life to a new estimate. Increases take
+// effect immediately, while decreases decay exponentially toward the new rate.
+func (c *FeeRateChain) smooth(rate uint64, halfLife time.Duration, now time.Time) uint64 {
+	if halfLife == 0 {
+		return rate
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	r := float64(rate)
+	if c.stamp.IsZero() || r >= c.smoothed {
+		c.smoothed, c.stamp = r, now
+		return rate
+	}
+	elapsed := now.Sub(c.stamp)
+	if elapsed <= 0 {
+		return uint64(math.Round(c.smoothed))
+	}
+	decay := math.Exp2(-float64(elapsed) / float64(halfLife))
+	c.smoothed = r + (c.smoothed-r)*decay
+	c.stamp = now
+	return uint64(math.Round(c.smoothed))
+}
@@ -0,0 +1,114 @@
+package asset
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex"
+)
+
+func TestFeeRateChain(t *testing.T) {
+	var rate1, rate2 uint64
+	var err1, err2 error
+	chain := NewFeeRateChain(dex.StdOutLogger("T", dex.LevelOff),
+		&FeeEstimator{
+			Name:     "first",
+			Estimate: func(context.Context) (uint64, error) { return rate1, err1 },
+		},
+		&FeeEstimator{
+			Name:     "second",
+			Estimate: func(context.Context) (uint64, error) { return rate2, err2 },
+		},
+	)
+
+	tests := []struct {
+		name         string
+		rate1, rate2 uint64
+		err1, err2   error
+		floor, max   uint64
+		wantRate     uint64
+		wantErr      bool
+	}{{
+		name:     "first estimator",
+		rate1:    10,
+		rate2:    20,
+		wantRate: 10,
+	}, {
+		name:     "first estimator error",
+		err1:     errors.New("test error"),
+		rate2:    20,
+		wantRate: 20,
+	}, {
+		name:     "first estimator zero rate",
+		rate2:    20,
+		wantRate: 20,
+	}, {
+		name:    "all estimators fail, no floor",
+		err1:    errors.New("test error"),
+		err2:    errors.New("test error"),
+		wantErr: true,
+	}, {
+		name:     "all estimators fail, floor",
+		err1:     errors.New("test error"),
+		err2:     errors.New("test error"),
+		floor:    5,
+		wantRate: 5,
+	}, {
+		name:     "estimate below floor",
+		rate1:    2,
+		floor:    5,
+		wantRate: 5,
+	}, {
+		name:     "estimate above max",
+		rate1:    200,
+		floor:    5,
+		max:      100,
+		wantRate: 100,
+	}}
+
+	for _, tt := range tests {
+		rate1, rate2, err1, err2 = tt.rate1, tt.rate2, tt.err1, tt.err2
+		if err := chain.Configure(&FeeRateConfig{Floor: tt.floor, MaxFeeRate: tt.max}); err != nil {
+			t.Fatalf("%s: Configure error: %v", tt.name, err)
+		}
+		rate, err := chain.FeeRate(context.Background())
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: wantErr = %t, err = %v", tt.name, tt.wantErr, err)
+		}
+		if rate != tt.wantRate {
+			t.Fatalf("%s: wanted rate %d, got %d", tt.name, tt.wantRate, rate)
+		}
+	}
+
+	if err := chain.Configure(&FeeRateConfig{Floor: 10, MaxFeeRate: 5}); err == nil {
+		t.Fatalf("no error for floor greater than max fee rate")
+	}
+}
+
+func TestFeeRateChainSmoothing(t *testing.T) {
+	chain := NewFeeRateChain(dex.StdOutLogger("T", dex.LevelOff))
+	const halfLife = time.Minute
+	start := time.Now()
+
+	// The first rate is taken as-is.
+	if r := chain.smooth(100, halfLife, start); r != 100 {
+		t.Fatalf("wanted initial rate 100, got %d", r)
+	}
+	// A decrease decays with the half-life.
+	if r := chain.smooth(20, halfLife, start.Add(halfLife)); r != 60 {
+		t.Fatalf("wanted smoothed rate 60 after one half-life, got %d", r)
+	}
+	if r := chain.smooth(20, halfLife, start.Add(2*halfLife)); r != 40 {
+		t.Fatalf("wanted smoothed rate 40 after two half-lives, got %d", r)
+	}
+	// An increase takes effect immediately.
+	if r := chain.smooth(80, halfLife, start.Add(2*halfLife)); r != 80 {
+		t.Fatalf("wanted immediate increase to 80, got %d", r)
+	}
+	// No smoothing with a zero half-life.
+	if r := chain.smooth(20, 0, start.Add(3*halfLife)); r != 20 {
+		t.Fatalf("wanted unsmoothed rate 20, got %d", r)
+	}
+}
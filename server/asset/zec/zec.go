@@ -27,6 +27,13 @@ func (d *Driver) Setup(configPath string, logger dex.Logger, network dex.Network
 	return NewBackend(configPath, logger, network)
 }
 
+// SetupFailover creates a ZEC backend that fails over between the nodes
+// configured by the config files, in order of preference. Part of the
+// asset.FailoverDriver interface.
+func (d *Driver) SetupFailover(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend(configPaths, logger, network)
+}
+
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // ZCash.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
@@ -58,6 +65,14 @@ const (
 // NewBackend generates the network parameters and creates a zec backend as a
 // btc clone using an asset/btc helper function.
 func NewBackend(configPath string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	return newBackend([]string{configPath}, logger, network)
+}
+
+// newBackend creates a backend for the nodes configured by the config files,
+// in order of preference. The first config path can be an empty string, in
+// which case the standard system location of the config file is assumed.
+func newBackend(configPaths []string, logger dex.Logger, network dex.Network) (asset.Backend, error) {
+	configPath := configPaths[0]
 	var btcParams *chaincfg.Params
 	var addrParams *dexzec.AddressParams
 	switch network {
@@ -87,13 +102,14 @@ func NewBackend(configPath string, logger dex.Logger, network dex.Network) (asse
 	}
 
 	be, err := btc.NewBTCClone(&btc.BackendCloneConfig{
-		Name:        assetName,
-		Segwit:      false,
-		ConfigPath:  configPath,
-		Logger:      logger,
-		Net:         network,
-		ChainParams: btcParams,
-		Ports:       ports,
+		Name:                assetName,
+		Segwit:              false,
+		ConfigPath:          configPath,
+		FailoverConfigPaths: configPaths[1:],
+		Logger:              logger,
+		Net:                 network,
+		ChainParams:         btcParams,
+		Ports:               ports,
 		AddressDecoder: func(addr string, net *chaincfg.Params) (btcutil.Address, error) {
 			return dexzec.DecodeAddress(addr, addrParams, btcParams)
 		},
@@ -9,6 +9,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/comms"
 	"decred.org/dcrdex/server/db"
+	"decred.org/dcrdex/server/metrics"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 )
@@ -62,6 +64,9 @@ type Storage interface {
 	CreateAccount(acct *account.Account, feeAsset uint32, feeAddr string) error
 	AccountRegAddr(account.AccountID) (addr string, asset uint32, err error)
 	PayAccount(account.AccountID, []byte) error
+	CreateAccountWithBond(acct *account.Account, bond *db.Bond) error
+	AddBond(aid account.AccountID, bond *db.Bond) error
+	Bonds(aid account.AccountID, lockTimeThresh time.Time) ([]*db.Bond, error)
 }
 
 // Signer signs messages. The message must be a 32-byte hash.
@@ -73,6 +78,12 @@ type Signer interface {
 // FeeChecker is a function for retrieving the details for a fee payment.
 type FeeChecker func(assetID uint32, coinID []byte) (addr string, val uint64, confs int64, err error)
 
+// BondCoinChecker is a function for locating an unspent bond, and extracting
+// the amount, lock time, and account ID. The confirmations of the bond
+// transaction are also provided.
+type BondCoinChecker func(ctx context.Context, assetID uint32, ver uint16,
+	coinID []byte) (amt, lockTime, confs int64, acct account.AccountID, err error)
+
 type TxDataSource func([]byte) ([]byte, error)
 
 // A respHandler is the handler for the response to a DEX-originating request. A
@@ -92,6 +103,13 @@ type clientInfo struct {
 	respHandlers map[uint64]*respHandler
 	recentOrders *latestOrders
 	suspended    bool // penalized, disallow new orders
+
+	// tier is the account's trading tier, which is the sum of the strengths
+	// of the account's active bonds, plus one if the legacy registration fee
+	// was paid. A tier less than one also disallows new orders.
+	tier          int64
+	legacyFeePaid bool
+	bonds         []*db.Bond // active bonds, sorted by lock time
 }
 
 // GraceLimit returns the number of initial orders allowed for a new user before
@@ -131,6 +149,55 @@ func (client *clientInfo) isSuspended() bool {
 	return client.suspended
 }
 
+// bondTier returns the client's current tier.
+func (client *clientInfo) bondTier() int64 {
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	return client.tier
+}
+
+// addBond adds a new active bond to the client, returning the new tier.
+func (client *clientInfo) addBond(bond *db.Bond) int64 {
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	client.bonds = append(client.bonds, bond)
+	sort.Slice(client.bonds, func(i, j int) bool {
+		return client.bonds[i].LockTime < client.bonds[j].LockTime
+	})
+	client.tier += int64(bond.Strength)
+	return client.tier
+}
+
+// pruneBonds removes any bonds with lock times before lockTimeThresh, returning
+// the expired bonds and the new tier.
+func (client *clientInfo) pruneBonds(lockTimeThresh int64) (expired []*db.Bond, tier int64) {
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	var n int
+	for _, bond := range client.bonds {
+		if bond.LockTime >= lockTimeThresh {
+			break // sorted by lock time
+		}
+		expired = append(expired, bond)
+		client.tier -= int64(bond.Strength)
+		n++
+	}
+	client.bonds = client.bonds[n:]
+	return expired, client.tier
+}
+
+// bondTier computes the tier for a set of active bonds and the legacy fee
+// payment status.
+func bondTier(bonds []*db.Bond, legacyFeePaid bool) (tier int64) {
+	for _, bond := range bonds {
+		tier += int64(bond.Strength)
+	}
+	if legacyFeePaid {
+		tier++
+	}
+	return tier
+}
+
 func (client *clientInfo) rmHandler(id uint64) bool {
 	client.mtx.Lock()
 	defer client.mtx.Unlock()
@@ -194,6 +261,10 @@ type AuthManager struct {
 	feeAddress func(assetID uint32) string
 	feeAssets  map[uint32]*msgjson.FeeAsset
 
+	checkBond  BondCoinChecker
+	bondAssets map[uint32]*msgjson.BondAsset
+	bondExpiry time.Duration
+
 	anarchy           bool
 	freeCancels       bool
 	banScore          uint32
@@ -329,6 +400,17 @@ type Config struct {
 	// FeeChecker is a method for getting the registration fee output info.
 	FeeChecker FeeChecker
 
+	// BondAssets specifies the fidelity bond parameters for assets supported
+	// for bonds. The amount is the bond increment for one tier.
+	BondAssets map[string]*msgjson.BondAsset
+	// BondExpiry is how long before a bond's lock time that the bond no longer
+	// counts toward the account's tier. This gives the client time to refund
+	// the bond and the server time to unbook orders.
+	BondExpiry time.Duration
+	// BondChecker is a method for getting the bond amount, lock time, and
+	// account commitment.
+	BondChecker BondCoinChecker
+
 	// TxDataSources are sources of tx data for a coin ID.
 	TxDataSources map[uint32]TxDataSource
 	// UserUnbooker is a function for unbooking all of a user's orders.
@@ -354,6 +436,13 @@ type Config struct {
 const (
 	defaultInitTakerLotLimit = 6
 	defaultAbsTakerLotLimit  = 375
+
+	// DefaultBondExpiry is the default bond expiry duration.
+	DefaultBondExpiry = 30 * 24 * time.Hour
+
+	// bondCheckInterval is how often the bonds of connected users are checked
+	// for expiry.
+	bondCheckInterval = time.Minute
 )
 
 // NewAuthManager is the constructor for an AuthManager.
@@ -376,6 +465,14 @@ func NewAuthManager(cfg *Config) *AuthManager {
 	for _, asset := range cfg.FeeAssets {
 		feeAssets[asset.ID] = asset
 	}
+	bondAssets := make(map[uint32]*msgjson.BondAsset)
+	for _, asset := range cfg.BondAssets {
+		bondAssets[asset.ID] = asset
+	}
+	bondExpiry := cfg.BondExpiry
+	if bondExpiry == 0 {
+		bondExpiry = DefaultBondExpiry
+	}
 	auth := &AuthManager{
 		storage:           cfg.Storage,
 		signer:            cfg.Signer,
@@ -384,6 +481,9 @@ func NewAuthManager(cfg *Config) *AuthManager {
 		unbookFun:         cfg.UserUnbooker,
 		feeAddress:        cfg.FeeAddress,
 		feeAssets:         feeAssets,
+		checkBond:         cfg.BondChecker,
+		bondAssets:        bondAssets,
+		bondExpiry:        bondExpiry,
 		anarchy:           cfg.Anarchy,
 		freeCancels:       cfg.FreeCancels || cfg.Anarchy,
 		banScore:          banScore,
@@ -403,6 +503,7 @@ func NewAuthManager(cfg *Config) *AuthManager {
 	comms.Route(msgjson.ConnectRoute, auth.handleConnect)
 	comms.Route(msgjson.RegisterRoute, auth.handleRegister)
 	comms.Route(msgjson.NotifyFeeRoute, auth.handleNotifyFee)
+	comms.Route(msgjson.PostBondRoute, auth.handlePostBond)
 	comms.Route(msgjson.MatchStatusRoute, auth.handleMatchStatus)
 	comms.Route(msgjson.OrderStatusRoute, auth.handleOrderStatus)
 	return auth
@@ -487,8 +588,33 @@ func (auth *AuthManager) Run(ctx context.Context) {
 	log.Infof("Allowing %d settling + taker order lots per market for new users.", auth.initTakerLotLimit)
 	log.Infof("Allowing up to %d settling + taker order lots per market for established users.", auth.absTakerLotLimit)
 
-	go auth.latencyQ.Run(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		auth.latencyQ.Run(ctx)
+	}()
+
+	if len(auth.bondAssets) > 0 {
+		log.Infof("Accepting fidelity bonds, expiring %v before lock time.", auth.bondExpiry)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(bondCheckInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					auth.checkBonds()
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
 	<-ctx.Done()
+	wg.Wait()
 	auth.connMtx.Lock()
 	defer auth.connMtx.Unlock()
 	for user, ub := range auth.unbookers {
@@ -663,18 +789,76 @@ const (
 
 // UserSettlingLimit returns a user's settling amount limit for the given market
 // in units of the base asset. The limit may be negative for accounts with poor
-// swap history.
+// swap history. Both the initial and absolute limits are scaled by the user's
+// tier, so the limit drops as the user's bonds expire. The limit applies to the
+// full size of an order, including the hidden reserve of an iceberg order.
 func (auth *AuthManager) UserSettlingLimit(user account.AccountID, mkt *dex.MarketInfo) int64 {
+	tier := auth.userTier(user)
+	if tier < 1 {
+		return 0
+	}
 	currentLotSize := int64(mkt.LotSize)
-	base := currentLotSize * auth.initTakerLotLimit
+	base := currentLotSize * auth.initTakerLotLimit * tier
 	sa := auth.userSwapAmountHistory(user, mkt.Base, mkt.Quote)
 	limit := base + sa.Swapped*successWeight + sa.StuckLong*stuckLongWeight + sa.StuckShort*stuckShortWeight + sa.Spoofed*spoofedWeight
-	if limit/currentLotSize >= auth.absTakerLotLimit {
-		limit = auth.absTakerLotLimit * currentLotSize
+	if absLimit := auth.absTakerLotLimit * tier; limit/currentLotSize >= absLimit {
+		limit = absLimit * currentLotSize
 	}
 	return limit
 }
 
+// userTier returns the tier of a connected user. Users that are not connected
+// are assumed to be tier 1.
+func (auth *AuthManager) userTier(user account.AccountID) int64 {
+	client := auth.user(user)
+	if client == nil {
+		return 1
+	}
+	return client.bondTier()
+}
+
+// checkBonds removes expired bonds from connected users, notifying them of the
+// expiry and unbooking their orders if their tier drops below one.
+func (auth *AuthManager) checkBonds() {
+	lockTimeThresh := time.Now().Add(auth.bondExpiry).Unix()
+
+	auth.connMtx.RLock()
+	clients := make([]*clientInfo, 0, len(auth.users))
+	for _, client := range auth.users {
+		clients = append(clients, client)
+	}
+	auth.connMtx.RUnlock()
+
+	for _, client := range clients {
+		expired, tier := client.pruneBonds(lockTimeThresh)
+		if len(expired) == 0 {
+			continue
+		}
+		user := client.acct.ID
+		for _, bond := range expired {
+			log.Infof("Bond %s for user %v expired (lock time %v). New tier = %d.",
+				coinIDString(bond.AssetID, bond.CoinID), user, time.Unix(bond.LockTime, 0), tier)
+			bondExpNote := &msgjson.BondExpiredNotification{
+				AccountID: user[:],
+				AssetID:   bond.AssetID,
+				BondID:    bond.CoinID,
+				Tier:      tier,
+			}
+			auth.Sign(bondExpNote)
+			note, err := msgjson.NewNotification(msgjson.BondExpiredRoute, bondExpNote)
+			if err != nil {
+				log.Errorf("error creating bond expired notification: %v", err)
+				continue
+			}
+			auth.Notify(user, note)
+		}
+		if tier < 1 {
+			log.Infof("User %v tier dropped to %d. Unbooking orders.", user, tier)
+			auth.unbookUserOrders(user)
+		}
+	}
+}
+
 func integrateOutcomes(matchOutcomes *latestMatchOutcomes, preimgOutcomes *latestPreimageOutcomes) (score, successCount, piMissCount int32) {
 	if matchOutcomes != nil {
 		matchCounts := matchOutcomes.binViolations()
@@ -690,6 +874,15 @@ func integrateOutcomes(matchOutcomes *latestMatchOutcomes, preimgOutcomes *lates
 	return
 }
 
+// setConnScore updates the account score of a connected user's link, which
+// selects the rate tier for their order requests. This must not be called with
+// the violationMtx locked.
+func (auth *AuthManager) setConnScore(user account.AccountID, score int32) {
+	if client := auth.user(user); client != nil {
+		client.conn.SetAccountScore(score)
+	}
+}
+
 // userScore computes an authenticated user's score from their recent match
 // outcomes and preimage history. They must have entries in the outcome maps.
 // Use loadUserScore to compute score from history in DB. This must be called
@@ -703,9 +896,7 @@ func (auth *AuthManager) registerMatchOutcome(user account.AccountID, misstep No
 	violation := misstep.Violation()
 
 	auth.violationMtx.Lock()
-	defer auth.violationMtx.Unlock()
-	matchOutcomes, found := auth.matchOutcomes[user]
-	if found {
+	if matchOutcomes, found := auth.matchOutcomes[user]; found {
 		matchOutcomes.add(&matchOutcome{
 			time:    refTime.UnixMilli(),
 			mid:     mmid.MatchID,
@@ -715,10 +906,13 @@ func (auth *AuthManager) registerMatchOutcome(user account.AccountID, misstep No
 			quote:   mmid.Quote,
 		})
 		score = auth.userScore(user)
+		auth.violationMtx.Unlock()
 		log.Debugf("Registering outcome %q (badness %d) for user %v, new score = %d",
 			violation.String(), violation.Score(), user, score)
+		auth.setConnScore(user, score)
 		return
 	}
+	defer auth.violationMtx.Unlock()
 
 	// The user is currently not connected and authenticated. When the user logs
 	// back in, their history will be reloaded (loadUserScore) and their account
@@ -764,6 +958,7 @@ func (auth *AuthManager) Inaction(user account.AccountID, misstep NoActionStep,
 		log.Errorf("Invalid inaction step %d", misstep)
 		return
 	}
+	metrics.Violations.Inc(misstep.Violation().String())
 	score := auth.registerMatchOutcome(user, misstep, mmid, matchValue, refTime)
 	if score < int32(auth.banScore) {
 		return
@@ -777,21 +972,22 @@ func (auth *AuthManager) Inaction(user account.AccountID, misstep NoActionStep,
 
 func (auth *AuthManager) registerPreimageOutcome(user account.AccountID, miss bool, oid order.OrderID, refTime time.Time) (score int32) {
 	auth.violationMtx.Lock()
-	defer auth.violationMtx.Unlock()
-	piOutcomes, found := auth.preimgOutcomes[user]
-	if found {
+	if piOutcomes, found := auth.preimgOutcomes[user]; found {
 		piOutcomes.add(&preimageOutcome{
 			time: refTime.UnixMilli(),
 			oid:  oid,
 			miss: miss,
 		})
 		score = auth.userScore(user)
+		auth.violationMtx.Unlock()
 		if miss {
 			log.Debugf("Registering outcome %q (badness %d) for user %v, new score = %d",
 				ViolationPreimageMiss.String(), ViolationPreimageMiss.Score(), user, score)
 		}
+		auth.setConnScore(user, score)
 		return
 	}
+	defer auth.violationMtx.Unlock()
 
 	// The user is currently not connected and authenticated. When the user logs
 	// back in, their history will be reloaded (loadUserScore) and their account
@@ -826,6 +1022,7 @@ func (auth *AuthManager) PreimageSuccess(user account.AccountID, epochEnd time.T
 
 // MissedPreimage registers a missed preimage violation by the user.
 func (auth *AuthManager) MissedPreimage(user account.AccountID, epochEnd time.Time, oid order.OrderID) {
+	metrics.Violations.Inc(ViolationPreimageMiss.String())
 	score := auth.registerPreimageOutcome(user, true, oid, epochEnd)
 	if score < int32(auth.banScore) {
 		return
@@ -856,6 +1053,7 @@ func (auth *AuthManager) Penalize(user account.AccountID, lastRule account.Rule,
 		if err := auth.storage.CloseAccount(user /*client.acct.ID*/, lastRule); err != nil {
 			return err
 		}
+		metrics.Penalties.Inc()
 	}
 
 	// Notify user of penalty.
@@ -892,14 +1090,15 @@ func (auth *AuthManager) Penalize(user account.AccountID, lastRule account.Rule,
 }
 
 // Suspended indicates the the user exists (is presently connected) and is
-// suspended. This does not access the persistent storage.
+// suspended, either by penalty or because the user's tier is less than one.
+// This does not access the persistent storage.
 func (auth *AuthManager) Suspended(user account.AccountID) (found, suspended bool) {
 	client := auth.user(user)
 	if client == nil {
 		// TODO: consider hitting auth.storage.Account(user)
 		return false, true // suspended for practical purposes
 	}
-	return true, client.isSuspended()
+	return true, client.isSuspended() || client.bondTier() < 1
 }
 
 // Unban forgives a user, allowing them to resume trading if their score permits
@@ -928,6 +1127,7 @@ func (auth *AuthManager) ForgiveMatchFail(user account.AccountID, mid order.Matc
 	if err != nil {
 		return
 	}
+	auth.setConnScore(user, score)
 
 	// Restore the account if score is sub-threshold.
 	if score < int32(auth.banScore) {
@@ -939,6 +1139,23 @@ func (auth *AuthManager) ForgiveMatchFail(user account.AccountID, mid order.Matc
 	return
 }
 
+// AccountOutcomes returns the user's score and the latest scored match and
+// preimage outcomes from which it is computed. The outcomes of a connected user
+// are those presently used for scoring. Otherwise, they are loaded from the DB.
+func (auth *AuthManager) AccountOutcomes(user account.AccountID) (score int32, matchOutcomes []*MatchOutcome, preimageOutcomes []*PreimageOutcome, err error) {
+	auth.violationMtx.Lock()
+	latestMatches, latestPreimages := auth.matchOutcomes[user], auth.preimgOutcomes[user]
+	auth.violationMtx.Unlock()
+	if latestMatches == nil || latestPreimages == nil {
+		latestMatches, latestPreimages, err = auth.loadUserOutcomes(user)
+		if err != nil {
+			return
+		}
+	}
+	score, _, _ = integrateOutcomes(latestMatches, latestPreimages)
+	return score, latestMatches.list(), latestPreimages.list(), nil
+}
+
 // TODO: a way to manipulate/forgive cancellation rate violation.
 
 // user gets the clientInfo for the specified account ID.
@@ -1140,17 +1357,43 @@ func (auth *AuthManager) handleConnect(conn comms.Link, msg *msgjson.Message) *m
 			Message: "no account found for account ID: " + connect.AccountID.String(),
 		}
 	}
-	if !paid {
-		// TODO: Send pending responses (e.g. a 'register` response that
-		// contains the fee address and amount for the user). Use
-		// rmUserConnectMsgs and rmUserConnectReqs to get them by account ID.
+
+	// Load the account's active bonds, which establish the account's tier
+	// along with any legacy registration fee payment.
+	bonds, err := auth.storage.Bonds(user, time.Now().Add(auth.bondExpiry))
+	if err != nil {
+		log.Errorf("Failed to load bonds for user %v: %v", user, err)
 		return &msgjson.Error{
-			Code:    msgjson.UnpaidAccountError,
-			Message: "unpaid account",
+			Code:    msgjson.RPCInternalError,
+			Message: "DB error",
 		}
 	}
-	// Note: suspended accounts may connect to complete swaps, etc. but not
-	// place new orders.
+	if !paid && len(bonds) == 0 {
+		// An account whose bonds have all expired may still connect at tier
+		// 0 to complete swaps, but an account that never paid the legacy fee
+		// or posted a bond may not.
+		allBonds, err := auth.storage.Bonds(user, time.Unix(0, 0))
+		if err != nil {
+			log.Errorf("Failed to load bonds for user %v: %v", user, err)
+			return &msgjson.Error{
+				Code:    msgjson.RPCInternalError,
+				Message: "DB error",
+			}
+		}
+		if len(allBonds) == 0 {
+			// TODO: Send pending responses (e.g. a 'register` response that
+			// contains the fee address and amount for the user). Use
+			// rmUserConnectMsgs and rmUserConnectReqs to get them by account ID.
+			return &msgjson.Error{
+				Code:    msgjson.UnpaidAccountError,
+				Message: "unpaid account",
+			}
+		}
+		log.Infof("User %v with only expired bonds connecting at tier 0.", user)
+	}
+	tier := bondTier(bonds, paid)
+	// Note: suspended accounts, including those with a tier less than one, may
+	// connect to complete swaps, etc. but not place new orders.
 
 	// Authorize the account.
 	sigMsg := connect.Serialize()
@@ -1182,11 +1425,14 @@ func (auth *AuthManager) handleConnect(conn comms.Link, msg *msgjson.Message) *m
 		}
 	}
 	client := &clientInfo{
-		acct:         acctInfo,
-		conn:         conn,
-		respHandlers: respHandlers,
-		recentOrders: latestFinished,
-		suspended:    !open,
+		acct:          acctInfo,
+		conn:          conn,
+		respHandlers:  respHandlers,
+		recentOrders:  latestFinished,
+		suspended:     !open,
+		tier:          tier,
+		legacyFeePaid: paid,
+		bonds:         bonds,
 	}
 
 	// Compute the user's cancellation rate.
@@ -1303,6 +1549,18 @@ func (auth *AuthManager) handleConnect(conn comms.Link, msg *msgjson.Message) *m
 	}
 
 	conn.Authorized()
+	conn.SetAccountScore(score)
+
+	msgBonds := make([]*msgjson.Bond, 0, len(bonds))
+	for _, bond := range bonds {
+		msgBonds = append(msgBonds, &msgjson.Bond{
+			Version: bond.Version,
+			Amount:  uint64(bond.Amount),
+			Expiry:  uint64(time.Unix(bond.LockTime, 0).Add(-auth.bondExpiry).Unix()),
+			CoinID:  bond.CoinID,
+			AssetID: bond.AssetID,
+		})
+	}
 
 	// Sign and send the connect response.
 	sig := auth.SignMsg(sigMsg)
@@ -1312,6 +1570,9 @@ func (auth *AuthManager) handleConnect(conn comms.Link, msg *msgjson.Message) *m
 		ActiveMatches:       msgMatches,
 		Score:               score,
 		Suspended:           &client.suspended,
+		Tier:                &tier,
+		ActiveBonds:         msgBonds,
+		LegacyFeePaid:       &paid,
 	}
 	respMsg, err := msgjson.NewResponse(msg.ID, resp, nil)
 	if err != nil {
@@ -1328,8 +1589,8 @@ func (auth *AuthManager) handleConnect(conn comms.Link, msg *msgjson.Message) *m
 		return nil
 	}
 
-	log.Infof("Authenticated account %v from %v with %d active orders, %d active matches (score = %d, suspended = %v)",
-		user, conn.Addr(), len(msgOrderStatuses), len(msgMatches), score, client.suspended)
+	log.Infof("Authenticated account %v from %v with %d active orders, %d active matches (score = %d, suspended = %v, tier = %d)",
+		user, conn.Addr(), len(msgOrderStatuses), len(msgMatches), score, client.suspended, tier)
 	auth.addClient(client)
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -48,23 +49,28 @@ type ratioData struct {
 
 // TStorage satisfies the Storage interface
 type TStorage struct {
-	acctInfo            *db.Account
-	acctInfoErr         error
-	acct                *account.Account
-	matches             []*db.MatchData
-	closedID            account.AccountID
-	matchStatuses       []*db.MatchStatus
-	userPreimageResults []*db.PreimageResult
-	userMatchOutcomes   []*db.MatchOutcome
-	orderStatuses       []*db.OrderStatus
-	acctErr             error
-	regAddr             string
-	regAsset            uint32
-	regErr              error
-	payErr              error
-	unpaid              bool
-	closed              bool
-	ratio               ratioData
+	acctInfo             *db.Account
+	acctInfoErr          error
+	acct                 *account.Account
+	matches              []*db.MatchData
+	closedID             account.AccountID
+	matchStatuses        []*db.MatchStatus
+	userPreimageResults  []*db.PreimageResult
+	userMatchOutcomes    []*db.MatchOutcome
+	userMatchOutcomesErr error
+	orderStatuses        []*db.OrderStatus
+	acctErr              error
+	regAddr              string
+	regAsset             uint32
+	regErr               error
+	payErr               error
+	unpaid               bool
+	closed               bool
+	ratio                ratioData
+	bonds                []*db.Bond
+	bondsErr             error
+	newAcctBond          *db.Bond
+	addedBond            *db.Bond
 }
 
 func (s *TStorage) CloseAccount(id account.AccountID, _ account.Rule) error {
@@ -81,7 +87,7 @@ func (s *TStorage) Account(account.AccountID) (*account.Account, bool, bool) {
 	return s.acct, !s.unpaid, !s.closed
 }
 func (s *TStorage) CompletedAndAtFaultMatchStats(aid account.AccountID, lastN int) ([]*db.MatchOutcome, error) {
-	return s.userMatchOutcomes, nil
+	return s.userMatchOutcomes, s.userMatchOutcomesErr
 }
 func (s *TStorage) PreimageStats(user account.AccountID, lastN int) ([]*db.PreimageResult, error) {
 	return s.userPreimageResults, nil
@@ -116,6 +122,23 @@ func (s *TStorage) AccountRegAddr(account.AccountID) (string, uint32, error) {
 	return s.regAddr, s.regAsset, s.regErr
 }
 func (s *TStorage) PayAccount(account.AccountID, []byte) error { return s.payErr }
+func (s *TStorage) CreateAccountWithBond(acct *account.Account, bond *db.Bond) error {
+	s.newAcctBond = bond
+	return s.acctErr
+}
+func (s *TStorage) AddBond(aid account.AccountID, bond *db.Bond) error {
+	s.addedBond = bond
+	return nil
+}
+func (s *TStorage) Bonds(aid account.AccountID, lockTimeThresh time.Time) ([]*db.Bond, error) {
+	var bonds []*db.Bond
+	for _, bond := range s.bonds {
+		if bond.LockTime >= lockTimeThresh.Unix() {
+			bonds = append(bonds, bond)
+		}
+	}
+	return bonds, s.bondsErr
+}
 func (s *TStorage) setRatioData(dat *ratioData) {
 	s.ratio = *dat
 }
@@ -153,12 +176,14 @@ type TRPCClient struct {
 	reqs       []*tReq
 	on         uint32
 	closed     chan struct{}
+	score      int32
 }
 
-func (c *TRPCClient) ID() uint64    { return c.id }
-func (c *TRPCClient) IP() dex.IPKey { return c.ip }
-func (c *TRPCClient) Addr() string  { return c.addr }
-func (c *TRPCClient) Authorized()   {}
+func (c *TRPCClient) ID() uint64                  { return c.id }
+func (c *TRPCClient) IP() dex.IPKey               { return c.ip }
+func (c *TRPCClient) Addr() string                { return c.addr }
+func (c *TRPCClient) Authorized()                 {}
+func (c *TRPCClient) SetAccountScore(score int32) { c.score = score }
 func (c *TRPCClient) Send(msg *msgjson.Message) error {
 	c.sends = append(c.sends, msg)
 	return c.sendErr
@@ -367,6 +392,27 @@ func tCheckFee(assetID uint32, coin []byte) (addr string, val uint64, confs int6
 	return tCheckFeeAddr, tCheckFeeVal, tCheckFeeConfs, tCheckFeeErr
 }
 
+const (
+	tBondIncrement uint64 = 1e8
+	tBondExpiry           = time.Hour
+)
+
+var (
+	tCheckBondAmt      int64 = 2e8
+	tCheckBondLockTime int64
+	tCheckBondConfs    int64 = 2
+	tCheckBondAcct     account.AccountID
+	tCheckBondErr      error
+)
+
+func tCheckBond(_ context.Context, assetID uint32, ver uint16, coinID []byte) (amt, lockTime, confs int64, acct account.AccountID, err error) {
+	if assetID != 42 {
+		err = fmt.Errorf("bad asset")
+		return
+	}
+	return tCheckBondAmt, tCheckBondLockTime, tCheckBondConfs, tCheckBondAcct, tCheckBondErr
+}
+
 func tFeeAddress(assetID uint32) string {
 	if assetID != 42 {
 		return ""
@@ -408,7 +454,16 @@ func TestMain(m *testing.M) {
 					Amt:   tRegFee,
 				},
 			},
-			FeeChecker:      tCheckFee,
+			FeeChecker: tCheckFee,
+			BondAssets: map[string]*msgjson.BondAsset{
+				"dcr": {
+					ID:    42,
+					Confs: uint32(tCheckBondConfs),
+					Amt:   tBondIncrement,
+				},
+			},
+			BondExpiry:      tBondExpiry,
+			BondChecker:     tCheckBond,
 			UserUnbooker:    func(account.AccountID) {},
 			MiaUserTimeout:  90 * time.Second, // TODO: test
 			CancelThreshold: 0.9,
@@ -668,6 +723,71 @@ func TestAuthManager_loadUserScore(t *testing.T) {
 	}
 }
 
+func TestAccountOutcomes(t *testing.T) {
+	user := tNewUser(t)
+	failMID := randomMatchID()
+	missTime := nextTime()
+	rig.storage.userMatchOutcomes = []*db.MatchOutcome{
+		newMatchOutcome(order.MatchComplete, randomMatchID(), false, 7, nextTime()),
+		newMatchOutcome(order.NewlyMatched, failMID, true, 7, nextTime()),
+	}
+	rig.storage.userPreimageResults = []*db.PreimageResult{
+		newPreimageResult(true, missTime),
+		newPreimageResult(false, nextTime()),
+	}
+	defer func() {
+		rig.storage.userMatchOutcomes = nil
+		rig.storage.userPreimageResults = nil
+	}()
+
+	// Not connected, so loaded from the DB.
+	score, matchOutcomes, preimageOutcomes, err := rig.mgr.AccountOutcomes(user.acctID)
+	if err != nil {
+		t.Fatalf("AccountOutcomes error: %v", err)
+	}
+	wantScore := int32(successScore + noSwapAsMakerScore + preimageMissScore)
+	if score != wantScore {
+		t.Fatalf("wrong score. got %d, want %d", score, wantScore)
+	}
+	if len(matchOutcomes) != 2 || len(preimageOutcomes) != 2 {
+		t.Fatalf("wrong number of outcomes. got %d match outcomes and %d preimage outcomes",
+			len(matchOutcomes), len(preimageOutcomes))
+	}
+	mo := matchOutcomes[1]
+	if mo.ID != failMID || mo.Outcome != ViolationNoSwapAsMaker || mo.Score != noSwapAsMakerScore || mo.Value != 7 {
+		t.Fatalf("wrong match outcome %+v", mo)
+	}
+	po := preimageOutcomes[0]
+	if !po.Miss || po.Time != missTime || po.Score != preimageMissScore || preimageOutcomes[1].Score != 0 {
+		t.Fatalf("wrong preimage outcomes %+v, %+v", po, preimageOutcomes[1])
+	}
+
+	// DB error.
+	rig.storage.userMatchOutcomesErr = errors.New("boom")
+	defer func() { rig.storage.userMatchOutcomesErr = nil }()
+	if _, _, _, err = rig.mgr.AccountOutcomes(user.acctID); err == nil {
+		t.Fatalf("no error for DB error")
+	}
+}
+
+func TestConnScoreUpdates(t *testing.T) {
+	user := tNewUser(t)
+	rig.signer.sig = user.randomSignature()
+	connectUser(t, user)
+	defer rig.mgr.removeClient(rig.mgr.user(user.acctID))
+
+	// The link's score is updated with each outcome.
+	mmid := db.MarketMatchID{MatchID: randomMatchID()}
+	rig.mgr.SwapSuccess(user.acctID, mmid, 1, time.Now())
+	if user.conn.score != successScore {
+		t.Fatalf("wrong link score after swap success. got %d, want %d", user.conn.score, successScore)
+	}
+	rig.mgr.MissedPreimage(user.acctID, time.Now(), randomOrderID())
+	if want := int32(successScore + preimageMissScore); user.conn.score != want {
+		t.Fatalf("wrong link score after missed preimage. got %d, want %d", user.conn.score, want)
+	}
+}
+
 func TestConnect(t *testing.T) {
 	user := tNewUser(t)
 	rig.signer.sig = user.randomSignature()
@@ -1539,6 +1659,227 @@ func TestHandleNotifyFee(t *testing.T) {
 	}
 }
 
+func TestHandlePostBond(t *testing.T) {
+	user := tNewUser(t)
+	acct, _ := account.NewAccountFromPubKey(user.privKey.PubKey().SerializeCompressed())
+	defer resetStorage()
+
+	tCheckBondAcct = acct.ID
+	tCheckBondLockTime = time.Now().Add(2 * tBondExpiry).Unix()
+	coinID := randBytes(36)
+
+	newPostBond := func() *msgjson.PostBond {
+		postBond := &msgjson.PostBond{
+			AcctPubKey: user.privKey.PubKey().SerializeCompressed(),
+			AssetID:    42,
+			Version:    0,
+			CoinID:     coinID,
+		}
+		postBond.SetSig(signMsg(user.privKey, postBond.Serialize()))
+		return postBond
+	}
+
+	newMsg := func(postBond *msgjson.PostBond) *msgjson.Message {
+		msg, err := msgjson.NewRequest(comms.NextID(), msgjson.PostBondRoute, postBond)
+		if err != nil {
+			t.Fatalf("NewRequest error: %v", err)
+		}
+		return msg
+	}
+
+	do := func(msg *msgjson.Message) *msgjson.Error {
+		return rig.mgr.handlePostBond(user.conn, msg)
+	}
+
+	// The first waiter attempt is synchronous.
+	doWaiter := func(msg *msgjson.Message) (*msgjson.PostBondResult, *msgjson.Error) {
+		t.Helper()
+		msgErr := rig.mgr.handlePostBond(user.conn, msg)
+		if msgErr != nil {
+			t.Fatalf("never made it to the waiter: %d: %v", msgErr.Code, msgErr.Message)
+		}
+		sent := user.conn.getSend()
+		if sent == nil {
+			t.Fatalf("no response")
+		}
+		resp, err := sent.Response()
+		if err != nil {
+			t.Fatalf("non-response found")
+		}
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		res := new(msgjson.PostBondResult)
+		if err = json.Unmarshal(resp.Result, res); err != nil {
+			t.Fatalf("error unmarshaling PostBondResult: %v", err)
+		}
+		return res, nil
+	}
+
+	ensureErr := makeEnsureErr(t)
+
+	msg := newMsg(newPostBond())
+	msg.Payload = []byte(`?`)
+	ensureErr(do(msg), "bad payload", msgjson.RPCParseError)
+
+	postBond := newPostBond()
+	postBond.AssetID = 0
+	ensureErr(do(newMsg(postBond)), "unsupported asset", msgjson.BondError)
+
+	postBond = newPostBond()
+	postBond.Version = 1
+	ensureErr(do(newMsg(postBond)), "unsupported version", msgjson.BondError)
+
+	postBond = newPostBond()
+	postBond.AcctPubKey = []byte{0x01}
+	ensureErr(do(newMsg(postBond)), "bad pubkey", msgjson.PubKeyParseError)
+
+	postBond = newPostBond()
+	postBond.Sig = []byte{0x01, 0x02}
+	ensureErr(do(newMsg(postBond)), "bad signature", msgjson.SignatureError)
+
+	// Closed account.
+	rig.storage.acct = acct
+	rig.storage.closed = true
+	ensureErr(do(newMsg(newPostBond())), "closed account", msgjson.AccountClosedError)
+	rig.storage.closed = false
+	rig.storage.acct = nil
+
+	goodMsg := newMsg(newPostBond())
+
+	tCheckBondErr = errors.New("invalid bond")
+	_, msgErr := doWaiter(goodMsg)
+	ensureErr(msgErr, "BondCoin error", msgjson.BondError)
+	tCheckBondErr = nil
+
+	tCheckBondAcct = newAccountID()
+	_, msgErr = doWaiter(goodMsg)
+	ensureErr(msgErr, "wrong account", msgjson.BondError)
+	tCheckBondAcct = acct.ID
+
+	tCheckBondAmt = int64(tBondIncrement) - 1
+	_, msgErr = doWaiter(goodMsg)
+	ensureErr(msgErr, "small bond", msgjson.BondError)
+	tCheckBondAmt = 2 * int64(tBondIncrement)
+
+	tCheckBondLockTime = time.Now().Add(tBondExpiry / 2).Unix()
+	_, msgErr = doWaiter(goodMsg)
+	ensureErr(msgErr, "expired bond", msgjson.BondError)
+	tCheckBondLockTime = time.Now().Add(2 * tBondExpiry).Unix()
+
+	// New account created with the bond.
+	rig.signer.sig = user.randomSignature()
+	rig.storage.unpaid = true
+	res, msgErr := doWaiter(goodMsg)
+	if msgErr != nil {
+		t.Fatalf("error posting valid bond: %s", msgErr.Message)
+	}
+	if rig.storage.newAcctBond == nil {
+		t.Fatalf("account not created with bond")
+	}
+	if rig.storage.newAcctBond.Strength != 2 {
+		t.Fatalf("wrong bond strength %d", rig.storage.newAcctBond.Strength)
+	}
+	if res.Tier != 2 {
+		t.Fatalf("wrong tier %d", res.Tier)
+	}
+	if !bytes.Equal(res.AccountID, acct.ID[:]) {
+		t.Fatalf("wrong account ID in response")
+	}
+
+	// Bond added to an existing account with a legacy fee.
+	rig.storage.acct = acct
+	rig.storage.unpaid = false
+	rig.storage.newAcctBond = nil
+	res, msgErr = doWaiter(newMsg(newPostBond()))
+	if msgErr != nil {
+		t.Fatalf("error posting valid bond to existing account: %s", msgErr.Message)
+	}
+	if rig.storage.addedBond == nil || rig.storage.newAcctBond != nil {
+		t.Fatalf("bond not added to existing account")
+	}
+	if res.Tier != 3 {
+		t.Fatalf("wrong tier %d", res.Tier)
+	}
+}
+
+func TestBondTiers(t *testing.T) {
+	resetStorage()
+	defer resetStorage()
+	user := tNewUser(t)
+	rig.signer.sig = user.randomSignature()
+
+	// An unpaid account without bonds cannot connect.
+	rig.storage.unpaid = true
+	connectMsg := queueUser(t, user)
+	msgErr := rig.mgr.handleConnect(user.conn, connectMsg)
+	makeEnsureErr(t)(msgErr, "unpaid account", msgjson.UnpaidAccountError)
+
+	// A bond-funded account can.
+	lockTime := time.Now().Add(tBondExpiry + time.Minute).Unix()
+	rig.storage.bonds = []*db.Bond{{
+		AssetID:  42,
+		CoinID:   randBytes(36),
+		Amount:   2 * int64(tBondIncrement),
+		Strength: 2,
+		LockTime: lockTime,
+	}}
+	respMsg := connectUser(t, user)
+	res := extractConnectResult(t, respMsg)
+	if res.Tier == nil || *res.Tier != 2 {
+		t.Fatalf("wrong tier in connect result")
+	}
+	if len(res.ActiveBonds) != 1 {
+		t.Fatalf("expected 1 active bond, got %d", len(res.ActiveBonds))
+	}
+	if res.LegacyFeePaid == nil || *res.LegacyFeePaid {
+		t.Fatalf("legacy fee should not be paid")
+	}
+
+	mkt := &dex.MarketInfo{Base: 42, Quote: 0, LotSize: 1e8}
+	limit := rig.mgr.UserSettlingLimit(user.acctID, mkt)
+	if expLimit := 2 * rig.mgr.initTakerLotLimit * int64(mkt.LotSize); limit != expLimit {
+		t.Fatalf("wrong settling limit. expected %d, got %d", expLimit, limit)
+	}
+	if _, suspended := rig.mgr.Suspended(user.acctID); suspended {
+		t.Fatalf("bonded user is suspended")
+	}
+
+	// Expire the bond.
+	client := rig.mgr.user(user.acctID)
+	client.mtx.Lock()
+	client.bonds[0].LockTime = time.Now().Add(tBondExpiry - time.Minute).Unix()
+	client.mtx.Unlock()
+	rig.mgr.checkBonds()
+
+	note := user.conn.getSend()
+	if note == nil || note.Route != msgjson.BondExpiredRoute {
+		t.Fatalf("no bond expired notification")
+	}
+	if tier := client.bondTier(); tier != 0 {
+		t.Fatalf("wrong tier after expiry %d", tier)
+	}
+	if _, suspended := rig.mgr.Suspended(user.acctID); !suspended {
+		t.Fatalf("user with expired bond not suspended")
+	}
+	if limit = rig.mgr.UserSettlingLimit(user.acctID, mkt); limit != 0 {
+		t.Fatalf("non-zero settling limit for tier 0 user")
+	}
+
+	// The account can still reconnect at tier 0 with only expired bonds.
+	respMsg = connectUser(t, user)
+	res = extractConnectResult(t, respMsg)
+	if res.Tier == nil || *res.Tier != 0 {
+		t.Fatalf("wrong tier in connect result for expired bonds")
+	}
+	if len(res.ActiveBonds) != 0 {
+		t.Fatalf("expected no active bonds, got %d", len(res.ActiveBonds))
+	}
+	if _, suspended := rig.mgr.Suspended(user.acctID); !suspended {
+		t.Fatalf("reconnected user with expired bond not suspended")
+	}
+}
+
 func TestAuthManager_RecordCancel_RecordCompletedOrder(t *testing.T) {
 	resetStorage()
 	user := tNewUser(t)
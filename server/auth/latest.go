@@ -87,6 +87,37 @@ func (la *latestMatchOutcomes) binViolations() map[Violation]int64 {
 	return bins
 }
 
+// MatchOutcome is a scored match outcome of an account.
+type MatchOutcome struct {
+	ID      order.MatchID
+	Time    int64
+	Outcome Violation
+	Score   int32
+	Base    uint32
+	Quote   uint32
+	Value   uint64
+}
+
+// list returns the MatchOutcomes, oldest first.
+func (la *latestMatchOutcomes) list() []*MatchOutcome {
+	la.mtx.Lock()
+	defer la.mtx.Unlock()
+
+	outcomes := make([]*MatchOutcome, 0, len(la.outcomes))
+	for _, mo := range la.outcomes {
+		outcomes = append(outcomes, &MatchOutcome{
+			ID:      mo.mid,
+			Time:    mo.time,
+			Outcome: mo.outcome,
+			Score:   mo.outcome.Score(),
+			Base:    mo.base,
+			Quote:   mo.quote,
+			Value:   mo.value,
+		})
+	}
+	return outcomes
+}
+
 // SwapAmounts breaks down the quantities of completed swaps in four rough
 // categories: successfully swapped (Swapped), failed with counterparty funds
 // locked for the long/maker lock time (StuckLong), failed with counterparty
@@ -201,3 +232,32 @@ func (la *latestPreimageOutcomes) misses() (misses int32) {
 	}
 	return
 }
+
+// PreimageOutcome is a scored preimage request outcome of an account.
+type PreimageOutcome struct {
+	OrderID order.OrderID
+	Time    int64
+	Miss    bool
+	Score   int32
+}
+
+// list returns the PreimageOutcomes, oldest first.
+func (la *latestPreimageOutcomes) list() []*PreimageOutcome {
+	la.mtx.Lock()
+	defer la.mtx.Unlock()
+
+	outcomes := make([]*PreimageOutcome, 0, len(la.outcomes))
+	for _, po := range la.outcomes {
+		var score int32
+		if po.miss {
+			score = ViolationPreimageMiss.Score()
+		}
+		outcomes = append(outcomes, &PreimageOutcome{
+			OrderID: po.oid,
+			Time:    po.time,
+			Miss:    po.miss,
+			Score:   score,
+		})
+	}
+	return outcomes
+}
@@ -4,6 +4,8 @@
 package auth
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -398,3 +400,247 @@ func (auth *AuthManager) coinNotFound(acctID account.AccountID, msgID uint64, co
 		log.Infof("Failed to send coin-not-found error to user %s: %v", acctID, err)
 	}
 }
+
+// handlePostBond handles the 'postbond' request, which is used to create a new
+// account funded by a fidelity bond, or to add a bond to an existing account.
+// The bond transaction must have the required number of confirmations.
+func (auth *AuthManager) handlePostBond(conn comms.Link, msg *msgjson.Message) *msgjson.Error {
+	postBond := new(msgjson.PostBond)
+	err := msg.Unmarshal(&postBond)
+	if err != nil || postBond == nil /* null payload */ {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "error parsing postbond request",
+		}
+	}
+
+	bondAsset := auth.bondAssets[postBond.AssetID]
+	if bondAsset == nil || auth.checkBond == nil {
+		return &msgjson.Error{
+			Code:    msgjson.BondError,
+			Message: fmt.Sprintf("%s does not support bonds", dex.BipIDSymbol(postBond.AssetID)),
+		}
+	}
+	if postBond.Version > bondAsset.Version {
+		return &msgjson.Error{
+			Code:    msgjson.BondError,
+			Message: fmt.Sprintf("unsupported bond version %d", postBond.Version),
+		}
+	}
+
+	// Create account.Account from pubkey.
+	acct, err := account.NewAccountFromPubKey(postBond.AcctPubKey)
+	if err != nil {
+		return &msgjson.Error{
+			Code:    msgjson.PubKeyParseError,
+			Message: "error parsing pubkey: " + err.Error(),
+		}
+	}
+
+	// Check signature.
+	err = checkSigS256(postBond.Serialize(), postBond.SigBytes(), acct.PubKey)
+	if err != nil {
+		return &msgjson.Error{
+			Code:    msgjson.SignatureError,
+			Message: "signature error: " + err.Error(),
+		}
+	}
+
+	// A closed account cannot be reopened with a bond.
+	existingAcct, _, open := auth.storage.Account(acct.ID)
+	if existingAcct != nil && !open {
+		return &msgjson.Error{
+			Code:    msgjson.AccountClosedError,
+			Message: "account closed and cannot be reopened",
+		}
+	}
+
+	auth.feeWaiterMtx.Lock()
+	if _, found := auth.feeWaiterIdx[acct.ID]; found {
+		auth.feeWaiterMtx.Unlock()
+		return &msgjson.Error{
+			Code:    msgjson.BondError,
+			Message: "already looking for a bond or fee coin, try again later",
+		}
+	}
+	auth.feeWaiterIdx[acct.ID] = struct{}{}
+	auth.feeWaiterMtx.Unlock()
+
+	removeWaiter := func() {
+		auth.feeWaiterMtx.Lock()
+		delete(auth.feeWaiterIdx, acct.ID)
+		auth.feeWaiterMtx.Unlock()
+	}
+
+	auth.latencyQ.Wait(&wait.Waiter{
+		Expiration: time.Now().Add(txWaitExpiration),
+		TryFunc: func() wait.TryDirective {
+			res := auth.validateBond(conn, msg.ID, acct, postBond, bondAsset)
+			if res == wait.DontTryAgain {
+				removeWaiter()
+			}
+			return res
+		},
+		ExpireFunc: func() {
+			removeWaiter()
+			resp, err := msgjson.NewResponse(msg.ID, nil, &msgjson.Error{
+				Code:    msgjson.TransactionUndiscovered,
+				Message: fmt.Sprintf("failed to find confirmed bond transaction %x", postBond.CoinID),
+			})
+			if err != nil {
+				log.Errorf("error encoding postbond error response: %v", err)
+				return
+			}
+			if err = conn.Send(resp); err != nil {
+				log.Warnf("error sending postbond error response: %v", err)
+			}
+		},
+	})
+	return nil
+}
+
+// validateBond is a coin waiter that validates a client's postbond request,
+// stores the bond, and responds with a PostBondResult.
+func (auth *AuthManager) validateBond(conn comms.Link, msgID uint64, acct *account.Account,
+	postBond *msgjson.PostBond, bondAsset *msgjson.BondAsset) wait.TryDirective {
+	// If there is a problem, respond with an error.
+	var msgErr *msgjson.Error
+	defer func() {
+		if msgErr == nil {
+			return
+		}
+		resp, err := msgjson.NewResponse(msgID, nil, msgErr)
+		if err != nil {
+			log.Errorf("error encoding postbond error response: %v", err)
+			return
+		}
+		err = conn.Send(resp)
+		if err != nil {
+			log.Warnf("error sending postbond error response: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	amt, lockTime, confs, commitAcct, err := auth.checkBond(ctx, postBond.AssetID, postBond.Version, postBond.CoinID)
+	if err != nil {
+		if errors.Is(err, asset.CoinNotFoundError) {
+			return wait.TryAgain
+		}
+		log.Infof("Invalid bond %s for account %v: %v", coinIDString(postBond.AssetID, postBond.CoinID), acct.ID, err)
+		msgErr = &msgjson.Error{
+			Code:    msgjson.BondError,
+			Message: "invalid bond: " + err.Error(),
+		}
+		return wait.DontTryAgain
+	}
+	if confs < int64(bondAsset.Confs) {
+		return wait.TryAgain
+	}
+
+	if commitAcct != acct.ID {
+		msgErr = &msgjson.Error{
+			Code:    msgjson.BondError,
+			Message: "bond is committed to a different account",
+		}
+		return wait.DontTryAgain
+	}
+	if amt < int64(bondAsset.Amt) {
+		msgErr = &msgjson.Error{
+			Code:    msgjson.BondError,
+			Message: fmt.Sprintf("bond amount %d is less than the minimum %d", amt, bondAsset.Amt),
+		}
+		return wait.DontTryAgain
+	}
+	expiry := time.Unix(lockTime, 0).Add(-auth.bondExpiry)
+	if time.Until(expiry) <= 0 {
+		msgErr = &msgjson.Error{
+			Code:    msgjson.BondError,
+			Message: fmt.Sprintf("bond lock time %v is too soon, must be later than %v", time.Unix(lockTime, 0), time.Now().Add(auth.bondExpiry)),
+		}
+		return wait.DontTryAgain
+	}
+
+	bond := &db.Bond{
+		Version:  postBond.Version,
+		AssetID:  postBond.AssetID,
+		CoinID:   postBond.CoinID,
+		Amount:   amt,
+		Strength: uint32(uint64(amt) / bondAsset.Amt),
+		LockTime: lockTime,
+	}
+
+	// Check if the bond is already known, making the request idempotent.
+	activeBonds, err := auth.storage.Bonds(acct.ID, time.Now().Add(auth.bondExpiry))
+	if err != nil {
+		log.Errorf("Failed to load bonds for account %v: %v", acct.ID, err)
+		msgErr = &msgjson.Error{
+			Code:    msgjson.RPCInternalError,
+			Message: "internal error",
+		}
+		return wait.DontTryAgain
+	}
+	var known bool
+	for _, b := range activeBonds {
+		if b.AssetID == bond.AssetID && bytes.Equal(b.CoinID, bond.CoinID) {
+			known = true
+			break
+		}
+	}
+
+	if !known {
+		existingAcct, paid, _ := auth.storage.Account(acct.ID)
+		if existingAcct == nil {
+			err = auth.storage.CreateAccountWithBond(acct, bond)
+		} else {
+			err = auth.storage.AddBond(acct.ID, bond)
+		}
+		if err != nil {
+			log.Errorf("Failed to store bond %s for account %v: %v",
+				coinIDString(bond.AssetID, bond.CoinID), acct.ID, err)
+			msgErr = &msgjson.Error{
+				Code:    msgjson.RPCInternalError,
+				Message: "failed to store bond",
+			}
+			return wait.DontTryAgain
+		}
+		activeBonds = append(activeBonds, bond)
+		log.Infof("New bond %s (strength %d, lock time %v) posted for account %v (legacy fee paid = %v)",
+			coinIDString(bond.AssetID, bond.CoinID), bond.Strength, time.Unix(lockTime, 0), acct.ID, paid)
+	}
+
+	// Update the tier of a connected client. Otherwise, the tier is computed
+	// from the stored bonds on connect.
+	var tier int64
+	if client := auth.user(acct.ID); client != nil {
+		tier = client.bondTier()
+		if !known {
+			tier = client.addBond(bond)
+		}
+	} else {
+		_, paid, _ := auth.storage.Account(acct.ID)
+		tier = bondTier(activeBonds, paid)
+	}
+
+	postBondRes := &msgjson.PostBondResult{
+		AccountID: acct.ID[:],
+		AssetID:   bond.AssetID,
+		Amount:    uint64(amt),
+		Expiry:    uint64(expiry.Unix()),
+		BondID:    bond.CoinID,
+		Tier:      tier,
+	}
+	auth.Sign(postBondRes)
+	resp, err := msgjson.NewResponse(msgID, postBondRes, nil)
+	if err != nil {
+		msgErr = &msgjson.Error{
+			Code:    msgjson.RPCInternalError,
+			Message: "internal encoding error",
+		}
+		return wait.DontTryAgain
+	}
+	if err = conn.Send(resp); err != nil {
+		log.Warnf("error sending postbond result to link: %v", err)
+	}
+	return wait.DontTryAgain
+}
@@ -29,6 +29,7 @@ type Book struct {
 	sells        *OrderPQ
 	acctTracking AccountTracking
 	acctTracker  *accountTracker
+	expiries     *expiryTracker
 }
 
 // New creates a new order book with the given lot size and account-tracking.
@@ -39,6 +40,7 @@ func New(lotSize uint64, acctTracking AccountTracking) *Book {
 		sells:        NewMinOrderPQ(initBookHalfCapacity),
 		acctTracking: acctTracking,
 		acctTracker:  newAccountTracker(acctTracking),
+		expiries:     newExpiryTracker(),
 	}
 }
 
@@ -49,6 +51,7 @@ func (b *Book) Clear() {
 	b.buys = NewMaxOrderPQ(initBookHalfCapacity)
 	b.sells = NewMinOrderPQ(initBookHalfCapacity)
 	b.acctTracker = newAccountTracker(b.acctTracking)
+	b.expiries = newExpiryTracker()
 	b.mtx.Unlock()
 }
 
@@ -102,12 +105,14 @@ func (b *Book) Insert(o *order.LimitOrder) bool {
 	if o.Sell {
 		if b.sells.Insert(o) {
 			b.acctTracker.add(o)
+			b.expiries.add(o)
 			return true
 		}
 		return false
 	}
 	if b.buys.Insert(o) {
 		b.acctTracker.add(o)
+		b.expiries.add(o)
 		return true
 	}
 	return false
@@ -119,25 +124,70 @@ func (b *Book) Remove(oid order.OrderID) (*order.LimitOrder, bool) {
 	defer b.mtx.Unlock()
 	if removed, ok := b.sells.RemoveOrderID(oid); ok {
 		b.acctTracker.remove(removed)
+		b.expiries.remove(removed)
 		return removed, true
 	}
 	if removed, ok := b.buys.RemoveOrderID(oid); ok {
 		b.acctTracker.remove(removed)
+		b.expiries.remove(removed)
 		return removed, true
 	}
 	return nil, false
 }
 
+// RemoveExpired removes the good-til-time orders that have expired as of the
+// close of the specified epoch from the book, and returns them.
+func (b *Book) RemoveExpired(epochIdx uint64) []*order.LimitOrder {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	expired := b.expiries.expired(epochIdx)
+	removed := make([]*order.LimitOrder, 0, len(expired))
+	for _, lo := range expired {
+		pq := b.buys
+		if lo.Sell {
+			pq = b.sells
+		}
+		if _, ok := pq.RemoveOrderID(lo.ID()); ok {
+			b.acctTracker.remove(lo)
+			removed = append(removed, lo)
+		}
+	}
+	return removed
+}
+
+// Requeue sets the time priority of a booked order, placing it behind other
+// orders at the same rate that were booked or requeued earlier. This is used to
+// replenish the displayed quantity of an iceberg order.
+func (b *Book) Requeue(oid order.OrderID, stamp int64) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.sells.Requeue(oid, stamp) || b.buys.Requeue(oid, stamp)
+}
+
+// Priority returns the time priority stamp of a booked order, which differs
+// from the order's server time if it was requeued. The boolean return
+// indicates if the order is on the book.
+func (b *Book) Priority(oid order.OrderID) (int64, bool) {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	if stamp, found := b.sells.Priority(oid); found {
+		return stamp, true
+	}
+	return b.buys.Priority(oid)
+}
+
 // RemoveUserOrders removes all orders from the book that belong to a user. The
 // removed buy and sell orders are returned.
 func (b *Book) RemoveUserOrders(user account.AccountID) (removedBuys, removedSells []*order.LimitOrder) {
 	removedBuys = b.buys.RemoveUserOrders(user)
 	for _, lo := range removedBuys {
 		b.acctTracker.remove(lo)
+		b.expiries.remove(lo)
 	}
 	removedSells = b.sells.RemoveUserOrders(user)
 	for _, lo := range removedSells {
 		b.acctTracker.remove(lo)
+		b.expiries.remove(lo)
 	}
 	return
 }
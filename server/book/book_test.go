@@ -326,3 +326,56 @@ func TestAccountTracking(t *testing.T) {
 		t.Fatalf("quote asset not cleared")
 	}
 }
+
+func TestRemoveExpired(t *testing.T) {
+	b := newBook(t)
+
+	newGTT := func(sell bool, rate, expiry uint64) *order.LimitOrder {
+		lo := newLimitOrder(sell, rate, 1, order.GoodTilTimeTiF, 0)
+		lo.ExpiryEpoch = expiry
+		if !b.Insert(lo) {
+			t.Fatalf("Failed to insert good-til-time order %v", lo)
+		}
+		return lo
+	}
+	expiredBuy := newGTT(false, 3000000, 10)
+	expiredSell := newGTT(true, 7000000, 9)
+	canceled := newGTT(true, 7100000, 10)
+	unexpired := newGTT(false, 3100000, 11)
+	if _, ok := b.Remove(canceled.ID()); !ok {
+		t.Fatalf("Failed to remove order %v", canceled)
+	}
+
+	if expired := b.RemoveExpired(8); len(expired) != 0 {
+		t.Fatalf("Expected no expired orders at epoch 8, got %d", len(expired))
+	}
+
+	expired := b.RemoveExpired(10)
+	if len(expired) != 2 {
+		t.Fatalf("Expected 2 expired orders at epoch 10, got %d", len(expired))
+	}
+	for _, lo := range expired {
+		if lo != expiredBuy && lo != expiredSell {
+			t.Fatalf("Unexpected expired order %v", lo)
+		}
+		if b.HaveOrder(lo.ID()) {
+			t.Fatalf("Expired order %v still booked", lo)
+		}
+	}
+	if !b.HaveOrder(unexpired.ID()) {
+		t.Fatalf("Unexpired order %v not booked", unexpired)
+	}
+	if b.BuyCount() != len(bookBuyOrders)+1 || b.SellCount() != len(bookSellOrders) {
+		t.Fatalf("Wrong book size after expiry")
+	}
+
+	// An order that is removed and booked again expires once.
+	b.Remove(unexpired.ID())
+	b.Insert(unexpired)
+	if expired := b.RemoveExpired(11); len(expired) != 1 || expired[0] != unexpired {
+		t.Fatalf("Expected the unexpired order to expire at epoch 11, got %d orders", len(expired))
+	}
+	if len(b.expiries.orders) != 0 || len(b.expiries.epochs) != 0 {
+		t.Fatalf("Expiry index not empty")
+	}
+}
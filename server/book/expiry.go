@@ -0,0 +1,82 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package book
+
+import (
+	"container/heap"
+
+	"decred.org/dcrdex/dex/order"
+)
+
+// epochHeap is a min-heap of epoch indexes. It implements heap.Interface.
+type epochHeap []uint64
+
+func (h epochHeap) Len() int           { return len(h) }
+func (h epochHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h epochHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *epochHeap) Push(x interface{}) {
+	*h = append(*h, x.(uint64))
+}
+
+func (h *epochHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	epoch := old[n-1]
+	*h = old[:n-1]
+	return epoch
+}
+
+// expiryTracker indexes good-til-time orders by expiry epoch so that expired
+// orders can be found without scanning the book. Every epoch in orders is in
+// the epochs heap exactly once. An epoch's order map is kept when it becomes
+// empty, and is only deleted when the epoch is popped from the heap. The
+// expiryTracker is not thread-safe. In use, synchronization is provided by the
+// *Book's mutex.
+type expiryTracker struct {
+	orders map[uint64]map[order.OrderID]*order.LimitOrder
+	epochs epochHeap
+}
+
+func newExpiryTracker() *expiryTracker {
+	return &expiryTracker{
+		orders: make(map[uint64]map[order.OrderID]*order.LimitOrder),
+	}
+}
+
+// add an order to tracking. Orders that are not good-til-time are ignored.
+func (t *expiryTracker) add(lo *order.LimitOrder) {
+	if lo.Force != order.GoodTilTimeTiF {
+		return
+	}
+	los, found := t.orders[lo.ExpiryEpoch]
+	if !found {
+		los = make(map[order.OrderID]*order.LimitOrder, 1)
+		t.orders[lo.ExpiryEpoch] = los
+		heap.Push(&t.epochs, lo.ExpiryEpoch)
+	}
+	los[lo.ID()] = lo
+}
+
+// remove an order from tracking.
+func (t *expiryTracker) remove(lo *order.LimitOrder) {
+	if lo.Force != order.GoodTilTimeTiF {
+		return
+	}
+	delete(t.orders[lo.ExpiryEpoch], lo.ID())
+}
+
+// expired removes the orders that have expired as of the close of the
+// specified epoch from tracking, and returns them.
+func (t *expiryTracker) expired(epochIdx uint64) []*order.LimitOrder {
+	var expired []*order.LimitOrder
+	for len(t.epochs) > 0 && t.epochs[0] <= epochIdx {
+		epoch := heap.Pop(&t.epochs).(uint64)
+		for _, lo := range t.orders[epoch] {
+			expired = append(expired, lo)
+		}
+		delete(t.orders, epoch)
+	}
+	return expired
+}
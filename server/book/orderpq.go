@@ -17,6 +17,10 @@ import (
 type orderEntry struct {
 	order   *order.LimitOrder
 	heapIdx int
+	// stamp is the time priority of the order among orders with the same rate.
+	// It is the order's time when booked, and is reset when an iceberg order's
+	// displayed quantity is replenished. See Requeue.
+	stamp int64
 }
 
 type orderHeap []*orderEntry
@@ -97,6 +101,7 @@ func (pq *OrderPQ) copy(newCap uint32) *OrderPQ {
 		entry := &orderEntry{ // alloc_objects hot spot
 			oe.order,
 			oe.heapIdx,
+			oe.stamp,
 		}
 		newPQ.push(entry)
 	}
@@ -125,17 +130,21 @@ func (pq *OrderPQ) UnfilledForUser(user account.AccountID) []*order.LimitOrder {
 func (pq *OrderPQ) Orders() []*order.LimitOrder {
 	// Deep copy the orders.
 	pq.mtx.RLock()
-	orders := make([]*order.LimitOrder, len(pq.oh))
+	entries := make([]orderEntry, len(pq.oh))
 	for i, oe := range pq.oh {
-		orders[i] = oe.order
+		entries[i] = *oe
 	}
 	pq.mtx.RUnlock()
 
-	// Sort the orders with pq.lessFn.
-	sort.Slice(orders, func(i, j int) bool {
-		return pq.lessFn(orders[i], orders[j])
+	// Sort the orders with pq.lessFn, respecting any requeued stamps.
+	sort.Slice(entries, func(i, j int) bool {
+		return pq.less(&entries[i], &entries[j])
 	})
 
+	orders := make([]*order.LimitOrder, len(entries))
+	for i, oe := range entries {
+		orders[i] = oe.order
+	}
 	return orders
 }
 
@@ -229,7 +238,17 @@ func (pq *OrderPQ) Len() int {
 // OrderPQ.SetLessFn to define the desired behavior for the orderEntry heap[i]
 // and heap[j]. Less is required for heap.Interface. It is not thread-safe.
 func (pq *OrderPQ) Less(i, j int) bool {
-	return pq.lessFn(pq.oh[i].order, pq.oh[j].order)
+	return pq.less(pq.oh[i], pq.oh[j])
+}
+
+// less compares two orderEntry. Orders with the same rate and different stamps
+// (i.e. a requeued order) are prioritized by stamp. Otherwise, the lessFn is
+// used.
+func (pq *OrderPQ) less(oi, oj *orderEntry) bool {
+	if oi.stamp != oj.stamp && oi.order.Rate == oj.order.Rate {
+		return oi.stamp < oj.stamp
+	}
+	return pq.lessFn(oi.order, oj.order)
 }
 
 // Swap swaps the orderEntry at i and j. This is used by container/heap. Swap is
@@ -257,6 +276,7 @@ func (pq *OrderPQ) Push(ord interface{}) {
 	entry := &orderEntry{
 		order:   lo,
 		heapIdx: len(pq.oh),
+		stamp:   lo.Time(),
 	}
 	pq.push(entry)
 }
@@ -396,6 +416,7 @@ func (pq *OrderPQ) Reset(orders []*order.LimitOrder) {
 		entry := &orderEntry{
 			order:   lo,
 			heapIdx: i,
+			stamp:   lo.Time(),
 		}
 		pq.push(entry)
 	}
@@ -441,6 +462,38 @@ func (pq *OrderPQ) Insert(ord *order.LimitOrder) bool {
 	return true
 }
 
+// Requeue sets the time priority of the order with the given ID, moving it
+// behind any orders at the same rate with an earlier stamp. An order is never
+// moved ahead of its current priority. This is used when the displayed
+// quantity of an iceberg order is replenished from its hidden reserve. The
+// return value indicates if the order was found.
+func (pq *OrderPQ) Requeue(oid order.OrderID, stamp int64) bool {
+	pq.mtx.Lock()
+	defer pq.mtx.Unlock()
+	oe := pq.orders[oid]
+	if oe == nil {
+		return false
+	}
+	if stamp > oe.stamp {
+		oe.stamp = stamp
+		heap.Fix(pq, oe.heapIdx)
+	}
+	return true
+}
+
+// Priority returns the time priority stamp of the order with the given ID. The
+// stamp is the order's server time unless the order was requeued. The boolean
+// return indicates if the order was found.
+func (pq *OrderPQ) Priority(oid order.OrderID) (int64, bool) {
+	pq.mtx.RLock()
+	defer pq.mtx.RUnlock()
+	oe := pq.orders[oid]
+	if oe == nil {
+		return 0, false
+	}
+	return oe.stamp, true
+}
+
 // RemoveOrder attempts to remove the provided order from the priority queue
 // based on it's ID.
 func (pq *OrderPQ) RemoveOrder(lo *order.LimitOrder) (*order.LimitOrder, bool) {
@@ -489,7 +542,8 @@ func (pq *OrderPQ) Order(oid order.OrderID) *order.LimitOrder {
 	return nil
 }
 
-// UserOrderTotals returns the total value and number of booked orders.
+// UserOrderTotals returns the total value and number of booked orders. The
+// value includes the hidden reserve of any iceberg orders.
 func (pq *OrderPQ) UserOrderTotals(user account.AccountID) (amt, count uint64) {
 	pq.mtx.Lock()
 	defer pq.mtx.Unlock()
@@ -545,11 +599,11 @@ func (pq *OrderPQ) Worst() *order.LimitOrder {
 	case 1:
 		return leaves[0].order
 	}
-	worst := leaves[0].order
+	worst := leaves[0]
 	for i := 0; i < len(leaves)-1; i++ {
-		if pq.lessFn(worst, leaves[i+1].order) {
-			worst = leaves[i+1].order
+		if pq.less(worst, leaves[i+1]) {
+			worst = leaves[i+1]
 		}
 	}
-	return worst
+	return worst.order
 }
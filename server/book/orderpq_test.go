@@ -438,6 +438,52 @@ func TestMaxOrderPQ_TieRateAndTime(t *testing.T) {
 	}
 }
 
+func TestMaxOrderPQ_Requeue(t *testing.T) {
+	pq := NewMaxOrderPQ(4)
+
+	for _, o := range orders[:4] {
+		if !pq.Insert(o) {
+			t.Fatalf("Failed to insert order %v", o)
+		}
+	}
+
+	// orders[2] is best at the tied rate until requeued behind orders[0].
+	if best := pq.PeekBest(); best.UID() != orders[3].UID() {
+		t.Fatalf("Incorrect best order %v", best)
+	}
+	if !pq.Requeue(orders[2].ID(), orders[0].Time()+1) {
+		t.Fatalf("Failed to requeue order %v", orders[2])
+	}
+	if pq.Requeue(orders[4].ID(), 0) {
+		t.Fatalf("Requeued an order that is not in the queue")
+	}
+	if stamp, found := pq.Priority(orders[2].ID()); !found || stamp != orders[0].Time()+1 {
+		t.Fatalf("Wrong priority %d for requeued order", stamp)
+	}
+	if stamp, found := pq.Priority(orders[0].ID()); !found || stamp != orders[0].Time() {
+		t.Fatalf("Wrong priority %d for order", stamp)
+	}
+	if _, found := pq.Priority(orders[4].ID()); found {
+		t.Fatalf("Priority found for an order that is not in the queue")
+	}
+
+	// Requeuing only reorders orders at the same rate.
+	want := []*Order{orders[3], orders[0], orders[2], orders[1]}
+	for i, o := range pq.Orders() {
+		if o.UID() != want[i].UID() {
+			t.Fatalf("Orders: wrong order at position %d", i)
+		}
+	}
+	if worst := pq.Worst(); worst.UID() != orders[1].UID() {
+		t.Fatalf("Incorrect worst order %v", worst)
+	}
+	for i, o := range pq.ExtractN(4) {
+		if o.UID() != want[i].UID() {
+			t.Fatalf("ExtractN: wrong order at position %d", i)
+		}
+	}
+}
+
 func TestOrderPQCapacity(t *testing.T) {
 	pq := NewMaxOrderPQ(2)
 
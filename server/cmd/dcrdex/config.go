@@ -40,6 +40,8 @@ const (
 	defaultLogDirname          = "logs"
 	defaultMarketsConfFilename = "markets.json"
 	defaultMaxLogZips          = 128
+	defaultDBDriver            = "pg"
+	defaultSQLiteFilename      = "dcrdex.sqlite"
 	defaultPGHost              = "127.0.0.1:5432"
 	defaultPGUser              = "dcrdex"
 	defaultPGDBName            = "dcrdex_{netname}"
@@ -54,6 +56,7 @@ const (
 
 	defaultCancelThresh     = 0.95             // 19 cancels : 1 success
 	defaultBroadcastTimeout = 12 * time.Minute // accommodate certain known long block download timeouts
+	defaultBondExpiry       = 30 * 24 * time.Hour
 )
 
 var (
@@ -69,6 +72,8 @@ type procOpts struct {
 type dexConf struct {
 	DataDir           string
 	Network           dex.Network
+	DBDriver          string
+	SQLitePath        string
 	DBName            string
 	DBUser            string
 	DBPass            string
@@ -92,14 +97,18 @@ type dexConf struct {
 	RPCListen         []string
 	HiddenService     string
 	BroadcastTimeout  time.Duration
+	BondExpiry        time.Duration
 	AltDNSNames       []string
 	LogMaker          *dex.LoggerMaker
 	SigningKeyPW      []byte
 	AdminSrvOn        bool
 	AdminSrvAddr      string
 	AdminSrvPW        []byte
+	AdminSrvMetrics   bool
 	NoResumeSwaps     bool
 	DisableDataAPI    bool
+	RateLimits        *comms.RateLimits
+	RateTiers         []*comms.RateTier
 }
 
 type flagsData struct {
@@ -125,6 +134,7 @@ type flagsData struct {
 	MarketsConfPath  string        `long:"marketsconfpath" description:"Path to the markets configuration JSON file."`
 	BroadcastTimeout time.Duration `long:"bcasttimeout" description:"The broadcast timeout specifies how long clients have to broadcast an expected transaction when it is their turn to act. Matches without the expected action by this time are revoked and the actor is penalized."`
 	DEXPrivKeyPath   string        `long:"dexprivkeypath" description:"The path to a file containing the DEX private key for message signing."`
+	BondExpiry       time.Duration `long:"bondexpiry" description:"How long before a fidelity bond's lock time expires that the bond no longer counts toward an account's tier."`
 
 	// Deprecated fields that specify the Decred-specific registration fee
 	// config. This information is now specified per-asset in markets.json.
@@ -143,6 +153,8 @@ type flagsData struct {
 	HTTPProfile bool   `long:"httpprof" short:"p" description:"Start HTTP profiler."`
 	CPUProfile  string `long:"cpuprofile" description:"File for CPU profiling."`
 
+	DBDriver           string `long:"dbdriver" description:"The server DB driver. Must be pg (PostgreSQL) or sqlite (embedded)."`
+	SQLitePath         string `long:"sqlitepath" description:"Path to the SQLite DB file when dbdriver=sqlite. Relative paths are in the network data directory."`
 	PGDBName           string `long:"pgdbname" description:"PostgreSQL DB name."`
 	PGUser             string `long:"pguser" description:"PostgreSQL DB user."`
 	PGPass             string `long:"pgpass" description:"PostgreSQL DB password."`
@@ -152,10 +164,15 @@ type flagsData struct {
 	AdminSrvOn         bool   `long:"adminsrvon" description:"Turn on the admin server."`
 	AdminSrvAddr       string `long:"adminsrvaddr" description:"Administration HTTPS server address (default: 127.0.0.1:6542)."`
 	AdminSrvPassword   string `long:"adminsrvpass" description:"Admin server password. INSECURE. Do not set unless absolutely necessary."`
+	AdminSrvMetrics    bool   `long:"adminsrvmetrics" description:"Serve Prometheus metrics at /metrics on the admin server."`
 
 	NoResumeSwaps bool `long:"noresumeswaps" description:"Do not attempt to resume swaps that are active in the DB."`
 
 	DisableDataAPI bool `long:"nodata" description:"Disable the HTTP data API."`
+
+	WSRateLimits   []string `long:"wsratelimit" description:"A per-IP websocket route rate limit as group:rate:burst, where rate is requests per second. Groups are status, order, info, subs, register, connect, and total. May be repeated."`
+	HTTPRateLimits []string `long:"httpratelimit" description:"An HTTP data API rate limit as global:rate:burst or ip:rate:burst, where rate is requests per second. May be repeated."`
+	RateTiers      []string `long:"ratetier" description:"An account rate tier for the order routes as name:maxscore:rate:burst. Authenticated accounts with a score at or below maxscore use the tier's order rate limit. May be repeated."`
 }
 
 // supportedSubsystems returns a sorted slice of the supported subsystems for
@@ -241,6 +258,89 @@ func normalizeNetworkAddress(a, defaultHost, defaultPort string) (string, error)
 	return net.JoinHostPort(host, port), nil
 }
 
+// parseRateLimit parses a rate limit from its rate and burst strings.
+func parseRateLimit(rateStr, burstStr string) (comms.RateLimit, error) {
+	r, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil {
+		return comms.RateLimit{}, fmt.Errorf("invalid rate %q: %w", rateStr, err)
+	}
+	burst, err := strconv.Atoi(burstStr)
+	if err != nil {
+		return comms.RateLimit{}, fmt.Errorf("invalid burst %q: %w", burstStr, err)
+	}
+	return comms.RateLimit{Rate: r, Burst: burst}, nil
+}
+
+// parseRateLimits applies the websocket and HTTP rate limit settings, each of
+// the form group:rate:burst, to the default rate limits.
+func parseRateLimits(wsLimits, httpLimits []string) (*comms.RateLimits, error) {
+	limits := comms.DefaultRateLimits()
+	wsGroups := map[string]*comms.RateLimit{
+		"status":   &limits.Status,
+		"order":    &limits.Order,
+		"info":     &limits.Info,
+		"subs":     &limits.Subs,
+		"register": &limits.Register,
+		"connect":  &limits.Connect,
+		"total":    &limits.Total,
+	}
+	httpGroups := map[string]*comms.RateLimit{
+		"global": &limits.HTTPGlobal,
+		"ip":     &limits.HTTPIP,
+	}
+	apply := func(settings []string, groups map[string]*comms.RateLimit) error {
+		for _, s := range settings {
+			parts := strings.Split(s, ":")
+			if len(parts) != 3 {
+				return fmt.Errorf("rate limit %q is not of the form group:rate:burst", s)
+			}
+			limit, found := groups[strings.ToLower(parts[0])]
+			if !found {
+				return fmt.Errorf("unknown rate limit group %q", parts[0])
+			}
+			l, err := parseRateLimit(parts[1], parts[2])
+			if err != nil {
+				return fmt.Errorf("rate limit %q: %w", s, err)
+			}
+			*limit = l
+		}
+		return nil
+	}
+	if err := apply(wsLimits, wsGroups); err != nil {
+		return nil, err
+	}
+	if err := apply(httpLimits, httpGroups); err != nil {
+		return nil, err
+	}
+	return limits, limits.Validate()
+}
+
+// parseRateTiers parses the account rate tier settings, each of the form
+// name:maxscore:rate:burst.
+func parseRateTiers(settings []string) ([]*comms.RateTier, error) {
+	tiers := make([]*comms.RateTier, 0, len(settings))
+	for _, s := range settings {
+		parts := strings.Split(s, ":")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("rate tier %q is not of the form name:maxscore:rate:burst", s)
+		}
+		maxScore, err := strconv.ParseInt(parts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("rate tier %q: invalid max score: %w", s, err)
+		}
+		limit, err := parseRateLimit(parts[2], parts[3])
+		if err != nil {
+			return nil, fmt.Errorf("rate tier %q: %w", s, err)
+		}
+		tiers = append(tiers, &comms.RateTier{
+			Name:     parts[0],
+			MaxScore: int32(maxScore),
+			Order:    limit,
+		})
+	}
+	return tiers, comms.ValidateRateTiers(tiers)
+}
+
 // loadConfig initializes and parses the config using a config file and command
 // line options.
 func loadConfig() (*dexConf, *procOpts, error) {
@@ -257,12 +357,14 @@ func loadConfig() (*dexConf, *procOpts, error) {
 		RPCCert:          defaultRPCCertFilename,
 		RPCKey:           defaultRPCKeyFilename,
 		DebugLevel:       defaultLogLevel,
+		DBDriver:         defaultDBDriver,
 		PGDBName:         defaultPGDBName,
 		PGUser:           defaultPGUser,
 		PGHost:           defaultPGHost,
 		MarketsConfPath:  defaultMarketsConfFilename,
 		DEXPrivKeyPath:   defaultDEXPrivKeyFilename,
 		BroadcastTimeout: defaultBroadcastTimeout,
+		BondExpiry:       defaultBondExpiry,
 		CancelThreshold:  defaultCancelThresh,
 		MaxUserCancels:   defaultMaxUserCancels,
 		BanScore:         defaultBanScore,
@@ -498,6 +600,19 @@ func loadConfig() (*dexConf, *procOpts, error) {
 		log.Infof("Logging with UTC time stamps. Current local time is %v", time.Now().Local().Format("15:04:05 MST"))
 	}
 
+	switch cfg.DBDriver {
+	case "pg", "sqlite":
+	default:
+		return loadConfigError(fmt.Errorf("unknown DB driver %q", cfg.DBDriver))
+	}
+	if cfg.SQLitePath == "" {
+		cfg.SQLitePath = defaultSQLiteFilename
+	}
+	cfg.SQLitePath = dex.CleanAndExpandPath(cfg.SQLitePath)
+	if !filepath.IsAbs(cfg.SQLitePath) {
+		cfg.SQLitePath = filepath.Join(cfg.DataDir, cfg.SQLitePath)
+	}
+
 	var dbPort uint16
 	dbHost := cfg.PGHost
 	// For UNIX sockets, do not attempt to parse out a port.
@@ -527,12 +642,23 @@ func loadConfig() (*dexConf, *procOpts, error) {
 		adminSrvAddr = cfg.AdminSrvAddr
 	}
 
+	rateLimits, err := parseRateLimits(cfg.WSRateLimits, cfg.HTTPRateLimits)
+	if err != nil {
+		return loadConfigError(err)
+	}
+	rateTiers, err := parseRateTiers(cfg.RateTiers)
+	if err != nil {
+		return loadConfigError(err)
+	}
+
 	// If using {netname} then replace it with the network name.
 	cfg.PGDBName = strings.ReplaceAll(cfg.PGDBName, "{netname}", network.String())
 
 	dexCfg := &dexConf{
 		DataDir:           cfg.DataDir,
 		Network:           network,
+		DBDriver:          cfg.DBDriver,
+		SQLitePath:        cfg.SQLitePath,
 		DBName:            cfg.PGDBName,
 		DBHost:            dbHost,
 		DBPort:            dbPort,
@@ -556,14 +682,18 @@ func loadConfig() (*dexConf, *procOpts, error) {
 		RPCListen:         RPCListen,
 		HiddenService:     HiddenService,
 		BroadcastTimeout:  cfg.BroadcastTimeout,
+		BondExpiry:        cfg.BondExpiry,
 		AltDNSNames:       cfg.AltDNSNames,
 		LogMaker:          logMaker,
 		SigningKeyPW:      []byte(cfg.SigningKeyPassword),
 		AdminSrvAddr:      adminSrvAddr,
 		AdminSrvOn:        cfg.AdminSrvOn,
 		AdminSrvPW:        []byte(cfg.AdminSrvPassword),
+		AdminSrvMetrics:   cfg.AdminSrvMetrics,
 		NoResumeSwaps:     cfg.NoResumeSwaps,
 		DisableDataAPI:    cfg.DisableDataAPI,
+		RateLimits:        rateLimits,
+		RateTiers:         rateTiers,
 	}
 
 	opts := &procOpts{
@@ -3,7 +3,11 @@
 
 package main
 
-import "testing"
+import (
+	"testing"
+
+	"decred.org/dcrdex/server/comms"
+)
 
 const (
 	defaultHost = "127.0.0.1"
@@ -50,3 +54,59 @@ func Test_normalizeNetworkAddress(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseRateLimits(t *testing.T) {
+	limits, err := parseRateLimits([]string{"order:10:200", "TOTAL:80:2000"}, []string{"ip:2:10"})
+	if err != nil {
+		t.Fatalf("parseRateLimits error: %v", err)
+	}
+	want := comms.DefaultRateLimits()
+	want.Order = comms.RateLimit{Rate: 10, Burst: 200}
+	want.Total = comms.RateLimit{Rate: 80, Burst: 2000}
+	want.HTTPIP = comms.RateLimit{Rate: 2, Burst: 10}
+	if *limits != *want {
+		t.Fatalf("wrong limits. wanted %+v, got %+v", want, limits)
+	}
+
+	for _, bad := range [][2][]string{
+		{{"order:10"}, nil},
+		{{"orders:10:200"}, nil},
+		{{"order:x:200"}, nil},
+		{{"order:10:0"}, nil},
+		{nil, {"order:10:200"}},
+	} {
+		if _, err := parseRateLimits(bad[0], bad[1]); err == nil {
+			t.Fatalf("no error for %v", bad)
+		}
+	}
+}
+
+func Test_parseRateTiers(t *testing.T) {
+	tiers, err := parseRateTiers([]string{"maker:-20:20:400", "good:0:10:200"})
+	if err != nil {
+		t.Fatalf("parseRateTiers error: %v", err)
+	}
+	want := []comms.RateTier{
+		{Name: "maker", MaxScore: -20, Order: comms.RateLimit{Rate: 20, Burst: 400}},
+		{Name: "good", MaxScore: 0, Order: comms.RateLimit{Rate: 10, Burst: 200}},
+	}
+	if len(tiers) != len(want) {
+		t.Fatalf("expected %d tiers, got %d", len(want), len(tiers))
+	}
+	for i := range want {
+		if *tiers[i] != want[i] {
+			t.Fatalf("wrong tier %d. wanted %+v, got %+v", i, want[i], tiers[i])
+		}
+	}
+
+	for _, bad := range [][]string{
+		{"maker:-20:20"},
+		{"maker:x:20:400"},
+		{":-20:20:400"},
+		{"maker:-20:20:400", "maker:0:10:200"},
+	} {
+		if _, err := parseRateTiers(bad); err == nil {
+			t.Fatalf("no error for %v", bad)
+		}
+	}
+}
@@ -146,6 +146,8 @@ func mainCore(ctx context.Context) error {
 		Assets:     assets,
 		Network:    cfg.Network,
 		DBConf: &dexsrv.DBConf{
+			Driver:       cfg.DBDriver,
+			SQLitePath:   cfg.SQLitePath,
 			DBName:       cfg.DBName,
 			Host:         cfg.DBHost,
 			User:         cfg.DBUser,
@@ -154,6 +156,7 @@ func mainCore(ctx context.Context) error {
 			ShowPGConfig: cfg.ShowPGConfig,
 		},
 		BroadcastTimeout:  cfg.BroadcastTimeout,
+		BondExpiry:        cfg.BondExpiry,
 		CancelThreshold:   cfg.CancelThreshold,
 		Anarchy:           cfg.Anarchy,
 		FreeCancels:       cfg.FreeCancels,
@@ -168,8 +171,13 @@ func mainCore(ctx context.Context) error {
 			AltDNSNames:       cfg.AltDNSNames,
 			DisableDataAPI:    cfg.DisableDataAPI,
 			HiddenServiceAddr: cfg.HiddenService,
+			RateLimits:        cfg.RateLimits,
+			RateTiers:         cfg.RateTiers,
 		},
 		NoResumeSwaps: cfg.NoResumeSwaps,
+		SaveMarkets: func(markets []*dex.MarketInfo) error {
+			return saveMarketConfFile(cfg.Network, cfg.MarketsConfPath, markets)
+		},
 	}
 	dexMan, err := dexsrv.NewDEX(ctx, dexConf) // ctx cancel just aborts setup; Stop does normal shutdown
 	if err != nil {
@@ -184,6 +192,7 @@ func mainCore(ctx context.Context) error {
 			AuthSHA: adminSrvAuthSHA,
 			Cert:    cfg.RPCCert,
 			Key:     cfg.RPCKey,
+			Metrics: cfg.AdminSrvMetrics,
 		}
 		adminServer, err := admin.NewServer(srvCFG)
 		if err != nil {
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"sort"
 	"strings"
@@ -15,17 +16,19 @@ import (
 	dexsrv "decred.org/dcrdex/server/dex"
 )
 
+type marketConf struct {
+	Base           string  `json:"base"`
+	Quote          string  `json:"quote"`
+	LotSize        uint64  `json:"lotSize"`
+	RateStep       uint64  `json:"rateStep"`
+	Duration       uint64  `json:"epochDuration"`
+	MBBuffer       float64 `json:"marketBuyBuffer"`
+	BookedLotLimit uint32  `json:"userBookedLotLimit,omitempty"`
+}
+
 type marketConfig struct {
-	Markets []*struct {
-		Base           string  `json:"base"`
-		Quote          string  `json:"quote"`
-		LotSize        uint64  `json:"lotSize"`
-		RateStep       uint64  `json:"rateStep"`
-		Duration       uint64  `json:"epochDuration"`
-		MBBuffer       float64 `json:"marketBuyBuffer"`
-		BookedLotLimit uint32  `json:"userBookedLotLimit"`
-	} `json:"markets"`
-	Assets map[string]*dexsrv.AssetConf `json:"assets"`
+	Markets []*marketConf                `json:"markets"`
+	Assets  map[string]*dexsrv.AssetConf `json:"assets"`
 }
 
 func loadMarketConfFile(network dex.Network, marketsJSON string) ([]*dex.MarketInfo, []*dexsrv.AssetConf, error) {
@@ -152,3 +155,83 @@ func loadMarketConf(network dex.Network, src io.Reader) ([]*dex.MarketInfo, []*d
 
 	return markets, assets, nil
 }
+
+// saveMarketConfFile replaces the markets for the given network in the markets
+// configuration JSON file. The markets for other networks and all asset
+// configurations are retained. The file is replaced atomically.
+func saveMarketConfFile(network dex.Network, marketsJSON string, markets []*dex.MarketInfo) error {
+	fi, err := os.Stat(marketsJSON)
+	if err != nil {
+		return err
+	}
+	settings, err := os.ReadFile(marketsJSON)
+	if err != nil {
+		return err
+	}
+
+	var conf marketConfig
+	if err = json.Unmarshal(settings, &conf); err != nil {
+		return err
+	}
+	if err = conf.setMarkets(network, markets); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(&conf, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmpPath := marketsJSON + ".tmp"
+	if err = os.WriteFile(tmpPath, append(b, '\n'), fi.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, marketsJSON)
+}
+
+// setMarkets replaces the markets for the given network. The base and quote of
+// each market refer to the keys of the assets configured for the network.
+func (conf *marketConfig) setMarkets(network dex.Network, markets []*dex.MarketInfo) error {
+	onNetwork := func(assetConf *dexsrv.AssetConf) bool {
+		net, err := dex.NetFromString(assetConf.Network)
+		return err == nil && net == network
+	}
+
+	assetKeys := make(map[uint32]string)
+	for key, assetConf := range conf.Assets {
+		if !onNetwork(assetConf) {
+			continue
+		}
+		if assetID, found := dex.BipSymbolID(strings.ToLower(assetConf.Symbol)); found {
+			assetKeys[assetID] = key
+		}
+	}
+
+	mktConfs := make([]*marketConf, 0, len(conf.Markets)+len(markets))
+	for _, mktConf := range conf.Markets {
+		if baseConf, found := conf.Assets[mktConf.Base]; found && onNetwork(baseConf) {
+			continue // replaced below
+		}
+		mktConfs = append(mktConfs, mktConf)
+	}
+
+	for _, mkt := range markets {
+		base, quote := assetKeys[mkt.Base], assetKeys[mkt.Quote]
+		if base == "" || quote == "" {
+			return fmt.Errorf("missing asset configuration for market %s", mkt.Name)
+		}
+		mktConf := &marketConf{
+			Base:     base,
+			Quote:    quote,
+			LotSize:  mkt.LotSize,
+			RateStep: mkt.RateStep,
+			Duration: mkt.EpochDuration,
+			MBBuffer: mkt.MarketBuyBuffer,
+		}
+		if mkt.BookedLotLimit != math.MaxUint32 {
+			mktConf.BookedLotLimit = mkt.BookedLotLimit
+		}
+		mktConfs = append(mktConfs, mktConf)
+	}
+	conf.Markets = mktConfs
+	return nil
+}
@@ -0,0 +1,118 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"decred.org/dcrdex/dex"
+)
+
+const testMarketsJSON = `{
+    "markets": [
+        {
+            "base": "DCR_mainnet",
+            "quote": "BTC_mainnet",
+            "lotSize": 100000000,
+            "rateStep": 100000,
+            "epochDuration": 10000,
+            "marketBuyBuffer": 1.25
+        },
+        {
+            "base": "BTC_testnet",
+            "quote": "LTC_testnet",
+            "lotSize": 100000,
+            "rateStep": 1000000,
+            "epochDuration": 6000,
+            "marketBuyBuffer": 1.25
+        }
+    ],
+    "assets": {
+        "DCR_mainnet": {
+            "bip44symbol": "dcr",
+            "network": "mainnet",
+            "maxFeeRate": 10,
+            "swapConf": 4
+        },
+        "BTC_mainnet": {
+            "bip44symbol": "btc",
+            "network": "mainnet",
+            "maxFeeRate": 100,
+            "swapConf": 4
+        },
+        "DCR_testnet": {
+            "bip44symbol": "dcr",
+            "network": "testnet",
+            "maxFeeRate": 10,
+            "swapConf": 1
+        },
+        "BTC_testnet": {
+            "bip44symbol": "btc",
+            "network": "testnet",
+            "maxFeeRate": 100,
+            "swapConf": 1
+        },
+        "LTC_testnet": {
+            "bip44symbol": "ltc",
+            "network": "testnet",
+            "maxFeeRate": 20,
+            "swapConf": 1
+        }
+    }
+}`
+
+func Test_saveMarketConfFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "markets.json")
+	if err := os.WriteFile(path, []byte(testMarketsJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	mainnetMkts, _, err := loadMarketConfFile(dex.Mainnet, path)
+	if err != nil {
+		t.Fatalf("error loading sample config: %v", err)
+	}
+
+	// Replace the testnet btc_ltc market with dcr_btc and btc_ltc markets.
+	dcrBTC, _ := dex.NewMarketInfoFromSymbols("dcr", "btc", 1e8, 1e3, 6000, 1.5)
+	dcrBTC.BookedLotLimit = 1000
+	btcLTC, _ := dex.NewMarketInfoFromSymbols("btc", "ltc", 2e5, 1e6, 10000, 1.25)
+	if err = saveMarketConfFile(dex.Testnet, path, []*dex.MarketInfo{dcrBTC, btcLTC}); err != nil {
+		t.Fatalf("saveMarketConfFile error: %v", err)
+	}
+
+	testnetMkts, _, err := loadMarketConfFile(dex.Testnet, path)
+	if err != nil {
+		t.Fatalf("error loading saved config: %v", err)
+	}
+	if len(testnetMkts) != 2 {
+		t.Fatalf("expected 2 testnet markets, got %d", len(testnetMkts))
+	}
+	for i, want := range []*dex.MarketInfo{dcrBTC, btcLTC} {
+		if *testnetMkts[i] != *want {
+			t.Fatalf("wrong market %d. wanted %+v, got %+v", i, want, testnetMkts[i])
+		}
+	}
+
+	// The mainnet markets are unchanged.
+	reloadedMainnet, _, err := loadMarketConfFile(dex.Mainnet, path)
+	if err != nil {
+		t.Fatalf("error loading saved config: %v", err)
+	}
+	if len(reloadedMainnet) != len(mainnetMkts) {
+		t.Fatalf("expected %d mainnet markets, got %d", len(mainnetMkts), len(reloadedMainnet))
+	}
+	for i, mkt := range mainnetMkts {
+		if *reloadedMainnet[i] != *mkt {
+			t.Fatalf("mainnet market %d changed. wanted %+v, got %+v", i, mkt, reloadedMainnet[i])
+		}
+	}
+
+	// Markets must use configured assets.
+	dcrDOGE, _ := dex.NewMarketInfoFromSymbols("dcr", "doge", 1e8, 1e3, 6000, 1.5)
+	if err = saveMarketConfFile(dex.Testnet, path, []*dex.MarketInfo{dcrDOGE}); err == nil {
+		t.Fatalf("no error for market with unconfigured asset")
+	}
+}
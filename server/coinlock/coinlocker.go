@@ -37,6 +37,10 @@ type CoinLocker interface {
 	// defined as a map of OrderIDs to a CoinID slice since it is likely easiest
 	// for the caller to construct the input in this way.
 	LockCoins(orderCoins map[order.OrderID][]CoinID) (failed map[order.OrderID][]CoinID)
+	// TransferOrderCoins moves the coins locked by one order to another order,
+	// such as when a booked order is replaced by a new order with the same
+	// funding coins. The coins remain locked throughout.
+	TransferOrderCoins(from, to order.OrderID) bool
 }
 
 // MasterCoinLocker coordinates a book and swap coin locker. The lock status of
@@ -118,6 +122,11 @@ func (bl *bookLocker) UnlockOrderCoins(oid order.OrderID) {
 	bl.bookLock.UnlockOrderCoins(oid)
 }
 
+// TransferOrderCoins moves the locked coins of one order to another.
+func (bl *bookLocker) TransferOrderCoins(from, to order.OrderID) bool {
+	return bl.bookLock.TransferOrderCoins(from, to)
+}
+
 var _ (CoinLocker) = (*bookLocker)(nil)
 
 type swapLocker struct {
@@ -149,6 +158,11 @@ func (sl *swapLocker) UnlockOrdersCoins(oids []order.OrderID) {
 	sl.swapLock.UnlockOrdersCoins(oids)
 }
 
+// TransferOrderCoins moves the locked coins of one order to another.
+func (sl *swapLocker) TransferOrderCoins(from, to order.OrderID) bool {
+	return sl.swapLock.TransferOrderCoins(from, to)
+}
+
 var _ (CoinLocker) = (*swapLocker)(nil)
 
 type coinIDKey string
@@ -270,6 +284,28 @@ ordersLoop:
 	return
 }
 
+// TransferOrderCoins moves the coins locked by the from order to the to order.
+// The return value indicates whether any coins were transferred. It is false if
+// the from order has no locked coins or if the to order already has locked
+// coins.
+func (ac *AssetCoinLocker) TransferOrderCoins(from, to order.OrderID) bool {
+	ac.coinMtx.Lock()
+	defer ac.coinMtx.Unlock()
+	coins := ac.lockedCoinsByOrder[from]
+	if len(coins) == 0 || len(ac.lockedCoinsByOrder[to]) > 0 {
+		return false
+	}
+	for i := range coins {
+		if _, locked := ac.lockedCoins[coinIDKey(coins[i])]; !locked {
+			// The from order's coins were already unlocked.
+			return false
+		}
+	}
+	ac.lockedCoinsByOrder[to] = coins
+	delete(ac.lockedCoinsByOrder, from)
+	return true
+}
+
 // DEXCoinLocker manages multiple MasterCoinLocker, one for each asset used by
 // the DEX.
 type DEXCoinLocker struct {
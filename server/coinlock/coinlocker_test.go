@@ -232,3 +232,43 @@ func Test_bookLocker_LockCoins(t *testing.T) {
 		t.Errorf("bookLock indicated coins were locked that should have been unlocked")
 	}
 }
+
+func Test_bookLocker_TransferOrderCoins(t *testing.T) {
+	masterLock := NewMasterCoinLocker()
+	bookLock := masterLock.Book()
+
+	from, to := randomOrderID(), randomOrderID()
+	coins := []CoinID{randCoinID(), randCoinID()}
+	bookLock.LockCoins(map[order.OrderID][]CoinID{from: coins})
+
+	if bookLock.TransferOrderCoins(to, from) {
+		t.Fatalf("transferred coins from an order with none locked")
+	}
+	if !bookLock.TransferOrderCoins(from, to) {
+		t.Fatalf("failed to transfer coins")
+	}
+	if !verifyLocked(bookLock, coins, true, t) {
+		t.Fatalf("coins unlocked by transfer")
+	}
+	if len(bookLock.OrderCoinsLocked(from)) != 0 {
+		t.Fatalf("coins still locked by the from order")
+	}
+	if len(bookLock.OrderCoinsLocked(to)) != len(coins) {
+		t.Fatalf("coins not locked by the to order")
+	}
+
+	// Unlocking the old order does nothing now.
+	bookLock.UnlockOrderCoins(from)
+	if !verifyLocked(bookLock, coins, true, t) {
+		t.Fatalf("coins unlocked by the from order")
+	}
+	bookLock.UnlockOrderCoins(to)
+	if !verifyLocked(bookLock, coins, false, t) {
+		t.Fatalf("coins not unlocked by the to order")
+	}
+
+	// Can't transfer unlocked coins.
+	if bookLock.TransferOrderCoins(to, from) {
+		t.Fatalf("transferred unlocked coins")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -22,6 +23,7 @@ import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/ws"
+	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/websocket"
 )
 
@@ -32,12 +34,16 @@ var (
 )
 
 func newServer() *Server {
+	limits := DefaultRateLimits()
 	return &Server{
-		clients:     make(map[uint64]*wsLink),
-		wsLimiters:  make(map[dex.IPKey]*ipWsLimiter),
-		v6Prefixes:  make(map[dex.IPKey]int),
-		quarantine:  make(map[dex.IPKey]time.Time),
-		dataEnabled: 1,
+		clients:           make(map[uint64]*wsLink),
+		wsLimiters:        make(map[dex.IPKey]*ipWsLimiter),
+		v6Prefixes:        make(map[dex.IPKey]int),
+		quarantine:        make(map[dex.IPKey]time.Time),
+		dataEnabled:       1,
+		limits:            limits,
+		globalHTTPLimiter: limits.HTTPGlobal.newLimiter(),
+		tiers:             newRateTiers(nil),
 	}
 }
 
@@ -270,7 +276,7 @@ func TestMain(m *testing.M) {
 	testCtx, shutdown = context.WithCancel(context.Background())
 	defer shutdown()
 	// Register dummy handlers for the HTTP routes.
-	for _, route := range []string{msgjson.ConfigRoute, msgjson.SpotsRoute, msgjson.CandlesRoute, msgjson.OrderBookRoute} {
+	for _, route := range []string{msgjson.ConfigRoute, msgjson.SpotsRoute, msgjson.CandlesRoute, msgjson.OrderBookRoute, msgjson.TradesRoute, msgjson.TickerRoute, msgjson.EpochTranscriptRoute} {
 		RegisterHTTP(route, func(interface{}) (interface{}, error) { return nil, nil })
 	}
 	UseLogger(tLogger)
@@ -912,7 +918,7 @@ func (h *tHTTPHandler) ServeHTTP(http.ResponseWriter, *http.Request) {
 
 func TestHTTPRateLimiter(t *testing.T) {
 	tHandler := &tHTTPHandler{}
-	s := Server{dataEnabled: 1}
+	s := newServer()
 
 	f := s.limitRate(tHandler)
 	ip := "ip"
@@ -1068,3 +1074,210 @@ func TestWSRateLimiter(t *testing.T) {
 		t.Fatalf("initial register request from different conn failed")
 	}
 }
+
+func TestRateTiers(t *testing.T) {
+	limits := DefaultRateLimits()
+	limits.Order = RateLimit{Rate: 1e-6, Burst: 2}
+	tiers := newRateTiers([]*RateTier{
+		{Name: "good", MaxScore: 0, Order: RateLimit{Rate: 1e-6, Burst: 5}},
+		{Name: "maker", MaxScore: -10, Order: RateLimit{Rate: 1e-6, Burst: 10}},
+	})
+
+	newLink := func() *wsLink {
+		return newWSLink("addr", newWsStub(), newRouteLimiter(limits), tiers, nil)
+	}
+
+	checkAllowed := func(c *wsLink, route string, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if !c.allow(route, 1) {
+				t.Fatalf("%s request %d not allowed", route, i)
+			}
+		}
+		if c.allow(route, 1) {
+			t.Fatalf("%s request %d allowed", route, n)
+		}
+	}
+
+	// Not authorized, so the per-IP order limiter applies.
+	checkAllowed(newLink(), msgjson.LimitRoute, 2)
+	checkAllowed(newLink(), msgjson.ReplaceRoute, 2)
+
+	// Score qualifies for no tier.
+	c := newLink()
+	c.SetAccountScore(1)
+	checkAllowed(c, msgjson.MarketRoute, 2)
+
+	// The tier with the lowest MaxScore is used.
+	c = newLink()
+	c.SetAccountScore(-20)
+	checkAllowed(c, msgjson.CancelRoute, 10)
+
+	c2 := newLink()
+	c2.SetAccountScore(-5)
+	checkAllowed(c2, msgjson.LimitRoute, 5)
+
+	// Repricing routes use the tier too.
+	c3 := newLink()
+	c3.SetAccountScore(-5)
+	checkAllowed(c3, msgjson.ReplaceRoute, 5)
+	c3 = newLink()
+	c3.SetAccountScore(-5)
+	checkAllowed(c3, msgjson.MultiTradeRoute, 5)
+
+	// A score change reassigns the tier.
+	c4 := newLink()
+	c4.SetAccountScore(1)
+	if !c4.allow(msgjson.LimitRoute, 1) {
+		t.Fatalf("IP order limiter request not allowed")
+	}
+	c4.SetAccountScore(-5)
+	checkAllowed(c4, msgjson.LimitRoute, 5)
+	// A score change within the same tier keeps the tier's limiter.
+	c4.SetAccountScore(-6)
+	if c4.allow(msgjson.LimitRoute, 1) {
+		t.Fatalf("tier limiter reset by a score change within the tier")
+	}
+
+	// Changing the tiers applies to connected accounts.
+	tiers.set([]*RateTier{{Name: "maker", MaxScore: -10, Order: RateLimit{Rate: 1e-6, Burst: 20}}})
+	checkAllowed(c, msgjson.LimitRoute, 20)
+	// c2 no longer qualifies for a tier.
+	if !c2.allow(msgjson.LimitRoute, 1) { // IP order limiter has burst of 2
+		t.Fatalf("IP order limiter not used after tier removed")
+	}
+
+	// A multitrade batch costs one token per order.
+	c = newLink()
+	batch := func(n int) *msgjson.Message {
+		msg, _ := msgjson.NewRequest(1, msgjson.MultiTradeRoute, &msgjson.MultiTrade{
+			Orders: make([]*msgjson.LimitOrder, n),
+		})
+		return msg
+	}
+	if cost := requestCost(batch(3)); cost != 3 {
+		t.Fatalf("wrong batch cost %d", cost)
+	}
+	if c.allow(msgjson.MultiTradeRoute, requestCost(batch(3))) { // IP order limiter has burst of 2
+		t.Fatalf("batch of 3 allowed")
+	}
+	checkAllowed(c, msgjson.MultiTradeRoute, 2)
+
+	// Invalid tiers.
+	for _, bad := range [][]*RateTier{
+		{{Name: "", Order: RateLimit{1, 1}}},
+		{{Name: "a", Order: RateLimit{1, 1}}, {Name: "a", Order: RateLimit{2, 2}}},
+		{{Name: "a", Order: RateLimit{0, 1}}},
+		{{Name: "a", Order: RateLimit{1, 0}}},
+	} {
+		if err := ValidateRateTiers(bad); err == nil {
+			t.Fatalf("no error for invalid tiers %+v", bad)
+		}
+	}
+}
+
+func TestTradesParamsParser(t *testing.T) {
+	var req *msgjson.TradesRequest
+	mux := chi.NewRouter()
+	mux.With(tradesParamsParser).Get("/trades/{market}", func(w http.ResponseWriter, r *http.Request) {
+		req, _ = r.Context().Value(ctxThing).(*msgjson.TradesRequest)
+	})
+
+	tests := []struct {
+		name, path string
+		wantCode   int
+		wantReq    *msgjson.TradesRequest
+	}{{
+		name:     "ok",
+		path:     "/trades/dcr_btc",
+		wantCode: http.StatusOK,
+		wantReq:  &msgjson.TradesRequest{BaseID: 42, QuoteID: 0},
+	}, {
+		name:     "ok with paging",
+		path:     "/trades/DCR_btc?n=20&before=0a0b",
+		wantCode: http.StatusOK,
+		wantReq:  &msgjson.TradesRequest{BaseID: 42, QuoteID: 0, N: 20, Before: []byte{0x0a, 0x0b}},
+	}, {
+		name:     "bad market name",
+		path:     "/trades/dcrbtc",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "unknown asset",
+		path:     "/trades/dcr_xyz",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "bad n",
+		path:     "/trades/dcr_btc?n=x",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "bad before",
+		path:     "/trades/dcr_btc?before=x",
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		req = nil
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost"+tt.path, nil)
+		mux.ServeHTTP(w, r)
+		if w.Code != tt.wantCode {
+			t.Fatalf("%s: wanted code %d, got %d", tt.name, tt.wantCode, w.Code)
+		}
+		if tt.wantReq == nil {
+			continue
+		}
+		if req == nil || !reflect.DeepEqual(req, tt.wantReq) {
+			t.Fatalf("%s: wanted request %+v, got %+v", tt.name, tt.wantReq, req)
+		}
+	}
+}
+
+func TestEpochTranscriptParamsParser(t *testing.T) {
+	var req *msgjson.EpochTranscriptRequest
+	mux := chi.NewRouter()
+	mux.With(epochTranscriptParamsParser).Get("/epochtranscript/{market}/{epoch}", func(w http.ResponseWriter, r *http.Request) {
+		req, _ = r.Context().Value(ctxThing).(*msgjson.EpochTranscriptRequest)
+	})
+
+	tests := []struct {
+		name, path string
+		wantCode   int
+		wantReq    *msgjson.EpochTranscriptRequest
+	}{{
+		name:     "ok",
+		path:     "/epochtranscript/dcr_btc/123",
+		wantCode: http.StatusOK,
+		wantReq:  &msgjson.EpochTranscriptRequest{BaseID: 42, QuoteID: 0, Epoch: 123},
+	}, {
+		name:     "ok with duration",
+		path:     "/epochtranscript/dcr_btc/123?duration=6000",
+		wantCode: http.StatusOK,
+		wantReq:  &msgjson.EpochTranscriptRequest{BaseID: 42, QuoteID: 0, Epoch: 123, Duration: 6000},
+	}, {
+		name:     "unknown asset",
+		path:     "/epochtranscript/dcr_xyz/123",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "bad epoch",
+		path:     "/epochtranscript/dcr_btc/x",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "bad duration",
+		path:     "/epochtranscript/dcr_btc/123?duration=-1",
+		wantCode: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		req = nil
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "https://localhost"+tt.path, nil)
+		mux.ServeHTTP(w, r)
+		if w.Code != tt.wantCode {
+			t.Fatalf("%s: wanted code %d, got %d", tt.name, tt.wantCode, w.Code)
+		}
+		if tt.wantReq == nil {
+			continue
+		}
+		if req == nil || *req != *tt.wantReq {
+			t.Fatalf("%s: wanted request %+v, got %+v", tt.name, tt.wantReq, req)
+		}
+	}
+}
@@ -4,11 +4,15 @@
 package comms
 
 import (
+	"encoding/json"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/ws"
+	"decred.org/dcrdex/server/metrics"
 )
 
 const readLimitAuthorized = 262144
@@ -48,6 +52,10 @@ type Link interface {
 	// becomes authorized. Request handlers must be run synchronous with other
 	// reads or it will be a data race with the link's input loop.
 	Authorized()
+	// SetAccountScore sets the score of the authorized account, which selects
+	// the RateTier for the link's order routes. It may be called whenever the
+	// account's score changes, from any goroutine.
+	SetAccountScore(score int32)
 }
 
 // When the DEX sends a request to the client, a responseHandler is created
@@ -75,10 +83,23 @@ type wsLink struct {
 	dataMeter func() (int, error)
 	// wsLimiter is a route-based rate limiter. This applies to rpcRoutes.
 	wsLimiter *routeLimiter
+	// tiers are the server's account rate tiers. Once the account score is
+	// set, the tieredRoutes use tierLimiter instead of the wsLimiter's route
+	// limiter. scored and score are accessed atomically. The remaining fields
+	// are only accessed by the input loop, and tierLimiter is rebuilt when the
+	// score or the tiers change the account's tier.
+	tiers       *rateTiers
+	scored      uint32
+	score       int32
+	tierSet     bool
+	tierScore   int32
+	tierVer     uint32
+	tierName    string  // empty if the account does not qualify for a tier
+	tierLimiter allower // nil if the account does not qualify for a tier
 }
 
 // newWSLink is a constructor for a new wsLink.
-func newWSLink(addr string, conn ws.Connection, wsLimiter *routeLimiter, limitData func() (int, error)) *wsLink {
+func newWSLink(addr string, conn ws.Connection, wsLimiter *routeLimiter, tiers *rateTiers, limitData func() (int, error)) *wsLink {
 	var c *wsLink
 	c = &wsLink{
 		WSLink: ws.NewWSLink(addr, conn, pingPeriod, func(msg *msgjson.Message) *msgjson.Error {
@@ -87,6 +108,7 @@ func newWSLink(addr string, conn ws.Connection, wsLimiter *routeLimiter, limitDa
 		respHandlers: make(map[uint64]*responseHandler),
 		dataMeter:    limitData,
 		wsLimiter:    wsLimiter,
+		tiers:        tiers,
 	}
 	return c
 }
@@ -116,6 +138,68 @@ func (c *wsLink) Authorized() {
 	c.SetReadLimit(readLimitAuthorized)
 }
 
+// SetAccountScore sets the score of the authorized account, which selects the
+// RateTier for the link's order routes. It is safe for concurrent use, and the
+// new score applies from the link's next order request.
+func (c *wsLink) SetAccountScore(score int32) {
+	atomic.StoreInt32(&c.score, score)
+	atomic.StoreUint32(&c.scored, 1)
+}
+
+// setTierLimiter selects the RateTier for the account score and the current
+// rate tiers, and creates a new tierLimiter if the tier has changed. A score
+// change within the same tier keeps the existing limiter.
+func (c *wsLink) setTierLimiter(score int32) {
+	tier, ver := c.tiers.tier(score)
+	var name string
+	if tier != nil {
+		name = tier.Name
+	}
+	changed := !c.tierSet || ver != c.tierVer || name != c.tierName
+	c.tierSet, c.tierScore, c.tierVer, c.tierName = true, score, ver, name
+	if !changed {
+		return
+	}
+	c.tierLimiter = nil
+	if tier != nil {
+		log.Debugf("Using rate tier %q for client at %s", tier.Name, c.Addr())
+		c.tierLimiter = tier.Order.newLimiter()
+	}
+}
+
+// allow checks the rate limiters for a request to the route that costs n
+// tokens. The order routes of a scored account with a RateTier are subject to
+// the tier's limiter and the cumulative limiter for the IP address.
+func (c *wsLink) allow(route string, n int) bool {
+	if atomic.LoadUint32(&c.scored) == 1 && tieredRoutes[route] {
+		score := atomic.LoadInt32(&c.score)
+		if !c.tierSet || score != c.tierScore || c.tiers.version() != c.tierVer {
+			c.setTierLimiter(score)
+		}
+		if c.tierLimiter != nil {
+			now := time.Now()
+			return c.wsLimiter.cumulative.AllowN(now, n) && c.tierLimiter.AllowN(now, n)
+		}
+	}
+	return c.wsLimiter.allow(route, n)
+}
+
+// requestCost is the number of rate limiter tokens charged for the request. A
+// multitrade batch costs one token per order, the same as placing the orders
+// individually. All other requests cost one token.
+func requestCost(msg *msgjson.Message) int {
+	if msg.Route != msgjson.MultiTradeRoute {
+		return 1
+	}
+	var batch struct {
+		Orders []json.RawMessage `json:"orders"`
+	}
+	if err := msg.Unmarshal(&batch); err != nil || len(batch.Orders) == 0 {
+		return 1 // the handler will reject it
+	}
+	return len(batch.Orders)
+}
+
 // The WSLink.handler for WSLink.inHandler
 func handleMessage(c *wsLink, msg *msgjson.Message) *msgjson.Error {
 	switch msg.Type {
@@ -127,7 +211,8 @@ func handleMessage(c *wsLink, msg *msgjson.Message) *msgjson.Error {
 		// API routes, which are part of the httpHandler map.
 		handler := RouteHandler(msg.Route)
 		if handler != nil {
-			if !c.wsLimiter.allow(msg.Route) {
+			if !c.allow(msg.Route, requestCost(msg)) {
+				metrics.RateLimited.Inc("ws", msg.Route)
 				return msgjson.NewError(msgjson.TooManyRequestsError, "too many requests to "+msg.Route)
 			}
 			// Handle the request.
@@ -142,7 +227,10 @@ func handleMessage(c *wsLink, msg *msgjson.Message) *msgjson.Error {
 
 		// If it's not a critical route, check the rate limiters.
 		if !criticalRoutes[msg.Route] {
-			if _, err := c.dataMeter(); err != nil {
+			if code, err := c.dataMeter(); err != nil {
+				if code == http.StatusTooManyRequests {
+					metrics.RateLimited.Inc("ws", msg.Route)
+				}
 				// These errors are actually formatted nicely for sending, since
 				// they are used directly in HTTP errors as well.
 				return msgjson.NewError(msgjson.TooManyRequestsError, err.Error())
@@ -156,6 +244,10 @@ func handleMessage(c *wsLink, msg *msgjson.Message) *msgjson.Error {
 			thing = new(msgjson.CandlesRequest)
 		case msgjson.OrderBookRoute:
 			thing = new(msgjson.OrderBookSubscription)
+		case msgjson.TradesRoute:
+			thing = new(msgjson.TradesRequest)
+		case msgjson.EpochTranscriptRoute:
+			thing = new(msgjson.EpochTranscriptRequest)
 		}
 		if thing != nil {
 			err := msg.Unmarshal(thing)
@@ -5,14 +5,17 @@ package comms
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/server/metrics"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -30,6 +33,9 @@ func (s *Server) limitRate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		code, err := s.meterIP(dex.NewIPKey(r.RemoteAddr))
 		if err != nil {
+			if code == http.StatusTooManyRequests {
+				metrics.RateLimited.Inc("http", "")
+			}
 			http.Error(w, err.Error(), code)
 			return
 		}
@@ -45,10 +51,10 @@ func (s *Server) meterIP(ip dex.IPKey) (int, error) {
 	if atomic.LoadUint32(&s.dataEnabled) != 1 {
 		return http.StatusServiceUnavailable, fmt.Errorf("data API is disabled")
 	}
-	if !globalHTTPRateLimiter.Allow() {
+	if !s.globalHTTPLimiter.Allow() {
 		return http.StatusTooManyRequests, fmt.Errorf("too many global requests")
 	}
-	ipLimiter := getIPLimiter(ip)
+	ipLimiter := getIPLimiter(ip, s.limits.HTTPIP)
 	if !ipLimiter.Allow() {
 		return http.StatusTooManyRequests, fmt.Errorf(http.StatusText(http.StatusTooManyRequests))
 	}
@@ -109,6 +115,88 @@ func orderBookParamsParser(next http.Handler) http.Handler {
 	})
 }
 
+// tradesParamsParser is middleware for the /trades route. Parses the
+// *msgjson.TradesRequest from the market name URL parameter, e.g. dcr_btc, and
+// the optional n and before query parameters.
+func tradesParamsParser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		baseID, quoteID, errMsg := parseMarketName(r)
+		if errMsg != "" {
+			http.Error(w, errMsg, http.StatusBadRequest)
+			return
+		}
+
+		req := &msgjson.TradesRequest{
+			BaseID:  baseID,
+			QuoteID: quoteID,
+		}
+		var err error
+		if nStr := r.URL.Query().Get("n"); nStr != "" {
+			if req.N, err = strconv.Atoi(nStr); err != nil {
+				http.Error(w, "n unparseable", http.StatusBadRequest)
+				return
+			}
+		}
+		if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
+			if req.Before, err = hex.DecodeString(beforeStr); err != nil {
+				http.Error(w, "before unparseable", http.StatusBadRequest)
+				return
+			}
+		}
+		ctx := context.WithValue(r.Context(), ctxThing, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// epochTranscriptParamsParser is middleware for the /epochtranscript route.
+// Parses the *msgjson.EpochTranscriptRequest from the market name and epoch
+// index URL parameters, and the optional duration query parameter.
+func epochTranscriptParamsParser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		baseID, quoteID, errMsg := parseMarketName(r)
+		if errMsg != "" {
+			http.Error(w, errMsg, http.StatusBadRequest)
+			return
+		}
+		epoch, err := strconv.ParseUint(chi.URLParam(r, "epoch"), 10, 64)
+		if err != nil {
+			http.Error(w, "epoch unparseable", http.StatusBadRequest)
+			return
+		}
+		req := &msgjson.EpochTranscriptRequest{
+			BaseID:  baseID,
+			QuoteID: quoteID,
+			Epoch:   epoch,
+		}
+		if durStr := r.URL.Query().Get("duration"); durStr != "" {
+			if req.Duration, err = strconv.ParseUint(durStr, 10, 64); err != nil {
+				http.Error(w, "duration unparseable", http.StatusBadRequest)
+				return
+			}
+		}
+		ctx := context.WithValue(r.Context(), ctxThing, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// parseMarketName parses the base and quote asset IDs from the "market" URL
+// parameter, e.g. dcr_btc.
+func parseMarketName(r *http.Request) (baseID, quoteID uint32, errMsg string) {
+	symbols := strings.Split(strings.ToLower(chi.URLParam(r, "market")), "_")
+	if len(symbols) != 2 {
+		return 0, 0, "market name unparseable"
+	}
+	baseID, found := dex.BipSymbolID(symbols[0])
+	if !found {
+		return 0, 0, "unknown base"
+	}
+	quoteID, found = dex.BipSymbolID(symbols[1])
+	if !found {
+		return 0, 0, "unknown quote"
+	}
+	return baseID, quoteID, ""
+}
+
 // parseBaseQuoteIDs parses the "baseSymbol" and "quoteSymbol" URL parameters
 // from the request.
 func parseBaseQuoteIDs(r *http.Request) (baseID, quoteID uint32, errMsg string) {
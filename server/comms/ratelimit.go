@@ -0,0 +1,213 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package comms
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"decred.org/dcrdex/dex/msgjson"
+	"golang.org/x/time/rate"
+)
+
+// RateLimit is a token bucket rate limit. Rate is the sustained number of
+// requests per second, and Burst is the maximum number of requests that may be
+// made at once.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+func (l RateLimit) validate() error {
+	if l.Rate <= 0 || l.Burst <= 0 {
+		return fmt.Errorf("rate (%v) and burst (%d) must be positive", l.Rate, l.Burst)
+	}
+	return nil
+}
+
+func (l RateLimit) newLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(l.Rate), l.Burst)
+}
+
+// RateLimits are the websocket route and HTTP data API rate limits. The
+// websocket route limits are shared by all connections from an IP address.
+type RateLimits struct {
+	// Status is for the order_status and match_status routes (combined).
+	Status RateLimit `json:"status"`
+	// Order is for the market, limit, cancel, replace, and multitrade routes
+	// (combined). A multitrade batch counts each of its orders. Accounts with a
+	// RateTier use the tier's order limit instead.
+	Order RateLimit `json:"order"`
+	// Info is for the config, fee_rate, spots, and candles routes (combined).
+	Info RateLimit `json:"info"`
+	// Subs is for the orderbook and price_feed subscription routes
+	// (combined).
+	Subs RateLimit `json:"subs"`
+	// Register is for the register route.
+	Register RateLimit `json:"register"`
+	// Connect is for the connect route.
+	Connect RateLimit `json:"connect"`
+	// Total is the cumulative limit for all of the above routes.
+	Total RateLimit `json:"total"`
+	// HTTPGlobal is the server-wide limit for the HTTP data API, including
+	// data API requests made over websocket connections.
+	HTTPGlobal RateLimit `json:"httpGlobal"`
+	// HTTPIP is the per-IP limit for the HTTP data API.
+	HTTPIP RateLimit `json:"httpIP"`
+}
+
+// DefaultRateLimits returns the default rate limits.
+func DefaultRateLimits() *RateLimits {
+	return &RateLimits{
+		Status:     RateLimit{wsRateStatus, wsBurstStatus},
+		Order:      RateLimit{wsRateOrder, wsBurstOrder},
+		Info:       RateLimit{wsRateInfo, wsBurstInfo},
+		Subs:       RateLimit{wsRateSubs, wsBurstSubs},
+		Register:   RateLimit{wsRateRegister, wsBurstRegister},
+		Connect:    RateLimit{wsRateConnect, wsBurstConnect},
+		Total:      RateLimit{wsRateTotal, wsBurstTotal},
+		HTTPGlobal: RateLimit{httpRateGlobal, httpBurstGlobal},
+		HTTPIP:     RateLimit{ipMaxRatePerSec, ipMaxBurstSize},
+	}
+}
+
+// Validate checks that all of the limits are positive.
+func (rl *RateLimits) Validate() error {
+	for _, l := range []struct {
+		name string
+		RateLimit
+	}{
+		{"status", rl.Status},
+		{"order", rl.Order},
+		{"info", rl.Info},
+		{"subs", rl.Subs},
+		{"register", rl.Register},
+		{"connect", rl.Connect},
+		{"total", rl.Total},
+		{"http global", rl.HTTPGlobal},
+		{"http ip", rl.HTTPIP},
+	} {
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("invalid %s rate limit: %w", l.name, err)
+		}
+	}
+	return nil
+}
+
+// RateTier is an order route rate limit for authenticated accounts in good
+// standing, such as market makers that must reprice their orders in bursts.
+// Account scores increase with violations, so lower scores are better. An
+// account is assigned the qualifying tier with the lowest MaxScore when it
+// connects, and is reassigned when its score changes. A tiered order limiter belongs to the connection rather than the
+// IP address, but the IP's cumulative limit still applies.
+type RateTier struct {
+	Name     string    `json:"name"`
+	MaxScore int32     `json:"maxScore"`
+	Order    RateLimit `json:"order"`
+}
+
+// ValidateRateTiers checks that the tiers have unique names and valid limits.
+func ValidateRateTiers(tiers []*RateTier) error {
+	names := make(map[string]bool, len(tiers))
+	for _, tier := range tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("rate tier has no name")
+		}
+		if names[tier.Name] {
+			return fmt.Errorf("duplicate rate tier %q", tier.Name)
+		}
+		names[tier.Name] = true
+		if err := tier.Order.validate(); err != nil {
+			return fmt.Errorf("invalid order rate limit for tier %q: %w", tier.Name, err)
+		}
+	}
+	return nil
+}
+
+// tieredRoutes are the websocket routes that use an account's RateTier.
+var tieredRoutes = map[string]bool{
+	msgjson.LimitRoute:      true,
+	msgjson.MarketRoute:     true,
+	msgjson.CancelRoute:     true,
+	msgjson.ReplaceRoute:    true,
+	msgjson.MultiTradeRoute: true,
+}
+
+// rateTiers is the set of account rate tiers, which may be modified while
+// links are connected. The version is incremented when the tiers change so
+// that links can rebuild their tiered limiters.
+type rateTiers struct {
+	mtx   sync.RWMutex
+	tiers []*RateTier // sorted by MaxScore
+	ver   uint32
+}
+
+func newRateTiers(tiers []*RateTier) *rateTiers {
+	rt := new(rateTiers)
+	rt.set(tiers)
+	return rt
+}
+
+func (rt *rateTiers) set(tiers []*RateTier) {
+	sorted := make([]*RateTier, 0, len(tiers))
+	for _, tier := range tiers {
+		t := *tier
+		sorted = append(sorted, &t)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MaxScore < sorted[j].MaxScore
+	})
+	rt.mtx.Lock()
+	rt.tiers = sorted
+	rt.ver++
+	rt.mtx.Unlock()
+}
+
+func (rt *rateTiers) get() []*RateTier {
+	rt.mtx.RLock()
+	defer rt.mtx.RUnlock()
+	tiers := make([]*RateTier, 0, len(rt.tiers))
+	for _, tier := range rt.tiers {
+		t := *tier
+		tiers = append(tiers, &t)
+	}
+	return tiers
+}
+
+// version is the current version of the tiers.
+func (rt *rateTiers) version() uint32 {
+	rt.mtx.RLock()
+	defer rt.mtx.RUnlock()
+	return rt.ver
+}
+
+// tier returns the tier for the account score, or nil if the account does
+// not qualify for a tier, and the version of the tiers.
+func (rt *rateTiers) tier(score int32) (*RateTier, uint32) {
+	rt.mtx.RLock()
+	defer rt.mtx.RUnlock()
+	for _, tier := range rt.tiers {
+		if score <= tier.MaxScore {
+			t := *tier
+			return &t, rt.ver
+		}
+	}
+	return nil, rt.ver
+}
+
+// SetRateTiers replaces the account rate tiers. Connected accounts switch to
+// the limits of their new tier with their next order request.
+func (s *Server) SetRateTiers(tiers []*RateTier) error {
+	if err := ValidateRateTiers(tiers); err != nil {
+		return err
+	}
+	s.tiers.set(tiers)
+	return nil
+}
+
+// RateTiers returns the account rate tiers, sorted by MaxScore.
+func (s *Server) RateTiers() []*RateTier {
+	return s.tiers.get()
+}
@@ -44,13 +44,17 @@ const (
 	// banishTime is the default duration of a client quarantine.
 	banishTime = time.Hour
 
-	// Per-ip rate limits for market data API routes.
+	// Default per-ip rate limits for market data API routes.
 	ipMaxRatePerSec = 1
 	ipMaxBurstSize  = 5
 
-	// Per-websocket-connection limits in requests per second. Rate should be a
-	// reasonable sustained rate, while burst should consider bulk reconnect
-	// operations. Consider which routes are authenticated when setting these.
+	// Default global rate limit for the market data API routes.
+	httpRateGlobal, httpBurstGlobal = 100, 1000
+
+	// Default per-websocket-connection limits in requests per second. See
+	// RateLimits. Rate should be a reasonable sustained rate, while burst
+	// should consider bulk reconnect operations. Consider which routes are
+	// authenticated when setting these.
 	wsRateStatus, wsBurstStatus     = 10, 500      // order_status and match_status (combined)
 	wsRateOrder, wsBurstOrder       = 5, 100       // market, limit, and cancel (combined)
 	wsRateInfo, wsBurstInfo         = 10, 200      // low-cost route limiter for: config, fee_rate, spots, candles (combined)
@@ -74,11 +78,6 @@ var (
 	// to facilitate testing.
 	pingPeriod = (pongWait * 9) / 10 // i.e. 18 sec
 
-	// globalHTTPRateLimiter is a limit on the global HTTP request limit. The
-	// global rate limiter is like a rudimentary auto-spam filter for
-	// non-critical routes, including all routes registered as HTTP routes.
-	globalHTTPRateLimiter = rate.NewLimiter(100, 1000) // rate per sec, max burst
-
 	// ipHTTPRateLimiter is a per-client rate limiter for the HTTP endpoints
 	// requests and httpRoutes (the market data API). The Server manages
 	// separate limiters used with the websocket routes, rpcRoutes.
@@ -94,10 +93,10 @@ type ipRateLimiter struct {
 	lastHit time.Time
 }
 
-// Get an ipRateLimiter for the IP. Creates a new one if it doesn't exist. This
-// is for use with the HTTP endpoints and httpRoutes (the data API), not the
-// websocket request routes in rpcRoutes.
-func getIPLimiter(ip dex.IPKey) *ipRateLimiter {
+// Get an ipRateLimiter for the IP. Creates a new one with the specified limit if
+// it doesn't exist. This is for use with the HTTP endpoints and httpRoutes (the
+// data API), not the websocket request routes in rpcRoutes.
+func getIPLimiter(ip dex.IPKey, limit RateLimit) *ipRateLimiter {
 	rateLimiterMtx.Lock()
 	defer rateLimiterMtx.Unlock()
 	limiter := ipHTTPRateLimiter[ip]
@@ -106,7 +105,7 @@ func getIPLimiter(ip dex.IPKey) *ipRateLimiter {
 		return limiter
 	}
 	limiter = &ipRateLimiter{
-		Limiter: rate.NewLimiter(ipMaxRatePerSec, ipMaxBurstSize),
+		Limiter: limit.newLimiter(),
 		lastHit: time.Now(),
 	}
 	ipHTTPRateLimiter[ip] = limiter
@@ -185,11 +184,17 @@ type RPCConfig struct {
 	AltDNSNames []string
 	// DisableDataAPI will disable all traffic to the HTTP data API routes.
 	DisableDataAPI bool
+	// RateLimits are the websocket route and HTTP data API rate limits. If
+	// nil, DefaultRateLimits are used.
+	RateLimits *RateLimits
+	// RateTiers are order route rate limits for authenticated accounts based
+	// on their score. The tiers may be changed with SetRateTiers.
+	RateTiers []*RateTier
 }
 
 // allower is satisfied by rate.Limiter.
 type allower interface {
-	Allow() bool
+	AllowN(now time.Time, n int) bool
 }
 
 // routeLimiter contains a set of rate limiters for individual routes, and a
@@ -200,7 +205,8 @@ type routeLimiter struct {
 	cumulative allower // only used for defined routes
 }
 
-func (rl *routeLimiter) allow(route string) bool {
+// allow checks the limiters for a request to the route that costs n tokens.
+func (rl *routeLimiter) allow(route string, n int) bool {
 	// To apply the cumulative limiter to all routes including those without
 	// their own limiter, we would apply it here. Maybe go with this if we are
 	// confident it's not going to interfere with init/redeem or others.
@@ -211,31 +217,35 @@ func (rl *routeLimiter) allow(route string) bool {
 	if limiter == nil {
 		return true // free
 	}
-	return rl.cumulative.Allow() && limiter.Allow()
+	now := time.Now()
+	return rl.cumulative.AllowN(now, n) && limiter.AllowN(now, n)
 }
 
 // newRouteLimiter creates a route-based rate limiter. It should be applied to
 // all connections from a given IP address.
-func newRouteLimiter() *routeLimiter {
+func newRouteLimiter(limits *RateLimits) *routeLimiter {
 	// Some routes share a limiter to aggregate request stats:
-	statusLimiter := rate.NewLimiter(wsRateStatus, wsBurstStatus)
-	orderLimiter := rate.NewLimiter(wsRateOrder, wsBurstOrder)
-	infoLimiter := rate.NewLimiter(wsRateInfo, wsBurstInfo)
-	marketSubsLimiter := rate.NewLimiter(wsRateSubs, wsBurstSubs)
+	statusLimiter := limits.Status.newLimiter()
+	orderLimiter := limits.Order.newLimiter()
+	infoLimiter := limits.Info.newLimiter()
+	marketSubsLimiter := limits.Subs.newLimiter()
 	return &routeLimiter{
-		cumulative: rate.NewLimiter(wsRateTotal, wsBurstTotal),
+		cumulative: limits.Total.newLimiter(),
 		routes: map[string]allower{
 			// Connect (authorize) route
-			msgjson.ConnectRoute: rate.NewLimiter(wsRateConnect, wsBurstConnect),
+			msgjson.ConnectRoute: limits.Connect.newLimiter(),
 			// Meter the 'register' route the most.
-			msgjson.RegisterRoute: rate.NewLimiter(wsRateRegister, wsBurstRegister),
+			msgjson.RegisterRoute: limits.Register.newLimiter(),
 			// Status checking of matches and orders
 			msgjson.MatchStatusRoute: statusLimiter,
 			msgjson.OrderStatusRoute: statusLimiter,
 			// Order submission
-			msgjson.LimitRoute:  orderLimiter,
-			msgjson.MarketRoute: orderLimiter,
-			msgjson.CancelRoute: orderLimiter,
+			msgjson.LimitRoute:   orderLimiter,
+			msgjson.MarketRoute:  orderLimiter,
+			msgjson.CancelRoute:  orderLimiter,
+			msgjson.ReplaceRoute: orderLimiter,
+			// A batch is charged one token per order.
+			msgjson.MultiTradeRoute: orderLimiter,
 			// Order book and price feed subscriptions
 			msgjson.OrderBookRoute: marketSubsLimiter,
 			msgjson.PriceFeedRoute: marketSubsLimiter,
@@ -280,6 +290,15 @@ type Server struct {
 	quarantine map[dex.IPKey]time.Time
 
 	dataEnabled uint32 // atomic
+
+	// limits are the websocket route and HTTP data API rate limits.
+	limits *RateLimits
+	// globalHTTPLimiter is a limit on the global HTTP request limit. The
+	// global rate limiter is like a rudimentary auto-spam filter for
+	// non-critical routes, including all routes registered as HTTP routes.
+	globalHTTPLimiter *rate.Limiter
+	// tiers are the account rate tiers for the order routes.
+	tiers *rateTiers
 }
 
 // NewServer constructs a Server that should be started with Run. The server is
@@ -358,18 +377,31 @@ func NewServer(cfg *RPCConfig) (*Server, error) {
 	if len(listeners) == 0 {
 		return nil, fmt.Errorf("RPCS: No valid listen address")
 	}
+	limits := cfg.RateLimits
+	if limits == nil {
+		limits = DefaultRateLimits()
+	}
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateRateTiers(cfg.RateTiers); err != nil {
+		return nil, err
+	}
 	var dataEnabled uint32 = 1
 	if cfg.DisableDataAPI {
 		dataEnabled = 0
 	}
 
 	return &Server{
-		listeners:   listeners,
-		clients:     make(map[uint64]*wsLink),
-		wsLimiters:  make(map[dex.IPKey]*ipWsLimiter),
-		v6Prefixes:  make(map[dex.IPKey]int),
-		quarantine:  make(map[dex.IPKey]time.Time),
-		dataEnabled: dataEnabled,
+		listeners:         listeners,
+		clients:           make(map[uint64]*wsLink),
+		wsLimiters:        make(map[dex.IPKey]*ipWsLimiter),
+		v6Prefixes:        make(map[dex.IPKey]int),
+		quarantine:        make(map[dex.IPKey]time.Time),
+		dataEnabled:       dataEnabled,
+		limits:            limits,
+		globalHTTPLimiter: limits.HTTPGlobal.newLimiter(),
+		tiers:             newRateTiers(cfg.RateTiers),
 	}, nil
 }
 
@@ -451,6 +483,9 @@ func (s *Server) Run(ctx context.Context) {
 		rr.With(candleParamsParser).Get("/candles/{baseSymbol}/{quoteSymbol}/{binSize}", routeHandler(msgjson.CandlesRoute))
 		rr.With(candleParamsParser).Get("/candles/{baseSymbol}/{quoteSymbol}/{binSize}/{count}", routeHandler(msgjson.CandlesRoute))
 		rr.With(orderBookParamsParser).Get("/orderbook/{baseSymbol}/{quoteSymbol}", routeHandler(msgjson.OrderBookRoute))
+		rr.With(tradesParamsParser).Get("/trades/{market}", routeHandler(msgjson.TradesRoute))
+		rr.Get("/ticker", routeHandler(msgjson.TickerRoute))
+		rr.With(epochTranscriptParamsParser).Get("/epochtranscript/{market}/{epoch}", routeHandler(msgjson.EpochTranscriptRoute))
 	})
 
 	// Start serving.
@@ -544,7 +579,7 @@ func (s *Server) wsLimiter(ip dex.IPKey) *routeLimiter {
 	// connections to share a common limiter. To avoid this, return a new
 	// untracked limiter for such clients.
 	if ip.IsLoopback() {
-		return newRouteLimiter()
+		return newRouteLimiter(s.limits)
 	}
 
 	s.wsLimiterMtx.Lock()
@@ -579,7 +614,7 @@ func (s *Server) wsLimiter(ip dex.IPKey) *routeLimiter {
 		return l.routeLimiter
 	}
 
-	limiter := newRouteLimiter()
+	limiter := newRouteLimiter(s.limits)
 	s.wsLimiters[ip] = &ipWsLimiter{
 		conns:        1,
 		routeLimiter: limiter,
@@ -644,7 +679,7 @@ func (s *Server) websocketHandler(ctx context.Context, conn ws.Connection, ip de
 		return
 	}
 	defer s.wsLimiterDone(ip)
-	client := newWSLink(addr, conn, wsLimiter, dataRoutesMeter)
+	client := newWSLink(addr, conn, wsLimiter, s.tiers, dataRoutesMeter)
 	cm, err := s.addClient(ctx, client)
 	if err != nil {
 		log.Errorf("Failed to add client %s", addr)
@@ -737,6 +772,11 @@ func (s *Server) clientCount() uint64 {
 	return uint64(len(s.clients))
 }
 
+// ClientCount is the number of connected websocket clients.
+func (s *Server) ClientCount() uint64 {
+	return s.clientCount()
+}
+
 // Get the number of websocket connections for a given IP, excluding loopback.
 func (s *Server) ipConnCount(ip dex.IPKey) int64 {
 	s.wsLimiterMtx.Lock()
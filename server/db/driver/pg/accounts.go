@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/db"
@@ -144,6 +145,64 @@ func (a *Archiver) PayAccount(aid account.AccountID, coinID []byte) error {
 	return nil
 }
 
+// CreateAccountWithBond creates a new account with the given bond in a single
+// database transaction. There is no registration fee for such an account.
+func (a *Archiver) CreateAccountWithBond(acct *account.Account, bond *db.Bond) error {
+	dbTx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin database transaction: %w", err)
+	}
+
+	stmt := fmt.Sprintf(internal.CreateAccountForBond, a.tables.accounts)
+	if _, err = dbTx.Exec(stmt, acct.ID, acct.PubKey.SerializeCompressed()); err != nil {
+		_ = dbTx.Rollback()
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+
+	if err = addBond(dbTx, bondsTableName, acct.ID, bond); err != nil {
+		_ = dbTx.Rollback()
+		return fmt.Errorf("failed to add bond: %w", err)
+	}
+
+	if err = dbTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit account creation: %w", err)
+	}
+	return nil
+}
+
+// AddBond stores a new fidelity bond for an existing account.
+func (a *Archiver) AddBond(aid account.AccountID, bond *db.Bond) error {
+	return addBond(a.db, bondsTableName, aid, bond)
+}
+
+// Bonds retrieves the account's bonds with lock times at or after
+// lockTimeThresh, sorted by lock time.
+func (a *Archiver) Bonds(aid account.AccountID, lockTimeThresh time.Time) ([]*db.Bond, error) {
+	stmt := fmt.Sprintf(internal.SelectActiveBondsForUser, bondsTableName)
+	rows, err := a.db.Query(stmt, aid, lockTimeThresh.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bonds []*db.Bond
+	for rows.Next() {
+		var bond db.Bond
+		var ver int16
+		var assetID, strength int32
+		err = rows.Scan(&ver, &bond.CoinID, &assetID, &bond.Amount, &strength, &bond.LockTime)
+		if err != nil {
+			return nil, err
+		}
+		bond.Version, bond.AssetID, bond.Strength = uint16(ver), uint32(assetID), uint32(strength)
+		bonds = append(bonds, &bond)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return bonds, nil
+}
+
 // KeyIndex returns the current child index for the an xpub. If it is not
 // known, this creates a new entry with index zero.
 func (a *Archiver) KeyIndex(xpub string) (uint32, error) {
@@ -242,6 +301,14 @@ func createAccount(dbe sqlExecutor, tableName string, acct *account.Account, fee
 	return err
 }
 
+// addBond inserts a new bond for the account into the bonds table.
+func addBond(dbe sqlExecutor, tableName string, aid account.AccountID, bond *db.Bond) error {
+	stmt := fmt.Sprintf(internal.AddBond, tableName)
+	_, err := dbe.Exec(stmt, int16(bond.Version), bond.CoinID, int32(bond.AssetID), aid,
+		bond.Amount, int32(bond.Strength), bond.LockTime)
+	return err
+}
+
 // accountRegAddr gets the registration fee address and its asset ID created for
 // the specified account.
 func accountRegAddr(dbe *sql.DB, tableName string, aid account.AccountID) (string, uint32, error) {
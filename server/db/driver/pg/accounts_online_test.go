@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/db"
 )
 
 var tPubKey = []byte{
@@ -188,3 +190,64 @@ func TestWrongAccount(t *testing.T) {
 		t.Fatalf("no error paying registration fee for unknown account")
 	}
 }
+
+func TestBonds(t *testing.T) {
+	if err := cleanTables(archie.db); err != nil {
+		t.Fatalf("cleanTables: %v", err)
+	}
+
+	acct := tNewAccount(t)
+	lockTime := time.Now().Add(time.Hour).Unix()
+	bond := &db.Bond{
+		Version:  0,
+		AssetID:  42,
+		CoinID:   randomBytes(36),
+		Amount:   2e8,
+		Strength: 2,
+		LockTime: lockTime,
+	}
+	if err := archie.CreateAccountWithBond(acct, bond); err != nil {
+		t.Fatalf("CreateAccountWithBond error: %v", err)
+	}
+
+	// Not paid with a registration fee, but open.
+	_, paid, open := archie.Account(tAcctID)
+	if paid {
+		t.Fatalf("bonded account marked as fee paid")
+	}
+	if !open {
+		t.Fatalf("bonded account not open")
+	}
+
+	bond2 := &db.Bond{
+		Version:  0,
+		AssetID:  0,
+		CoinID:   randomBytes(36),
+		Amount:   1e6,
+		Strength: 1,
+		LockTime: lockTime + 10,
+	}
+	if err := archie.AddBond(tAcctID, bond2); err != nil {
+		t.Fatalf("AddBond error: %v", err)
+	}
+
+	bonds, err := archie.Bonds(tAcctID, time.Unix(lockTime, 0))
+	if err != nil {
+		t.Fatalf("Bonds error: %v", err)
+	}
+	if len(bonds) != 2 {
+		t.Fatalf("expected 2 bonds, got %d", len(bonds))
+	}
+	if !reflect.DeepEqual(bonds[0], bond) || !reflect.DeepEqual(bonds[1], bond2) {
+		t.Fatalf("wrong bonds retrieved")
+	}
+
+	// Only the later bond has a lock time after the threshold.
+	bonds, err = archie.Bonds(tAcctID, time.Unix(lockTime+1, 0))
+	if err != nil {
+		t.Fatalf("Bonds error: %v", err)
+	}
+	if len(bonds) != 1 || bonds[0].AssetID != 0 {
+		t.Fatalf("expected only the second bond, got %d", len(bonds))
+	}
+}
@@ -5,7 +5,9 @@ package pg
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"time"
 
@@ -77,11 +79,43 @@ func (a *Archiver) InsertEpoch(ed *db.EpochResults) error {
 		ed.BookSells, ed.BookSells5, ed.BookSells25, ed.HighRate, ed.LowRate, ed.StartRate, ed.EndRate)
 	if err != nil {
 		a.fatalBackendErr(err)
+		return err
+	}
+
+	if ed.Transcript != nil {
+		epochTranscriptsTableName := fullEpochTranscriptsTableName(a.dbName, marketSchema)
+		stmt = fmt.Sprintf(internal.InsertEpochTranscript, epochTranscriptsTableName)
+		_, err = a.db.Exec(stmt, ed.Idx, ed.Dur, order.EncodeEpochTranscript(ed.Transcript))
+		if err != nil {
+			a.fatalBackendErr(err)
+		}
 	}
 
 	return err
 }
 
+// EpochTranscript retrieves the transcript of a market's epoch.
+func (a *Archiver) EpochTranscript(base, quote uint32, epochIdx, epochDur int64) (*order.EpochTranscript, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	stmt := fmt.Sprintf(internal.SelectEpochTranscript, fullEpochTranscriptsTableName(a.dbName, marketSchema))
+	var b []byte
+	err = a.db.QueryRowContext(ctx, stmt, epochIdx, epochDur).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ArchiveError{Code: db.ErrUnknownEpoch}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return order.DecodeEpochTranscript(b)
+}
+
 // LoadEpochStats reads all market epoch history from the database, updating the
 // provided caches along the way.
 func (a *Archiver) LoadEpochStats(base, quote uint32, caches []*candles.Cache) error {
@@ -18,6 +18,19 @@ const (
 		broken_rule INT2 DEFAULT 0 -- TODO: change to banned BOOL
 		);`
 
+	// CreateBondsTable creates the bonds table, which records the fidelity
+	// bonds posted by accounts.
+	CreateBondsTable = `CREATE TABLE IF NOT EXISTS %s (
+		version INT2,
+		bond_coin_id BYTEA NOT NULL,
+		asset_id INT4 NOT NULL,
+		account_id BYTEA NOT NULL, -- INDEX this
+		amount INT8,
+		strength INT4,
+		lock_time INT8,
+		PRIMARY KEY (bond_coin_id, asset_id)
+		);`
+
 	// InsertKeyIfMissing creates an entry for the specified key hash, if it
 	// doesn't already exist.
 	InsertKeyIfMissing = `INSERT INTO %s (key_hash)
@@ -66,4 +79,20 @@ const (
 	SetRegOutput = `UPDATE %s SET
 		fee_coin = $1
 		WHERE account_id = $2;`
+
+	// CreateAccountForBond creates an entry for a new account that is funded
+	// with a fidelity bond rather than a registration fee.
+	CreateAccountForBond = `INSERT INTO %s (account_id, pubkey)
+		VALUES ($1, $2);`
+
+	// AddBond inserts a new bond for an account.
+	AddBond = `INSERT INTO %s (version, bond_coin_id, asset_id, account_id, amount, strength, lock_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+	// SelectActiveBondsForUser retrieves the bonds for an account with lock
+	// times after the provided threshold.
+	SelectActiveBondsForUser = `SELECT version, bond_coin_id, asset_id, amount, strength, lock_time
+		FROM %s
+		WHERE account_id = $1 AND lock_time >= $2
+		ORDER BY lock_time;`
 )
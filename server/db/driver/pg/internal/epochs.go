@@ -37,6 +37,22 @@ const (
 		end_rate INT8               -- the mid-gap rate at the end of the match cycle
 	);`
 
+	// CreateEpochTranscriptsTable creates a table for the transcripts of the
+	// epochs with orders, with which the shuffle and matching may be verified.
+	CreateEpochTranscriptsTable = `CREATE TABLE IF NOT EXISTS %s (
+		epoch_idx INT8,
+		epoch_dur INT4,       -- epoch duration in milliseconds
+		transcript BYTEA,     -- order.EncodeEpochTranscript
+		PRIMARY KEY(epoch_idx, epoch_dur)
+	);`
+
+	// InsertEpochTranscript inserts an epoch's transcript.
+	InsertEpochTranscript = `INSERT INTO %s (epoch_idx, epoch_dur, transcript)
+		VALUES ($1, $2, $3);`
+
+	// SelectEpochTranscript retrieves the transcript of an epoch.
+	SelectEpochTranscript = `SELECT transcript FROM %s WHERE epoch_idx = $1 AND epoch_dur = $2;`
+
 	// InsertEpochReport inserts a row into the epoch_reports table.
 	InsertEpochReport = `INSERT INTO %s (epoch_end, epoch_dur, match_volume, quote_volume,
 			book_buys, book_buys_5, book_buys_25, book_sells, book_sells_5, book_sells_25,
@@ -124,7 +124,36 @@ const (
 		aContractCoinID, bContractCoinID, aRedeemCoinID, bRedeemCoinID
 	FROM %s
 	WHERE takerSell IS NOT NULL -- not a cancel order
-	ORDER BY epochIdx * epochDur DESC
+	ORDER BY epochIdx * epochDur DESC, matchid DESC
+	LIMIT $1;`
+
+	// RetrieveMarketTrades retrieves the most recent trade matches, excluding
+	// inactive matches with a status before the status in $2, e.g. failed
+	// before the maker redeemed. The match ID breaks ties in epoch time.
+	RetrieveMarketTrades = `SELECT matchid, active, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status,
+		aContractCoinID, bContractCoinID, aRedeemCoinID, bRedeemCoinID
+	FROM %s
+	WHERE takerSell IS NOT NULL -- not a cancel order
+		AND (active OR status >= $2)
+	ORDER BY epochIdx * epochDur DESC, matchid DESC
+	LIMIT $1;`
+
+	// RetrieveMarketTradesBefore is like RetrieveMarketTrades, but starts after
+	// the match with ID $3 in the same order. Both table names must be given.
+	RetrieveMarketTradesBefore = `SELECT matchid, active, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status,
+		aContractCoinID, bContractCoinID, aRedeemCoinID, bRedeemCoinID
+	FROM %s
+	WHERE takerSell IS NOT NULL -- not a cancel order
+		AND (active OR status >= $2)
+		AND (epochIdx * epochDur, matchid) <
+			(SELECT epochIdx * epochDur, matchid FROM %s WHERE matchid = $3)
+	ORDER BY epochIdx * epochDur DESC, matchid DESC
 	LIMIT $1;`
 
 	RetrieveActiveMarketMatches = `SELECT matchid, takerSell,
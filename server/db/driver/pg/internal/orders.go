@@ -23,28 +23,31 @@ const (
 		filled INT8,
 		epoch_idx INT8, epoch_dur INT4,
 		preimage BYTEA UNIQUE,
-		complete_time INT8      -- when the order has successfully completed all swaps
+		complete_time INT8,     -- when the order has successfully completed all swaps
+		expiry_epoch INT8 DEFAULT 0, -- the last epoch index of a good-til-time order
+		display_qty INT8 DEFAULT 0,  -- the displayed quantity of an iceberg order
+		replaces BYTEA DEFAULT NULL  -- the order ID replaced by a replacement order
 	);`
 
 	// InsertOrder inserts a market or limit order into the specified table.
 	InsertOrder = `INSERT INTO %s (oid, type, sell, account_id, address,
 			client_time, server_time, commit, coins, quantity,
 			rate, force, status, filled,
-			epoch_idx, epoch_dur)
+			epoch_idx, epoch_dur, expiry_epoch, display_qty, replaces)
 		VALUES ($1, $2, $3, $4, $5,
 			$6, $7, $8, $9, $10,
 			$11, $12, $13, $14,
-			$15, $16);`
+			$15, $16, $17, $18, $19);`
 
 	// SelectOrder retrieves all columns with the given order ID. This may be
 	// used for any table with an "oid" column (orders_active, cancels_archived,
 	// etc.).
 	SelectOrder = `SELECT oid, type, sell, account_id, address, client_time, server_time,
-		commit, coins, quantity, rate, force, status, filled
+		commit, coins, quantity, rate, force, status, filled, expiry_epoch, display_qty, replaces
 	FROM %s WHERE oid = $1;`
 
 	SelectOrdersByStatus = `SELECT oid, type, sell, account_id, address, client_time, server_time,
-		commit, coins, quantity, rate, force, filled
+		commit, coins, quantity, rate, force, filled, expiry_epoch, display_qty, replaces
 	FROM %s WHERE status = $1;`
 
 	PreimageResultsLastN = `SELECT oid, (preimage IS NULL AND status=$3) AS preimageMiss, 
@@ -59,7 +62,7 @@ const (
 	// SelectUserOrders retrieves all columns of all orders for the given
 	// account ID.
 	SelectUserOrders = `SELECT oid, type, sell, account_id, address, client_time, server_time,
-		commit, coins, quantity, rate, force, status, filled
+		commit, coins, quantity, rate, force, status, filled, expiry_epoch, display_qty, replaces
 	FROM %s WHERE account_id = $1;`
 
 	// SelectUserOrderStatuses retrieves the order IDs and statuses of all orders
@@ -141,7 +144,7 @@ const (
 	//			force,
 	//			2,                                      -- new status (%d)
 	//			123456789,                              -- new filled (%d)
-	//          epoch_idx, epoch_dur, preimage, complete_time
+	//          epoch_idx, epoch_dur, preimage, complete_time, expiry_epoch, display_qty, replaces
 	//		)
 	//		INSERT INTO dcrdex.dcr_btc.orders_archived  -- destination table (%s)
 	//		SELECT * FROM moved;
@@ -151,7 +154,7 @@ const (
 		RETURNING oid, type, sell, account_id, address,
 			client_time, server_time, commit, coins, quantity,
 			rate, force, %d, %d,
-			epoch_idx, epoch_dur, preimage, complete_time
+			epoch_idx, epoch_dur, preimage, complete_time, expiry_epoch, display_qty, replaces
 	)
 	INSERT INTO %s
 	SELECT * FROM moved;`
@@ -163,7 +166,7 @@ const (
 		RETURNING oid, type, sell, account_id, address,
 			client_time, server_time, commit, coins, quantity,
 			rate, force, %d, filled, -- revoked status code
-			epoch_idx, epoch_dur, preimage, complete_time
+			epoch_idx, epoch_dur, preimage, complete_time, expiry_epoch, display_qty, replaces
 	)
 	INSERT INTO %s -- archived orders table for market X
 	SELECT * FROM moved
@@ -31,7 +31,7 @@ func (a *Archiver) matchTableName(match *order.Match) (string, error) {
 // can actually be forgiven (inactive, not already forgiven, and not in
 // MatchComplete status).
 func (a *Archiver) ForgiveMatchFail(mid order.MatchID) (bool, error) {
-	for schema := range a.markets {
+	for schema := range a.marketMap() {
 		stmt := fmt.Sprintf(internal.ForgiveMatchFail, fullMatchesTableName(a.dbName, schema))
 		N, err := sqlExec(a.db, stmt, mid)
 		if err != nil { // not just no rows updated
@@ -49,7 +49,7 @@ func (a *Archiver) ForgiveMatchFail(mid order.MatchID) (bool, error) {
 func (a *Archiver) ActiveSwaps() ([]*db.SwapDataFull, error) {
 	var sd []*db.SwapDataFull
 
-	for schema, mkt := range a.markets {
+	for schema, mkt := range a.marketMap() {
 		matchesTableName := fullMatchesTableName(a.dbName, schema)
 		ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
 		matches, swapData, err := activeSwaps(ctx, a.db, matchesTableName)
@@ -140,7 +140,7 @@ func activeSwaps(ctx context.Context, dbe *sql.DB, tableName string) (matches []
 func (a *Archiver) CompletedAndAtFaultMatchStats(aid account.AccountID, lastN int) ([]*db.MatchOutcome, error) {
 	var outcomes []*db.MatchOutcome
 
-	for schema, mkt := range a.markets {
+	for schema, mkt := range a.marketMap() {
 		matchesTableName := fullMatchesTableName(a.dbName, schema)
 		ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
 		matchOutcomes, err := completedAndAtFaultMatches(ctx, a.db, matchesTableName, aid, lastN, mkt.Base, mkt.Quote)
@@ -351,6 +351,46 @@ func (a *Archiver) MarketMatchesStreaming(base, quote uint32, includeInactive bo
 	return a.marketMatches(base, quote, includeInactive, N, f)
 }
 
+// MarketTrades retrieves up to N of the market's most recent trade matches,
+// excluding matches that failed before the maker redeemed. The matches are
+// ordered by epoch time and then by match ID, most recent first. If before is
+// not nil, the matches that follow the match with that ID are returned, so
+// that the trades may be paged through from the last one received. No matches
+// are returned if the before match is not found.
+func (a *Archiver) MarketTrades(base, quote uint32, before *order.MatchID, N int) ([]*db.MatchDataWithCoins, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	matchesTableName := fullMatchesTableName(a.dbName, marketSchema)
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	var rows *sql.Rows
+	if before == nil {
+		stmt := fmt.Sprintf(internal.RetrieveMarketTrades, matchesTableName)
+		rows, err = a.db.QueryContext(ctx, stmt, N, uint8(order.MakerRedeemed))
+	} else {
+		stmt := fmt.Sprintf(internal.RetrieveMarketTradesBefore, matchesTableName, matchesTableName)
+		rows, err = a.db.QueryContext(ctx, stmt, N, uint8(order.MakerRedeemed), *before)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []*db.MatchDataWithCoins
+	_, err = rowsToMatchDataWithCoinsStreaming(rows, true, func(m *db.MatchDataWithCoins) error {
+		ms = append(ms, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
 func rowsToMatchDataWithCoinsStreaming(rows *sql.Rows, includeInactive bool, f func(*db.MatchDataWithCoins) error) (int, error) {
 	defer rows.Close()
 
@@ -410,7 +450,7 @@ func (a *Archiver) AllActiveUserMatches(aid account.AccountID) ([]*db.MatchData,
 	defer cancel()
 
 	var matches []*db.MatchData
-	for schema := range a.markets {
+	for schema := range a.marketMap() {
 		matchesTableName := fullMatchesTableName(a.dbName, schema)
 		mdM, err := userMatches(ctx, a.db, matchesTableName, aid, false)
 		if err != nil {
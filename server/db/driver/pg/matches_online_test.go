@@ -1192,6 +1192,51 @@ func TestMatchStatuses(t *testing.T) {
 
 }
 
+func TestEpochTranscript(t *testing.T) {
+	if err := cleanTables(archie.db); err != nil {
+		t.Fatalf("cleanTables: %v", err)
+	}
+
+	var epochIdx, epochDur int64 = 13245678, 6000
+	lo, pimg := newLimitOrderRevealed(false, 4500000, 1, order.StandingTiF, 0)
+	transcript := &order.EpochTranscript{
+		Base:      mktInfo.Base,
+		Quote:     mktInfo.Quote,
+		Epoch:     order.EpochID{Idx: uint64(epochIdx), Dur: uint64(epochDur)},
+		LotSize:   mktInfo.LotSize,
+		CSum:      randomBytes(32),
+		Seed:      randomBytes(32),
+		Queue:     []*order.TranscriptOrder{order.NewTranscriptOrder(lo)},
+		Preimages: []order.Preimage{pimg},
+	}
+	err := archie.InsertEpoch(&db.EpochResults{
+		MktBase:        mktInfo.Base,
+		MktQuote:       mktInfo.Quote,
+		Idx:            epochIdx,
+		Dur:            epochDur,
+		CSum:           transcript.CSum,
+		Seed:           transcript.Seed,
+		OrdersRevealed: []order.OrderID{lo.ID()},
+		Transcript:     transcript,
+	})
+	if err != nil {
+		t.Fatalf("error inserting epoch: %v", err)
+	}
+
+	loaded, err := archie.EpochTranscript(mktInfo.Base, mktInfo.Quote, epochIdx, epochDur)
+	if err != nil {
+		t.Fatalf("EpochTranscript error: %v", err)
+	}
+	if len(loaded.Queue) != 1 || loaded.Queue[0].ID != lo.ID() || loaded.Preimages[0] != pimg {
+		t.Fatalf("wrong transcript loaded")
+	}
+
+	_, err = archie.EpochTranscript(mktInfo.Base, mktInfo.Quote, epochIdx+1, epochDur)
+	if !db.IsErrEpochUnknown(err) {
+		t.Fatalf("expected unknown epoch error, got %v", err)
+	}
+}
+
 func TestEpochReport(t *testing.T) {
 	if err := cleanTables(archie.db); err != nil {
 		t.Fatalf("cleanTables: %v", err)
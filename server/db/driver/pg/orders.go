@@ -126,6 +126,7 @@ const (
 	orderStatusFailed // failed helps distinguish matched from unmatched executed cancel orders
 	orderStatusCanceled
 	orderStatusRevoked // indicates a trade order was revoked, or in the cancels table that the cancel is server-generated
+	orderStatusExpired // a good-til-time limit order that was unbooked when it expired
 )
 
 func marketToPgStatus(status order.OrderStatus) pgOrderStatus {
@@ -140,6 +141,8 @@ func marketToPgStatus(status order.OrderStatus) pgOrderStatus {
 		return orderStatusCanceled
 	case order.OrderStatusRevoked:
 		return orderStatusRevoked
+	case order.OrderStatusExpired:
+		return orderStatusExpired
 	}
 	return orderStatusUnknown
 }
@@ -156,6 +159,8 @@ func pgToMarketStatus(status pgOrderStatus) order.OrderStatus {
 		return order.OrderStatusCanceled
 	case orderStatusRevoked, -orderStatusRevoked: // negative revoke status means forgiven preimage miss
 		return order.OrderStatusRevoked
+	case orderStatusExpired:
+		return order.OrderStatusExpired
 	}
 	return order.OrderStatusUnknown
 }
@@ -174,7 +179,7 @@ func (status pgOrderStatus) active() bool {
 	case orderStatusEpoch, orderStatusBooked:
 		return true
 	case orderStatusCanceled, orderStatusRevoked, -orderStatusRevoked,
-		orderStatusExecuted, orderStatusFailed, orderStatusUnknown:
+		orderStatusExecuted, orderStatusFailed, orderStatusExpired, orderStatusUnknown:
 		return false
 	default:
 		panic("unknown order status!") // programmer error
@@ -473,6 +478,12 @@ func (a *Archiver) CancelOrder(lo *order.LimitOrder) error {
 	return a.updateOrderStatus(lo, orderStatusCanceled)
 }
 
+// ExpireOrder updates a good-til-time LimitOrder with expired status. If the
+// order does not exist in the Archiver, ExpireOrder returns ErrUnknownOrder.
+func (a *Archiver) ExpireOrder(lo *order.LimitOrder) error {
+	return a.updateOrderStatus(lo, orderStatusExpired)
+}
+
 // RevokeOrder updates an Order with revoked status, which is used for
 // DEX-revoked orders rather than orders matched with a user's CancelOrder. If
 // the order does not exist in the Archiver, RevokeOrder returns
@@ -544,11 +555,12 @@ func (a *Archiver) storeOrder(ord order.Order, epochIdx, epochDur int64, status
 		return err
 	}
 
-	if !validateOrder(ord, status, a.markets[marketSchema]) {
+	mktInfo := a.marketMap()[marketSchema]
+	if !validateOrder(ord, status, mktInfo) {
 		return db.ArchiveError{
 			Code: db.ErrInvalidOrder,
 			Detail: fmt.Sprintf("invalid order %v for status %v and market %v",
-				ord.UID(), status, a.markets[marketSchema]),
+				ord.UID(), status, mktInfo),
 		}
 	}
 
@@ -764,7 +776,7 @@ type orderCompStamped struct {
 func (a *Archiver) CompletedUserOrders(aid account.AccountID, N int) (oids []order.OrderID, compTimes []int64, err error) {
 	var ords []orderCompStamped
 
-	for schema := range a.markets {
+	for schema := range a.marketMap() {
 		tableName := fullOrderTableName(a.dbName, schema, false) // NOT active table
 		ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
 		mktOids, err := completedUserOrders(ctx, a.db, tableName, aid, N)
@@ -852,7 +864,7 @@ func (a *Archiver) PreimageStats(user account.AccountID, lastN int) ([]*db.Preim
 		return rows.Err()
 	}
 
-	for schema := range a.markets {
+	for schema := range a.marketMap() {
 		// archived trade orders
 		stmt := fmt.Sprintf(internal.PreimageResultsLastN, fullOrderTableName(a.dbName, schema, false))
 		if err := queryOutcomes(stmt); err != nil {
@@ -1153,7 +1165,7 @@ func (a *Archiver) UserOrderStatuses(aid account.AccountID, base, quote uint32,
 // active orders for a user across all markets.
 func (a *Archiver) ActiveUserOrderStatuses(aid account.AccountID) ([]*db.OrderStatus, error) {
 	var orders []*db.OrderStatus
-	for schema := range a.markets {
+	for schema := range a.marketMap() {
 		tableName := fullOrderTableName(a.dbName, schema, true) // active table
 		mktOrders, err := a.userOrderStatusesFromTable(tableName, aid, nil)
 		if err != nil {
@@ -1213,7 +1225,7 @@ func (a *Archiver) userOrderStatusesFromTable(fullTable string, aid account.Acco
 // and archived, for an order with the given Commitment.
 func (a *Archiver) OrderWithCommit(ctx context.Context, commit order.Commitment) (found bool, oid order.OrderID, err error) {
 	// Check all markets.
-	for marketSchema := range a.markets {
+	for marketSchema := range a.marketMap() {
 		found, oid, err = orderForCommit(ctx, a.db, a.dbName, marketSchema, commit)
 		if err != nil {
 			a.fatalBackendErr(err)
@@ -1240,7 +1252,7 @@ func (a *Archiver) ExecutedCancelsForUser(aid account.AccountID, N int) (oids, t
 	var ords []cancelExecStamped
 
 	// Check all markets.
-	for marketSchema := range a.markets {
+	for marketSchema := range a.marketMap() {
 		// Query for executed cancels (user-initiated).
 		cancelTableName := fullCancelOrderTableName(a.dbName, marketSchema, false) // executed cancel orders are inactive
 		epochsTableName := fullEpochsTableName(a.dbName, marketSchema)
@@ -1421,22 +1433,26 @@ func loadTradeFromTable(dbe *sql.DB, fullTable string, oid order.OrderID) (order
 	var trade order.Trade
 	var id order.OrderID
 	var tif order.TimeInForce
-	var rate uint64
+	var rate, expiry, displayQty uint64
+	var replaces []byte
 	var status pgOrderStatus
 	err := dbe.QueryRow(stmt, oid).Scan(&id, &prefix.OrderType, &trade.Sell,
 		&prefix.AccountID, &trade.Address, &prefix.ClientTime, &prefix.ServerTime,
 		&prefix.Commit, (*dbCoins)(&trade.Coins),
-		&trade.Quantity, &rate, &tif, &status, &trade.FillAmt)
+		&trade.Quantity, &rate, &tif, &status, &trade.FillAmt, &expiry, &displayQty, &replaces)
 	if err != nil {
 		return nil, orderStatusUnknown, err
 	}
 	switch prefix.OrderType {
 	case order.LimitOrderType:
 		return &order.LimitOrder{
-			T:     *trade.Copy(), // govet would complain because Trade has a Mutex
-			P:     prefix,
-			Rate:  rate,
-			Force: tif,
+			T:           *trade.Copy(), // govet would complain because Trade has a Mutex
+			P:           prefix,
+			Rate:        rate,
+			Force:       tif,
+			ExpiryEpoch: expiry,
+			DisplayQty:  displayQty,
+			Replaces:    replacedOrderID(replaces),
 		}, status, nil
 	case order.MarketOrderType:
 		return &order.MarketOrder{
@@ -1521,11 +1537,12 @@ func ordersByStatusFromTable(ctx context.Context, dbe *sql.DB, fullTable string,
 		var trade order.Trade
 		var id order.OrderID
 		var tif order.TimeInForce
-		var rate uint64
+		var rate, expiry, displayQty uint64
+		var replaces []byte
 		err = rows.Scan(&id, &prefix.OrderType, &trade.Sell,
 			&prefix.AccountID, &trade.Address, &prefix.ClientTime, &prefix.ServerTime,
 			&prefix.Commit, (*dbCoins)(&trade.Coins),
-			&trade.Quantity, &rate, &tif, &trade.FillAmt)
+			&trade.Quantity, &rate, &tif, &trade.FillAmt, &expiry, &displayQty, &replaces)
 		if err != nil {
 			return nil, err
 		}
@@ -1535,10 +1552,13 @@ func ordersByStatusFromTable(ctx context.Context, dbe *sql.DB, fullTable string,
 		switch prefix.OrderType {
 		case order.LimitOrderType:
 			ord = &order.LimitOrder{
-				P:     prefix,
-				T:     *trade.Copy(),
-				Rate:  rate,
-				Force: tif,
+				P:           prefix,
+				T:           *trade.Copy(),
+				Rate:        rate,
+				Force:       tif,
+				ExpiryEpoch: expiry,
+				DisplayQty:  displayQty,
+				Replaces:    replacedOrderID(replaces),
 			}
 		case order.MarketOrderType:
 			ord = &order.MarketOrder{
@@ -1578,12 +1598,13 @@ func userOrdersFromTable(ctx context.Context, dbe *sql.DB, fullTable string, bas
 		var trade order.Trade
 		var id order.OrderID
 		var tif order.TimeInForce
-		var rate uint64
+		var rate, expiry, displayQty uint64
+		var replaces []byte
 		var status pgOrderStatus
 		err = rows.Scan(&id, &prefix.OrderType, &trade.Sell,
 			&prefix.AccountID, &trade.Address, &prefix.ClientTime, &prefix.ServerTime,
 			&prefix.Commit, (*dbCoins)(&trade.Coins),
-			&trade.Quantity, &rate, &tif, &status, &trade.FillAmt)
+			&trade.Quantity, &rate, &tif, &status, &trade.FillAmt, &expiry, &displayQty, &replaces)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1593,10 +1614,13 @@ func userOrdersFromTable(ctx context.Context, dbe *sql.DB, fullTable string, bas
 		switch prefix.OrderType {
 		case order.LimitOrderType:
 			ord = &order.LimitOrder{
-				P:     prefix,
-				T:     *trade.Copy(),
-				Rate:  rate,
-				Force: tif,
+				P:           prefix,
+				T:           *trade.Copy(),
+				Rate:        rate,
+				Force:       tif,
+				ExpiryEpoch: expiry,
+				DisplayQty:  displayQty,
+				Replaces:    replacedOrderID(replaces),
 			}
 		case order.MarketOrderType:
 			ord = &order.MarketOrder{
@@ -1664,18 +1688,33 @@ func orderForCommit(ctx context.Context, dbe *sql.DB, dbName, marketSchema strin
 	return false, zeroOrderID, nil
 }
 
+// replacesBytes is the value of the replaces column for a limit order, which is
+// NULL for orders that are not replacements.
+func replacesBytes(lo *order.LimitOrder) []byte {
+	if !lo.IsReplacement() {
+		return nil
+	}
+	return lo.Replaces[:]
+}
+
+// replacedOrderID converts the value of the replaces column to an OrderID.
+func replacedOrderID(b []byte) (oid order.OrderID) {
+	copy(oid[:], b)
+	return
+}
+
 func storeLimitOrder(dbe sqlExecutor, tableName string, lo *order.LimitOrder, status pgOrderStatus, epochIdx, epochDur int64) (int64, error) {
 	stmt := fmt.Sprintf(internal.InsertOrder, tableName)
 	return sqlExec(dbe, stmt, lo.ID(), lo.Type(), lo.Sell, lo.AccountID,
 		lo.Address, lo.ClientTime, lo.ServerTime, lo.Commit, dbCoins(lo.Coins),
-		lo.Quantity, lo.Rate, lo.Force, status, lo.Filled(), epochIdx, epochDur)
+		lo.Quantity, lo.Rate, lo.Force, status, lo.Filled(), epochIdx, epochDur, lo.ExpiryEpoch, lo.DisplayQty, replacesBytes(lo))
 }
 
 func storeMarketOrder(dbe sqlExecutor, tableName string, mo *order.MarketOrder, status pgOrderStatus, epochIdx, epochDur int64) (int64, error) {
 	stmt := fmt.Sprintf(internal.InsertOrder, tableName)
 	return sqlExec(dbe, stmt, mo.ID(), mo.Type(), mo.Sell, mo.AccountID,
 		mo.Address, mo.ClientTime, mo.ServerTime, mo.Commit, dbCoins(mo.Coins),
-		mo.Quantity, 0, order.ImmediateTiF, status, mo.Filled(), epochIdx, epochDur)
+		mo.Quantity, 0, order.ImmediateTiF, status, mo.Filled(), epochIdx, epochDur, 0, 0, nil)
 }
 
 func updateOrderStatus(dbe sqlExecutor, tableName string, oid order.OrderID, status pgOrderStatus) error {
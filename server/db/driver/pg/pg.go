@@ -65,9 +65,13 @@ type Archiver struct {
 	queryTimeout time.Duration
 	db           *sql.DB
 	dbName       string
-	markets      map[string]*dex.MarketInfo
 	tables       archiverTables
 
+	// markets is replaced rather than modified when a market is added, so
+	// that the map returned by marketMap may be used without locking.
+	marketsMtx sync.RWMutex
+	markets    map[string]*dex.MarketInfo
+
 	fatalMtx sync.RWMutex
 	fatal    chan struct{}
 	fatalErr error
@@ -191,6 +195,43 @@ func NewArchiver(ctx context.Context, cfg *Config) (*Archiver, error) {
 	return archiver, nil
 }
 
+// marketMap returns the markets supported by the Archiver, keyed by schema
+// name. The map must not be modified.
+func (a *Archiver) marketMap() map[string]*dex.MarketInfo {
+	a.marketsMtx.RLock()
+	defer a.marketsMtx.RUnlock()
+	return a.markets
+}
+
+// AddMarket prepares the tables for a new market, or updates the stored
+// configuration of an existing market. If the lot size of an existing market
+// is changed, its book is flushed.
+func (a *Archiver) AddMarket(mkt *dex.MarketInfo) error {
+	purgeMarkets, err := prepareMarkets(a.db, []*dex.MarketInfo{mkt})
+	if err != nil {
+		return err
+	}
+
+	a.marketsMtx.Lock()
+	markets := make(map[string]*dex.MarketInfo, len(a.markets)+1)
+	for schema, mktInfo := range a.markets {
+		markets[schema] = mktInfo
+	}
+	markets[marketSchema(mkt.Name)] = mkt
+	a.markets = markets
+	a.marketsMtx.Unlock()
+
+	if len(purgeMarkets) > 0 {
+		unbookedSells, unbookedBuys, err := a.FlushBook(mkt.Base, mkt.Quote)
+		if err != nil {
+			return fmt.Errorf("failed to flush book for market %v: %w", mkt.Name, err)
+		}
+		log.Infof("Flushed %d sell orders and %d buy orders from market %v with a changed lot size.",
+			len(unbookedSells), len(unbookedBuys), mkt.Name)
+	}
+	return nil
+}
+
 // Close closes the underlying DB connection.
 func (a *Archiver) Close() error {
 	return a.db.Close()
@@ -202,7 +243,7 @@ func (a *Archiver) marketSchema(base, quote uint32) (string, error) {
 		return "", err
 	}
 	schema := marketSchema(marketName)
-	_, found := a.markets[schema]
+	_, found := a.marketMap()[schema]
 	if !found {
 		return "", db.ArchiveError{
 			Code:   db.ErrUnsupportedMarket,
@@ -18,15 +18,17 @@ const (
 	metaTableName     = "meta"
 	feeKeysTableName  = "fee_keys"
 	accountsTableName = "accounts"
+	bondsTableName    = "bonds"
 
 	// market schema tables
-	matchesTableName         = "matches"
-	epochsTableName          = "epochs"
-	ordersArchivedTableName  = "orders_archived"
-	ordersActiveTableName    = "orders_active"
-	cancelsArchivedTableName = "cancels_archived"
-	cancelsActiveTableName   = "cancels_active"
-	epochReportsTableName    = "epoch_reports"
+	matchesTableName          = "matches"
+	epochsTableName           = "epochs"
+	ordersArchivedTableName   = "orders_archived"
+	ordersActiveTableName     = "orders_active"
+	cancelsArchivedTableName  = "cancels_archived"
+	cancelsActiveTableName    = "cancels_active"
+	epochReportsTableName     = "epoch_reports"
+	epochTranscriptsTableName = "epoch_transcripts"
 )
 
 type tableStmt struct {
@@ -42,6 +44,7 @@ var createDEXTableStatements = []tableStmt{
 var createAccountTableStatements = []tableStmt{
 	{feeKeysTableName, internal.CreateFeeKeysTable},
 	{accountsTableName, internal.CreateAccountsTable},
+	{bondsTableName, internal.CreateBondsTable},
 }
 
 var createMarketTableStatements = []tableStmt{
@@ -52,6 +55,7 @@ var createMarketTableStatements = []tableStmt{
 	{matchesTableName, internal.CreateMatchesTable}, // just one matches table per market for now
 	{epochsTableName, internal.CreateEpochsTable},
 	{epochReportsTableName, internal.CreateEpochReportTable},
+	{epochTranscriptsTableName, internal.CreateEpochTranscriptsTable},
 }
 
 var tableMap = func() map[string]string {
@@ -103,6 +107,10 @@ func fullEpochReportsTableName(dbName, marketSchema string) string {
 	return dbName + "." + marketSchema + "." + epochReportsTableName
 }
 
+func fullEpochTranscriptsTableName(dbName, marketSchema string) string {
+	return dbName + "." + marketSchema + "." + epochTranscriptsTableName
+}
+
 // createTable creates one of the known tables by name. The table will be
 // created in the specified schema (schema.tableName). If schema is empty,
 // "public" is used.
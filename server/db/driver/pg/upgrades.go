@@ -17,7 +17,7 @@ import (
 	"decred.org/dcrdex/server/db/driver/pg/internal"
 )
 
-const dbVersion = 4
+const dbVersion = 9
 
 // The number of upgrades defined MUST be equal to dbVersion.
 var upgrades = []func(db *sql.Tx) error{
@@ -39,6 +39,24 @@ var upgrades = []func(db *sql.Tx) error{
 	// v4 upgrade updates the markets tables to use a integer type that can
 	// accommodate a 32-bit unsigned integer.
 	v4Upgrade,
+
+	// v5 upgrade creates the bonds table for fidelity bonds.
+	v5Upgrade,
+
+	// v6 upgrade adds the expiry_epoch column to the orders tables for
+	// good-til-time limit orders.
+	v6Upgrade,
+
+	// v7 upgrade adds the display_qty column to the orders tables for iceberg
+	// limit orders.
+	v7Upgrade,
+
+	// v8 upgrade adds the replaces column to the orders tables for replacement
+	// limit orders.
+	v8Upgrade,
+
+	// v9 upgrade creates the epoch_transcripts table for each market.
+	v9Upgrade,
 }
 
 // v1Upgrade adds the schema_version column and removes the state_hash column
@@ -291,6 +309,83 @@ func v4Upgrade(tx *sql.Tx) (err error) {
 	return err
 }
 
+func v5Upgrade(tx *sql.Tx) error {
+	created, err := createTableStmt(tx, internal.CreateBondsTable, publicSchema, bondsTableName)
+	if err != nil {
+		return fmt.Errorf("failed to create bonds table: %w", err)
+	}
+	if created {
+		log.Infof("Created new %q table", bondsTableName)
+	}
+	return nil
+}
+
+func v6Upgrade(tx *sql.Tx) error {
+	mkts, err := loadMarkets(tx, marketsTableName)
+	if err != nil {
+		return fmt.Errorf("failed to read markets table: %w", err)
+	}
+	// The column is appended to both the active and archived orders tables so
+	// that their columns remain in the same order for the MoveOrder query.
+	for _, mkt := range mkts {
+		for _, tableName := range []string{ordersActiveTableName, ordersArchivedTableName} {
+			fullTableName := mkt.Name + "." + tableName
+			_, err = tx.Exec(`ALTER TABLE ` + fullTableName + ` ADD COLUMN IF NOT EXISTS expiry_epoch INT8 DEFAULT 0;`)
+			if err != nil {
+				return fmt.Errorf("failed to add expiry_epoch column to %s: %w", fullTableName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func v7Upgrade(tx *sql.Tx) error {
+	mkts, err := loadMarkets(tx, marketsTableName)
+	if err != nil {
+		return fmt.Errorf("failed to read markets table: %w", err)
+	}
+	for _, mkt := range mkts {
+		for _, tableName := range []string{ordersActiveTableName, ordersArchivedTableName} {
+			fullTableName := mkt.Name + "." + tableName
+			_, err = tx.Exec(`ALTER TABLE ` + fullTableName + ` ADD COLUMN IF NOT EXISTS display_qty INT8 DEFAULT 0;`)
+			if err != nil {
+				return fmt.Errorf("failed to add display_qty column to %s: %w", fullTableName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func v8Upgrade(tx *sql.Tx) error {
+	mkts, err := loadMarkets(tx, marketsTableName)
+	if err != nil {
+		return fmt.Errorf("failed to read markets table: %w", err)
+	}
+	for _, mkt := range mkts {
+		for _, tableName := range []string{ordersActiveTableName, ordersArchivedTableName} {
+			fullTableName := mkt.Name + "." + tableName
+			_, err = tx.Exec(`ALTER TABLE ` + fullTableName + ` ADD COLUMN IF NOT EXISTS replaces BYTEA DEFAULT NULL;`)
+			if err != nil {
+				return fmt.Errorf("failed to add replaces column to %s: %w", fullTableName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func v9Upgrade(tx *sql.Tx) error {
+	mkts, err := loadMarkets(tx, marketsTableName)
+	if err != nil {
+		return fmt.Errorf("failed to read markets table: %w", err)
+	}
+	for _, mkt := range mkts {
+		if _, err = createTable(tx, marketSchema(mkt.Name), epochTranscriptsTableName); err != nil {
+			return fmt.Errorf("failed to create %s table for market %s: %w", epochTranscriptsTableName, mkt.Name, err)
+		}
+	}
+	return nil
+}
+
 // DBVersion retrieves the database version from the meta table.
 func DBVersion(db *sql.DB) (ver uint32, err error) {
 	err = db.QueryRow(internal.SelectDBVersion).Scan(&ver)
@@ -0,0 +1,193 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/db"
+)
+
+var tPubKey = []byte{
+	0x02, 0x04, 0x98, 0x8a, 0x49, 0x8d, 0x5d, 0x19, 0x51, 0x4b, 0x21, 0x7e, 0x87,
+	0x2b, 0x4d, 0xbd, 0x1c, 0xf0, 0x71, 0xd3, 0x65, 0xc4, 0x87, 0x9e, 0x64, 0xed,
+	0x59, 0x19, 0x88, 0x1c, 0x97, 0xeb, 0x19,
+}
+
+func tNewAccount(t *testing.T) *account.Account {
+	acct, err := account.NewAccountFromPubKey(tPubKey)
+	if err != nil {
+		t.Fatalf("error creating account from pubkey: %v", err)
+	}
+	return acct
+}
+
+func TestAccounts(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+	acct := tNewAccount(t)
+
+	if _, err := archie.AccountInfo(acct.ID); !db.IsErrAccountUnknown(err) {
+		t.Fatalf("expected ErrAccountUnknown, got %v", err)
+	}
+
+	assetID := uint32(42)
+	regAddr := "DsdQFmH3azyoGKJHt2ArJNxi35LCEgMqi8k"
+	if err := archie.CreateAccount(acct, assetID, regAddr); err != nil {
+		t.Fatalf("error creating account: %v", err)
+	}
+	// Creating again with the same fee info is not an error until paid.
+	if err := archie.CreateAccount(acct, assetID, regAddr); err != nil {
+		t.Fatalf("error recreating unpaid account: %v", err)
+	}
+
+	addr, checkAssetID, err := archie.AccountRegAddr(acct.ID)
+	if err != nil {
+		t.Fatalf("error getting registration address: %v", err)
+	}
+	if addr != regAddr || checkAssetID != assetID {
+		t.Fatalf("wrong registration address %s (asset %d)", addr, checkAssetID)
+	}
+
+	_, paid, open := archie.Account(acct.ID)
+	if paid || !open {
+		t.Fatalf("new account should be unpaid and open, got paid = %v, open = %v", paid, open)
+	}
+
+	coinID := randomBytes(36)
+	if err = archie.PayAccount(acct.ID, coinID); err != nil {
+		t.Fatalf("PayAccount error: %v", err)
+	}
+	checkAcct, paid, open := archie.Account(acct.ID)
+	if !paid || !open {
+		t.Fatalf("paid account should be paid and open, got paid = %v, open = %v", paid, open)
+	}
+	if checkAcct.ID != acct.ID {
+		t.Fatalf("wrong account ID %v", checkAcct.ID)
+	}
+
+	var errA db.ArchiveError
+	if err = archie.CreateAccount(acct, assetID, regAddr); !errors.As(err, &errA) || errA.Code != db.ErrAccountExists {
+		t.Fatalf("expected ErrAccountExists, got %v", err)
+	}
+
+	if err = archie.CloseAccount(acct.ID, account.FailureToAct); err != nil {
+		t.Fatalf("CloseAccount error: %v", err)
+	}
+	if _, _, open = archie.Account(acct.ID); open {
+		t.Fatalf("closed account is open")
+	}
+	ai, err := archie.AccountInfo(acct.ID)
+	if err != nil {
+		t.Fatalf("AccountInfo error: %v", err)
+	}
+	if ai.BrokenRule != account.FailureToAct {
+		t.Fatalf("wrong broken rule %v", ai.BrokenRule)
+	}
+
+	if err = archie.RestoreAccount(acct.ID); err != nil {
+		t.Fatalf("RestoreAccount error: %v", err)
+	}
+	if _, _, open = archie.Account(acct.ID); !open {
+		t.Fatalf("restored account is closed")
+	}
+
+	accts, err := archie.Accounts()
+	if err != nil {
+		t.Fatalf("Accounts error: %v", err)
+	}
+	if len(accts) != 1 || accts[0].AccountID != acct.ID || !reflect.DeepEqual([]byte(accts[0].FeeCoin), coinID) {
+		t.Fatalf("wrong accounts: %+v", accts)
+	}
+
+	if err = archie.CloseAccount(randomAccountID(), account.FailureToAct); err == nil {
+		t.Fatalf("no error closing unknown account")
+	}
+}
+
+func TestBonds(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+	acct := tNewAccount(t)
+
+	lockTime := time.Now().Add(time.Hour).Unix()
+	bond := &db.Bond{
+		Version:  0,
+		AssetID:  42,
+		CoinID:   randomBytes(36),
+		Amount:   2e8,
+		Strength: 2,
+		LockTime: lockTime,
+	}
+	if err := archie.CreateAccountWithBond(acct, bond); err != nil {
+		t.Fatalf("CreateAccountWithBond error: %v", err)
+	}
+
+	// Not paid with a registration fee, but open.
+	_, paid, open := archie.Account(acct.ID)
+	if paid {
+		t.Fatalf("bonded account marked as fee paid")
+	}
+	if !open {
+		t.Fatalf("bonded account not open")
+	}
+
+	bond2 := &db.Bond{
+		Version:  0,
+		AssetID:  0,
+		CoinID:   randomBytes(36),
+		Amount:   1e6,
+		Strength: 1,
+		LockTime: lockTime + 10,
+	}
+	if err := archie.AddBond(acct.ID, bond2); err != nil {
+		t.Fatalf("AddBond error: %v", err)
+	}
+
+	bonds, err := archie.Bonds(acct.ID, time.Unix(lockTime, 0))
+	if err != nil {
+		t.Fatalf("Bonds error: %v", err)
+	}
+	if len(bonds) != 2 {
+		t.Fatalf("expected 2 bonds, got %d", len(bonds))
+	}
+	if !reflect.DeepEqual(bonds[0], bond) || !reflect.DeepEqual(bonds[1], bond2) {
+		t.Fatalf("wrong bonds retrieved")
+	}
+
+	// Only the later bond has a lock time after the threshold.
+	bonds, err = archie.Bonds(acct.ID, time.Unix(lockTime+1, 0))
+	if err != nil {
+		t.Fatalf("Bonds error: %v", err)
+	}
+	if len(bonds) != 1 || bonds[0].AssetID != 0 {
+		t.Fatalf("expected only the second bond, got %d", len(bonds))
+	}
+}
+
+func TestKeyIndex(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+	const xpub = "dpubZF4LSCdF9YKZfNzTVYhz4RBxsjYXqms8AQnMBHXZ8GUKoRSigG7kQnKiJt5pzk93Q8FxcdVBEkQZruSXduGtWnkwXzGnjbSovQ97dCxqaXc"
+
+	idx, err := archie.KeyIndex(xpub)
+	if err != nil {
+		t.Fatalf("KeyIndex error: %v", err)
+	}
+	if idx != 0 {
+		t.Fatalf("new key index %d, expected 0", idx)
+	}
+
+	if err = archie.SetKeyIndex(5, xpub); err != nil {
+		t.Fatalf("SetKeyIndex error: %v", err)
+	}
+	if idx, err = archie.KeyIndex(xpub); err != nil {
+		t.Fatalf("KeyIndex error: %v", err)
+	}
+	if idx != 5 {
+		t.Fatalf("key index %d, expected 5", idx)
+	}
+}
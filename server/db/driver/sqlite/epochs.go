@@ -0,0 +1,180 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/dex/candles"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/db"
+	"decred.org/dcrdex/server/db/driver/sqlite/internal"
+)
+
+// In a table, a []order.OrderID is stored as a BLOB of the concatenated IDs.
+// The orderIDs type defines the Value and Scan methods for such an OrderID
+// slice.
+type orderIDs []order.OrderID
+
+// Value implements the sql/driver.Valuer interface.
+func (oids orderIDs) Value() (driver.Value, error) {
+	if oids == nil {
+		return nil, nil
+	}
+
+	b := make([]byte, 0, len(oids)*order.OrderIDSize)
+	for i := range oids {
+		b = append(b, oids[i][:]...)
+	}
+	return b, nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (oids *orderIDs) Scan(src interface{}) error {
+	if src == nil {
+		*oids = nil
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into orderIDs", src)
+	}
+	if len(b)%order.OrderIDSize != 0 {
+		return fmt.Errorf("invalid order IDs length %d", len(b))
+	}
+
+	n := len(b) / order.OrderIDSize
+	*oids = make([]order.OrderID, n)
+	for i := range *oids {
+		copy((*oids)[i][:], b[i*order.OrderIDSize:])
+	}
+	return nil
+}
+
+// InsertEpoch stores the results of a newly-processed epoch.
+func (a *Archiver) InsertEpoch(ed *db.EpochResults) error {
+	marketSchema, err := a.marketSchema(ed.MktBase, ed.MktQuote)
+	if err != nil {
+		return err
+	}
+
+	// Store the epoch, its report, and its transcript atomically.
+	tx, err := a.db.Begin()
+	if err != nil {
+		a.fatalBackendErr(err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	epochsTableName := fullEpochsTableName(marketSchema)
+	stmt := fmt.Sprintf(internal.InsertEpoch, epochsTableName)
+	_, err = tx.Exec(stmt, ed.Idx, ed.Dur, ed.MatchTime, ed.CSum, ed.Seed,
+		orderIDs(ed.OrdersRevealed), orderIDs(ed.OrdersMissed))
+	if err != nil {
+		a.fatalBackendErr(err)
+		return err
+	}
+
+	epochReportsTableName := fullEpochReportsTableName(marketSchema)
+	stmt = fmt.Sprintf(internal.InsertEpochReport, epochReportsTableName)
+	epochEnd := (ed.Idx + 1) * ed.Dur
+	_, err = tx.Exec(stmt, epochEnd, ed.Dur, ed.MatchVolume, ed.QuoteVolume, ed.BookBuys, ed.BookBuys5, ed.BookBuys25,
+		ed.BookSells, ed.BookSells5, ed.BookSells25, ed.HighRate, ed.LowRate, ed.StartRate, ed.EndRate)
+	if err != nil {
+		a.fatalBackendErr(err)
+		return err
+	}
+
+	if ed.Transcript != nil {
+		epochTranscriptsTableName := fullEpochTranscriptsTableName(marketSchema)
+		stmt = fmt.Sprintf(internal.InsertEpochTranscript, epochTranscriptsTableName)
+		_, err = tx.Exec(stmt, ed.Idx, ed.Dur, order.EncodeEpochTranscript(ed.Transcript))
+		if err != nil {
+			a.fatalBackendErr(err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		a.fatalBackendErr(err)
+	}
+	return err
+}
+
+// EpochTranscript retrieves the transcript of a market's epoch.
+func (a *Archiver) EpochTranscript(base, quote uint32, epochIdx, epochDur int64) (*order.EpochTranscript, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	stmt := fmt.Sprintf(internal.SelectEpochTranscript, fullEpochTranscriptsTableName(marketSchema))
+	var b []byte
+	err = a.db.QueryRowContext(ctx, stmt, epochIdx, epochDur).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, db.ArchiveError{Code: db.ErrUnknownEpoch}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return order.DecodeEpochTranscript(b)
+}
+
+// LoadEpochStats reads all market epoch history from the database, updating the
+// provided caches along the way.
+func (a *Archiver) LoadEpochStats(base, quote uint32, caches []*candles.Cache) error {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return err
+	}
+	epochReportsTableName := fullEpochReportsTableName(marketSchema)
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	tstart := time.Now()
+	defer func() { log.Debugf("select epoch candles in: %v", time.Since(tstart)) }()
+
+	stmt := fmt.Sprintf(internal.SelectEpochCandles, epochReportsTableName)
+	rows, err := a.db.QueryContext(ctx, stmt, 0)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var endStamp, epochDur, matchVol, quoteVol, highRate, lowRate, startRate, endRate fastUint64
+	for rows.Next() {
+		err = rows.Scan(&endStamp, &epochDur, &matchVol, &quoteVol, &highRate, &lowRate, &startRate, &endRate)
+		if err != nil {
+			return err
+		}
+		candle := &candles.Candle{
+			StartStamp:  uint64(endStamp - epochDur),
+			EndStamp:    uint64(endStamp),
+			MatchVolume: uint64(matchVol),
+			QuoteVolume: uint64(quoteVol),
+			HighRate:    uint64(highRate),
+			LowRate:     uint64(lowRate),
+			StartRate:   uint64(startRate),
+			EndRate:     uint64(endRate),
+		}
+		for _, set := range caches {
+			set.Add(candle)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,130 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"decred.org/dcrdex/dex/candles"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/db"
+)
+
+func TestOrderIDs(t *testing.T) {
+	oids := orderIDs{randomOrderID(), randomOrderID()}
+	v, err := oids.Value()
+	if err != nil {
+		t.Fatalf("Value error: %v", err)
+	}
+	var checkOids orderIDs
+	if err = checkOids.Scan(v); err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+	if !reflect.DeepEqual(oids, checkOids) {
+		t.Fatalf("wrong order IDs scanned")
+	}
+
+	if err = checkOids.Scan([]byte{1, 2, 3}); err == nil {
+		t.Fatalf("no error scanning a partial order ID")
+	}
+}
+
+func TestEpochStats(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	dur := int64(EpochDuration)
+	for i := int64(0); i < 3; i++ {
+		err := archie.InsertEpoch(&db.EpochResults{
+			MktBase:        AssetDCR,
+			MktQuote:       AssetBTC,
+			Idx:            100 + i,
+			Dur:            dur,
+			MatchTime:      (100+i)*dur + 5,
+			CSum:           randomBytes(32),
+			Seed:           randomBytes(32),
+			OrdersRevealed: []order.OrderID{randomOrderID()},
+			MatchVolume:    uint64(i+1) * LotSize,
+			QuoteVolume:    uint64(i + 1),
+			HighRate:       5000000,
+			LowRate:        4000000,
+			StartRate:      4500000,
+			EndRate:        4600000,
+		})
+		if err != nil {
+			t.Fatalf("InsertEpoch error: %v", err)
+		}
+	}
+
+	// The epoch index and duration are unique.
+	err := archie.InsertEpoch(&db.EpochResults{MktBase: AssetDCR, MktQuote: AssetBTC, Idx: 100, Dur: dur})
+	if err == nil {
+		t.Fatalf("no error inserting a duplicate epoch")
+	}
+	archie.fatalMtx.Lock()
+	archie.fatalErr, archie.fatal = nil, make(chan struct{})
+	archie.fatalMtx.Unlock()
+
+	cache := candles.NewCache(10, uint64(dur))
+	if err = archie.LoadEpochStats(AssetDCR, AssetBTC, []*candles.Cache{cache}); err != nil {
+		t.Fatalf("LoadEpochStats error: %v", err)
+	}
+	last := cache.Last()
+	if last == nil || last.EndStamp != uint64(103*dur) || last.MatchVolume != 3*LotSize {
+		t.Fatalf("wrong last candle: %+v", last)
+	}
+}
+
+func TestEpochTranscript(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	dur := int64(EpochDuration)
+	oid := randomOrderID()
+	transcript := &order.EpochTranscript{
+		Base:    AssetDCR,
+		Quote:   AssetBTC,
+		Epoch:   order.EpochID{Idx: 100, Dur: uint64(dur)},
+		LotSize: LotSize,
+		CSum:    randomBytes(32),
+		Seed:    randomBytes(32),
+		Queue: []*order.TranscriptOrder{{
+			ID:       oid,
+			Type:     order.LimitOrderType,
+			Quantity: LotSize,
+			Rate:     4500000,
+			Force:    order.StandingTiF,
+			Stamp:    100 * dur,
+		}},
+		Preimages: []order.Preimage{{1}},
+	}
+	err := archie.InsertEpoch(&db.EpochResults{
+		MktBase:        AssetDCR,
+		MktQuote:       AssetBTC,
+		Idx:            100,
+		Dur:            dur,
+		MatchTime:      100*dur + 5,
+		CSum:           transcript.CSum,
+		Seed:           transcript.Seed,
+		OrdersRevealed: []order.OrderID{oid},
+		Transcript:     transcript,
+	})
+	if err != nil {
+		t.Fatalf("InsertEpoch error: %v", err)
+	}
+
+	loaded, err := archie.EpochTranscript(AssetDCR, AssetBTC, 100, dur)
+	if err != nil {
+		t.Fatalf("EpochTranscript error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Queue, transcript.Queue) || !reflect.DeepEqual(loaded.Seed, transcript.Seed) {
+		t.Fatalf("wrong transcript loaded")
+	}
+
+	// Unknown epoch.
+	_, err = archie.EpochTranscript(AssetDCR, AssetBTC, 101, dur)
+	if !db.IsErrEpochUnknown(err) {
+		t.Fatalf("expected unknown epoch error, got %v", err)
+	}
+}
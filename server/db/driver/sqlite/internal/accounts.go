@@ -0,0 +1,97 @@
+package internal
+
+const (
+	// CreateFeeKeysTable creates the fee_keys table, which is a small table that
+	// is used as a persistent child key counter for a master extended public key.
+	CreateFeeKeysTable = `CREATE TABLE IF NOT EXISTS %s (
+		key_hash BLOB PRIMARY KEY,    -- UNIQUE INDEX
+		child INTEGER DEFAULT 0
+		);`
+
+	// CreateAccountsTable creates the account table.
+	CreateAccountsTable = `CREATE TABLE IF NOT EXISTS %s (
+		account_id BLOB PRIMARY KEY,  -- UNIQUE INDEX
+		pubkey BLOB,
+		fee_asset INTEGER,
+		fee_address TEXT,
+		fee_coin BLOB,
+		broken_rule INTEGER DEFAULT 0
+		);`
+
+	// CreateBondsTable creates the bonds table, which records the fidelity
+	// bonds posted by accounts.
+	CreateBondsTable = `CREATE TABLE IF NOT EXISTS %s (
+		version INTEGER,
+		bond_coin_id BLOB NOT NULL,
+		asset_id INTEGER NOT NULL,
+		account_id BLOB NOT NULL,
+		amount INTEGER,
+		strength INTEGER,
+		lock_time INTEGER,
+		PRIMARY KEY (bond_coin_id, asset_id)
+		);`
+
+	// CreateBondsAccountIndex indexes the bonds table by account.
+	CreateBondsAccountIndex = `CREATE INDEX IF NOT EXISTS %[1]s_account_id_idx ON %[1]s (account_id);`
+
+	// InsertKeyIfMissing creates an entry for the specified key hash, if it
+	// doesn't already exist.
+	InsertKeyIfMissing = `INSERT INTO %s (key_hash)
+		VALUES (?1)
+		ON CONFLICT (key_hash) DO NOTHING
+		RETURNING child;`
+
+	CurrentKeyIndex = `SELECT child FROM %s WHERE key_hash = ?1;`
+
+	UpsertKeyIndex = `INSERT INTO %s (child, key_hash)
+		VALUES (?1, ?2)
+		ON CONFLICT (key_hash) DO UPDATE
+		SET child = ?1;`
+
+	// CloseAccount sets the broken_rule column for the account, which signifies
+	// that the account is closed.
+	CloseAccount = `UPDATE %s SET broken_rule = ?1 WHERE account_id = ?2;`
+
+	// SelectAccount gathers account details for the specified account ID. The
+	// details returned from this query are sufficient to determine 1) whether the
+	// registration fee has been paid, or 2) whether the account has been closed.
+	SelectAccount = `SELECT pubkey, fee_asset, fee_coin, broken_rule
+		FROM %s
+		WHERE account_id = ?1;`
+
+	// SelectAllAccounts retrieves all accounts.
+	SelectAllAccounts = `SELECT account_id, pubkey, fee_asset, fee_address, fee_coin, broken_rule FROM %s;`
+
+	// SelectAccountInfo retrieves all fields for an account.
+	SelectAccountInfo = `SELECT account_id, pubkey, fee_asset, fee_address, fee_coin, broken_rule FROM %s
+		WHERE account_id = ?1;`
+
+	// CreateAccount creates an entry for a new account.
+	CreateAccount = `INSERT INTO %s (account_id, pubkey, fee_asset, fee_address)
+		VALUES (?1, ?2, ?3, ?4);`
+
+	// SelectRegAddress fetches the registration fee address for the account.
+	SelectRegAddress = `SELECT fee_asset, fee_address FROM %s WHERE account_id = ?1;`
+
+	// SetRegOutput sets the registration fee payment transaction details for the
+	// account.
+	SetRegOutput = `UPDATE %s SET
+		fee_coin = ?1
+		WHERE account_id = ?2;`
+
+	// CreateAccountForBond creates an entry for a new account that is funded
+	// with a fidelity bond rather than a registration fee.
+	CreateAccountForBond = `INSERT INTO %s (account_id, pubkey)
+		VALUES (?1, ?2);`
+
+	// AddBond inserts a new bond for an account.
+	AddBond = `INSERT INTO %s (version, bond_coin_id, asset_id, account_id, amount, strength, lock_time)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7);`
+
+	// SelectActiveBondsForUser retrieves the bonds for an account with lock
+	// times after the provided threshold.
+	SelectActiveBondsForUser = `SELECT version, bond_coin_id, asset_id, amount, strength, lock_time
+		FROM %s
+		WHERE account_id = ?1 AND lock_time >= ?2
+		ORDER BY lock_time;`
+)
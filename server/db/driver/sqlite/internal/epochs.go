@@ -0,0 +1,70 @@
+package internal
+
+const (
+	// CreateEpochsTable creates a table specified via the %s printf specifier
+	// for epoch data. SQLite has no array types, so the revealed and missed
+	// order IDs are each stored as a concatenation of the 32-byte IDs.
+	CreateEpochsTable = `CREATE TABLE IF NOT EXISTS %s (
+		epoch_idx INTEGER,
+		epoch_dur INTEGER,    -- epoch duration in milliseconds
+		match_time INTEGER,   -- time at which matching and book/unbooks began
+		csum BLOB,            -- commitment checksum
+		seed BLOB,            -- preimage-derived shuffle seed
+		revealed BLOB,        -- order IDs with revealed preimages
+		missed BLOB,          -- IDs of orders with no preimage
+		PRIMARY KEY(epoch_idx, epoch_dur)  -- epoch idx:dur is unique and the primary key
+	);`
+
+	// InsertEpoch inserts the epoch's match proof data into the epoch table.
+	InsertEpoch = `INSERT INTO %s (epoch_idx, epoch_dur, match_time, csum, seed, revealed, missed)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7);`
+
+	// CreateEpochReportTable creates an epoch_reports table that holds
+	// epoch-end reports that can be used to construct market history data sets.
+	CreateEpochReportTable = `CREATE TABLE IF NOT EXISTS %s (
+		epoch_end INTEGER PRIMARY KEY, -- using timestamp instead of index to facilitate sorting and filtering with less math
+		epoch_dur INTEGER,             -- epoch duration in milliseconds
+		match_volume INTEGER,          -- total matched during epoch's match cycle
+		quote_volume INTEGER,          -- total matched volume in terms of quote asset
+		book_buys INTEGER,             -- booked buy volume
+		book_buys_5 INTEGER,           -- booked buy volume within 5 pct of market
+		book_buys_25 INTEGER,          -- booked buy volume within 25 pct of market
+		book_sells INTEGER,            -- booked sell volume
+		book_sells_5 INTEGER,          -- booked sell volume within 5 pct of market
+		book_sells_25 INTEGER,         -- booked sell volume within 25 pct of market
+		high_rate INTEGER,             -- the highest rate matched
+		low_rate INTEGER,              -- the lowest rate matched
+		start_rate INTEGER,            -- the mid-gap rate at the beginning of the match cycle
+		end_rate INTEGER               -- the mid-gap rate at the end of the match cycle
+	);`
+
+	// CreateEpochTranscriptsTable creates a table for the transcripts of the
+	// epochs with orders, with which the shuffle and matching may be verified.
+	CreateEpochTranscriptsTable = `CREATE TABLE IF NOT EXISTS %s (
+		epoch_idx INTEGER,
+		epoch_dur INTEGER,  -- epoch duration in milliseconds
+		transcript BLOB,    -- order.EncodeEpochTranscript
+		PRIMARY KEY(epoch_idx, epoch_dur)
+	);`
+
+	// InsertEpochTranscript inserts an epoch's transcript.
+	InsertEpochTranscript = `INSERT INTO %s (epoch_idx, epoch_dur, transcript)
+		VALUES (?1, ?2, ?3);`
+
+	// SelectEpochTranscript retrieves the transcript of an epoch.
+	SelectEpochTranscript = `SELECT transcript FROM %s WHERE epoch_idx = ?1 AND epoch_dur = ?2;`
+
+	// InsertEpochReport inserts a row into the epoch_reports table.
+	InsertEpochReport = `INSERT INTO %s (epoch_end, epoch_dur, match_volume, quote_volume,
+			book_buys, book_buys_5, book_buys_25, book_sells, book_sells_5, book_sells_25,
+			high_rate, low_rate, start_rate, end_rate)
+			VALUES(?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14);`
+
+	// SelectEpochCandles selects all rows from the epoch_reports table sorted
+	// by ascending time.
+	SelectEpochCandles = `SELECT epoch_end, epoch_dur, match_volume, quote_volume,
+			high_rate, low_rate, start_rate, end_rate
+		FROM %s
+		WHERE epoch_end >= ?1
+		ORDER BY epoch_end;`
+)
@@ -0,0 +1,255 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package internal
+
+const (
+	// CreateMatchesTable creates the matches table for storing data related to
+	// a match and the related swap. This only includes trade matches, not
+	// cancel order matches that just remove one order from the book (and change
+	// the target order status in the orders table). See the pg driver's
+	// CreateMatchesTable for a description of the takerSell column.
+	CreateMatchesTable = `CREATE TABLE IF NOT EXISTS %s (
+		matchid BLOB PRIMARY KEY,
+		active BOOLEAN DEFAULT TRUE, -- negotiation active, where FALSE includes failure, successful completion, or a taker cancel order
+		takerSell BOOLEAN,     -- to identify asset of address and coinIDs, NULL for cancel orders
+		takerOrder BLOB,
+		takerAccount BLOB,
+		takerAddress TEXT,     -- NULL for cancel orders
+		makerOrder BLOB,
+		makerAccount BLOB,
+		makerAddress TEXT,     -- NULL for cancel orders
+		epochIdx INTEGER,
+		epochDur INTEGER,
+		quantity INTEGER,
+		rate INTEGER,
+		baseRate INTEGER, quoteRate INTEGER, -- contract tx fee rates, NULL for cancel orders
+		status INTEGER,        -- also updated during swap negotiation, independent from active for failed swaps
+		forgiven BOOLEAN,
+
+		-- The remaining columns are only set during swap negotiation.
+		sigMatchAckMaker BLOB,   -- maker's ack of the match
+		sigMatchAckTaker BLOB,   -- taker's ack of the match
+
+		-- initiator/A (maker) CONTRACT data
+		aContractCoinID BLOB,    -- coinID (e.g. tx:vout) with the contract
+		aContract BLOB,          -- includes secret hash, get with ExtractSwapDetails for DCR
+		aContractTime INTEGER,   -- server time stamp
+		bSigAckOfAContract BLOB, -- counterparty's (participant) sig with ack of initiator CONTRACT data
+
+		-- participant/B (taker) CONTRACT data
+		bContractCoinID BLOB,
+		bContract BLOB,
+		bContractTime INTEGER,   -- server time stamp
+		aSigAckOfBContract BLOB, -- counterparty's (initiator) sig with ack of participant CONTRACT data
+
+		-- initiator/A (maker) REDEEM data
+		aRedeemCoinID BLOB,      -- the input spending the taker's contract output includes the secret
+		aRedeemSecret BLOB,
+		aRedeemTime INTEGER,     -- server time stamp
+		bSigAckOfARedeem BLOB,   -- counterparty's (participant) sig with ack of initiator REDEEM data
+
+		-- participant/B (taker) REDEEM data
+		bRedeemCoinID BLOB,
+		bRedeemTime INTEGER      -- server time stamp
+	)`
+
+	// CreateMatchesIndexes indexes the matches table specified by the %s
+	// printf specifier on the taker and maker account columns.
+	CreateMatchesIndexes = `CREATE INDEX IF NOT EXISTS %[1]s_takerAccount_idx ON %[1]s (takerAccount);
+		CREATE INDEX IF NOT EXISTS %[1]s_makerAccount_idx ON %[1]s (makerAccount);`
+
+	RetrieveSwapData = `SELECT status, sigMatchAckMaker, sigMatchAckTaker,
+		aContractCoinID, aContract, aContractTime, bSigAckOfAContract,
+		bContractCoinID, bContract, bContractTime, aSigAckOfBContract,
+		aRedeemCoinID, aRedeemSecret, aRedeemTime, bSigAckOfARedeem,
+		bRedeemCoinID, bRedeemTime
+	FROM %s WHERE matchid = ?1;`
+
+	UpsertMatch = `INSERT INTO %s (matchid, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur,
+		quantity, rate, baseRate, quoteRate, status)
+	VALUES (?1, ?2,
+		?3, ?4, ?5,
+		?6, ?7, ?8,
+		?9, ?10,
+		?11, ?12, ?13, ?14, ?15)
+	ON CONFLICT (matchid) DO
+	UPDATE SET quantity = ?11, status = ?15;`
+
+	UpsertCancelMatch = `INSERT INTO %s (matchid, active, -- omit takerSell
+			takerOrder, takerAccount, -- no taker address for a cancel order
+			makerOrder, makerAccount, -- omit maker's swap address too
+			epochIdx, epochDur,
+			quantity, rate, status) -- omit base and quote fee rates
+		VALUES (?1, FALSE, -- no active swap for a cancel
+			?2, ?3,
+			?4, ?5,
+			?6, ?7,
+			?8, ?9, ?10) -- status should be MatchComplete although there is no swap
+		ON CONFLICT (matchid) DO NOTHING;`
+
+	RetrieveMatchByID = `SELECT matchid, active, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status
+	FROM %s WHERE matchid = ?1;`
+
+	RetrieveUserMatches = `SELECT matchid, active, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status
+	FROM %s
+	WHERE takerAccount = ?1 OR makerAccount = ?1;`
+
+	RetrieveActiveUserMatches = `SELECT matchid, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status
+	FROM %s
+	WHERE (takerAccount = ?1 OR makerAccount = ?1)
+		AND active;`
+
+	RetrieveMarketMatches = `SELECT matchid, active, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status,
+		aContractCoinID, bContractCoinID, aRedeemCoinID, bRedeemCoinID
+	FROM %s
+	WHERE takerSell IS NOT NULL -- not a cancel order
+	ORDER BY epochIdx * epochDur DESC, matchid DESC
+	LIMIT ?1;`
+
+	// RetrieveMarketTrades retrieves the most recent trade matches, excluding
+	// inactive matches with a status before the status in ?2, e.g. failed
+	// before the maker redeemed. The match ID breaks ties in epoch time.
+	RetrieveMarketTrades = `SELECT matchid, active, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status,
+		aContractCoinID, bContractCoinID, aRedeemCoinID, bRedeemCoinID
+	FROM %s
+	WHERE takerSell IS NOT NULL -- not a cancel order
+		AND (active OR status >= ?2)
+	ORDER BY epochIdx * epochDur DESC, matchid DESC
+	LIMIT ?1;`
+
+	// RetrieveMarketTradesBefore is like RetrieveMarketTrades, but starts after
+	// the match with ID ?3 in the same order. Both table names must be given.
+	RetrieveMarketTradesBefore = `SELECT matchid, active, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status,
+		aContractCoinID, bContractCoinID, aRedeemCoinID, bRedeemCoinID
+	FROM %s
+	WHERE takerSell IS NOT NULL -- not a cancel order
+		AND (active OR status >= ?2)
+		AND (epochIdx * epochDur, matchid) <
+			(SELECT epochIdx * epochDur, matchid FROM %s WHERE matchid = ?3)
+	ORDER BY epochIdx * epochDur DESC, matchid DESC
+	LIMIT ?1;`
+
+	RetrieveActiveMarketMatches = `SELECT matchid, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status,
+		aContractCoinID, bContractCoinID, aRedeemCoinID, bRedeemCoinID
+	FROM %s
+	WHERE takerSell IS NOT NULL -- not a cancel order
+		AND active
+	ORDER BY epochIdx * epochDur DESC;`
+
+	// RetrieveActiveMarketMatchesExtended combines RetrieveSwapData with
+	// RetrieveActiveMarketMatches.
+	RetrieveActiveMarketMatchesExtended = `SELECT matchid, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status,
+		sigMatchAckMaker, sigMatchAckTaker,
+		aContractCoinID, aContract, aContractTime, bSigAckOfAContract,
+		bContractCoinID, bContract, bContractTime, aSigAckOfBContract,
+		aRedeemCoinID, aRedeemSecret, aRedeemTime, bSigAckOfARedeem,
+		bRedeemCoinID, bRedeemTime
+	FROM %s
+	WHERE takerSell IS NOT NULL -- not a cancel order
+		AND active
+	ORDER BY epochIdx * epochDur DESC;`
+
+	// CompletedOrAtFaultMatchesLastN retrieves inactive matches for a user that
+	// are either successfully completed by the user (MatchComplete or
+	// MakerRedeemed with user as maker), or failed because of this user's
+	// inaction. Note that the literal status values used in this query MUST BE
+	// UPDATED if the order.OrderStatus enum is changed. The multi-argument MAX
+	// is SQLite's GREATEST, but it is NULL if any argument is NULL.
+	CompletedOrAtFaultMatchesLastN = `
+		SELECT matchid, status, quantity, (status=4 OR (status=3 AND makerAccount = ?1 AND takerAccount != ?1)) AS success,
+			MAX((epochIdx+1)*epochDur, COALESCE(aContractTime, 0), COALESCE(bContractTime, 0),
+				COALESCE(aRedeemTime, 0), COALESCE(bRedeemTime, 0)) AS lastTime
+		FROM %s
+		WHERE takerSell IS NOT NULL      -- exclude cancel order matches
+			AND (makerAccount = ?1 OR takerAccount = ?1)
+			AND (
+				-- swap success for both
+				status=4                                       -- success for both
+				OR
+				-- swap success for maker unless maker==taker
+				(status=3 AND makerAccount = ?1 AND takerAccount != ?1)
+				OR
+				( -- at-fault swap failures
+					NOT active -- failure means inactive/revoked
+					AND (forgiven IS NULL OR NOT forgiven)
+					AND (
+						(status=0 AND makerAccount = ?1) OR   -- fault for maker
+						(status=1 AND takerAccount = ?1) OR   -- fault for taker
+						(status=2 AND makerAccount = ?1) OR   -- fault for maker
+						(status=3 AND takerAccount = ?1)      -- fault for taker
+					)
+				)
+			)
+		ORDER BY lastTime DESC   -- last action time i.e. success or approx. when could have acted
+		LIMIT ?2;`
+
+	ForgiveMatchFail = `UPDATE %s SET forgiven = TRUE
+		WHERE matchid = ?1 AND NOT active;`
+
+	SetMakerMatchAckSig = `UPDATE %s SET sigMatchAckMaker = ?2 WHERE matchid = ?1;`
+	SetTakerMatchAckSig = `UPDATE %s SET sigMatchAckTaker = ?2 WHERE matchid = ?1;`
+
+	SetInitiatorSwapData = `UPDATE %s SET status = ?2,
+		aContractCoinID = ?3, aContract = ?4, aContractTime = ?5
+	WHERE matchid = ?1;`
+	SetParticipantSwapData = `UPDATE %s SET status = ?2,
+		bContractCoinID = ?3, bContract = ?4, bContractTime = ?5
+	WHERE matchid = ?1;`
+
+	SetParticipantContractAuditSig = `UPDATE %s SET bSigAckOfAContract = ?2 WHERE matchid = ?1;`
+	SetInitiatorContractAuditSig   = `UPDATE %s SET aSigAckOfBContract = ?2 WHERE matchid = ?1;`
+
+	SetInitiatorRedeemData = `UPDATE %s SET status = ?2,
+		aRedeemCoinID = ?3, aRedeemSecret = ?4, aRedeemTime = ?5
+	WHERE matchid = ?1;`
+	SetParticipantRedeemData = `UPDATE %s SET status = ?2,
+		bRedeemCoinID = ?3, bRedeemTime = ?4, active = FALSE
+	WHERE matchid = ?1;`
+
+	SetParticipantRedeemAckSig = `UPDATE %s
+		SET bSigAckOfARedeem = ?2
+		WHERE matchid = ?1;`
+
+	SetSwapDone = `UPDATE %s SET active = FALSE  -- leave forgiven NULL
+		WHERE matchid = ?1;`
+
+	SetSwapDoneForgiven = `UPDATE %s SET active = FALSE, forgiven = TRUE
+		WHERE matchid = ?1;`
+
+	// SelectMatchStatuses retrieves the statuses of the user's matches. The
+	// table is %[1]s, and %[2]s is a list of placeholders for the match IDs,
+	// starting with ?2.
+	SelectMatchStatuses = `SELECT takerSell, (takerAccount = ?1) AS isTaker, (makerAccount = ?1) AS isMaker, matchid, status, aContract, bContract, aContractCoinID,
+		bContractCoinID, aRedeemCoinID, bRedeemCoinID, aRedeemSecret, active
+		FROM %[1]s
+		WHERE matchid IN (%[2]s)
+		AND (takerAccount = ?1 OR makerAccount = ?1);`
+)
@@ -0,0 +1,224 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package internal
+
+const (
+	// CreateOrdersTable creates a table specified via the %s printf specifier
+	// for market and limit orders. The client and server times are stored as
+	// UNIX milliseconds.
+	CreateOrdersTable = `CREATE TABLE IF NOT EXISTS %s (
+		oid BLOB PRIMARY KEY, -- UNIQUE
+		type INTEGER,
+		sell BOOLEAN,
+		account_id BLOB,
+		address TEXT,
+		client_time INTEGER,
+		server_time INTEGER,
+		commit_hash BLOB UNIQUE,
+		coins BLOB,
+		quantity INTEGER,
+		rate INTEGER,
+		force INTEGER,
+		status INTEGER,
+		filled INTEGER,
+		epoch_idx INTEGER, epoch_dur INTEGER,
+		preimage BLOB UNIQUE,
+		complete_time INTEGER,        -- when the order has successfully completed all swaps
+		expiry_epoch INTEGER DEFAULT 0, -- the last epoch index of a good-til-time order
+		display_qty INTEGER DEFAULT 0,  -- the displayed quantity of an iceberg order
+		replaces BLOB DEFAULT NULL      -- the order ID replaced by a replacement order
+	);`
+
+	// CreateAccountIndex creates an index on the account_id column of the
+	// orders or cancel orders table specified by the %s printf specifier.
+	CreateAccountIndex = `CREATE INDEX IF NOT EXISTS %[1]s_account_id_idx ON %[1]s (account_id);`
+
+	// InsertOrder inserts a market or limit order into the specified table.
+	InsertOrder = `INSERT INTO %s (oid, type, sell, account_id, address,
+			client_time, server_time, commit_hash, coins, quantity,
+			rate, force, status, filled,
+			epoch_idx, epoch_dur, expiry_epoch, display_qty, replaces)
+		VALUES (?1, ?2, ?3, ?4, ?5,
+			?6, ?7, ?8, ?9, ?10,
+			?11, ?12, ?13, ?14,
+			?15, ?16, ?17, ?18, ?19);`
+
+	// SelectOrder retrieves all columns with the given order ID. This may be
+	// used for any table with an "oid" column (orders_active, cancels_archived,
+	// etc.).
+	SelectOrder = `SELECT oid, type, sell, account_id, address, client_time, server_time,
+		commit_hash, coins, quantity, rate, force, status, filled, expiry_epoch, display_qty, replaces
+	FROM %s WHERE oid = ?1;`
+
+	SelectOrdersByStatus = `SELECT oid, type, sell, account_id, address, client_time, server_time,
+		commit_hash, coins, quantity, rate, force, filled, expiry_epoch, display_qty, replaces
+	FROM %s WHERE status = ?1;`
+
+	PreimageResultsLastN = `SELECT oid, (preimage IS NULL AND status=?3) AS preimageMiss,
+		(epoch_idx+1) * epoch_dur as epochCloseTime   -- when preimages are requested
+	FROM %s -- e.g. dcr_btc_orders_archived
+	WHERE account_id = ?1
+		AND status >= 0         -- exclude forgiven
+	ORDER BY epochCloseTime DESC
+	LIMIT ?2`
+
+	// SelectUserOrders retrieves all columns of all orders for the given
+	// account ID.
+	SelectUserOrders = `SELECT oid, type, sell, account_id, address, client_time, server_time,
+		commit_hash, coins, quantity, rate, force, status, filled, expiry_epoch, display_qty, replaces
+	FROM %s WHERE account_id = ?1;`
+
+	// SelectUserOrderStatuses retrieves the order IDs and statuses of all orders
+	// for the given account ID. Only applies to market and limit orders.
+	SelectUserOrderStatuses = `SELECT oid, status FROM %s WHERE account_id = ?1;`
+
+	// SelectUserOrderStatusesByID retrieves the order IDs and statuses of the
+	// orders with the provided order IDs for the given account ID. Only applies
+	// to market and limit orders. The table is %[1]s, and %[2]s is a list of
+	// placeholders for the order IDs, starting with ?2.
+	SelectUserOrderStatusesByID = `SELECT oid, status FROM %[1]s WHERE account_id = ?1 AND oid IN (%[2]s);`
+
+	// SelectOrderByCommit retrieves the order ID for any order with the given
+	// commitment value. This applies to the cancel order tables as well.
+	SelectOrderByCommit = `SELECT oid FROM %s WHERE commit_hash = ?1;`
+
+	// SelectOrderPreimage retrieves the preimage for the order ID;
+	SelectOrderPreimage = `SELECT preimage FROM %s WHERE oid = ?1;`
+
+	// SelectOrderCoinIDs retrieves the order id, sell flag, and coins for all
+	// orders in a certain table.
+	SelectOrderCoinIDs = `SELECT oid, sell, coins
+		FROM %s;`
+
+	SetOrderPreimage     = `UPDATE %s SET preimage = ?1 WHERE oid = ?2;`
+	SetOrderCompleteTime = `UPDATE %s SET complete_time = ?1
+		WHERE oid = ?2;`
+
+	RetrieveCompletedOrdersForAccount = `SELECT oid, account_id, complete_time
+		FROM %s
+		WHERE account_id = ?1 AND complete_time IS NOT NULL
+		ORDER BY complete_time DESC
+		LIMIT ?2;`
+
+	// UpdateOrderStatus sets the status of an order with the given order ID.
+	UpdateOrderStatus = `UPDATE %s SET status = ?1 WHERE oid = ?2;`
+	// UpdateOrderFilledAmt sets the filled amount of an order with the given
+	// order ID.
+	UpdateOrderFilledAmt = `UPDATE %s SET filled = ?1 WHERE oid = ?2;`
+	// UpdateOrderStatusAndFilledAmt sets the order status and filled amount of
+	// an order with the given order ID.
+	UpdateOrderStatusAndFilledAmt = `UPDATE %s SET status = ?1, filled = ?2 WHERE oid = ?3;`
+
+	// OrderStatus retrieves the order type, status, and filled amount for an
+	// order with the given order ID. This only applies to market and limit
+	// orders. For cancel orders, which lack a type and filled column, use
+	// CancelOrderStatus.
+	OrderStatus = `SELECT type, status, filled FROM %s WHERE oid = ?1;`
+
+	// CopyOrder copies an order row from one table to another (e.g.
+	// orders_active to orders_archived), while setting the order's status and
+	// filled amount. SQLite does not support data-modifying statements in a
+	// WITH clause, so this must be followed by DeleteOrder in the same
+	// transaction to complete the move. The destination table is %[2]s, and
+	// the source table is %[1]s.
+	CopyOrder = `INSERT INTO %[2]s (oid, type, sell, account_id, address,
+			client_time, server_time, commit_hash, coins, quantity,
+			rate, force, status, filled,
+			epoch_idx, epoch_dur, preimage, complete_time, expiry_epoch, display_qty, replaces)
+		SELECT oid, type, sell, account_id, address,
+			client_time, server_time, commit_hash, coins, quantity,
+			rate, force, ?2, ?3,
+			epoch_idx, epoch_dur, preimage, complete_time, expiry_epoch, display_qty, replaces
+		FROM %[1]s
+		WHERE oid = ?1;`
+
+	// DeleteOrder deletes the order with the given order ID. This applies to
+	// the cancel order tables as well.
+	DeleteOrder = `DELETE FROM %s WHERE oid = ?1;`
+
+	// SelectOrdersByStatusBrief retrieves the order ID, sell flag, and account
+	// ID of all orders with the given status. This is used with
+	// CopyOrdersByStatus and DeleteOrdersByStatus to purge the book.
+	SelectOrdersByStatusBrief = `SELECT oid, sell, account_id FROM %s WHERE status = ?1;`
+
+	// CopyOrdersByStatus copies all orders with the status ?1 from table %[1]s
+	// to table %[2]s, setting the status to ?2.
+	CopyOrdersByStatus = `INSERT INTO %[2]s (oid, type, sell, account_id, address,
+			client_time, server_time, commit_hash, coins, quantity,
+			rate, force, status, filled,
+			epoch_idx, epoch_dur, preimage, complete_time, expiry_epoch, display_qty, replaces)
+		SELECT oid, type, sell, account_id, address,
+			client_time, server_time, commit_hash, coins, quantity,
+			rate, force, ?2, filled,
+			epoch_idx, epoch_dur, preimage, complete_time, expiry_epoch, display_qty, replaces
+		FROM %[1]s
+		WHERE status = ?1;`
+
+	// DeleteOrdersByStatus deletes all orders with the given status.
+	DeleteOrdersByStatus = `DELETE FROM %s WHERE status = ?1;`
+
+	// CreateCancelOrdersTable creates a table specified via the %s printf
+	// specifier for cancel orders.
+	CreateCancelOrdersTable = `CREATE TABLE IF NOT EXISTS %s (
+		oid BLOB PRIMARY KEY, -- UNIQUE INDEX
+		account_id BLOB,
+		client_time INTEGER,
+		server_time INTEGER,
+		commit_hash BLOB UNIQUE, -- null for server-generated cancels (order revocations)
+		target_order BLOB,       -- cancel orders ref another order
+		status INTEGER,
+		epoch_idx INTEGER, epoch_dur INTEGER, -- 0 for rule-based revocations, -1 for exempt (e.g. book purge)
+		preimage BLOB UNIQUE     -- null before preimage collection, and all server-generated cancels (revocations)
+	);`
+
+	SelectCancelOrder = `SELECT oid, account_id, client_time, server_time,
+		commit_hash, target_order, status
+	FROM %s WHERE oid = ?1;`
+
+	SelectCancelOrdersByStatus = `SELECT account_id, client_time, server_time,
+		commit_hash, target_order
+	FROM %s WHERE status = ?1;`
+
+	CancelPreimageResultsLastN = `SELECT oid, (preimage IS NULL AND status=?3) AS preimageMiss,  -- orderStatusRevoked
+		(epoch_idx+1) * epoch_dur AS epochCloseTime   -- when preimages are requested
+	FROM %s -- e.g. dcr_btc_cancels_archived
+	WHERE account_id = ?1
+		AND commit_hash IS NOT NULL  -- commit NOT NULL to exclude server-generated cancels
+		AND status >= 0              -- not forgiven
+	ORDER BY epochCloseTime DESC
+	LIMIT ?2`
+
+	// SelectRevokeCancels retrieves server-initiated cancels (revokes).
+	SelectRevokeCancels = `SELECT oid, target_order, server_time, epoch_idx
+		FROM %s
+		WHERE account_id = ?1 AND status = ?2 -- use orderStatusRevoked
+		ORDER BY server_time DESC
+		LIMIT ?3;`
+
+	// RetrieveCancelTimesForUserByStatus gets matched cancel orders by user and
+	// status, joining on an epochs table to get the match_time. The cancels
+	// table is %[1]s, while the epochs table is %[2]s.
+	RetrieveCancelTimesForUserByStatus = `SELECT oid, target_order, match_time
+		FROM %[1]s -- a cancels table
+		JOIN %[2]s ON %[2]s.epoch_idx = %[1]s.epoch_idx AND %[2]s.epoch_dur = %[1]s.epoch_dur -- join on epochs table PK
+		WHERE account_id = ?1 AND status = ?2
+		ORDER BY match_time DESC
+		LIMIT ?3;` // NOTE: find revoked orders via SelectRevokeCancels
+
+	// InsertCancelOrder inserts a cancel order row into the specified table.
+	InsertCancelOrder = `INSERT INTO %s (oid, account_id, client_time, server_time, commit_hash, target_order, status, epoch_idx, epoch_dur)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9);`
+
+	// CancelOrderStatus retrieves an order's status
+	CancelOrderStatus = `SELECT status FROM %s WHERE oid = ?1;`
+
+	// CopyCancelOrder, like CopyOrder, copies an order row from one table to
+	// another. However, for a cancel order, only the status column is updated.
+	CopyCancelOrder = `INSERT INTO %[2]s (oid, account_id, client_time, server_time,
+			commit_hash, target_order, status, epoch_idx, epoch_dur, preimage)
+		SELECT oid, account_id, client_time, server_time,
+			commit_hash, target_order, ?2, epoch_idx, epoch_dur, preimage
+		FROM %[1]s
+		WHERE oid = ?1;`
+)
@@ -0,0 +1,91 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/db/driver/sqlite/internal"
+)
+
+func loadMarkets(db sqlQueryer, marketsTableName string) ([]*dex.MarketInfo, error) {
+	stmt := fmt.Sprintf(internal.SelectAllMarkets, marketsTableName)
+	rows, err := db.Query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mkts []*dex.MarketInfo
+	for rows.Next() {
+		var name string
+		var base, quote uint32
+		var lotSize uint64
+		err = rows.Scan(&name, &base, &quote, &lotSize)
+		if err != nil {
+			return nil, err
+		}
+		mkts = append(mkts, &dex.MarketInfo{
+			Name:    name,
+			Base:    base,
+			Quote:   quote,
+			LotSize: lotSize,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mkts, nil
+}
+
+func newMarket(db *sql.DB, marketsTableName string, mkt *dex.MarketInfo) error {
+	stmt := fmt.Sprintf(internal.InsertMarket, marketsTableName)
+	res, err := db.Exec(stmt, mkt.Name, mkt.Base, mkt.Quote, mkt.LotSize)
+	if err != nil {
+		return err
+	}
+	N, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if N != 1 {
+		return fmt.Errorf("failed to insert market, %d rows affected", N)
+	}
+	return nil
+}
+
+func createMarketTables(db *sql.DB, marketName string) error {
+	marketUID := marketSchema(marketName)
+	var created int
+	for _, c := range createMarketTableStatements {
+		newTable, err := createTable(db, marketUID, c.name)
+		if err != nil {
+			return err
+		}
+		if newTable {
+			created++
+		}
+	}
+
+	switch created {
+	case len(createMarketTableStatements):
+		log.Debugf("Created new tables for market %q", marketUID)
+	case 0:
+		log.Tracef("Tables for market %q already exist.", marketUID)
+	default:
+		log.Warnf("Created %d missing tables for existing market %s.", created, marketUID)
+	}
+	return nil
+}
+
+// marketSchema replaces the special token symbol character '.' with "TKN", as
+// with the pg driver, so the market name may be used in table names.
+func marketSchema(marketName string) string {
+	return strings.ReplaceAll(marketName, ".", "TKN")
+}
@@ -0,0 +1,271 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/db"
+)
+
+func TestMatchSwapData(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	maker := newLimitOrder(false, 4900000, 1, order.StandingTiF, 0)
+	taker := newLimitOrder(true, 4800000, 1, order.ImmediateTiF, 1)
+	match := newMatch(maker, taker, maker.Quantity, order.EpochID{Idx: 10, Dur: EpochDuration})
+	if err := archie.InsertMatch(match); err != nil {
+		t.Fatalf("InsertMatch error: %v", err)
+	}
+	mid := db.MatchID(match)
+
+	md, err := archie.MatchByID(match.ID(), AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("MatchByID error: %v", err)
+	}
+	if !md.Active || md.Status != order.NewlyMatched || !md.TakerSell || md.Quantity != match.Quantity ||
+		md.Maker != maker.ID() || md.Taker != taker.ID() || md.BaseRate != match.FeeRateBase {
+		t.Fatalf("wrong match data: %+v", md)
+	}
+	if _, err = archie.MatchByID(order.MatchID{}, AssetDCR, AssetBTC); !db.IsErrMatchUnknown(err) {
+		t.Fatalf("expected ErrUnknownMatch, got %v", err)
+	}
+
+	// Update the status with an upsert.
+	match.Status = order.MakerSwapCast
+	if err = archie.InsertMatch(match); err != nil {
+		t.Fatalf("InsertMatch (update) error: %v", err)
+	}
+
+	contractA, coinA, secret := randomBytes(60), randomBytes(36), randomBytes(32)
+	steps := []func() error{
+		func() error { return archie.SaveMatchAckSigA(mid, randomBytes(72)) },
+		func() error { return archie.SaveMatchAckSigB(mid, randomBytes(72)) },
+		func() error { return archie.SaveContractA(mid, contractA, coinA, 1000) },
+		func() error { return archie.SaveAuditAckSigB(mid, randomBytes(72)) },
+		func() error { return archie.SaveContractB(mid, randomBytes(60), randomBytes(36), 2000) },
+		func() error { return archie.SaveAuditAckSigA(mid, randomBytes(72)) },
+		func() error { return archie.SaveRedeemA(mid, randomBytes(36), secret, 3000) },
+		func() error { return archie.SaveRedeemAckSigB(mid, randomBytes(72)) },
+	}
+	for i, step := range steps {
+		if err = step(); err != nil {
+			t.Fatalf("swap step %d error: %v", i, err)
+		}
+	}
+
+	status, sd, err := archie.SwapData(mid)
+	if err != nil {
+		t.Fatalf("SwapData error: %v", err)
+	}
+	if status != order.MakerRedeemed { // set by SaveRedeemA
+		t.Fatalf("wrong match status %v", status)
+	}
+	if !bytes.Equal(sd.ContractA, contractA) || !bytes.Equal(sd.ContractACoinID, coinA) ||
+		!bytes.Equal(sd.RedeemASecret, secret) || sd.ContractATime != 1000 || sd.RedeemATime != 3000 {
+		t.Fatalf("wrong swap data: %+v", sd)
+	}
+
+	swaps, err := archie.ActiveSwaps()
+	if err != nil {
+		t.Fatalf("ActiveSwaps error: %v", err)
+	}
+	if len(swaps) != 1 || swaps[0].ID != match.ID() || !bytes.Equal(swaps[0].SwapData.ContractA, contractA) {
+		t.Fatalf("wrong active swaps")
+	}
+
+	statuses, err := archie.MatchStatuses(taker.User(), AssetDCR, AssetBTC, []order.MatchID{match.ID(), {}})
+	if err != nil {
+		t.Fatalf("MatchStatuses error: %v", err)
+	}
+	if len(statuses) != 1 || !statuses[0].IsTaker || statuses[0].IsMaker || !bytes.Equal(statuses[0].Secret, secret) {
+		t.Fatalf("wrong match statuses: %+v", statuses)
+	}
+
+	if err = archie.SaveRedeemB(mid, randomBytes(36), 4000); err != nil {
+		t.Fatalf("SaveRedeemB error: %v", err)
+	}
+	if swaps, _ = archie.ActiveSwaps(); len(swaps) != 0 {
+		t.Fatalf("completed swap still active")
+	}
+}
+
+func TestMarketAndUserMatches(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	maker := newLimitOrder(false, 4900000, 2, order.StandingTiF, 0)
+	takerA := newLimitOrder(true, 4800000, 1, order.ImmediateTiF, 1)
+	takerB := newLimitOrder(true, 4800000, 1, order.ImmediateTiF, 2)
+	matchA := newMatch(maker, takerA, takerA.Quantity, order.EpochID{Idx: 10, Dur: EpochDuration})
+	matchB := newMatch(maker, takerB, takerB.Quantity, order.EpochID{Idx: 11, Dur: EpochDuration})
+	cancel := newCancelOrder(maker.ID(), AssetDCR, AssetBTC, 3)
+	cancelMatch := newMatch(maker, cancel, maker.Remaining(), order.EpochID{Idx: 12, Dur: EpochDuration})
+	for _, match := range []*order.Match{matchA, matchB, cancelMatch} {
+		if err := archie.InsertMatch(match); err != nil {
+			t.Fatalf("InsertMatch error: %v", err)
+		}
+	}
+	if err := archie.SetMatchInactive(db.MatchID(matchA), false); err != nil {
+		t.Fatalf("SetMatchInactive error: %v", err)
+	}
+
+	userMatches, err := archie.UserMatches(maker.User(), AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("UserMatches error: %v", err)
+	}
+	if len(userMatches) != 3 {
+		t.Fatalf("expected 3 user matches, got %d", len(userMatches))
+	}
+
+	activeMatches, err := archie.AllActiveUserMatches(maker.User())
+	if err != nil {
+		t.Fatalf("AllActiveUserMatches error: %v", err)
+	}
+	if len(activeMatches) != 1 || activeMatches[0].ID != matchB.ID() {
+		t.Fatalf("wrong active user matches")
+	}
+
+	mktMatches, err := archie.MarketMatches(AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("MarketMatches error: %v", err)
+	}
+	if len(mktMatches) != 1 || mktMatches[0].ID != matchB.ID() {
+		t.Fatalf("wrong market matches")
+	}
+
+	// All trade matches, including inactive ones, most recent first. Cancel
+	// matches are excluded.
+	var ids []order.MatchID
+	N, err := archie.MarketMatchesStreaming(AssetDCR, AssetBTC, true, 2, func(m *db.MatchDataWithCoins) error {
+		ids = append(ids, m.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("MarketMatchesStreaming error: %v", err)
+	}
+	if N != 2 || ids[0] != matchB.ID() || ids[1] != matchA.ID() {
+		t.Fatalf("wrong streamed matches")
+	}
+}
+
+func TestMarketTrades(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	maker := newLimitOrder(false, 4900000, 4, order.StandingTiF, 0)
+	var takers []*order.LimitOrder
+	for i := 0; i < 4; i++ {
+		takers = append(takers, newLimitOrder(true, 4800000, 1, order.ImmediateTiF, int64(i+1)))
+	}
+	// A failed match and a completed match in epoch 10, and two active matches
+	// in epoch 11.
+	failed := newMatch(maker, takers[0], 1, order.EpochID{Idx: 10, Dur: EpochDuration})
+	complete := newMatch(maker, takers[1], 1, order.EpochID{Idx: 10, Dur: EpochDuration})
+	complete.Status = order.MatchComplete
+	activeA := newMatch(maker, takers[2], 1, order.EpochID{Idx: 11, Dur: EpochDuration})
+	activeB := newMatch(maker, takers[3], 1, order.EpochID{Idx: 11, Dur: EpochDuration})
+	cancel := newCancelOrder(maker.ID(), AssetDCR, AssetBTC, 5)
+	cancelMatch := newMatch(maker, cancel, maker.Remaining(), order.EpochID{Idx: 12, Dur: EpochDuration})
+	for _, match := range []*order.Match{failed, complete, activeA, activeB, cancelMatch} {
+		if err := archie.InsertMatch(match); err != nil {
+			t.Fatalf("InsertMatch error: %v", err)
+		}
+	}
+	for _, match := range []*order.Match{failed, complete} {
+		if err := archie.SetMatchInactive(db.MatchID(match), false); err != nil {
+			t.Fatalf("SetMatchInactive error: %v", err)
+		}
+	}
+
+	// Most recent first, with the match ID breaking the tie in epoch 11.
+	wantIDs := []order.MatchID{activeA.ID(), activeB.ID(), complete.ID()}
+	if bytes.Compare(wantIDs[0][:], wantIDs[1][:]) < 0 {
+		wantIDs[0], wantIDs[1] = wantIDs[1], wantIDs[0]
+	}
+	trades, err := archie.MarketTrades(AssetDCR, AssetBTC, nil, 10)
+	if err != nil {
+		t.Fatalf("MarketTrades error: %v", err)
+	}
+	if len(trades) != len(wantIDs) {
+		t.Fatalf("wanted %d trades, got %d", len(wantIDs), len(trades))
+	}
+	for i, md := range trades {
+		if md.ID != wantIDs[i] {
+			t.Fatalf("trade %d: wrong match ID", i)
+		}
+	}
+
+	// Paging from the last trade received gives the same order.
+	var before *order.MatchID
+	for i, wantID := range wantIDs {
+		trades, err = archie.MarketTrades(AssetDCR, AssetBTC, before, 1)
+		if err != nil {
+			t.Fatalf("MarketTrades page %d error: %v", i, err)
+		}
+		if len(trades) != 1 || trades[0].ID != wantID {
+			t.Fatalf("wrong trade on page %d", i)
+		}
+		before = &trades[0].ID
+	}
+	if trades, _ = archie.MarketTrades(AssetDCR, AssetBTC, before, 1); len(trades) != 0 {
+		t.Fatalf("trades after the last trade")
+	}
+	// An unknown before match gives no trades.
+	if trades, _ = archie.MarketTrades(AssetDCR, AssetBTC, &order.MatchID{1}, 10); len(trades) != 0 {
+		t.Fatalf("trades before an unknown match")
+	}
+}
+
+func TestCompletedAndAtFaultMatchStats(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	maker := newLimitOrder(false, 4900000, 2, order.StandingTiF, 0)
+	takerA := newLimitOrder(true, 4800000, 1, order.ImmediateTiF, 1)
+	takerB := newLimitOrder(true, 4800000, 1, order.ImmediateTiF, 2)
+
+	// A completed match, and a failed match where the maker did not swap.
+	complete := newMatch(maker, takerA, takerA.Quantity, order.EpochID{Idx: 10, Dur: EpochDuration})
+	complete.Status = order.MatchComplete
+	failed := newMatch(maker, takerB, takerB.Quantity, order.EpochID{Idx: 11, Dur: EpochDuration})
+	for _, match := range []*order.Match{complete, failed} {
+		if err := archie.InsertMatch(match); err != nil {
+			t.Fatalf("InsertMatch error: %v", err)
+		}
+		if err := archie.SetMatchInactive(db.MatchID(match), false); err != nil {
+			t.Fatalf("SetMatchInactive error: %v", err)
+		}
+	}
+
+	outcomes, err := archie.CompletedAndAtFaultMatchStats(maker.User(), 10)
+	if err != nil {
+		t.Fatalf("CompletedAndAtFaultMatchStats error: %v", err)
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("expected 2 outcomes, got %d", len(outcomes))
+	}
+	if outcomes[0].ID != failed.ID() || !outcomes[0].Fail {
+		t.Fatalf("wrong outcome for the failed match: %+v", outcomes[0])
+	}
+	if outcomes[1].ID != complete.ID() || outcomes[1].Fail {
+		t.Fatalf("wrong outcome for the completed match: %+v", outcomes[1])
+	}
+
+	// The taker was not at fault for the failed match.
+	if outcomes, _ = archie.CompletedAndAtFaultMatchStats(takerB.User(), 10); len(outcomes) != 0 {
+		t.Fatalf("taker has %d outcomes, expected 0", len(outcomes))
+	}
+
+	forgiven, err := archie.ForgiveMatchFail(failed.ID())
+	if err != nil {
+		t.Fatalf("ForgiveMatchFail error: %v", err)
+	}
+	if !forgiven {
+		t.Fatalf("match not forgiven")
+	}
+	if outcomes, _ = archie.CompletedAndAtFaultMatchStats(maker.User(), 10); len(outcomes) != 1 {
+		t.Fatalf("expected 1 outcome after forgiving, got %d", len(outcomes))
+	}
+}
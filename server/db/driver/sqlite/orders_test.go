@@ -0,0 +1,354 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/db"
+)
+
+func TestStoreLoadOrders(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	lo := newLimitOrder(false, 4900000, 1, order.GoodTilTimeTiF, 0)
+	lo.ExpiryEpoch = 1234
+	mo := newMarketSellOrder(2, 1)
+	co := newCancelOrder(lo.ID(), AssetDCR, AssetBTC, 2)
+	replacement := newLimitOrder(false, 4800000, 1, order.StandingTiF, 3)
+	replacement.Replaces = lo.ID()
+
+	for _, ord := range []order.Order{lo, mo, co, replacement} {
+		if err := archie.NewEpochOrder(ord, 10, int64(EpochDuration)); err != nil {
+			t.Fatalf("NewEpochOrder(%v) error: %v", ord.Type(), err)
+		}
+		loaded, status, err := archie.Order(ord.ID(), AssetDCR, AssetBTC)
+		if err != nil {
+			t.Fatalf("Order(%v) error: %v", ord.Type(), err)
+		}
+		if status != order.OrderStatusEpoch {
+			t.Fatalf("wrong status %v for %v order", status, ord.Type())
+		}
+		if loaded.ID() != ord.ID() {
+			t.Fatalf("loaded %v order ID %v, expected %v", ord.Type(), loaded.ID(), ord.ID())
+		}
+		if loaded.Time() != ord.Time() {
+			t.Fatalf("loaded %v order server time %v, expected %v", ord.Type(), loaded.Time(), ord.Time())
+		}
+	}
+
+	// The order ID is a hash of the serialized order, so a matching ID for a
+	// loaded order covers the stored fields.
+	loaded, _, _ := archie.Order(lo.ID(), AssetDCR, AssetBTC)
+	if loaded.(*order.LimitOrder).ExpiryEpoch != lo.ExpiryEpoch {
+		t.Fatalf("expiry epoch not loaded")
+	}
+	loaded, _, _ = archie.Order(replacement.ID(), AssetDCR, AssetBTC)
+	if loaded.(*order.LimitOrder).Replaces != lo.ID() {
+		t.Fatalf("replaced order ID not loaded")
+	}
+
+	// Reused commitment.
+	loDup := newLimitOrder(true, 4900000, 1, order.StandingTiF, 10)
+	loDup.Commit = lo.Commit
+	if err := archie.NewEpochOrder(loDup, 10, int64(EpochDuration)); !db.IsErrReusedCommit(err) {
+		t.Fatalf("expected ErrReusedCommit, got %v", err)
+	}
+
+	epochOrders, err := archie.EpochOrders(AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("EpochOrders error: %v", err)
+	}
+	if len(epochOrders) != 4 {
+		t.Fatalf("loaded %d epoch orders, expected 4", len(epochOrders))
+	}
+
+	if _, _, err = archie.Order(randomOrderID(), AssetDCR, AssetBTC); !db.IsErrOrderUnknown(err) {
+		t.Fatalf("expected ErrUnknownOrder, got %v", err)
+	}
+}
+
+func TestOrderStatusChanges(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	lo, pi := newLimitOrderRevealed(false, 4900000, 2, order.StandingTiF, 0)
+	if err := archie.NewEpochOrder(lo, 10, int64(EpochDuration)); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	if err := archie.StorePreimage(lo, pi); err != nil {
+		t.Fatalf("StorePreimage error: %v", err)
+	}
+	checkPi, err := archie.OrderPreimage(lo)
+	if err != nil {
+		t.Fatalf("OrderPreimage error: %v", err)
+	}
+	if checkPi != pi {
+		t.Fatalf("wrong preimage loaded")
+	}
+
+	if err = archie.BookOrder(lo); err != nil {
+		t.Fatalf("BookOrder error: %v", err)
+	}
+	lo.FillAmt = LotSize
+	if err = archie.UpdateOrderFilled(lo); err != nil {
+		t.Fatalf("UpdateOrderFilled error: %v", err)
+	}
+	status, _, filled, err := archie.OrderStatus(lo)
+	if err != nil {
+		t.Fatalf("OrderStatus error: %v", err)
+	}
+	if status != order.OrderStatusBooked || filled != int64(LotSize) {
+		t.Fatalf("wrong status %v or filled %d", status, filled)
+	}
+
+	baseCoins, quoteCoins, err := archie.ActiveOrderCoins(AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("ActiveOrderCoins error: %v", err)
+	}
+	if len(baseCoins) != 0 || len(quoteCoins) != 1 || !reflect.DeepEqual(quoteCoins[lo.ID()], lo.Coins) {
+		t.Fatalf("wrong active order coins")
+	}
+
+	bookOrders, err := archie.BookOrders(AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("BookOrders error: %v", err)
+	}
+	if len(bookOrders) != 1 || bookOrders[0].ID() != lo.ID() {
+		t.Fatalf("wrong book orders")
+	}
+
+	activeStatuses, err := archie.ActiveUserOrderStatuses(lo.User())
+	if err != nil {
+		t.Fatalf("ActiveUserOrderStatuses error: %v", err)
+	}
+	if len(activeStatuses) != 1 || activeStatuses[0].Status != order.OrderStatusBooked {
+		t.Fatalf("wrong active user order statuses")
+	}
+
+	// Execute the order, moving it to the archived table.
+	lo.FillAmt = lo.Quantity
+	if err = archie.ExecuteOrder(lo); err != nil {
+		t.Fatalf("ExecuteOrder error: %v", err)
+	}
+	status, _, filled, err = archie.OrderStatus(lo)
+	if err != nil {
+		t.Fatalf("OrderStatus error: %v", err)
+	}
+	if status != order.OrderStatusExecuted || filled != int64(lo.Quantity) {
+		t.Fatalf("wrong status %v or filled %d", status, filled)
+	}
+	if activeStatuses, _ = archie.ActiveUserOrderStatuses(lo.User()); len(activeStatuses) != 0 {
+		t.Fatalf("executed order still active")
+	}
+
+	if err = archie.SetOrderCompleteTime(lo, 12345); err != nil {
+		t.Fatalf("SetOrderCompleteTime error: %v", err)
+	}
+	oids, compTimes, err := archie.CompletedUserOrders(lo.User(), 10)
+	if err != nil {
+		t.Fatalf("CompletedUserOrders error: %v", err)
+	}
+	if len(oids) != 1 || oids[0] != lo.ID() || compTimes[0] != 12345 {
+		t.Fatalf("wrong completed orders")
+	}
+
+	// Moving an archived order back to an active status is an error.
+	if err = archie.BookOrder(lo); err == nil {
+		t.Fatalf("no error booking an executed order")
+	}
+
+	statuses, err := archie.UserOrderStatuses(lo.User(), AssetDCR, AssetBTC, []order.OrderID{lo.ID(), randomOrderID()})
+	if err != nil {
+		t.Fatalf("UserOrderStatuses error: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].ID != lo.ID() || statuses[0].Status != order.OrderStatusExecuted {
+		t.Fatalf("wrong user order statuses")
+	}
+
+	ords, ordStatuses, err := archie.UserOrders(archie.ctx, lo.User(), AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("UserOrders error: %v", err)
+	}
+	if len(ords) != 1 || ordStatuses[0] != order.OrderStatusExecuted {
+		t.Fatalf("wrong user orders")
+	}
+}
+
+func TestCancelsAndRevokes(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	loA := newLimitOrder(true, 4900000, 1, order.StandingTiF, 0)
+	loB := newLimitOrder(true, 4900000, 1, order.StandingTiF, 1)
+	loB.AccountID = loA.AccountID
+	loC := newLimitOrder(false, 4800000, 1, order.StandingTiF, 2)
+	for _, lo := range []*order.LimitOrder{loA, loB, loC} {
+		if err := archie.StoreOrder(lo, 10, int64(EpochDuration), order.OrderStatusBooked); err != nil {
+			t.Fatalf("StoreOrder error: %v", err)
+		}
+	}
+
+	// A user cancel of loA, matched in epoch 11.
+	co := newCancelOrder(loA.ID(), AssetDCR, AssetBTC, 5)
+	co.AccountID = loA.AccountID
+	if err := archie.NewEpochOrder(co, 11, int64(EpochDuration)); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	if err := archie.CancelOrder(loA); err != nil {
+		t.Fatalf("CancelOrder error: %v", err)
+	}
+	if err := archie.ExecuteOrder(co); err != nil {
+		t.Fatalf("ExecuteOrder error: %v", err)
+	}
+	err := archie.InsertEpoch(&db.EpochResults{
+		MktBase:   AssetDCR,
+		MktQuote:  AssetBTC,
+		Idx:       11,
+		Dur:       int64(EpochDuration),
+		MatchTime: 11*int64(EpochDuration) + 10,
+	})
+	if err != nil {
+		t.Fatalf("InsertEpoch error: %v", err)
+	}
+
+	// A counted revoke of loB.
+	revokeID, revokeTime, err := archie.RevokeOrder(loB)
+	if err != nil {
+		t.Fatalf("RevokeOrder error: %v", err)
+	}
+
+	oids, targets, execTimes, err := archie.ExecutedCancelsForUser(loA.User(), 10)
+	if err != nil {
+		t.Fatalf("ExecutedCancelsForUser error: %v", err)
+	}
+	if len(oids) != 2 {
+		t.Fatalf("expected 2 executed cancels, got %d", len(oids))
+	}
+	// The revoke is the most recent.
+	if oids[0] != revokeID || targets[0] != loB.ID() || execTimes[0] != revokeTime.UnixMilli() {
+		t.Fatalf("wrong revoke cancel")
+	}
+	if oids[1] != co.ID() || targets[1] != loA.ID() || execTimes[1] != 11*int64(EpochDuration)+10 {
+		t.Fatalf("wrong user cancel")
+	}
+
+	revokeOrd, status, err := archie.Order(revokeID, AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("Order error: %v", err)
+	}
+	if status != order.OrderStatusRevoked || revokeOrd.Time() != revokeTime.UnixMilli() {
+		t.Fatalf("wrong revoke cancel order status %v or time %v", status, revokeOrd.Time())
+	}
+
+	// Uncounted revokes are not returned.
+	if _, _, err = archie.RevokeOrderUncounted(loC); err != nil {
+		t.Fatalf("RevokeOrderUncounted error: %v", err)
+	}
+	if oids, _, _, _ = archie.ExecutedCancelsForUser(loC.User(), 10); len(oids) != 0 {
+		t.Fatalf("uncounted revoke returned")
+	}
+
+	// A failed cancel order stays in the archived table.
+	co2 := newCancelOrder(loC.ID(), AssetDCR, AssetBTC, 6)
+	if err = archie.NewEpochOrder(co2, 12, int64(EpochDuration)); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	if err = archie.FailCancelOrder(co2); err != nil {
+		t.Fatalf("FailCancelOrder error: %v", err)
+	}
+	status, ordType, _, err := archie.OrderStatus(co2)
+	if err != nil {
+		t.Fatalf("OrderStatus error: %v", err)
+	}
+	if status != order.OrderStatusExecuted || ordType != order.CancelOrderType {
+		t.Fatalf("wrong failed cancel status %v or type %v", status, ordType)
+	}
+}
+
+func TestFlushBook(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	sell := newLimitOrder(true, 4900000, 1, order.StandingTiF, 0)
+	buy := newLimitOrder(false, 4800000, 1, order.StandingTiF, 1)
+	epoch := newLimitOrder(false, 4800000, 1, order.StandingTiF, 2)
+	for _, lo := range []*order.LimitOrder{sell, buy} {
+		if err := archie.StoreOrder(lo, 10, int64(EpochDuration), order.OrderStatusBooked); err != nil {
+			t.Fatalf("StoreOrder error: %v", err)
+		}
+	}
+	if err := archie.NewEpochOrder(epoch, 11, int64(EpochDuration)); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+
+	sells, buys, err := archie.FlushBook(AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("FlushBook error: %v", err)
+	}
+	if len(sells) != 1 || sells[0] != sell.ID() || len(buys) != 1 || buys[0] != buy.ID() {
+		t.Fatalf("wrong orders flushed")
+	}
+
+	for _, lo := range []*order.LimitOrder{sell, buy} {
+		status, _, _, err := archie.OrderStatus(lo)
+		if err != nil {
+			t.Fatalf("OrderStatus error: %v", err)
+		}
+		if status != order.OrderStatusRevoked {
+			t.Fatalf("flushed order has status %v", status)
+		}
+	}
+	status, _, _, _ := archie.OrderStatus(epoch)
+	if status != order.OrderStatusEpoch {
+		t.Fatalf("epoch order has status %v after flush", status)
+	}
+
+	// Flushing is exempt from cancellation rate accounting.
+	oids, _, _, err := archie.ExecutedCancelsForUser(sell.User(), 10)
+	if err != nil {
+		t.Fatalf("ExecutedCancelsForUser error: %v", err)
+	}
+	if len(oids) != 0 {
+		t.Fatalf("flush revoke counted")
+	}
+}
+
+func TestPreimageStats(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	// One revealed, executed order and one revoked for a missed preimage.
+	loA, piA := newLimitOrderRevealed(false, 4900000, 1, order.StandingTiF, 0)
+	loB := newLimitOrder(false, 4900000, 1, order.StandingTiF, 1)
+	loB.AccountID = loA.AccountID
+	if err := archie.NewEpochOrder(loA, 10, int64(EpochDuration)); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	if err := archie.NewEpochOrder(loB, 11, int64(EpochDuration)); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	if err := archie.StorePreimage(loA, piA); err != nil {
+		t.Fatalf("StorePreimage error: %v", err)
+	}
+	if err := archie.ExecuteOrder(loA); err != nil {
+		t.Fatalf("ExecuteOrder error: %v", err)
+	}
+	if _, _, err := archie.RevokeOrderUncounted(loB); err != nil {
+		t.Fatalf("RevokeOrderUncounted error: %v", err)
+	}
+
+	outcomes, err := archie.PreimageStats(loA.User(), 10)
+	if err != nil {
+		t.Fatalf("PreimageStats error: %v", err)
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("expected 2 outcomes, got %d", len(outcomes))
+	}
+	if outcomes[0].ID != loB.ID() || !outcomes[0].Miss || outcomes[0].Time != 12*int64(EpochDuration) {
+		t.Fatalf("wrong outcome for the missed preimage: %+v", outcomes[0])
+	}
+	if outcomes[1].ID != loA.ID() || outcomes[1].Miss {
+		t.Fatalf("wrong outcome for the revealed preimage: %+v", outcomes[1])
+	}
+}
@@ -0,0 +1,263 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package sqlite implements an embedded server/db.DEXArchivist backed by a
+// SQLite database file. The tables and queries mirror those of the pg driver,
+// with each market's tables prefixed with the market's name rather than being
+// in a separate schema.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/db"
+	_ "modernc.org/sqlite" // the pure-Go "sqlite" sql driver
+)
+
+// Driver implements db.Driver.
+type Driver struct{}
+
+// Open creates the DB backend, returning a DEXArchivist.
+func (d *Driver) Open(ctx context.Context, cfg interface{}) (db.DEXArchivist, error) {
+	switch c := cfg.(type) {
+	case *Config:
+		return NewArchiver(ctx, c)
+	case Config:
+		return NewArchiver(ctx, &c)
+	default:
+		return nil, fmt.Errorf("invalid config type %t", cfg)
+	}
+}
+
+// UseLogger sets the package-wide logger for the registered DB Driver.
+func (*Driver) UseLogger(logger dex.Logger) {
+	UseLogger(logger)
+}
+
+func init() {
+	db.Register("sqlite", &Driver{})
+}
+
+const (
+	defaultQueryTimeout = 20 * time.Minute
+	// busyTimeout is how long a connection waits for another connection's
+	// write lock before failing with SQLITE_BUSY.
+	busyTimeout = 10 * time.Second
+)
+
+// Config holds the Archiver's configuration.
+type Config struct {
+	// Path is the path to the database file, which is created if it does not
+	// exist.
+	Path         string
+	QueryTimeout time.Duration
+
+	// MarketCfg specifies all of the markets that the Archiver should prepare.
+	MarketCfg []*dex.MarketInfo
+}
+
+// Archiver must implement server/db.DEXArchivist.
+type Archiver struct {
+	ctx          context.Context
+	queryTimeout time.Duration
+	db           *sql.DB
+
+	// markets is replaced rather than modified when a market is added, so
+	// that the map returned by marketMap may be used without locking.
+	marketsMtx sync.RWMutex
+	markets    map[string]*dex.MarketInfo
+
+	fatalMtx sync.RWMutex
+	fatal    chan struct{}
+	fatalErr error
+}
+
+var _ db.DEXArchivist = (*Archiver)(nil)
+
+// LastErr returns any fatal or unexpected error encountered in a recent query.
+// This may be used to check if the database had an unrecoverable error
+// (disk full, etc.).
+func (a *Archiver) LastErr() error {
+	a.fatalMtx.RLock()
+	defer a.fatalMtx.RUnlock()
+	return a.fatalErr
+}
+
+// Fatal returns a nil or closed channel for select use. Use LastErr to get the
+// latest fatal error.
+func (a *Archiver) Fatal() <-chan struct{} {
+	a.fatalMtx.RLock()
+	defer a.fatalMtx.RUnlock()
+	return a.fatal
+}
+
+func (a *Archiver) fatalBackendErr(err error) {
+	if err == nil {
+		return
+	}
+	a.fatalMtx.Lock()
+	if a.fatalErr == nil {
+		close(a.fatal)
+	}
+	a.fatalErr = err // consider slice and append
+	a.fatalMtx.Unlock()
+}
+
+// connect opens the SQLite database file. The caller is responsible for
+// calling Close() on the returned db when finished using it. Write-ahead
+// logging allows reads concurrent with a write, and transactions take the
+// write lock when they begin so that they wait on, rather than deadlock with,
+// another writer.
+func connect(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()))
+	params.Set("_txlock", "immediate")
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	// Establish a connection and verify it is alive.
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewArchiverForRead constructs a new Archiver without creating or modifying
+// any data structures. This should be used for read-only applications. Use
+// Close when done with the Archiver.
+func NewArchiverForRead(ctx context.Context, cfg *Config) (*Archiver, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("no database path")
+	}
+	db, err := connect(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	ver, err := retrieveSQLiteVersion(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	log.Infof("SQLite version %s, database file %s", ver, cfg.Path)
+
+	queryTimeout := cfg.QueryTimeout
+	if queryTimeout <= 0 {
+		queryTimeout = defaultQueryTimeout
+	}
+
+	mktMap := make(map[string]*dex.MarketInfo, len(cfg.MarketCfg))
+	for _, mkt := range cfg.MarketCfg {
+		mktMap[marketSchema(mkt.Name)] = mkt
+	}
+
+	return &Archiver{
+		ctx:          ctx,
+		db:           db,
+		queryTimeout: queryTimeout,
+		markets:      mktMap,
+		fatal:        make(chan struct{}),
+	}, nil
+}
+
+// NewArchiver constructs a new Archiver. All tables are created, including
+// tables for markets that may have been added since last startup. Use Close
+// when done with the Archiver.
+func NewArchiver(ctx context.Context, cfg *Config) (*Archiver, error) {
+	archiver, err := NewArchiverForRead(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure all tables required by the current market configuration are ready.
+	purgeMarkets, err := prepareTables(ctx, archiver.db, cfg.MarketCfg)
+	if err != nil {
+		archiver.Close()
+		return nil, err
+	}
+	for _, staleMarket := range purgeMarkets {
+		mkt := archiver.markets[staleMarket]
+		if mkt == nil { // shouldn't happen
+			archiver.Close()
+			return nil, fmt.Errorf("unrecognized market %v", staleMarket)
+		}
+		unbookedSells, unbookedBuys, err := archiver.FlushBook(mkt.Base, mkt.Quote)
+		if err != nil {
+			archiver.Close()
+			return nil, fmt.Errorf("failed to flush book for market %v: %w", staleMarket, err)
+		}
+		log.Infof("Flushed %d sell orders and %d buy orders from market %v with a changed lot size.",
+			len(unbookedSells), len(unbookedBuys), staleMarket)
+	}
+
+	return archiver, nil
+}
+
+// marketMap returns the markets supported by the Archiver, keyed by schema
+// name. The map must not be modified.
+func (a *Archiver) marketMap() map[string]*dex.MarketInfo {
+	a.marketsMtx.RLock()
+	defer a.marketsMtx.RUnlock()
+	return a.markets
+}
+
+// AddMarket prepares the tables for a new market, or updates the stored
+// configuration of an existing market. If the lot size of an existing market
+// is changed, its book is flushed.
+func (a *Archiver) AddMarket(mkt *dex.MarketInfo) error {
+	purgeMarkets, err := prepareMarkets(a.db, []*dex.MarketInfo{mkt})
+	if err != nil {
+		return err
+	}
+
+	a.marketsMtx.Lock()
+	markets := make(map[string]*dex.MarketInfo, len(a.markets)+1)
+	for schema, mktInfo := range a.markets {
+		markets[schema] = mktInfo
+	}
+	markets[marketSchema(mkt.Name)] = mkt
+	a.markets = markets
+	a.marketsMtx.Unlock()
+
+	if len(purgeMarkets) > 0 {
+		unbookedSells, unbookedBuys, err := a.FlushBook(mkt.Base, mkt.Quote)
+		if err != nil {
+			return fmt.Errorf("failed to flush book for market %v: %w", mkt.Name, err)
+		}
+		log.Infof("Flushed %d sell orders and %d buy orders from market %v with a changed lot size.",
+			len(unbookedSells), len(unbookedBuys), mkt.Name)
+	}
+	return nil
+}
+
+// Close closes the underlying DB connection.
+func (a *Archiver) Close() error {
+	return a.db.Close()
+}
+
+func (a *Archiver) marketSchema(base, quote uint32) (string, error) {
+	marketName, err := dex.MarketName(base, quote)
+	if err != nil {
+		return "", err
+	}
+	schema := marketSchema(marketName)
+	_, found := a.marketMap()[schema]
+	if !found {
+		return "", db.ArchiveError{
+			Code:   db.ErrUnsupportedMarket,
+			Detail: fmt.Sprintf(`archiver does not support the market "%s"`, schema),
+		}
+	}
+	return schema, nil
+}
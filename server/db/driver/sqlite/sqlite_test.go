@@ -0,0 +1,126 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/db"
+)
+
+func TestMain(m *testing.M) {
+	startLogger()
+	mkts := mktConfig()
+	AssetDCR, AssetBTC = mkts[0].Base, mkts[0].Quote
+	AssetLTC = mkts[1].Quote
+	os.Exit(m.Run())
+}
+
+func TestOpen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "dcrdex.db")
+
+	drv, err := db.Open(context.Background(), "sqlite", &Config{
+		Path:      dbPath,
+		MarketCfg: mktConfig(),
+	})
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	archie := drv.(*Archiver)
+
+	ver, err := DBVersion(archie.db)
+	if err != nil {
+		t.Fatalf("DBVersion error: %v", err)
+	}
+	if ver != dbVersion {
+		t.Fatalf("wrong DB version %d, wanted %d", ver, dbVersion)
+	}
+
+	for _, mkt := range mktConfig() {
+		for _, tbl := range createMarketTableStatements {
+			tableName := marketTableName(marketSchema(mkt.Name), tbl.name)
+			exists, err := tableExists(archie.db, tableName)
+			if err != nil {
+				t.Fatalf("tableExists error: %v", err)
+			}
+			if !exists {
+				t.Errorf("table %s not created", tableName)
+			}
+		}
+	}
+
+	var errA db.ArchiveError
+	if _, _, err = archie.Order(order.OrderID{}, AssetDCR, AssetLTC); !errors.As(err, &errA) || errA.Code != db.ErrUnsupportedMarket {
+		t.Fatalf("expected an unsupported market error, got %v", err)
+	}
+
+	if err = archie.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	// Book an order, then reopen with a different lot size for the DCR-BTC
+	// market, which should flush the book.
+	archie = newTestArchiver(t, dbPath)
+	lo := newLimitOrder(false, 4900000, 1, order.StandingTiF, 0)
+	if err = archie.StoreOrder(lo, 1, int64(EpochDuration), order.OrderStatusBooked); err != nil {
+		t.Fatalf("StoreOrder error: %v", err)
+	}
+	archie.Close()
+
+	mkts := mktConfig()
+	mkts[0].LotSize *= 10
+	archie, err = NewArchiver(context.Background(), &Config{
+		Path:      dbPath,
+		MarketCfg: mkts,
+	})
+	if err != nil {
+		t.Fatalf("NewArchiver error: %v", err)
+	}
+	defer archie.Close()
+
+	status, _, _, err := archie.OrderStatus(lo)
+	if err != nil {
+		t.Fatalf("OrderStatus error: %v", err)
+	}
+	if status != order.OrderStatusRevoked {
+		t.Fatalf("booked order not revoked after lot size change, status %v", status)
+	}
+	bookOrders, err := archie.BookOrders(AssetDCR, AssetBTC)
+	if err != nil {
+		t.Fatalf("BookOrders error: %v", err)
+	}
+	if len(bookOrders) != 0 {
+		t.Fatalf("%d book orders after lot size change", len(bookOrders))
+	}
+}
+
+func TestAddMarket(t *testing.T) {
+	archie := newTestArchiver(t, filepath.Join(t.TempDir(), "dcrdex.db"))
+
+	mkt, err := dex.NewMarketInfoFromSymbols("DCR", "LTC", LotSize, RateStep, EpochDuration, MarketBuyBuffer)
+	if err != nil {
+		t.Fatalf("NewMarketInfoFromSymbols error: %v", err)
+	}
+	if err = archie.AddMarket(mkt); err != nil {
+		t.Fatalf("AddMarket error: %v", err)
+	}
+
+	lo := newLimitOrderWithAssets(true, 4900000, 1, order.StandingTiF, 0, AssetDCR, AssetLTC)
+	if err = archie.NewEpochOrder(lo, 1, int64(EpochDuration)); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	ords, err := archie.EpochOrders(AssetDCR, AssetLTC)
+	if err != nil {
+		t.Fatalf("EpochOrders error: %v", err)
+	}
+	if len(ords) != 1 || ords[0].ID() != lo.ID() {
+		t.Fatalf("wrong epoch orders loaded: %v", ords)
+	}
+}
@@ -0,0 +1,101 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sqlExecutor is implemented by both sql.DB and sql.Tx.
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+type sqlQueryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type sqlQueryExecutor interface {
+	sqlQueryer
+	sqlExecutor
+}
+
+// sqlExec executes the SQL statement string with any optional arguments, and
+// returns the number of rows affected.
+func sqlExec(db sqlExecutor, stmt string, args ...interface{}) (int64, error) {
+	res, err := db.Exec(stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	var N int64
+	N, err = res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf(`error in RowsAffected: %w`, err)
+	}
+	return N, err
+}
+
+// tableExists checks if the specified table exists.
+func tableExists(db sqlQueryer, tableName string) (bool, error) {
+	rows, err := db.Query(`SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?1;`,
+		tableName)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+// columnExists checks if the table has the specified column.
+func columnExists(db sqlQueryer, table, col string) (bool, error) {
+	rows, err := db.Query(`SELECT 1 FROM pragma_table_info(?1) WHERE name = ?2;`,
+		table, col)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+// createTableStmt creates a table with the given name using the provided SQL
+// statement, if it does not already exist.
+func createTableStmt(db sqlQueryExecutor, fmtStmt, tableName string) (bool, error) {
+	exists, err := tableExists(db, tableName)
+	if err != nil {
+		return false, err
+	}
+
+	var created bool
+	if !exists {
+		stmt := fmt.Sprintf(fmtStmt, tableName)
+		log.Debugf("Creating the %q table.", tableName)
+		_, err = db.Exec(stmt)
+		if err != nil {
+			return false, err
+		}
+		created = true
+	}
+
+	return created, nil
+}
+
+// retrieveSQLiteVersion retrieves the version of the linked SQLite library.
+func retrieveSQLiteVersion(db *sql.DB) (ver string, err error) {
+	err = db.QueryRow(`SELECT sqlite_version();`).Scan(&ver)
+	return
+}
+
+// placeholders creates a comma-separated list of n numbered parameters,
+// beginning with ?start, for use in an IN list.
+func placeholders(start, n int) string {
+	params := make([]string, 0, n)
+	for i := start; i < start+n; i++ {
+		params = append(params, "?"+strconv.Itoa(i))
+	}
+	return strings.Join(params, ",")
+}
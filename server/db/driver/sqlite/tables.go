@@ -0,0 +1,256 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/db/driver/sqlite/internal"
+)
+
+const (
+	marketsTableName  = "markets"
+	metaTableName     = "meta"
+	feeKeysTableName  = "fee_keys"
+	accountsTableName = "accounts"
+	bondsTableName    = "bonds"
+
+	// market tables, prefixed with the market's schema name
+	matchesTableName          = "matches"
+	epochsTableName           = "epochs"
+	ordersArchivedTableName   = "orders_archived"
+	ordersActiveTableName     = "orders_active"
+	cancelsArchivedTableName  = "cancels_archived"
+	cancelsActiveTableName    = "cancels_active"
+	epochReportsTableName     = "epoch_reports"
+	epochTranscriptsTableName = "epoch_transcripts"
+)
+
+type tableStmt struct {
+	name string
+	stmt string
+	// index is an optional statement with a %[1]s specifier for the table
+	// name that creates the table's indexes.
+	index string
+}
+
+var createDEXTableStatements = []tableStmt{
+	{marketsTableName, internal.CreateMarketsTable, ""},
+	{metaTableName, internal.CreateMetaTable, ""},
+}
+
+var createAccountTableStatements = []tableStmt{
+	{feeKeysTableName, internal.CreateFeeKeysTable, ""},
+	{accountsTableName, internal.CreateAccountsTable, ""},
+	{bondsTableName, internal.CreateBondsTable, internal.CreateBondsAccountIndex},
+}
+
+var createMarketTableStatements = []tableStmt{
+	{ordersArchivedTableName, internal.CreateOrdersTable, internal.CreateAccountIndex},
+	{ordersActiveTableName, internal.CreateOrdersTable, internal.CreateAccountIndex},
+	{cancelsArchivedTableName, internal.CreateCancelOrdersTable, internal.CreateAccountIndex},
+	{cancelsActiveTableName, internal.CreateCancelOrdersTable, internal.CreateAccountIndex},
+	{matchesTableName, internal.CreateMatchesTable, internal.CreateMatchesIndexes}, // just one matches table per market for now
+	{epochsTableName, internal.CreateEpochsTable, ""},
+	{epochReportsTableName, internal.CreateEpochReportTable, ""},
+	{epochTranscriptsTableName, internal.CreateEpochTranscriptsTable, ""},
+}
+
+var tableMap = func() map[string]tableStmt {
+	m := make(map[string]tableStmt, len(createDEXTableStatements)+
+		len(createMarketTableStatements)+len(createAccountTableStatements))
+	for _, tbl := range createDEXTableStatements {
+		m[tbl.name] = tbl
+	}
+	for _, tbl := range createMarketTableStatements {
+		m[tbl.name] = tbl
+	}
+	for _, tbl := range createAccountTableStatements {
+		m[tbl.name] = tbl
+	}
+	return m
+}()
+
+// marketTableName is the name of one of a market's tables. SQLite has no
+// schemas, so the market's schema name is used as a table name prefix.
+func marketTableName(marketSchema, tableName string) string {
+	return marketSchema + "_" + tableName
+}
+
+func fullOrderTableName(marketSchema string, active bool) string {
+	var orderTable string
+	if active {
+		orderTable = ordersActiveTableName
+	} else {
+		orderTable = ordersArchivedTableName
+	}
+	return marketTableName(marketSchema, orderTable)
+}
+
+func fullCancelOrderTableName(marketSchema string, active bool) string {
+	var orderTable string
+	if active {
+		orderTable = cancelsActiveTableName
+	} else {
+		orderTable = cancelsArchivedTableName
+	}
+	return marketTableName(marketSchema, orderTable)
+}
+
+func fullMatchesTableName(marketSchema string) string {
+	return marketTableName(marketSchema, matchesTableName)
+}
+
+func fullEpochsTableName(marketSchema string) string {
+	return marketTableName(marketSchema, epochsTableName)
+}
+
+func fullEpochReportsTableName(marketSchema string) string {
+	return marketTableName(marketSchema, epochReportsTableName)
+}
+
+func fullEpochTranscriptsTableName(marketSchema string) string {
+	return marketTableName(marketSchema, epochTranscriptsTableName)
+}
+
+// createTable creates one of the known tables by name, along with any of its
+// indexes. If marketSchema is not empty, the table is a market table, and the
+// name is prefixed with the market's schema name.
+func createTable(db sqlQueryExecutor, marketSchema, tableName string) (bool, error) {
+	tbl, tableNameFound := tableMap[tableName]
+	if !tableNameFound {
+		return false, fmt.Errorf("table name %q unknown", tableName)
+	}
+	if marketSchema != "" {
+		tableName = marketTableName(marketSchema, tableName)
+	}
+	created, err := createTableStmt(db, tbl.stmt, tableName)
+	if err != nil {
+		return false, err
+	}
+	if tbl.index != "" {
+		if _, err = db.Exec(fmt.Sprintf(tbl.index, tableName)); err != nil {
+			return false, fmt.Errorf("failed to create indexes for table %q: %w", tableName, err)
+		}
+	}
+	return created, nil
+}
+
+// prepareTables ensures that all tables required by the DEX market config,
+// mktConfig, are ready. This also runs any required DB scheme upgrades. The
+// Context allows safely canceling upgrades, which may be long running. Returns
+// a slice of markets that should have orders flushed due to lot size changes.
+func prepareTables(ctx context.Context, db *sql.DB, mktConfig []*dex.MarketInfo) ([]string, error) {
+	// Create the markets table.
+	created, err := createTable(db, "", marketsTableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create markets table: %w", err)
+	}
+	if created { // Fresh install
+		// Create the meta table.
+		created, err = createTable(db, "", metaTableName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create meta table: %w", err)
+		}
+		if !created {
+			return nil, fmt.Errorf("existing meta table but no markets table: corrupt DB")
+		}
+		_, err = db.Exec(internal.CreateMetaRow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create row for meta table: %w", err)
+		}
+		err = setDBVersion(db, dbVersion) // no upgrades
+		if err != nil {
+			return nil, fmt.Errorf("failed to set db version in meta table: %w", err)
+		}
+		log.Infof("Created new meta table at version %d", dbVersion)
+
+		// Prepare the account and registration key counter tables.
+		err = createAccountTables(db)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Attempt upgrade.
+		if err = upgradeDB(ctx, db); err != nil {
+			// If the context is canceled, it will either be context.Canceled
+			// from db.BeginTx, or sql.ErrTxDone from any of the tx operations.
+			if errors.Is(err, context.Canceled) || errors.Is(err, sql.ErrTxDone) {
+				return nil, fmt.Errorf("upgrade DB canceled: %w", err)
+			}
+			return nil, fmt.Errorf("upgrade DB failed: %w", err)
+		}
+	}
+
+	// Verify config of existing markets, creating tables for new markets.
+	// This is done after upgrades since it can create new tables with the
+	// current DB scheme for newly configured markets.
+	log.Infof("Configuring %d markets tables: %v", len(mktConfig), mktConfig)
+	return prepareMarkets(db, mktConfig)
+}
+
+// prepareMarkets ensures that the market-specific tables required by the DEX
+// market config, mktConfig, are ready. See also prepareTables.
+func prepareMarkets(db *sql.DB, mktConfig []*dex.MarketInfo) ([]string, error) {
+	// Load existing markets and ensure there aren't multiple with the same ID.
+	mkts, err := loadMarkets(db, marketsTableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read markets table: %w", err)
+	}
+	marketMap := make(map[string]*dex.MarketInfo, len(mkts))
+	for _, mkt := range mkts {
+		if _, found := marketMap[mkt.Name]; found {
+			// should never happen since market name is (unique) primary key
+			panic(fmt.Sprintf(`multiple markets with the same name "%s" found!`,
+				mkt.Name))
+		}
+		marketMap[mkt.Name] = mkt
+	}
+
+	var purgeMarkets []string
+	// Create any markets in the config that do not already exist. Also create
+	// any missing tables for existing markets.
+	for _, mkt := range mktConfig {
+		existingMkt := marketMap[mkt.Name]
+		if existingMkt == nil {
+			log.Infof("New market specified in config: %s", mkt.Name)
+			err = newMarket(db, marketsTableName, mkt)
+			if err != nil {
+				return nil, fmt.Errorf("newMarket failed: %w", err)
+			}
+		} else {
+			if mkt.LotSize != existingMkt.LotSize {
+				err = updateLotSize(db, mkt.Name, mkt.LotSize)
+				if err != nil {
+					return nil, fmt.Errorf("unable to update lot size for %s: %w", mkt.Name, err)
+				}
+				purgeMarkets = append(purgeMarkets, marketSchema(mkt.Name))
+			}
+		}
+
+		// Create the market's tables.
+		err = createMarketTables(db, mkt.Name)
+		if err != nil {
+			return nil, fmt.Errorf("createMarketTables failed: %w", err)
+		}
+	}
+
+	return purgeMarkets, nil
+}
+
+// updateLotSize updates the lot size for a market. Must only be called on an
+// existing market.
+func updateLotSize(db sqlExecutor, mktName string, lotSize uint64) error {
+	stmt := fmt.Sprintf(internal.UpdateLotSize, marketsTableName)
+	_, err := db.Exec(stmt, mktName, lotSize)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Updated %s lot size to %d.", mktName, lotSize)
+	return nil
+}
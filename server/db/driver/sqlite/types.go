@@ -0,0 +1,51 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// fastUint64 provides an efficient Scanner for 64-bit integer data. With a
+// regular uint64 or any other integer type that itself does not implement
+// Scanner, the database/sql.Scan implementation will coerce type by converting
+// to and from a string, so we avoid this expensive operation by implementing a
+// Scanner that uses a type assertion. The sqlite driver returns all INTEGER
+// column values as int64.
+type fastUint64 uint64
+
+func (n *fastUint64) Scan(value interface{}) error {
+	if value == nil {
+		*n = 0
+		return fmt.Errorf("NULL not supported")
+	}
+
+	v, ok := value.(int64)
+	if !ok {
+		return fmt.Errorf("not a signed 64-bit integer: %T", value)
+	}
+	*n = fastUint64(v)
+	return nil
+}
+
+// msTime is a time.Time that is stored as an INTEGER of UNIX milliseconds,
+// which is the precision of order times.
+type msTime time.Time
+
+// Value implements the sql/driver.Valuer interface.
+func (t msTime) Value() (driver.Value, error) {
+	return time.Time(t).UnixMilli(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (t *msTime) Scan(value interface{}) error {
+	ms, ok := value.(int64)
+	if !ok {
+		return fmt.Errorf("not a signed 64-bit integer: %T", value)
+	}
+	*t = msTime(time.UnixMilli(ms).UTC())
+	return nil
+}
@@ -0,0 +1,131 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"decred.org/dcrdex/server/db/driver/sqlite/internal"
+)
+
+// dbVersion is the current version of the SQLite DB scheme. It is independent
+// of the pg driver's version since the SQLite scheme began with all of the pg
+// upgrades applied.
+const dbVersion = 0
+
+// The number of upgrades defined MUST be equal to dbVersion. An upgrade to
+// version N is upgrades[N-1], and is run in a transaction that also sets the
+// new version in the meta table.
+var upgrades = []func(db *sql.Tx) error{}
+
+// DBVersion retrieves the database version from the meta table.
+func DBVersion(db *sql.DB) (ver uint32, err error) {
+	err = db.QueryRow(internal.SelectDBVersion).Scan(&ver)
+	return
+}
+
+func setDBVersion(db sqlExecutor, ver uint32) error {
+	res, err := db.Exec(internal.SetDBVersion, ver)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return fmt.Errorf("set the DB version in %d rows instead of 1", n)
+	}
+	return nil
+}
+
+func upgradeDB(ctx context.Context, db *sql.DB) error {
+	return upgradeDBWith(ctx, db, upgrades)
+}
+
+// upgradeDBWith runs the upgrades required to bring the DB from its current
+// version to len(ups).
+func upgradeDBWith(ctx context.Context, db *sql.DB, ups []func(db *sql.Tx) error) error {
+	// Unlike the pg driver, there is no pre-versioning scheme, so the meta
+	// table with the schema_version column must exist.
+	found, err := columnExists(db, metaTableName, "schema_version")
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no schema_version in the %q table: corrupt DB", metaTableName)
+	}
+	current, err := DBVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to get DB version: %w", err)
+	}
+
+	targetVersion := uint32(len(ups))
+	if current == targetVersion {
+		log.Infof("DCRDEX database ready at version %d", targetVersion)
+		return nil // all upgraded
+	}
+
+	if current > targetVersion {
+		return fmt.Errorf("current DB version %d is newer than highest recognized version %d",
+			current, targetVersion)
+	}
+
+	runUpgradeTx := func(targetVer uint32, up func(db *sql.Tx) error) error {
+		// Canceling the context automatically rolls back the transaction.
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			// On error, rollback the transaction unless ctx was canceled
+			// (sql.ErrTxDone) because then rollback is automatic. See the
+			// (*sql.DB).BeginTx docs.
+			if err == nil || errors.Is(err, sql.ErrTxDone) {
+				return
+			}
+			log.Warnf("Rolling back upgrade to version %d", targetVer-1)
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				log.Errorf("Rollback failed: %v", errRollback)
+			}
+		}()
+
+		if err = up(tx); err != nil {
+			return fmt.Errorf("failed to upgrade to db version %d: %w", targetVer, err)
+		}
+
+		if err = setDBVersion(tx, targetVer); err != nil {
+			return fmt.Errorf("failed to set new DB version %d: %w", targetVer, err)
+		}
+
+		err = tx.Commit() // for the defer
+		return err
+	}
+
+	log.Infof("Upgrading DB scheme from %d to %d", current, targetVersion)
+	for i, up := range ups[current:] {
+		targetVer := current + uint32(i) + 1
+		log.Debugf("Upgrading DB scheme to %d...", targetVer)
+		if err = runUpgradeTx(targetVer, up); err != nil {
+			if errors.Is(err, sql.ErrTxDone) {
+				return fmt.Errorf("upgrade cancelled (rolled back to version %d)", current+uint32(i))
+			}
+			return err
+		}
+	}
+
+	current, err = DBVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to get DB version: %w", err)
+	}
+	log.Infof("Upgrades complete. DB is at version %d", current)
+
+	return nil
+}
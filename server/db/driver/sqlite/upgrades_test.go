@@ -0,0 +1,67 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_upgradeDB(t *testing.T) {
+	if len(upgrades) != dbVersion {
+		t.Fatalf("%d upgrades defined for DB version %d", len(upgrades), dbVersion)
+	}
+
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "dcrdex.db")
+	archie := newTestArchiver(t, dbPath)
+
+	// Two test upgrades, the second of which fails after the first is
+	// committed.
+	errUpgrade := errors.New("upgrade failed")
+	addColumn := func(tx *sql.Tx) error {
+		_, err := tx.Exec(`ALTER TABLE ` + marketsTableName + ` ADD COLUMN test_col INTEGER DEFAULT 0;`)
+		return err
+	}
+	failUpgrade := func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`ALTER TABLE ` + marketsTableName + ` ADD COLUMN test_col2 INTEGER;`); err != nil {
+			return err
+		}
+		return errUpgrade
+	}
+	ups := append(append([]func(*sql.Tx) error{}, upgrades...), addColumn, failUpgrade)
+
+	err := upgradeDBWith(ctx, archie.db, ups)
+	if !errors.Is(err, errUpgrade) {
+		t.Fatalf("expected the failed upgrade error, got %v", err)
+	}
+
+	ver, err := DBVersion(archie.db)
+	if err != nil {
+		t.Fatalf("DBVersion error: %v", err)
+	}
+	if ver != dbVersion+1 {
+		t.Fatalf("DB version %d after failed upgrade, expected %d", ver, dbVersion+1)
+	}
+	for col, expExists := range map[string]bool{"test_col": true, "test_col2": false} {
+		exists, err := columnExists(archie.db, marketsTableName, col)
+		if err != nil {
+			t.Fatalf("columnExists error: %v", err)
+		}
+		if exists != expExists {
+			t.Fatalf("column %s exists = %v, expected %v", col, exists, expExists)
+		}
+	}
+
+	// The DB version is now newer than the known version.
+	archie.Close()
+	_, err = NewArchiver(ctx, &Config{Path: dbPath, MarketCfg: mktConfig()})
+	if err == nil || !strings.Contains(err.Error(), "newer than highest recognized version") {
+		t.Fatalf("expected a newer DB version error, got %v", err)
+	}
+}
@@ -59,6 +59,7 @@ const (
 	ErrAccountUnknown
 	ErrAccountBadFeeInfo
 	ErrUnknownFeeKey
+	ErrUnknownEpoch
 )
 
 func (ae ArchiveError) Error() string {
@@ -90,6 +91,8 @@ func (ae ArchiveError) Error() string {
 		desc = "mismatching fee address or asset"
 	case ErrUnknownFeeKey:
 		desc = "unknown fee key"
+	case ErrUnknownEpoch:
+		desc = "unknown epoch"
 	}
 
 	if ae.Detail == "" {
@@ -130,6 +133,13 @@ func IsErrMatchUnknown(err error) bool {
 	return errors.As(err, &errA) && errA.Code == ErrUnknownMatch
 }
 
+// IsErrEpochUnknown returns true if the error is of type ArchiveError and has
+// code ErrUnknownEpoch.
+func IsErrEpochUnknown(err error) bool {
+	var errA ArchiveError
+	return errors.As(err, &errA) && errA.Code == ErrUnknownEpoch
+}
+
 // IsErrMatchUnsupported returns true if the error is of type ArchiveError and
 // has code ErrUnsupportedMarket.
 func IsErrMatchUnsupported(err error) bool {
@@ -37,6 +37,10 @@ type EpochResults struct {
 	LowRate           uint64
 	StartRate         uint64
 	EndRate           uint64
+	// Transcript is the record of the epoch's matching, with which the
+	// shuffle and matching may be independently verified. Transcript is nil
+	// for an epoch with no orders.
+	Transcript *order.EpochTranscript
 }
 
 // OrderStatus is the current status of an order.
@@ -81,6 +85,16 @@ type DEXArchivist interface {
 	// LoadEpochStats reads all market epoch history from the database.
 	LoadEpochStats(uint32, uint32, []*candles.Cache) error
 
+	// EpochTranscript retrieves the transcript of a market's epoch. An
+	// ArchiveError with code ErrUnknownEpoch is returned if there is no
+	// transcript for the epoch.
+	EpochTranscript(base, quote uint32, epochIdx, epochDur int64) (*order.EpochTranscript, error)
+
+	// AddMarket prepares storage for a new market, or updates the stored
+	// configuration of an existing market. Changing the lot size of an
+	// existing market flushes its book.
+	AddMarket(mkt *dex.MarketInfo) error
+
 	OrderArchiver
 	AccountArchiver
 	KeyIndexer
@@ -170,6 +184,12 @@ type OrderArchiver interface {
 	// "revoked", and RevokeOrder should be used to set this status.
 	CancelOrder(*order.LimitOrder) error
 
+	// ExpireOrder puts a good-til-time limit order into the expired state.
+	// Unlike RevokeOrder, no cancel order is generated since the order was
+	// removed from the book as requested by the user when the order was
+	// placed.
+	ExpireOrder(*order.LimitOrder) error
+
 	// RevokeOrder puts an order into the revoked state, and generates a cancel
 	// order to record the action. Orders should be revoked by the DEX according
 	// to policy on failed orders. For canceling an order that was matched with
@@ -237,6 +257,17 @@ type AccountArchiver interface {
 
 	// AccountInfo returns data for an account.
 	AccountInfo(account.AccountID) (*Account, error)
+
+	// CreateAccountWithBond creates a new account that is funded with a
+	// fidelity bond instead of a registration fee payment.
+	CreateAccountWithBond(acct *account.Account, bond *Bond) error
+
+	// AddBond stores a new fidelity bond for an existing account.
+	AddBond(aid account.AccountID, bond *Bond) error
+
+	// Bonds retrieves the account's bonds with lock times at or after
+	// lockTimeThresh, sorted by lock time.
+	Bonds(aid account.AccountID, lockTimeThresh time.Time) ([]*Bond, error)
 }
 
 // MatchData represents an order pair match, but with just the order IDs instead
@@ -359,6 +390,7 @@ type MatchArchiver interface {
 	AllActiveUserMatches(aid account.AccountID) ([]*MatchData, error)
 	MarketMatches(base, quote uint32) ([]*MatchDataWithCoins, error)
 	MarketMatchesStreaming(base, quote uint32, includeInactive bool, N int64, f func(*MatchDataWithCoins) error) (int, error)
+	MarketTrades(base, quote uint32, before *order.MatchID, N int) ([]*MatchDataWithCoins, error)
 	MatchStatuses(aid account.AccountID, base, quote uint32, matchIDs []order.MatchID) ([]*MatchStatus, error)
 }
 
@@ -8,6 +8,16 @@ import (
 	"decred.org/dcrdex/server/account"
 )
 
+// Bond represents a time-locked fidelity bond posted by an account.
+type Bond struct {
+	Version  uint16
+	AssetID  uint32
+	CoinID   []byte
+	Amount   int64
+	Strength uint32 // Amount divided by the asset's bond increment
+	LockTime int64  // UNIX time in seconds
+}
+
 // Account holds data returned by Accounts.
 type Account struct {
 	AccountID  account.AccountID `json:"accountid"`
@@ -24,6 +24,7 @@ import (
 	"decred.org/dcrdex/server/comms"
 	"decred.org/dcrdex/server/db"
 	"decred.org/dcrdex/server/db/driver/pg"
+	"decred.org/dcrdex/server/db/driver/sqlite"
 	"decred.org/dcrdex/server/market"
 	"decred.org/dcrdex/server/swap"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
@@ -52,10 +53,27 @@ type AssetConf struct {
 	RegFee      uint64 `json:"regFee,omitempty"`
 	RegConfs    uint32 `json:"regConfs,omitempty"`
 	RegXPub     string `json:"regXPub,omitempty"`
+	BondAmt     uint64 `json:"bondAmt,omitempty"`
+	BondConfs   uint32 `json:"bondConfs,omitempty"`
+	// FeeRateFloor is a fee rate to use if the backend's fee rate estimators
+	// fail, and the minimum fee rate. Zero means no fallback rate.
+	FeeRateFloor uint64 `json:"feeRateFloor,omitempty"`
+	// FeeRateHalfLife is the half-life, in seconds, with which decreases in
+	// the estimated fee rate are smoothed. Zero disables smoothing.
+	FeeRateHalfLife uint32 `json:"feeRateHalfLife,omitempty"`
+	// FailoverConfigPaths are the config paths for additional nodes that the
+	// backend fails over to, in order of preference, when the node configured
+	// by ConfigPath is unhealthy.
+	FailoverConfigPaths []string `json:"failoverConfigPaths,omitempty"`
 }
 
 // DBConf groups the database configuration parameters.
 type DBConf struct {
+	// Driver is the DB driver, either "pg" or "sqlite". The default is "pg".
+	Driver string
+	// SQLitePath is the path of the DB file for the "sqlite" driver.
+	SQLitePath string
+
 	DBName       string
 	User         string
 	Pass         string
@@ -67,6 +85,15 @@ type DBConf struct {
 // RPCConfig is an alias for the comms Server's RPC config struct.
 type RPCConfig = comms.RPCConfig
 
+// MatchOutcome is an alias for the AuthManager's scored match outcome.
+type MatchOutcome = auth.MatchOutcome
+
+// PreimageOutcome is an alias for the AuthManager's scored preimage outcome.
+type PreimageOutcome = auth.PreimageOutcome
+
+// RateTier is an alias for the comms Server's account rate tier.
+type RateTier = comms.RateTier
+
 // DexConf is the configuration data required to create a new DEX.
 type DexConf struct {
 	DataDir           string
@@ -85,6 +112,11 @@ type DexConf struct {
 	DEXPrivKey        *secp256k1.PrivateKey
 	CommsCfg          *RPCConfig
 	NoResumeSwaps     bool
+	BondExpiry        time.Duration
+	// SaveMarkets, if set, is called with the full set of markets whenever
+	// markets are added, retired, or reconfigured, so that the changes survive
+	// a restart.
+	SaveMarkets func([]*dex.MarketInfo) error
 }
 
 type signer struct {
@@ -116,29 +148,39 @@ func (ss *subsystem) stop() {
 // components of the DEX.
 type DEX struct {
 	network     dex.Network
-	markets     map[string]*market.Market
 	assets      map[uint32]*swap.SwapperAsset
 	storage     db.DEXArchivist
 	authMgr     *auth.AuthManager
 	swapper     *swap.Swapper
+	feeMgr      *FeeManager
+	coinLocker  *coinlock.DEXCoinLocker
+	dataAPI     *apidata.DataAPI
+	balancer    *market.DEXBalancer
 	orderRouter *market.OrderRouter
 	bookRouter  *market.BookRouter
-	subsystems  []subsystem
 	server      *comms.Server
+	saveMarkets func([]*dex.MarketInfo) error
+
+	// marketsMtx guards the markets map and the subsystems slice, and
+	// serializes operations that start, stop, add, or remove markets.
+	marketsMtx sync.RWMutex
+	markets    map[string]*market.Market
+	subsystems []subsystem
 
 	configRespMtx sync.RWMutex
 	configResp    *configResponse
 }
 
-// configResponse is defined here to leave open the possibility for hot
-// adjustable parameters while storing a pre-encoded config response message. An
-// update method will need to be defined in the future for this purpose.
+// configResponse stores a pre-encoded config response message along with the
+// ConfigResult it was encoded from. The update methods modify the ConfigResult
+// and re-encode it.
 type configResponse struct {
-	configMsg *msgjson.ConfigResult // constant for now
+	configMsg *msgjson.ConfigResult
 	configEnc json.RawMessage
 }
 
-func newConfigResponse(cfg *DexConf, regAssets map[string]*msgjson.FeeAsset, cfgAssets []*msgjson.Asset, cfgMarkets []*msgjson.Market) (*configResponse, error) {
+func newConfigResponse(cfg *DexConf, regAssets map[string]*msgjson.FeeAsset, bondAssets map[string]*msgjson.BondAsset,
+	cfgAssets []*msgjson.Asset, cfgMarkets []*msgjson.Market) (*configResponse, error) {
 	dcrAsset := regAssets["dcr"]
 	if dcrAsset == nil {
 		return nil, fmt.Errorf("DCR is required as a fee asset for backward compatibility")
@@ -156,6 +198,14 @@ func newConfigResponse(cfg *DexConf, regAssets map[string]*msgjson.FeeAsset, cfg
 		DEXPubKey:        cfg.DEXPrivKey.PubKey().SerializeCompressed(),
 		RegFees:          regAssets,
 	}
+	if len(bondAssets) > 0 {
+		configMsg.BondAssets = bondAssets
+		bondExpiry := cfg.BondExpiry
+		if bondExpiry == 0 {
+			bondExpiry = auth.DefaultBondExpiry
+		}
+		configMsg.BondExpiry = uint64(bondExpiry.Seconds())
+	}
 
 	// NOTE/TODO: To include active epoch in the market status objects, we need
 	// a channel from Market to push status changes back to DEX manager.
@@ -199,6 +249,71 @@ func (cr *configResponse) setMktResume(name string, startEpoch uint64) (epochLen
 	return 0
 }
 
+// setMarket adds the market to the config, replacing any existing market with
+// the same name.
+func (cr *configResponse) setMarket(mkt *msgjson.Market) {
+	var replaced bool
+	for i, m := range cr.configMsg.Markets {
+		if m.Name == mkt.Name {
+			cr.configMsg.Markets[i] = mkt
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		cr.configMsg.Markets = append(cr.configMsg.Markets, mkt)
+	}
+	cr.setAssetMarketParams()
+	cr.remarshal()
+}
+
+// removeMarket removes the named market from the config.
+func (cr *configResponse) removeMarket(name string) {
+	mkts := make([]*msgjson.Market, 0, len(cr.configMsg.Markets))
+	for _, m := range cr.configMsg.Markets {
+		if m.Name != name {
+			mkts = append(mkts, m)
+		}
+	}
+	cr.configMsg.Markets = mkts
+	cr.setAssetMarketParams()
+	cr.remarshal()
+}
+
+// marketStatus returns the MarketStatus of the named market in the config.
+func (cr *configResponse) marketStatus(name string) (status msgjson.MarketStatus, found bool) {
+	for _, m := range cr.configMsg.Markets {
+		if m.Name == name {
+			return m.MarketStatus, true
+		}
+	}
+	return
+}
+
+// setAssetMarketParams updates the legacy asset lot sizes and rate steps for
+// the current set of markets. As in NewDEX, the value is zero for any asset
+// with conflicting values across markets so that older clients cannot use it.
+func (cr *configResponse) setAssetMarketParams() {
+	lotSizes := make(map[uint32]uint64)
+	rateSteps := make(map[uint32]uint64)
+	for _, mkt := range cr.configMsg.Markets {
+		if lotSize, found := lotSizes[mkt.Base]; !found {
+			lotSizes[mkt.Base] = mkt.LotSize
+		} else if lotSize != mkt.LotSize {
+			lotSizes[mkt.Base] = 0
+		}
+		if rateStep, found := rateSteps[mkt.Quote]; !found {
+			rateSteps[mkt.Quote] = mkt.RateStep
+		} else if rateStep != mkt.RateStep {
+			rateSteps[mkt.Quote] = 0
+		}
+	}
+	for _, a := range cr.configMsg.Assets {
+		a.LotSize = lotSizes[a.ID]
+		a.RateStep = rateSteps[a.ID]
+	}
+}
+
 func (cr *configResponse) remarshal() {
 	encResult, err := json.Marshal(cr.configMsg)
 	if err != nil {
@@ -212,7 +327,10 @@ func (cr *configResponse) remarshal() {
 // completed their shutdown.
 func (dm *DEX) Stop() {
 	log.Infof("Stopping all DEX subsystems.")
-	for _, ss := range dm.subsystems {
+	dm.marketsMtx.RLock()
+	subsystems := dm.subsystems
+	dm.marketsMtx.RUnlock()
+	for _, ss := range subsystems {
 		log.Infof("Stopping %s...", ss.name)
 		ss.stop()
 		log.Infof("%s is now shut down.", ss.name)
@@ -317,6 +435,14 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		if assetConf.MaxFeeRate == 0 {
 			return nil, fmt.Errorf("max fee rate of 0 is invalid for asset %q", symbol)
 		}
+		if assetConf.FeeRateFloor > assetConf.MaxFeeRate {
+			return nil, fmt.Errorf("fee rate floor %d is greater than the max fee rate %d for asset %q",
+				assetConf.FeeRateFloor, assetConf.MaxFeeRate, symbol)
+		}
+		// A bond must be confirmed before it is accepted.
+		if assetConf.BondAmt > 0 && assetConf.BondConfs == 0 {
+			return nil, fmt.Errorf("bond confirmations of 0 is invalid for bond asset %q", symbol)
+		}
 
 		assetIDs[i] = assetID
 	}
@@ -347,17 +473,31 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		}
 	}
 
-	// Create DEXArchivist with the pg DB driver. The fee Addressers require the
-	// archivist for key index storage and retrieval.
-	pgCfg := &pg.Config{
-		Host:         cfg.DBConf.Host,
-		Port:         strconv.Itoa(int(cfg.DBConf.Port)),
-		User:         cfg.DBConf.User,
-		Pass:         cfg.DBConf.Pass,
-		DBName:       cfg.DBConf.DBName,
-		ShowPGConfig: cfg.DBConf.ShowPGConfig,
-		QueryTimeout: 20 * time.Minute,
-		MarketCfg:    cfg.Markets,
+	// Create DEXArchivist with the configured DB driver. The fee Addressers
+	// require the archivist for key index storage and retrieval.
+	dbDriver := cfg.DBConf.Driver
+	var dbCfg interface{}
+	switch dbDriver {
+	case "sqlite":
+		dbCfg = &sqlite.Config{
+			Path:         cfg.DBConf.SQLitePath,
+			QueryTimeout: 20 * time.Minute,
+			MarketCfg:    cfg.Markets,
+		}
+	case "pg", "":
+		dbDriver = "pg"
+		dbCfg = &pg.Config{
+			Host:         cfg.DBConf.Host,
+			Port:         strconv.Itoa(int(cfg.DBConf.Port)),
+			User:         cfg.DBConf.User,
+			Pass:         cfg.DBConf.Pass,
+			DBName:       cfg.DBConf.DBName,
+			ShowPGConfig: cfg.DBConf.ShowPGConfig,
+			QueryTimeout: 20 * time.Minute,
+			MarketCfg:    cfg.Markets,
+		}
+	default:
+		return nil, fmt.Errorf("unknown DB driver %q", dbDriver)
 	}
 	// After DEX construction, the storage subsystem should be stopped
 	// gracefully with its Close method, and in coordination with other
@@ -372,7 +512,7 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		case <-running: // DB shutdown now only via dex.Stop=>db.Close
 		}
 	}()
-	storage, err := db.Open(ctxDB, "pg", pgCfg)
+	storage, err := db.Open(ctxDB, dbDriver, dbCfg)
 	if err != nil {
 		return nil, fmt.Errorf("db.Open: %w", err)
 	}
@@ -388,6 +528,10 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	feeAddressers := make(map[uint32]asset.Addresser)
 	xpubs := make(map[string]string) // to enforce uniqueness
 
+	// Prepare fidelity bond assets.
+	bondAssets := make(map[string]*msgjson.BondAsset)
+	bonders := make(map[uint32]asset.Bonder)
+
 	// Start asset backends.
 	lockableAssets := make(map[uint32]*swap.SwapperAsset, len(cfg.Assets))
 	backedAssets := make(map[uint32]*asset.BackedAsset, len(cfg.Assets))
@@ -419,10 +563,19 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 			if !is {
 				return fmt.Errorf("token %d parent %d is not a TokenBacker", assetID, parentID)
 			}
+			if len(assetConf.FailoverConfigPaths) > 0 {
+				return fmt.Errorf("token %q uses the nodes of its parent asset. failover nodes must be configured for the parent", symbol)
+			}
 			be, err = backer.TokenBackend(assetID, assetConf.ConfigPath)
 			if err != nil {
 				return fmt.Errorf("failed to setup token %q: %w", symbol, err)
 			}
+		} else if len(assetConf.FailoverConfigPaths) > 0 {
+			configPaths := append([]string{assetConf.ConfigPath}, assetConf.FailoverConfigPaths...)
+			be, err = asset.SetupFailover(assetID, configPaths, logger, cfg.Network)
+			if err != nil {
+				return fmt.Errorf("failed to setup asset %q with failover nodes: %w", symbol, err)
+			}
 		} else {
 			be, err = asset.Setup(assetID, assetConf.ConfigPath, logger, cfg.Network)
 			if err != nil {
@@ -430,6 +583,21 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 			}
 		}
 
+		// Configure the fee rate estimation chain before starting the backend,
+		// which may prime its fee rate.
+		if frc, is := be.(asset.FeeRateConfigurer); is {
+			err = frc.ConfigureFeeRate(&asset.FeeRateConfig{
+				Floor:      assetConf.FeeRateFloor,
+				MaxFeeRate: assetConf.MaxFeeRate,
+				HalfLife:   time.Duration(assetConf.FeeRateHalfLife) * time.Second,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to configure fee rates for asset %q: %w", symbol, err)
+			}
+		} else if assetConf.FeeRateFloor > 0 || assetConf.FeeRateHalfLife > 0 {
+			return fmt.Errorf("asset %q does not support a fee rate floor or smoothing", symbol)
+		}
+
 		err = startSubSys(fmt.Sprintf("Asset[%s]", symbol), be)
 		if err != nil {
 			return fmt.Errorf("failed to start asset %q: %w", symbol, err)
@@ -461,6 +629,23 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 				symbol, assetConf.RegFee, assetConf.RegConfs, startChild)
 		}
 
+		if assetConf.BondAmt > 0 {
+			// Make sure we can check on bond transactions.
+			bonder, ok := be.(asset.Bonder)
+			if !ok {
+				return fmt.Errorf("asset %v is not a Bonder", symbol)
+			}
+			bondAssets[symbol] = &msgjson.BondAsset{
+				Version: bonder.BondVer(),
+				ID:      assetID,
+				Amt:     assetConf.BondAmt,
+				Confs:   assetConf.BondConfs,
+			}
+			bonders[assetID] = bonder
+			log.Infof("Bonds accepted using %s: amount %d, confs %d",
+				symbol, assetConf.BondAmt, assetConf.BondConfs)
+		}
+
 		unitInfo, err := asset.UnitInfo(assetID)
 		if err != nil {
 			return err
@@ -547,10 +732,22 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		return nil, err
 	}
 
+	// The DEX manager is populated as the subsystems are created. The markets
+	// map is needed by the callbacks provided to the AuthManager and Swapper.
+	dexMgr := &DEX{
+		network:     cfg.Network,
+		markets:     make(map[string]*market.Market, len(cfg.Markets)),
+		assets:      lockableAssets,
+		storage:     storage,
+		feeMgr:      feeMgr,
+		coinLocker:  dexCoinLocker,
+		dataAPI:     dataAPI,
+		saveMarkets: cfg.SaveMarkets,
+	}
+
 	// Create the user order unbook dispatcher for the AuthManager.
-	markets := make(map[string]*market.Market, len(cfg.Markets))
 	userUnbookFun := func(user account.AccountID) {
-		for _, mkt := range markets {
+		for _, mkt := range dexMgr.marketList() {
 			mkt.UnbookUserOrders(user)
 		}
 	}
@@ -577,12 +774,24 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		return fc.FeeCoin(coinID)
 	}
 
+	bondChecker := func(ctx context.Context, assetID uint32, version uint16, coinID []byte) (amt, lockTime, confs int64, acct account.AccountID, err error) {
+		bonder := bonders[assetID]
+		if bonder == nil {
+			err = fmt.Errorf("unsupported bond asset")
+			return
+		}
+		return bonder.BondCoin(ctx, version, coinID)
+	}
+
 	authCfg := auth.Config{
 		Storage:           storage,
 		Signer:            signer{cfg.DEXPrivKey},
 		FeeAddress:        feeAddresser,
 		FeeAssets:         feeAssets,
 		FeeChecker:        feeChecker,
+		BondAssets:        bondAssets,
+		BondExpiry:        cfg.BondExpiry,
+		BondChecker:       bondChecker,
 		UserUnbooker:      userUnbookFun,
 		MiaUserTimeout:    cfg.BroadcastTimeout,
 		CancelThreshold:   cfg.CancelThreshold,
@@ -610,7 +819,12 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 			log.Errorf("bad market for order %v: %v", ord.ID(), err)
 			return
 		}
-		markets[name].SwapDone(ord, match, fail)
+		mkt := dexMgr.market(name)
+		if mkt == nil {
+			log.Warnf("swap done for order %v on unknown market %s", ord.ID(), name)
+			return
+		}
+		mkt.SwapDone(ord, match, fail)
 	}
 
 	// Create the swapper.
@@ -632,15 +846,18 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		return nil, fmt.Errorf("NewSwapper: %w", err)
 	}
 
+	dexMgr.authMgr = authMgr
+	dexMgr.swapper = swapper
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	// Because the dexBalancer relies on the marketTunnels map, and NewMarket
-	// checks necessary balances for account-based assets using the dexBalancer,
-	// that means that each market can only query orders for the markets that
-	// were intitialized before it was, which is fine, but notable. The
-	// resulting behavior is that a user could have orders involving an
+	// Because markets are added to the dexBalancer as they are created, and
+	// NewMarket checks necessary balances for account-based assets using the
+	// dexBalancer, that means that each market can only query orders for the
+	// markets that were intitialized before it was, which is fine, but notable.
+	// The resulting behavior is that a user could have orders involving an
 	// account-based asset approved for re-booking on one market, but have
 	// orders rejected on a market involving the same asset created afterwards,
 	// since the later balance query is accounting for the earlier market.
@@ -650,51 +867,23 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	// be developed to do reject only some orders, based on available funding.
 	//
 	// This pattern is only safe because the markets are not Run until after
-	// they are all instantiated, so we are synchronous in our use of the
-	// marketTunnels map.
-	marketTunnels := make(map[string]market.MarketTunnel, len(cfg.Markets))
-	pendingAccounters := make(map[string]market.PendingAccounter, len(cfg.Markets))
-
-	dexBalancer, err := market.NewDEXBalancer(pendingAccounters, backedAssets, swapper)
+	// they are all instantiated.
+	dexBalancer, err := market.NewDEXBalancer(nil, backedAssets, swapper)
 	if err != nil {
 		return nil, fmt.Errorf("NewDEXBalancer error: %w", err)
 	}
+	dexMgr.balancer = dexBalancer
 
 	// Markets
 	usersWithOrders := make(map[account.AccountID]struct{})
+	marketTunnels := make(map[string]market.MarketTunnel, len(cfg.Markets))
 	for _, mktInf := range cfg.Markets {
-		// nilness of the coin locker signals account-based asset.
-		var baseCoinLocker, quoteCoinLocker coinlock.CoinLocker
-		if _, ok := backedAssets[mktInf.Base].Backend.(asset.OutputTracker); ok {
-			baseCoinLocker = dexCoinLocker.AssetLocker(mktInf.Base).Book()
-		}
-		if _, ok := backedAssets[mktInf.Quote].Backend.(asset.OutputTracker); ok {
-			quoteCoinLocker = dexCoinLocker.AssetLocker(mktInf.Quote).Book()
-		}
-
-		mkt, err := market.NewMarket(&market.Config{
-			MarketInfo:      mktInf,
-			Storage:         storage,
-			Swapper:         swapper,
-			AuthManager:     authMgr,
-			FeeFetcherBase:  feeMgr.FeeFetcher(mktInf.Base),
-			CoinLockerBase:  baseCoinLocker,
-			FeeFetcherQuote: feeMgr.FeeFetcher(mktInf.Quote),
-			CoinLockerQuote: quoteCoinLocker,
-			DataCollector:   dataAPI,
-			Balancer:        dexBalancer,
-		})
+		mkt, err := dexMgr.newMarket(mktInf, false)
 		if err != nil {
-			return nil, fmt.Errorf("NewMarket failed: %w", err)
+			return nil, err
 		}
-		markets[mktInf.Name] = mkt
+		dexMgr.markets[mktInf.Name] = mkt
 		marketTunnels[mktInf.Name] = mkt
-		pendingAccounters[mktInf.Name] = mkt
-		log.Infof("Preparing historical market data API for market %v...", mktInf.Name)
-		err = dataAPI.AddMarketSource(mkt)
-		if err != nil {
-			return nil, fmt.Errorf("DataSource.AddMarket: %w", err)
-		}
 
 		// Having loaded the book, get the accounts owning the orders.
 		_, buys, sells := mkt.Book()
@@ -721,22 +910,13 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	now := time.Now().UnixMilli()
 	bookSources := make(map[string]market.BookSource, len(cfg.Markets))
 	cfgMarkets := make([]*msgjson.Market, 0, len(cfg.Markets))
-	for name, mkt := range markets {
+	for name, mkt := range dexMgr.markets {
 		startEpochIdx := 1 + now/int64(mkt.EpochDuration())
 		mkt.SetStartEpochIdx(startEpochIdx)
 		bookSources[name] = mkt
-		cfgMarkets = append(cfgMarkets, &msgjson.Market{
-			Name:            name,
-			Base:            mkt.Base(),
-			Quote:           mkt.Quote(),
-			LotSize:         mkt.LotSize(),
-			RateStep:        mkt.RateStep(),
-			EpochLen:        mkt.EpochDuration(),
-			MarketBuyBuffer: mkt.MarketBuyBuffer(),
-			MarketStatus: msgjson.MarketStatus{
-				StartEpoch: uint64(startEpochIdx),
-			},
-		})
+		cfgMarkets = append(cfgMarkets, marketConfig(mkt, msgjson.MarketStatus{
+			StartEpoch: uint64(startEpochIdx),
+		}))
 	}
 
 	// Book router
@@ -747,7 +927,7 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	dataAPI.SetBookSource(bookRouter)
 
 	// Market, now that book router is running.
-	for name, mkt := range markets {
+	for name, mkt := range dexMgr.markets {
 		startSubSys(marketSubSysName(name), mkt)
 	}
 
@@ -771,26 +951,19 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		return nil, fmt.Errorf("NewServer failed: %w", err)
 	}
 
-	cfgResp, err := newConfigResponse(cfg, feeAssets, cfgAssets, cfgMarkets)
+	cfgResp, err := newConfigResponse(cfg, feeAssets, bondAssets, cfgAssets, cfgMarkets)
 	if err != nil {
 		return nil, err
 	}
 
-	dexMgr := &DEX{
-		network:     cfg.Network,
-		markets:     markets,
-		assets:      lockableAssets,
-		swapper:     swapper,
-		authMgr:     authMgr,
-		storage:     storage,
-		orderRouter: orderRouter,
-		bookRouter:  bookRouter,
-		subsystems:  subsystems,
-		server:      server,
-		configResp:  cfgResp,
-	}
+	dexMgr.orderRouter = orderRouter
+	dexMgr.bookRouter = bookRouter
+	dexMgr.server = server
+	dexMgr.configResp = cfgResp
+	dexMgr.subsystems = subsystems
 
 	comms.RegisterHTTP(msgjson.ConfigRoute, dexMgr.handleDEXConfig)
+	dexMgr.registerMetrics()
 
 	startSubSys("Comms Server", server)
 
@@ -812,7 +985,7 @@ func (dm *DEX) Asset(id uint32) (*asset.BackedAsset, error) {
 // the optimal fee rates for new swaps for for the specified asset. That is,
 // values above 1 increase the fee rate, while values below 1 decrease it.
 func (dm *DEX) SetFeeRateScale(assetID uint32, scale float64) {
-	for _, mkt := range dm.markets {
+	for _, mkt := range dm.marketList() {
 		if mkt.Base() == assetID || mkt.Quote() == assetID {
 			mkt.SetFeeRateScale(assetID, scale)
 		}
@@ -823,7 +996,7 @@ func (dm *DEX) SetFeeRateScale(assetID uint32, scale float64) {
 // rate scale factor, which is 1.0 by default.
 func (dm *DEX) ScaleFeeRate(assetID uint32, rate uint64) uint64 {
 	// Any market will have the rate. Just find the first one.
-	for _, mkt := range dm.markets {
+	for _, mkt := range dm.marketList() {
 		if mkt.Base() == assetID || mkt.Quote() == assetID {
 			return mkt.ScaleFeeRate(assetID, rate)
 		}
@@ -841,7 +1014,7 @@ func (dm *DEX) ConfigMsg() json.RawMessage {
 // TODO: for just market running status, the DEX manager should use its
 // knowledge of Market subsystem state.
 func (dm *DEX) MarketRunning(mktName string) (found, running bool) {
-	mkt := dm.markets[mktName]
+	mkt := dm.market(mktName)
 	if mkt == nil {
 		return
 	}
@@ -851,7 +1024,7 @@ func (dm *DEX) MarketRunning(mktName string) (found, running bool) {
 // MarketStatus returns the market.Status for the named market. If the market is
 // unknown to the DEX, nil is returned.
 func (dm *DEX) MarketStatus(mktName string) *market.Status {
-	mkt := dm.markets[mktName]
+	mkt := dm.market(mktName)
 	if mkt == nil {
 		return nil
 	}
@@ -861,6 +1034,8 @@ func (dm *DEX) MarketStatus(mktName string) *market.Status {
 // MarketStatuses returns a map of market names to market.Status for all known
 // markets.
 func (dm *DEX) MarketStatuses() map[string]*market.Status {
+	dm.marketsMtx.RLock()
+	defer dm.marketsMtx.RUnlock()
 	statuses := make(map[string]*market.Status, len(dm.markets))
 	for name, mkt := range dm.markets {
 		statuses[name] = mkt.Status()
@@ -876,6 +1051,9 @@ func (dm *DEX) MarketStatuses() map[string]*market.Status {
 func (dm *DEX) SuspendMarket(name string, tSusp time.Time, persistBooks bool) (suspEpoch *market.SuspendEpoch, err error) {
 	name = strings.ToLower(name)
 
+	dm.marketsMtx.Lock()
+	defer dm.marketsMtx.Unlock()
+
 	// Locate the (running) subsystem for this market.
 	i := dm.findSubsys(marketSubSysName(name))
 	if i == -1 {
@@ -916,6 +1094,8 @@ func (dm *DEX) SuspendMarket(name string, tSusp time.Time, persistBooks bool) (s
 	return
 }
 
+// findSubsys returns the index of the named subsystem, or -1 if it is not found.
+// The marketsMtx must be locked.
 func (dm *DEX) findSubsys(name string) int {
 	for i := range dm.subsystems {
 		if dm.subsystems[i].name == name {
@@ -930,6 +1110,10 @@ func (dm *DEX) findSubsys(name string) int {
 // duration, as the market only starts at the beginning of an epoch.
 func (dm *DEX) ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64, startTime time.Time, err error) {
 	name = strings.ToLower(name)
+
+	dm.marketsMtx.Lock()
+	defer dm.marketsMtx.Unlock()
+
 	mkt := dm.markets[name]
 	if mkt == nil {
 		err = fmt.Errorf("unknown market %s", name)
@@ -1005,6 +1189,78 @@ func (dm *DEX) ForgiveMatchFail(aid account.AccountID, mid order.MatchID) (forgi
 	return dm.authMgr.ForgiveMatchFail(aid, mid)
 }
 
+// Penalize closes the account citing the broken rule, unbooks all of the
+// user's orders, and notifies the user of the penalty.
+func (dm *DEX) Penalize(aid account.AccountID, rule account.Rule, details string) error {
+	return dm.authMgr.Penalize(aid, rule, details)
+}
+
+// Unban restores a closed account, allowing the user to resume trading if
+// their score permits it.
+func (dm *DEX) Unban(aid account.AccountID) error {
+	return dm.authMgr.Unban(aid)
+}
+
+// AccountOutcomes returns the account's score and the latest scored match and
+// preimage outcomes from which it is computed.
+func (dm *DEX) AccountOutcomes(aid account.AccountID) (int32, []*MatchOutcome, []*PreimageOutcome, error) {
+	return dm.authMgr.AccountOutcomes(aid)
+}
+
+// ActiveUserOrders returns the account's epoch and booked orders on all
+// markets.
+func (dm *DEX) ActiveUserOrders(ctx context.Context, aid account.AccountID) ([]order.Order, error) {
+	dm.marketsMtx.RLock()
+	mkts := make(map[string]*market.Market, len(dm.markets))
+	for name, mkt := range dm.markets {
+		mkts[name] = mkt
+	}
+	dm.marketsMtx.RUnlock()
+
+	var active []order.Order
+	for name, mkt := range mkts {
+		ords, statuses, err := dm.storage.UserOrders(ctx, aid, mkt.Base(), mkt.Quote())
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving orders for market %s: %w", name, err)
+		}
+		for i, ord := range ords {
+			if statuses[i] == order.OrderStatusEpoch || statuses[i] == order.OrderStatusBooked {
+				active = append(active, ord)
+			}
+		}
+	}
+	return active, nil
+}
+
+// ActiveUserMatches returns the account's active matches on all markets.
+func (dm *DEX) ActiveUserMatches(aid account.AccountID) ([]*db.MatchData, error) {
+	return dm.storage.AllActiveUserMatches(aid)
+}
+
+// UnbookOrder unbooks the specified standing order from the named market
+// without penalizing the owner. The unbooked order is returned.
+func (dm *DEX) UnbookOrder(mktName string, oid order.OrderID) (*order.LimitOrder, error) {
+	mkt := dm.market(mktName)
+	if mkt == nil {
+		return nil, fmt.Errorf("unknown market %s", mktName)
+	}
+	lo := mkt.UnbookOrder(oid)
+	if lo == nil {
+		return nil, fmt.Errorf("order %v not booked on market %s", oid, mktName)
+	}
+	return lo, nil
+}
+
+// UnbookAccountOrders unbooks all of the account's standing orders from the
+// named market without penalizing the user. The unbooked orders are returned.
+func (dm *DEX) UnbookAccountOrders(mktName string, aid account.AccountID) ([]*order.LimitOrder, error) {
+	mkt := dm.market(mktName)
+	if mkt == nil {
+		return nil, fmt.Errorf("unknown market %s", mktName)
+	}
+	return mkt.UnbookAccountOrders(aid), nil
+}
+
 // Notify sends a text notification to a connected client.
 func (dm *DEX) Notify(acctID account.AccountID, msg *msgjson.Message) {
 	dm.authMgr.Notify(acctID, msg)
@@ -1123,3 +1379,14 @@ func (dm *DEX) MarketMatches(base, quote uint32) ([]*MatchData, error) {
 func (dm *DEX) EnableDataAPI(yes bool) {
 	dm.server.EnableDataAPI(yes)
 }
+
+// RateTiers returns the account rate tiers for the order routes.
+func (dm *DEX) RateTiers() []*RateTier {
+	return dm.server.RateTiers()
+}
+
+// SetRateTiers can be called via admin API to replace the account rate tiers
+// for the order routes. The change is not persisted.
+func (dm *DEX) SetRateTiers(tiers []*RateTier) error {
+	return dm.server.SetRateTiers(tiers)
+}
@@ -0,0 +1,366 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/coinlock"
+	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/metrics"
+)
+
+// MarketParams are the market parameters that may be changed for a suspended
+// market with ReconfigureMarket. Zero values leave the current setting
+// unchanged.
+type MarketParams struct {
+	LotSize         uint64
+	RateStep        uint64
+	EpochDuration   uint64
+	MarketBuyBuffer float64
+}
+
+// market returns the named market, or nil if it is not known.
+func (dm *DEX) market(name string) *market.Market {
+	dm.marketsMtx.RLock()
+	defer dm.marketsMtx.RUnlock()
+	return dm.markets[name]
+}
+
+// marketList returns all of the current markets.
+func (dm *DEX) marketList() []*market.Market {
+	dm.marketsMtx.RLock()
+	defer dm.marketsMtx.RUnlock()
+	mkts := make([]*market.Market, 0, len(dm.markets))
+	for _, mkt := range dm.markets {
+		mkts = append(mkts, mkt)
+	}
+	return mkts
+}
+
+// newMarket creates a Market, and registers it with the DEXBalancer and the
+// data API. The Market is not started. If emptyBook is true, the persisted book
+// orders are not loaded.
+func (dm *DEX) newMarket(mktInf *dex.MarketInfo, emptyBook bool) (*market.Market, error) {
+	// nilness of the coin locker signals account-based asset.
+	var baseCoinLocker, quoteCoinLocker coinlock.CoinLocker
+	if _, ok := dm.assets[mktInf.Base].Backend.(asset.OutputTracker); ok {
+		baseCoinLocker = dm.coinLocker.AssetLocker(mktInf.Base).Book()
+	}
+	if _, ok := dm.assets[mktInf.Quote].Backend.(asset.OutputTracker); ok {
+		quoteCoinLocker = dm.coinLocker.AssetLocker(mktInf.Quote).Book()
+	}
+
+	mkt, err := market.NewMarket(&market.Config{
+		MarketInfo:      mktInf,
+		Storage:         dm.storage,
+		Swapper:         dm.swapper,
+		AuthManager:     dm.authMgr,
+		FeeFetcherBase:  dm.feeMgr.FeeFetcher(mktInf.Base),
+		CoinLockerBase:  baseCoinLocker,
+		FeeFetcherQuote: dm.feeMgr.FeeFetcher(mktInf.Quote),
+		CoinLockerQuote: quoteCoinLocker,
+		DataCollector:   dm.dataAPI,
+		Balancer:        dm.balancer,
+		EmptyBook:       emptyBook,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("NewMarket failed: %w", err)
+	}
+	dm.balancer.AddMarket(mkt)
+
+	log.Infof("Preparing historical market data API for market %v...", mktInf.Name)
+	if err = dm.dataAPI.AddMarketSource(mkt); err != nil {
+		dm.balancer.RemoveMarket(mkt)
+		return nil, fmt.Errorf("DataSource.AddMarket: %w", err)
+	}
+	return mkt, nil
+}
+
+// marketConfig creates the config response entry for a market.
+func marketConfig(mkt *market.Market, status msgjson.MarketStatus) *msgjson.Market {
+	mktInfo := mkt.MarketInfo()
+	return &msgjson.Market{
+		Name:            mktInfo.Name,
+		Base:            mktInfo.Base,
+		Quote:           mktInfo.Quote,
+		LotSize:         mktInfo.LotSize,
+		RateStep:        mktInfo.RateStep,
+		EpochLen:        mktInfo.EpochDuration,
+		MarketBuyBuffer: mktInfo.MarketBuyBuffer,
+		MarketStatus:    status,
+	}
+}
+
+// validateMarketInfo checks that the market's assets are configured and that
+// its parameters are usable.
+func (dm *DEX) validateMarketInfo(mktInfo *dex.MarketInfo) error {
+	if mktInfo.Base == mktInfo.Quote {
+		return fmt.Errorf("base and quote assets must differ")
+	}
+	if _, found := dm.assets[mktInfo.Base]; !found {
+		return fmt.Errorf("base asset %d is not configured", mktInfo.Base)
+	}
+	if _, found := dm.assets[mktInfo.Quote]; !found {
+		return fmt.Errorf("quote asset %d is not configured", mktInfo.Quote)
+	}
+	if mktInfo.LotSize == 0 {
+		return fmt.Errorf("market %s has no lot size", mktInfo.Name)
+	}
+	if mktInfo.RateStep == 0 {
+		return fmt.Errorf("market %s has no rate step", mktInfo.Name)
+	}
+	if mktInfo.EpochDuration == 0 {
+		return fmt.Errorf("market %s has no epoch duration", mktInfo.Name)
+	}
+	if mktInfo.MarketBuyBuffer < 0 {
+		return fmt.Errorf("market %s has a negative market buy buffer", mktInfo.Name)
+	}
+	return nil
+}
+
+// stoppedMarketSubsys locates the subsystem for the named market, and ensures
+// that it is stopped. The marketsMtx must be locked.
+func (dm *DEX) stoppedMarketSubsys(name string) (int, error) {
+	i := dm.findSubsys(marketSubSysName(name))
+	if i == -1 {
+		return -1, fmt.Errorf("market subsystem %s not found", name)
+	}
+	ssw := dm.subsystems[i].ssw
+	if ssw.On() {
+		return -1, fmt.Errorf("market %s is not suspended", name)
+	}
+	ssw.WaitForShutdown()
+	return i, nil
+}
+
+// updateConfig applies the update to the config response, and broadcasts the
+// new config to all connected clients in a ConfigUpdateRoute notification.
+func (dm *DEX) updateConfig(update func(*configResponse)) {
+	dm.configRespMtx.Lock()
+	update(dm.configResp)
+	cfgEnc := dm.configResp.configEnc
+	dm.configRespMtx.Unlock()
+
+	note, err := msgjson.NewNotification(msgjson.ConfigUpdateRoute, cfgEnc)
+	if err != nil {
+		log.Errorf("Failed to create config update notification: %v", err)
+		return
+	}
+	dm.server.Broadcast(note)
+}
+
+// saveMarketsConf persists the current set of markets with the SaveMarkets
+// function from the DexConf, if one was provided. The change to the markets is
+// already live, so a failure is logged rather than returned. The marketsMtx must
+// be locked.
+func (dm *DEX) saveMarketsConf() {
+	if dm.saveMarkets == nil {
+		return
+	}
+	mkts := make([]*dex.MarketInfo, 0, len(dm.markets))
+	for _, mkt := range dm.markets {
+		mkts = append(mkts, mkt.MarketInfo())
+	}
+	sort.Slice(mkts, func(i, j int) bool {
+		return mkts[i].Name < mkts[j].Name
+	})
+	if err := dm.saveMarkets(mkts); err != nil {
+		log.Errorf("Failed to save markets config. The markets config file "+
+			"must be updated manually before restarting: %v", err)
+	}
+}
+
+// AddMarket creates and launches a new market for a pair of configured assets.
+// The market's start epoch and start time are returned. A ConfigUpdateRoute
+// notification is broadcasted to all connected clients.
+func (dm *DEX) AddMarket(mktInfo *dex.MarketInfo) (startEpoch int64, startTime time.Time, err error) {
+	name, err := dex.MarketName(mktInfo.Base, mktInfo.Quote)
+	if err != nil {
+		return
+	}
+	mi := *mktInfo
+	mi.Name = name
+	mktInfo = &mi
+	if err = dm.validateMarketInfo(mktInfo); err != nil {
+		return
+	}
+
+	dm.marketsMtx.Lock()
+	defer dm.marketsMtx.Unlock()
+
+	if dm.markets[name] != nil {
+		err = fmt.Errorf("market %s already exists", name)
+		return
+	}
+
+	// The market's tables must exist before the Market loads its book.
+	if err = dm.storage.AddMarket(mktInfo); err != nil {
+		err = fmt.Errorf("failed to prepare storage for market %s: %w", name, err)
+		return
+	}
+
+	mkt, err := dm.newMarket(mktInfo, false)
+	if err != nil {
+		return
+	}
+
+	epochLen := int64(mktInfo.EpochDuration)
+	startEpoch = 1 + time.Now().UnixMilli()/epochLen
+	startTime = time.UnixMilli(startEpoch * epochLen)
+	mkt.SetStartEpochIdx(startEpoch)
+
+	// As in NewDEX, the book router must have the book before the Market runs.
+	dm.markets[name] = mkt
+	dm.bookRouter.AddBook(name, mkt)
+	ssw := dex.NewStartStopWaiter(mkt)
+	ssw.Start(context.Background()) // stopped with Stop
+	dm.subsystems = append([]subsystem{{name: marketSubSysName(name), ssw: ssw}}, dm.subsystems...)
+	dm.orderRouter.AddMarket(name, mkt)
+
+	log.Infof("Added market %s, starting at epoch %d (%v)", name, startEpoch, startTime)
+
+	dm.updateConfig(func(cr *configResponse) {
+		cr.setMarket(marketConfig(mkt, msgjson.MarketStatus{
+			StartEpoch: uint64(startEpoch),
+		}))
+	})
+
+	dm.saveMarketsConf()
+	return
+}
+
+// RetireMarket permanently removes a suspended market. Any orders remaining on
+// the book are revoked without penalty. A ConfigUpdateRoute notification is
+// broadcasted to all connected clients.
+func (dm *DEX) RetireMarket(name string) error {
+	name = strings.ToLower(name)
+
+	dm.marketsMtx.Lock()
+	defer dm.marketsMtx.Unlock()
+
+	mkt := dm.markets[name]
+	if mkt == nil {
+		return fmt.Errorf("unknown market %s", name)
+	}
+	i, err := dm.stoppedMarketSubsys(name)
+	if err != nil {
+		return err
+	}
+
+	dm.orderRouter.RemoveMarket(name)
+	dm.bookRouter.RemoveBook(name)
+	dm.balancer.RemoveMarket(mkt)
+	dm.dataAPI.RemoveMarketSource(name)
+	mkt.PurgeBook()
+	dm.subsystems = append(dm.subsystems[:i:i], dm.subsystems[i+1:]...)
+	delete(dm.markets, name)
+	metrics.EpochQueueSize.Delete(name)
+
+	log.Infof("Retired market %s", name)
+
+	dm.updateConfig(func(cr *configResponse) {
+		cr.removeMarket(name)
+	})
+
+	dm.saveMarketsConf()
+	return nil
+}
+
+// ReconfigureMarket changes the parameters of a suspended market. The Market is
+// replaced by a new Market with the updated configuration. If the lot size is
+// unchanged, the new Market takes over the booked orders. Otherwise, the book is
+// flushed, and the owners of the orders and the order book subscribers are
+// notified of the revocations. The market remains suspended until it is resumed
+// with ResumeMarket. A ConfigUpdateRoute notification is broadcasted to all
+// connected clients.
+func (dm *DEX) ReconfigureMarket(name string, params *MarketParams) (*dex.MarketInfo, error) {
+	name = strings.ToLower(name)
+
+	dm.marketsMtx.Lock()
+	defer dm.marketsMtx.Unlock()
+
+	oldMkt := dm.markets[name]
+	if oldMkt == nil {
+		return nil, fmt.Errorf("unknown market %s", name)
+	}
+	if _, err := dm.stoppedMarketSubsys(name); err != nil {
+		return nil, err
+	}
+
+	mktInfo := oldMkt.MarketInfo()
+	oldLotSize := mktInfo.LotSize
+	if params.LotSize > 0 {
+		mktInfo.LotSize = params.LotSize
+	}
+	if params.RateStep > 0 {
+		mktInfo.RateStep = params.RateStep
+	}
+	if params.EpochDuration > 0 {
+		mktInfo.EpochDuration = params.EpochDuration
+	}
+	if params.MarketBuyBuffer > 0 {
+		mktInfo.MarketBuyBuffer = params.MarketBuyBuffer
+	}
+	if err := dm.validateMarketInfo(mktInfo); err != nil {
+		return nil, err
+	}
+
+	// Build the new Market before anything is changed for the old one. The
+	// booked orders are moved or revoked below, so the new Market does not
+	// load them or lock their funding coins.
+	mkt, err := dm.newMarket(mktInfo, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the new lot size, which flushes the persisted book if it changed.
+	if err = dm.storage.AddMarket(mktInfo); err != nil {
+		dm.balancer.RemoveMarket(mkt)
+		if err := dm.dataAPI.AddMarketSource(oldMkt); err != nil {
+			log.Errorf("Failed to restore data API source for market %s: %v", name, err)
+		}
+		return nil, fmt.Errorf("failed to update storage for market %s: %w", name, err)
+	}
+
+	var revoked []*order.LimitOrder
+	if mktInfo.LotSize == oldLotSize {
+		mkt.TakeBook(oldMkt)
+	} else {
+		revoked = oldMkt.ReleaseBook()
+	}
+	dm.balancer.RemoveMarket(oldMkt)
+
+	// The market's stopped subsystem is left in place. ResumeMarket will
+	// launch the new Market.
+	dm.markets[name] = mkt
+	dm.bookRouter.AddBook(name, mkt)
+	dm.orderRouter.AddMarket(name, mkt)
+
+	// The book router is now subscribed to the new Market, so the unbook
+	// notifications reach the book subscribers.
+	if len(revoked) > 0 {
+		log.Infof("Revoked %d orders booked with the old lot size on market %s.", len(revoked), name)
+		mkt.NotifyRevoked(revoked)
+	}
+
+	log.Infof("Reconfigured market %s: lot size %d, rate step %d, epoch duration %d ms, market buy buffer %f",
+		name, mktInfo.LotSize, mktInfo.RateStep, mktInfo.EpochDuration, mktInfo.MarketBuyBuffer)
+
+	dm.updateConfig(func(cr *configResponse) {
+		status, _ := cr.marketStatus(name)
+		cr.setMarket(marketConfig(mkt, status))
+	})
+
+	dm.saveMarketsConf()
+	return mktInfo, nil
+}
@@ -0,0 +1,87 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"strconv"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/metrics"
+)
+
+// registerMetrics registers the gauges that are collected from the DEX's
+// subsystems each time the metrics are written.
+func (dm *DEX) registerMetrics() {
+	metrics.RegisterGaugeFunc("dcrdex_active_swaps",
+		"Number of active swaps in each match status.",
+		dm.activeSwapSamples, "status")
+	metrics.RegisterGaugeFunc("dcrdex_asset_fee_rate",
+		"Last fee rate reported by the asset backend, in atoms per byte or gwei per gas.",
+		dm.feeRateSamples, "asset")
+	metrics.RegisterGaugeFunc("dcrdex_asset_synced",
+		"Whether the asset backend is synced (1) or not (0).",
+		dm.syncedSamples, "asset")
+	metrics.RegisterGaugeFunc("dcrdex_connected_clients",
+		"Number of connected websocket clients.",
+		func() []metrics.Sample {
+			return []metrics.Sample{{Value: float64(dm.server.ClientCount())}}
+		})
+}
+
+func (dm *DEX) activeSwapSamples() []metrics.Sample {
+	counts := dm.swapper.MatchStatusCounts()
+	samples := make([]metrics.Sample, 0, len(counts))
+	for status, n := range counts {
+		samples = append(samples, metrics.Sample{
+			LabelValues: []string{status.String()},
+			Value:       float64(n),
+		})
+	}
+	return samples
+}
+
+// assetLabel is the asset label value for the asset ID.
+func assetLabel(assetID uint32) string {
+	if symbol := dex.BipIDSymbol(assetID); symbol != "" {
+		return symbol
+	}
+	return strconv.FormatUint(uint64(assetID), 10)
+}
+
+// feeRateSamples reports the fee manager's cached fee rates rather than
+// requesting them from the backends, so that scrapes don't generate RPCs.
+// Assets without a known rate are omitted.
+func (dm *DEX) feeRateSamples() []metrics.Sample {
+	samples := make([]metrics.Sample, 0, len(dm.assets))
+	for assetID := range dm.assets {
+		feeRate := dm.feeMgr.LastRate(assetID)
+		if feeRate == 0 {
+			continue
+		}
+		samples = append(samples, metrics.Sample{
+			LabelValues: []string{assetLabel(assetID)},
+			Value:       float64(feeRate),
+		})
+	}
+	return samples
+}
+
+func (dm *DEX) syncedSamples() []metrics.Sample {
+	samples := make([]metrics.Sample, 0, len(dm.assets))
+	for assetID, a := range dm.assets {
+		synced, err := a.Backend.Synced()
+		if err != nil {
+			log.Debugf("Unable to get %s sync status for metrics: %v", assetLabel(assetID), err)
+		}
+		var v float64
+		if synced {
+			v = 1
+		}
+		samples = append(samples, metrics.Sample{
+			LabelValues: []string{assetLabel(assetID)},
+			Value:       v,
+		})
+	}
+	return samples
+}
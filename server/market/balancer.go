@@ -5,6 +5,7 @@ package market
 
 import (
 	"fmt"
+	"sync"
 
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/calc"
@@ -41,6 +42,8 @@ type MatchNegotiator interface {
 type DEXBalancer struct {
 	assets          map[uint32]*backedBalancer
 	matchNegotiator MatchNegotiator
+	// marketsMtx guards the markets of each backedBalancer.
+	marketsMtx sync.RWMutex
 }
 
 // NewDEXBalancer is a constructor for a DEXBalancer. Provided assets will
@@ -203,7 +206,10 @@ func (b *DEXBalancer) CheckBalance(acctAddr string, assetID, redeemAssetID uint3
 
 		var l uint64
 		var r int
-		for _, mt := range ba.markets {
+		b.marketsMtx.RLock()
+		markets := ba.markets
+		b.marketsMtx.RUnlock()
+		for _, mt := range markets {
 			newQty, newLots, newRedeems := mt.AccountPending(acctAddr, assetID)
 			l += newLots
 			q += newQty
@@ -258,6 +264,37 @@ func (b *DEXBalancer) CheckBalance(acctAddr string, assetID, redeemAssetID uint3
 	return bal >= reqFunds
 }
 
+// AddMarket adds a market to the balance checks of its account-based assets.
+func (b *DEXBalancer) AddMarket(mkt PendingAccounter) {
+	b.marketsMtx.Lock()
+	defer b.marketsMtx.Unlock()
+	for _, assetID := range []uint32{mkt.Base(), mkt.Quote()} {
+		if ba, found := b.assets[assetID]; found {
+			ba.markets = append(ba.markets[:len(ba.markets):len(ba.markets)], mkt)
+		}
+	}
+}
+
+// RemoveMarket removes a market from the balance checks of its account-based
+// assets.
+func (b *DEXBalancer) RemoveMarket(mkt PendingAccounter) {
+	b.marketsMtx.Lock()
+	defer b.marketsMtx.Unlock()
+	for _, assetID := range []uint32{mkt.Base(), mkt.Quote()} {
+		ba, found := b.assets[assetID]
+		if !found {
+			continue
+		}
+		markets := make([]PendingAccounter, 0, len(ba.markets))
+		for _, m := range ba.markets {
+			if m != mkt {
+				markets = append(markets, m)
+			}
+		}
+		ba.markets = markets
+	}
+}
+
 // backedBalancer is similar to a BackedAsset, but with the Backends already
 // cast to AccountBalancer.
 type backedBalancer struct {
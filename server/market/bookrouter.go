@@ -200,6 +200,8 @@ type msgBook struct {
 	source   BookSource
 	baseID   uint32
 	quoteID  uint32
+	// cancel stops the book's monitoring loop.
+	cancel context.CancelFunc
 }
 
 func (book *msgBook) setEpoch(idx int64) {
@@ -270,7 +272,12 @@ func (book *msgBook) addBulkOrders(epoch int64, orderSets ...[]*order.LimitOrder
 // of subscribers, and maintaining an intermediate copy of the orderbook in
 // message payload format for quick, full-book syncing.
 type BookRouter struct {
-	books     map[string]*msgBook
+	booksMtx sync.RWMutex
+	books    map[string]*msgBook
+	// ctx is the Context passed to Run, and is nil until Run is called.
+	ctx context.Context
+	wg  sync.WaitGroup
+
 	feeSource FeeSource
 
 	priceFeeders *subscribers
@@ -292,18 +299,9 @@ func NewBookRouter(sources map[string]BookSource, feeSource FeeSource) *BookRout
 		spots: make(map[string]*msgjson.Spot),
 	}
 	for mkt, src := range sources {
-		subs := &subscribers{
+		router.books[mkt] = newMsgBook(mkt, src, &subscribers{
 			conns: make(map[uint64]comms.Link),
-		}
-		book := &msgBook{
-			name:    mkt,
-			orders:  make(map[order.OrderID]*msgjson.BookOrderNote),
-			subs:    subs,
-			source:  src,
-			baseID:  src.Base(),
-			quoteID: src.Quote(),
-		}
-		router.books[mkt] = book
+		})
 	}
 	comms.Route(msgjson.OrderBookRoute, router.handleOrderBook)
 	comms.Route(msgjson.UnsubOrderBookRoute, router.handleUnsubOrderBook)
@@ -313,23 +311,90 @@ func NewBookRouter(sources map[string]BookSource, feeSource FeeSource) *BookRout
 	return router
 }
 
+func newMsgBook(mkt string, src BookSource, subs *subscribers) *msgBook {
+	return &msgBook{
+		name:    mkt,
+		orders:  make(map[order.OrderID]*msgjson.BookOrderNote),
+		subs:    subs,
+		source:  src,
+		baseID:  src.Base(),
+		quoteID: src.Quote(),
+	}
+}
+
 // Run implements dex.Runner, and is blocking.
 func (r *BookRouter) Run(ctx context.Context) {
-	var wg sync.WaitGroup
+	r.booksMtx.Lock()
+	r.ctx = ctx
 	for _, b := range r.books {
-		wg.Add(1)
-		go func(b *msgBook) {
-			r.runBook(ctx, b)
-			wg.Done()
-		}(b)
+		r.startBook(b)
+	}
+	r.booksMtx.Unlock()
+	<-ctx.Done()
+	r.wg.Wait()
+}
+
+// startBook starts the monitoring loop for the book. The booksMtx must be
+// locked, and Run must have been called.
+func (r *BookRouter) startBook(book *msgBook) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	book.cancel = cancel
+	// Subscribe before the loop starts so that signals sent by the source
+	// immediately after the book is added are not missed.
+	feed := book.source.OrderFeed()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.runBook(ctx, book, feed)
+	}()
+}
+
+// AddBook begins serving the order book for a market. If a book for the market
+// already exists, it is replaced, and its subscribers are transferred to the
+// new book.
+func (r *BookRouter) AddBook(mktName string, src BookSource) {
+	r.booksMtx.Lock()
+	defer r.booksMtx.Unlock()
+	subs := &subscribers{
+		conns: make(map[uint64]comms.Link),
+	}
+	if old := r.books[mktName]; old != nil {
+		if old.cancel != nil {
+			old.cancel()
+		}
+		subs = old.subs
+	}
+	book := newMsgBook(mktName, src, subs)
+	r.books[mktName] = book
+	if r.ctx != nil {
+		r.startBook(book)
 	}
-	wg.Wait()
+}
+
+// RemoveBook stops serving the order book for a market.
+func (r *BookRouter) RemoveBook(mktName string) {
+	r.booksMtx.Lock()
+	defer r.booksMtx.Unlock()
+	book := r.books[mktName]
+	if book == nil {
+		return
+	}
+	if book.cancel != nil {
+		book.cancel()
+	}
+	delete(r.books, mktName)
+}
+
+// book gets the msgBook for the named market, or nil if it is not known.
+func (r *BookRouter) book(mktName string) *msgBook {
+	r.booksMtx.RLock()
+	defer r.booksMtx.RUnlock()
+	return r.books[mktName]
 }
 
 // runBook is a monitoring loop for an order book.
-func (r *BookRouter) runBook(ctx context.Context, book *msgBook) {
+func (r *BookRouter) runBook(ctx context.Context, book *msgBook, feed <-chan *updateSignal) {
 	// Get the initial book.
-	feed := book.source.OrderFeed()
 	book.addBulkOrders(book.source.Book())
 	subs := book.subs
 
@@ -401,7 +466,7 @@ out:
 				bookNote := book.update(lo)
 				n := &msgjson.UpdateRemainingNote{
 					OrderNote: bookNote.OrderNote,
-					Remaining: lo.Remaining(),
+					Remaining: lo.Visible(), // only the displayed quantity of an iceberg order
 				}
 				n.Seq = subs.nextSeq()
 				note = n
@@ -526,7 +591,7 @@ out:
 
 // Book creates a copy of the book as a *msgjson.OrderBook.
 func (r *BookRouter) Book(mktName string) (*msgjson.OrderBook, error) {
-	book := r.books[mktName]
+	book := r.book(mktName)
 	if book == nil {
 		return nil, fmt.Errorf("market %s unknown", mktName)
 	}
@@ -599,8 +664,8 @@ func (r *BookRouter) handleOrderBook(conn comms.Link, msg *msgjson.Message) *msg
 			Message: "market name error: " + err.Error(),
 		}
 	}
-	book, found := r.books[mkt]
-	if !found {
+	book := r.book(mkt)
+	if book == nil {
 		return &msgjson.Error{
 			Code:    msgjson.UnknownMarket,
 			Message: "unknown market",
@@ -623,7 +688,7 @@ func (r *BookRouter) handleUnsubOrderBook(conn comms.Link, msg *msgjson.Message)
 			Message: "error parsing unsub_orderbook request",
 		}
 	}
-	book := r.books[unsub.MarketID]
+	book := r.book(unsub.MarketID)
 	if book == nil {
 		return &msgjson.Error{
 			Code:    msgjson.UnknownMarket,
@@ -746,7 +811,8 @@ func cancelOrderToMsgOrder(o *order.CancelOrder, mkt string) *msgjson.BookOrderN
 }
 
 // limitOrderToMsgOrder converts an *order.LimitOrder to a
-// *msgjson.BookOrderNote.
+// *msgjson.BookOrderNote. Only the displayed quantity of an iceberg order is
+// included.
 func limitOrderToMsgOrder(o *order.LimitOrder, mkt string) *msgjson.BookOrderNote {
 	oid := o.ID()
 	oSide := uint8(msgjson.BuyOrderNum)
@@ -754,8 +820,11 @@ func limitOrderToMsgOrder(o *order.LimitOrder, mkt string) *msgjson.BookOrderNot
 		oSide = msgjson.SellOrderNum
 	}
 	tif := uint8(msgjson.StandingOrderNum)
-	if o.Force == order.ImmediateTiF {
+	switch o.Force {
+	case order.ImmediateTiF:
 		tif = msgjson.ImmediateOrderNum
+	case order.GoodTilTimeTiF:
+		tif = msgjson.GoodTilTimeOrderNum
 	}
 	return &msgjson.BookOrderNote{
 		OrderNote: msgjson.OrderNote{
@@ -765,7 +834,7 @@ func limitOrderToMsgOrder(o *order.LimitOrder, mkt string) *msgjson.BookOrderNot
 		},
 		TradeNote: msgjson.TradeNote{
 			Side:     oSide,
-			Quantity: o.Remaining(),
+			Quantity: o.Visible(),
 			Rate:     o.Rate,
 			TiF:      tif,
 			Time:     uint64(o.ServerTime.UnixMilli()),
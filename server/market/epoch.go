@@ -24,19 +24,23 @@ type EpochQueue struct {
 	UserCancels map[account.AccountID]uint32
 	// CancelTargets maps known targeted order IDs with the CancelOrder
 	CancelTargets map[order.OrderID]*order.CancelOrder
+	// ReplaceTargets maps the order IDs targeted by replacement orders with
+	// the replacement LimitOrder.
+	ReplaceTargets map[order.OrderID]*order.LimitOrder
 }
 
 // NewEpoch creates an epoch with the given index and duration in milliseconds.
 func NewEpoch(idx int64, duration int64) *EpochQueue {
 	startTime := time.UnixMilli(idx * duration)
 	return &EpochQueue{
-		Epoch:         idx,
-		Duration:      duration,
-		Start:         startTime,
-		End:           startTime.Add(time.Duration(duration) * time.Millisecond),
-		Orders:        make(map[order.OrderID]order.Order),
-		UserCancels:   make(map[account.AccountID]uint32),
-		CancelTargets: make(map[order.OrderID]*order.CancelOrder),
+		Epoch:          idx,
+		Duration:       duration,
+		Start:          startTime,
+		End:            startTime.Add(time.Duration(duration) * time.Millisecond),
+		Orders:         make(map[order.OrderID]order.Order),
+		UserCancels:    make(map[account.AccountID]uint32),
+		CancelTargets:  make(map[order.OrderID]*order.CancelOrder),
+		ReplaceTargets: make(map[order.OrderID]*order.LimitOrder),
 	}
 }
 
@@ -52,9 +56,15 @@ func (eq *EpochQueue) OrderSlice() []order.Order {
 // Stores an order in the Order slice, overwriting and pre-existing order.
 func (eq *EpochQueue) Insert(ord order.Order) {
 	eq.Orders[ord.ID()] = ord
-	if co, ok := ord.(*order.CancelOrder); ok {
-		eq.CancelTargets[co.TargetOrderID] = co
-		eq.UserCancels[co.AccountID]++
+	switch o := ord.(type) {
+	case *order.CancelOrder:
+		eq.CancelTargets[o.TargetOrderID] = o
+		eq.UserCancels[o.AccountID]++
+	case *order.LimitOrder:
+		// Replacements are not counted as cancels.
+		if o.IsReplacement() {
+			eq.ReplaceTargets[o.Replaces] = o
+		}
 	}
 }
 
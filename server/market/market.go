@@ -4,6 +4,7 @@
 package market
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -27,6 +28,7 @@ import (
 	"decred.org/dcrdex/server/comms"
 	"decred.org/dcrdex/server/db"
 	"decred.org/dcrdex/server/matcher"
+	"decred.org/dcrdex/server/metrics"
 )
 
 // Error is just a basic error.
@@ -45,10 +47,13 @@ const (
 	ErrDuplicateOrder         = Error("order already in epoch") // maybe remove since this is ill defined
 	ErrQuantityTooHigh        = Error("order quantity exceeds user limit")
 	ErrDuplicateCancelOrder   = Error("equivalent cancel order already in epoch")
+	ErrDuplicateReplaceOrder  = Error("targeted order already canceled or replaced in epoch")
+	ErrInvalidReplacement     = Error("replacement order does not match its targeted order")
 	ErrTooManyCancelOrders    = Error("too many cancel orders in current epoch")
 	ErrCancelNotPermitted     = Error("cancel order account does not match targeted order account")
 	ErrTargetNotActive        = Error("target order not active on this market")
 	ErrTargetNotCancelable    = Error("targeted order is not a limit order with standing time-in-force")
+	ErrInvalidExpiry          = Error("good-til-time order expires before its epoch closes")
 	ErrSuspendedAccount       = Error("suspended account")
 	ErrMalformedOrderResponse = Error("malformed order response")
 	ErrInternalServer         = Error("internal server error")
@@ -96,6 +101,10 @@ type Config struct {
 	CoinLockerQuote coinlock.CoinLocker
 	DataCollector   DataCollector
 	Balancer        Balancer
+	// EmptyBook prevents the Market from loading the persisted book orders.
+	// This is used when replacing a suspended Market, whose book is either
+	// taken over with TakeBook or revoked.
+	EmptyBook bool
 }
 
 // Market is the market manager. It should not be overly involved with details
@@ -194,11 +203,15 @@ func NewMarket(cfg *Config) (*Market, error) {
 	// Load existing book orders from the DB.
 	base, quote := mktInfo.Base, mktInfo.Quote
 
-	bookOrders, err := storage.BookOrders(base, quote)
-	if err != nil {
-		return nil, err
+	var bookOrders []*order.LimitOrder
+	var err error
+	if !cfg.EmptyBook {
+		bookOrders, err = storage.BookOrders(base, quote)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Loaded %d stored book orders.", len(bookOrders))
 	}
-	log.Infof("Loaded %d stored book orders.", len(bookOrders))
 
 	baseIsAcctBased := cfg.CoinLockerBase == nil
 	quoteIsAcctBased := cfg.CoinLockerQuote == nil
@@ -665,6 +678,12 @@ func (m *Market) RateStep() uint64 {
 	return m.marketInfo.RateStep
 }
 
+// MarketInfo returns a copy of the Market's configuration.
+func (m *Market) MarketInfo() *dex.MarketInfo {
+	mktInfo := *m.marketInfo
+	return &mktInfo
+}
+
 // Base is the base asset ID.
 func (m *Market) Base() uint32 {
 	return m.marketInfo.Base
@@ -719,11 +738,12 @@ func (m *Market) sendToFeeds(sig *updateSignal) {
 
 type orderUpdateSignal struct {
 	rec     *orderRecord
-	errChan chan error // should be buffered
+	batch   []*orderRecord // a multi-order submission, rec is nil
+	errChan chan error     // should be buffered
 }
 
 func newOrderUpdateSignal(ord *orderRecord) *orderUpdateSignal {
-	return &orderUpdateSignal{ord, make(chan error, 1)}
+	return &orderUpdateSignal{rec: ord, errChan: make(chan error, 1)}
 }
 
 // SubmitOrder submits a new order for inclusion into the current epoch. This is
@@ -934,6 +954,52 @@ func (m *Market) SubmitOrderAsync(rec *orderRecord) <-chan error {
 	return sig.errChan
 }
 
+// SubmitOrders submits a batch of limit orders for inclusion into the current
+// epoch. Either all of the orders are accepted into the same epoch, or none
+// are. This is the synchronous version of SubmitOrdersAsync.
+func (m *Market) SubmitOrders(recs []*orderRecord) error {
+	return <-m.SubmitOrdersAsync(recs)
+}
+
+// SubmitOrdersAsync submits a batch of limit orders for inclusion into the
+// current epoch. Either all of the orders are accepted into the same epoch, or
+// none are.
+func (m *Market) SubmitOrdersAsync(recs []*orderRecord) <-chan error {
+	sendErr := func(err error) <-chan error {
+		errChan := make(chan error, 1)
+		errChan <- err
+		return errChan
+	}
+
+	if len(recs) == 0 {
+		return sendErr(ErrInvalidOrder)
+	}
+	for _, rec := range recs {
+		if rec.order.Type() != order.LimitOrderType {
+			return sendErr(ErrInvalidOrder)
+		}
+		if err := m.validateOrder(rec.order); err != nil {
+			log.Debugf("SubmitOrdersAsync: Invalid order received from user %v with commitment %v: %v",
+				rec.order.User(), rec.order.Commitment(), err)
+			return sendErr(err)
+		}
+	}
+
+	m.runMtx.RLock()
+	defer m.runMtx.RUnlock()
+
+	select {
+	case <-m.running:
+	default:
+		log.Infof("SubmitOrdersAsync: Market stopped with %d orders in submission.", len(recs))
+		return sendErr(ErrMarketNotRunning)
+	}
+
+	sig := &orderUpdateSignal{batch: recs, errChan: make(chan error, 1)}
+	m.orderRouter <- sig
+	return sig.errChan
+}
+
 // MidGap returns the mid-gap market rate, which is ths rate halfway between the
 // best buy order and the best sell order in the order book. If one side has no
 // orders, the best order rate on other side is returned. If both sides have no
@@ -985,7 +1051,7 @@ func (m *Market) Cancelable(oid order.OrderID) bool {
 	m.epochMtx.RUnlock()
 
 	if lo, ok := ord.(*order.LimitOrder); ok {
-		return lo.Force == order.StandingTiF
+		return lo.Force != order.ImmediateTiF
 	}
 	return false
 }
@@ -1016,7 +1082,7 @@ func (m *Market) CancelableBy(oid order.OrderID, aid account.AccountID) (bool, e
 	if !ok {
 		return false, ErrTargetNotCancelable
 	}
-	if lo.Force != order.StandingTiF {
+	if lo.Force == order.ImmediateTiF {
 		return false, ErrTargetNotCancelable
 	}
 	if lo.AccountID != aid {
@@ -1102,7 +1168,7 @@ func (m *Market) SwapDone(ord order.Order, match *order.Match, fail bool) {
 			m.unlockOrderCoins(lo)
 			if removed {
 				// Lazily update DB and auth, and notify orderbook subscribers.
-				m.lazy(func() { m.unbookedOrder(lo) })
+				m.lazy(func() { m.unbookedOrder(lo, false) })
 			}
 		}
 		return
@@ -1112,7 +1178,7 @@ func (m *Market) SwapDone(ord order.Order, match *order.Match, fail bool) {
 	// matches can be made). We check Book.HaveOrder instead of Remaining since
 	// the provided Order instance may not belong to Market and may thus be out
 	// of sync with respect to filled amount.
-	if settling > 0 || (limit && lo.Force == order.StandingTiF && m.book.HaveOrder(oid)) {
+	if settling > 0 || (limit && lo.Force != order.ImmediateTiF && m.book.HaveOrder(oid)) {
 		m.settling[oid] = settling
 		return
 	}
@@ -1299,6 +1365,50 @@ func (m *Market) PurgeBook() {
 	}
 }
 
+// TakeBook moves the orders on the book of a suspended Market that is being
+// replaced onto this Market's book. The funding coins of the orders remain
+// locked. The Markets must be for the same asset pair and lot size, and this
+// Market must have been created with an empty book and not yet started.
+func (m *Market) TakeBook(old *Market) {
+	old.bookMtx.Lock()
+	los := append(old.book.SellOrders(), old.book.BuyOrders()...)
+	old.book.Clear()
+	old.bookMtx.Unlock()
+
+	m.bookMtx.Lock()
+	defer m.bookMtx.Unlock()
+	for _, lo := range los {
+		if !m.book.Insert(lo) {
+			log.Errorf("Failed to move order %v to the new %v book.", lo, m.marketInfo.Name)
+		}
+	}
+}
+
+// ReleaseBook removes all orders from the book of a suspended Market without
+// modifying storage, and unlocks their funding coins. The removed orders are
+// returned. This is used when the persisted book is flushed so that the Market
+// may be replaced with one that has a different lot size.
+func (m *Market) ReleaseBook() []*order.LimitOrder {
+	m.bookMtx.Lock()
+	los := append(m.book.SellOrders(), m.book.BuyOrders()...)
+	m.book.Clear()
+	m.bookMtx.Unlock()
+
+	for _, lo := range los {
+		m.unlockOrderCoins(lo)
+	}
+	return los
+}
+
+// NotifyRevoked sends revoke_order notifications to the owners of orders that
+// were already revoked in storage, and unbook notifications to the order book
+// subscribers.
+func (m *Market) NotifyRevoked(los []*order.LimitOrder) {
+	for _, lo := range los {
+		m.notifyUnbooked(lo)
+	}
+}
+
 func (m *Market) lazy(do func()) {
 	m.tasks.Add(1)
 	go func() {
@@ -1542,17 +1652,24 @@ func (m *Market) Run(ctx context.Context) {
 			return
 
 		case s := <-m.orderRouter:
+			recs := s.batch
+			if s.rec != nil {
+				recs = []*orderRecord{s.rec}
+			}
 			if currentEpoch == nil {
 				// The order is not time-stamped yet, so the ID cannot be computed.
-				log.Debugf("Order type %v received prior to market start.", s.rec.order.Type())
+				log.Debugf("Order type %v received prior to market start.", recs[0].order.Type())
 				s.errChan <- ErrMarketNotRunning
 				continue
 			}
 
 			// Set the order's server time stamp, giving the order a valid ID.
+			// Orders in a batch share a time stamp.
 			sTime := time.Now().Truncate(time.Millisecond).UTC()
-			s.rec.order.SetTime(sTime) // Order.ID()/UID()/String() is OK now.
-			log.Tracef("Received order %v at %v", s.rec.order, sTime)
+			for _, rec := range recs {
+				rec.order.SetTime(sTime) // Order.ID()/UID()/String() is OK now.
+				log.Tracef("Received order %v at %v", rec.order, sTime)
+			}
 
 			// Push the order into the next epoch if receiving and stamping it
 			// took just a little too long.
@@ -1562,7 +1679,7 @@ func (m *Market) Run(ctx context.Context) {
 				orderEpoch = currentEpoch
 			case nextEpoch.IncludesTime(sTime):
 				log.Infof("Order %v (sTime=%d) fell into the next epoch [%d,%d)",
-					s.rec.order, sTime.UnixNano(), nextEpoch.Start.Unix(), nextEpoch.End.Unix())
+					recs[0].order, sTime.UnixNano(), nextEpoch.Start.Unix(), nextEpoch.End.Unix())
 				orderEpoch = nextEpoch
 			default:
 				// This should not happen.
@@ -1573,9 +1690,14 @@ func (m *Market) Run(ctx context.Context) {
 			}
 
 			// Process the order in the target epoch queue.
-			err := m.processOrder(s.rec, orderEpoch, notifyChan, s.errChan)
+			var err error
+			if s.batch != nil {
+				err = m.processOrders(s.batch, orderEpoch, notifyChan, s.errChan)
+			} else {
+				err = m.processOrder(s.rec, orderEpoch, notifyChan, s.errChan)
+			}
 			if err != nil {
-				log.Errorf("Failed to process order %v: %v", s.rec.order, err)
+				log.Errorf("Failed to process order %v: %v", recs[0].order, err)
 				// Signal to the other Run goroutines to return.
 				return
 			}
@@ -1647,6 +1769,21 @@ func (m *Market) unlockOrderCoins(o order.Order) {
 	}
 }
 
+// transferOrderCoins moves the locked coins of a replaced order to its
+// replacement.
+func (m *Market) transferOrderCoins(lo *order.LimitOrder) {
+	locker := m.coinLockerQuote
+	if lo.Sell {
+		locker = m.coinLockerBase
+	}
+	if locker == nil { // Not utxo-based
+		return
+	}
+	if !locker.TransferOrderCoins(lo.Replaces, lo.ID()) {
+		log.Errorf("Failed to transfer locked coins from replaced order %v to %v", lo.Replaces, lo)
+	}
+}
+
 // processOrder performs the following actions:
 // 1. Verify the order is new and that none of the backing coins are locked.
 // 2. Lock the order's coins.
@@ -1655,13 +1792,123 @@ func (m *Market) unlockOrderCoins(o order.Order) {
 // 5. Respond to the client that placed the order.
 // 6. Notify epoch queue event subscribers.
 func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan chan<- *updateSignal, errChan chan<- error) error {
+	ord := rec.order
+	if err := m.checkOrder(ord, epoch, nil); err != nil {
+		errChan <- err
+		return nil
+	}
+
+	// Sign the order and prepare the client response. Only after the archiver
+	// has successfully stored the new epoch order should the order be committed
+	// for processing.
+	respMsg, err := m.orderResponse(rec)
+	if err != nil {
+		log.Errorf("failed to create msgjson.Message for order %v, msgID %v response: %v",
+			ord, rec.msgID, err)
+		errChan <- ErrMalformedOrderResponse
+		return nil
+	}
+
+	if err := m.insertOrder(ord, epoch); err != nil {
+		errChan <- ErrInternalServer
+		return err
+	}
+
+	// Respond to the order router only after updating epochOrders so that
+	// Cancelable will reflect that the order is now in the epoch queue.
+	errChan <- nil
+
+	// Inform the client that the order has been received, stamped, signed, and
+	// inserted into the current epoch queue.
+	user, oid := ord.User(), ord.ID()
+	m.lazy(func() {
+		if err := m.auth.Send(user, respMsg); err != nil {
+			log.Infof("Failed to send signed new order response to user %v, order %v: %v",
+				user, oid, err)
+		}
+	})
+
+	// Send epoch update to epoch queue subscribers.
+	notifyChan <- &updateSignal{
+		action: epochAction,
+		data: sigDataEpochOrder{
+			order:    ord,
+			epochIdx: epoch.Epoch,
+		},
+	}
+	// With the notification sent to subscribers, this order must be included in
+	// the processing of this epoch.
+	return nil
+}
+
+// processOrders processes a batch of orders from a single multitrade request.
+// Every order is checked before any are inserted into the epoch queue, so that
+// either all of the orders are accepted, or none are. The client receives a
+// single response with the results for every order.
+func (m *Market) processOrders(recs []*orderRecord, epoch *EpochQueue, notifyChan chan<- *updateSignal, errChan chan<- error) error {
+	ords := make([]order.Order, 0, len(recs))
+	results := make([]*msgjson.OrderResult, 0, len(recs))
+	for _, rec := range recs {
+		if err := m.checkOrder(rec.order, epoch, ords); err != nil {
+			errChan <- err
+			return nil
+		}
+		ords = append(ords, rec.order)
+		results = append(results, m.orderResult(rec))
+	}
+
+	respMsg, err := msgjson.NewResponse(recs[0].msgID, results, nil)
+	if err != nil {
+		log.Errorf("failed to create msgjson.Message for %d orders, msgID %v response: %v",
+			len(recs), recs[0].msgID, err)
+		errChan <- ErrMalformedOrderResponse
+		return nil
+	}
+
+	for _, ord := range ords {
+		if err := m.insertOrder(ord, epoch); err != nil {
+			errChan <- ErrInternalServer
+			return err
+		}
+	}
+
+	errChan <- nil
+
+	user := ords[0].User()
+	m.lazy(func() {
+		if err := m.auth.Send(user, respMsg); err != nil {
+			log.Infof("Failed to send signed new order response to user %v for %d orders: %v",
+				user, len(ords), err)
+		}
+	})
+
+	for _, ord := range ords {
+		notifyChan <- &updateSignal{
+			action: epochAction,
+			data: sigDataEpochOrder{
+				order:    ord,
+				epochIdx: epoch.Epoch,
+			},
+		}
+	}
+	return nil
+}
+
+// checkOrder checks that a new order may be accepted into the epoch queue. The
+// batch orders are other orders from the same request that have already been
+// checked, but not yet inserted into the epoch queue. They are considered
+// along with the user's epoch orders. The returned error is intended for the
+// client.
+func (m *Market) checkOrder(ord order.Order, epoch *EpochQueue, batch []order.Order) error {
+	oid := ord.ID()
+	user := ord.User()
+
 	// Disallow trade orders from suspended accounts. Cancel orders are allowed.
-	if rec.order.Type() != order.CancelOrderType {
+	if ord.Type() != order.CancelOrderType {
 		// Do not bother the auth manager for cancel orders.
-		if _, suspended := m.auth.Suspended(rec.order.User()); suspended {
-			log.Debugf("Account %v not allowed to submit order %v", rec.order.User(), rec.order.ID())
-			errChan <- ErrSuspendedAccount
-			return nil
+		if _, suspended := m.auth.Suspended(user); suspended {
+			log.Debugf("Account %v not allowed to submit order %v", user, oid)
+			return ErrSuspendedAccount
 		}
 	}
 
@@ -1669,18 +1916,37 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 	// queue. Since commitment is part of the order serialization and thus order
 	// ID, this also prevents orders with the same ID.
 	// TODO: Prevent commitment reuse in general, without expensive DB queries.
-	ord := rec.order
-	oid := ord.ID()
-	user := ord.User()
 	commit := ord.Commitment()
 	m.epochMtx.RLock()
 	otherOid, found := m.epochCommitments[commit]
 	m.epochMtx.RUnlock()
+	for _, batchOrd := range batch {
+		if batchOrd.Commitment() == commit {
+			otherOid, found = batchOrd.ID(), true
+		}
+	}
 	if found {
 		log.Debugf("Received order %v with commitment %x also used in previous order %v!",
 			oid, commit, otherOid)
-		errChan <- ErrInvalidCommitment
-		return nil
+		return ErrInvalidCommitment
+	}
+
+	// A replacement order must target one of the user's unfilled book orders.
+	// The replacement reuses the target's locked coins.
+	var replaced *order.LimitOrder
+	if lo, ok := ord.(*order.LimitOrder); ok && lo.IsReplacement() {
+		var err error
+		if replaced, err = m.checkReplacement(lo, epoch); err != nil {
+			return err
+		}
+	}
+
+	// A good-til-time order must be able to rest on the book for at least one
+	// epoch after the one in which it is matched.
+	if lo, ok := ord.(*order.LimitOrder); ok && lo.Force == order.GoodTilTimeTiF &&
+		lo.ExpiryEpoch <= uint64(epoch.Epoch) {
+		log.Debugf("Received order %v with expiry epoch %d in epoch %d.", oid, lo.ExpiryEpoch, epoch.Epoch)
+		return ErrInvalidExpiry
 	}
 
 	// Whether an order is a taker depends on type, and for limit orders it
@@ -1728,14 +1994,22 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 	// taker settling amount limits.
 	var userStandingEpochQty, userTakerEpochQty uint64
 	m.epochMtx.RLock()
+	userEpochOrders := make([]order.Order, 0, len(batch))
 	for _, epOrd := range m.epochOrders {
-		if epOrd.User() != user || epOrd.Type() == order.CancelOrderType {
+		if epOrd.User() == user {
+			userEpochOrders = append(userEpochOrders, epOrd)
+		}
+	}
+	m.epochMtx.RUnlock()
+	for _, epOrd := range append(userEpochOrders, batch...) {
+		if epOrd.Type() == order.CancelOrderType {
 			continue
 		}
 
 		// For purposes of the user's book qty limit, assumed standing limits
-		// will be booked.
-		if lo, ok := epOrd.(*order.LimitOrder); ok && lo.Force == order.StandingTiF {
+		// will be booked. The full quantity of iceberg orders is counted, not
+		// just the displayed quantity.
+		if lo, ok := epOrd.(*order.LimitOrder); ok && lo.Force != order.ImmediateTiF {
 			userStandingEpochQty += lo.Quantity
 		}
 
@@ -1744,20 +2018,22 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 			userTakerEpochQty += baseQty(epOrd)
 		}
 	}
-	m.epochMtx.RUnlock()
 
 	// Now that epoch orders are considered, check this candidate order.
-	if lo, ok := ord.(*order.LimitOrder); ok && lo.Force == order.StandingTiF {
-		// Check the user's current booked amount.
+	if lo, ok := ord.(*order.LimitOrder); ok && lo.Force != order.ImmediateTiF {
+		// Check the user's current booked amount. A replaced order will not
+		// remain on the book.
 		bookedBuyAmt, bookedSellAmt, _, _ := m.book.UserOrderTotals(user)
 		bookedAmt := bookedBuyAmt + bookedSellAmt + userStandingEpochQty
+		if replaced != nil && bookedAmt >= replaced.Remaining() {
+			bookedAmt -= replaced.Remaining()
+		}
 		qty := lo.Quantity
 		if (qty+bookedAmt)/m.marketInfo.LotSize > uint64(m.marketInfo.BookedLotLimit) {
 			log.Debugf("Rejecting user %v order %v: too much in booked orders", user, oid)
-			errChan <- dex.NewError(ErrQuantityTooHigh,
+			return dex.NewError(ErrQuantityTooHigh,
 				fmt.Sprintf("Order quantity %d (%d lots) too large. User book limit is %d lots, and you have %d lots booked already).",
 					qty, qty/m.marketInfo.LotSize, uint64(m.marketInfo.BookedLotLimit), bookedAmt/m.marketInfo.LotSize))
-			return nil
 		}
 	}
 
@@ -1768,15 +2044,18 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 		if eco := epoch.CancelTargets[co.TargetOrderID]; eco != nil {
 			log.Debugf("Received cancel order %v targeting %v, but already have %v.",
 				co, co.TargetOrderID, eco)
-			errChan <- ErrDuplicateCancelOrder
-			return nil
+			return ErrDuplicateCancelOrder
+		}
+		if ero := epoch.ReplaceTargets[co.TargetOrderID]; ero != nil {
+			log.Debugf("Received cancel order %v targeting %v, but already have replacement %v.",
+				co, co.TargetOrderID, ero)
+			return ErrDuplicateReplaceOrder
 		}
 
 		if nc := epoch.UserCancels[co.AccountID]; nc >= m.marketInfo.MaxUserCancelsPerEpoch {
 			log.Debugf("Received cancel order %v targeting %v, but user already has %d cancel orders in this epoch.",
 				co, co.TargetOrderID, nc)
-			errChan <- ErrTooManyCancelOrders
-			return nil
+			return ErrTooManyCancelOrders
 		}
 
 		// Verify that the target order is on the books or in the epoch queue,
@@ -1786,8 +2065,7 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 		if !cancelable {
 			log.Debugf("Cancel order %v (account=%v) target order %v: %v",
 				co, co.AccountID, co.TargetOrderID, err)
-			errChan <- err
-			return nil
+			return err
 		}
 	} else if likelyTaker(ord) { // Likely-taker trade order. Check the quantity against user's limit.
 		// NOTE: We can entirely change this so that the taker limit is not
@@ -1818,37 +2096,82 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 			log.Infof("Rejecting user %v likely-taker order %v: qty %d > %d allowed "+
 				"(already have %d swapping and %d epoch takers with %d limit)",
 				user, oid, qty, orderQtyAllowed, amtInSwaps, userTakerEpochQty, userLimit)
-			errChan <- dex.NewError(ErrQuantityTooHigh,
+			return dex.NewError(ErrQuantityTooHigh,
 				fmt.Sprintf("Order quantity %d too large. Current likely-taker order limit: %d "+
 					"(you have %d settling already and %d in epoch taker orders)",
 					qty, orderQtyAllowed, amtInSwaps, userTakerEpochQty))
-			return nil
 		}
 	}
 
-	// Sign the order and prepare the client response. Only after the archiver
-	// has successfully stored the new epoch order should the order be committed
-	// for processing.
-	respMsg, err := m.orderResponse(rec)
-	if err != nil {
-		log.Errorf("failed to create msgjson.Message for order %v, msgID %v response: %v",
-			ord, rec.msgID, err)
-		errChan <- ErrMalformedOrderResponse
+	// Ensure that the received order does not use locked coins, including
+	// coins used by other orders in the batch. A replacement's coins are
+	// locked by its target, and were checked by checkReplacement.
+	if replaced != nil {
 		return nil
 	}
-
-	// Ensure that the received order does not use locked coins.
 	if lockedCoins, assetID := m.coinsLocked(ord); len(lockedCoins) > 0 {
 		log.Debugf("processOrder: Order %v submitted with already-locked %s coins: %v",
 			ord, strings.ToUpper(dex.BipIDSymbol(assetID)), fmtCoinIDs(assetID, lockedCoins))
-		errChan <- ErrInvalidOrder
-		return nil
+		return ErrInvalidOrder
+	}
+	if ord.Type() != order.CancelOrderType {
+		for _, batchOrd := range batch {
+			if batchOrd.Trade().Sell != ord.Trade().Sell {
+				continue
+			}
+			for _, coinID := range ord.Trade().Coins {
+				for _, batchCoinID := range batchOrd.Trade().Coins {
+					if bytes.Equal(coinID, batchCoinID) {
+						log.Debugf("processOrder: Order %v shares coin %v with order %v in the same batch",
+							ord, coinID, batchOrd)
+						return ErrInvalidOrder
+					}
+				}
+			}
+		}
 	}
 
+	return nil
+}
+
+// checkReplacement checks that a replacement order may replace its target, which
+// must be one of the user's unfilled book orders, and that the target is not
+// already canceled or replaced by another order in the epoch. The target order
+// is returned.
+func (m *Market) checkReplacement(lo *order.LimitOrder, epoch *EpochQueue) (*order.LimitOrder, error) {
+	target := m.book.Order(lo.Replaces)
+	if target == nil {
+		log.Debugf("Replacement order %v target order %v not booked.", lo, lo.Replaces)
+		return nil, ErrTargetNotActive
+	}
+	if err := order.ValidateReplacement(lo, target); err != nil {
+		log.Debugf("Replacement order %v rejected: %v", lo, err)
+		return nil, ErrInvalidReplacement
+	}
+	if eco := epoch.CancelTargets[lo.Replaces]; eco != nil {
+		log.Debugf("Received replacement order %v targeting %v, but already have cancel %v.",
+			lo, lo.Replaces, eco)
+		return nil, ErrDuplicateReplaceOrder
+	}
+	if ero := epoch.ReplaceTargets[lo.Replaces]; ero != nil {
+		log.Debugf("Received replacement order %v targeting %v, but already have replacement %v.",
+			lo, lo.Replaces, ero)
+		return nil, ErrDuplicateReplaceOrder
+	}
+	return target, nil
+}
+
+// insertOrder locks the coins of a checked order, stores it, and inserts it
+// into the epoch queue.
+func (m *Market) insertOrder(ord order.Order, epoch *EpochQueue) error {
 	// For market and limit orders, lock the backing coins NOW so orders using
 	// locked coins cannot get into the epoch queue. Later, in processReadyEpoch
-	// or the Swapper, release these coins when the swap is completed.
-	m.lockOrderCoins(ord)
+	// or the Swapper, release these coins when the swap is completed. The
+	// coins of a replacement order remain locked by its target until the
+	// replacement is matched.
+	if lo, ok := ord.(*order.LimitOrder); !ok || !lo.IsReplacement() {
+		m.lockOrderCoins(ord)
+	}
 
 	// Check for known orders in the DB with the same Commitment.
 	//
@@ -1872,7 +2195,6 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 	// Store the new epoch order BEFORE inserting it into the epoch queue,
 	// initiating the swap, and notifying book subscribers.
 	if err := m.storage.NewEpochOrder(ord, epoch.Epoch, epoch.Duration); err != nil {
-		errChan <- ErrInternalServer
 		return fmt.Errorf("processOrder: Failed to store new epoch order %v: %w",
 			ord, err)
 	}
@@ -1881,33 +2203,10 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 	epoch.Insert(ord)
 
 	m.epochMtx.Lock()
-	m.epochOrders[oid] = ord
-	m.epochCommitments[commit] = oid
+	m.epochOrders[ord.ID()] = ord
+	m.epochCommitments[ord.Commitment()] = ord.ID()
 	m.epochMtx.Unlock()
 
-	// Respond to the order router only after updating epochOrders so that
-	// Cancelable will reflect that the order is now in the epoch queue.
-	errChan <- nil
-
-	// Inform the client that the order has been received, stamped, signed, and
-	// inserted into the current epoch queue.
-	m.lazy(func() {
-		if err := m.auth.Send(user, respMsg); err != nil {
-			log.Infof("Failed to send signed new order response to user %v, order %v: %v",
-				user, oid, err)
-		}
-	})
-
-	// Send epoch update to epoch queue subscribers.
-	notifyChan <- &updateSignal{
-		action: epochAction,
-		data: sigDataEpochOrder{
-			order:    ord,
-			epochIdx: epoch.Epoch,
-		},
-	}
-	// With the notification sent to subscribers, this order must be included in
-	// the processing of this epoch.
 	return nil
 }
 
@@ -2183,7 +2482,7 @@ func (m *Market) UnbookUserOrders(user account.AccountID) {
 	sellIDs := make([]order.OrderID, 0, len(removedSells))
 	for _, lo := range removedSells {
 		sellIDs = append(sellIDs, lo.ID())
-		m.unbookedOrder(lo)
+		m.unbookedOrder(lo, false)
 	}
 	if m.coinLockerBase != nil {
 		m.coinLockerBase.UnlockOrdersCoins(sellIDs)
@@ -2192,13 +2491,69 @@ func (m *Market) UnbookUserOrders(user account.AccountID) {
 	buyIDs := make([]order.OrderID, 0, len(removedBuys))
 	for _, lo := range removedBuys {
 		buyIDs = append(buyIDs, lo.ID())
-		m.unbookedOrder(lo)
+		m.unbookedOrder(lo, false)
 	}
 	if m.coinLockerQuote != nil {
 		m.coinLockerQuote.UnlockOrdersCoins(buyIDs)
 	}
 }
 
+// UnbookAccountOrders unbooks all of a user's standing orders without
+// penalizing the user. This is like UnbookUserOrders, except that the orders
+// are revoked in the DB with RevokeOrderUncounted so that the revocations do not
+// count against the user's cancellation rate, and settling swaps keep their
+// order completion credit. This is intended for operator intervention, such as
+// when a user reports a compromised trading bot. The unbooked orders are
+// returned.
+func (m *Market) UnbookAccountOrders(user account.AccountID) []*order.LimitOrder {
+	m.bookMtx.Lock()
+	removedBuys, removedSells := m.book.RemoveUserOrders(user)
+	removed := make([]*order.LimitOrder, 0, len(removedBuys)+len(removedSells))
+	removed = append(append(removed, removedBuys...), removedSells...)
+	for _, lo := range removed {
+		if oid := lo.ID(); m.settling[oid] == 0 {
+			delete(m.settling, oid)
+		}
+	}
+	m.bookMtx.Unlock()
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	log.Infof("Operator unbooked %d orders (%d buys, %d sells) from market %v for user %v.",
+		len(removed), len(removedBuys), len(removedSells), m.marketInfo.Name, user)
+
+	for _, lo := range removed {
+		m.unlockOrderCoins(lo)
+		m.unbookedOrder(lo, true)
+	}
+	return removed
+}
+
+// UnbookOrder unbooks the specified standing order without penalizing the
+// owner. See UnbookAccountOrders. The unbooked order is returned, or nil if
+// the order was not on the book.
+func (m *Market) UnbookOrder(oid order.OrderID) *order.LimitOrder {
+	m.bookMtx.Lock()
+	lo, removed := m.book.Remove(oid)
+	if removed && m.settling[oid] == 0 {
+		delete(m.settling, oid)
+	}
+	m.bookMtx.Unlock()
+
+	if !removed {
+		return nil
+	}
+
+	log.Infof("Operator unbooked order %v from market %v for user %v.",
+		oid, m.marketInfo.Name, lo.User())
+
+	m.unlockOrderCoins(lo)
+	m.unbookedOrder(lo, true)
+	return lo
+}
+
 // Unbook allows the DEX manager to remove a booked order. This does: (1) remove
 // the order from the in-memory book, (2) unlock funding order coins, (3) set
 // the order's status in the DB to "revoked", (4) inform the auth manager of the
@@ -2217,23 +2572,41 @@ func (m *Market) Unbook(lo *order.LimitOrder) bool {
 
 	if removed {
 		// Update the order status in DB, and notify orderbook subscribers.
-		m.unbookedOrder(lo)
+		m.unbookedOrder(lo, false)
 	}
 	return removed
 }
 
-func (m *Market) unbookedOrder(lo *order.LimitOrder) {
-	// Create the server-generated cancel order, and register it with the
-	// AuthManager for cancellation rate computation if still connected.
+// unbookedOrder revokes the unbooked order in the DB and notifies the owner
+// and the order book subscribers. If exempt is true, the revocation is not
+// counted toward the user's cancellation rate.
+func (m *Market) unbookedOrder(lo *order.LimitOrder, exempt bool) {
 	oid, user := lo.ID(), lo.User()
-	coid, revTime, err := m.storage.RevokeOrder(lo)
-	if err == nil {
-		m.auth.RecordCancel(user, coid, oid, revTime)
+	if exempt {
+		if _, _, err := m.storage.RevokeOrderUncounted(lo); err != nil {
+			log.Errorf("Failed to revoke order %v with a new cancel order: %v",
+				lo.UID(), err)
+		}
 	} else {
-		log.Errorf("Failed to revoke order %v with a new cancel order: %v",
-			lo.UID(), err)
+		// Create the server-generated cancel order, and register it with the
+		// AuthManager for cancellation rate computation if still connected.
+		coid, revTime, err := m.storage.RevokeOrder(lo)
+		if err == nil {
+			m.auth.RecordCancel(user, coid, oid, revTime)
+		} else {
+			log.Errorf("Failed to revoke order %v with a new cancel order: %v",
+				lo.UID(), err)
+		}
 	}
 
+	m.notifyUnbooked(lo)
+}
+
+// notifyUnbooked notifies the owner of a revoked order and the order book
+// subscribers that the order was unbooked.
+func (m *Market) notifyUnbooked(lo *order.LimitOrder) {
+	oid, user := lo.ID(), lo.User()
+
 	// Send revoke_order notification to order owner.
 	route := msgjson.RevokeOrderRoute
 	log.Infof("Sending a '%s' notification to %v for order %v", route, user, oid)
@@ -2279,9 +2652,30 @@ func (m *Market) getFeeRate(assetID uint32, f FeeFetcher) uint64 {
 	return rate
 }
 
+// expireBookOrders removes the good-til-time orders that have expired as of
+// the close of the specified epoch from the book. Expiry is not the user's
+// fault, so unlike a cancel or revoke, swaps that are still settling keep
+// their completion credit. The book indexes orders by expiry epoch, so the
+// whole book is not scanned. The bookMtx must be locked.
+func (m *Market) expireBookOrders(epochIdx uint64) []*order.LimitOrder {
+	expired := m.book.RemoveExpired(epochIdx)
+	for _, lo := range expired {
+		oid := lo.ID()
+		if m.settling[oid] == 0 {
+			delete(m.settling, oid)
+		}
+	}
+	if len(expired) > 0 {
+		log.Infof("Expired %d good-til-time orders from market %v at epoch %d.",
+			len(expired), m.marketInfo.Name, epochIdx)
+	}
+	return expired
+}
+
 // processReadyEpoch performs the following operations for a closed epoch that
 // has finished preimage collection via collectPreimages:
-//  1. Perform matching with the order book.
+//  1. Perform matching with the order book, and remove expired good-til-time
+//     orders from the book.
 //  2. Send book and unbook notifications to the book subscribers.
 //  3. Unlock coins with the book lock for unbooked and failed orders.
 //  4. Lock coins with the swap lock.
@@ -2322,11 +2716,26 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 	cSum := epoch.cSum
 	misses := epoch.misses
 
+	// Record the epoch queue orders for the epoch transcript before they are
+	// modified by matching.
+	queueRecords := make(map[order.OrderID]*order.TranscriptOrder, len(ordersRevealed))
+	for _, or := range ordersRevealed {
+		queueRecords[or.Order.ID()] = order.NewTranscriptOrder(or.Order)
+	}
+
 	// Perform order matching using the preimages to shuffle the queue.
 	m.bookMtx.Lock()        // allow a coherent view of book orders with (*Market).Book
 	matchTime := time.Now() // considered as the time at which matched cancel orders are executed
-	seed, matches, _, failed, doneOK, partial, booked, nomatched, unbooked, updates, stats := m.matcher.Match(m.book, ordersRevealed)
+	booker := newTranscriptBooker(m.book)
+	seed, matches, passed, failed, doneOK, partial, booked, nomatched, unbooked, updates, stats := m.matcher.Match(booker, ordersRevealed)
 	m.bookEpochIdx = epoch.Epoch + 1
+	// The coins of replaced orders are now locked by their replacements. This
+	// must be done before the coins of unbooked orders are unlocked.
+	for _, or := range passed {
+		if lo, ok := or.Order.(*order.LimitOrder); ok && lo.IsReplacement() {
+			m.transferOrderCoins(lo)
+		}
+	}
 	var canceled []order.OrderID
 	for _, ms := range matches {
 		// Set the epoch ID.
@@ -2351,6 +2760,8 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		// there is no completion credit on a canceled order.
 		delete(m.settling, oid)
 	}
+	// Remove the good-til-time orders that expire with this epoch.
+	expired := m.expireBookOrders(uint64(epoch.Epoch))
 	m.bookMtx.Unlock()
 
 	if len(ordersRevealed) > 0 {
@@ -2374,6 +2785,34 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		oidsMissed = append(oidsMissed, om.ID())
 	}
 
+	// The transcript has the queue in its shuffled order.
+	var transcript *order.EpochTranscript
+	if len(ordersRevealed) > 0 || len(misses) > 0 {
+		transcript = &order.EpochTranscript{
+			Base:  m.marketInfo.Base,
+			Quote: m.marketInfo.Quote,
+			Epoch: order.EpochID{
+				Idx: uint64(epoch.Epoch),
+				Dur: uint64(epoch.Duration),
+			},
+			LotSize:   m.book.LotSize(),
+			CSum:      cSum,
+			Seed:      seed,
+			Queue:     make([]*order.TranscriptOrder, 0, len(ordersRevealed)),
+			Preimages: make([]order.Preimage, 0, len(ordersRevealed)),
+			Misses:    make([]*order.TranscriptOrder, 0, len(misses)),
+			Makers:    booker.makers,
+			Matches:   transcriptMatches(matches),
+		}
+		for _, or := range ordersRevealed {
+			transcript.Queue = append(transcript.Queue, queueRecords[or.Order.ID()])
+			transcript.Preimages = append(transcript.Preimages, or.Preimage)
+		}
+		for _, om := range misses {
+			transcript.Misses = append(transcript.Misses, order.NewTranscriptOrder(om))
+		}
+	}
+
 	err := m.storage.InsertEpoch(&db.EpochResults{
 		MktBase:        m.marketInfo.Base,
 		MktQuote:       m.marketInfo.Quote,
@@ -2396,6 +2835,7 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		LowRate:        stats.LowRate,
 		StartRate:      stats.StartRate,
 		EndRate:        stats.EndRate,
+		Transcript:     transcript,
 	})
 	if err != nil {
 		// fatal backend error, do not begin new swaps.
@@ -2441,6 +2881,19 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 			return
 		}
 	}
+	// Orders replaced by a replacement order. These are not counted as
+	// cancellations against the user.
+	for _, lo := range updates.TradesReplaced {
+		if err = m.storage.CancelOrder(lo); err != nil {
+			return
+		}
+	}
+	// Expired good-til-time orders.
+	for _, lo := range expired {
+		if err = m.storage.ExpireOrder(lo); err != nil {
+			return
+		}
+	}
 	// Failed orders refer to epoch queue orders that are unmatched&unbooked, or
 	// had a bad lot size.
 	for _, ord := range updates.TradesFailed {
@@ -2504,6 +2957,9 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 	for _, ubo := range unbooked {
 		m.unlockOrderCoins(ubo)
 	}
+	for _, lo := range expired {
+		m.unlockOrderCoins(lo)
+	}
 
 	// Send "book" notifications to order book subscribers.
 	for _, ord := range booked {
@@ -2540,12 +2996,29 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		}
 		notifyChan <- sig
 	}
+	for _, lo := range expired {
+		notifyChan <- &updateSignal{
+			action: unbookAction,
+			data: sigDataUnbookedOrder{
+				order:    lo,
+				epochIdx: epoch.Epoch,
+			},
+		}
+	}
 
-	// Send "nomatch" notifications.
+	// Send "nomatch" notifications. A replacement order that failed is flagged
+	// so the client knows that its target order is still booked.
+	failedReplacements := make(map[order.OrderID]bool)
+	for _, fo := range failed {
+		if lo, ok := fo.Order.(*order.LimitOrder); ok && lo.IsReplacement() {
+			failedReplacements[lo.ID()] = true
+		}
+	}
 	for _, ord := range nomatched {
 		oid := ord.Order.ID()
 		msg, err := msgjson.NewNotification(msgjson.NoMatchRoute, &msgjson.NoMatch{
 			OrderID: oid[:],
+			Failed:  failedReplacements[oid],
 		})
 		if err != nil {
 			// This is probably impossible in practice, but we'll log it anyway.
@@ -2563,6 +3036,11 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		log.Errorf("Error updating API data collector: %v", err)
 	}
 
+	// Update the metrics.
+	metrics.EpochQueueSize.Set(float64(stats.QueueSize), m.marketInfo.Name)
+	metrics.Matches.Add(float64(stats.Matches), m.marketInfo.Name)
+	metrics.EpochProcessingSeconds.Observe(time.Since(epoch.End).Seconds(), m.marketInfo.Name)
+
 	// Send "epoch_report" notifications.
 	notifyChan <- &updateSignal{
 		action: epochReportAction,
@@ -2603,6 +3081,12 @@ func (m *Market) validateOrder(ord order.Order) error {
 // orderResponse signs the order data and prepares the OrderResult to be sent to
 // the client.
 func (m *Market) orderResponse(oRecord *orderRecord) (*msgjson.Message, error) {
+	// Encode the order response as a message for the client.
+	return msgjson.NewResponse(oRecord.msgID, m.orderResult(oRecord), nil)
+}
+
+// orderResult signs the order data and prepares the OrderResult.
+func (m *Market) orderResult(oRecord *orderRecord) *msgjson.OrderResult {
 	// Add the server timestamp.
 	stamp := uint64(oRecord.order.Time())
 	oRecord.req.Stamp(stamp)
@@ -2612,14 +3096,11 @@ func (m *Market) orderResponse(oRecord *orderRecord) (*msgjson.Message, error) {
 
 	// Prepare the OrderResult, including the server signature and time stamp.
 	oid := oRecord.order.ID()
-	res := &msgjson.OrderResult{
+	return &msgjson.OrderResult{
 		Sig:        oRecord.req.SigBytes(),
 		OrderID:    oid[:],
 		ServerTime: stamp,
 	}
-
-	// Encode the order response as a message for the client.
-	return msgjson.NewResponse(oRecord.msgID, res, nil)
 }
 
 // SetFeeRateScale sets a swap fee scale factor for the given asset.
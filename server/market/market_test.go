@@ -39,9 +39,12 @@ type TArchivist struct {
 	commitForKnownOrder  order.Commitment
 	bookedOrders         []*order.LimitOrder
 	canceledOrders       []*order.LimitOrder
+	expiredOrders        []*order.LimitOrder
 	archivedCancels      []*order.CancelOrder
 	epochInserted        chan struct{}
+	transcript           *order.EpochTranscript
 	revoked              order.Order
+	revokedUncounted     []order.Order
 }
 
 func (ta *TArchivist) Close() error           { return nil }
@@ -130,6 +133,9 @@ func (ta *TArchivist) failOnEpochOrder(ord order.Order) {
 	ta.mtx.Unlock()
 }
 func (ta *TArchivist) InsertEpoch(ed *db.EpochResults) error {
+	ta.mtx.Lock()
+	ta.transcript = ed.Transcript
+	ta.mtx.Unlock()
 	if ta.epochInserted != nil { // the test wants to know
 		ta.epochInserted <- struct{}{}
 	}
@@ -150,11 +156,20 @@ func (ta *TArchivist) CancelOrder(lo *order.LimitOrder) error {
 	}
 	return nil
 }
+func (ta *TArchivist) ExpireOrder(lo *order.LimitOrder) error {
+	ta.mtx.Lock()
+	defer ta.mtx.Unlock()
+	ta.expiredOrders = append(ta.expiredOrders, lo)
+	return nil
+}
 func (ta *TArchivist) RevokeOrder(ord order.Order) (order.OrderID, time.Time, error) {
 	ta.revoked = ord
 	return ord.ID(), time.Now(), nil
 }
-func (ta *TArchivist) RevokeOrderUncounted(order.Order) (order.OrderID, time.Time, error) {
+func (ta *TArchivist) RevokeOrderUncounted(ord order.Order) (order.OrderID, time.Time, error) {
+	ta.mtx.Lock()
+	defer ta.mtx.Unlock()
+	ta.revokedUncounted = append(ta.revokedUncounted, ord)
 	return order.OrderID{}, time.Now(), nil
 }
 func (ta *TArchivist) SetOrderCompleteTime(ord order.Order, compTime int64) error { return nil }
@@ -948,6 +963,17 @@ func TestMarket_Run(t *testing.T) {
 	// and for matching to complete (in processReadyEpoch).
 	<-storage.epochInserted
 
+	// The epoch transcript has the order and its preimage.
+	storage.mtx.Lock()
+	transcript := storage.transcript
+	storage.mtx.Unlock()
+	if transcript == nil || len(transcript.Queue) != 1 || len(transcript.Preimages) != 1 {
+		t.Fatalf("epoch transcript not recorded with the queued order")
+	}
+	if transcript.Queue[0].ID != oRecord.order.ID() || transcript.Preimages[0] != pi {
+		t.Fatalf("wrong order or preimage in epoch transcript")
+	}
+
 	// Submit an immediate taker sell (taker) over user taker limit
 	piSell := test.RandomPreimage()
 	commitSell := piSell.Commit()
@@ -1471,6 +1497,320 @@ func TestMarket_enqueueEpoch(t *testing.T) {
 	cancel()
 }
 
+func TestMarket_ExpireGoodTilTime(t *testing.T) {
+	mkt, storage, _, cleanup, err := newTestMarket()
+	if err != nil {
+		t.Fatalf("Failed to create test market: %v", err)
+	}
+	defer cleanup()
+
+	var epochIdx, epochDur int64 = 123413513, int64(mkt.marketInfo.EpochDuration)
+
+	newGTT := func(writer *test.Writer, rate uint64, expiry int64) *order.LimitOrder {
+		lo := makeLO(writer, rate, 1, order.GoodTilTimeTiF)
+		lo.ExpiryEpoch = uint64(expiry)
+		return lo
+	}
+	expiredBuy := newGTT(buyer3, mkRate3(0.8, 1.0), epochIdx)
+	expiredSell := newGTT(seller3, mkRate3(1.0, 1.2), epochIdx-1)
+	unexpired := newGTT(seller3, mkRate3(1.0, 1.2), epochIdx+1)
+	standing := makeLO(buyer3, mkRate3(0.8, 1.0), 1, order.StandingTiF)
+	for _, lo := range []*order.LimitOrder{expiredBuy, expiredSell, unexpired, standing} {
+		if !mkt.book.Insert(lo) {
+			t.Fatalf("Failed to Insert order into book.")
+		}
+	}
+
+	ready := make(chan struct{})
+	close(ready)
+	notifyChan := make(chan *updateSignal, 32)
+	mkt.processReadyEpoch(&readyEpoch{
+		EpochQueue: NewEpoch(epochIdx, epochDur),
+		ready:      ready,
+	}, notifyChan)
+	close(notifyChan)
+
+	unbooked := make(map[order.OrderID]bool)
+	for sig := range notifyChan {
+		if sig.action != unbookAction {
+			continue
+		}
+		sigData := sig.data.(sigDataUnbookedOrder)
+		if sigData.epochIdx != epochIdx {
+			t.Errorf("unbook signal has epoch index %d, expected %d", sigData.epochIdx, epochIdx)
+		}
+		unbooked[sigData.order.ID()] = true
+	}
+	if len(unbooked) != 2 || !unbooked[expiredBuy.ID()] || !unbooked[expiredSell.ID()] {
+		t.Fatalf("wrong unbooked orders: %v", unbooked)
+	}
+
+	for _, lo := range []*order.LimitOrder{expiredBuy, expiredSell} {
+		if mkt.book.HaveOrder(lo.ID()) {
+			t.Errorf("expired order %v still booked", lo.ID())
+		}
+	}
+	for _, lo := range []*order.LimitOrder{unexpired, standing} {
+		if !mkt.book.HaveOrder(lo.ID()) {
+			t.Errorf("order %v unbooked", lo.ID())
+		}
+	}
+
+	storage.mtx.Lock()
+	numExpired := len(storage.expiredOrders)
+	storage.mtx.Unlock()
+	if numExpired != 2 {
+		t.Fatalf("expected 2 expired orders stored, got %d", numExpired)
+	}
+}
+
+func TestMarket_UnbookOrder(t *testing.T) {
+	mkt, storage, auth, cleanup, err := newTestMarket()
+	if err != nil {
+		t.Fatalf("Failed to create test market: %v", err)
+	}
+	defer cleanup()
+
+	buy1 := makeLO(buyer3, mkRate3(0.8, 1.0), 1, order.StandingTiF)
+	buy2 := makeLO(buyer3, mkRate3(0.8, 1.0), 2, order.StandingTiF)
+	sell := makeLO(seller3, mkRate3(1.0, 1.2), 1, order.StandingTiF)
+	for _, lo := range []*order.LimitOrder{buy1, buy2, sell} {
+		if !mkt.book.Insert(lo) {
+			t.Fatalf("Failed to Insert order into book.")
+		}
+	}
+
+	feed := mkt.OrderFeed()
+	unbooked := make(chan order.OrderID, 3)
+	go func() {
+		for sig := range feed {
+			if sig.action != unbookAction {
+				t.Errorf("wrong signal action %v", sig.action)
+				continue
+			}
+			unbooked <- sig.data.(sigDataUnbookedOrder).order.ID()
+		}
+	}()
+	defer mkt.FeedDone(feed)
+
+	checkUnbooked := func(los ...*order.LimitOrder) {
+		t.Helper()
+		for _, lo := range los {
+			select {
+			case oid := <-unbooked:
+				if oid != lo.ID() {
+					t.Fatalf("unbook signal for order %v, expected %v", oid, lo.ID())
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("no unbook signal for order %v", lo.ID())
+			}
+			if mkt.book.HaveOrder(lo.ID()) {
+				t.Fatalf("order %v still booked", lo.ID())
+			}
+			revokeNtfn := auth.getSend()
+			if revokeNtfn == nil || revokeNtfn.Route != msgjson.RevokeOrderRoute {
+				t.Fatalf("no revoke_order notification sent for order %v", lo.ID())
+			}
+		}
+	}
+
+	// Unbook a specific order.
+	if lo := mkt.UnbookOrder(sell.ID()); lo != sell {
+		t.Fatalf("UnbookOrder returned %v, expected %v", lo, sell)
+	}
+	checkUnbooked(sell)
+
+	// Unbooking the order again does nothing.
+	if lo := mkt.UnbookOrder(sell.ID()); lo != nil {
+		t.Fatalf("UnbookOrder returned %v for an unbooked order", lo)
+	}
+
+	// Unbook all of the buyer's orders.
+	removed := mkt.UnbookAccountOrders(buyer3.Acct)
+	if len(removed) != 2 {
+		t.Fatalf("UnbookAccountOrders unbooked %d orders, expected 2", len(removed))
+	}
+	checkUnbooked(removed...)
+	if mkt.UnbookAccountOrders(buyer3.Acct) != nil {
+		t.Fatalf("UnbookAccountOrders unbooked orders for a user with no booked orders")
+	}
+
+	// The revocations must not count against the users.
+	if !auth.canceledOrder.IsZero() {
+		t.Fatalf("revocation recorded as a cancel for order %v", auth.canceledOrder)
+	}
+	storage.mtx.Lock()
+	numRevoked := len(storage.revokedUncounted)
+	counted := storage.revoked
+	storage.mtx.Unlock()
+	if numRevoked != 3 {
+		t.Fatalf("expected 3 uncounted revocations stored, got %d", numRevoked)
+	}
+	if counted != nil {
+		t.Fatalf("counted revocation stored for order %v", counted.ID())
+	}
+}
+
+func TestMarket_TakeBook_ReleaseBook(t *testing.T) {
+	oldMkt, storage, _, oldCleanup, err := newTestMarket()
+	if err != nil {
+		t.Fatalf("Failed to create test market: %v", err)
+	}
+	defer oldCleanup()
+
+	buy := makeLO(buyer3, mkRate3(0.8, 1.0), 1, order.StandingTiF)
+	sell := makeLO(seller3, mkRate3(1.0, 1.2), 1, order.StandingTiF)
+	for _, lo := range []*order.LimitOrder{buy, sell} {
+		if !oldMkt.book.Insert(lo) {
+			t.Fatalf("Failed to Insert order into book.")
+		}
+	}
+
+	mkt, _, auth, cleanup, err := newTestMarket(storage)
+	if err != nil {
+		t.Fatalf("Failed to create test market: %v", err)
+	}
+	defer cleanup()
+
+	// The new market takes over the old market's book.
+	mkt.TakeBook(oldMkt)
+	if oldMkt.book.BuyCount() != 0 || oldMkt.book.SellCount() != 0 {
+		t.Fatalf("orders left on the old book")
+	}
+	if !mkt.book.HaveOrder(buy.ID()) || !mkt.book.HaveOrder(sell.ID()) {
+		t.Fatalf("orders not moved to the new book")
+	}
+
+	// Releasing the book removes the orders without revoking them in storage.
+	released := mkt.ReleaseBook()
+	if len(released) != 2 {
+		t.Fatalf("released %d orders, expected 2", len(released))
+	}
+	if mkt.book.BuyCount() != 0 || mkt.book.SellCount() != 0 {
+		t.Fatalf("orders left on the released book")
+	}
+	storage.mtx.Lock()
+	numRevoked := len(storage.revokedUncounted)
+	storage.mtx.Unlock()
+	if numRevoked != 0 {
+		t.Fatalf("ReleaseBook revoked %d orders in storage", numRevoked)
+	}
+
+	// The owners and book subscribers are notified of the revocations.
+	feed := mkt.OrderFeed()
+	defer mkt.FeedDone(feed)
+	go mkt.NotifyRevoked(released)
+	for _, lo := range released {
+		select {
+		case sig := <-feed:
+			if sig.action != unbookAction || sig.data.(sigDataUnbookedOrder).order.ID() != lo.ID() {
+				t.Fatalf("wrong signal for order %v", lo.ID())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no unbook signal for order %v", lo.ID())
+		}
+		revokeNtfn := auth.getSend()
+		if revokeNtfn == nil || revokeNtfn.Route != msgjson.RevokeOrderRoute {
+			t.Fatalf("no revoke_order notification sent for order %v", lo.ID())
+		}
+	}
+}
+
+func TestMarket_Replace(t *testing.T) {
+	mkt, storage, auth, cleanup, err := newTestMarket()
+	if err != nil {
+		t.Fatalf("Failed to create test market: %v", err)
+	}
+	defer cleanup()
+	storage.canceledOrders = make([]*order.LimitOrder, 0, 1)
+
+	var epochIdx, epochDur int64 = 123413513, int64(mkt.marketInfo.EpochDuration)
+	epoch := NewEpoch(epochIdx, epochDur)
+
+	target := makeLO(seller3, mkRate3(1.0, 1.2), 2, order.StandingTiF)
+	target.Coins = []order.CoinID{randomBytes(36)}
+	if !mkt.book.Insert(target) {
+		t.Fatalf("Failed to Insert order into book.")
+	}
+	mkt.lockOrderCoins(target)
+
+	newReplacement := func(lots uint64) (*order.LimitOrder, order.Preimage) {
+		lo, pi := makeLORevealed(seller3, target.Rate+mkt.marketInfo.RateStep, lots, order.StandingTiF)
+		lo.Coins = target.Coins
+		lo.Replaces = target.ID()
+		return lo, pi
+	}
+
+	tooBig, _ := newReplacement(3)
+	if err := mkt.checkOrder(tooBig, epoch, nil); !errors.Is(err, ErrInvalidReplacement) {
+		t.Fatalf("expected ErrInvalidReplacement, got %v", err)
+	}
+
+	replacement, pi := newReplacement(1)
+	if err := mkt.checkOrder(replacement, epoch, nil); err != nil {
+		t.Fatalf("checkOrder error for replacement: %v", err)
+	}
+	epoch.Insert(replacement)
+
+	// Another replacement or a cancel of the same target is rejected.
+	dupe, _ := newReplacement(1)
+	if err := mkt.checkOrder(dupe, epoch, nil); !errors.Is(err, ErrDuplicateReplaceOrder) {
+		t.Fatalf("expected ErrDuplicateReplaceOrder for replacement, got %v", err)
+	}
+	if err := mkt.checkOrder(makeCO(seller3, target.ID()), epoch, nil); !errors.Is(err, ErrDuplicateReplaceOrder) {
+		t.Fatalf("expected ErrDuplicateReplaceOrder for cancel, got %v", err)
+	}
+	// Other orders can't use the target's coins.
+	other := makeLO(seller3, mkRate3(1.0, 1.2), 1, order.StandingTiF)
+	other.Coins = target.Coins
+	if err := mkt.checkOrder(other, epoch, nil); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder for locked coins, got %v", err)
+	}
+
+	ready := make(chan struct{})
+	close(ready)
+	notifyChan := make(chan *updateSignal, 32)
+	mkt.processReadyEpoch(&readyEpoch{
+		EpochQueue:     epoch,
+		ready:          ready,
+		ordersRevealed: []*matcher.OrderRevealed{{Order: replacement, Preimage: pi}},
+	}, notifyChan)
+	close(notifyChan)
+
+	var targetUnbooked bool
+	for sig := range notifyChan {
+		if sig.action == unbookAction {
+			targetUnbooked = sig.data.(sigDataUnbookedOrder).order.ID() == target.ID()
+		}
+	}
+	if !targetUnbooked {
+		t.Fatalf("no unbook signal for the replaced order")
+	}
+	if mkt.book.HaveOrder(target.ID()) || !mkt.book.HaveOrder(replacement.ID()) {
+		t.Fatalf("replacement did not take the place of its target on the book")
+	}
+
+	// The coins are locked by the replacement.
+	if !mkt.CoinLocked(mkt.marketInfo.Base, target.Coins[0]) {
+		t.Fatalf("coins unlocked")
+	}
+	if len(mkt.coinLockerBase.OrderCoinsLocked(replacement.ID())) != 1 ||
+		len(mkt.coinLockerBase.OrderCoinsLocked(target.ID())) != 0 {
+		t.Fatalf("coins not transferred to the replacement")
+	}
+
+	// The target is canceled, but not counted as a cancel.
+	storage.mtx.Lock()
+	canceled := storage.canceledOrders
+	storage.mtx.Unlock()
+	if len(canceled) != 1 || canceled[0].ID() != target.ID() {
+		t.Fatalf("replaced order not stored as canceled")
+	}
+	if auth.canceledOrder == target.ID() {
+		t.Fatalf("replacement recorded as a cancel")
+	}
+}
+
 func TestMarket_Cancelable(t *testing.T) {
 	// Create the market.
 	mkt, storage, auth, cleanup, err := newTestMarket()
@@ -1599,6 +1939,106 @@ func TestMarket_Cancelable(t *testing.T) {
 	wg.Wait()
 }
 
+func TestMarket_SubmitOrders(t *testing.T) {
+	mkt, _, _, cleanup, err := newTestMarket()
+	if err != nil {
+		t.Fatalf("newTestMarket failure: %v", err)
+	}
+	defer cleanup()
+
+	epochDurationMSec := int64(mkt.EpochDuration())
+	startEpochIdx := 1 + time.Now().UnixMilli()/epochDurationMSec
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mkt.Start(ctx, startEpochIdx)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	clientTimeMSec := startEpochIdx*epochDurationMSec + 10
+	aid := test.NextAccount()
+	newRecord := func(commit order.Commitment) *orderRecord {
+		limitMsg := &msgjson.LimitOrder{
+			Prefix: msgjson.Prefix{
+				AccountID:  aid[:],
+				Base:       dcrID,
+				Quote:      btcID,
+				OrderType:  msgjson.LimitOrderNum,
+				ClientTime: uint64(clientTimeMSec),
+				Commit:     commit[:],
+			},
+			Trade: msgjson.Trade{
+				Side:     msgjson.SellOrderNum,
+				Quantity: dcrLotSize,
+				Coins:    []*msgjson.Coin{},
+				Address:  btcAddr,
+			},
+			Rate: 1000 * dcrRateStep,
+			TiF:  msgjson.StandingOrderNum,
+		}
+		return &orderRecord{
+			msgID: 1,
+			req:   limitMsg,
+			order: &order.LimitOrder{
+				P: order.Prefix{
+					AccountID:  aid,
+					BaseAsset:  dcrID,
+					QuoteAsset: btcID,
+					OrderType:  order.LimitOrderType,
+					ClientTime: time.UnixMilli(clientTimeMSec),
+					Commit:     commit,
+				},
+				T: order.Trade{
+					Coins:    []order.CoinID{},
+					Sell:     true,
+					Quantity: dcrLotSize,
+					Address:  btcAddr,
+				},
+				Rate:  1000 * dcrRateStep,
+				Force: order.StandingTiF,
+			},
+		}
+	}
+
+	mkt.waitForEpochOpen()
+
+	// A batch with a reused commitment is rejected entirely.
+	pi, goodPI := test.RandomPreimage(), test.RandomPreimage()
+	commit := pi.Commit()
+	good := newRecord(goodPI.Commit())
+	err = mkt.SubmitOrders([]*orderRecord{good, newRecord(commit), newRecord(commit)})
+	if !errors.Is(err, ErrInvalidCommitment) {
+		t.Fatalf("expected ErrInvalidCommitment, got %v", err)
+	}
+	if mkt.Cancelable(good.order.ID()) {
+		t.Fatalf("order from a rejected batch is in the epoch queue")
+	}
+
+	// Cancel orders are not allowed in a batch.
+	co := &orderRecord{order: &order.CancelOrder{P: order.Prefix{OrderType: order.CancelOrderType}}}
+	if err = mkt.SubmitOrders([]*orderRecord{co}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder for a cancel order, got %v", err)
+	}
+
+	recs := []*orderRecord{newRecord(commit), good}
+	if err = mkt.SubmitOrders(recs); err != nil {
+		t.Fatalf("SubmitOrders error: %v", err)
+	}
+	for _, rec := range recs {
+		if !mkt.Cancelable(rec.order.ID()) {
+			t.Fatalf("order %v not in the epoch queue", rec.order)
+		}
+		if rec.order.Time() != recs[0].order.Time() {
+			t.Fatalf("batch orders have different time stamps")
+		}
+	}
+}
+
 func TestMarket_handlePreimageResp(t *testing.T) {
 	randomCommit := func() (com order.Commitment) {
 		rand.Read(com[:])
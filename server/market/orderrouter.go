@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"decred.org/dcrdex/dex"
@@ -42,7 +43,10 @@ type AuthManager interface {
 
 const (
 	maxClockOffset = 600_000 // milliseconds => 600 sec => 10 minutes
-	fundingTxWait  = time.Minute
+	// maxMultiTradeOrders is the maximum number of orders that may be placed
+	// with a single multitrade request.
+	maxMultiTradeOrders = 50
+	fundingTxWait       = time.Minute
 	// ZeroConfFeeRateThreshold is multiplied by the last known fee rate for an
 	// asset to attain a minimum fee rate acceptable for zero-conf funding
 	// coins.
@@ -54,6 +58,9 @@ type MarketTunnel interface {
 	// SubmitOrder submits the order to the market for insertion into the epoch
 	// queue.
 	SubmitOrder(*orderRecord) error
+	// SubmitOrders submits a batch of orders to the market for insertion into
+	// the same epoch queue. Either all of the orders are accepted, or none are.
+	SubmitOrders([]*orderRecord) error
 	// MidGap returns the mid-gap market rate, which is ths rate halfway between
 	// the best buy order and the best sell order in the order book.
 	MidGap() uint64
@@ -134,11 +141,13 @@ type FeeSource interface {
 	LastRate(assetID uint32) (feeRate uint64)
 }
 
-// OrderRouter handles the 'limit', 'market', and 'cancel' DEX routes. These
-// are authenticated routes used for placing and canceling orders.
+// OrderRouter handles the 'limit', 'market', 'multitrade', 'replace', and
+// 'cancel' DEX routes. These are authenticated routes used for placing and
+// canceling orders.
 type OrderRouter struct {
 	auth        AuthManager
 	assets      map[uint32]*asset.BackedAsset
+	tunnelsMtx  sync.RWMutex
 	tunnels     map[string]MarketTunnel
 	latencyQ    *wait.TickerQueue
 	feeSource   FeeSource
@@ -166,6 +175,8 @@ func NewOrderRouter(cfg *OrderRouterConfig) *OrderRouter {
 	}
 	cfg.AuthManager.Route(msgjson.LimitRoute, router.handleLimit)
 	cfg.AuthManager.Route(msgjson.MarketRoute, router.handleMarket)
+	cfg.AuthManager.Route(msgjson.MultiTradeRoute, router.handleMultiTrade)
+	cfg.AuthManager.Route(msgjson.ReplaceRoute, router.handleReplace)
 	cfg.AuthManager.Route(msgjson.CancelRoute, router.handleCancel)
 	return router
 }
@@ -213,37 +224,61 @@ func (r *OrderRouter) handleLimit(user account.AccountID, msg *msgjson.Message)
 		return msgjson.NewError(msgjson.RPCParseError, "error decoding 'limit' payload")
 	}
 
-	rpcErr := r.verifyAccount(user, limit.AccountID, limit)
+	if _, suspended := r.auth.Suspended(user); suspended {
+		return msgjson.NewError(msgjson.MarketNotRunningError, "suspended account %v may not submit trade orders", user)
+	}
+
+	lo, tunnel, assets, rpcErr := r.limitOrder(user, limit)
 	if rpcErr != nil {
 		return rpcErr
 	}
+	if lo.IsReplacement() {
+		return msgjson.NewError(msgjson.OrderParameterError, "replacement orders must use the '%s' route", msgjson.ReplaceRoute)
+	}
 
-	if _, suspended := r.auth.Suspended(user); suspended {
-		return msgjson.NewError(msgjson.MarketNotRunningError, "suspended account %v may not submit trade orders", user)
+	// NOTE: ServerTime is not yet set, so the order's ID, which is computed
+	// from the serialized order, is not yet valid. The Market will stamp the
+	// order on receipt, and the order ID will be valid.
+
+	oRecord := &orderRecord{
+		order: lo,
+		req:   limit,
+		msgID: msg.ID,
+	}
+
+	return r.processTrade(oRecord, tunnel, assets, limit.Coins, lo.Sell, limit.Rate, limit.RedeemSig, limit.Serialize())
+}
+
+// limitOrder validates the msgjson.LimitOrder and constructs the
+// order.LimitOrder.
+func (r *OrderRouter) limitOrder(user account.AccountID, limit *msgjson.LimitOrder) (*order.LimitOrder, MarketTunnel, *assetSet, *msgjson.Error) {
+	rpcErr := r.verifyAccount(user, limit.AccountID, limit)
+	if rpcErr != nil {
+		return nil, nil, nil, rpcErr
 	}
 
 	tunnel, assets, sell, rpcErr := r.extractMarketDetails(&limit.Prefix, &limit.Trade)
 	if rpcErr != nil {
-		return rpcErr
+		return nil, nil, nil, rpcErr
 	}
 
 	// Spare some resources if the market is closed now. Any orders that make it
 	// through to a closed market will receive a similar error from SubmitOrder.
 	if !tunnel.Running() {
-		return msgjson.NewError(msgjson.MarketNotRunningError, "market closed to new orders")
+		return nil, nil, nil, msgjson.NewError(msgjson.MarketNotRunningError, "market closed to new orders")
 	}
 
 	// Check that OrderType is set correctly
 	if limit.OrderType != msgjson.LimitOrderNum {
-		return msgjson.NewError(msgjson.OrderParameterError, "wrong order type set for limit order. wanted %d, got %d", msgjson.LimitOrderNum, limit.OrderType)
+		return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "wrong order type set for limit order. wanted %d, got %d", msgjson.LimitOrderNum, limit.OrderType)
 	}
 
 	// Check that the rate is non-zero and obeys the rate step interval.
 	if limit.Rate == 0 {
-		return msgjson.NewError(msgjson.OrderParameterError, "rate = 0 not allowed")
+		return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "rate = 0 not allowed")
 	}
 	if rateStep := tunnel.RateStep(); limit.Rate%rateStep != 0 {
-		return msgjson.NewError(msgjson.OrderParameterError, "rate (%d) not a multiple of ratestep (%d)",
+		return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "rate (%d) not a multiple of ratestep (%d)",
 			limit.Rate, rateStep)
 	}
 
@@ -254,23 +289,58 @@ func (r *OrderRouter) handleLimit(user account.AccountID, msg *msgjson.Message)
 		force = order.StandingTiF
 	case msgjson.ImmediateOrderNum:
 		force = order.ImmediateTiF
+	case msgjson.GoodTilTimeOrderNum:
+		force = order.GoodTilTimeTiF
+		if limit.ExpiryEpoch == 0 {
+			return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "good-til-time order has no expiry epoch")
+		}
 	default:
-		return msgjson.NewError(msgjson.OrderParameterError, "unknown time-in-force")
+		return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "unknown time-in-force")
+	}
+	if force != order.GoodTilTimeTiF && limit.ExpiryEpoch != 0 {
+		return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "expiry epoch is only allowed for good-til-time orders")
 	}
 
 	lotSize := tunnel.LotSize()
 	rpcErr = r.checkPrefixTrade(assets, lotSize, &limit.Prefix, &limit.Trade, true)
 	if rpcErr != nil {
-		return rpcErr
+		return nil, nil, nil, rpcErr
+	}
+
+	// Check the display quantity of an iceberg order.
+	if limit.DisplayQty > 0 {
+		if force == order.ImmediateTiF {
+			return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "display quantity not allowed for immediate orders")
+		}
+		if limit.DisplayQty%lotSize != 0 {
+			return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "display quantity (%d) not a multiple of lot size (%d)",
+				limit.DisplayQty, lotSize)
+		}
+		if limit.DisplayQty >= limit.Quantity {
+			return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "display quantity (%d) must be less than the order quantity (%d)",
+				limit.DisplayQty, limit.Quantity)
+		}
 	}
 
 	// Commitment
 	if len(limit.Commit) != order.CommitmentSize {
-		return msgjson.NewError(msgjson.OrderParameterError, "invalid commitment")
+		return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "invalid commitment")
 	}
 	var commit order.Commitment
 	copy(commit[:], limit.Commit)
 
+	// The order replaced by a replacement order.
+	var replaces order.OrderID
+	if len(limit.Replaces) > 0 {
+		if len(limit.Replaces) != order.OrderIDSize {
+			return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "invalid replaced order ID format")
+		}
+		copy(replaces[:], limit.Replaces)
+		if force == order.ImmediateTiF {
+			return nil, nil, nil, msgjson.NewError(msgjson.OrderParameterError, "immediate orders cannot be replacements")
+		}
+	}
+
 	coinIDs := make([]order.CoinID, 0, len(limit.Trade.Coins))
 	for _, coin := range limit.Trade.Coins {
 		coinID := order.CoinID(coin.ID)
@@ -294,21 +364,166 @@ func (r *OrderRouter) handleLimit(user account.AccountID, msg *msgjson.Message)
 			Quantity: limit.Quantity,
 			Address:  limit.Address,
 		},
-		Rate:  limit.Rate,
-		Force: force,
+		Rate:        limit.Rate,
+		Force:       force,
+		ExpiryEpoch: limit.ExpiryEpoch,
+		DisplayQty:  limit.DisplayQty,
+		Replaces:    replaces,
 	}
 
-	// NOTE: ServerTime is not yet set, so the order's ID, which is computed
-	// from the serialized order, is not yet valid. The Market will stamp the
-	// order on receipt, and the order ID will be valid.
+	return lo, tunnel, assets, nil
+}
 
-	oRecord := &orderRecord{
+// handleReplace is the handler for the 'replace' route. This route accepts a
+// msgjson.LimitOrder payload with the Replaces field set, constructs an
+// order.LimitOrder that replaces one of the user's standing limit orders, and
+// submits it to the epoch queue. The replacement is funded by the same coins as
+// the order it replaces, which were validated and locked when that order was
+// placed, so the funding is not checked again. The Market checks that the
+// replacement requires no more funding than its target.
+func (r *OrderRouter) handleReplace(user account.AccountID, msg *msgjson.Message) *msgjson.Error {
+	limit := new(msgjson.LimitOrder)
+	err := msg.Unmarshal(&limit)
+	if err != nil || limit == nil {
+		return msgjson.NewError(msgjson.RPCParseError, "error decoding 'replace' payload")
+	}
+
+	if _, suspended := r.auth.Suspended(user); suspended {
+		return msgjson.NewError(msgjson.MarketNotRunningError, "suspended account %v may not submit trade orders", user)
+	}
+
+	lo, tunnel, _, rpcErr := r.limitOrder(user, limit)
+	if rpcErr != nil {
+		return rpcErr
+	}
+	if !lo.IsReplacement() {
+		return msgjson.NewError(msgjson.OrderParameterError, "no replaced order ID")
+	}
+	if !tunnel.Cancelable(lo.Replaces) {
+		return msgjson.NewError(msgjson.OrderParameterError, "target order not known: %v", lo.Replaces)
+	}
+
+	return r.submitOrderToMarket(tunnel, &orderRecord{
 		order: lo,
 		req:   limit,
 		msgID: msg.ID,
+	})
+}
+
+// handleMultiTrade is the handler for the 'multitrade' route. This route
+// accepts a msgjson.MultiTrade payload, validates each of the limit orders, and
+// submits them to the epoch queue together once the funding coins for every
+// order have been found. The orders must all be for the same market.
+func (r *OrderRouter) handleMultiTrade(user account.AccountID, msg *msgjson.Message) *msgjson.Error {
+	multi := new(msgjson.MultiTrade)
+	err := msg.Unmarshal(&multi)
+	if err != nil || multi == nil {
+		return msgjson.NewError(msgjson.RPCParseError, "error decoding 'multitrade' payload")
 	}
 
-	return r.processTrade(oRecord, tunnel, assets, limit.Coins, sell, limit.Rate, limit.RedeemSig, limit.Serialize())
+	if len(multi.Orders) == 0 {
+		return msgjson.NewError(msgjson.OrderParameterError, "no orders")
+	}
+	if len(multi.Orders) > maxMultiTradeOrders {
+		return msgjson.NewError(msgjson.OrderParameterError, "too many orders. %d > %d",
+			len(multi.Orders), maxMultiTradeOrders)
+	}
+
+	if _, suspended := r.auth.Suspended(user); suspended {
+		return msgjson.NewError(msgjson.MarketNotRunningError, "suspended account %v may not submit trade orders", user)
+	}
+
+	var tunnel MarketTunnel
+	oRecords := make([]*orderRecord, 0, len(multi.Orders))
+	ords := make([]order.Order, 0, len(multi.Orders))
+	assetSets := make([]*assetSet, 0, len(multi.Orders))
+	coinLocks := make(map[string]bool)
+	for i, limit := range multi.Orders {
+		if limit == nil {
+			return msgjson.NewError(msgjson.RPCParseError, "error decoding 'multitrade' payload")
+		}
+		lo, mktTunnel, assets, rpcErr := r.limitOrder(user, limit)
+		if rpcErr != nil {
+			rpcErr.Message = fmt.Sprintf("order %d: %s", i, rpcErr.Message)
+			return rpcErr
+		}
+		if lo.IsReplacement() {
+			return msgjson.NewError(msgjson.OrderParameterError, "order %d: replacement orders must use the '%s' route",
+				i, msgjson.ReplaceRoute)
+		}
+		if tunnel == nil {
+			tunnel = mktTunnel
+		} else if lo.BaseAsset != ords[0].Base() || lo.QuoteAsset != ords[0].Quote() {
+			return msgjson.NewError(msgjson.OrderParameterError, "all orders must be for the same market")
+		}
+		// The market would reject the batch for coins shared between orders,
+		// but save the coin search.
+		for _, coinID := range lo.Coins {
+			k := fmtCoinID(assets.funding.ID, coinID)
+			if coinLocks[k] {
+				return msgjson.NewError(msgjson.FundingError, "coin %s funds more than one order", k)
+			}
+			coinLocks[k] = true
+		}
+		oRecords = append(oRecords, &orderRecord{
+			order: lo,
+			req:   limit,
+			msgID: msg.ID,
+		})
+		ords = append(ords, lo)
+		assetSets = append(assetSets, assets)
+	}
+
+	// Account-based balances are checked against the total of all orders in
+	// the batch.
+	coinCheckers := make([]func() (bool, *msgjson.Error), 0, len(oRecords))
+	for i, oRecord := range oRecords {
+		limit := multi.Orders[i]
+		checkCoins, _, rpcErr := r.checkTradeFunding(oRecord, tunnel, assetSets[i], limit.Coins,
+			ords[i].Trade().Sell, limit.Rate, limit.RedeemSig, limit.Serialize(), ords)
+		if rpcErr != nil {
+			rpcErr.Message = fmt.Sprintf("order %d: %s", i, rpcErr.Message)
+			return rpcErr
+		}
+		if checkCoins != nil {
+			coinCheckers = append(coinCheckers, checkCoins)
+		}
+	}
+
+	if len(coinCheckers) == 0 {
+		return r.submitOrdersToMarket(tunnel, oRecords)
+	}
+
+	log.Tracef("Searching for funding coins for %d new orders from user %s", len(oRecords), user)
+	r.latencyQ.Wait(&wait.Waiter{
+		Expiration: time.Now().Add(fundingTxWait),
+		TryFunc: func() wait.TryDirective {
+			for len(coinCheckers) > 0 {
+				tryAgain, msgErr := coinCheckers[0]()
+				if tryAgain {
+					return wait.TryAgain
+				}
+				if msgErr != nil {
+					r.respondError(msg.ID, user, msgErr)
+					return wait.DontTryAgain
+				}
+				coinCheckers = coinCheckers[1:] // don't check this order again
+			}
+
+			// Send the orders to the epoch queue where they will be time stamped.
+			log.Tracef("Found and validated funding coins for %d new orders from user %s", len(oRecords), user)
+			if msgErr := r.submitOrdersToMarket(tunnel, oRecords); msgErr != nil {
+				r.respondError(msg.ID, user, msgErr)
+			}
+			return wait.DontTryAgain
+		},
+		ExpireFunc: func() {
+			r.respondError(msg.ID, user, msgjson.NewError(msgjson.TransactionUndiscovered,
+				"failed to find funding coins for all orders"))
+		},
+	})
+
+	return nil
 }
 
 // handleMarket is the handler for the 'market' route. This route accepts a
@@ -399,6 +614,61 @@ func (r *OrderRouter) handleMarket(user account.AccountID, msg *msgjson.Message)
 func (r *OrderRouter) processTrade(oRecord *orderRecord, tunnel MarketTunnel, assets *assetSet,
 	coins []*msgjson.Coin, sell bool, rate uint64, redeemSig *msgjson.RedeemSig, sigMsg []byte) *msgjson.Error {
 
+	checkCoins, coinStrs, msgErr := r.checkTradeFunding(oRecord, tunnel, assets, coins, sell, rate,
+		redeemSig, sigMsg, []order.Order{oRecord.order})
+	if msgErr != nil {
+		return msgErr
+	}
+	if checkCoins == nil {
+		// Account-based funding. No coins to find.
+		return r.submitOrderToMarket(tunnel, oRecord)
+	}
+
+	fundingAsset := assets.funding
+	user := oRecord.order.User()
+
+	log.Tracef("Searching for %s coins %v for new order", fundingAsset.Symbol, coinStrs)
+	r.latencyQ.Wait(&wait.Waiter{
+		Expiration: time.Now().Add(fundingTxWait),
+		TryFunc: func() wait.TryDirective {
+			tryAgain, msgErr := checkCoins()
+			if tryAgain {
+				return wait.TryAgain
+			}
+			if msgErr != nil {
+				r.respondError(oRecord.msgID, user, msgErr)
+				return wait.DontTryAgain
+			}
+
+			// Send the order to the epoch queue where it will be time stamped.
+			log.Tracef("Found and validated %s coins %v for new order", fundingAsset.Symbol, coinStrs)
+			if msgErr := r.submitOrderToMarket(tunnel, oRecord); msgErr != nil {
+				r.respondError(oRecord.msgID, user, msgErr)
+			}
+			return wait.DontTryAgain
+		},
+		ExpireFunc: func() {
+			// Tell them to broadcast again or check their node before broadcast
+			// timeout is reached and the match is revoked.
+			r.respondError(oRecord.msgID, user, msgjson.NewError(msgjson.TransactionUndiscovered,
+				fmt.Sprintf("failed to find funding coins %v", coinStrs)))
+		},
+	})
+
+	return nil
+}
+
+// checkTradeFunding validates the funding of a trade order. Signatures and
+// account balances for account-based assets are checked immediately, with the
+// balance checked against the total of the orders in batch, which must include
+// the order being checked. For utxo-based funding, the returned function finds
+// and validates the funding coins, and must be called again later if it
+// returns tryAgain = true. The returned function is nil if the order is funded
+// by an account-based asset.
+func (r *OrderRouter) checkTradeFunding(oRecord *orderRecord, tunnel MarketTunnel, assets *assetSet,
+	coins []*msgjson.Coin, sell bool, rate uint64, redeemSig *msgjson.RedeemSig, sigMsg []byte,
+	batch []order.Order) (checkCoins func() (tryAgain bool, msgErr *msgjson.Error), coinStrs []string, _ *msgjson.Error) {
+
 	fundingAsset := assets.funding
 	user := oRecord.order.User()
 	trade := oRecord.order.Trade()
@@ -410,36 +680,36 @@ func (r *OrderRouter) processTrade(oRecord *orderRecord, tunnel MarketTunnel, as
 	if isToAccount {
 		if redeemSig == nil {
 			log.Infof("user %s did not include a RedeemSig for received asset %s", user, assets.receiving.Symbol)
-			return msgjson.NewError(msgjson.OrderParameterError, "no redeem address verification included for asset %s", assets.receiving.Symbol)
+			return nil, nil, msgjson.NewError(msgjson.OrderParameterError, "no redeem address verification included for asset %s", assets.receiving.Symbol)
 		}
 
 		acctAddr := trade.ToAccount()
 		if err := receivingBalancer.ValidateSignature(acctAddr, redeemSig.PubKey, sigMsg, redeemSig.Sig); err != nil {
 			log.Infof("user %s failed redeem signature validation for order: %v",
 				user, err)
-			return msgjson.NewError(msgjson.SignatureError, "redeem signature validation failed")
+			return nil, nil, msgjson.NewError(msgjson.SignatureError, "redeem signature validation failed")
 		}
 
-		if !r.sufficientAccountBalance(acctAddr, oRecord.order, &assets.receiving.Asset, assets.receiving.ID, tunnel) {
-			return msgjson.NewError(msgjson.FundingError, "insufficient balance")
+		if !r.sufficientAccountBalance(acctAddr, batch, &assets.receiving.Asset, assets.receiving.ID, tunnel) {
+			return nil, nil, msgjson.NewError(msgjson.FundingError, "insufficient balance")
 		}
 	}
 
-	// If the funding asset is account-based, we'll check balance and submit the
-	// order immediately, since we don't need to find coins.
+	// If the funding asset is account-based, we'll check balance now, since we
+	// don't need to find coins.
 	fundingBalancer, isAccountFunded := assets.funding.Backend.(asset.AccountBalancer)
 	if isAccountFunded {
 		// Validate that the coins are correct for an account-based-asset-funded
 		// order. There should be 1 coin, 1 sig, 1 pubkey, and no redeem script.
 		if len(coins) != 1 {
 			log.Infof("user %s submitted an %s-funded order with %d coin IDs", user, assets.funding.Symbol, len(coins))
-			return msgjson.NewError(msgjson.OrderParameterError, "account-type asset funding requires exactly one coin ID")
+			return nil, nil, msgjson.NewError(msgjson.OrderParameterError, "account-type asset funding requires exactly one coin ID")
 		}
 		acctProof := coins[0]
 		if len(acctProof.PubKeys) != 1 || len(acctProof.Sigs) != 1 || len(acctProof.Redeem) > 0 {
 			log.Infof("user %s submitted an %s-funded order with %d pubkeys, %d sigs, redeem script length %d",
 				user, assets.funding.Symbol, len(acctProof.PubKeys), len(acctProof.Sigs), len(acctProof.Redeem))
-			return msgjson.NewError(msgjson.OrderParameterError, "account-type asset funding requires exactly one coin ID")
+			return nil, nil, msgjson.NewError(msgjson.OrderParameterError, "account-type asset funding requires exactly one coin ID")
 		}
 
 		acctAddr := trade.FromAccount()
@@ -448,34 +718,34 @@ func (r *OrderRouter) processTrade(oRecord *orderRecord, tunnel MarketTunnel, as
 		if err := fundingBalancer.ValidateSignature(acctAddr, pubKey, sigMsg, sig); err != nil {
 			log.Infof("user %s failed signature validation for order: %v",
 				user, err)
-			return msgjson.NewError(msgjson.SignatureError, "signature validation failed")
+			return nil, nil, msgjson.NewError(msgjson.SignatureError, "signature validation failed")
 		}
 
-		if !r.sufficientAccountBalance(acctAddr, oRecord.order, &assets.funding.Asset, assets.receiving.ID, tunnel) {
-			return msgjson.NewError(msgjson.FundingError, "insufficient balance")
+		if !r.sufficientAccountBalance(acctAddr, batch, &assets.funding.Asset, assets.receiving.ID, tunnel) {
+			return nil, nil, msgjson.NewError(msgjson.FundingError, "insufficient balance")
 		}
-		return r.submitOrderToMarket(tunnel, oRecord)
+		return nil, nil, nil
 	}
 
 	// Funding coins are from a utxo-based asset. Need to find them.
 
 	// Validate coin IDs and prepare some strings for debug logging.
-	coinStrs := make([]string, 0, len(coins))
+	coinStrs = make([]string, 0, len(coins))
 	for _, coinID := range trade.Coins {
 		coinStr, err := fundingAsset.Backend.ValidateCoinID(coinID)
 		if err != nil {
-			return msgjson.NewError(msgjson.FundingError, fmt.Sprintf("invalid coin ID %v: %v", coinID, err))
+			return nil, nil, msgjson.NewError(msgjson.FundingError, fmt.Sprintf("invalid coin ID %v: %v", coinID, err))
 		}
 		// TODO: Check all markets here?
 		if tunnel.CoinLocked(assets.funding.ID, coinID) {
-			return msgjson.NewError(msgjson.FundingError, fmt.Sprintf("coin %s is locked", fmtCoinID(assets.funding.ID, coinID)))
+			return nil, nil, msgjson.NewError(msgjson.FundingError, fmt.Sprintf("coin %s is locked", fmtCoinID(assets.funding.ID, coinID)))
 		}
 		coinStrs = append(coinStrs, coinStr)
 	}
 
 	// Use this as a chance to check user's existing market orders.
 	// TODO: check all markets?
-	for mktName, tunnel := range r.tunnels {
+	for mktName, tunnel := range r.marketTunnels() {
 		unbookedUnfunded := tunnel.CheckUnfilled(assets.funding.ID, oRecord.order.User())
 		for _, badLo := range unbookedUnfunded {
 			log.Infof("Unbooked unfunded order %v from market %s for user %v", badLo, mktName, oRecord.order.User())
@@ -501,7 +771,7 @@ func (r *OrderRouter) processTrade(oRecord *orderRecord, tunnel MarketTunnel, as
 		neededCoins[i] = coin
 	}
 
-	checkCoins := func() (tryAgain bool, msgErr *msgjson.Error) {
+	checkCoins = func() (tryAgain bool, msgErr *msgjson.Error) {
 		for key, coin := range neededCoins {
 			// Get the coin from the backend and validate it.
 			dexCoin, err := fundingCoin(fundingAsset.Backend, coin.ID, coin.Redeem)
@@ -583,67 +853,41 @@ func (r *OrderRouter) processTrade(oRecord *orderRecord, tunnel MarketTunnel, as
 		return false, nil
 	}
 
-	log.Tracef("Searching for %s coins %v for new order", fundingAsset.Symbol, coinStrs)
-	r.latencyQ.Wait(&wait.Waiter{
-		Expiration: time.Now().Add(fundingTxWait),
-		TryFunc: func() wait.TryDirective {
-			tryAgain, msgErr := checkCoins()
-			if tryAgain {
-				return wait.TryAgain
-			}
-			if msgErr != nil {
-				r.respondError(oRecord.msgID, user, msgErr)
-				return wait.DontTryAgain
-			}
-
-			// Send the order to the epoch queue where it will be time stamped.
-			log.Tracef("Found and validated %s coins %v for new order", fundingAsset.Symbol, coinStrs)
-			if msgErr := r.submitOrderToMarket(tunnel, oRecord); msgErr != nil {
-				r.respondError(oRecord.msgID, user, msgErr)
-			}
-			return wait.DontTryAgain
-		},
-		ExpireFunc: func() {
-			// Tell them to broadcast again or check their node before broadcast
-			// timeout is reached and the match is revoked.
-			r.respondError(oRecord.msgID, user, msgjson.NewError(msgjson.TransactionUndiscovered,
-				fmt.Sprintf("failed to find funding coins %v", coinStrs)))
-		},
-	})
-
-	return nil
+	return checkCoins, coinStrs, nil
 }
 
 // sufficientAccountBalance checks that the user's account-based asset balance
-// is sufficient to support the order, considering the user's other orders and
+// is sufficient to support the orders, considering the user's other orders and
 // active matches across all DEX markets.
-func (r *OrderRouter) sufficientAccountBalance(accountAddr string, ord order.Order, assetInfo *dex.Asset, redeemAssetID uint32, tunnel MarketTunnel) bool {
+func (r *OrderRouter) sufficientAccountBalance(accountAddr string, ords []order.Order, assetInfo *dex.Asset, redeemAssetID uint32, tunnel MarketTunnel) bool {
 	assetID := assetInfo.ID
-	trade := ord.Trade()
 
 	var fundingQty, fundingLots uint64
 	var redeems int
-	if ord.Base() == assetID {
-		if trade.Sell {
-			fundingQty = trade.Quantity
-			fundingLots = trade.Quantity / tunnel.LotSize()
-		} else {
-			if lo, ok := ord.(*order.LimitOrder); ok {
-				redeems = int(calc.QuoteToBase(lo.Rate, trade.Quantity) / tunnel.LotSize())
+	for _, ord := range ords {
+		trade := ord.Trade()
+		if ord.Base() == assetID {
+			if trade.Sell {
+				fundingQty += trade.Quantity
+				fundingLots += trade.Quantity / tunnel.LotSize()
 			} else {
-				redeems = int(calc.QuoteToBase(safeMidGap(tunnel), trade.Quantity) / tunnel.LotSize())
+				if lo, ok := ord.(*order.LimitOrder); ok {
+					redeems += int(calc.QuoteToBase(lo.Rate, trade.Quantity) / tunnel.LotSize())
+				} else {
+					redeems += int(calc.QuoteToBase(safeMidGap(tunnel), trade.Quantity) / tunnel.LotSize())
+				}
 			}
-		}
-	} else {
-		if trade.Sell {
-			redeems = int(trade.Quantity / tunnel.LotSize())
 		} else {
-			if lo, ok := ord.(*order.LimitOrder); ok {
-				fundingQty = calc.BaseToQuote(lo.Rate, trade.Quantity)
-				fundingLots = trade.Quantity / tunnel.LotSize()
-			} else { // market buy
-				fundingQty = trade.Quantity
-				fundingLots = fundingQty / tunnel.LotSize()
+			if trade.Sell {
+				redeems += int(trade.Quantity / tunnel.LotSize())
+			} else {
+				if lo, ok := ord.(*order.LimitOrder); ok {
+					fundingQty += calc.BaseToQuote(lo.Rate, trade.Quantity)
+					fundingLots += trade.Quantity / tunnel.LotSize()
+				} else { // market buy
+					fundingQty += trade.Quantity
+					fundingLots += trade.Quantity / tunnel.LotSize()
+				}
 			}
 		}
 	}
@@ -653,21 +897,34 @@ func (r *OrderRouter) sufficientAccountBalance(accountAddr string, ord order.Ord
 
 func (r *OrderRouter) submitOrderToMarket(tunnel MarketTunnel, oRecord *orderRecord) *msgjson.Error {
 	if err := tunnel.SubmitOrder(oRecord); err != nil {
-		code := msgjson.UnknownMarketError
-		switch {
-		case errors.Is(err, ErrInternalServer):
-			log.Errorf("Market failed to SubmitOrder: %v", err)
-		case errors.Is(err, ErrQuantityTooHigh):
-			code = msgjson.OrderQuantityTooHigh
-			fallthrough
-		default:
-			log.Debugf("Market failed to SubmitOrder: %v", err)
-		}
-		return msgjson.NewError(code, err.Error())
+		return submissionError(err)
+	}
+	return nil
+}
+
+func (r *OrderRouter) submitOrdersToMarket(tunnel MarketTunnel, oRecords []*orderRecord) *msgjson.Error {
+	if err := tunnel.SubmitOrders(oRecords); err != nil {
+		return submissionError(err)
 	}
 	return nil
 }
 
+// submissionError converts an error from the market's order submission
+// methods to a *msgjson.Error.
+func submissionError(err error) *msgjson.Error {
+	code := msgjson.UnknownMarketError
+	switch {
+	case errors.Is(err, ErrInternalServer):
+		log.Errorf("Market failed to SubmitOrder: %v", err)
+	case errors.Is(err, ErrQuantityTooHigh):
+		code = msgjson.OrderQuantityTooHigh
+		fallthrough
+	default:
+		log.Debugf("Market failed to SubmitOrder: %v", err)
+	}
+	return msgjson.NewError(code, err.Error())
+}
+
 // Check the FundingCoin confirmations, and if zero, ensure the tx fee rate
 // is sufficient, > 90% of our last recorded estimate for the asset.
 func (r *OrderRouter) checkZeroConfs(dexCoin asset.FundingCoin, fundingAsset *asset.BackedAsset) *msgjson.Error {
@@ -791,13 +1048,47 @@ func (r *OrderRouter) extractMarket(prefix *msgjson.Prefix) (MarketTunnel, *msgj
 	if err != nil {
 		return nil, msgjson.NewError(msgjson.UnknownMarketError, "asset lookup error: "+err.Error())
 	}
-	tunnel, found := r.tunnels[mktName]
-	if !found {
+	tunnel := r.tunnel(mktName)
+	if tunnel == nil {
 		return nil, msgjson.NewError(msgjson.UnknownMarketError, "unknown market "+mktName)
 	}
 	return tunnel, nil
 }
 
+// tunnel gets the MarketTunnel for the named market, or nil if it is not
+// known.
+func (r *OrderRouter) tunnel(mktName string) MarketTunnel {
+	r.tunnelsMtx.RLock()
+	defer r.tunnelsMtx.RUnlock()
+	return r.tunnels[mktName]
+}
+
+// marketTunnels returns a copy of the MarketTunnels map.
+func (r *OrderRouter) marketTunnels() map[string]MarketTunnel {
+	r.tunnelsMtx.RLock()
+	defer r.tunnelsMtx.RUnlock()
+	tunnels := make(map[string]MarketTunnel, len(r.tunnels))
+	for name, tunnel := range r.tunnels {
+		tunnels[name] = tunnel
+	}
+	return tunnels
+}
+
+// AddMarket begins routing orders to the market. An existing MarketTunnel
+// with the same name is replaced.
+func (r *OrderRouter) AddMarket(mktName string, tunnel MarketTunnel) {
+	r.tunnelsMtx.Lock()
+	r.tunnels[mktName] = tunnel
+	r.tunnelsMtx.Unlock()
+}
+
+// RemoveMarket stops routing orders to the market.
+func (r *OrderRouter) RemoveMarket(mktName string) {
+	r.tunnelsMtx.Lock()
+	delete(r.tunnels, mktName)
+	r.tunnelsMtx.Unlock()
+}
+
 // SuspendEpoch holds the index and end time of final epoch marking the
 // suspension of a market.
 type SuspendEpoch struct {
@@ -814,8 +1105,8 @@ type SuspendEpoch struct {
 // blocking order submission according to the schedule rather than just checking
 // Market.Running prior to submitting incoming orders to the Market.
 func (r *OrderRouter) SuspendMarket(mktName string, asSoonAs time.Time, persistBooks bool) *SuspendEpoch {
-	mkt, found := r.tunnels[mktName]
-	if !found {
+	mkt := r.tunnel(mktName)
+	if mkt == nil {
 		return nil
 	}
 
@@ -829,8 +1120,9 @@ func (r *OrderRouter) SuspendMarket(mktName string, asSoonAs time.Time, persistB
 // Suspend is like SuspendMarket, but for all known markets.
 func (r *OrderRouter) Suspend(asSoonAs time.Time, persistBooks bool) map[string]*SuspendEpoch {
 
-	suspendTimes := make(map[string]*SuspendEpoch, len(r.tunnels))
-	for name, mkt := range r.tunnels {
+	tunnels := r.marketTunnels()
+	suspendTimes := make(map[string]*SuspendEpoch, len(tunnels))
+	for name, mkt := range tunnels {
 		idx, ts := mkt.Suspend(asSoonAs, persistBooks)
 		suspendTimes[name] = &SuspendEpoch{Idx: idx, End: ts}
 	}
@@ -298,6 +298,33 @@ func (m *TMarketTunnel) SubmitOrder(o *orderRecord) error {
 	return nil
 }
 
+func (m *TMarketTunnel) SubmitOrders(recs []*orderRecord) error {
+	now := nowMs()
+	results := make([]*msgjson.OrderResult, 0, len(recs))
+	for _, o := range recs {
+		o.order.SetTime(now)
+		oid := o.order.ID()
+		results = append(results, &msgjson.OrderResult{
+			Sig:        msgjson.Bytes{},
+			OrderID:    oid[:],
+			ServerTime: uint64(now.UnixMilli()),
+		})
+	}
+	m.adds = append(m.adds, recs...)
+
+	resp, _ := msgjson.NewResponse(1, results, nil)
+	err := m.auth.Send(account.AccountID{}, resp)
+	if err != nil {
+		log.Debug("Send:", err)
+	}
+
+	if m.added != nil {
+		m.added <- struct{}{}
+	}
+
+	return nil
+}
+
 func (m *TMarketTunnel) MidGap() uint64 {
 	return m.midGap
 }
@@ -849,6 +876,40 @@ func TestLimit(t *testing.T) {
 		t.Errorf("Got force %v, expected %v (immediate)", epochOrder.Force, order.ImmediateTiF)
 	}
 
+	// An expiry epoch is not allowed for immediate TiF.
+	limit.ExpiryEpoch = 1234
+	ensureErr("immediate with expiry", sendLimit(), msgjson.OrderParameterError)
+
+	// Good-til-time TiF.
+	limit.TiF = msgjson.GoodTilTimeOrderNum
+	ensureSuccess("valid good-til-time order")
+	epochOrder = oRecord.order.(*order.LimitOrder)
+	if epochOrder.Force != order.GoodTilTimeTiF || epochOrder.ExpiryEpoch != 1234 {
+		t.Errorf("Got force %v, expiry %d, expected %v, 1234 (good-til-time)",
+			epochOrder.Force, epochOrder.ExpiryEpoch, order.GoodTilTimeTiF)
+	}
+
+	// Good-til-time TiF requires an expiry epoch.
+	limit.ExpiryEpoch = 0
+	ensureErr("good-til-time without expiry", sendLimit(), msgjson.OrderParameterError)
+
+	// Iceberg order with a display quantity.
+	limit.TiF = msgjson.StandingOrderNum
+	limit.DisplayQty = 2 * dcrLotSize
+	ensureSuccess("valid iceberg order")
+	epochOrder = oRecord.order.(*order.LimitOrder)
+	if epochOrder.DisplayQty != 2*dcrLotSize || epochOrder.Visible() != 2*dcrLotSize {
+		t.Errorf("Got display quantity %d, expected %d", epochOrder.DisplayQty, 2*dcrLotSize)
+	}
+	limit.DisplayQty = dcrLotSize + 1
+	ensureErr("display quantity not lot multiple", sendLimit(), msgjson.OrderParameterError)
+	limit.DisplayQty = qty
+	ensureErr("display quantity not less than quantity", sendLimit(), msgjson.OrderParameterError)
+	limit.DisplayQty = dcrLotSize
+	limit.TiF = msgjson.ImmediateOrderNum
+	ensureErr("immediate iceberg", sendLimit(), msgjson.OrderParameterError)
+	limit.DisplayQty = 0
+
 	// Test an invalid payload.
 	msg := new(msgjson.Message)
 	msg.Payload = []byte(`?`)
@@ -1010,6 +1071,217 @@ func TestLimit(t *testing.T) {
 	ensureSuccess("enough to redeem account-based quote")
 }
 
+func TestMultiTrade(t *testing.T) {
+	const lots = 2
+	qty := uint64(dcrLotSize) * lots
+	rate := uint64(1000) * dcrRateStep
+	user := oRig.user
+	newLimit := func(base uint32, coins ...*msgjson.Coin) *msgjson.LimitOrder {
+		pi := ordertest.RandomPreimage()
+		commit := pi.Commit()
+		return &msgjson.LimitOrder{
+			Prefix: msgjson.Prefix{
+				AccountID:  user.acct[:],
+				Base:       base,
+				Quote:      btcID,
+				OrderType:  msgjson.LimitOrderNum,
+				ClientTime: uint64(nowMs().UnixMilli()),
+				Commit:     commit[:],
+			},
+			Trade: msgjson.Trade{
+				Side:     msgjson.SellOrderNum,
+				Quantity: qty,
+				Coins:    coins,
+				Address:  btcAddr,
+			},
+			Rate: rate,
+			TiF:  msgjson.StandingOrderNum,
+		}
+	}
+	newBatch := func() *msgjson.MultiTrade {
+		return &msgjson.MultiTrade{Orders: []*msgjson.LimitOrder{
+			newLimit(dcrID, oRig.signedUTXO(dcrID, 2*qty, 1)),
+			newLimit(dcrID, oRig.signedUTXO(dcrID, 2*qty, 1)),
+			newLimit(dcrID, oRig.signedUTXO(dcrID, 2*qty, 1)),
+		}}
+	}
+
+	ensureErr := makeEnsureErr(t)
+
+	oRig.auth.sent = make(chan *msgjson.Error, 1)
+	defer func() { oRig.auth.sent = nil }()
+	oRig.market.added = make(chan struct{}, 1)
+	defer func() { oRig.market.added = nil }()
+
+	sendMulti := func(multi *msgjson.MultiTrade) *msgjson.Error {
+		msg, _ := msgjson.NewRequest(5, msgjson.MultiTradeRoute, multi)
+		err := oRig.router.handleMultiTrade(user.acct, msg)
+		if err != nil {
+			return err
+		}
+		return <-oRig.auth.sent
+	}
+
+	ensureSuccess := func(tag string, multi *msgjson.MultiTrade) {
+		t.Helper()
+		ensureErr(tag, sendMulti(multi), -1)
+		select {
+		case <-oRig.market.added:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: no orders submitted to epoch", tag)
+		}
+		for i := range multi.Orders {
+			oRecord := oRig.market.pop()
+			if oRecord == nil {
+				t.Fatalf("%s: order %d not submitted to epoch", tag, i)
+			}
+			if lo := oRecord.order.(*order.LimitOrder); !bytes.Equal(lo.Commit[:], multi.Orders[i].Commit) {
+				t.Fatalf("%s: order %d submitted out of order", tag, i)
+			}
+		}
+		if oRig.market.pop() != nil {
+			t.Fatalf("%s: extra orders submitted to epoch", tag)
+		}
+	}
+
+	for oRig.auth.getSend() != nil {
+	} // drain
+	ensureSuccess("valid batch", newBatch())
+
+	// The response is an OrderResult for each order.
+	respMsg := oRig.auth.getSend()
+	if respMsg == nil {
+		t.Fatalf("no response from multitrade")
+	}
+	resp, _ := respMsg.Response()
+	var results []*msgjson.OrderResult
+	if err := json.Unmarshal(resp.Result, &results); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	msg := new(msgjson.Message)
+	msg.Payload = []byte(`?`)
+	ensureErr("bad payload", oRig.router.handleMultiTrade(user.acct, msg), msgjson.RPCParseError)
+
+	ensureErr("no orders", sendMulti(&msgjson.MultiTrade{}), msgjson.OrderParameterError)
+
+	multi := &msgjson.MultiTrade{}
+	for i := 0; i <= maxMultiTradeOrders; i++ {
+		multi.Orders = append(multi.Orders, newLimit(dcrID))
+	}
+	ensureErr("too many orders", sendMulti(multi), msgjson.OrderParameterError)
+
+	// Any invalid order fails the batch.
+	multi = newBatch()
+	multi.Orders[1].Rate++
+	ensureErr("bad rate", sendMulti(multi), msgjson.OrderParameterError)
+	if oRig.market.pop() != nil {
+		t.Fatalf("order submitted from a failed batch")
+	}
+
+	// Orders on different markets.
+	multi = newBatch()
+	multi.Orders[2].Base = assetETH.ID
+	ensureErr("mixed markets", sendMulti(multi), msgjson.OrderParameterError)
+
+	// The same coin funding two orders.
+	multi = newBatch()
+	multi.Orders[2].Coins = multi.Orders[0].Coins
+	ensureErr("shared coin", sendMulti(multi), msgjson.FundingError)
+
+	// One order is underfunded.
+	multi = newBatch()
+	multi.Orders[1].Coins = []*msgjson.Coin{oRig.signedUTXO(dcrID, qty/2, 1)}
+	ensureErr("underfunded order", sendMulti(multi), msgjson.FundingError)
+
+	// Account-based funding is checked against the batch total.
+	ethCoin := func() *msgjson.Coin { return oRig.signedUTXO(int(assetETH.ID), 0, 1) }
+	multi = &msgjson.MultiTrade{Orders: []*msgjson.LimitOrder{
+		newLimit(assetETH.ID, ethCoin()),
+		newLimit(assetETH.ID, ethCoin()),
+	}}
+	reqFunds := calc.RequiredOrderFunds(qty, 0, lots, &assetETH.Asset)
+	oRig.eth.bal = 2*reqFunds - 1
+	ensureErr("not enough for batch", sendMulti(multi), msgjson.FundingError)
+	oRig.eth.bal = 2 * reqFunds
+	ensureSuccess("well-funded account-based batch", multi)
+}
+
+func TestReplace(t *testing.T) {
+	const lots = 2
+	qty := uint64(dcrLotSize) * lots
+	rate := uint64(1000) * dcrRateStep
+	user := oRig.user
+	targetID := order.OrderID{245}
+	pi := ordertest.RandomPreimage()
+	commit := pi.Commit()
+	limit := &msgjson.LimitOrder{
+		Prefix: msgjson.Prefix{
+			AccountID:  user.acct[:],
+			Base:       dcrID,
+			Quote:      btcID,
+			OrderType:  msgjson.LimitOrderNum,
+			ClientTime: uint64(nowMs().UnixMilli()),
+			Commit:     commit[:],
+		},
+		Trade: msgjson.Trade{
+			Side:     msgjson.SellOrderNum,
+			Quantity: qty,
+			Coins:    []*msgjson.Coin{oRig.signedUTXO(dcrID, qty, 1)},
+			Address:  btcAddr,
+		},
+		Rate:     rate,
+		TiF:      msgjson.StandingOrderNum,
+		Replaces: targetID[:],
+	}
+
+	ensureErr := makeEnsureErr(t)
+
+	sendReplace := func() *msgjson.Error {
+		msg, _ := msgjson.NewRequest(5, msgjson.ReplaceRoute, limit)
+		return oRig.router.handleReplace(user.acct, msg)
+	}
+
+	// The replacement is submitted directly, without a funding check.
+	ensureErr("valid replacement", sendReplace(), -1)
+	oRecord := oRig.market.pop()
+	if oRecord == nil {
+		t.Fatalf("no order submitted to epoch")
+	}
+	if lo := oRecord.order.(*order.LimitOrder); lo.Replaces != targetID {
+		t.Fatalf("wrong replaced order ID %v", lo.Replaces)
+	}
+
+	msg := new(msgjson.Message)
+	msg.Payload = []byte(`?`)
+	ensureErr("bad payload", oRig.router.handleReplace(user.acct, msg), msgjson.RPCParseError)
+
+	oRig.market.cancelable = false
+	ensureErr("unknown target", sendReplace(), msgjson.OrderParameterError)
+	oRig.market.cancelable = true
+
+	limit.Replaces = []byte{0x01}
+	ensureErr("bad target ID", sendReplace(), msgjson.OrderParameterError)
+	limit.Replaces = nil
+	ensureErr("no target ID", sendReplace(), msgjson.OrderParameterError)
+	limit.Replaces = targetID[:]
+
+	limit.TiF = msgjson.ImmediateOrderNum
+	ensureErr("immediate replacement", sendReplace(), msgjson.OrderParameterError)
+	limit.TiF = msgjson.StandingOrderNum
+
+	// Replacements can't be placed with the limit route.
+	msg, _ = msgjson.NewRequest(5, msgjson.LimitRoute, limit)
+	ensureErr("limit route", oRig.router.handleLimit(user.acct, msg), msgjson.OrderParameterError)
+
+	if oRig.market.pop() != nil {
+		t.Fatalf("invalid replacement submitted to epoch")
+	}
+}
+
 func TestMarketStartProcessStop(t *testing.T) {
 	const sellLots = 10
 	qty := uint64(dcrLotSize) * sellLots
@@ -1509,10 +1781,11 @@ func tNewLink() *TLink {
 	}
 }
 
-func (conn *TLink) Authorized()   {}
-func (conn *TLink) ID() uint64    { return conn.id }
-func (conn *TLink) IP() dex.IPKey { return conn.ip }
-func (conn *TLink) Addr() string  { return conn.addr }
+func (conn *TLink) Authorized()           {}
+func (conn *TLink) SetAccountScore(int32) {}
+func (conn *TLink) ID() uint64            { return conn.id }
+func (conn *TLink) IP() dex.IPKey         { return conn.ip }
+func (conn *TLink) Addr() string          { return conn.addr }
 func (conn *TLink) Send(msg *msgjson.Message) error {
 	conn.mtx.Lock()
 	defer conn.mtx.Unlock()
@@ -1925,6 +2198,45 @@ func TestRouter(t *testing.T) {
 		t.Fatalf("order still in book after unbookAction")
 	}
 
+	// Only the displayed quantity of an iceberg order is broadcast.
+	lo = makeLO(seller2, mkRate2(1.0, 1.2), 10, order.StandingTiF)
+	lo.DisplayQty = 3 * mkt2.LotSize
+	src2.feed <- &updateSignal{
+		action: bookAction,
+		data: sigDataBookedOrder{
+			order:    lo,
+			epochIdx: 12344366,
+		},
+	}
+	bookNote = getBookNoteFromLink(t, link1)
+	link2.getSend()
+	if bookNote.Quantity != lo.DisplayQty {
+		t.Fatalf("wrong iceberg quantity in book update. expected %d, got %d", lo.DisplayQty, bookNote.Quantity)
+	}
+	// Partially filled, the displayed quantity is replenished.
+	lo.FillAmt = mkt2.LotSize
+	src2.feed <- &updateSignal{
+		action: updateRemainingAction,
+		data: sigDataUpdateRemaining{
+			order:    lo,
+			epochIdx: 12344366,
+		},
+	}
+	urNote = getUpdateRemainingNoteFromLink(t, link1)
+	link2.getSend()
+	if urNote.Remaining != lo.DisplayQty {
+		t.Fatalf("wrong iceberg remaining quantity. expected %d, got %d", lo.DisplayQty, urNote.Remaining)
+	}
+	src2.feed <- &updateSignal{
+		action: unbookAction,
+		data: sigDataUnbookedOrder{
+			order:    lo,
+			epochIdx: 12344366,
+		},
+	}
+	getUnbookNoteFromLink(t, link1)
+	link2.getSend()
+
 	// Now unsubscribe link 1 from market 1.
 	unsub, _ := msgjson.NewRequest(10, msgjson.UnsubOrderBookRoute, &msgjson.UnsubOrderBook{
 		MarketID: mktName1,
@@ -0,0 +1,98 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/book"
+	"decred.org/dcrdex/server/matcher"
+)
+
+// transcriptBooker wraps the market's book for a match cycle, recording the
+// book orders considered by the matcher in their state prior to matching. The
+// first access of a book order is always before the matcher modifies it.
+// Orders inserted during the match cycle are from the epoch queue, and are not
+// recorded.
+type transcriptBooker struct {
+	*book.Book
+	seen   map[order.OrderID]bool
+	makers []*order.TranscriptOrder
+}
+
+func newTranscriptBooker(b *book.Book) *transcriptBooker {
+	return &transcriptBooker{
+		Book: b,
+		seen: make(map[order.OrderID]bool),
+	}
+}
+
+// record records the book order if it has not already been seen.
+func (tb *transcriptBooker) record(lo *order.LimitOrder) {
+	if lo == nil {
+		return
+	}
+	oid := lo.ID()
+	if tb.seen[oid] {
+		return
+	}
+	tb.seen[oid] = true
+	to := order.NewTranscriptOrder(lo)
+	if stamp, found := tb.Book.Priority(oid); found {
+		to.Stamp = stamp
+	}
+	tb.makers = append(tb.makers, to)
+}
+
+// BestSell is part of the matcher.Booker interface.
+func (tb *transcriptBooker) BestSell() *order.LimitOrder {
+	lo := tb.Book.BestSell()
+	tb.record(lo)
+	return lo
+}
+
+// BestBuy is part of the matcher.Booker interface.
+func (tb *transcriptBooker) BestBuy() *order.LimitOrder {
+	lo := tb.Book.BestBuy()
+	tb.record(lo)
+	return lo
+}
+
+// Order is part of the matcher.Booker interface.
+func (tb *transcriptBooker) Order(oid order.OrderID) *order.LimitOrder {
+	lo := tb.Book.Order(oid)
+	tb.record(lo)
+	return lo
+}
+
+// Remove is part of the matcher.Booker interface.
+func (tb *transcriptBooker) Remove(oid order.OrderID) (*order.LimitOrder, bool) {
+	tb.record(tb.Book.Order(oid))
+	return tb.Book.Remove(oid)
+}
+
+// Insert is part of the matcher.Booker interface.
+func (tb *transcriptBooker) Insert(lo *order.LimitOrder) bool {
+	tb.seen[lo.ID()] = true
+	return tb.Book.Insert(lo)
+}
+
+var _ matcher.Booker = (*transcriptBooker)(nil)
+
+// transcriptMatches flattens the match sets into the matches of an epoch
+// transcript.
+func transcriptMatches(matchSets []*order.MatchSet) []*order.TranscriptMatch {
+	var matches []*order.TranscriptMatch
+	for _, ms := range matchSets {
+		takerID := ms.Taker.ID()
+		for i, maker := range ms.Makers {
+			matches = append(matches, &order.TranscriptMatch{
+				Taker:    takerID,
+				Maker:    maker.ID(),
+				Quantity: ms.Amounts[i],
+				Rate:     ms.Rates[i],
+			})
+		}
+	}
+	return matches
+}
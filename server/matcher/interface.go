@@ -14,12 +14,20 @@ type Booker interface {
 	BestBuy() *order.LimitOrder
 	Insert(*order.LimitOrder) bool
 	Remove(order.OrderID) (*order.LimitOrder, bool)
+	// Order retrieves a booked order, or nil if the order is not on the book.
+	Order(order.OrderID) *order.LimitOrder
+	// Requeue sets the time priority of a booked order among the orders at the
+	// same rate. This is used to replenish an iceberg order's displayed
+	// quantity.
+	Requeue(oid order.OrderID, stamp int64) bool
 	BuyOrders() []*order.LimitOrder
 	SellOrders() []*order.LimitOrder
 }
 
 // MatchCycleStats is data about the results of a match cycle.
 type MatchCycleStats struct {
+	QueueSize   uint64 // number of orders in the epoch queue
+	Matches     uint64 // number of maker-taker matches
 	MatchVolume uint64
 	QuoteVolume uint64
 	BookSells   uint64
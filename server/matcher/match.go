@@ -121,6 +121,9 @@ type OrdersUpdated struct {
 	// cancel order. These may also be in TradesBooked and TradesPartial, but
 	// not TradesCompleted.
 	TradesCanceled []*order.LimitOrder
+	// TradesReplaced are limit orders that were removed from the book by a
+	// replacement order. They are unfilled, and in no other slice.
+	TradesReplaced []*order.LimitOrder
 	// TradesCompleted are market or limit orders that filled to completion. For
 	// a market order, this means it had a match that partially or completely
 	// filled it. For a limit order, this means the time-in-force is immediate
@@ -130,9 +133,9 @@ type OrdersUpdated struct {
 }
 
 func (ou *OrdersUpdated) String() string {
-	return fmt.Sprintf("cExec=%d, cFail=%d, tPartial=%d, tBooked=%d, tCanceled=%d, tComp=%d, tFail=%d",
+	return fmt.Sprintf("cExec=%d, cFail=%d, tPartial=%d, tBooked=%d, tCanceled=%d, tReplaced=%d, tComp=%d, tFail=%d",
 		len(ou.CancelsExecuted), len(ou.CancelsFailed), len(ou.TradesPartial), len(ou.TradesBooked),
-		len(ou.TradesCanceled), len(ou.TradesCompleted), len(ou.TradesFailed))
+		len(ou.TradesCanceled), len(ou.TradesReplaced), len(ou.TradesCompleted), len(ou.TradesFailed))
 }
 
 // Match matches orders given a standing order book and an epoch queue. Matched
@@ -150,8 +153,32 @@ func (m *Matcher) Match(book Booker, queue []*OrderRevealed) (seed []byte, match
 	// Apply the deterministic pseudorandom shuffling.
 	seed = shuffleQueue(queue)
 
+	matches, passed, failed, doneOK, partial, booked, nomatched, unbooked, updates, stats = matchShuffled(book, queue)
+	return
+}
+
+// Replay matches an epoch queue that is already in its shuffled order, such as
+// the queue of an order.EpochTranscript, with the book. Unlike Match, the queue
+// is not shuffled, and the preimages are not used.
+func (m *Matcher) Replay(book Booker, queue []order.Order) []*order.MatchSet {
+	revealed := make([]*OrderRevealed, 0, len(queue))
+	for _, ord := range queue {
+		revealed = append(revealed, &OrderRevealed{Order: ord})
+	}
+	matches, _, _, _, _, _, _, _, _, _ := matchShuffled(book, revealed)
+	return matches
+}
+
+// matchShuffled performs matching of the shuffled queue with the book. See
+// Match for a description of the outputs.
+func matchShuffled(book Booker, queue []*OrderRevealed) (matches []*order.MatchSet,
+	passed, failed, doneOK, partial, booked, nomatched []*OrderRevealed,
+	unbooked []*order.LimitOrder, updates *OrdersUpdated, stats *MatchCycleStats) {
+
 	updates = new(OrdersUpdated)
-	stats = new(MatchCycleStats)
+	stats = &MatchCycleStats{
+		QueueSize: uint64(len(queue)),
+	}
 	startRate := midGap(book)
 	stats.StartRate = startRate
 	stats.LowRate = startRate
@@ -160,6 +187,7 @@ func (m *Matcher) Match(book Booker, queue []*OrderRevealed) (seed []byte, match
 	appendTradeSet := func(matchSet *order.MatchSet) {
 		matches = append(matches, matchSet)
 
+		stats.Matches += uint64(len(matchSet.Makers))
 		stats.MatchVolume += matchSet.Total
 		high, low := matchSet.HighLowRates()
 		if high > stats.HighRate {
@@ -229,6 +257,24 @@ func (m *Matcher) Match(book Booker, queue []*OrderRevealed) (seed []byte, match
 			updates.TradesCanceled = append(updates.TradesCanceled, removed)
 
 		case *order.LimitOrder:
+			// A replacement order takes the place of its target on the book
+			// before it is matched. The target must still be booked and
+			// unfilled, or the replacement fails.
+			if o.IsReplacement() {
+				target := book.Order(o.Replaces)
+				if target == nil || target.Filled() > 0 {
+					log.Debugf("Replacement order %v failed (target %v either non-existent or filled)",
+						o.ID(), o.Replaces)
+					failed = append(failed, q)
+					updates.TradesFailed = append(updates.TradesFailed, o)
+					nomatched = append(nomatched, q)
+					continue
+				}
+				book.Remove(o.Replaces)
+				unbooked = append(unbooked, target)
+				updates.TradesReplaced = append(updates.TradesReplaced, target)
+			}
+
 			// limit-limit order matching
 			var makers []*order.LimitOrder
 			matchSet := matchLimitOrder(book, o)
@@ -258,8 +304,8 @@ func (m *Matcher) Match(book Booker, queue []*OrderRevealed) (seed []byte, match
 				if o.Filled() > 0 {
 					partial = append(partial, q)
 				}
-				if o.Force == order.StandingTiF {
-					// Standing TiF orders go on the book.
+				if o.Force != order.ImmediateTiF {
+					// Standing and good-til-time TiF orders go on the book.
 					book.Insert(o)
 					booked = append(booked, q)
 					updates.TradesBooked = append(updates.TradesBooked, o)
@@ -356,43 +402,70 @@ func matchLimitOrder(book Booker, ord *order.LimitOrder) (matchSet *order.MatchS
 		// now, best.Rate <= ord.Rate
 
 		// The match amount is the smaller of the order's remaining quantity or
-		// the best matching order amount.
-		amt := best.Remaining()
+		// the best matching order's displayed amount.
+		amt := best.Visible()
+		replenish := best.Hidden() > 0
 		if amtRemaining < amt {
 			// Partially fill the standing order, updating its value.
 			amt = amtRemaining
-		} else {
+		} else if !replenish {
 			// The standing order has been consumed. Remove it from the book.
 			if _, ok := book.Remove(best.ID()); !ok {
 				log.Errorf("Failed to remove standing order %v.", best)
 			}
 		}
 		best.AddFill(amt)
+		if replenish {
+			requeueIceberg(book, best, ord)
+		}
 
 		// Reduce the remaining quantity of the taker order.
 		amtRemaining -= amt
 		ord.AddFill(amt)
 
 		// Add the matched maker order to the output.
-		if matchSet == nil {
-			matchSet = &order.MatchSet{
-				Taker:   ord,
-				Makers:  []*order.LimitOrder{best},
-				Amounts: []uint64{amt},
-				Rates:   []uint64{best.Rate},
-				Total:   amt,
-			}
-		} else {
-			matchSet.Makers = append(matchSet.Makers, best)
-			matchSet.Amounts = append(matchSet.Amounts, amt)
-			matchSet.Rates = append(matchSet.Rates, best.Rate)
-			matchSet.Total += amt
-		}
+		matchSet = addMatch(matchSet, ord, best, amt)
 	}
 
 	return
 }
 
+// requeueIceberg moves an iceberg order that has had its displayed quantity
+// replenished from its hidden reserve behind the other orders at the same rate,
+// as if it were booked at the time of the taker order.
+func requeueIceberg(book Booker, maker *order.LimitOrder, taker order.Order) {
+	if !book.Requeue(maker.ID(), taker.Time()) {
+		log.Errorf("Failed to requeue iceberg order %v.", maker)
+	}
+}
+
+// addMatch adds the maker order and matched amount to the MatchSet, creating
+// the MatchSet if it is nil. An iceberg maker may be matched more than once by
+// the same taker as its displayed quantity is replenished, in which case the
+// amounts are combined so that there is one match per maker.
+func addMatch(matchSet *order.MatchSet, taker order.Order, maker *order.LimitOrder, amt uint64) *order.MatchSet {
+	if matchSet == nil {
+		return &order.MatchSet{
+			Taker:   taker,
+			Makers:  []*order.LimitOrder{maker},
+			Amounts: []uint64{amt},
+			Rates:   []uint64{maker.Rate},
+			Total:   amt,
+		}
+	}
+	matchSet.Total += amt
+	for i, m := range matchSet.Makers {
+		if m == maker {
+			matchSet.Amounts[i] += amt
+			return matchSet
+		}
+	}
+	matchSet.Makers = append(matchSet.Makers, maker)
+	matchSet.Amounts = append(matchSet.Amounts, amt)
+	matchSet.Rates = append(matchSet.Rates, maker.Rate)
+	return matchSet
+}
+
 // market(sell)-limit order matching
 func matchMarketSellOrder(book Booker, ord *order.MarketOrder) (matchSet *order.MatchSet) {
 	if !ord.Sell {
@@ -450,18 +523,22 @@ func matchMarketBuyOrder(book Booker, ord *order.MarketOrder) (matchSet *order.M
 		// amt := uint64(best.Rate * float64(best.Quantity)) // trunc
 
 		// The match amount is the smaller of the order's remaining quantity or
-		// the best matching order amount.
-		amt := best.Remaining()
+		// the best matching order's displayed amount.
+		amt := best.Visible()
+		replenish := best.Hidden() > 0
 		if amtRemainingBase < amt {
 			// Partially fill the standing order, updating its value.
 			amt = amtRemainingBase - amtRemainingBase%lotSize // amt is a multiple of lot size
-		} else {
+		} else if !replenish {
 			// The standing order has been consumed. Remove it from the book.
 			if _, ok := book.Remove(best.ID()); !ok {
 				log.Errorf("Failed to remove standing order %v.", best)
 			}
 		}
 		best.AddFill(amt)
+		if replenish {
+			requeueIceberg(book, best, ord)
+		}
 
 		// Reduce the remaining quantity of the taker order.
 		// amtRemainingBase -= amt // FYI
@@ -471,20 +548,7 @@ func matchMarketBuyOrder(book Booker, ord *order.MarketOrder) (matchSet *order.M
 		ord.AddFill(amtQuote)    // quote asset filled
 
 		// Add the matched maker order to the output.
-		if matchSet == nil {
-			matchSet = &order.MatchSet{
-				Taker:   ord,
-				Makers:  []*order.LimitOrder{best},
-				Amounts: []uint64{amt},
-				Rates:   []uint64{best.Rate},
-				Total:   amt,
-			}
-		} else {
-			matchSet.Makers = append(matchSet.Makers, best)
-			matchSet.Amounts = append(matchSet.Amounts, amt)
-			matchSet.Rates = append(matchSet.Rates, best.Rate)
-			matchSet.Total += amt
-		}
+		matchSet = addMatch(matchSet, ord, best, amt)
 	}
 
 	return
@@ -611,18 +675,28 @@ func shuffleQueue(queue []*OrderRevealed) (seed []byte) {
 	// preimages, lexicographically sorted by order ID.
 	sortQueueByID(queue)
 
+	preimages := make([]order.Preimage, 0, len(queue))
+	for _, o := range queue {
+		preimages = append(preimages, o.Preimage)
+	}
+	return shuffle(preimages, func(i, j int) {
+		queue[i], queue[j] = queue[j], queue[i]
+	})
+}
+
+// shuffle computes the shuffle seed as the hash of the concatenated preimages,
+// which must be sorted by order ID, and Fisher-Yates shuffles a slice of the
+// same length using the swap function.
+func shuffle(preimages []order.Preimage, swap func(i, j int)) (seed []byte) {
 	// Hash the concatenation of the preimages.
-	qLen := len(queue)
+	qLen := len(preimages)
 	hasher := blake256.New()
-	//peCat := make([]byte, peSize*qLen)
-	for _, o := range queue {
-		hasher.Write(o.Preimage[:]) // err is always nil and n is always len(s)
-		//copy(peCat[peSize*i:peSize*(i+1)], o.Preimage[:])
+	for i := range preimages {
+		hasher.Write(preimages[i][:]) // err is always nil and n is always len(s)
 	}
 
 	// Fisher-Yates shuffle the slice using MT19937 seeded with the hash.
 	seed = hasher.Sum(nil)
-	// seed = HashFunc(hashCat)
 
 	// This seeded random number generator is used to generate one sequence, and
 	// the seed is revealed then revealed. It need not be cryptographically
@@ -630,14 +704,50 @@ func shuffleQueue(queue []*OrderRevealed) (seed []byte) {
 	mtSrc := mt19937.NewSource()
 	mtSrc.SeedBytes(seed[:])
 	prng := rand.New(mtSrc)
-	for i := range queue {
+	for i := 0; i < qLen; i++ {
 		j := prng.Intn(qLen-i) + i
-		queue[i], queue[j] = queue[j], queue[i]
+		swap(i, j)
 	}
 
 	return
 }
 
+// ShuffleIDs computes the shuffle seed and shuffled order of an epoch queue
+// from the order IDs and their revealed preimages, as done by Match. This
+// allows the shuffle to be reproduced without the full orders. The input
+// slices are not modified.
+func ShuffleIDs(oids []order.OrderID, preimages []order.Preimage) (seed []byte, shuffled []order.OrderID, err error) {
+	if len(oids) != len(preimages) {
+		return nil, nil, fmt.Errorf("%d order IDs for %d preimages", len(oids), len(preimages))
+	}
+	if len(oids) == 0 {
+		return nil, nil, nil
+	}
+	type revealedID struct {
+		oid  order.OrderID
+		pimg order.Preimage
+	}
+	queue := make([]revealedID, 0, len(oids))
+	for i := range oids {
+		queue = append(queue, revealedID{oids[i], preimages[i]})
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		return bytes.Compare(queue[i].oid[:], queue[j].oid[:]) < 0
+	})
+	sortedPimgs := make([]order.Preimage, 0, len(queue))
+	for _, r := range queue {
+		sortedPimgs = append(sortedPimgs, r.pimg)
+	}
+	seed = shuffle(sortedPimgs, func(i, j int) {
+		queue[i], queue[j] = queue[j], queue[i]
+	})
+	shuffled = make([]order.OrderID, 0, len(queue))
+	for _, r := range queue {
+		shuffled = append(shuffled, r.oid)
+	}
+	return seed, shuffled, nil
+}
+
 func midGap(book Booker) uint64 {
 	b, s := book.BestBuy(), book.BestSell()
 	if b == nil {
@@ -661,8 +771,9 @@ func sideVolume(ords []*order.LimitOrder) (q uint64) {
 func bookVolumes(book Booker, midGap uint64, stats *MatchCycleStats) {
 	cutoff5 := midGap - midGap/20 // 5%
 	cutoff25 := midGap - midGap/4 // 25%
+	// Only the displayed quantity of iceberg orders is counted.
 	for _, ord := range book.BuyOrders() {
-		remaining := ord.Remaining()
+		remaining := ord.Visible()
 		stats.BookBuys += remaining
 		if ord.Rate > cutoff25 {
 			stats.BookBuys25 += remaining
@@ -674,7 +785,7 @@ func bookVolumes(book Booker, midGap uint64, stats *MatchCycleStats) {
 	cutoff5 = midGap + midGap/20
 	cutoff25 = midGap + midGap/4
 	for _, ord := range book.SellOrders() {
-		remaining := ord.Remaining()
+		remaining := ord.Visible()
 		stats.BookSells += remaining
 		if ord.Rate < cutoff25 {
 			stats.BookSells25 += remaining
@@ -247,6 +247,35 @@ func (b *BookStub) Remove(orderID order.OrderID) (*order.LimitOrder, bool) {
 	return nil, false
 }
 
+func (b *BookStub) Order(orderID order.OrderID) *order.LimitOrder {
+	for _, ords := range [][]*order.LimitOrder{b.buyOrders, b.sellOrders} {
+		for _, lo := range ords {
+			if lo.ID() == orderID {
+				return lo
+			}
+		}
+	}
+	return nil
+}
+
+func (b *BookStub) Requeue(orderID order.OrderID, _ int64) bool {
+	// Move the order behind the other orders at the same rate. The best orders
+	// are at the end of each slice.
+	requeue := func(ords []*order.LimitOrder) bool {
+		for i := range ords {
+			if ords[i].ID() != orderID {
+				continue
+			}
+			for ; i > 0 && ords[i-1].Rate == ords[i].Rate; i-- {
+				ords[i-1], ords[i] = ords[i], ords[i-1]
+			}
+			return true
+		}
+		return false
+	}
+	return requeue(b.sellOrders) || requeue(b.buyOrders)
+}
+
 func (b *BookStub) BuyOrders() []*order.LimitOrder  { return b.buyOrders }
 func (b *BookStub) SellOrders() []*order.LimitOrder { return b.sellOrders }
 
@@ -518,6 +547,132 @@ func Test_matchLimitOrder(t *testing.T) {
 	}
 }
 
+func Test_matchIceberg(t *testing.T) {
+	startLogger()
+
+	newIceberg := func() *order.LimitOrder {
+		lo := newLimitOrder(true, 4550000, 5, order.StandingTiF, -1) // older
+		lo.DisplayQty = 2 * LotSize
+		return lo
+	}
+	iceberg := newIceberg()
+	other := newLimitOrder(true, 4550000, 1, order.StandingTiF, 0)
+	worse := newLimitOrder(true, 4600000, 2, order.StandingTiF, 0)
+	book := &BookStub{
+		lotSize:    LotSize,
+		sellOrders: []*order.LimitOrder{worse, other, iceberg},
+	}
+
+	// The taker consumes the iceberg's displayed 2 lots, then the other order
+	// at the same rate, then 1 lot of the replenished iceberg.
+	taker := newLimitOrder(false, 4550000, 4, order.ImmediateTiF, 0)
+	matchSet := matchLimitOrder(book, taker)
+	if matchSet == nil {
+		t.Fatalf("no match")
+	}
+	wantMakers := []*order.LimitOrder{iceberg, other}
+	wantAmts := []uint64{3 * LotSize, 1 * LotSize}
+	if len(matchSet.Makers) != len(wantMakers) {
+		t.Fatalf("expected %d makers, got %d", len(wantMakers), len(matchSet.Makers))
+	}
+	for i, maker := range matchSet.Makers {
+		if maker != wantMakers[i] || matchSet.Amounts[i] != wantAmts[i] {
+			t.Fatalf("wrong maker %d: %v, amount %d", i, maker, matchSet.Amounts[i])
+		}
+	}
+	if matchSet.Total != 4*LotSize || taker.Remaining() != 0 {
+		t.Fatalf("wrong total %d or taker remaining %d", matchSet.Total, taker.Remaining())
+	}
+	if iceberg.Remaining() != 2*LotSize || iceberg.Visible() != 2*LotSize || iceberg.Hidden() != 0 {
+		t.Fatalf("wrong iceberg amounts %d/%d", iceberg.Visible(), iceberg.Remaining())
+	}
+	if book.BestSell() != iceberg || book.SellCount() != 2 {
+		t.Fatalf("iceberg not left on the book")
+	}
+
+	// A market buy is also limited to the displayed quantity of each fill.
+	iceberg = newIceberg()
+	book = &BookStub{
+		lotSize:    LotSize,
+		sellOrders: []*order.LimitOrder{worse, iceberg},
+	}
+	worse.FillAmt = 0
+	mkt := newMarketBuyOrder(BaseToQuote(iceberg.Rate, 5*LotSize)+BaseToQuote(worse.Rate, LotSize), 0).Order.(*order.MarketOrder)
+	matchSet = matchMarketBuyOrder(book, mkt)
+	if matchSet == nil {
+		t.Fatalf("no market buy match")
+	}
+	if len(matchSet.Makers) != 2 || matchSet.Makers[0] != iceberg || matchSet.Amounts[0] != 5*LotSize ||
+		matchSet.Makers[1] != worse || matchSet.Amounts[1] != 1*LotSize {
+		t.Fatalf("wrong market buy match %v, amounts %v", matchSet.Makers, matchSet.Amounts)
+	}
+	if book.SellCount() != 1 || book.BestSell() != worse {
+		t.Fatalf("filled iceberg order still on the book")
+	}
+}
+
+func TestMatch_replace(t *testing.T) {
+	startLogger()
+	me := New()
+
+	target := newLimitOrder(false, 4500000, 2, order.StandingTiF, -1)
+	sell := newLimitOrder(true, 4600000, 1, order.StandingTiF, -1)
+	book := &BookStub{
+		lotSize:    LotSize,
+		buyOrders:  []*order.LimitOrder{target},
+		sellOrders: []*order.LimitOrder{sell},
+	}
+
+	// The replacement crosses the spread, and takes the sell order.
+	replacement := newLimit(false, 4600000, 2, order.StandingTiF, 0)
+	replacement.Order.(*order.LimitOrder).Replaces = target.ID()
+	_, matches, passed, failed, _, _, booked, _, unbooked, updates, _ := me.Match(book, []*OrderRevealed{replacement})
+	if len(updates.TradesReplaced) != 1 || updates.TradesReplaced[0] != target {
+		t.Fatalf("target not replaced: %v", updates)
+	}
+	if len(passed) != 1 || passed[0] != replacement || len(failed) != 0 {
+		t.Fatalf("wrong passed/failed: %d/%d", len(passed), len(failed))
+	}
+	if len(matches) != 1 || matches[0].Taker != replacement.Order || matches[0].Makers[0] != sell {
+		t.Fatalf("replacement not matched")
+	}
+	if len(booked) != 1 || booked[0] != replacement {
+		t.Fatalf("replacement not booked")
+	}
+	var targetUnbooked bool
+	for _, lo := range unbooked {
+		targetUnbooked = targetUnbooked || lo == target
+	}
+	if !targetUnbooked || book.Order(target.ID()) != nil {
+		t.Fatalf("target not unbooked")
+	}
+	if book.BestBuy() != replacement.Order {
+		t.Fatalf("replacement not on the book")
+	}
+
+	// Another replacement of the same target fails.
+	dupe := newLimit(false, 4400000, 1, order.StandingTiF, 0)
+	dupe.Order.(*order.LimitOrder).Replaces = target.ID()
+	_, _, _, failed, _, _, _, _, _, updates, _ = me.Match(book, []*OrderRevealed{dupe})
+	if len(failed) != 1 || len(updates.TradesFailed) != 1 || len(updates.TradesReplaced) != 0 {
+		t.Fatalf("replacement of a removed target did not fail")
+	}
+
+	// A filled target can't be replaced.
+	target = newLimitOrder(false, 4500000, 2, order.StandingTiF, -1)
+	target.FillAmt = LotSize
+	book = &BookStub{
+		lotSize:   LotSize,
+		buyOrders: []*order.LimitOrder{target},
+	}
+	replacement = newLimit(false, 4400000, 1, order.StandingTiF, 0)
+	replacement.Order.(*order.LimitOrder).Replaces = target.ID()
+	_, _, _, failed, _, _, _, _, _, updates, _ = me.Match(book, []*OrderRevealed{replacement})
+	if len(failed) != 1 || len(updates.TradesReplaced) != 0 || book.Order(target.ID()) == nil {
+		t.Fatalf("filled target replaced")
+	}
+}
+
 func newCancelOrder(targetOrderID order.OrderID, serverTime time.Time) *OrderRevealed {
 	pe := randomPreimage()
 	return &OrderRevealed{
@@ -992,6 +1147,16 @@ func TestMatch_limitsOnly(t *testing.T) {
 			}
 			if tt.matchStats != nil {
 				compareMatchStats(t, tt.matchStats, stats)
+				if stats.QueueSize != uint64(len(tt.args.queue)) {
+					t.Errorf("wrong QueueSize. wanted %d, got %d", len(tt.args.queue), stats.QueueSize)
+				}
+				var wantMatches uint64
+				for _, ms := range tt.wantMatches {
+					wantMatches += uint64(len(ms.Makers))
+				}
+				if stats.Matches != wantMatches {
+					t.Errorf("wrong Matches. wanted %d, got %d", wantMatches, stats.Matches)
+				}
 			}
 		})
 	}
@@ -2262,3 +2427,123 @@ func TestCSum(t *testing.T) {
 		t.Errorf("got csum %x, wanted %x", csum, wantCSum)
 	}
 }
+
+func TestShuffleIDs(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 5, 20} {
+		queue := make([]*OrderRevealed, 0, n)
+		for i := 0; i < n; i++ {
+			queue = append(queue, newLimit(i%2 == 0, 4500000, 1, order.StandingTiF, int64(i)))
+		}
+		oids := make([]order.OrderID, 0, n)
+		pimgs := make([]order.Preimage, 0, n)
+		for _, or := range queue {
+			oids = append(oids, or.Order.ID())
+			pimgs = append(pimgs, or.Preimage)
+		}
+
+		seed := shuffleQueue(queue)
+		idSeed, shuffled, err := ShuffleIDs(oids, pimgs)
+		if err != nil {
+			t.Fatalf("ShuffleIDs error: %v", err)
+		}
+		if !bytes.Equal(seed, idSeed) {
+			t.Fatalf("seed mismatch for %d orders", n)
+		}
+		for i, or := range queue {
+			if or.Order.ID() != shuffled[i] {
+				t.Fatalf("order %d of %d shuffled differently", i, n)
+			}
+		}
+	}
+
+	if _, _, err := ShuffleIDs(make([]order.OrderID, 2), make([]order.Preimage, 1)); err == nil {
+		t.Fatalf("no error for mismatched preimages")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	startLogger()
+	me := New()
+
+	buys := []*order.LimitOrder{
+		newLimitOrder(false, 4400000, 2, order.StandingTiF, -2),
+		newLimitOrder(false, 4500000, 2, order.StandingTiF, -1),
+	}
+	sells := []*order.LimitOrder{
+		newLimitOrder(true, 4700000, 3, order.StandingTiF, -2),
+		newLimitOrder(true, 4600000, 1, order.StandingTiF, -1),
+	}
+	queue := []*OrderRevealed{
+		newLimit(false, 4700000, 2, order.ImmediateTiF, 0),
+		newLimit(true, 4400000, 3, order.StandingTiF, 0),
+		newMarketSellOrder(1, 0),
+		newCancelOrder(buys[0].ID(), time.Now()),
+	}
+
+	// Copy the orders before they are modified by matching.
+	copyLimit := func(lo *order.LimitOrder) *order.LimitOrder {
+		return &order.LimitOrder{
+			P:           lo.P,
+			T:           *lo.T.Copy(),
+			Rate:        lo.Rate,
+			Force:       lo.Force,
+			ExpiryEpoch: lo.ExpiryEpoch,
+			DisplayQty:  lo.DisplayQty,
+			Replaces:    lo.Replaces,
+		}
+	}
+	copyLimits := func(los []*order.LimitOrder) []*order.LimitOrder {
+		cp := make([]*order.LimitOrder, 0, len(los))
+		for _, lo := range los {
+			cp = append(cp, copyLimit(lo))
+		}
+		return cp
+	}
+	replayBook := &BookStub{
+		lotSize:    LotSize,
+		buyOrders:  copyLimits(buys),
+		sellOrders: copyLimits(sells),
+	}
+	book := &BookStub{
+		lotSize:    LotSize,
+		buyOrders:  buys,
+		sellOrders: sells,
+	}
+	queueCopy := make(map[order.OrderID]order.Order, len(queue))
+	for _, or := range queue {
+		switch o := or.Order.(type) {
+		case *order.LimitOrder:
+			queueCopy[o.ID()] = copyLimit(o)
+		case *order.MarketOrder:
+			queueCopy[o.ID()] = &order.MarketOrder{P: o.P, T: *o.T.Copy()}
+		case *order.CancelOrder:
+			queueCopy[o.ID()] = o
+		}
+	}
+
+	_, matches, _, _, _, _, _, _, _, _, _ := me.Match(book, queue)
+	if len(matches) < 3 {
+		t.Fatalf("expected at least 3 match sets, got %d", len(matches))
+	}
+
+	// Replay the queue in its shuffled order.
+	shuffled := make([]order.Order, 0, len(queue))
+	for _, or := range queue {
+		shuffled = append(shuffled, queueCopy[or.Order.ID()])
+	}
+	replayed := me.Replay(replayBook, shuffled)
+	if len(replayed) != len(matches) {
+		t.Fatalf("replayed %d match sets, expected %d", len(replayed), len(matches))
+	}
+	for i, ms := range matches {
+		rms := replayed[i]
+		if rms.Taker.ID() != ms.Taker.ID() || len(rms.Makers) != len(ms.Makers) {
+			t.Fatalf("replayed match set %d has a different taker or number of makers", i)
+		}
+		for j := range ms.Makers {
+			if rms.Makers[j].ID() != ms.Makers[j].ID() || rms.Amounts[j] != ms.Amounts[j] || rms.Rates[j] != ms.Rates[j] {
+				t.Fatalf("replayed match set %d differs at maker %d", i, j)
+			}
+		}
+	}
+}
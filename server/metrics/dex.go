@@ -0,0 +1,32 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package metrics
+
+// Metrics that are updated by the server subsystems as events occur. Metrics
+// that are read from a subsystem's current state are registered with
+// RegisterGaugeFunc by the DEX manager.
+var (
+	// EpochQueueSize is the number of orders in a market's most recently
+	// matched epoch queue.
+	EpochQueueSize = NewGaugeVec("dcrdex_epoch_queue_size",
+		"Number of orders in the most recently matched epoch queue.", "market")
+	// Matches counts the matches made by a market's matcher.
+	Matches = NewCounterVec("dcrdex_matches_total",
+		"Number of matches made in matched epochs.", "market")
+	// EpochProcessingSeconds is the time from the close of an epoch until its
+	// orders are matched, which includes preimage collection.
+	EpochProcessingSeconds = NewHistogramVec("dcrdex_epoch_processing_seconds",
+		"Time from the close of an epoch until its orders are matched, including preimage collection.",
+		[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60}, "market")
+	// RateLimited counts client requests rejected by the comms rate limiters.
+	// The transport is "ws" or "http". The route is empty for HTTP requests.
+	RateLimited = NewCounterVec("dcrdex_rate_limited_requests_total",
+		"Number of client requests rejected by the rate limiters.", "transport", "route")
+	// Violations counts user violations by auth.Violation description.
+	Violations = NewCounterVec("dcrdex_violations_total",
+		"Number of user violations by type.", "violation")
+	// Penalties counts the users penalized for reaching the ban score.
+	Penalties = NewCounterVec("dcrdex_penalties_total",
+		"Number of users penalized for reaching the ban score.")
+)
@@ -0,0 +1,369 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package metrics collects server metrics and writes them in the Prometheus
+// text exposition format. Metrics are registered with a package-level registry
+// so that any server package may update them without additional plumbing.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the HTTP Content-Type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	counterType   = "counter"
+	gaugeType     = "gauge"
+	histogramType = "histogram"
+)
+
+// metric is satisfied by all registered metrics.
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+var (
+	registryMtx sync.RWMutex
+	registry    = make(map[string]metric)
+)
+
+// register adds the metric to the registry, replacing any metric registered
+// with the same name.
+func register(name string, m metric) {
+	registryMtx.Lock()
+	registry[name] = m
+	registryMtx.Unlock()
+}
+
+// Write writes all registered metrics, sorted by name, to the io.Writer in the
+// Prometheus text exposition format.
+func Write(w io.Writer) error {
+	registryMtx.RLock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	metrics := make([]metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		metrics = append(metrics, registry[name])
+	}
+	registryMtx.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// desc describes a metric.
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, strings.ReplaceAll(d.help, "\n", " "))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.typ)
+}
+
+// writeSample writes a single sample line. extraLabel and extraValue, if
+// extraLabel is not empty, are appended to the metric's labels, as with the le
+// label of histogram buckets.
+func (d *desc) writeSample(w *bufio.Writer, suffix string, labelValues []string, extraLabel, extraValue string, v float64) {
+	w.WriteString(d.name + suffix)
+	if len(d.labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, label := range d.labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label + `="` + escapeLabelValue(labelValues[i]) + `"`)
+		}
+		if extraLabel != "" {
+			if len(d.labels) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraLabel + `="` + escapeLabelValue(extraValue) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+// checkLabels panics if the number of label values does not match the number
+// of labels. This is a programming error, as with the Prometheus client.
+func (d *desc) checkLabels(labelValues []string) {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d",
+			d.name, len(d.labels), len(labelValues)))
+	}
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// labelKey creates a map key from label values.
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+// Sample is a value with its label values. Samples are returned by the
+// functions provided to RegisterGaugeFunc.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// valueVec is a set of values partitioned by label values.
+type valueVec struct {
+	*desc
+	mtx    sync.Mutex
+	values map[string]*Sample
+}
+
+func newValueVec(name, help, typ string, labels []string) *valueVec {
+	return &valueVec{
+		desc: &desc{
+			name:   name,
+			help:   help,
+			typ:    typ,
+			labels: labels,
+		},
+		values: make(map[string]*Sample),
+	}
+}
+
+// update applies f to the value for the label values.
+func (v *valueVec) update(labelValues []string, f func(float64) float64) {
+	v.checkLabels(labelValues)
+	k := labelKey(labelValues)
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	s, found := v.values[k]
+	if !found {
+		s = &Sample{LabelValues: append([]string(nil), labelValues...)}
+		v.values[k] = s
+	}
+	s.Value = f(s.Value)
+}
+
+func (v *valueVec) samples() []Sample {
+	v.mtx.Lock()
+	samples := make([]Sample, 0, len(v.values))
+	for _, s := range v.values {
+		samples = append(samples, *s)
+	}
+	v.mtx.Unlock()
+	sortSamples(samples)
+	return samples
+}
+
+func (v *valueVec) write(w *bufio.Writer) {
+	v.writeHeader(w)
+	for _, s := range v.samples() {
+		v.writeSample(w, "", s.LabelValues, "", "", s.Value)
+	}
+}
+
+func sortSamples(samples []Sample) {
+	sort.Slice(samples, func(i, j int) bool {
+		return labelKey(samples[i].LabelValues) < labelKey(samples[j].LabelValues)
+	})
+}
+
+// CounterVec is a set of counters partitioned by label values.
+type CounterVec struct {
+	*valueVec
+}
+
+// NewCounterVec creates and registers a CounterVec with the specified labels.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newValueVec(name, help, counterType, labels)}
+	register(name, c)
+	return c
+}
+
+// Add adds v, which must not be negative, to the counter for the label values.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.update(labelValues, func(cur float64) float64 { return cur + v })
+}
+
+// Inc increments the counter for the label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// GaugeVec is a set of gauges partitioned by label values.
+type GaugeVec struct {
+	*valueVec
+}
+
+// NewGaugeVec creates and registers a GaugeVec with the specified labels.
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newValueVec(name, help, gaugeType, labels)}
+	register(name, g)
+	return g
+}
+
+// Set sets the gauge for the label values.
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.update(labelValues, func(float64) float64 { return v })
+}
+
+// Delete removes the gauge for the label values.
+func (g *GaugeVec) Delete(labelValues ...string) {
+	g.checkLabels(labelValues)
+	g.mtx.Lock()
+	delete(g.values, labelKey(labelValues))
+	g.mtx.Unlock()
+}
+
+// gaugeFunc is a gauge with values that are collected when the metrics are
+// written.
+type gaugeFunc struct {
+	*desc
+	collect func() []Sample
+}
+
+// RegisterGaugeFunc registers a gauge with the specified labels whose values
+// are collected by calling f each time the metrics are written. Each Sample
+// returned by f must have a label value for each label. Registering a gauge
+// with the same name as an existing metric replaces it.
+func RegisterGaugeFunc(name, help string, f func() []Sample, labels ...string) {
+	register(name, &gaugeFunc{
+		desc: &desc{
+			name:   name,
+			help:   help,
+			typ:    gaugeType,
+			labels: labels,
+		},
+		collect: f,
+	})
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	samples := g.collect()
+	sortSamples(samples)
+	g.writeHeader(w)
+	for _, s := range samples {
+		if len(s.LabelValues) != len(g.labels) {
+			continue
+		}
+		g.writeSample(w, "", s.LabelValues, "", "", s.Value)
+	}
+}
+
+// histogram is the state of a single histogram.
+type histogram struct {
+	labelValues []string
+	counts      []uint64 // per bucket, not cumulative
+	sum         float64
+	count       uint64
+}
+
+// HistogramVec is a set of histograms partitioned by label values.
+type HistogramVec struct {
+	*desc
+	buckets []float64 // upper bounds, sorted
+	mtx     sync.Mutex
+	values  map[string]*histogram
+}
+
+// NewHistogramVec creates and registers a HistogramVec with the bucket upper
+// bounds and labels.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{
+		desc: &desc{
+			name:   name,
+			help:   help,
+			typ:    histogramType,
+			labels: labels,
+		},
+		buckets: buckets,
+		values:  make(map[string]*histogram),
+	}
+	register(name, h)
+	return h
+}
+
+// Observe adds an observation to the histogram for the label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.checkLabels(labelValues)
+	k := labelKey(labelValues)
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	hist, found := h.values[k]
+	if !found {
+		hist = &histogram{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.values[k] = hist
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		hist.counts[i]++
+	}
+	hist.sum += v
+	hist.count++
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mtx.Lock()
+	hists := make([]histogram, 0, len(h.values))
+	for _, hist := range h.values {
+		hc := *hist
+		hc.counts = append([]uint64(nil), hist.counts...)
+		hists = append(hists, hc)
+	}
+	h.mtx.Unlock()
+	sort.Slice(hists, func(i, j int) bool {
+		return labelKey(hists[i].labelValues) < labelKey(hists[j].labelValues)
+	})
+
+	h.writeHeader(w)
+	for _, hist := range hists {
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += hist.counts[i]
+			h.writeSample(w, "_bucket", hist.labelValues, "le", formatFloat(upper), float64(cumulative))
+		}
+		h.writeSample(w, "_bucket", hist.labelValues, "le", "+Inf", float64(hist.count))
+		h.writeSample(w, "_sum", hist.labelValues, "", "", hist.sum)
+		h.writeSample(w, "_count", hist.labelValues, "", "", float64(hist.count))
+	}
+}
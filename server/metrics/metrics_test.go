@@ -0,0 +1,90 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// resetRegistry clears the registry so tests only see their own metrics.
+func resetRegistry() {
+	registryMtx.Lock()
+	registry = make(map[string]metric)
+	registryMtx.Unlock()
+}
+
+func writeString(t *testing.T) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := Write(&b); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	return b.String()
+}
+
+func TestWrite(t *testing.T) {
+	resetRegistry()
+	defer resetRegistry()
+
+	c := NewCounterVec("test_requests_total", "Test requests.", "route")
+	c.Inc("b")
+	c.Add(2, "a")
+	c.Add(-1, "a") // ignored
+	c.Inc("b")
+
+	g := NewGaugeVec("test_size", "Test size.", "market")
+	g.Set(5, "dcr_btc")
+	g.Set(7, `we"ird`)
+	g.Set(1, "gone")
+	g.Delete("gone")
+
+	RegisterGaugeFunc("test_clients", "Test clients.", func() []Sample {
+		return []Sample{{Value: 3}, {LabelValues: []string{"bad"}, Value: 1}}
+	})
+
+	h := NewHistogramVec("test_seconds", "Test seconds.", []float64{1, 0.5}, "market")
+	h.Observe(0.25, "dcr_btc")
+	h.Observe(0.75, "dcr_btc")
+	h.Observe(3, "dcr_btc")
+
+	want := `# HELP test_clients Test clients.
+# TYPE test_clients gauge
+test_clients 3
+# HELP test_requests_total Test requests.
+# TYPE test_requests_total counter
+test_requests_total{route="a"} 2
+test_requests_total{route="b"} 2
+# HELP test_seconds Test seconds.
+# TYPE test_seconds histogram
+test_seconds_bucket{market="dcr_btc",le="0.5"} 1
+test_seconds_bucket{market="dcr_btc",le="1"} 2
+test_seconds_bucket{market="dcr_btc",le="+Inf"} 3
+test_seconds_sum{market="dcr_btc"} 4
+test_seconds_count{market="dcr_btc"} 3
+# HELP test_size Test size.
+# TYPE test_size gauge
+test_size{market="dcr_btc"} 5
+test_size{market="we\"ird"} 7
+`
+	if got := writeString(t); got != want {
+		t.Fatalf("wrong output. wanted:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestLabelCountPanics(t *testing.T) {
+	resetRegistry()
+	defer resetRegistry()
+
+	c := NewCounterVec("test_total", "Test.", "a", "b")
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("no panic for wrong number of label values")
+		} else if !strings.Contains(r.(string), "test_total") {
+			t.Fatalf("panic message does not name the metric: %v", r)
+		}
+	}()
+	c.Inc("x")
+}
@@ -151,6 +151,11 @@ type matchTracker struct {
 	matchTime   time.Time // epoch close time
 	makerStatus *swapStatus
 	takerStatus *swapStatus
+	// lastPaused is the last time that block-based inaction checks were
+	// skipped because the nodes of a swap asset were unhealthy. The inaction
+	// timeout restarts when the nodes recover. lastPaused is protected by the
+	// Swapper's matchMtx.
+	lastPaused time.Time
 }
 
 // expiredBy returns true if the lock time of either party's *known* swap is
@@ -509,6 +514,18 @@ func (s *Swapper) AccountStats(acctAddr string, assetID uint32) (qty, swaps uint
 	return stats.qty, stats.swaps, stats.redeems
 }
 
+// MatchStatusCounts returns the number of active matches in each
+// order.MatchStatus.
+func (s *Swapper) MatchStatusCounts() map[order.MatchStatus]int {
+	counts := make(map[order.MatchStatus]int)
+	for _, mt := range s.matchSlice() {
+		mt.mtx.RLock()
+		counts[mt.Status]++
+		mt.mtx.RUnlock()
+	}
+	return counts
+}
+
 // ChainsSynced will return true if both specified asset's backends are synced.
 func (s *Swapper) ChainsSynced(base, quote uint32) (bool, error) {
 	b, found := s.coins[base]
@@ -1182,15 +1199,41 @@ func (s *Swapper) checkInactionBlockBased(assetID uint32) {
 	var failures []fail
 	// Do time.Since(event) with the same now time for each match.
 	now := time.Now()
-	tooOld := func(evt time.Time) bool {
+	tooOld := func(match *matchTracker, evt time.Time) bool {
 		// If the time is not set (zero), it has not happened yet (not too old).
-		return !evt.IsZero() && now.Sub(evt) >= s.bTimeout
+		if evt.IsZero() {
+			return false
+		}
+		// Restart the timeout if the checks were paused since the event.
+		if match.lastPaused.After(evt) {
+			evt = match.lastPaused
+		}
+		return now.Sub(evt) >= s.bTimeout
 	}
 
+	// Do not penalize inaction while all of the nodes of either swap asset are
+	// unhealthy. The users may have acted, but the server cannot see it, and
+	// they are given the full timeout again once the nodes recover.
+	healthy := make(map[uint32]bool, 2)
+	assetHealthy := func(assetID uint32) bool {
+		h, found := healthy[assetID]
+		if !found {
+			h = s.assetHealthy(assetID)
+			healthy[assetID] = h
+		}
+		return h
+	}
+	var paused int
+
 	checkMatch := func(match *matchTracker) {
 		if match.makerStatus.swapAsset != assetID && match.takerStatus.swapAsset != assetID {
 			return
 		}
+		if !assetHealthy(match.makerStatus.swapAsset) || !assetHealthy(match.takerStatus.swapAsset) {
+			match.lastPaused = now
+			paused++
+			return
+		}
 
 		// Lock entire matchTracker so the following is atomic with respect to
 		// Status.
@@ -1208,11 +1251,11 @@ func (s *Swapper) checkInactionBlockBased(assetID uint32) {
 
 		switch match.Status {
 		case order.MakerSwapCast:
-			if tooOld(match.makerStatus.swapConfTime()) { // rlocks swapStatus.mtx
+			if tooOld(match, match.makerStatus.swapConfTime()) { // rlocks swapStatus.mtx
 				deleteMatch()
 			}
 		case order.TakerSwapCast:
-			if tooOld(match.takerStatus.swapConfTime()) {
+			if tooOld(match, match.takerStatus.swapConfTime()) {
 				deleteMatch()
 			}
 		}
@@ -1225,6 +1268,10 @@ func (s *Swapper) checkInactionBlockBased(assetID uint32) {
 	}
 	s.matchMtx.Unlock()
 
+	if paused > 0 {
+		log.Warnf("Inaction penalties paused for %d matches while asset nodes are unhealthy.", paused)
+	}
+
 	// Record failed matches in the DB and auth mgr, unlock coins, and send
 	// revoke_match messages.
 	for _, fail := range failures {
@@ -1232,6 +1279,17 @@ func (s *Swapper) checkInactionBlockBased(assetID uint32) {
 	}
 }
 
+// assetHealthy is false if the asset's backend reports that none of its nodes
+// are healthy.
+func (s *Swapper) assetHealthy(assetID uint32) bool {
+	swapAsset, found := s.coins[assetID]
+	if !found {
+		return true
+	}
+	hr, is := swapAsset.Backend.(asset.HealthReporter)
+	return !is || hr.Healthy()
+}
+
 // respondError sends an rpcError to a user.
 func (s *Swapper) respondError(id uint64, user account.AccountID, code int, errMsg string) {
 	log.Debugf("Error going to user %v, code: %d, msg: %s", user, code, errMsg)
@@ -1808,6 +1866,15 @@ func (s *Swapper) processRedeem(msg *msgjson.Message, params *msgjson.Redeem, st
 		return wait.DontTryAgain
 	}
 	// For maker's redeem, inform the taker.
+	s.sendRedemption(match, counterParty, params, redeemTime, time.Until(redeemTime.Add(s.bTimeout)))
+	return wait.DontTryAgain
+}
+
+// sendRedemption sends the 'redemption' request for the maker's redemption to
+// the taker, allowing timeout for the taker's response.
+func (s *Swapper) sendRedemption(match *matchTracker, counterParty stepActor, params *msgjson.Redeem,
+	redeemTime time.Time, timeout time.Duration) {
+	matchID := match.ID()
 	rParams := &msgjson.Redemption{
 		Redeem: msgjson.Redeem{
 			OrderID: idToBytes(counterParty.order.ID()),
@@ -1815,13 +1882,13 @@ func (s *Swapper) processRedeem(msg *msgjson.Message, params *msgjson.Redeem, st
 			CoinID:  params.CoinID,
 			Secret:  params.Secret,
 		},
-		Time: uint64(redeemTimeMs),
+		Time: uint64(redeemTime.UnixMilli()),
 	}
 	s.authMgr.Sign(rParams)
 	redemptionReq, err := msgjson.NewRequest(comms.NextID(), msgjson.RedemptionRoute, rParams)
 	if err != nil {
 		log.Errorf("error creating redemption request: %v", err)
-		return wait.DontTryAgain
+		return
 	}
 
 	// Set up the acknowledgement callback.
@@ -1832,7 +1899,7 @@ func (s *Swapper) processRedeem(msg *msgjson.Message, params *msgjson.Redeem, st
 		isMaker: counterParty.isMaker,
 		// isAudit: false,
 	}
-	log.Debugf("processRedeem: sending 'redemption' request to counterparty %v (%s) "+
+	log.Debugf("sendRedemption: sending 'redemption' request to counterparty %v (%s) "+
 		"for match %v", ack.user, makerTaker(ack.isMaker), matchID)
 
 	// Send the ack request.
@@ -1841,11 +1908,121 @@ func (s *Swapper) processRedeem(msg *msgjson.Message, params *msgjson.Redeem, st
 	// so use the default request timeout.
 	s.authMgr.RequestWithTimeout(ack.user, redemptionReq, func(_ comms.Link, resp *msgjson.Message) {
 		s.processAck(resp, ack) // resp.ID == notification.ID
-	}, time.Until(redeemTime.Add(s.bTimeout)), func() {
+	}, timeout, func() {
 		log.Infof("Timeout waiting for 'redemption' request from user %v (%s) for match %v",
 			ack.user, makerTaker(ack.isMaker), matchID)
 	})
+}
+
+// redeemReplacementStep returns the step information for a 'redeem' request
+// from a maker that has already redeemed, or nil if the request is not for a
+// replacement of the maker's redemption. The taker's redemption completes the
+// match, so it cannot be replaced.
+func (s *Swapper) redeemReplacementStep(user account.AccountID, matchID order.MatchID, oid []byte) *stepInformation {
+	s.matchMtx.RLock()
+	match, found := s.matches[matchID]
+	s.matchMtx.RUnlock()
+	if !found {
+		return nil
+	}
+
+	match.mtx.RLock()
+	defer match.mtx.RUnlock()
+	maker, taker := match.Maker, match.Taker
+	if match.Status != order.MakerRedeemed || maker.User() != user || !bytes.Equal(oid, idToBytes(maker.ID())) {
+		return nil
+	}
+
+	actor := stepActor{
+		user:      user,
+		swapAsset: maker.QuoteAsset,
+		isMaker:   true,
+		order:     maker,
+		status:    match.makerStatus,
+	}
+	counterParty := stepActor{
+		user:      taker.User(),
+		swapAsset: maker.BaseAsset,
+		order:     taker,
+		status:    match.takerStatus,
+	}
+	if !maker.Sell { // maker redeem: base asset if buy
+		actor.swapAsset, counterParty.swapAsset = maker.BaseAsset, maker.QuoteAsset
+	}
+	return &stepInformation{
+		match:        match,
+		actor:        actor,
+		counterParty: counterParty,
+		asset:        s.coins[actor.swapAsset].BackedAsset,
+		isBaseAsset:  !maker.Sell,
+		step:         order.MakerRedeemed,
+		nextStep:     order.MakerRedeemed,
+	}
+}
+
+// processRedeemReplacement processes a 'redeem' request from a maker that
+// replaced their redemption, e.g. with a transaction paying a higher fee. The
+// replacement must spend the taker's contract. The redeem time, and so the
+// taker's deadline to redeem, is not changed. The taker is sent a new
+// 'redemption' request with the replacement coin ID. The maker is not credited
+// again for the swap.
+func (s *Swapper) processRedeemReplacement(msg *msgjson.Message, params *msgjson.Redeem, stepInfo *stepInformation) wait.TryDirective {
+	actor, counterParty := stepInfo.actor, stepInfo.counterParty
+	counterParty.status.mtx.RLock()
+	cpContract := counterParty.status.swap.ContractData
+	cpSwapCoin := counterParty.status.swap.ID()
+	counterParty.status.mtx.RUnlock()
+
+	match := stepInfo.match
+	matchID := match.ID()
+	chain := stepInfo.asset.Backend
+	if !chain.ValidateSecret(params.Secret, cpContract) {
+		log.Infof("Secret validation failed for replacement redemption (match id=%v, secret=%v)",
+			matchID, params.Secret)
+		s.respondError(msg.ID, actor.user, msgjson.UnknownMarketError, "secret validation failed")
+		return wait.DontTryAgain
+	}
+	redemption, err := chain.Redemption(params.CoinID, cpSwapCoin, cpContract)
+	if err != nil {
+		if errors.Is(err, asset.CoinNotFoundError) {
+			return wait.TryAgain
+		}
+		log.Warnf("Replacement redemption error encountered for match %s, using coin ID %v to satisfy contract at %x: %v",
+			matchID, params.CoinID, cpSwapCoin, err)
+		s.respondError(msg.ID, actor.user, msgjson.RedemptionError, "redemption error")
+		return wait.DontTryAgain
+	}
+
+	// The taker may have redeemed, completing the match, or the match may
+	// have been revoked while waiting for the txn.
+	s.matchMtx.RLock()
+	if _, found := s.matches[matchID]; !found {
+		s.matchMtx.RUnlock()
+		s.respondError(msg.ID, actor.user, msgjson.RPCUnknownMatch, "match already complete or revoked")
+		return wait.DontTryAgain
+	}
+	actor.status.mtx.Lock()
+	oldRedemption := actor.status.redemption
+	actor.status.redemption = redemption
+	redeemTime := actor.status.redeemTime
+	actor.status.mtx.Unlock()
+	s.matchMtx.RUnlock()
+
+	log.Infof("Maker %v replaced redemption %v with %v (%s) for match %v", actor.user,
+		oldRedemption, redemption, stepInfo.asset.Symbol, matchID)
+
+	err = s.storage.SaveRedeemA(db.MatchID(match.Match), params.CoinID, params.Secret, redeemTime.UnixMilli())
+	if err != nil {
+		log.Errorf("saving replacement redeem transaction (match id=%v) failed: %v", matchID, err)
+	}
+
+	s.authMgr.Sign(params)
+	s.respondSuccess(msg.ID, actor.user, &msgjson.Acknowledgement{
+		MatchID: matchID[:],
+		Sig:     params.Sig,
+	})
 
+	s.sendRedemption(match, counterParty, params, redeemTime, s.bTimeout)
 	return wait.DontTryAgain
 }
 
@@ -2011,27 +2188,37 @@ func (s *Swapper) handleRedeem(user account.AccountID, msg *msgjson.Message) *ms
 
 	var matchID order.MatchID
 	copy(matchID[:], params.MatchID)
-	stepInfo, rpcErr := s.step(user, matchID)
-	if rpcErr != nil {
-		return rpcErr
-	}
 
-	// redeem requests should only be sent when all contracts have been
-	// received, in the correct sequence, and by the correct party.
-	switch stepInfo.step {
-	case order.TakerSwapCast, order.MakerRedeemed:
-		// Ensure we only start one coin waiter for this redeem. This is an
-		// atomic CAS, so it must ultimately be followed by endRedeemSearch().
-		if !stepInfo.actor.status.startRedeemSearch() {
+	// A maker that has already redeemed may be replacing their redemption,
+	// e.g. with a transaction paying a higher fee.
+	process := s.processRedeem
+	stepInfo := s.redeemReplacementStep(user, matchID, params.OrderID)
+	if stepInfo != nil {
+		process = s.processRedeemReplacement
+	} else {
+		var rpcErr *msgjson.Error
+		stepInfo, rpcErr = s.step(user, matchID)
+		if rpcErr != nil {
+			return rpcErr
+		}
+		// redeem requests should only be sent when all contracts have been
+		// received, in the correct sequence, and by the correct party.
+		switch stepInfo.step {
+		case order.TakerSwapCast, order.MakerRedeemed:
+		default: // also includes MatchComplete
 			return &msgjson.Error{
-				Code:    msgjson.DuplicateRequestError, // not really a sequence error since they are still the "actor"
-				Message: "already received a redeem transaction, search in progress",
+				Code:    msgjson.SettlementSequenceError,
+				Message: "swap contracts not yet received",
 			}
 		}
-	default: // also includes MatchComplete
+	}
+
+	// Ensure we only start one coin waiter for this redeem. This is an
+	// atomic CAS, so it must ultimately be followed by endRedeemSearch().
+	if !stepInfo.actor.status.startRedeemSearch() {
 		return &msgjson.Error{
-			Code:    msgjson.SettlementSequenceError,
-			Message: "swap contracts not yet received",
+			Code:    msgjson.DuplicateRequestError, // not really a sequence error since they are still the "actor"
+			Message: "already received a redeem transaction, search in progress",
 		}
 	}
 
@@ -2060,7 +2247,7 @@ func (s *Swapper) handleRedeem(user account.AccountID, msg *msgjson.Message) *ms
 	s.latencyQ.Wait(&wait.Waiter{
 		Expiration: expireTime,
 		TryFunc: func() wait.TryDirective {
-			res := s.processRedeem(msg, params, stepInfo)
+			res := process(msg, params, stepInfo)
 			if res == wait.DontTryAgain {
 				stepInfo.actor.status.endRedeemSearch()
 			}
@@ -375,6 +375,7 @@ type TBackend struct {
 	bChan          chan *asset.BlockUpdate // to trigger processBlock and eventually (after up to BroadcastTimeout) checkInaction depending on block time
 	lbl            string
 	invalidFeeRate bool
+	unhealthy      bool
 }
 
 func newTBackend(lbl string) TBackend {
@@ -438,6 +439,18 @@ func (a *TBackend) TxData([]byte) ([]byte, error) {
 	return nil, nil
 }
 
+func (a *TBackend) Healthy() bool {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	return !a.unhealthy
+}
+
+func (a *TBackend) setUnhealthy(unhealthy bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.unhealthy = unhealthy
+}
+
 func (a *TBackend) setContractErr(err error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
@@ -1476,6 +1489,10 @@ func testSwap(t *testing.T, rig *testRig) {
 		sendBlock(&takerSwapAsset.Backend.(*TUTXOBackend).TBackend)
 		ensureNilErr(rig.redeem_maker(true))
 		ensureNilErr(rig.ackRedemption_taker(true))
+		// The maker replaces their redemption, e.g. to pay a higher fee, and
+		// the taker is sent the replacement.
+		ensureNilErr(rig.redeem_maker(true))
+		ensureNilErr(rig.ackRedemption_taker(true))
 		ensureNilErr(rig.redeem_taker(true))
 	}
 }
@@ -1891,6 +1908,59 @@ func TestBroadcastTimeouts(t *testing.T) {
 	}
 }
 
+func TestInactionPausedWhileUnhealthy(t *testing.T) {
+	rig, cleanup := tNewTestRig(nil)
+	defer cleanup()
+	rig.auth.auditReq = make(chan struct{}, 1)
+	ensureNilErr := makeEnsureNilErr(t)
+
+	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	matchInfo := set.matchInfos[0]
+	rig.matchInfo = matchInfo
+	rig.swapper.Negotiate([]*order.MatchSet{set.matchSet})
+	ensureNilErr(rig.ackMatch_maker(true))
+	ensureNilErr(rig.ackMatch_taker(true))
+	ensureNilErr(rig.sendSwap_maker(true))
+	ensureNilErr(rig.auditSwap_taker())
+	ensureNilErr(rig.ackAudit_taker(true))
+
+	// All of the nodes for the taker's swap asset are unhealthy, so the server
+	// cannot see the taker's swap.
+	rig.xyzNode.setUnhealthy(true)
+
+	// Maker's swap reaches swapConf, and the taker's broadcast timeout passes.
+	matchInfo.db.makerSwap.coin.Coin.(*TCoin).setConfs(int64(rig.abc.SwapConf))
+	rig.abcNode.bChan <- &asset.BlockUpdate{Err: nil}
+	time.Sleep(rig.swapper.bTimeout * 2)
+
+	rig.swapper.checkInactionBlockBased(ABCID)
+	if found, _ := rig.auth.flushPenalty(matchInfo.taker.acct); found {
+		t.Fatalf("taker penalized while the swap asset was unhealthy")
+	}
+	if rig.getTracker() == nil {
+		t.Fatalf("match revoked while the swap asset was unhealthy")
+	}
+
+	// The timeout restarts when a node recovers, since the taker may have
+	// acted during the outage.
+	rig.xyzNode.setUnhealthy(false)
+	rig.swapper.checkInactionBlockBased(ABCID)
+	if found, _ := rig.auth.flushPenalty(matchInfo.taker.acct); found {
+		t.Fatalf("taker penalized immediately after the swap asset recovered")
+	}
+	if rig.getTracker() == nil {
+		t.Fatalf("match revoked immediately after the swap asset recovered")
+	}
+
+	// The penalty is assigned if the taker still has not acted a full timeout
+	// after the recovery.
+	time.Sleep(rig.swapper.bTimeout * 2)
+	rig.swapper.checkInactionBlockBased(ABCID)
+	if found, _ := rig.auth.flushPenalty(matchInfo.taker.acct); !found {
+		t.Fatalf("taker not penalized after the swap asset recovered")
+	}
+}
+
 func TestSigErrors(t *testing.T) {
 	dummyError := fmt.Errorf("test error")
 	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)